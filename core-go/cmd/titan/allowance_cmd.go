@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/allowance"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/execution"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/rpc"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+)
+
+// runAllowances implements `titan allowances scan|revoke`
+func runAllowances(args []string) error {
+	usage := fmt.Errorf("usage: titan allowances scan|revoke --chain <chain> [--owner <address>] [--yes]")
+	if len(args) == 0 || (args[0] != "scan" && args[0] != "revoke") {
+		return usage
+	}
+	revoke := args[0] == "revoke"
+
+	fs := flag.NewFlagSet("allowances "+args[0], flag.ContinueOnError)
+	chainName := fs.String("chain", "", "chain name (e.g. polygon)")
+	ownerFlag := fs.String("owner", "", "scan only this owner (default: the executor and the signing wallet)")
+	yes := fs.Bool("yes", false, "revoke without confirmation")
+	timeout := fs.Duration("timeout", 10*time.Minute, "overall timeout")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *chainName == "" {
+		fs.Usage()
+		return fmt.Errorf("--chain is required")
+	}
+	network, err := enum.FromName(*chainName)
+	if err != nil {
+		return err
+	}
+	chainID := uint64(network)
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	if chainCfg, ok := cfg.GetChain(chainID); !ok || chainCfg.RPC == "" {
+		return fmt.Errorf("no RPC configured for %s", network.Name())
+	}
+	registry, err := tokens.FromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	var key *ecdsa.PrivateKey
+	if revoke {
+		if cfg.WatchOnly {
+			return errors.New("watch-only: not revoking")
+		}
+		if key, err = openKey(cfg, "PRIVATE_KEY", os.Getenv("PRIVATE_KEY")); err != nil {
+			return err
+		}
+	}
+	owners := allowanceOwners(cfg)
+	if *ownerFlag != "" {
+		if !common.IsHexAddress(*ownerFlag) {
+			return fmt.Errorf("invalid owner %q", *ownerFlag)
+		}
+		owner := allowance.Owner{Address: common.HexToAddress(*ownerFlag)}
+		for _, o := range owners(chainID) {
+			if o.Address == owner.Address {
+				owner.Executor = o.Executor
+			}
+		}
+		owners = func(uint64) []allowance.Owner { return []allowance.Owner{owner} }
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	providers := rpc.NewRouter(cfg, rpc.Dialer(cfg), nil)
+	defer providers.Close()
+	hygiene := newAllowanceHygiene(cfg, providers, registry, owners)
+
+	var found []allowance.Approval
+	for _, owner := range owners(chainID) {
+		approvals, err := hygiene.Scan(ctx, chainID, owner)
+		if err != nil {
+			return fmt.Errorf("%s: %w", owner.Address.Hex(), err)
+		}
+		found = append(found, approvals...)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OWNER\tTOKEN\tSPENDER\tALLOWANCE\tLAST USED\tSTATUS")
+	var due []allowance.Approval
+	for _, a := range found {
+		status := "ok"
+		if a.Due() {
+			status = "revoke: " + a.Reason()
+			if a.Executor {
+				status = "due, executor-held: " + a.Reason()
+			}
+		}
+		if a.Revocable() && key != nil && a.Owner == execution.Sender(key) {
+			due = append(due, a)
+		}
+		spender := a.Spender.Hex()
+		if a.Router != "" {
+			spender += " (" + a.Router + ")"
+		}
+		amount := a.Allowance.String()
+		if a.Allowance.Cmp(math.MaxBig256) == 0 {
+			amount = "unlimited"
+		}
+		lastUsed := "-"
+		if a.LastUsed > 0 {
+			lastUsed = fmt.Sprintf("block %d", a.LastUsed)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", a.Owner.Hex(), orDash(a.Symbol), spender, amount, lastUsed, status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if !revoke {
+		return nil
+	}
+	if len(due) == 0 {
+		fmt.Println("\n✅ Nothing the signing wallet can revoke")
+		return nil
+	}
+	if !*yes && !confirm(fmt.Sprintf("\nRevoke %d approvals on %s?", len(due), network.Name())) {
+		return errors.New("aborted by operator")
+	}
+	fees := newGasOracle(cfg, providers)
+	for _, a := range due {
+		hash, err := sendRevocation(ctx, cfg, fees, key, a)
+		if err != nil {
+			return fmt.Errorf("revoking %s's approval for %s: %w", orDash(a.Symbol), a.Spender.Hex(), err)
+		}
+		fmt.Printf("🔒 Revoked %s's approval for %s in %s\n", orDash(a.Symbol), a.Spender.Hex(), hash.Hex())
+	}
+	return nil
+}
+
+// allowanceOwners returns each chain's executor, from EXECUTOR_ADDRESS_<CHAIN>,
+// and the wallet signing with PRIVATE_KEY when one is loaded
+func allowanceOwners(cfg *config.Config) func(chainID uint64) []allowance.Owner {
+	var wallet *common.Address
+	if key, err := openKey(cfg, "PRIVATE_KEY", os.Getenv("PRIVATE_KEY")); err == nil {
+		sender := execution.Sender(key)
+		wallet = &sender
+	}
+	return func(chainID uint64) []allowance.Owner {
+		var owners []allowance.Owner
+		name := strings.ToUpper(enum.ChainID(chainID).Name())
+		if executor := os.Getenv("EXECUTOR_ADDRESS_" + name); common.IsHexAddress(executor) {
+			owners = append(owners, allowance.Owner{Address: common.HexToAddress(executor), Executor: true})
+		}
+		if wallet != nil {
+			owners = append(owners, allowance.Owner{Address: *wallet})
+		}
+		return owners
+	}
+}
+
+// newAllowanceHygiene scans owners' approvals of every registered token over
+// low-priority providers
+func newAllowanceHygiene(cfg *config.Config, providers *rpc.Router, registry *tokens.Registry, owners func(chainID uint64) []allowance.Owner) *allowance.Hygiene {
+	dial := func(chainID uint64) (allowance.Client, error) {
+		client, err := providers.Client(chainID, rpc.PriorityLow)
+		if err != nil {
+			return nil, err
+		}
+		logs, ok := client.(allowance.Client)
+		if !ok {
+			return nil, fmt.Errorf("chain %d client can't filter logs", chainID)
+		}
+		return logs, nil
+	}
+	return allowance.New(cfg.Allowances, cfg.DexRouters, dial, registry.Chain, owners, metrics.Default)
+}
+
+// sendRevocation signs and broadcasts a's approve(spender, 0) from key
+func sendRevocation(ctx context.Context, cfg *config.Config, fees *gas.Oracle, key *ecdsa.PrivateKey, a allowance.Approval) (common.Hash, error) {
+	if a.Owner != execution.Sender(key) {
+		return common.Hash{}, fmt.Errorf("approval owned by %s, not the signer", a.Owner.Hex())
+	}
+	chainCfg, ok := cfg.GetChain(a.ChainID)
+	if !ok {
+		return common.Hash{}, fmt.Errorf("chain %d not configured", a.ChainID)
+	}
+	call, err := allowance.Revocation(a)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	node, err := rpc.Dial(ctx, cfg, chainCfg.RPC)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer node.Close()
+	adapter, err := execution.FromConfig(a.ChainID, chainCfg)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if call.GasLimit, err = adapter.EstimateGas(ctx, node, call); err != nil {
+		return common.Hash{}, err
+	}
+	if call.Fees, err = fees.Suggest(ctx, a.ChainID); err != nil {
+		return common.Hash{}, fmt.Errorf("fees: %w", err)
+	}
+	if call.Nonce, err = ethclient.NewClient(node).PendingNonceAt(ctx, call.From); err != nil {
+		return common.Hash{}, err
+	}
+	raw, hash, err := adapter.Sign(call, key)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return hash, execution.Broadcast(ctx, node, raw, hash)
+}
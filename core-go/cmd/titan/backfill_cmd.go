@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/models"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/report"
+)
+
+// runBackfill implements `titan backfill`: every journaled opportunity's
+// features recomputed under the current schema, with the scores of the
+// built-in and registry models and its execution outcome, as JSON lines for
+// training after a feature-schema change
+func runBackfill(args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	journalPath := fs.String("journal", "", "journal file (defaults to <data dir>/journal.jsonl)")
+	out := fs.String("out", "", "output file, or - for stdout (defaults to <data dir>/backfill.jsonl)")
+	since := fs.Duration("since", 0, "how far back to reach; 0 backfills the whole journal")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	if *journalPath == "" {
+		*journalPath = filepath.Join(cfg.DataDir, "journal.jsonl")
+	}
+	if *out == "" {
+		*out = filepath.Join(cfg.DataDir, "backfill.jsonl")
+	}
+	registry := models.FromConfig(cfg.Models, nil)
+	if err := loadModels(context.Background(), registry); err != nil {
+		return err
+	}
+	entries, err := journal.ReadSealed(*journalPath, "", cfg.StateKeys)
+	if err != nil {
+		return err
+	}
+	var cutoff time.Time
+	if *since > 0 {
+		cutoff = time.Now().Add(-*since)
+	}
+	rows, err := report.Backfill(entries, cutoff, registry)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	for _, row := range rows {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	if *out != "-" {
+		fmt.Printf("Backfilled %d opportunities with %d registry models into %s\n", len(rows), len(registry.Models()), *out)
+	}
+	return nil
+}
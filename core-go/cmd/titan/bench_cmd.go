@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/bench"
+)
+
+// runBench implements `titan bench`
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	pools := fs.String("pools", "50,200,1000", "comma-separated graph sizes (pool count) for route search")
+	calls := fs.String("calls", "10,100", "comma-separated calls per multicall batch")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	graphSizes, err := parseIntList(*pools)
+	if err != nil {
+		return err
+	}
+	multicallSizes, err := parseIntList(*calls)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("⏱️  Running Titan hot-path benchmarks...")
+	results := bench.Run(bench.Cases(graphSizes, multicallSizes))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BENCHMARK\tOPS/SEC\tNS/OP\tALLOCS/OP\tBYTES/OP")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%.0f\t%d\t%d\t%d\n", r.Name, r.OpsPerSec, r.NsPerOp, r.AllocsPerOp, r.BytesPerOp)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/bridge"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/prices"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/rpc"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// quotePlaceholderSender is used as fromAddress when no executor is configured;
+// aggregators require an address but do not check balances for quotes
+const quotePlaceholderSender = "0x0000000000000000000000000000000000000001"
+
+// runBridge implements `titan bridge <subcommand>`
+func runBridge(args []string) error {
+	usage := fmt.Errorf("usage: titan bridge quote --from <chain> --to <chain> --token <symbol> --amount <n> | titan bridge calibration [--since 720h] [--json] | titan bridge gas --from <chain> --to <chain> [--gas <limit>]")
+	if len(args) == 0 {
+		return usage
+	}
+	switch args[0] {
+	case "quote":
+		return runBridgeQuote(args[1:])
+	case "calibration":
+		return runBridgeCalibration(args[1:])
+	case "gas":
+		return runBridgeGas(args[1:])
+	}
+	return usage
+}
+
+// loadCalibration replays the realized transfers stored since
+func loadCalibration(cfg *config.Config, path string, since time.Time) (*bridge.Calibrator, error) {
+	if path == "" {
+		path = filepath.Join(cfg.DataDir, "bridges.jsonl")
+	}
+	transfers, err := bridge.ReadTransfers(path, cfg.StateKeys, since)
+	if err != nil {
+		return nil, err
+	}
+	calibration := bridge.NewCalibrator(cfg.IntentBasedBridges, cfg.BridgeCalibration, nil, nil)
+	calibration.Load(transfers)
+	return calibration, nil
+}
+
+// runBridgeCalibration implements `titan bridge calibration`
+func runBridgeCalibration(args []string) error {
+	fs := flag.NewFlagSet("bridge calibration", flag.ContinueOnError)
+	since := fs.Duration("since", 30*24*time.Hour, "how far back realized transfers count")
+	asJSON := fs.Bool("json", false, "print estimates and drift as JSON")
+	historyPath := fs.String("history", "", "realized transfer file (defaults to <data dir>/bridges.jsonl)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	calibration, err := loadCalibration(cfg, *historyPath, time.Now().Add(-*since))
+	if err != nil {
+		return err
+	}
+	estimates, drifts := calibration.Estimates(), calibration.Drifts()
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]interface{}{"estimates": estimates, "drift": drifts})
+	}
+	if len(drifts) == 0 {
+		fmt.Printf("No realized bridge transfers over the last %s\n", *since)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BRIDGE\tTRANSFERS\tFEE BPS\tCONFIGURED\tFILL\tCONFIGURED MAX\tSTATUS")
+	for _, d := range drifts {
+		configured, status := "-", "ok"
+		if len(d.ConfiguredBps) == 2 {
+			configured = fmt.Sprintf("%d-%d", d.ConfiguredBps[0], d.ConfiguredBps[1])
+		}
+		if d.Drifted {
+			status = "drifted: " + strings.Join(d.Reasons, "; ")
+		} else if d.Transfers < int(cfg.BridgeCalibration.MinTransfers) {
+			status = "too few transfers"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.1f\t%s\t%s\t%s\t%s\n",
+			d.Bridge, d.Transfers, d.FeeBps, configured, d.Fill.Round(time.Second), d.ConfiguredMax, status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if len(estimates) == 0 {
+		return nil
+	}
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BRIDGE\tROUTE\tSIZE\tTRANSFERS\tFEE BPS\tP10-P90 BPS\tFILL\tP90 FILL")
+	for _, e := range estimates {
+		fmt.Fprintf(w, "%s\t%s → %s\t%s\t%d\t%.1f\t%.1f-%.1f\t%s\t%s\n",
+			e.Bridge, enum.ChainID(e.FromChain).Name(), enum.ChainID(e.ToChain).Name(), e.Size, e.Transfers,
+			e.FeeBps, e.FeeRangeBps[0], e.FeeRangeBps[1], e.Fill.Round(time.Second), e.MaxFill.Round(time.Second))
+	}
+	return w.Flush()
+}
+
+// runBridgeQuote implements `titan bridge quote`
+func runBridgeQuote(args []string) error {
+	fs := flag.NewFlagSet("bridge quote", flag.ContinueOnError)
+	from := fs.String("from", "", "source chain name (e.g. polygon)")
+	to := fs.String("to", "", "destination chain name (e.g. arbitrum)")
+	token := fs.String("token", "USDC", "token symbol or address on the source chain")
+	toToken := fs.String("to-token", "", "destination token symbol or address (defaults to --token)")
+	amount := fs.String("amount", "", "amount in whole token units (e.g. 10000)")
+	sender := fs.String("sender", "", "sender address (defaults to EXECUTOR_ADDRESS_<FROM>)")
+	noLifi := fs.Bool("no-lifi", false, "skip the LiFi aggregator")
+	timeout := fs.Duration("timeout", 10*time.Second, "per-adapter quote timeout")
+	price := fs.Float64("price", 0, "token price in USD for the timing risk haircut (stablecoins default to par)")
+	volBps := fs.Float64("vol-bps", 0, "token's hourly volatility in bps (defaults to BRIDGE_RISK_FALLBACK_VOL_BPS)")
+	profit := fs.Float64("profit", 0, "expected profit in USD before the bridge fee and timing risk")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" || *amount == "" {
+		fs.Usage()
+		return fmt.Errorf("--from, --to and --amount are required")
+	}
+
+	fromChain, err := enum.FromName(*from)
+	if err != nil {
+		return err
+	}
+	toChain, err := enum.FromName(*to)
+	if err != nil {
+		return err
+	}
+
+	registry := tokens.Default()
+	fromTok, err := registry.Lookup(uint64(fromChain), *token)
+	if err != nil {
+		return err
+	}
+	if *toToken == "" {
+		*toToken = fromTok.Symbol
+	}
+	toTok, err := registry.Lookup(uint64(toChain), *toToken)
+	if err != nil {
+		return err
+	}
+	amt, err := units.Parse(fromTok.Address, *amount, fromTok.Decimals)
+	if err != nil {
+		return err
+	}
+
+	if *sender == "" {
+		*sender = os.Getenv("EXECUTOR_ADDRESS_" + strings.ToUpper(fromChain.Name()))
+	}
+	if *sender == "" {
+		*sender = quotePlaceholderSender
+	}
+	if !common.IsHexAddress(*sender) {
+		return fmt.Errorf("invalid sender address %q", *sender)
+	}
+
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	calibration, err := loadCalibration(cfg, "", time.Now().Add(-30*24*time.Hour))
+	if err != nil {
+		return err
+	}
+	adapters := bridge.FromConfig(cfg, calibration)
+	if !*noLifi {
+		adapters.Register(bridge.NewLifiAdapter("", os.Getenv("LIFI_API_KEY")))
+	}
+
+	if fromTok.Stable() && *price == 0 {
+		*price = 1
+	}
+	notional := units.USD(amt.Float() * *price * 1e6)
+
+	req := bridge.Request{
+		FromChain: uint64(fromChain),
+		ToChain:   uint64(toChain),
+		FromToken: fromTok,
+		ToToken:   toTok,
+		Amount:    amt,
+		Sender:    common.HexToAddress(*sender),
+		SizeUSD:   notional,
+	}
+	fmt.Printf("🌉 Bridge quotes: %s %s %s → %s %s\n\n", amt, fromTok.Symbol, fromChain.Name(), toTok.Symbol, toChain.Name())
+	results := adapters.QuoteAll(context.Background(), req, *timeout)
+
+	risk := bridge.NewTimingRisk(cfg.IntentBasedBridges, cfg.BridgeRisk, func(tokens.Token) (float64, bool) {
+		return *volBps, *volBps > 0
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BRIDGE\tRECEIVE\tFEE BPS\tFEE USD\tETA\tMAX ETA\tRISK BPS\tRISK USD\tNET PROFIT\tSOURCE")
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\t-\t-\t-\terror: %v\n", r.Bridge, r.Err)
+			continue
+		}
+		q := r.Quote
+		feeUSD := "-"
+		if q.FeeUSD > 0 {
+			feeUSD = q.FeeUSD.String()
+		}
+		a := risk.AssessQuote(q, fromTok, notional)
+		riskUSD, net := "-", "-"
+		if notional > 0 {
+			riskUSD = a.HaircutUSD.String()
+		}
+		if *profit != 0 && notional > 0 {
+			fee := q.FeeUSD
+			if fee == 0 {
+				fee = notional * units.USD(q.FeeBps) / units.BpsDenominator
+			}
+			net = (units.USD(*profit*1e6) - fee - a.HaircutUSD).String()
+		}
+		fmt.Fprintf(w, "%s\t%s %s\t%d\t%s\t%s\t%s\t%.1f\t%s\t%s\t%s\n",
+			q.Bridge, q.AmountOut, toTok.Symbol, q.FeeBps, feeUSD, q.ETA, q.MaxETA, a.HaircutBps, riskUSD, net, q.Source)
+	}
+	return w.Flush()
+}
+
+// runBridgeGas implements `titan bridge gas`: whether the wallet a transfer
+// lands in holds the gas for the destination leg, and the refuel top-up to
+// send from the source chain when it doesn't
+func runBridgeGas(args []string) error {
+	fs := flag.NewFlagSet("bridge gas", flag.ContinueOnError)
+	from := fs.String("from", "", "source chain name (e.g. polygon)")
+	to := fs.String("to", "", "destination chain name (e.g. arbitrum)")
+	wallet := fs.String("wallet", "", "wallet the transfer lands in (defaults to EXECUTOR_ADDRESS_<FROM>)")
+	gasLimit := fs.Uint64("gas", 0, "destination leg's gas limit (defaults to GAS_PREFUND_DEFAULT_GAS)")
+	timeout := fs.Duration("timeout", 30*time.Second, "overall timeout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" {
+		fs.Usage()
+		return fmt.Errorf("--from and --to are required")
+	}
+	fromChain, err := enum.FromName(*from)
+	if err != nil {
+		return err
+	}
+	toChain, err := enum.FromName(*to)
+	if err != nil {
+		return err
+	}
+	if *wallet == "" {
+		*wallet = os.Getenv("EXECUTOR_ADDRESS_" + strings.ToUpper(fromChain.Name()))
+	}
+	if !common.IsHexAddress(*wallet) {
+		return fmt.Errorf("invalid wallet address %q", *wallet)
+	}
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	if !cfg.GasPrefund.Enabled {
+		return fmt.Errorf("the destination gas check is disabled (GAS_PREFUND_ENABLED=false)")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	providers := rpc.NewRouter(cfg, rpc.Dialer(cfg), nil)
+	defer providers.Close()
+	fees := newGasOracle(cfg, providers)
+	dial := func(chainID uint64) (ethereum.ContractCaller, error) {
+		return providers.Client(chainID, rpc.PriorityLow)
+	}
+	nativePrices := prices.NewTracker(0, nil, prices.NewChainlinkSource(prices.ChainlinkFeedsFromConfig(cfg), dial))
+	if cfg.GasPrefund.Refuel {
+		if err := nativePrices.Refresh(ctx, uint64(fromChain), uint64(toChain)); err != nil {
+			fmt.Printf("⚠️  Native prices: %v\n", err)
+		}
+	}
+	prefund := bridge.NewPrefunder(cfg.GasPrefund,
+		func(ctx context.Context, chainID uint64, wallet common.Address) (*big.Int, error) {
+			chainCfg, ok := cfg.Chains[chainID]
+			if !ok {
+				return nil, fmt.Errorf("chain %d not configured", chainID)
+			}
+			node, err := rpc.Dial(ctx, cfg, chainCfg.RPC)
+			if err != nil {
+				return nil, err
+			}
+			defer node.Close()
+			return ethclient.NewClient(node).BalanceAt(ctx, wallet, nil)
+		},
+		func(ctx context.Context, chainID uint64) (*big.Int, error) {
+			suggestion, err := fees.Suggest(ctx, chainID)
+			return suggestion.FeeCap, err
+		},
+		func(chainID uint64) (uint64, error) {
+			q, err := nativePrices.Quote(chainID)
+			return q.PriceE8, err
+		},
+	)
+
+	plan := bridge.Plan{
+		ID:          "check",
+		Transfer:    bridge.Request{FromChain: uint64(fromChain), ToChain: uint64(toChain), Sender: common.HexToAddress(*wallet)},
+		Source:      bridge.Leg{ChainID: uint64(fromChain)},
+		Destination: bridge.Leg{ChainID: uint64(toChain), Gas: *gasLimit},
+	}
+	check, err := prefund.Check(ctx, plan)
+	if check.Required == nil {
+		return err
+	}
+	native := func(wei *big.Int) string {
+		amount, _ := units.FromBig(common.Address{}, wei, 18)
+		return amount.String()
+	}
+	fmt.Printf("⛽ %s on %s\n", check.Wallet.Hex(), toChain.Name())
+	fmt.Printf("   Gas limit %d at a max fee of %s wei\n", check.GasLimit, check.FeeCap)
+	fmt.Printf("   Required  %s (%d bps of the leg's gas)\n", native(check.Required), cfg.GasPrefund.HeadroomBps)
+	fmt.Printf("   Balance   %s\n", native(check.Balance))
+	if check.Funded() {
+		fmt.Println("✅ Funded")
+		return nil
+	}
+	fmt.Printf("⚠️  Short by %s\n", native(check.Shortfall))
+	if check.TopUp == nil {
+		return err
+	}
+	fmt.Printf("🔁 Top-up (%s): send %s on %s to %s with data %s\n",
+		check.TopUpUSD, native(check.TopUp.Value), fromChain.Name(), check.TopUp.To.Hex(), hexutil.Encode(check.TopUp.Data))
+	return nil
+}
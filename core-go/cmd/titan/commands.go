@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// command is a titan subcommand entry point
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+// commands lists every subcommand available as `titan <name>`
+var commands = map[string]command{
+	"allowances": {usage: "Find and revoke unused or delisted token approvals: allowances scan|revoke --chain <chain> [--owner <address>]", run: runAllowances},
+	"backfill":   {usage: "Recompute journaled opportunities' features and model scores: backfill [--since 720h] [--out <file|->]", run: runBackfill},
+	"bench":      {usage: "Measure hot-path quoting, route search and multicall throughput", run: runBench},
+	"bridge":     {usage: "Compare bridge quotes: bridge quote --from <chain> --to <chain> --token <sym> --amount <n>", run: runBridge},
+	"console":    {usage: "Interactive shell over a running daemon: console [--api <addr>] [command]", run: runConsole},
+	"drift":      {usage: "Check configured contracts against on-chain code: drift [--chain <chain>] [--accept]", run: runDrift},
+	"execute":    {usage: "Validate, simulate and submit an operator route: execute --chain <chain> --route route.json [--dry-run]", run: runExecute},
+	"executor":   {usage: "Manage the on-chain executor: executor status|pause|unpause|transfer-ownership|rescue --chain <chain>", run: runExecutor},
+	"explain":    {usage: "Show an opportunity's stored score breakdown: explain <id> [--json] | explain --export <file> [--since 168h]", run: runExplain},
+	"liquidity":  {usage: "List pools, reserves and price impact: liquidity --chain <chain> --pair BASE/QUOTE", run: runLiquidity},
+	"providers":  {usage: "Rank RPC providers by stored uptime, errors and latency: providers report [--since 720h] [--chain <chain>]", run: runProviders},
+	"quote":      {usage: "Compare swap quotes across DEXes and aggregators: quote --chain <chain> --in <sym> --out <sym> --amount <n>", run: runQuote},
+	"report":     {usage: "Generate a performance report: report --period daily|weekly [--notify]", run: runReport},
+	"router":     {usage: "Classify DEX routers: router detect --chain <chain> [--address <router>]", run: runRouter},
+	"serve":      {usage: "Run the daemon and control API", run: runServe},
+	"state":      {usage: "Encrypt state at rest: state keygen <id> | seal | rekey", run: runState},
+}
+
+// runCommand dispatches a subcommand by name
+func runCommand(name string, args []string) error {
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return nil
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		printUsage()
+		return fmt.Errorf("unknown command %q", name)
+	}
+	return cmd.run(args)
+}
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("Usage: titan [command] [flags]")
+	fmt.Println("\nWith no command, Titan Core starts and tests chain connections.")
+	fmt.Println("\nCommands:")
+	for _, name := range names {
+		fmt.Printf("  %-12s %s\n", name, commands[name].usage)
+	}
+}
+
+// parseIntList parses a comma-separated list of positive integers
+func parseIntList(value string) ([]int, error) {
+	var out []int
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid size %q", part)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
@@ -0,0 +1,492 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/api"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/impact"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/simulation"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// errQuit ends the console loop
+var errQuit = errors.New("quit")
+
+// consoleHelp lists the console's commands
+const consoleHelp = `Commands:
+  status                              instance status, shard and leadership
+  chains                              chains and whether they may execute
+  enable <chain> | disable <chain>    let a chain hand opportunities to the executor, or stop it
+  guardrails                          live sizing limits
+  set <limit> <value>                 adjust a limit: min-loan-usd, max-tvl-share-bps, max-slippage-bps,
+                                      min-profit-usd
+  changes [n]                         recent control and guardrail changes, newest first
+  rollups [hours]                     hourly PnL, hit rate, gas and opportunity counts (default 168)
+  cost <chain> <kind> <usd> [route]   record an approval, bridge or deploy cost; no route shares it chain-wide
+  quote <chain> <in> <out> <amount>   quote a swap across the chain's pools
+  quote <chain> <in> <out> receive=<amount>
+                                      quote the cost of ending with exactly <amount>
+  impact <chain> <in> <max> <pool>[,<pool>...] [points]
+                                      output and price impact of sizes up to <max> through cached pools
+  cache <chain>                       cached pool reserves
+  tokens <chain> [tag]                known tokens
+  simulate <chain> <to> <data> [from=<addr>] [value=<wei>] [gas=<n>] [profit=<usd>]
+                                      simulate a call at the chain's policy depth
+  gas | minloan | prices | volatility | liquidity | blackouts | costs |
+  canaries | exposure | strategies | scoring | models | news | signals |
+  whales | pipeline | opportunities
+                                      show the daemon's view as JSON
+  get <path>                          GET any API path as JSON
+  help | quit`
+
+// runConsole implements `titan console`, an interactive shell over a running
+// daemon's API. Arguments after the flags run as a single command.
+func runConsole(args []string) error {
+	fs := flag.NewFlagSet("console", flag.ContinueOnError)
+	addr := fs.String("api", "", "daemon API address (defaults to TITAN_API_ADDR)")
+	key := fs.String("key", "", "API key (defaults to TITAN_API_KEY, then TITAN_API_TOKEN)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *addr == "" {
+		*addr = os.Getenv("TITAN_API_ADDR")
+	}
+	if *addr == "" {
+		*addr = "127.0.0.1:8090"
+	}
+	if *key == "" {
+		*key = os.Getenv("TITAN_API_KEY")
+	}
+	if *key == "" {
+		*key = os.Getenv("TITAN_API_TOKEN")
+	}
+	c := &console{client: api.NewClient(*addr, *key), out: os.Stdout}
+
+	if fs.NArg() > 0 {
+		return c.exec(strings.Join(fs.Args(), " "))
+	}
+	if err := c.exec("status"); err != nil {
+		return fmt.Errorf("daemon at %s: %w", *addr, err)
+	}
+	fmt.Println("Type help for commands, quit to leave.")
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print("titan> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			return scanner.Err()
+		}
+		err := c.exec(scanner.Text())
+		if errors.Is(err, errQuit) {
+			return nil
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+		}
+	}
+}
+
+// console runs commands against one daemon
+type console struct {
+	client *api.Client
+	out    io.Writer
+}
+
+// exec runs one command line
+func (c *console) exec(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cmd, args := strings.ToLower(fields[0]), fields[1:]
+	switch cmd {
+	case "help", "?":
+		fmt.Fprintln(c.out, consoleHelp)
+		return nil
+	case "quit", "exit":
+		return errQuit
+	case "status":
+		return c.show(ctx, "/status")
+	case "gas", "minloan", "prices", "volatility", "liquidity", "blackouts", "costs", "canaries", "exposure", "strategies", "scoring", "models", "news", "signals", "whales", "pipeline", "opportunities":
+		return c.show(ctx, "/"+cmd)
+	case "get":
+		if len(args) != 1 {
+			return errors.New("usage: get <path>")
+		}
+		return c.show(ctx, "/"+strings.TrimPrefix(args[0], "/"))
+	case "chains":
+		return c.chains(ctx)
+	case "enable", "disable":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: %s <chain>", cmd)
+		}
+		chainID, err := consoleChain(args[0])
+		if err != nil {
+			return err
+		}
+		var cc api.ChainControl
+		if err := c.client.Do(ctx, http.MethodPost, fmt.Sprintf("/chains/%d", chainID), map[string]bool{"enabled": cmd == "enable"}, &cc); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "✅ %s %sd\n", cc.Name, cmd)
+		return nil
+	case "guardrails":
+		return c.show(ctx, "/guardrails")
+	case "set":
+		return c.setGuardrail(ctx, args)
+	case "changes":
+		return c.changes(ctx, args)
+	case "rollups":
+		if len(args) > 1 {
+			return errors.New("usage: rollups [hours]")
+		}
+		path := "/rollups"
+		if len(args) == 1 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid hours %q", args[0])
+			}
+			path += fmt.Sprintf("?hours=%d", n)
+		}
+		return c.show(ctx, path)
+	case "cost":
+		return c.recordCost(ctx, args)
+	case "quote":
+		return c.quote(ctx, args)
+	case "impact":
+		return c.impact(ctx, args)
+	case "cache":
+		return c.cache(ctx, args)
+	case "tokens":
+		if len(args) < 1 || len(args) > 2 {
+			return errors.New("usage: tokens <chain> [tag]")
+		}
+		chainID, err := consoleChain(args[0])
+		if err != nil {
+			return err
+		}
+		q := url.Values{"chain": {strconv.FormatUint(chainID, 10)}}
+		if len(args) == 2 {
+			q.Set("tag", args[1])
+		}
+		return c.show(ctx, "/tokens?"+q.Encode())
+	case "simulate":
+		return c.simulate(ctx, args)
+	}
+	return fmt.Errorf("unknown command %q (try help)", cmd)
+}
+
+// show prints path's response as indented JSON
+func (c *console) show(ctx context.Context, path string) error {
+	var v json.RawMessage
+	if err := c.client.Get(ctx, path, &v); err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, string(out))
+	return nil
+}
+
+func (c *console) chains(ctx context.Context) error {
+	var chains []api.ChainControl
+	if err := c.client.Get(ctx, "/chains", &chains); err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHAIN\tID\tEXECUTING")
+	for _, cc := range chains {
+		fmt.Fprintf(w, "%s\t%d\t%v\n", cc.Name, cc.ChainID, cc.Enabled)
+	}
+	return w.Flush()
+}
+
+// guardrailFields maps console limit names to their JSON fields
+var guardrailFields = map[string]string{
+	"min-loan-usd":      "minLoanUsd",
+	"max-tvl-share-bps": "maxTvlShareBps",
+	"max-slippage-bps":  "maxSlippageBps",
+	"min-profit-usd":    "minProfitUsd",
+}
+
+func (c *console) setGuardrail(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: set <limit> <value>")
+	}
+	field, ok := guardrailFields[strings.ToLower(args[0])]
+	if !ok {
+		return fmt.Errorf("unknown limit %q: want min-loan-usd, max-tvl-share-bps, max-slippage-bps or min-profit-usd", args[0])
+	}
+	value, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid value %q", args[1])
+	}
+	var g config.Guardrails
+	if err := c.client.Do(ctx, http.MethodPut, "/guardrails", map[string]uint64{field: value}, &g); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "✅ Guardrails: min loan $%d, max TVL share %d bps, max slippage %d bps, min profit $%d\n", g.MinLoanUSD, g.MaxTVLShareBps, g.MaxSlippageBps, g.MinProfitUSD)
+	return nil
+}
+
+func (c *console) recordCost(ctx context.Context, args []string) error {
+	if len(args) != 3 && len(args) != 4 {
+		return errors.New("usage: cost <chain> <approval|bridge|deploy> <usd> [route]")
+	}
+	chainID, err := consoleChain(args[0])
+	if err != nil {
+		return err
+	}
+	usd, err := units.Parse(common.Address{}, args[2], units.USDDecimals)
+	if err != nil {
+		return fmt.Errorf("invalid amount %q", args[2])
+	}
+	cost := opportunity.Cost{Kind: strings.ToLower(args[1]), ChainID: chainID, USD: units.USD(usd.Big().Int64())}
+	if len(args) == 4 {
+		cost.Route = args[3]
+	}
+	if err := c.client.Do(ctx, http.MethodPost, "/costs", cost, &cost); err != nil {
+		return err
+	}
+	scope := "every route on " + enum.ChainID(chainID).Name()
+	if cost.Route != "" {
+		scope = cost.Route
+	}
+	fmt.Fprintf(c.out, "✅ Recorded %s %s cost, amortized over %s\n", cost.USD, cost.Kind, scope)
+	return nil
+}
+
+func (c *console) quote(ctx context.Context, args []string) error {
+	if len(args) != 4 {
+		return errors.New("usage: quote <chain> <in> <out> <amount>|receive=<amount>")
+	}
+	chainID, err := consoleChain(args[0])
+	if err != nil {
+		return err
+	}
+	q := url.Values{
+		"chain": {strconv.FormatUint(chainID, 10)},
+		"in":    {args[1]},
+		"out":   {args[2]},
+	}
+	if receive, ok := strings.CutPrefix(args[3], "receive="); ok {
+		q.Set("receive", receive)
+	} else {
+		q.Set("amount", args[3])
+	}
+	var resp quoteResponse
+	if err := c.client.Get(ctx, "/quote?"+q.Encode(), &resp); err != nil {
+		return err
+	}
+	exact := resp.AmountOut != nil
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	if exact {
+		fmt.Fprintln(w, "ADAPTER\tVENUE\tPAY\tFEE BPS\tGAS\tBLOCK\tLATENCY\tPOOL")
+	} else {
+		fmt.Fprintln(w, "ADAPTER\tVENUE\tRECEIVE\tFEE BPS\tGAS\tBLOCK\tLATENCY\tPOOL")
+	}
+	for _, r := range resp.Results {
+		if r.Error != "" {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\t%dms\terror: %s\n", r.Adapter, r.LatencyMs, r.Error)
+			continue
+		}
+		quote := r.Quote
+		amount := fmt.Sprintf("%s %s", quote.AmountOut, resp.TokenOut.Symbol)
+		if exact && quote.AmountIn != nil {
+			amount = fmt.Sprintf("%s %s", quote.AmountIn, resp.TokenIn.Symbol)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%dms\t%s\n",
+			r.Adapter, quote.Venue, amount, quote.FeeBps, quote.GasUnits, quote.Block, r.LatencyMs, quote.Pool.Hex())
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if resp.Best == nil {
+		return errors.New("no usable quote")
+	}
+	if exact {
+		fmt.Fprintf(c.out, "✅ Best: %s via %s ← %s %s for %s %s\n", resp.Best.Adapter, resp.Best.Venue, resp.Best.AmountIn, resp.TokenIn.Symbol, resp.AmountOut, resp.TokenOut.Symbol)
+		return nil
+	}
+	fmt.Fprintf(c.out, "✅ Best: %s via %s → %s %s\n", resp.Best.Adapter, resp.Best.Venue, resp.Best.AmountOut, resp.TokenOut.Symbol)
+	return nil
+}
+
+func (c *console) impact(ctx context.Context, args []string) error {
+	if len(args) < 4 || len(args) > 5 {
+		return errors.New("usage: impact <chain> <in> <max> <pool>[,<pool>...] [points]")
+	}
+	chainID, err := consoleChain(args[0])
+	if err != nil {
+		return err
+	}
+	q := url.Values{
+		"chain": {strconv.FormatUint(chainID, 10)},
+		"in":    {args[1]},
+		"max":   {args[2]},
+		"pools": {args[3]},
+	}
+	if len(args) == 5 {
+		q.Set("points", args[4])
+	}
+	var curve impact.Curve
+	if err := c.client.Get(ctx, "/impact?"+q.Encode(), &curve); err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PAY\tRECEIVE\tPRICE\tMARGINAL\tIMPACT BPS")
+	for _, p := range curve.Points {
+		fmt.Fprintf(w, "%s %s\t%s %s\t%.6g\t%.6g\t%.1f\n",
+			p.AmountIn, curve.TokenIn.Symbol, p.AmountOut, curve.TokenOut.Symbol, p.Price, p.MarginalPrice, p.ImpactBps)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Spot: %.6g %s per %s across %d pools\n", curve.SpotPrice, curve.TokenOut.Symbol, curve.TokenIn.Symbol, len(curve.Pools))
+	return nil
+}
+
+func (c *console) changes(ctx context.Context, args []string) error {
+	limit := 20
+	if len(args) > 1 {
+		return errors.New("usage: changes [n]")
+	}
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid count %q", args[0])
+		}
+		limit = n
+	}
+	var changes []api.Change
+	if err := c.client.Get(ctx, fmt.Sprintf("/changes?limit=%d", limit), &changes); err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tSOURCE\tACTOR\tSETTING\tOLD\tNEW")
+	for _, ch := range changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", ch.Time.Local().Format(time.DateTime), ch.Source, ch.Actor, ch.Setting, ch.Old, ch.New)
+	}
+	return w.Flush()
+}
+
+func (c *console) cache(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: cache <chain>")
+	}
+	chainID, err := consoleChain(args[0])
+	if err != nil {
+		return err
+	}
+	var snapshots []reserves.Snapshot
+	if err := c.client.Get(ctx, fmt.Sprintf("/cache/reserves?chain=%d", chainID), &snapshots); err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DEX\tKIND\tPOOL\tTOKEN0\tTOKEN1\tRESERVE0\tRESERVE1\tBLOCK\tAGE")
+	for _, s := range snapshots {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			s.Dex, s.Kind, s.Pool.Hex(), s.Token0.Hex(), s.Token1.Hex(), s.Reserve0, s.Reserve1, s.Block, time.Since(s.FetchedAt).Round(time.Second))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "%d pools cached\n", len(snapshots))
+	return nil
+}
+
+func (c *console) simulate(ctx context.Context, args []string) error {
+	if len(args) < 3 {
+		return errors.New("usage: simulate <chain> <to> <data> [from=<addr>] [value=<wei>] [gas=<n>] [profit=<usd>]")
+	}
+	chainID, err := consoleChain(args[0])
+	if err != nil {
+		return err
+	}
+	if !common.IsHexAddress(args[1]) {
+		return fmt.Errorf("invalid address %q", args[1])
+	}
+	data, err := hexutil.Decode(args[2])
+	if err != nil {
+		return fmt.Errorf("invalid calldata: %w", err)
+	}
+	req := simulateRequest{ChainID: chainID, To: common.HexToAddress(args[1]), Data: data}
+	for _, opt := range args[3:] {
+		key, value, ok := strings.Cut(opt, "=")
+		if !ok {
+			return fmt.Errorf("expected key=value, got %q", opt)
+		}
+		switch key {
+		case "from":
+			if !common.IsHexAddress(value) {
+				return fmt.Errorf("invalid address %q", value)
+			}
+			req.From = common.HexToAddress(value)
+		case "value":
+			wei, ok := new(big.Int).SetString(value, 10)
+			if !ok {
+				return fmt.Errorf("invalid value %q", value)
+			}
+			req.Value = (*hexutil.Big)(wei)
+		case "gas":
+			if req.Gas, err = strconv.ParseUint(value, 10, 64); err != nil {
+				return fmt.Errorf("invalid gas %q", value)
+			}
+		case "profit":
+			if req.ExpectedProfitUSD, err = strconv.ParseUint(value, 10, 64); err != nil {
+				return fmt.Errorf("invalid profit %q", value)
+			}
+		default:
+			return fmt.Errorf("unknown option %q", key)
+		}
+	}
+	var res simulation.Result
+	if err := c.client.Do(ctx, http.MethodPost, "/simulate", req, &res); err != nil {
+		return err
+	}
+	status := "✅ OK"
+	if !res.OK {
+		status = "❌ Failed: " + res.Reason
+	}
+	fmt.Fprintf(c.out, "%s at %s depth (requested %s) in %s, gas %d\n", status, res.Depth, res.Requested, res.Elapsed.Round(time.Millisecond), res.GasUsed)
+	if len(res.Output) > 0 {
+		fmt.Fprintf(c.out, "Output: %s\n", hexutil.Encode(res.Output))
+	}
+	return nil
+}
+
+// consoleChain accepts a chain name or numeric ID
+func consoleChain(s string) (uint64, error) {
+	if id, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return id, nil
+	}
+	chain, err := enum.FromName(s)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(chain), nil
+}
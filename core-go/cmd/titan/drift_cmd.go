@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/drift"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/rpc"
+)
+
+// runDrift implements `titan drift`
+func runDrift(args []string) error {
+	fs := flag.NewFlagSet("drift", flag.ContinueOnError)
+	chainName := fs.String("chain", "", "chain name (default: every chain with an RPC)")
+	accept := fs.Bool("accept", false, "accept current on-chain code as the new baseline")
+	timeout := fs.Duration("timeout", 2*time.Minute, "overall check timeout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	chains := rpcChains(cfg)
+	if *chainName != "" {
+		chain, err := enum.FromName(*chainName)
+		if err != nil {
+			return err
+		}
+		chains = []uint64{uint64(chain)}
+	}
+
+	providers := rpc.NewRouter(cfg, rpc.Dialer(cfg), nil)
+	defer providers.Close()
+	checker, err := newDriftChecker(cfg, providers)
+	if err != nil {
+		return err
+	}
+	targets := driftTargets(cfg, chains)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if *accept {
+		if err := checker.AcceptAll(ctx, targets); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Accepted %d contracts into the baseline\n", len(targets))
+		return nil
+	}
+
+	findings, err := checker.Check(ctx, targets)
+	if len(findings) == 0 && err == nil {
+		fmt.Printf("✅ %d configured contracts match on-chain state\n", len(targets))
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHAIN\tNAME\tADDRESS\tFINDING\tDETAIL\tSUGGESTION")
+	for _, f := range findings {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", f.Target.ChainID, f.Target.Name, f.Target.Address.Hex(), f.Kind, f.Detail, f.Suggestion)
+	}
+	w.Flush()
+	return err
+}
+
+// newDriftChecker creates a checker over the data directory's code hash baseline
+func newDriftChecker(cfg *config.Config, providers *rpc.Router) (*drift.Checker, error) {
+	baseline, err := drift.LoadBaseline(filepath.Join(cfg.DataDir, "codehashes.json"))
+	if err != nil {
+		return nil, err
+	}
+	return drift.NewChecker(baseline, func(chainID uint64) (chain.ChainReader, error) {
+		return providers.Client(chainID, rpc.PriorityLow)
+	}), nil
+}
+
+// driftTargets lists the configured contracts on the given chains
+func driftTargets(cfg *config.Config, chains []uint64) []drift.Target {
+	wanted := make(map[uint64]bool, len(chains))
+	for _, id := range chains {
+		wanted[id] = true
+	}
+	var out []drift.Target
+	for _, t := range drift.TargetsFromConfig(cfg) {
+		if wanted[t.ChainID] {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/commander"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/execution"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/pipeline"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/route"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/rpc"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/simulation"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// runExecute implements `titan execute`: an operator-specified route goes
+// through the same sizing guardrails, repayment check and simulation as
+// automated trades, then is submitted once the operator confirms
+func runExecute(args []string) error {
+	fs := flag.NewFlagSet("execute", flag.ContinueOnError)
+	chainName := fs.String("chain", "", "chain name (e.g. polygon)")
+	routePath := fs.String("route", "", "route JSON file")
+	lender := fs.String("flash", "balancer", "flash-loan lender: balancer or aave")
+	executor := fs.String("executor", "", "executor contract (defaults to EXECUTOR_ADDRESS_<CHAIN>)")
+	yes := fs.Bool("yes", false, "submit without prompting")
+	dryRun := fs.Bool("dry-run", false, "stop after simulation")
+	from := fs.String("from", quotePlaceholderSender, "sender simulated in watch-only mode, where no key is loaded")
+	wait := fs.Duration("wait", 2*time.Minute, "how long to wait for the receipt; 0 returns after submission")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *chainName == "" || *routePath == "" {
+		fs.Usage()
+		return fmt.Errorf("--chain and --route are required")
+	}
+	network, err := enum.FromName(*chainName)
+	if err != nil {
+		return err
+	}
+	chainID := uint64(network)
+	source, err := route.ParseFlashSource(*lender)
+	if err != nil {
+		return err
+	}
+	r, err := loadTrade(*routePath)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	chainCfg, ok := cfg.GetChain(chainID)
+	if !ok || chainCfg.RPC == "" {
+		return fmt.Errorf("no RPC configured for %s", network.Name())
+	}
+	if *executor == "" {
+		*executor = os.Getenv("EXECUTOR_ADDRESS_" + strings.ToUpper(network.Name()))
+	}
+	if !common.IsHexAddress(*executor) {
+		return fmt.Errorf("invalid executor address %q (set --executor or EXECUTOR_ADDRESS_%s)", *executor, strings.ToUpper(network.Name()))
+	}
+	executorAddr := common.HexToAddress(*executor)
+	if cfg.WatchOnly && !common.IsHexAddress(*from) {
+		return fmt.Errorf("invalid --from address %q", *from)
+	}
+	// Watch-only runs evaluate the route without a signer
+	var key *ecdsa.PrivateKey
+	sender := common.HexToAddress(*from)
+	if !cfg.WatchOnly {
+		privateKey, err := cfg.StateKeys.OpenString(os.Getenv("PRIVATE_KEY"))
+		if err != nil {
+			return fmt.Errorf("PRIVATE_KEY: %w", err)
+		}
+		if key, err = crypto.HexToECDSA(strings.TrimPrefix(privateKey, "0x")); err != nil {
+			return fmt.Errorf("PRIVATE_KEY: %w", err)
+		}
+		sender = execution.Sender(key)
+	}
+	registry, err := tokens.FromConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	node, err := rpc.Dial(ctx, cfg, chainCfg.RPC)
+	if err != nil {
+		return fmt.Errorf("%s: %w", network.Name(), err)
+	}
+	defer node.Close()
+	var client chain.Client = ethclient.NewClient(node)
+	if code, err := client.CodeAt(ctx, executorAddr, nil); err != nil {
+		return err
+	} else if len(code) == 0 {
+		return fmt.Errorf("no contract at executor %s on %s", executorAddr.Hex(), network.Name())
+	}
+
+	// OP-stack calldata pays an L1 data fee, reported with the route
+	var l1 *route.L1Fee
+	if execution.OPStack(chainID) {
+		if fee, err := execution.EcotoneL1Fee(ctx, node); err != nil {
+			fmt.Printf("⚠️  L1 fee: %v\n", err)
+		} else {
+			l1 = &fee
+		}
+	}
+
+	// Loans are sized against the lender the trade borrows from
+	sizer := commander.NewFromConfig(chainID, client, cfg.Guardrails)
+	if source == route.FlashAave {
+		if !common.IsHexAddress(chainCfg.AavePool) {
+			return fmt.Errorf("no Aave pool configured for %s", network.Name())
+		}
+		sizer.SizeAgainstAave(common.HexToAddress(chainCfg.AavePool))
+	}
+	data, paper, err := prepareRoute(sizer, registry, network, source, r, l1)
+	if err != nil {
+		return err
+	}
+	// Without a liquidity read the TVL guardrail never applied, so the trade
+	// is evaluated but not sent
+	if paper && !*dryRun {
+		fmt.Printf("📝 Paper mode: no %s liquidity read on %s, not submitting\n", source.Name(), network.Name())
+		*dryRun = true
+	}
+
+	// The trade is tracked like automated ones, so a replayed plan or reused
+	// nonce is refused and the outcome lands in the pipeline log
+	lifecycle, err := pipeline.OpenSealed(filepath.Join(cfg.DataDir, "pipeline.jsonl"), metrics.Default, cfg.StateKeys)
+	if err != nil {
+		return err
+	}
+	defer lifecycle.Close()
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	fingerprint := crypto.Keccak256Hash(data)
+	id := fmt.Sprintf("execute-%d-%s", time.Now().Unix(), fingerprint.Hex()[2:10])
+	if err := lifecycle.StartAt(id, chainID, head); err != nil {
+		return err
+	}
+	fail := func(reason failure.Reason, err error) error {
+		if ferr := lifecycle.FailAs(id, reason, err.Error()); ferr != nil {
+			log.Printf("⚠️ Pipeline: %v", ferr)
+		}
+		return err
+	}
+	if err := lifecycle.Advance(id, pipeline.StageScored, "operator route"); err != nil {
+		return err
+	}
+	if err := lifecycle.Advance(id, pipeline.StageSized, fmt.Sprintf("borrow from %s", source.Name())); err != nil {
+		return err
+	}
+
+	runner, err := simulation.RunnerFromConfig(ctx, cfg, chainID, client)
+	if err != nil {
+		return fail(failure.RPCError, err)
+	}
+	res, err := runner.Simulate(ctx, simulation.Candidate{
+		ChainID: chainID,
+		Bundle:  []simulation.Call{{From: sender, To: executorAddr, Data: data}},
+	})
+	if err != nil {
+		return fail(failure.RPCError, err)
+	}
+	if res.Depth == simulation.DepthQuote {
+		return fail(failure.SimulationRevert, errors.New("simulation: no node simulation available for this chain"))
+	}
+	if !res.OK {
+		return fail(failure.SimulationRevert, fmt.Errorf("simulation failed at %s depth: %s", res.Depth, res.Reason))
+	}
+	fmt.Printf("🧪 Simulation: OK at %s depth, gas %d\n", res.Depth, res.GasUsed)
+	if err := lifecycle.Advance(id, pipeline.StageSimulated, fmt.Sprintf("OK at %s depth", res.Depth)); err != nil {
+		return err
+	}
+	if cfg.WatchOnly {
+		fmt.Println("👀 Watch-only: not submitting")
+		return lifecycle.Observe(id, "watch-only operator route")
+	}
+
+	adapter, err := execution.FromConfig(chainID, chainCfg)
+	if err != nil {
+		return fail(failure.Unknown, err)
+	}
+	call := execution.Call{ChainID: chainID, From: sender, To: executorAddr, Data: data}
+	if call.GasLimit, err = adapter.EstimateGas(ctx, node, call); err != nil {
+		return fail(failure.Classify(err.Error()), err)
+	}
+	fees := gas.NewOracle(nil,
+		gas.NewGasStationSource(gas.GasStationsFromConfig(cfg)),
+		gas.NewNodeSource(func(uint64) (chain.Client, error) { return client, nil }),
+	)
+	if call.Fees, err = fees.Suggest(ctx, chainID); err != nil {
+		return fail(failure.RPCError, fmt.Errorf("fees: %w", err))
+	}
+	if call.Nonce, err = client.PendingNonceAt(ctx, sender); err != nil {
+		return fail(failure.RPCError, err)
+	}
+	cost, err := adapter.MaxCost(ctx, node, call)
+	if err != nil {
+		return fail(failure.RPCError, err)
+	}
+	if err := execution.CheckBalance(ctx, node, sender, cost); err != nil {
+		return fail(failure.GuardrailFloor, err)
+	}
+	fmt.Printf("⛽ Gas limit %d, max fee %s wei (%s), nonce %d from %s\n\n", call.GasLimit, call.Fees.FeeCap, call.Fees.Source, call.Nonce, sender.Hex())
+
+	if *dryRun {
+		fmt.Println("🏁 Dry run: not submitting")
+		return lifecycle.Observe(id, "dry run")
+	}
+	if !*yes && !confirm(fmt.Sprintf("Submit this trade on %s?", network.Name())) {
+		if err := lifecycle.Abandon(id, "aborted by operator"); err != nil {
+			log.Printf("⚠️ Pipeline: %v", err)
+		}
+		return errors.New("aborted by operator")
+	}
+	raw, hash, err := adapter.Sign(call, key)
+	if err != nil {
+		return fail(failure.Unknown, err)
+	}
+	relay, err := execution.RelayFromConfig(ctx, chainID, chainCfg, execution.RelayOptions{
+		Public: node,
+		Dial:   func(url string) (execution.RPC, error) { return rpc.Dial(ctx, cfg, url) },
+	})
+	if err != nil {
+		return fail(failure.RPCError, err)
+	}
+	// Recorded before broadcast, so a crash never leaves an untracked
+	// transaction and a plan or nonce already in flight is refused
+	if head, err = client.BlockNumber(ctx); err != nil {
+		return fail(failure.RPCError, err)
+	}
+	if err := lifecycle.Submit(id, pipeline.TxRef{Hash: hash, From: sender, Nonce: call.Nonce, Fingerprint: fingerprint, Block: head}); err != nil {
+		if errors.Is(err, pipeline.ErrDuplicate) {
+			return fail(failure.Duplicate, err)
+		}
+		return err
+	}
+	if err := relay.Submit(ctx, raw, hash, nil); err != nil {
+		return fail(failure.RPCError, fmt.Errorf("submit via %s: %w", relay.Name(), err))
+	}
+	fmt.Printf("🚀 Submitted %s via %s\n", hash.Hex(), relay.Name())
+	if *wait <= 0 {
+		return nil
+	}
+	receipt, err := waitReceipt(ctx, client, hash, *wait)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fail(failure.Reverted, fmt.Errorf("transaction %s reverted in block %d", hash.Hex(), receipt.BlockNumber))
+	}
+	fmt.Printf("✅ Included in block %d, gas used %d\n", receipt.BlockNumber, receipt.GasUsed)
+	return lifecycle.Advance(id, pipeline.StageConfirmed, fmt.Sprintf("included in block %d", receipt.BlockNumber))
+}
+
+// prepareRoute sizes a single-token route against the lender's liquidity,
+// checks it repays the loan and returns its execute calldata, reporting its
+// L1 data fee when l1 prices the chain's calldata. paper reports that no lender liquidity
+// could be read, so the TVL guardrail was not applied.
+func prepareRoute(sizer *commander.TitanCommander, registry *tokens.Registry, chain enum.ChainID, source route.FlashSource, r *route.Route, l1 *route.L1Fee) (data []byte, paper bool, err error) {
+	tokenIn, err := registry.Lookup(uint64(chain), r.TokenIn.Hex())
+	if err != nil {
+		return nil, false, err
+	}
+	amountIn, err := units.FromBig(tokenIn.Address, r.AmountIn, tokenIn.Decimals)
+	if err != nil {
+		return nil, false, err
+	}
+	fmt.Printf("📄 Route on %s: borrow %s %s from %s, %d steps via %s\n\n", chain.Name(), amountIn, tokenIn.Symbol, source.Name(), len(r.Steps), adapterPath(r))
+
+	// Sizing: the same guardrails as automated trades, scaling the route down
+	// when the lender can't safely cover it
+	decision, err := sizer.SizeLoan(r.TokenIn, r.AmountIn, tokenIn.Decimals)
+	if err != nil {
+		return nil, false, fmt.Errorf("sizing: %w", err)
+	}
+	if decision.Amount.Sign() == 0 {
+		return nil, false, fmt.Errorf("sizing rejected the trade (bound by %s)", decision.BoundBy)
+	}
+	paper = decision.PaperMode
+	if paper {
+		fmt.Printf("📝 Sizing: no %s liquidity for %s, the TVL cap was not applied\n", source.Name(), tokenIn.Symbol)
+	} else if decision.Amount.Cmp(r.AmountIn) != 0 {
+		r = r.Scale(decision.Amount)
+		sized, _ := units.FromBig(tokenIn.Address, decision.Amount, tokenIn.Decimals)
+		fmt.Printf("🛡️  Sizing: scaled to %s %s (bound by %s)\n", sized, tokenIn.Symbol, decision.BoundBy)
+	} else {
+		fmt.Printf("🛡️  Sizing: %s %s within guardrails\n", amountIn, tokenIn.Symbol)
+	}
+
+	// Safety: the executor must revert rather than lose principal
+	if err := r.CheckRepayable(source); err != nil {
+		return nil, false, err
+	}
+	data, err = route.EncodeExecute(source, r)
+	if err != nil {
+		return nil, false, err
+	}
+	fmt.Printf("✅ Safety: route returns to %s with minOut covering the loan\n", tokenIn.Symbol)
+	// RAW_ADDRESSES routeData carries no amounts, so there is nothing to compact
+	if l1 != nil {
+		fmt.Printf("🗜️  Calldata: %d bytes, L1 data fee %s wei\n", len(data), l1.Cost(data))
+	}
+	return data, paper, nil
+}
+
+// loadTrade reads and validates a route JSON file. UniV2 and UniV3 steps
+// name the router the executor calls in "router"; Curve steps name their
+// pool in "pool". Multi-token plans, with a "legs" list, are refused: the
+// executor has no executeMulti entry point.
+func loadTrade(path string) (*route.Route, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var probe struct {
+		Legs json.RawMessage `json:"legs"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if probe.Legs != nil {
+		return nil, fmt.Errorf("%s: %w", path, route.ErrNoExecuteMulti)
+	}
+	var r route.Route
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := r.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if r.AmountIn == nil || r.AmountIn.Sign() == 0 {
+		return nil, fmt.Errorf("%s: amountIn required", path)
+	}
+	return &r, nil
+}
+
+// adapterPath names each hop's adapter, e.g. univ2>univ3
+func adapterPath(r *route.Route) string {
+	names := make([]string, len(r.Steps))
+	for i, s := range r.Steps {
+		names[i] = s.Adapter.Name()
+	}
+	return strings.Join(names, ">")
+}
+
+// confirm asks a yes/no question on the terminal, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// waitReceipt polls for hash's receipt until it lands or wait elapses
+func waitReceipt(ctx context.Context, client chain.TxReader, hash common.Hash, wait time.Duration) (*types.Receipt, error) {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		receipt, err := client.TransactionReceipt(ctx, hash)
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			log.Printf("⚠️ Receipt lookup: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no receipt for %s after %s; check it before retrying", hash.Hex(), wait)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/execution"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/executor"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/rpc"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// runExecutor implements `titan executor status|pause|unpause|transfer-ownership|rescue`.
+// Owner calls are confirmed on the terminal and recorded in the audit log
+// whether sent, declined or failed.
+func runExecutor(args []string) error {
+	usage := fmt.Errorf("usage: titan executor status|pause|unpause --chain <chain> | transfer-ownership --chain <chain> --to <address> | rescue --chain <chain> --token <symbol|address>")
+	if len(args) == 0 {
+		return usage
+	}
+	action := args[0]
+	switch action {
+	case "status", executor.ActionPause, executor.ActionUnpause, executor.ActionTransferOwnership, executor.ActionRescue:
+	default:
+		return usage
+	}
+
+	fs := flag.NewFlagSet("executor "+action, flag.ContinueOnError)
+	chainName := fs.String("chain", "", "chain name (e.g. polygon)")
+	address := fs.String("executor", "", "executor address (defaults to EXECUTOR_ADDRESS_<CHAIN>)")
+	to := fs.String("to", "", "new owner, for transfer-ownership")
+	token := fs.String("token", "", "token to rescue, by symbol or address")
+	yes := fs.Bool("yes", false, "send without confirmation")
+	wait := fs.Duration("wait", 2*time.Minute, "how long to wait for the receipt; 0 returns once submitted")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *chainName == "" {
+		fs.Usage()
+		return fmt.Errorf("--chain is required")
+	}
+	network, err := enum.FromName(*chainName)
+	if err != nil {
+		return err
+	}
+	chainID := uint64(network)
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	chainCfg, ok := cfg.GetChain(chainID)
+	if !ok || chainCfg.RPC == "" {
+		return fmt.Errorf("no RPC configured for %s", network.Name())
+	}
+	if *address == "" {
+		*address = os.Getenv("EXECUTOR_ADDRESS_" + strings.ToUpper(network.Name()))
+	}
+	if !common.IsHexAddress(*address) {
+		return fmt.Errorf("invalid executor address %q (set --executor or EXECUTOR_ADDRESS_%s)", *address, strings.ToUpper(network.Name()))
+	}
+
+	ctx := context.Background()
+	node, err := rpc.Dial(ctx, cfg, chainCfg.RPC)
+	if err != nil {
+		return fmt.Errorf("%s: %w", network.Name(), err)
+	}
+	defer node.Close()
+	client := ethclient.NewClient(node)
+	contract := executor.New(chainID, common.HexToAddress(*address), client)
+	owner, err := contract.Owner(ctx)
+	if err != nil {
+		return err
+	}
+	paused, err := contract.Paused(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🏦 Executor %s on %s\n", contract.Address().Hex(), network.Name())
+	fmt.Printf("   Owner   %s\n", owner.Hex())
+	fmt.Printf("   Paused  %v\n", paused)
+	if action == "status" {
+		return nil
+	}
+	if cfg.WatchOnly {
+		return errors.New("watch-only: not sending owner calls")
+	}
+	key, err := openKey(cfg, "PRIVATE_KEY", os.Getenv("PRIVATE_KEY"))
+	if err != nil {
+		return err
+	}
+	sender := execution.Sender(key)
+	if sender != owner {
+		return fmt.Errorf("signer %s is not the executor's owner %s", sender.Hex(), owner.Hex())
+	}
+
+	// Build the call and the question the operator answers
+	var call execution.Call
+	var target, question string
+	switch action {
+	case executor.ActionPause:
+		if paused {
+			return errors.New("the executor is already paused")
+		}
+		question = "Pause the executor? Every trade through it will revert until it is unpaused."
+		call, err = contract.Pause(sender)
+	case executor.ActionUnpause:
+		if !paused {
+			return errors.New("the executor is not paused")
+		}
+		question = "Unpause the executor and resume trading?"
+		call, err = contract.Unpause(sender)
+	case executor.ActionTransferOwnership:
+		if !common.IsHexAddress(*to) {
+			return fmt.Errorf("invalid new owner %q", *to)
+		}
+		next := common.HexToAddress(*to)
+		if next == (common.Address{}) || next == owner {
+			return fmt.Errorf("refusing to transfer ownership to %s", next.Hex())
+		}
+		target = next.Hex()
+		question = fmt.Sprintf("Transfer ownership to %s? This signer loses control of the executor, irreversibly.", target)
+		call, err = contract.TransferOwnership(sender, next)
+	case executor.ActionRescue:
+		registry, err := tokens.FromConfig(cfg)
+		if err != nil {
+			return err
+		}
+		t, err := registry.Lookup(chainID, *token)
+		if err != nil {
+			return err
+		}
+		balance, err := contract.Balance(ctx, t.Address)
+		if err != nil {
+			return err
+		}
+		if balance.Sign() == 0 {
+			return fmt.Errorf("the executor holds no %s", t.Symbol)
+		}
+		amount, err := units.FromBig(t.Address, balance, t.Decimals)
+		if err != nil {
+			return err
+		}
+		target = t.Address.Hex()
+		question = fmt.Sprintf("Sweep %s %s from the executor to %s?", amount, t.Symbol, owner.Hex())
+		call, err = contract.Rescue(sender, t.Address)
+		if err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	audit, err := journal.OpenSealed(filepath.Join(cfg.DataDir, "audit.jsonl"), cfg.StateKeys)
+	if err != nil {
+		return err
+	}
+	defer audit.Close()
+	record := executor.Action{Time: time.Now().UTC(), ChainID: chainID, Executor: contract.Address(), Action: action, Target: target, Sender: sender}
+	finish := func(status string, err error) error {
+		record.Status = status
+		if err != nil {
+			record.Error = err.Error()
+		}
+		if aerr := audit.Append(journal.KindAdmin, record); aerr != nil {
+			log.Printf("⚠️ Audit log: %v", aerr)
+		}
+		return err
+	}
+
+	adapter, err := execution.FromConfig(chainID, chainCfg)
+	if err != nil {
+		return finish(executor.StatusFailed, err)
+	}
+	// Estimation runs the call, so one the contract would reject stops here
+	if call.GasLimit, err = adapter.EstimateGas(ctx, node, call); err != nil {
+		return finish(executor.StatusFailed, err)
+	}
+	providers := rpc.NewRouter(cfg, rpc.Dialer(cfg), nil)
+	defer providers.Close()
+	if call.Fees, err = newGasOracle(cfg, providers).Suggest(ctx, chainID); err != nil {
+		return finish(executor.StatusFailed, fmt.Errorf("fees: %w", err))
+	}
+	if call.Nonce, err = client.PendingNonceAt(ctx, sender); err != nil {
+		return finish(executor.StatusFailed, err)
+	}
+	fmt.Printf("\n⛽ Gas limit %d, max fee %s wei (%s), nonce %d from %s\n\n", call.GasLimit, call.Fees.FeeCap, call.Fees.Source, call.Nonce, sender.Hex())
+	if !*yes && !confirm(question) {
+		return finish(executor.StatusDeclined, errors.New("aborted by operator"))
+	}
+
+	raw, hash, err := adapter.Sign(call, key)
+	if err != nil {
+		return finish(executor.StatusFailed, err)
+	}
+	record.TxHash = hash
+	if err := execution.Broadcast(ctx, node, raw, hash); err != nil {
+		return finish(executor.StatusFailed, err)
+	}
+	fmt.Printf("🚀 Submitted %s\n", hash.Hex())
+	if *wait <= 0 {
+		return finish(executor.StatusSubmitted, nil)
+	}
+	receipt, err := waitReceipt(ctx, client, hash, *wait)
+	if err != nil {
+		return finish(executor.StatusSubmitted, err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return finish(executor.StatusReverted, fmt.Errorf("transaction %s reverted in block %d", hash.Hex(), receipt.BlockNumber))
+	}
+	fmt.Printf("✅ %s included in block %d\n", action, receipt.BlockNumber)
+	return finish(executor.StatusConfirmed, nil)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/report"
+)
+
+// runExplain implements `titan explain`: the stored score breakdown of one
+// journaled opportunity, or every breakdown since a cutoff as JSON lines
+// labeled with execution outcomes for retraining
+func runExplain(args []string) error {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	journalPath := fs.String("journal", "", "journal file (defaults to <data dir>/journal.jsonl)")
+	asJSON := fs.Bool("json", false, "print the opportunity as JSON")
+	export := fs.String("export", "", "write labeled breakdowns as JSON lines to this file, or - for stdout")
+	since := fs.Duration("since", 7*24*time.Hour, "how far back --export reaches")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *export == "" && fs.NArg() != 1 {
+		return fmt.Errorf("usage: titan explain <opportunity id> | --export <file> [--since 168h]")
+	}
+
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	if *journalPath == "" {
+		*journalPath = filepath.Join(cfg.DataDir, "journal.jsonl")
+	}
+	entries, err := journal.ReadSealed(*journalPath, "", cfg.StateKeys)
+	if err != nil {
+		return err
+	}
+
+	if *export != "" {
+		labels, err := report.Labels(entries, time.Now().Add(-*since))
+		if err != nil {
+			return err
+		}
+		var out io.Writer = os.Stdout
+		if *export != "-" {
+			f, err := os.Create(*export)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		enc := json.NewEncoder(out)
+		for _, l := range labels {
+			if err := enc.Encode(l); err != nil {
+				return err
+			}
+		}
+		if *export != "-" {
+			fmt.Printf("Wrote %d labeled breakdowns to %s\n", len(labels), *export)
+		}
+		return nil
+	}
+
+	id := fs.Arg(0)
+	var found *opportunity.Opportunity
+	for _, e := range entries {
+		if e.Kind != journal.KindOpportunity {
+			continue
+		}
+		var o opportunity.Opportunity
+		if err := json.Unmarshal(e.Data, &o); err != nil {
+			return fmt.Errorf("bad opportunity entry at %s: %w", e.Time, err)
+		}
+		if o.ID == id {
+			found = &o
+		}
+	}
+	if found == nil || found.Explanation == nil {
+		return fmt.Errorf("opportunity %s not found in %s", id, *journalPath)
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(found)
+	}
+	fmt.Printf("Opportunity %s (%s, chain %d)\n", found.ID, found.Strategy, found.ChainID)
+	fmt.Print(found.Explanation.Text())
+	return nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/dex"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// defaultReserveCachePath is where CLI commands persist discovered pool state
+const defaultReserveCachePath = "data/reserves_cache.json"
+
+// runLiquidity implements `titan liquidity`
+func runLiquidity(args []string) error {
+	fs := flag.NewFlagSet("liquidity", flag.ContinueOnError)
+	chainName := fs.String("chain", "", "chain name (e.g. polygon)")
+	pair := fs.String("pair", "", "token pair as BASE/QUOTE (e.g. WETH/USDC; native symbols resolve to the wrapped token)")
+	sizes := fs.String("sizes", "", "comma-separated trade sizes in whole BASE units (default depends on BASE)")
+	cachePath := fs.String("cache", defaultReserveCachePath, "reserve cache file")
+	maxAge := fs.Duration("max-age", 30*time.Second, "maximum cached snapshot age before re-discovery")
+	refresh := fs.Bool("refresh", false, "ignore the cache and re-discover pools")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *chainName == "" || *pair == "" {
+		fs.Usage()
+		return fmt.Errorf("--chain and --pair are required")
+	}
+
+	chain, err := enum.FromName(*chainName)
+	if err != nil {
+		return err
+	}
+	chainID := uint64(chain)
+	baseSym, quoteSym, ok := strings.Cut(*pair, "/")
+	if !ok {
+		return fmt.Errorf("invalid pair %q, expected BASE/QUOTE", *pair)
+	}
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	registry, err := tokens.FromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	base, err := registry.Lookup(chainID, baseSym)
+	if err != nil {
+		return err
+	}
+	quote, err := registry.Lookup(chainID, quoteSym)
+	if err != nil {
+		return err
+	}
+
+	sizeList := defaultSizes(base.Symbol)
+	if *sizes != "" {
+		if sizeList, err = parseIntList(*sizes); err != nil {
+			return err
+		}
+	}
+
+	cache := reserves.NewCache()
+	if err := cache.Load(*cachePath); err != nil {
+		log.Printf("⚠️ Ignoring reserve cache: %v", err)
+	}
+
+	snapshots := cache.Pair(chainID, base.Address, quote.Address, *maxAge)
+	source := "cache"
+	if *refresh || len(snapshots) == 0 {
+		chainCfg, ok := cfg.GetChain(chainID)
+		if !ok || chainCfg.RPC == "" {
+			return fmt.Errorf("no RPC configured for %s", chain.Name())
+		}
+		provider, err := enum.NewProviderManager().GetProvider(chainID, chainCfg.RPC)
+		if err != nil {
+			return err
+		}
+
+		for _, err := range dex.DetectRouterTypes(context.Background(), provider, cfg.DexRouters[chainID]) {
+			log.Printf("⚠️ Router detection: %v", err)
+		}
+		found, errs := dex.NewDiscovery(chainID, provider).DiscoverPair(
+			context.Background(), dex.RoutersFromConfig(cfg, chainID), base.Address, quote.Address)
+		for _, err := range errs {
+			log.Printf("⚠️ Discovery: %v", err)
+		}
+		for _, s := range found {
+			cache.Put(s)
+		}
+		if err := cache.Save(*cachePath); err != nil {
+			log.Printf("⚠️ Failed to save reserve cache: %v", err)
+		}
+		snapshots = cache.Pair(chainID, base.Address, quote.Address, 0)
+		source = "chain"
+	}
+
+	fmt.Printf("💧 %s/%s liquidity on %s (%d pools, source: %s)\n\n", base.Symbol, quote.Symbol, chain.Name(), len(snapshots), source)
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := []string{"DEX", "KIND", "POOL", "FEE BPS", base.Symbol, quote.Symbol, "BLOCK"}
+	for _, size := range sizeList {
+		header = append(header, fmt.Sprintf("IMPACT@%d", size))
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for _, s := range snapshots {
+		baseReserve, quoteReserve := s.Reserve0, s.Reserve1
+		if s.Token0 != base.Address {
+			baseReserve, quoteReserve = s.Reserve1, s.Reserve0
+		}
+		row := []string{
+			s.Dex, s.Kind, s.Pool.Hex(), fmt.Sprint(s.FeeBps),
+			formatReserve(base, baseReserve), formatReserve(quote, quoteReserve),
+			fmt.Sprint(s.Block),
+		}
+		for _, size := range sizeList {
+			row = append(row, impactCell(s, base, size))
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// defaultSizes picks standard trade sizes: dollar-like sizes for stables, unit sizes otherwise
+func defaultSizes(symbol string) []int {
+	if strings.Contains(symbol, "USD") || symbol == "DAI" {
+		return []int{1_000, 10_000, 100_000}
+	}
+	return []int{1, 10, 100}
+}
+
+func formatReserve(t tokens.Token, raw *big.Int) string {
+	amount, err := units.FromBig(t.Address, raw, t.Decimals)
+	if err != nil {
+		return raw.String()
+	}
+	return amount.String()
+}
+
+func impactCell(s *reserves.Snapshot, base tokens.Token, size int) string {
+	amountIn, err := units.FromWhole(base.Address, uint64(size), base.Decimals)
+	if err != nil {
+		return "-"
+	}
+	bps, err := s.PriceImpactBps(base.Address, amountIn.Big())
+	if err != nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.2f%%", float64(bps)/100)
+}
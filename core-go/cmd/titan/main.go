@@ -6,21 +6,35 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	
 	"github.com/joho/godotenv"
-	"github.com/vegas-max/Titan2.0/core-go/config"
-	"github.com/vegas-max/Titan2.0/core-go/enum"
-	"github.com/vegas-max/Titan2.0/core-go/commander"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/commander"
 )
 
 const version = "0.1.0"
 
 func main() {
+	// go-ethereum's log package installs a discarding slog default at init,
+	// which also swallows the standard logger; send it back to stderr
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.LstdFlags)
+	
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using system environment variables")
 	}
 	
+	// Subcommands (titan bench, ...) run instead of the default startup
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("%s: %v", os.Args[1], err)
+		}
+		return
+	}
+	
 	fmt.Printf("🚀 Titan Core (Go) v%s\n", version)
 	fmt.Println("=" + string(make([]byte, 50)) + "=")
 	
@@ -46,11 +60,11 @@ func main() {
 		if err != nil {
 			log.Printf("Failed to connect to Polygon: %v", err)
 		} else {
-			cmd := commander.New(uint64(enum.Polygon), provider)
+			cmd := commander.NewFromConfig(uint64(enum.Polygon), provider, cfg.Guardrails)
 			fmt.Printf("✅ Commander initialized for chain %d\n", cmd.ChainID())
 			fmt.Printf("   Min Loan USD: $%d\n", cmd.MinLoanUSD)
-			fmt.Printf("   Max TVL Share: %.1f%%\n", cmd.MaxTVLShare*100)
-			fmt.Printf("   Slippage Tolerance: %.2f%%\n", (1-cmd.SlippageTolerance)*100)
+			fmt.Printf("   Max TVL Share: %d bps\n", cmd.MaxTVLShareBps)
+			fmt.Printf("   Slippage Tolerance: %d bps\n", cmd.MaxSlippageBps)
 		}
 	}
 	
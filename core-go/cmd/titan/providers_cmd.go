@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/rpc"
+)
+
+// runProviders implements `titan providers report`
+func runProviders(args []string) error {
+	if len(args) == 0 || args[0] != "report" {
+		return fmt.Errorf("usage: titan providers report [--since 720h] [--chain <chain>] [--json]")
+	}
+
+	fs := flag.NewFlagSet("providers report", flag.ContinueOnError)
+	since := fs.Duration("since", 30*24*time.Hour, "how far back to rank providers over")
+	chainName := fs.String("chain", "", "chain name (default: every chain)")
+	asJSON := fs.Bool("json", false, "print the ranking as JSON")
+	historyPath := fs.String("history", "", "provider history file (defaults to <data dir>/providers.jsonl)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	if *historyPath == "" {
+		*historyPath = filepath.Join(cfg.DataDir, "providers.jsonl")
+	}
+	windows, err := rpc.ReadHistory(*historyPath, cfg.StateKeys, time.Now().Add(-*since))
+	if err != nil {
+		return err
+	}
+	if *chainName != "" {
+		chain, err := enum.FromName(*chainName)
+		if err != nil {
+			return err
+		}
+		kept := windows[:0]
+		for _, w := range windows {
+			if w.ChainID == uint64(chain) {
+				kept = append(kept, w)
+			}
+		}
+		windows = kept
+	}
+	ranked := rpc.Rank(windows)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(ranked)
+	}
+	if len(ranked) == 0 {
+		fmt.Printf("No provider history in %s over the last %s\n", *historyPath, *since)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHAIN\tRANK\tPROVIDER\tTIER\tCALLS\tUPTIME\tERROR RATE\tMEAN\tP50\tP95\tERRORS")
+	rank := 0
+	for i, p := range ranked {
+		if i == 0 || ranked[i-1].ChainID != p.ChainID {
+			rank = 0
+		}
+		rank++
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%d\t%.3f%%\t%.2f%%\t%s\t%s\t%s\t%s\n",
+			enum.ChainID(p.ChainID).Name(), rank, p.Provider, p.Tier, p.Calls,
+			p.Uptime()*100, p.ErrorRate()*100, p.MeanLatency(), p.Quantile(0.5), p.Quantile(0.95), errorTypes(p.Errors))
+	}
+	return w.Flush()
+}
+
+// errorTypes renders error counts by type, most frequent first, e.g.
+// "timeout 12, rate_limit 3"
+func errorTypes(counts map[string]uint64) string {
+	if len(counts) == 0 {
+		return "-"
+	}
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if counts[types[i]] != counts[types[j]] {
+			return counts[types[i]] > counts[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	parts := make([]string, len(types))
+	for i, t := range types {
+		parts[i] = fmt.Sprintf("%s %d", t, counts[t])
+	}
+	return strings.Join(parts, ", ")
+}
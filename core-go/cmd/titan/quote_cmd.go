@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/dex"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/freshness"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/quotes"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// runQuote implements `titan quote`
+func runQuote(args []string) error {
+	fs := flag.NewFlagSet("quote", flag.ContinueOnError)
+	chainName := fs.String("chain", "", "chain name (e.g. polygon)")
+	in := fs.String("in", "", "input token symbol or address")
+	out := fs.String("out", "", "output token symbol or address")
+	amount := fs.String("amount", "", "amount in whole input-token units (e.g. 10000)")
+	receive := fs.String("receive", "", "quote the input needed to end with exactly this many whole output-token units, in place of --amount")
+	sender := fs.String("sender", "", "sender for aggregator calldata (defaults to EXECUTOR_ADDRESS_<CHAIN>)")
+	noLifi := fs.Bool("no-lifi", false, "skip the LiFi aggregator")
+	timeout := fs.Duration("timeout", quotes.DefaultTimeout, "per-adapter quote timeout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *chainName == "" || *in == "" || *out == "" || (*amount == "") == (*receive == "") {
+		fs.Usage()
+		return fmt.Errorf("--chain, --in, --out and one of --amount or --receive are required")
+	}
+
+	chain, err := enum.FromName(*chainName)
+	if err != nil {
+		return err
+	}
+	chainID := uint64(chain)
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	registry, err := tokens.FromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	tokenIn, err := registry.Lookup(chainID, *in)
+	if err != nil {
+		return err
+	}
+	tokenOut, err := registry.Lookup(chainID, *out)
+	if err != nil {
+		return err
+	}
+	req := quotes.Request{ChainID: chainID, TokenIn: tokenIn, TokenOut: tokenOut}
+	if *receive != "" {
+		req.AmountOut, err = units.Parse(tokenOut.Address, *receive, tokenOut.Decimals)
+	} else {
+		req.AmountIn, err = units.Parse(tokenIn.Address, *amount, tokenIn.Decimals)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *sender == "" {
+		*sender = os.Getenv("EXECUTOR_ADDRESS_" + strings.ToUpper(chain.Name()))
+	}
+	if *sender == "" {
+		*sender = quotePlaceholderSender
+	}
+	if !common.IsHexAddress(*sender) {
+		return fmt.Errorf("invalid sender address %q", *sender)
+	}
+
+	svc := quotes.NewService(*timeout)
+	svc.Freshness = freshness.FromConfig(cfg, nil)
+	if chainCfg, ok := cfg.GetChain(chainID); ok && chainCfg.RPC != "" {
+		provider, err := enum.NewProviderManager().GetProvider(chainID, chainCfg.RPC)
+		if err != nil {
+			return err
+		}
+		for _, err := range dex.DetectRouterTypes(context.Background(), provider, cfg.DexRouters[chainID]) {
+			log.Printf("⚠️ Router detection: %v", err)
+		}
+		for _, a := range quotes.PoolAdapters(dex.NewDiscovery(chainID, provider), dex.RoutersFromConfig(cfg, chainID)) {
+			svc.Register(a)
+		}
+	} else {
+		log.Printf("⚠️ No RPC configured for %s; quoting through aggregators only", chain.Name())
+	}
+	if !*noLifi {
+		svc.Register(quotes.NewLifiAdapter("", os.Getenv("LIFI_API_KEY")))
+	}
+
+	req.Sender = common.HexToAddress(*sender)
+	if req.ExactOutput() {
+		fmt.Printf("💱 Swap quotes: %s → exactly %s %s on %s\n\n", tokenIn.Symbol, req.AmountOut, tokenOut.Symbol, chain.Name())
+	} else {
+		fmt.Printf("💱 Swap quotes: %s %s → %s on %s\n\n", req.AmountIn, tokenIn.Symbol, tokenOut.Symbol, chain.Name())
+	}
+	best, results, bestErr := svc.Best(context.Background(), req)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if req.ExactOutput() {
+		fmt.Fprintln(w, "ADAPTER\tVENUE\tPAY\tFEE BPS\tGAS\tBLOCK\tLATENCY\tSOURCE")
+	} else {
+		fmt.Fprintln(w, "ADAPTER\tVENUE\tRECEIVE\tFEE BPS\tGAS\tBLOCK\tLATENCY\tSOURCE")
+	}
+	for _, r := range results {
+		latency := r.Latency.Round(time.Millisecond)
+		if r.Err != nil {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\t%s\terror: %v\n", r.Adapter, latency, r.Err)
+			continue
+		}
+		q := r.Quote
+		source := q.Pool.Hex()
+		if q.Kind == quotes.KindAggregator {
+			source = "aggregator"
+			if q.Tx != nil {
+				source += " → " + q.Tx.To.Hex()
+			}
+		}
+		if !q.Executable() {
+			source += " (not executable)"
+		}
+		amount := fmt.Sprintf("%s %s", q.AmountOut, tokenOut.Symbol)
+		if req.ExactOutput() && q.AmountIn != nil {
+			amount = fmt.Sprintf("%s %s", q.AmountIn, tokenIn.Symbol)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%s\t%s\n",
+			r.Adapter, q.Venue, amount, q.FeeBps, q.GasUnits, q.Block, latency, source)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if bestErr != nil {
+		return bestErr
+	}
+	if req.ExactOutput() {
+		fmt.Printf("\n✅ Best: %s via %s ← %s %s\n", best.Adapter, best.Venue, best.AmountIn, tokenIn.Symbol)
+		return nil
+	}
+	fmt.Printf("\n✅ Best: %s via %s → %s %s\n", best.Adapter, best.Venue, best.AmountOut, tokenOut.Symbol)
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/alert"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/report"
+)
+
+// runReport implements `titan report`
+func runReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	period := fs.String("period", report.PeriodDaily, "report period: daily or weekly")
+	journalPath := fs.String("journal", "", "journal file (defaults to <data dir>/journal.jsonl)")
+	outDir := fs.String("out", "", "artifact directory (defaults to <data dir>/reports)")
+	notify := fs.Bool("notify", false, "deliver the report through configured alert channels")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	if *journalPath == "" {
+		*journalPath = filepath.Join(cfg.DataDir, "journal.jsonl")
+	}
+	if *outDir == "" {
+		*outDir = filepath.Join(cfg.DataDir, "reports")
+	}
+
+	summary, err := report.Generate(*journalPath, cfg.StateKeys, *period, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	jsonPath, mdPath, err := summary.Save(*outDir)
+	if err != nil {
+		return err
+	}
+	fmt.Print(summary.Markdown())
+	fmt.Printf("\nSaved %s and %s\n", jsonPath, mdPath)
+
+	if *notify {
+		return alert.FromConfig(cfg.Alerts).Notify(context.Background(), summary.Message())
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/dex"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+)
+
+// runRouter implements `titan router detect`
+func runRouter(args []string) error {
+	if len(args) == 0 || args[0] != "detect" {
+		return fmt.Errorf("usage: titan router detect --chain <chain> [--address <router>]")
+	}
+
+	fs := flag.NewFlagSet("router detect", flag.ContinueOnError)
+	chainName := fs.String("chain", "", "chain name (e.g. polygon)")
+	address := fs.String("address", "", "router address to classify (default: every configured router)")
+	timeout := fs.Duration("timeout", 30*time.Second, "overall probe timeout")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *chainName == "" {
+		fs.Usage()
+		return fmt.Errorf("--chain is required")
+	}
+
+	chain, err := enum.FromName(*chainName)
+	if err != nil {
+		return err
+	}
+	chainID := uint64(chain)
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	chainCfg, ok := cfg.GetChain(chainID)
+	if !ok || chainCfg.RPC == "" {
+		return fmt.Errorf("no RPC configured for %s", chain.Name())
+	}
+	provider, err := enum.NewProviderManager().GetProvider(chainID, chainCfg.RPC)
+	if err != nil {
+		return err
+	}
+
+	// name -> configured entry; an ad-hoc address has no configured type
+	targets := make(map[string]*config.RouterConfig)
+	if *address != "" {
+		if !common.IsHexAddress(*address) {
+			return fmt.Errorf("invalid router address %q", *address)
+		}
+		targets[*address] = &config.RouterConfig{Address: *address}
+	} else {
+		for name, rc := range cfg.DexRouters[chainID] {
+			targets[name] = rc
+		}
+	}
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROUTER\tCONFIGURED\tDETECTED\tCONFIDENCE\tFACTORY\tNOTE")
+	for _, name := range names {
+		rc := targets[name]
+		c, err := dex.Classify(ctx, provider, common.HexToAddress(rc.Address))
+		if err != nil {
+			fmt.Fprintf(w, "%s\t%s\t-\t-\t-\t%v\n", name, orDash(rc.Type), err)
+			continue
+		}
+		note := strings.Join(c.Evidence, "; ")
+		if rc.Type != "" && c.Type != "" && rc.Type != c.Type {
+			note = "⚠️ MISMATCH: " + note
+		}
+		factory := "-"
+		if c.Factory != (common.Address{}) {
+			factory = c.Factory.Hex()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", name, orDash(rc.Type), orDash(c.Type), c.Confidence, factory, note)
+	}
+	return w.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,1460 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/alert"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/allowance"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/amortize"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/api"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/blackout"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/bridge"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/canary"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/cex"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chaos"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/clock"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/commander"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/crash"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/dex"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/drift"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/events"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/execution"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/executor"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/experiment"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/exposure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/freshness"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/graphql"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/heartbeat"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/hf"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/leader"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/liquidity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/mevshare"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/minloan"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/models"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/pipeline"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/prices"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/profile"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/report"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/route"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/rpc"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/scoring"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/seal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/sequencer"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/signals"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/simulation"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/slippage"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/strategy"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/volatility"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/webhook"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/whale"
+)
+
+// runServe implements `titan serve`, the long-running daemon
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", "", "API listen address (defaults to TITAN_API_ADDR)")
+	watchOnly := fs.Bool("watch-only", false, "score and track opportunities without executing (defaults to WATCH_ONLY)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	if *addr != "" {
+		cfg.API.Addr = *addr
+	}
+	if *watchOnly {
+		cfg.WatchOnly = true
+	}
+	if cfg.WatchOnly {
+		log.Printf("👀 Watch-only: opportunities are scored, sized and simulated but never submitted")
+	}
+	allChains := cfg.ApplyShard()
+	ownChains := make([]uint64, 0, len(cfg.Chains))
+	for _, id := range allChains {
+		if cfg.IsChainSupported(id) {
+			ownChains = append(ownChains, id)
+		}
+	}
+	log.Printf("🧩 Shard %s: running %d of %d chains", cfg.Shard, len(ownChains), len(allChains))
+
+	j, err := journal.OpenSealed(filepath.Join(cfg.DataDir, "journal.jsonl"), cfg.StateKeys)
+	if err != nil {
+		return err
+	}
+	defer j.Close()
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	alerts := alert.FromConfig(cfg.Alerts)
+	// Long-lived components run supervised: a panic is reported and the
+	// component restarted rather than taking the daemon down
+	supervisor, err := crash.FromConfig(cfg.Alerts, alerts, metrics.Default)
+	if err != nil {
+		return err
+	}
+	heartbeats := heartbeat.FromConfig(cfg.Heartbeat, metrics.Default)
+	heartbeats.OnFail = func(name string, silent time.Duration) {
+		alerts.Notify(ctx, alert.Message{Level: alert.LevelCritical, Title: "Heartbeat missed: " + name, Body: fmt.Sprintf("No progress for %s", silent.Round(time.Second))})
+	}
+	supervisor.Go(ctx, "heartbeat", func(ctx context.Context) {
+		heartbeats.Run(ctx, time.Duration(cfg.Heartbeat.IntervalSecs)*time.Second)
+	})
+	hooks := webhook.FromConfig(cfg.Webhooks)
+	if hooks != nil {
+		alerts.Add(hooks)
+		supervisor.Go(ctx, "webhook", hooks.Run)
+	}
+	bus, err := events.FromConfig(cfg.EventBus)
+	if err != nil {
+		return err
+	}
+	if bus != nil {
+		alerts.Add(bus)
+		supervisor.Go(ctx, "events", bus.Run)
+	}
+	elector, err := leader.FromConfig(cfg.Leader, metrics.Default)
+	if err != nil {
+		return err
+	}
+	isLeader := func() bool { return true }
+	electorDone := make(chan struct{})
+	if elector != nil {
+		isLeader = elector.IsLeader
+		elector.OnChange(func(leading bool) {
+			role := "standby"
+			if leading {
+				role = "leader"
+			}
+			log.Printf("👑 Instance %s is now %s", elector.ID(), role)
+			alerts.Notify(ctx, alert.Message{Level: alert.LevelWarning, Title: fmt.Sprintf("Instance %s is now %s", elector.ID(), role)})
+		})
+		supervisor.Go(ctx, "leader", func(ctx context.Context) {
+			elector.Run(ctx)
+			close(electorDone)
+		})
+	}
+	runID := profile.NewRunID()
+	log.Printf("🏷️  Run %s", runID)
+	if p := cfg.Profiling; p != nil {
+		if p.SnapshotSecs > 0 {
+			snapshots := profile.NewSnapshotter(filepath.Join(cfg.DataDir, "profiles"), runID, int(p.SnapshotKeep))
+			go snapshots.Run(ctx, time.Duration(p.SnapshotSecs)*time.Second)
+		}
+		leaks := profile.NewLeakDetector(int(p.LeakSamples), int(p.MaxGoroutines), metrics.Default)
+		go leaks.Run(ctx, time.Minute, func(l profile.Leak) {
+			log.Printf("🚰 Goroutine leak suspected: %s", l)
+			alerts.Notify(ctx, alert.Message{Level: alert.LevelWarning, Title: "Goroutine leak: " + l.Module, Body: l.String()})
+		})
+	}
+	reports := &report.Job{
+		JournalPath: j.Path(),
+		Keys:        cfg.StateKeys,
+		OutDir:      filepath.Join(cfg.DataDir, "reports"),
+		Alerts:      alerts,
+	}
+	supervisor.Go(ctx, "reports", reports.Run)
+	rollupStore, err := journal.OpenSealed(filepath.Join(cfg.DataDir, "rollups.jsonl"), cfg.StateKeys)
+	if err != nil {
+		return err
+	}
+	defer rollupStore.Close()
+	rollups := &report.RollupJob{JournalPath: j.Path(), Keys: cfg.StateKeys, Store: rollupStore}
+	supervisor.Go(ctx, "rollups", rollups.Run)
+
+	fills := slippage.NewTracker(metrics.Default)
+	gasHistory := gas.NewHistory()
+	costs := amortize.New(time.Duration(cfg.AmortizeWindowHours)*time.Hour, metrics.Default)
+	canaries := canary.FromConfig(cfg.Canary, metrics.Default)
+	if history, err := journal.ReadSealed(j.Path(), journal.KindExecution, cfg.StateKeys); err == nil {
+		if err := report.ObserveFills(history, fills); err != nil {
+			log.Printf("⚠️ Slippage history: %v", err)
+		}
+		if err := report.ObserveGas(history, gasHistory); err != nil {
+			log.Printf("⚠️ Gas history: %v", err)
+		}
+		if err := costs.Load(history); err != nil {
+			log.Printf("⚠️ Execution history for cost amortization: %v", err)
+		}
+		if err := canaries.Load(history); err != nil {
+			log.Printf("⚠️ Execution history for canary routes: %v", err)
+		}
+	}
+	if spent, err := journal.ReadSealed(j.Path(), journal.KindCost, cfg.StateKeys); err == nil {
+		if err := costs.Load(spent); err != nil {
+			log.Printf("⚠️ Cost history: %v", err)
+		}
+	}
+	scoringModels := models.FromConfig(cfg.Models, metrics.Default)
+	if err := loadModels(ctx, scoringModels); err != nil {
+		return err
+	}
+
+	dial := chaos.FromConfig(cfg.Chaos, metrics.Default).Dialer(rpc.Dialer(cfg))
+	providers := rpc.NewRouter(cfg, dial, rpc.NewMeter(cfg.RPCPricing, metrics.Default))
+	defer providers.Close()
+	providers.Probe = rpc.ProberFor(cfg)
+	providerStore, err := journal.OpenSealed(filepath.Join(cfg.DataDir, "providers.jsonl"), cfg.StateKeys)
+	if err != nil {
+		return err
+	}
+	defer providerStore.Close()
+	providers.History = rpc.NewHistory(providerStore, metrics.Default)
+	defer func() {
+		if err := providers.History.Flush(); err != nil {
+			log.Printf("⚠️ Provider history: %v", err)
+		}
+	}()
+	supervisor.Go(ctx, "providers", providers.History.Run)
+	if regional := providers.Chains(rpcChains(cfg)...); len(regional) > 0 {
+		supervisor.Go(ctx, "regions", func(ctx context.Context) {
+			providers.Run(ctx, time.Duration(cfg.Region.ProbeSecs)*time.Second, regional...)
+		})
+	}
+	registry, err := tokens.FromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	ingestTokenLists(ctx, registry, cfg)
+	// headlines older than a few hours say little about the next block
+	news := hf.NewBoard(hf.FromConfig(cfg.AI, metrics.Default), registry, 6*time.Hour)
+	social := signals.FromConfig(cfg, news, registry, metrics.Default)
+	if social != nil && (len(cfg.Signals.RSSFeeds) > 0 || cfg.Signals.TwitterToken != "") {
+		supervisor.Go(ctx, "signals", signals.NewPoller(social, cfg.Signals).Run)
+	}
+	reserveCache := reserves.NewCache()
+	if err := reserveCache.Load(defaultReserveCachePath); err != nil {
+		log.Printf("⚠️ Ignoring reserve cache: %v", err)
+	}
+	nativePrices := newPriceTracker(cfg, providers, registry, reserveCache)
+	for _, id := range rpcChains(cfg) {
+		heartbeats.Register(priceCheck(id))
+	}
+	regimes := volatility.FromConfig(cfg.Volatility, metrics.Default)
+	pairs, feeds := volatilityPairs(cfg)
+	nativePrices.OnRefresh = func(chainID uint64) {
+		heartbeats.Beat(priceCheck(chainID))
+		if q, err := nativePrices.Quote(chainID); err == nil && feeds[chainID] {
+			regimes.Observe(pairs[chainID], float64(q.PriceE8)/1e8)
+		}
+	}
+	supervisor.Go(ctx, "prices", func(ctx context.Context) {
+		nativePrices.Run(ctx, time.Minute, rpcChains(cfg)...)
+	})
+	fees := newGasOracle(cfg, providers)
+	floors := newLoanFloors(cfg, fees, gasHistory, nativePrices)
+	if floors != nil {
+		supervisor.Go(ctx, "minloan", func(ctx context.Context) {
+			floors.Run(ctx, time.Duration(cfg.MinLoan.RefreshSecs)*time.Second, rpcChains(cfg)...)
+		})
+	}
+
+	lifecycle, err := pipeline.OpenSealed(filepath.Join(cfg.DataDir, "pipeline.jsonl"), metrics.Default, cfg.StateKeys)
+	if err != nil {
+		return err
+	}
+	defer lifecycle.Close()
+	quoteFreshness := freshness.FromConfig(cfg, metrics.Default)
+	lifecycle.Freshness = quoteFreshness
+	if err := lifecycle.Compact(); err != nil {
+		log.Printf("⚠️ Pipeline compaction: %v", err)
+	}
+	reconciler := pipeline.NewReconciler(lifecycle, func(chainID uint64) (chain.Client, error) {
+		return providers.Client(chainID, rpc.PriorityHigh)
+	})
+	for id, chain := range cfg.Chains {
+		reconciler.Confirmations[id] = chain.Confirmations
+	}
+	store := opportunity.NewStore(1000)
+	recorder := opportunity.NewRecorder(store, j)
+	recorder.Failures = failure.NewCounter(metrics.Default)
+	if hooks != nil {
+		recorder.Listen(hooks.Publish)
+	}
+	if bus != nil {
+		recorder.Listen(bus.Publish)
+	}
+	recorder.Listen(func(kind string, record interface{}) {
+		if x, ok := record.(*opportunity.Execution); ok {
+			gasHistory.Observe(x.GasShape(), x.GasUsed)
+		}
+	})
+	recorder.Listen(costs.Observe)
+	recorder.Listen(canaries.Observe)
+	strategies, err := strategy.New(cfg.Strategies, registry, metrics.Default)
+	if err != nil {
+		return err
+	}
+	recorder.Listen(strategies.Observe)
+	book := newExposureBook(cfg, providers, registry, reserveCache, nativePrices)
+	book.Lifecycle, book.Store = lifecycle, store
+	supervisor.Go(ctx, "exposure", func(ctx context.Context) {
+		book.Run(ctx, time.Duration(cfg.Exposure.RefreshSecs)*time.Second)
+	})
+	whales := newWhaleMonitor(cfg, providers, registry, book.Value)
+	if whales != nil {
+		whales.OnFlow = func(f whale.Flow) {
+			log.Printf("🐋 Whale %s", f)
+			alerts.Notify(ctx, alert.Message{Level: alert.LevelWarning, Title: "Whale " + f.Direction + ": " + f.Symbol, Body: f.String()})
+		}
+		supervisor.Go(ctx, "whales", func(ctx context.Context) {
+			whales.Run(ctx, time.Duration(cfg.Whale.PollSecs)*time.Second, rpcChains(cfg)...)
+		})
+	}
+	if cfg.Allowances.Enabled {
+		allowances := newAllowanceHygiene(cfg, providers, registry, allowanceOwners(cfg))
+		allowances.OnDue = func(a allowance.Approval) {
+			alerts.Notify(ctx, alert.Message{Level: alert.LevelWarning, Title: "Standing approval due for revocation",
+				Body: fmt.Sprintf("%s's %s approval for %s on chain %d: %s", a.Owner.Hex(), a.Symbol, a.Spender.Hex(), a.ChainID, a.Reason())})
+		}
+		if cfg.Allowances.AutoRevoke && !cfg.WatchOnly {
+			key, err := openKey(cfg, "PRIVATE_KEY", os.Getenv("PRIVATE_KEY"))
+			if err != nil {
+				return err
+			}
+			fees := newGasOracle(cfg, providers)
+			allowances.Revoke = func(ctx context.Context, a allowance.Approval) (common.Hash, error) {
+				if !isLeader() {
+					return common.Hash{}, errors.New("standby: the leader revokes")
+				}
+				return sendRevocation(ctx, cfg, fees, key, a)
+			}
+		}
+		supervisor.Go(ctx, "allowances", func(ctx context.Context) {
+			allowances.Run(ctx, time.Duration(cfg.Allowances.IntervalSecs)*time.Second, rpcChains(cfg)...)
+		})
+	}
+	pools := newPoolTracker(cfg, providers, reserveCache, book.Value)
+	if pools != nil {
+		supervisor.Go(ctx, "liquidity", func(ctx context.Context) {
+			pools.Run(ctx, time.Duration(cfg.Liquidity.PollSecs)*time.Second, rpcChains(cfg)...)
+		})
+	}
+	sequencers := sequencer.FromConfig(cfg, func(chainID uint64) (sequencer.Client, error) {
+		return providers.Client(chainID, rpc.PriorityHigh)
+	}, metrics.Default)
+	if sequencers != nil {
+		sequencers.OnChange = func(s sequencer.Status) {
+			if s.Paused {
+				log.Printf("⏸️ Chain %d paused: %s", s.ChainID, s.Reason)
+				alerts.Notify(ctx, alert.Message{Level: alert.LevelWarning, Title: fmt.Sprintf("Chain %d sequencer unfit", s.ChainID), Body: s.Reason})
+				return
+			}
+			log.Printf("▶️ Chain %d sequencer healthy, resuming", s.ChainID)
+			alerts.Notify(ctx, alert.Message{Level: alert.LevelInfo, Title: fmt.Sprintf("Chain %d sequencer healthy", s.ChainID), Body: "execution resumed"})
+		}
+		supervisor.Go(ctx, "sequencer", func(ctx context.Context) {
+			sequencers.Run(ctx, time.Duration(cfg.Sequencer.PollSecs)*time.Second, sequencers.Chains(rpcChains(cfg)...)...)
+		})
+	}
+	hostClock := clock.FromConfig(cfg.Clock, metrics.Default)
+	if hostClock != nil {
+		hostClock.OnChange = func(s clock.Status) {
+			if s.Drifted {
+				body := fmt.Sprintf("host clock is %.0fms off NTP (threshold %s); quote freshness, price feed age and sequencer lag are unreliable", s.OffsetMs, hostClock.MaxDrift())
+				log.Printf("⏰ Clock drift: %s", body)
+				alerts.Notify(ctx, alert.Message{Level: alert.LevelCritical, Title: "Host clock drifting", Body: body})
+				return
+			}
+			log.Printf("⏰ Clock back within %s of NTP (%.0fms)", hostClock.MaxDrift(), s.OffsetMs)
+			alerts.Notify(ctx, alert.Message{Level: alert.LevelInfo, Title: "Host clock in sync", Body: fmt.Sprintf("offset %.0fms", s.OffsetMs)})
+		}
+		supervisor.Go(ctx, "clock", func(ctx context.Context) {
+			hostClock.Run(ctx, time.Duration(cfg.Clock.PollSecs)*time.Second)
+		})
+	}
+	bridgeStore, err := journal.OpenSealed(filepath.Join(cfg.DataDir, "bridges.jsonl"), cfg.StateKeys)
+	if err != nil {
+		return err
+	}
+	defer bridgeStore.Close()
+	transfers, err := bridge.ReadTransfers(bridgeStore.Path(), cfg.StateKeys, time.Time{})
+	if err != nil {
+		return err
+	}
+	calibration := bridge.NewCalibrator(cfg.IntentBasedBridges, cfg.BridgeCalibration, bridgeStore, metrics.Default)
+	calibration.Load(transfers)
+	calibration.OnChange = func(d bridge.Drift) {
+		if d.Drifted {
+			alerts.Notify(ctx, alert.Message{Level: alert.LevelWarning, Title: fmt.Sprintf("Bridge %s costs drifted", d.Bridge), Body: strings.Join(d.Reasons, "; ")})
+			return
+		}
+		alerts.Notify(ctx, alert.Message{Level: alert.LevelInfo, Title: fmt.Sprintf("Bridge %s costs within config", d.Bridge), Body: fmt.Sprintf("median fee %.1f bps", d.FeeBps)})
+	}
+	bridges := bridge.FromConfig(cfg, calibration)
+	if key := os.Getenv("LIFI_API_KEY"); key != "" {
+		bridges.Register(bridge.NewLifiAdapter("", key))
+	}
+	plans := bridge.NewTracker(bridge.Policy(cfg.CrossChainPolicy), nil)
+	bridgeStatus := bridge.NewMonitor(bridges, plans, calibration, cfg.IntentBasedBridges, cfg.BridgeStatus, metrics.Default)
+	bridgeStatus.OnChange = func(s bridge.Status) {
+		level := alert.LevelWarning
+		switch s.Health {
+		case bridge.HealthOK:
+			level = alert.LevelInfo
+		case bridge.HealthDown:
+			level = alert.LevelCritical
+		}
+		alerts.Notify(ctx, alert.Message{Level: level, Title: fmt.Sprintf("Bridge %s %s", s.Bridge, s.Health), Body: strings.Join(s.Reasons, "; ")})
+	}
+	supervisor.Go(ctx, "bridges", func(ctx context.Context) {
+		bridgeStatus.Run(ctx, time.Duration(cfg.BridgeStatus.RefreshSecs)*time.Second)
+	})
+	notifyOutcome := func(o pipeline.Outcome) {
+		// Confirmed trades are settled by their execution record instead
+		switch o.Action {
+		case pipeline.ActionFailed, pipeline.ActionAbandoned:
+			strategies.Release(o.ID)
+		}
+		level := alert.LevelInfo
+		switch o.Action {
+		case pipeline.ActionAbandoned, pipeline.ActionReorged:
+			level = alert.LevelWarning
+		}
+		if o.Action == pipeline.ActionReorged {
+			if err := recordReversal(recorder, lifecycle, o); err != nil {
+				log.Printf("❌ Failed to journal reversal for %s: %v", o.ID, err)
+			}
+		}
+		body := o.Detail
+		if o.Reason != "" {
+			body = fmt.Sprintf("[%s] %s", o.Reason, o.Detail)
+		}
+		alerts.Notify(ctx, alert.Message{Level: level, Title: fmt.Sprintf("Opportunity %s %s", o.ID, o.Action), Body: body})
+	}
+	outcomes, err := reconciler.Reconcile(ctx)
+	if err != nil {
+		// Unresolved records stay in flight and are retried by the watcher
+		log.Printf("❌ Crash recovery incomplete: %v", err)
+	}
+	for _, o := range outcomes {
+		log.Printf("🔁 Recovered %s: %s (%s)", o.ID, o.Action, o.Detail)
+		if o.Action != pipeline.ActionMonitoring {
+			notifyOutcome(o)
+		}
+	}
+	heartbeats.Register("reconciler")
+	reconciler.OnPass = func() { heartbeats.Beat("reconciler") }
+	supervisor.Go(ctx, "pipeline", func(ctx context.Context) {
+		reconciler.Watch(ctx, 15*time.Second, notifyOutcome)
+	})
+	supervisor.Go(ctx, "stuck", func(ctx context.Context) { watchStuck(ctx, lifecycle, alerts) })
+
+	checker, err := newDriftChecker(cfg, providers)
+	if err != nil {
+		return err
+	}
+	supervisor.Go(ctx, "drift", func(ctx context.Context) {
+		checker.Run(ctx, time.Hour, driftTargets(cfg, rpcChains(cfg)), func(f drift.Finding) {
+			alerts.Notify(ctx, alert.Message{Level: alert.LevelWarning, Title: "Config drift: " + f.Kind, Body: f.String()})
+		})
+	})
+
+	audit, err := journal.OpenSealed(filepath.Join(cfg.DataDir, "audit.jsonl"), cfg.StateKeys)
+	if err != nil {
+		return err
+	}
+	defer audit.Close()
+	changes, err := openChangeLog(audit, cfg.StateKeys)
+	if err != nil {
+		return err
+	}
+	controls := api.NewControls(ownChains, cfg.Guardrails)
+	if err := controls.Track(changes); err != nil {
+		return err
+	}
+	blackouts, err := blackout.New(cfg.Blackouts, metrics.Default)
+	if err != nil {
+		return err
+	}
+	var pipelines *scoring.Set
+	if cfg.MEVShare != nil && cfg.MEVShare.Enabled {
+		backrunner, err := newBackrunner(ctx, cfg, fees, nativePrices, gasHistory, costs, canaries, reserveCache, quoteFreshness)
+		if err != nil {
+			return err
+		}
+		submitter, err := newBackrunSubmitter(ctx, cfg, backrunner, fees)
+		if err != nil {
+			return err
+		}
+		trial, err := experiment.FromConfig(cfg.Experiment, scoringModels, metrics.Default)
+		if err != nil {
+			return err
+		}
+		if trial != nil && social != nil {
+			trial.Signals = experiment.MergeSignals(social.Features, whales.Features)
+		} else if trial != nil {
+			trial.Signals = experiment.MergeSignals(news.Features, whales.Features)
+		}
+		if trial != nil && cfg.Experiment.Candidate == "" {
+			log.Printf("🧪 Scoring with %s", cfg.Experiment.Control)
+		} else if trial != nil {
+			log.Printf("🧪 Scoring experiment: %s against %s (%s)", cfg.Experiment.Candidate, cfg.Experiment.Control, cfg.Experiment.Mode)
+		}
+		notional := func(o *opportunity.Opportunity) units.USD { return backrunNotional(backrunner, o) }
+		stages := scoringStages(strategies, controls, blackouts, sequencers, regimes, pools, floors, notional, pairs, trial, social, canaries)
+		pipelines, err = scoring.FromConfig(cfg, stages, metrics.Default)
+		if err != nil {
+			return err
+		}
+		pipelines.Features = trial.Inputs
+		log.Printf("🧮 Scoring pipeline: %s", describeStages(pipelines.For(config.StrategyMain).Stages()))
+		heartbeats.Register("mevshare")
+		supervisor.Go(ctx, "mevshare", func(ctx context.Context) {
+			consumeMEVShare(ctx, cfg.MEVShare, backrunner, submitter, recorder, lifecycle, isLeader, cfg.WatchOnly, controls, strategies, pipelines, heartbeats, regimes, pairs)
+		})
+	}
+
+	var cexBoard *cex.Board
+	if cfg.CEX != nil && cfg.CEX.Enabled {
+		feeds, err := cex.FeedsFromConfig(cfg.CEX)
+		if err != nil {
+			return err
+		}
+		cexBoard = cex.NewBoard(time.Duration(cfg.CEX.MaxAgeSecs)*time.Second, metrics.Default)
+		for _, feed := range feeds {
+			stream := cex.NewStream(feed, cexBoard)
+			supervisor.Go(ctx, "cex", func(ctx context.Context) { stream.Run(ctx) })
+		}
+		supervisor.Go(ctx, "depeg", func(ctx context.Context) { watchDepegs(ctx, cexBoard, cfg.CEX, alerts) })
+	}
+
+	server := api.New(cfg.API.Addr, store)
+	server.Handle("/metrics", metrics.Default.Handler())
+	if cfg.Profiling != nil && cfg.Profiling.PprofEnabled {
+		profile.Register(server.Handle)
+	}
+	server.Handle("/pipeline", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"inFlight": lifecycle.InFlight(),
+			"stuck":    lifecycle.Stuck(pipeline.DefaultStuckLimits),
+		})
+	})
+	server.Handle("/rpc/usage", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, providers.Meter().Usage())
+	})
+	server.Handle("/rpc/capabilities", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, providers.Endpoints())
+	})
+	server.Handle("/rpc/regions", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, providers.Latencies())
+	})
+	server.Handle("/prices", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, nativePrices.Quotes())
+	})
+	server.Handle("/volatility", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, regimes.States())
+	})
+	server.Handle("/liquidity", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, pools.States())
+	})
+	server.Handle("/minloan", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, floors.Floors())
+	})
+	server.Handle("/costs", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			api.WriteJSON(w, http.StatusOK, costs.Routes())
+		case http.MethodPost:
+			if !api.Allow(w, r, api.RoleOperator) {
+				return
+			}
+			var c opportunity.Cost
+			if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+				api.WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if err := amortize.Validate(&c); err != nil {
+				api.WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			c.Time = time.Now().UTC()
+			if err := recorder.RecordCost(&c); err != nil {
+				api.WriteError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			api.WriteJSON(w, http.StatusCreated, c)
+		default:
+			api.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	})
+	server.Handle("/canaries", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, canaries.Routes())
+	})
+	server.Handle("/scoring", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, pipelines.Pipelines())
+	})
+	history, err := graphql.New(j.Path(), cfg.StateKeys, metrics.Default)
+	if err != nil {
+		return err
+	}
+	server.Handle("/graphql", history.ServeHTTP)
+	server.Handle("/rollups", func(w http.ResponseWriter, r *http.Request) {
+		hours := 168
+		if q := r.URL.Query().Get("hours"); q != "" {
+			n, err := strconv.Atoi(q)
+			if err != nil || n <= 0 {
+				api.WriteError(w, http.StatusBadRequest, "invalid hours")
+				return
+			}
+			hours = n
+		}
+		since := time.Now().UTC().Truncate(time.Hour).Add(-time.Duration(hours) * time.Hour)
+		out, err := report.ReadRollups(rollupStore.Path(), cfg.StateKeys, since)
+		if err != nil {
+			api.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		api.WriteJSON(w, http.StatusOK, out)
+	})
+	server.Handle("/models", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, scoringModels.Models())
+	})
+	server.Handle("/signals", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			api.WriteJSON(w, http.StatusOK, map[string]interface{}{"tokens": social.Tokens(), "recent": social.Recent()})
+		case http.MethodPost:
+			if !api.Allow(w, r, api.RoleOperator) {
+				return
+			}
+			if social == nil {
+				api.WriteError(w, http.StatusServiceUnavailable, "real-time data is disabled")
+				return
+			}
+			var e signals.Event
+			if err := json.NewDecoder(r.Body).Decode(&e); err != nil || e.ID == "" || e.Text == "" {
+				api.WriteError(w, http.StatusBadRequest, "expected an event with an id and text")
+				return
+			}
+			e.Source = signals.SourceWebhook
+			e, _ = social.Ingest(r.Context(), e)
+			api.WriteJSON(w, http.StatusOK, e)
+		default:
+			api.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	})
+	server.Handle("/whales", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, whales.Flows())
+	})
+	server.Handle("/news", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			api.WriteJSON(w, http.StatusOK, news.Readings())
+		case http.MethodPost:
+			if !api.Allow(w, r, api.RoleOperator) {
+				return
+			}
+			var headline struct {
+				Tokens []string `json:"tokens"`
+				Text   string   `json:"text"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&headline); err != nil || len(headline.Tokens) == 0 || headline.Text == "" {
+				api.WriteError(w, http.StatusBadRequest, "expected {\"tokens\": [...], \"text\": \"...\"}")
+				return
+			}
+			s, err := news.Ingest(r.Context(), headline.Tokens, headline.Text)
+			if err != nil {
+				api.WriteError(w, http.StatusServiceUnavailable, err.Error())
+				return
+			}
+			api.WriteJSON(w, http.StatusOK, s)
+		default:
+			api.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	})
+	server.Handle("/exposure", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, book.Snapshot())
+	})
+	server.Handle("/strategies", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, strategies.Statuses())
+	})
+	server.Handle("/blackouts", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, map[string]blackout.Verdict{
+			blackout.StrategyBackrun: blackouts.Now(blackout.StrategyBackrun),
+		})
+	})
+	server.EnableCluster(func() api.Status {
+		st := api.Status{RunID: runID, Shard: cfg.Shard.String(), Chains: ownChains, WatchOnly: cfg.WatchOnly}
+		if elector != nil {
+			leading := elector.IsLeader()
+			st.Leader = &leading
+		}
+		return st
+	}, cfg.Shard.Peers, allChains)
+	if elector != nil {
+		server.Handle("/leader", func(w http.ResponseWriter, r *http.Request) {
+			holder, err := elector.Holder(r.Context())
+			if err != nil {
+				api.WriteError(w, http.StatusBadGateway, err.Error())
+				return
+			}
+			api.WriteJSON(w, http.StatusOK, map[string]interface{}{
+				"instance": elector.ID(),
+				"leader":   elector.IsLeader(),
+				"holder":   holder,
+			})
+		})
+	}
+	if cexBoard != nil {
+		server.Handle("/cex", func(w http.ResponseWriter, r *http.Request) {
+			api.WriteJSON(w, http.StatusOK, cexBoard.Tickers())
+		})
+	}
+	server.Handle("/tokens", func(w http.ResponseWriter, r *http.Request) {
+		chainID, err := strconv.ParseUint(r.URL.Query().Get("chain"), 10, 64)
+		if err != nil {
+			api.WriteError(w, http.StatusBadRequest, "chain query parameter required")
+			return
+		}
+		list := registry.Chain(chainID)
+		if tag := r.URL.Query().Get("tag"); tag != "" {
+			list = registry.Tagged(chainID, tag)
+		}
+		api.WriteJSON(w, http.StatusOK, list)
+	})
+	server.Handle("/sequencers", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, sequencers.Statuses())
+	})
+	server.Handle("/bridges", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, bridgeStatus.Statuses())
+	})
+	server.Handle("/clock", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, hostClock.Status())
+	})
+	server.Handle("/heartbeats", func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, heartbeats.Checks())
+	})
+	server.Handle("/gas", func(w http.ResponseWriter, r *http.Request) {
+		out := make(map[uint64]interface{})
+		for _, chainID := range rpcChains(cfg) {
+			if suggestion, err := fees.Suggest(r.Context(), chainID); err != nil {
+				out[chainID] = map[string]string{"error": err.Error()}
+			} else {
+				out[chainID] = suggestion
+			}
+		}
+		api.WriteJSON(w, http.StatusOK, out)
+	})
+	server.EnableControls(controls)
+	server.EnableChanges(changes)
+	newConsoleEndpoints(cfg, providers, registry, reserveCache).register(server)
+	auth, err := api.NewAuth(cfg.API.Keys)
+	if err != nil {
+		return err
+	}
+	if auth != nil {
+		auth.Audit = func(rec api.AuditRecord) {
+			if err := audit.Append(journal.KindAudit, rec); err != nil {
+				log.Printf("⚠️ Audit log: %v", err)
+			}
+		}
+		server.EnableAuth(auth)
+		log.Printf("🔐 API keys required (%d configured); audit log %s", len(cfg.API.Keys), audit.Path())
+	} else {
+		log.Printf("⚠️ API open: set TITAN_API_KEYS to require keys")
+	}
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.ListenAndServe() }()
+	fmt.Printf("🛰️  Titan Core API listening on %s (journal: %s)\n", cfg.API.Addr, j.Path())
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+wait:
+	for {
+		select {
+		case err := <-errCh:
+			return err
+		case s := <-sig:
+			if s == syscall.SIGHUP {
+				reloadGuardrails(controls)
+				if err := loadModels(ctx, scoringModels); err != nil {
+					log.Printf("⚠️ Reload: %v (keeping the previous versions)", err)
+				}
+				continue
+			}
+			log.Printf("Received %s, shutting down", s)
+			break wait
+		}
+	}
+	if elector != nil {
+		// Release the lock now so a standby takes over without waiting out the TTL
+		stop()
+		<-electorDone
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// openChangeLog loads the setting change history from the audit journal and
+// appends new changes to it
+func openChangeLog(audit *journal.Journal, keys *seal.Keyring) (*api.ChangeLog, error) {
+	entries, err := journal.ReadSealed(audit.Path(), journal.KindChange, keys)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]api.Change, 0, len(entries))
+	for _, e := range entries {
+		var c api.Change
+		if err := json.Unmarshal(e.Data, &c); err != nil {
+			return nil, fmt.Errorf("%s: %w", audit.Path(), err)
+		}
+		history = append(history, c)
+	}
+	return api.NewChangeLog(history, func(c api.Change) error {
+		log.Printf("📝 %s by %s (%s): %s → %s", c.Setting, c.Actor, c.Source, c.Old, c.New)
+		return audit.Append(journal.KindChange, c)
+	}), nil
+}
+
+// reloadGuardrails re-reads the configuration on SIGHUP and applies its
+// sizing limits; the rest of the configuration needs a restart
+func reloadGuardrails(controls *api.Controls) {
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		log.Printf("⚠️ Reload: %v", err)
+		return
+	}
+	if err := controls.SetGuardrails(*cfg.Guardrails, api.Actor{Source: api.SourceReload, Name: "SIGHUP"}); err != nil {
+		log.Printf("⚠️ Reload: %v", err)
+		return
+	}
+	log.Printf("🔄 Reloaded guardrails: %+v", controls.Guardrails())
+}
+
+// loadModels loads the current version of every registry model
+func loadModels(ctx context.Context, registry *models.Registry) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	return registry.Load(ctx)
+}
+
+// ingestTokenLists adds the configured token lists' tokens on configured chains
+func ingestTokenLists(ctx context.Context, registry *tokens.Registry, cfg *config.Config) {
+	chains := make([]uint64, 0, len(cfg.Chains))
+	for id := range cfg.Chains {
+		chains = append(chains, id)
+	}
+	for _, source := range cfg.TokenLists {
+		list, err := tokens.LoadTokenList(ctx, source)
+		if err != nil {
+			log.Printf("⚠️ Token list %s: %v", source, err)
+			continue
+		}
+		added, err := registry.Ingest(list, chains...)
+		if err != nil {
+			log.Printf("⚠️ Token list %s: skipped entries: %v", list.Name, err)
+		}
+		log.Printf("✅ Token list %s v%s: %d tokens added", list.Name, list.Version, added)
+	}
+}
+
+// newPriceTracker prices native gas tokens from Chainlink, falling back to cached DEX pools
+func newPriceTracker(cfg *config.Config, providers *rpc.Router, registry *tokens.Registry, cache *reserves.Cache) *prices.Tracker {
+	dial := func(chainID uint64) (ethereum.ContractCaller, error) {
+		return providers.Client(chainID, rpc.PriorityLow)
+	}
+	return prices.NewTracker(10*time.Minute, metrics.Default,
+		prices.NewChainlinkSource(prices.ChainlinkFeedsFromConfig(cfg), dial),
+		prices.NewPoolSource(cache, registry, time.Hour),
+	)
+}
+
+// newExposureBook watches the configured wallets and each chain's executor
+// across every registered token, valued by the native price tracker and the
+// reserve cache
+func newExposureBook(cfg *config.Config, providers *rpc.Router, registry *tokens.Registry, cache *reserves.Cache, nativePrices *prices.Tracker) *exposure.Book {
+	chains := rpcChains(cfg)
+	executors := make(map[uint64]string, len(chains))
+	for _, id := range chains {
+		executors[id] = os.Getenv("EXECUTOR_ADDRESS_" + strings.ToUpper(cfg.Chains[id].Name))
+	}
+	dial := func(chainID uint64) (ethereum.ContractCaller, error) {
+		return providers.Client(chainID, rpc.PriorityLow)
+	}
+	list := func(chainID uint64) []tokens.Token {
+		native := tokens.Token{ChainID: chainID, Symbol: cfg.Chains[chainID].Native, Decimals: 18}
+		return append([]tokens.Token{native}, registry.Chain(chainID)...)
+	}
+	book := exposure.New(exposure.WalletsFromConfig(cfg, chains, executors), dial, list, metrics.Default)
+	book.Value = &exposure.Prices{Native: nativePrices, Registry: registry, Pools: cache, MaxAge: time.Hour}
+	return book
+}
+
+// newWhaleMonitor watches exchange transfers of every registered token,
+// valued like the exposure book, or returns nil without exchanges configured
+func newWhaleMonitor(cfg *config.Config, providers *rpc.Router, registry *tokens.Registry, value exposure.Valuer) *whale.Monitor {
+	dial := func(chainID uint64) (whale.Filterer, error) {
+		client, err := providers.Client(chainID, rpc.PriorityLow)
+		if err != nil {
+			return nil, err
+		}
+		logs, ok := client.(whale.Filterer)
+		if !ok {
+			return nil, fmt.Errorf("chain %d client can't filter logs", chainID)
+		}
+		return logs, nil
+	}
+	return whale.FromConfig(cfg.Whale, dial, registry.Chain, value, metrics.Default)
+}
+
+// newPoolTracker follows the TVL and volume of the cached pools, valued like
+// the exposure book, or returns nil when disabled
+func newPoolTracker(cfg *config.Config, providers *rpc.Router, cache *reserves.Cache, value exposure.Valuer) *liquidity.Tracker {
+	dial := func(chainID uint64) (liquidity.Backend, error) {
+		client, err := providers.Client(chainID, rpc.PriorityLow)
+		if err != nil {
+			return nil, err
+		}
+		backend, ok := client.(liquidity.Backend)
+		if !ok {
+			return nil, fmt.Errorf("chain %d client can't read pools and logs", chainID)
+		}
+		return backend, nil
+	}
+	return liquidity.FromConfig(cfg.Liquidity, cache, dial, value, metrics.Default)
+}
+
+// newBackrunner quotes MEV-Share backruns from WETH through the cached Ethereum
+// pools, net of the configured builder payment and of gas predicted from
+// history and priced at the oracle's fees
+func newBackrunner(ctx context.Context, cfg *config.Config, fees *gas.Oracle, nativePrices *prices.Tracker, gasHistory *gas.History, costs *amortize.Ledger, canaries *canary.Tracker, cache *reserves.Cache, fresh *freshness.Policy) (*mevshare.Backrunner, error) {
+	chain, ok := cfg.GetChain(uint64(enum.Ethereum))
+	if !ok || !common.IsHexAddress(chain.WrappedNative) {
+		return nil, fmt.Errorf("mev-share needs ethereum's wrapped native token configured")
+	}
+	amountIn, err := units.Parse(common.Address{}, strconv.FormatFloat(cfg.MEVShare.BackrunInETH, 'f', -1, 64), 18)
+	if err != nil {
+		return nil, fmt.Errorf("mev-share backrun size: %w", err)
+	}
+	b := mevshare.NewBackrunner(uint64(enum.Ethereum), cache, common.HexToAddress(chain.WrappedNative), amountIn.Big())
+	b.ToUSD = func(wei *big.Int) (units.USD, error) { return nativePrices.NativeToUSD(uint64(enum.Ethereum), wei) }
+	b.Gas = gasHistory
+	b.Costs = costs
+	b.Canary = canaries
+	b.FlashPremiumBps = route.FlashBalancer.PremiumBps()
+	b.GasCostUSD = func(gasUnits uint64) (units.USD, error) {
+		suggestion, err := fees.Suggest(ctx, uint64(enum.Ethereum))
+		if err != nil {
+			return 0, err
+		}
+		// A builder payment replaces the quoted tip and is charged as the relay tip
+		price := new(big.Int).Set(suggestion.BaseFee)
+		if b.Payment == nil {
+			price.Add(price, suggestion.TipCap)
+		}
+		return nativePrices.GasCostUSD(uint64(enum.Ethereum), gasUnits, price)
+	}
+	if window := fresh.Window(uint64(enum.Ethereum)); window.MaxAge > 0 {
+		b.MaxAge = window.MaxAge
+	}
+	if cfg.BuilderPayment != nil {
+		policy, err := mevshare.PaymentPolicyFromConfig(cfg.BuilderPayment)
+		if err != nil {
+			return nil, err
+		}
+		b.Payment = &policy
+	}
+	return b, nil
+}
+
+// newBackrunSubmitter sizes, simulates and submits MEV-Share backruns
+// through the Ethereum executor at EXECUTOR_ADDRESS_ETHEREUM, signing with
+// PRIVATE_KEY. Relay requests are signed with MEV_SHARE_AUTH_KEY; without
+// one a throwaway key is used, which builds no reputation with the relay.
+// Watch-only submitters load no keys and simulate as the executor's owner.
+func newBackrunSubmitter(ctx context.Context, cfg *config.Config, backrunner *mevshare.Backrunner, fees *gas.Oracle) (*mevshare.Submitter, error) {
+	chainID := uint64(enum.Ethereum)
+	chainCfg, ok := cfg.GetChain(chainID)
+	if !ok || chainCfg.RPC == "" {
+		return nil, fmt.Errorf("mev-share needs an ethereum RPC")
+	}
+	address := os.Getenv("EXECUTOR_ADDRESS_ETHEREUM")
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("mev-share needs the ethereum executor: invalid EXECUTOR_ADDRESS_ETHEREUM %q", address)
+	}
+	contract := common.HexToAddress(address)
+	node, err := rpc.Dial(ctx, cfg, chainCfg.RPC)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", chainCfg.Name, err)
+	}
+	client := ethclient.NewClient(node)
+	runner, err := simulation.RunnerFromConfig(ctx, cfg, chainID, client)
+	if err != nil {
+		return nil, err
+	}
+	sizer := commander.NewFromConfig(chainID, client, cfg.Guardrails)
+	s := mevshare.NewSubmitter(backrunner, contract, route.FlashBalancer, node, sizer, runner)
+	// Snapshots carry the name of the router their pool was discovered through
+	s.Routers = make(map[string]common.Address)
+	for _, r := range dex.RoutersFromConfig(cfg, chainID) {
+		s.Routers[r.Name] = r.Address
+	}
+	if s.Adapter, err = execution.FromConfig(chainID, chainCfg); err != nil {
+		return nil, err
+	}
+	if cfg.WatchOnly {
+		if s.Sender, err = executorOwner(ctx, client, contract); err != nil {
+			return nil, err
+		}
+		log.Printf("🏦 MEV-Share backruns are simulated through executor %s as %s", contract.Hex(), s.Sender.Hex())
+		return s, nil
+	}
+
+	key, err := openKey(cfg, "PRIVATE_KEY", os.Getenv("PRIVATE_KEY"))
+	if err != nil {
+		return nil, err
+	}
+	var authKey *ecdsa.PrivateKey
+	if cfg.MEVShare.AuthKey != "" {
+		authKey, err = openKey(cfg, "MEV_SHARE_AUTH_KEY", cfg.MEVShare.AuthKey)
+	} else {
+		log.Printf("⚠️ No MEV_SHARE_AUTH_KEY: relay requests are signed with a throwaway key")
+		authKey, err = crypto.GenerateKey()
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Sender, s.Key = execution.Sender(key), key
+	s.Relay = mevshare.NewClient(cfg.MEVShare.RelayURL, authKey)
+	s.Fees = func(ctx context.Context) (gas.Fees, error) { return fees.Suggest(ctx, chainID) }
+	log.Printf("🏦 MEV-Share backruns go through executor %s, signed by %s", contract.Hex(), s.Sender.Hex())
+	return s, nil
+}
+
+// executorOwner reads the executor's owner, the only sender its execute accepts
+func executorOwner(ctx context.Context, client *ethclient.Client, contract common.Address) (common.Address, error) {
+	return executor.New(uint64(enum.Ethereum), contract, client).Owner(ctx)
+}
+
+// openKey opens a private key sealed with the state keys; name labels errors
+func openKey(cfg *config.Config, name, sealed string) (*ecdsa.PrivateKey, error) {
+	opened, err := cfg.StateKeys.OpenString(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(opened, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return key, nil
+}
+
+// consumeMEVShare records backrun candidates for MEV-Share hints as scored
+// opportunities. Standbys journal candidates but only the leader sizes,
+// simulates and submits them, and only while the operator hasn't disabled
+// the chain. Each candidate is gated and budgeted by the strategy instance
+// whose universe covers it. In watch-only mode nothing is submitted, so
+// every instance sizes and simulates, and the lifecycle ends as observed
+// where it would have been submitted.
+func consumeMEVShare(ctx context.Context, cfg *config.MEVShareConfig, backrunner *mevshare.Backrunner, submitter *mevshare.Submitter, recorder *opportunity.Recorder, lifecycle *pipeline.Machine, isLeader func() bool, watchOnly bool, controls *api.Controls, strategies *strategy.Set, pipelines *scoring.Set, heartbeats *heartbeat.Monitor, regimes *volatility.Detector, pairs map[uint64]string) {
+	log.Printf("📡 Consuming MEV-Share hints from %s", cfg.StreamURL)
+	err := mevshare.NewStream(cfg.StreamURL).Run(ctx, func(h *mevshare.Hint) {
+		heartbeats.Beat("mevshare")
+		candidates := backrunner.Candidates(h)
+		if len(candidates) == 0 {
+			return
+		}
+		best := candidates[0].Opportunity
+		instance := strategies.Route(config.StrategyKindBackrun, best)
+		if instance == nil {
+			return
+		}
+		best.Strategy = instance.Name
+		best.Explanation.Components = append(best.Explanation.Components, regimes.Feature(pairs[best.ChainID]))
+		executable := pipelines.Run(best)
+		decision := strategy.DecisionAccept
+		if !executable {
+			decision = strategy.DecisionReject
+		}
+		// Only backruns this instance will start count against its budget
+		start := executable && (watchOnly || isLeader()) && controls.Enabled(best.ChainID)
+		if start {
+			if err := strategies.Claim(instance, best.ID, backrunNotional(backrunner, best)); err != nil {
+				best.Explanation.Reject(failure.GuardrailFloor, err.Error())
+				decision, start = strategy.DecisionOverBudget, false
+			}
+		}
+		instance.Count(decision)
+		if err := recorder.Record(best); err != nil {
+			log.Printf("❌ Failed to journal backrun %s: %v", best.ID, err)
+		}
+		if !start {
+			return
+		}
+		if err := lifecycle.StartAt(best.ID, best.ChainID, best.Block); err != nil {
+			log.Printf("❌ Failed to track backrun %s: %v", best.ID, err)
+			strategies.Release(best.ID)
+			return
+		}
+		note := fmt.Sprintf("%s backrun of %s via %s", instance.Name, h.Hash.Hex(), best.Explanation.Route())
+		if err := lifecycle.Advance(best.ID, pipeline.StageScored, note); err != nil {
+			log.Printf("❌ Failed to track backrun %s: %v", best.ID, err)
+			// Failed rather than left in Scored, where nothing would pick it up
+			if ferr := lifecycle.FailAs(best.ID, failure.Unknown, err.Error()); ferr != nil {
+				log.Printf("❌ Failed to track backrun %s: %v", best.ID, ferr)
+			}
+			strategies.Release(best.ID)
+			return
+		}
+		submitBackrun(ctx, submitter, h, candidates[0], lifecycle, watchOnly, controls, strategies)
+	})
+	if err != nil && ctx.Err() == nil {
+		log.Printf("❌ MEV-Share stream stopped: %v", err)
+	}
+}
+
+// submitBackrun sizes, simulates and submits a started backrun, advancing
+// its lifecycle through each stage. The chain's switch is checked again
+// before signing, as the operator may have disabled it meanwhile. Watch-only
+// backruns are observed instead of signed, releasing their budget.
+func submitBackrun(ctx context.Context, submitter *mevshare.Submitter, h *mevshare.Hint, c *mevshare.Candidate, lifecycle *pipeline.Machine, watchOnly bool, controls *api.Controls, strategies *strategy.Set) {
+	o := c.Opportunity
+	stop := func(err error) {
+		reason := failure.Classify(err.Error())
+		switch {
+		case errors.Is(err, mevshare.ErrSizing):
+			reason = failure.GuardrailFloor
+		case errors.Is(err, mevshare.ErrSimulation):
+			reason = failure.SimulationRevert
+		case errors.Is(err, pipeline.ErrDuplicate):
+			reason = failure.Duplicate
+		}
+		log.Printf("⏭️ Backrun %s: %v", o.ID, err)
+		if err := lifecycle.FailAs(o.ID, reason, err.Error()); err != nil {
+			log.Printf("❌ Failed to track backrun %s: %v", o.ID, err)
+		}
+		strategies.Release(o.ID)
+	}
+	b, err := submitter.Size(h, c)
+	if err != nil {
+		stop(err)
+		return
+	}
+	o.Explanation.Guardrails = append(o.Explanation.Guardrails, b.Sizing.Guardrails()...)
+	if err := lifecycle.Advance(o.ID, pipeline.StageSized, backrunSizeNote(b)); err != nil {
+		stop(err)
+		return
+	}
+	if err := submitter.Simulate(ctx, b); err != nil {
+		stop(err)
+		return
+	}
+	if err := lifecycle.Advance(o.ID, pipeline.StageSimulated, fmt.Sprintf("OK at %s depth", b.Result.Depth)); err != nil {
+		stop(err)
+		return
+	}
+	if !controls.Enabled(o.ChainID) {
+		if err := lifecycle.AbandonAs(o.ID, failure.GuardrailFloor, "chain disabled by the operator"); err != nil {
+			log.Printf("❌ Failed to track backrun %s: %v", o.ID, err)
+		}
+		strategies.Release(o.ID)
+		return
+	}
+	if watchOnly {
+		if err := lifecycle.Observe(o.ID, "watch-only: "+backrunSizeNote(b)); err != nil {
+			log.Printf("❌ Failed to track backrun %s: %v", o.ID, err)
+		}
+		strategies.Release(o.ID)
+		return
+	}
+	sub, err := submitter.Sign(ctx, b)
+	if err != nil {
+		stop(err)
+		return
+	}
+	// Record before sending, so the replay guard sees the plan and a crash
+	// leaves a submitted record for the reconciler to follow
+	if err := lifecycle.Submit(o.ID, pipeline.TxRef{Hash: sub.Tx, From: sub.From, Nonce: sub.Nonce, Fingerprint: o.Fingerprint(), Block: sub.Block}); err != nil {
+		if errors.Is(err, freshness.ErrStale) {
+			// Submit already failed the record as a stale quote
+			log.Printf("⏭️ Backrun %s: %v", o.ID, err)
+			strategies.Release(o.ID)
+			return
+		}
+		stop(err)
+		return
+	}
+	if err := submitter.Send(ctx, sub); err != nil {
+		stop(err)
+		return
+	}
+	log.Printf("🚀 Backrun %s submitted: %s in bundle %s for block %d", o.ID, sub.Tx.Hex(), sub.Bundle.Hex(), sub.Block+1)
+}
+
+// backrunSizeNote describes a backrun's loan, e.g. "borrow 0.5 WETH (bound by max_tvl_share)"
+func backrunSizeNote(b *mevshare.Backrun) string {
+	amount, _ := units.FromBig(common.Address{}, b.Route.AmountIn, 18)
+	note := "borrow " + amount.String() + " WETH"
+	if b.Sizing.BoundBy != "" {
+		note += " (bound by " + b.Sizing.BoundBy + ")"
+	}
+	return note
+}
+
+// scoringStages implements the scoring pipeline's stages over the daemon's
+// components; pools, floors, trial, social and canaries may be nil
+func scoringStages(strategies *strategy.Set, controls *api.Controls, blackouts *blackout.Schedule, sequencers *sequencer.Monitor, regimes *volatility.Detector, pools *liquidity.Tracker, floors *minloan.Floors, notional func(*opportunity.Opportunity) units.USD, pairs map[uint64]string, trial *experiment.Experiment, social *signals.Hub, canaries *canary.Tracker) map[string]scoring.Stage {
+	return map[string]scoring.Stage{
+		// The min-profit floor widens with volatility, as the quote is more
+		// likely to have moved by the time the backrun lands, and in raising
+		// blackout windows; pausing windows keep scanning but never execute,
+		// as do L2s whose sequencer is down, restarting or lagging.
+		// Each leg's share of its pool shrinks while the pool drains, and the
+		// trade must be large enough that its edge pays the chain's gas.
+		config.ScoringFilters: func(o *opportunity.Opportunity) scoring.Verdict {
+			instance, ok := strategies.Get(o.Strategy)
+			if !ok {
+				return scoring.Reject(failure.GuardrailFloor, "unknown strategy "+o.Strategy)
+			}
+			window := blackouts.Now(blackout.StrategyBackrun)
+			limits := floors.Adjust(o.ChainID, window.Apply(regimes.Adjust(pairs[o.ChainID], instance.Limits(controls.Guardrails()))))
+			if !o.Explanation.GateProfit(units.DollarsToUSD(limits.MinProfitUSD)) {
+				return scoring.Reject(o.Explanation.Category, o.Explanation.Reason)
+			}
+			if usd := notional(o); usd > 0 && !o.Explanation.GateLoan(usd, units.DollarsToUSD(limits.MinLoanUSD)) {
+				return scoring.Reject(o.Explanation.Category, o.Explanation.Reason)
+			}
+			if !pools.Gate(o, limits) {
+				return scoring.Reject(o.Explanation.Category, o.Explanation.Reason)
+			}
+			if window.Pause {
+				return scoring.Reject(failure.GuardrailFloor, "blackout window "+window.Window)
+			}
+			if reason, halted := sequencers.Halted(o.ChainID); halted {
+				return scoring.Reject(failure.GuardrailFloor, reason)
+			}
+			return scoring.Pass(1)
+		},
+		config.ScoringTAR: func(o *opportunity.Opportunity) scoring.Verdict {
+			f := social.Features(o)
+			if f["pump_gated"] == 1 {
+				return scoring.Reject(failure.GuardrailFloor, fmt.Sprintf("token trending at pump probability %.2f", f["pump_probability"]))
+			}
+			return scoring.Pass(1 - f["pump_probability"])
+		},
+		config.ScoringML: func(o *opportunity.Opportunity) scoring.Verdict {
+			if !trial.Decide(o) {
+				return scoring.Reject(failure.ScoringReject, "score below the model's threshold")
+			}
+			return scoring.Pass(1)
+		},
+		config.ScoringRoutes: func(o *opportunity.Opportunity) scoring.Verdict {
+			return scoring.Pass(canaries.Proven(o.ChainID, o.Explanation.Route()))
+		},
+		// calm markets score 1, normal ones 0.5 and turbulent ones 0
+		config.ScoringRisk: func(o *opportunity.Opportunity) scoring.Verdict {
+			return scoring.Pass((1 - regimes.State(pairs[o.ChainID]).Regime.Level()) / 2)
+		},
+	}
+}
+
+// describeStages renders stages in order, e.g. "filters → ml (weight 2)"
+func describeStages(stages []config.ScoringStage) string {
+	if len(stages) == 0 {
+		return "none"
+	}
+	names := make([]string, len(stages))
+	for i, stage := range stages {
+		names[i] = stage.Name
+		if stage.Weight != 1 {
+			names[i] += fmt.Sprintf(" (weight %g)", stage.Weight)
+		}
+	}
+	return strings.Join(names, " → ")
+}
+
+// backrunNotional values a backrun's input for its instance's in-flight
+// budget; unpriced inputs reserve nothing
+func backrunNotional(backrunner *mevshare.Backrunner, o *opportunity.Opportunity) units.USD {
+	if backrunner.ToUSD == nil || o.AmountIn == nil {
+		return 0
+	}
+	usd, err := backrunner.ToUSD(o.AmountIn)
+	if err != nil {
+		return 0
+	}
+	return usd
+}
+
+// watchDepegs alerts when a watched stablecoin's CEX price leaves its peg and
+// again when it recovers
+func watchDepegs(ctx context.Context, board *cex.Board, cfg *config.CEXConfig, alerts *alert.Dispatcher) {
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+	depegged := make(map[string]bool)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		now := make(map[string]bool)
+		for _, d := range board.Depegs(cfg.Stablecoins, float64(cfg.DepegBps)) {
+			now[d.Asset] = true
+			if !depegged[d.Asset] {
+				alerts.Notify(ctx, alert.Message{Level: alert.LevelCritical, Title: "Depeg: " + d.Asset, Body: d.String()})
+			}
+		}
+		for asset := range depegged {
+			if now[asset] {
+				continue
+			}
+			// A stale feed is not a recovery; keep the depeg until a fresh quote shows one
+			if _, err := board.Reference(asset); err != nil {
+				now[asset] = true
+				continue
+			}
+			alerts.Notify(ctx, alert.Message{Level: alert.LevelInfo, Title: "Peg restored: " + asset})
+		}
+		depegged = now
+	}
+}
+
+// floorShape is the typical trade whose gas sets a chain's minimum trade size
+var floorShape = []string{"univ2", "univ2"}
+
+// newLoanFloors derives each chain's minimum trade size from the gas of a
+// typical trade at its suggested fees, or returns nil when disabled
+func newLoanFloors(cfg *config.Config, fees *gas.Oracle, history *gas.History, nativePrices *prices.Tracker) *minloan.Floors {
+	cost := func(ctx context.Context, chainID uint64) (units.USD, error) {
+		suggestion, err := fees.Suggest(ctx, chainID)
+		if err != nil {
+			return 0, err
+		}
+		price := new(big.Int).Add(suggestion.BaseFee, suggestion.TipCap)
+		return nativePrices.GasCostUSD(chainID, history.Estimate(gas.Shape{ChainID: chainID, Adapters: floorShape}).Gas, price)
+	}
+	return minloan.FromConfig(cfg.MinLoan, cost, metrics.Default)
+}
+
+// newGasOracle estimates fees from configured gas stations, falling back to the
+// chains' nodes, which tip from fee history where the provider serves it
+func newGasOracle(cfg *config.Config, providers *rpc.Router) *gas.Oracle {
+	dial := func(chainID uint64) (chain.Client, error) {
+		return providers.Client(chainID, rpc.PriorityHigh)
+	}
+	node := gas.NewNodeSource(dial)
+	node.FeeHistory = func(chainID uint64) bool {
+		return providers.Supports(chainID, rpc.PriorityHigh, rpc.CapFeeHistory)
+	}
+	return gas.NewOracle(metrics.Default,
+		gas.NewGasStationSource(gas.GasStationsFromConfig(cfg)),
+		node,
+	)
+}
+
+// priceCheck names a chain's price refresh heartbeat, e.g. prices-polygon
+func priceCheck(chainID uint64) string {
+	return "prices-" + enum.ChainID(chainID).Name()
+}
+
+// volatilityPairs names each chain's native/USD pair, e.g. ETH/USD. feeds
+// marks the one chain whose price refresh samples each pair, so chains
+// sharing a native token don't interleave their sources.
+func volatilityPairs(cfg *config.Config) (pairs map[uint64]string, feeds map[uint64]bool) {
+	pairs, feeds = make(map[uint64]string), make(map[uint64]bool)
+	fed := make(map[string]bool)
+	for _, id := range rpcChains(cfg) {
+		if cfg.Chains[id].Native == "" {
+			continue
+		}
+		pair := strings.ToUpper(cfg.Chains[id].Native) + "/USD"
+		pairs[id] = pair
+		if !fed[pair] {
+			fed[pair], feeds[id] = true, true
+		}
+	}
+	return pairs, feeds
+}
+
+// rpcChains lists the configured chains that have an RPC endpoint, plain
+// or regional
+func rpcChains(cfg *config.Config) []uint64 {
+	var ids []uint64
+	for id, chain := range cfg.Chains {
+		if chain.RPC != "" || len(chain.RegionRPCs) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// recordReversal retracts any PnL journaled for a transaction reorged out of o.BlockHash
+func recordReversal(recorder *opportunity.Recorder, lifecycle *pipeline.Machine, o pipeline.Outcome) error {
+	r, ok := lifecycle.Get(o.ID)
+	if !ok || r.Tx == nil {
+		return fmt.Errorf("no submitted transaction on record")
+	}
+	return recorder.RecordReversal(&opportunity.Reversal{
+		OpportunityID: o.ID,
+		ChainID:       r.ChainID,
+		TxHash:        r.Tx.Hash,
+		BlockHash:     o.BlockHash,
+		Reason:        o.Detail,
+		Time:          time.Now().UTC(),
+	})
+}
+
+// watchStuck alerts once for each opportunity that overstays its stage
+func watchStuck(ctx context.Context, lifecycle *pipeline.Machine, alerts *alert.Dispatcher) {
+	reported := make(map[string]pipeline.Stage)
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		stuck := make(map[string]pipeline.Stage)
+		for _, r := range lifecycle.Stuck(pipeline.DefaultStuckLimits) {
+			stuck[r.ID] = r.Stage
+			if reported[r.ID] == r.Stage {
+				continue
+			}
+			alerts.Notify(ctx, alert.Message{
+				Level: alert.LevelWarning,
+				Title: fmt.Sprintf("Opportunity %s stuck in %s", r.ID, r.Stage),
+				Body:  fmt.Sprintf("Chain %d, in stage since %s", r.ChainID, r.Since.UTC().Format(time.RFC3339)),
+			})
+		}
+		reported = stuck
+	}
+}
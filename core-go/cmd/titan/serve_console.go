@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/api"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/dex"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/freshness"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/impact"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/quotes"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/rpc"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/simulation"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// quoteRow is one adapter's answer at /quote
+type quoteRow struct {
+	Adapter   string        `json:"adapter"`
+	Quote     *quotes.Quote `json:"quote,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	LatencyMs int64         `json:"latencyMs"`
+}
+
+// quoteResponse is served at /quote
+type quoteResponse struct {
+	TokenIn   tokens.Token  `json:"tokenIn"`
+	TokenOut  tokens.Token  `json:"tokenOut"`
+	AmountIn  units.Amount  `json:"amountIn"`
+	AmountOut *units.Amount `json:"amountOut,omitempty"` // set for exact-output quotes
+	Best      *quotes.Quote `json:"best,omitempty"`
+	Results   []quoteRow    `json:"results"`
+}
+
+// simulateRequest is a manual simulation posted to /simulate
+type simulateRequest struct {
+	ChainID           uint64         `json:"chainId"`
+	From              common.Address `json:"from"`
+	To                common.Address `json:"to"`
+	Data              hexutil.Bytes  `json:"data"`
+	Value             *hexutil.Big   `json:"value,omitempty"`
+	Gas               uint64         `json:"gas,omitempty"`
+	ExpectedProfitUSD uint64         `json:"expectedProfitUsd,omitempty"` // whole dollars; drives depth escalation
+}
+
+// consoleEndpoints serves the daemon state `titan console` queries beyond
+// the read-only endpoints: on-chain quotes, the reserve cache and manual
+// simulations. Quote services and simulation runners are built per chain on
+// first use.
+type consoleEndpoints struct {
+	cfg       *config.Config
+	providers *rpc.Router
+	registry  *tokens.Registry
+	reserves  *reserves.Cache
+
+	mu      sync.Mutex
+	quoters map[uint64]*quotes.Service
+	runners map[uint64]*simulation.Runner
+}
+
+func newConsoleEndpoints(cfg *config.Config, providers *rpc.Router, registry *tokens.Registry, cache *reserves.Cache) *consoleEndpoints {
+	return &consoleEndpoints{
+		cfg:       cfg,
+		providers: providers,
+		registry:  registry,
+		reserves:  cache,
+		quoters:   make(map[uint64]*quotes.Service),
+		runners:   make(map[uint64]*simulation.Runner),
+	}
+}
+
+// register adds /quote, /impact, /cache/reserves and /simulate; under API
+// auth simulations need an operator key
+func (e *consoleEndpoints) register(server *api.Server) {
+	server.Handle("/quote", e.handleQuote)
+	server.Handle("/impact", e.handleImpact)
+	server.Handle("/cache/reserves", e.handleReserves)
+	server.Handle("/simulate", e.handleSimulate)
+}
+
+// GET /quote?chain=137&in=USDC&out=WETH&amount=10000, or receive=5 in place
+// of amount for the cost of ending with exactly 5 WETH
+func (e *consoleEndpoints) handleQuote(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	chainID, err := strconv.ParseUint(q.Get("chain"), 10, 64)
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, "chain query parameter required")
+		return
+	}
+	tokenIn, err := e.registry.Lookup(chainID, q.Get("in"))
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tokenOut, err := e.registry.Lookup(chainID, q.Get("out"))
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req := quotes.Request{ChainID: chainID, TokenIn: tokenIn, TokenOut: tokenOut, Sender: common.HexToAddress(quotePlaceholderSender)}
+	if receive := q.Get("receive"); receive != "" {
+		req.AmountOut, err = units.Parse(tokenOut.Address, receive, tokenOut.Decimals)
+	} else {
+		req.AmountIn, err = units.Parse(tokenIn.Address, q.Get("amount"), tokenIn.Decimals)
+	}
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	svc, err := e.quoter(r.Context(), chainID)
+	if err != nil {
+		api.WriteError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	best, results, _ := svc.Best(r.Context(), req)
+	resp := quoteResponse{TokenIn: tokenIn, TokenOut: tokenOut, AmountIn: req.AmountIn, Best: best, Results: make([]quoteRow, len(results))}
+	if req.ExactOutput() {
+		resp.AmountOut = &req.AmountOut
+		if best != nil {
+			resp.AmountIn = *best.AmountIn
+		}
+	}
+	for i, res := range results {
+		resp.Results[i] = quoteRow{Adapter: res.Adapter, Quote: res.Quote, LatencyMs: res.Latency.Milliseconds()}
+		if res.Err != nil {
+			resp.Results[i].Error = res.Err.Error()
+		}
+	}
+	api.WriteJSON(w, http.StatusOK, resp)
+}
+
+// GET /impact?chain=137&in=USDC&pools=0xA,0xB&max=100000&points=20 traces
+// the output curve of swapping up to max USDC through the cached pools in
+// order
+func (e *consoleEndpoints) handleImpact(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	chainID, err := strconv.ParseUint(q.Get("chain"), 10, 64)
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, "chain query parameter required")
+		return
+	}
+	tokenIn, err := e.registry.Lookup(chainID, q.Get("in"))
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	maxIn, err := units.Parse(tokenIn.Address, q.Get("max"), tokenIn.Decimals)
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	points := 20
+	if v := q.Get("points"); v != "" {
+		if points, err = strconv.Atoi(v); err != nil {
+			api.WriteError(w, http.StatusBadRequest, "invalid points")
+			return
+		}
+	}
+	var snapshots []*reserves.Snapshot
+	for _, pool := range strings.Split(q.Get("pools"), ",") {
+		if !common.IsHexAddress(pool) {
+			api.WriteError(w, http.StatusBadRequest, fmt.Sprintf("invalid pool address %q", pool))
+			return
+		}
+		s, ok := e.reserves.Get(chainID, common.HexToAddress(pool), 0)
+		if !ok {
+			api.WriteError(w, http.StatusNotFound, fmt.Sprintf("pool %s is not in the reserve cache", pool))
+			return
+		}
+		snapshots = append(snapshots, s)
+	}
+	path, out, err := impact.Path(snapshots, tokenIn.Address)
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tokenOut, err := e.registry.Lookup(chainID, out.Hex())
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	curve, err := impact.Build(chainID, path, tokenIn, tokenOut, maxIn, points)
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	api.WriteJSON(w, http.StatusOK, curve)
+}
+
+// GET /cache/reserves?chain=1
+func (e *consoleEndpoints) handleReserves(w http.ResponseWriter, r *http.Request) {
+	chainID, err := strconv.ParseUint(r.URL.Query().Get("chain"), 10, 64)
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, "chain query parameter required")
+		return
+	}
+	api.WriteJSON(w, http.StatusOK, e.reserves.All(chainID))
+}
+
+// POST /simulate with a simulateRequest body
+func (e *consoleEndpoints) handleSimulate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		api.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req simulateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.To == (common.Address{}) {
+		api.WriteError(w, http.StatusBadRequest, "to address required")
+		return
+	}
+	runner, err := e.runner(r.Context(), req.ChainID)
+	if err != nil {
+		api.WriteError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	call := simulation.Call{From: req.From, To: req.To, Data: req.Data, Gas: req.Gas}
+	if req.Value != nil {
+		call.Value = (*big.Int)(req.Value)
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	res, err := runner.Simulate(ctx, simulation.Candidate{
+		ChainID:           req.ChainID,
+		Bundle:            []simulation.Call{call},
+		ExpectedProfitUSD: units.DollarsToUSD(req.ExpectedProfitUSD),
+	})
+	if err != nil {
+		api.WriteError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	api.WriteJSON(w, http.StatusOK, res)
+}
+
+// quoter returns the chain's on-chain pool quoting service, classifying its
+// routers on first use
+func (e *consoleEndpoints) quoter(ctx context.Context, chainID uint64) (*quotes.Service, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if svc, ok := e.quoters[chainID]; ok {
+		return svc, nil
+	}
+	client, err := e.providers.Client(chainID, rpc.PriorityHigh)
+	if err != nil {
+		return nil, err
+	}
+	for _, err := range dex.DetectRouterTypes(ctx, client, e.cfg.DexRouters[chainID]) {
+		log.Printf("⚠️ Router detection: %v", err)
+	}
+	svc := quotes.NewService(quotes.DefaultTimeout, quotes.PoolAdapters(dex.NewDiscovery(chainID, client), dex.RoutersFromConfig(e.cfg, chainID))...)
+	svc.Freshness = freshness.FromConfig(e.cfg, metrics.Default)
+	e.quoters[chainID] = svc
+	return svc, nil
+}
+
+// runner returns the chain's simulation runner
+func (e *consoleEndpoints) runner(ctx context.Context, chainID uint64) (*simulation.Runner, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if runner, ok := e.runners[chainID]; ok {
+		return runner, nil
+	}
+	client, err := e.providers.Client(chainID, rpc.PriorityHigh)
+	if err != nil {
+		return nil, err
+	}
+	runner, err := simulation.RunnerFromConfig(ctx, e.cfg, chainID, client)
+	if err != nil {
+		return nil, fmt.Errorf("simulation runner: %w", err)
+	}
+	e.runners[chainID] = runner
+	return runner, nil
+}
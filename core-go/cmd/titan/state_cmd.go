@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/seal"
+)
+
+// stateFiles are the data-directory logs sealed at rest
+var stateFiles = []string{"journal.jsonl", "audit.jsonl", "pipeline.jsonl", "rollups.jsonl"}
+
+const stateUsage = "usage: titan state keygen <id> | seal | rekey"
+
+// runState implements `titan state`, managing encryption of state at rest:
+//
+//	keygen <id>  print a new key entry for TITAN_STATE_KEYS
+//	seal         seal a secret read from stdin, e.g. for PRIVATE_KEY
+//	rekey        reseal the data directory's logs under the primary key
+//
+// To rotate, put a new key first in TITAN_STATE_KEYS, keep the old one after
+// it, stop the daemon, run rekey, then drop the old key.
+func runState(args []string) error {
+	if len(args) == 0 {
+		return errors.New(stateUsage)
+	}
+	switch args[0] {
+	case "keygen":
+		if len(args) != 2 {
+			return errors.New("usage: titan state keygen <id>")
+		}
+		key, err := seal.GenerateKey()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s:%s\n", args[1], key)
+		return nil
+	case "seal":
+		return runStateSeal()
+	case "rekey":
+		return runStateRekey(args[1:])
+	}
+	return errors.New(stateUsage)
+}
+
+// runStateSeal seals one line from stdin under the primary key
+func runStateSeal() error {
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	if cfg.StateKeys == nil {
+		return errors.New("TITAN_STATE_KEYS is not set")
+	}
+	secret, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && secret == "" {
+		return fmt.Errorf("read secret: %w", err)
+	}
+	sealed, err := cfg.StateKeys.Seal([]byte(strings.TrimRight(secret, "\r\n")))
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(sealed))
+	return nil
+}
+
+// runStateRekey reseals the data directory's logs; with TITAN_STATE_KEYS
+// unset it only checks they are plaintext
+func runStateRekey(args []string) error {
+	fs := flag.NewFlagSet("state rekey", flag.ContinueOnError)
+	dataDir := fs.String("data", "", "data directory (defaults to TITAN_DATA_DIR)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+	if *dataDir == "" {
+		*dataDir = cfg.DataDir
+	}
+	target := "plaintext"
+	if cfg.StateKeys != nil {
+		target = "key " + cfg.StateKeys.Primary()
+	}
+	for _, name := range stateFiles {
+		path := filepath.Join(*dataDir, name)
+		n, err := journal.Reseal(path, cfg.StateKeys)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🔐 %s: %d lines now under %s\n", path, n, target)
+	}
+	return nil
+}
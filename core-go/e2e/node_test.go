@@ -0,0 +1,963 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/route"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/simulation"
+)
+
+// gasPerTx is the gas every mined transaction reports; the node charges none
+const gasPerTx = 150_000
+
+// Storage layouts of the modelled contracts, as deployed on mainnet: an
+// OpenZeppelin ERC20's balances and allowances, a UniswapV2 pair's tokens
+// and packed reserves, and the executor's owner
+var (
+	tokenLayout    = simulation.TokenLayout{BalanceSlot: 0, AllowanceSlot: 1}
+	pairToken0Slot = common.BigToHash(big.NewInt(6))
+	pairToken1Slot = common.BigToHash(big.NewInt(7))
+	v2ReservesSlot = common.BigToHash(big.NewInt(8))
+	ownerSlot      = common.Hash{}
+	mask112        = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 112), big.NewInt(1))
+)
+
+var (
+	tokenABI = mustABI(`[
+		{"name":"balanceOf","type":"function","inputs":[{"name":"holder","type":"address"}],"outputs":[{"type":"uint256"}]},
+		{"name":"allowance","type":"function","inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"outputs":[{"type":"uint256"}]},
+		{"name":"decimals","type":"function","inputs":[],"outputs":[{"type":"uint8"}]},
+		{"name":"symbol","type":"function","inputs":[],"outputs":[{"type":"string"}]},
+		{"name":"approve","type":"function","inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"type":"bool"}]},
+		{"name":"transfer","type":"function","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"type":"bool"}]},
+		{"name":"transferFrom","type":"function","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"type":"bool"}]}
+	]`)
+	pairABI = mustABI(`[
+		{"name":"token0","type":"function","inputs":[],"outputs":[{"type":"address"}]},
+		{"name":"token1","type":"function","inputs":[],"outputs":[{"type":"address"}]},
+		{"name":"getReserves","type":"function","inputs":[],"outputs":[{"type":"uint112"},{"type":"uint112"},{"type":"uint32"}]},
+		{"name":"swap","type":"function","inputs":[{"name":"amount0Out","type":"uint256"},{"name":"amount1Out","type":"uint256"},{"name":"to","type":"address"},{"name":"data","type":"bytes"}],"outputs":[]}
+	]`)
+	routerABI = mustABI(`[
+		{"name":"swapExactTokensForTokens","type":"function","inputs":[{"name":"amountIn","type":"uint256"},{"name":"amountOutMin","type":"uint256"},{"name":"path","type":"address[]"},{"name":"to","type":"address"},{"name":"deadline","type":"uint256"}],"outputs":[{"name":"amounts","type":"uint256[]"}]}
+	]`)
+	executorABI = mustABI(`[
+		{"name":"owner","type":"function","inputs":[],"outputs":[{"type":"address"}]},
+		{"name":"execute","type":"function","inputs":[{"name":"flashSource","type":"uint8"},{"name":"token","type":"address"},{"name":"amount","type":"uint256"},{"name":"routeData","type":"bytes"}],"outputs":[{"type":"uint256"}]}
+	]`)
+	revertArgs = abi.Arguments{{Type: mustType("string")}}
+)
+
+var (
+	syncTopic     = crypto.Keccak256Hash([]byte("Sync(uint112,uint112)"))
+	transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+)
+
+func mustABI(def string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(def))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func mustType(name string) abi.Type {
+	t, err := abi.NewType(name, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// reverted is a contract revert, reported over JSON-RPC as geth does: code
+// 3 with the Error(string) payload as data
+type reverted struct{ reason string }
+
+func (r *reverted) Error() string {
+	if r.reason == "" {
+		return "execution reverted"
+	}
+	return "execution reverted: " + r.reason
+}
+
+func (r *reverted) ErrorCode() int { return 3 }
+
+func (r *reverted) ErrorData() interface{} {
+	if r.reason == "" {
+		return "0x"
+	}
+	packed, _ := revertArgs.Pack(r.reason)
+	return hexutil.Encode(append(common.FromHex("0x08c379a0"), packed...))
+}
+
+// account is one account of the local chain
+type account struct {
+	balance *big.Int
+	nonce   uint64
+	code    []byte
+	storage map[common.Hash]common.Hash
+}
+
+// world is the local chain's state. Calls run on a copy, so a revert
+// anywhere discards everything the call did.
+type world map[common.Address]*account
+
+func (w world) copy() world {
+	out := make(world, len(w))
+	for addr, acc := range w {
+		storage := make(map[common.Hash]common.Hash, len(acc.storage))
+		for k, v := range acc.storage {
+			storage[k] = v
+		}
+		out[addr] = &account{balance: new(big.Int).Set(acc.balance), nonce: acc.nonce, code: acc.code, storage: storage}
+	}
+	return out
+}
+
+func (w world) get(addr common.Address) *account {
+	acc, ok := w[addr]
+	if !ok {
+		acc = &account{balance: new(big.Int), storage: make(map[common.Hash]common.Hash)}
+		w[addr] = acc
+	}
+	return acc
+}
+
+func (w world) load(addr common.Address, slot common.Hash) *big.Int {
+	return w.get(addr).storage[slot].Big()
+}
+
+func (w world) store(addr common.Address, slot common.Hash, value *big.Int) {
+	w.get(addr).storage[slot] = common.BigToHash(value)
+}
+
+// override applies eth_call state overrides
+func (w world) override(over map[common.Address]overrideArgs) {
+	for addr, o := range over {
+		acc := w.get(addr)
+		if o.Balance != nil {
+			acc.balance = o.Balance.ToInt()
+		}
+		if o.Nonce != nil {
+			acc.nonce = uint64(*o.Nonce)
+		}
+		if o.Code != nil {
+			acc.code = *o.Code
+		}
+		if o.State != nil {
+			acc.storage = make(map[common.Hash]common.Hash)
+		}
+		for _, diff := range []map[common.Hash]common.Hash{o.State, o.StateDiff} {
+			for k, v := range diff {
+				acc.storage[k] = v
+			}
+		}
+	}
+}
+
+// model is the Go stand-in for a contract's bytecode: it answers the
+// methods of its ABI, keeping all of its state in storage
+type model struct {
+	abi abi.ABI
+	run func(e *evm, self, caller common.Address, method string, args []interface{}) ([]interface{}, error)
+}
+
+// evm executes calls against a world
+type evm struct {
+	w      world
+	models map[common.Address]*model
+	time   uint64
+	logs   []*types.Log
+}
+
+// call runs input on to as caller. Accounts without a model accept any
+// call and return nothing, as an EOA does.
+func (e *evm) call(caller, to common.Address, input []byte) ([]byte, error) {
+	m, ok := e.models[to]
+	if !ok || len(e.w.get(to).code) == 0 {
+		return nil, nil
+	}
+	if len(input) < 4 {
+		return nil, &reverted{}
+	}
+	method, err := m.abi.MethodById(input[:4])
+	if err != nil {
+		return nil, &reverted{}
+	}
+	args, err := method.Inputs.Unpack(input[4:])
+	if err != nil {
+		return nil, &reverted{}
+	}
+	out, err := m.run(e, to, caller, method.Name, args)
+	if err != nil {
+		return nil, err
+	}
+	return method.Outputs.Pack(out...)
+}
+
+// invoke calls a method of the contract at to through its ABI
+func (e *evm) invoke(caller, to common.Address, contract abi.ABI, method string, args ...interface{}) ([]interface{}, error) {
+	input, err := contract.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := e.call(caller, to, input)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 && len(contract.Methods[method].Outputs) > 0 {
+		return nil, &reverted{reason: method + " on " + to.Hex() + ", which has no code"}
+	}
+	return contract.Unpack(method, out)
+}
+
+func (e *evm) balanceOf(token, holder common.Address) (*big.Int, error) {
+	out, err := e.invoke(holder, token, tokenABI, "balanceOf", holder)
+	if err != nil {
+		return nil, err
+	}
+	return out[0].(*big.Int), nil
+}
+
+func (e *evm) transfer(token, from, to common.Address, amount *big.Int) error {
+	_, err := e.invoke(from, token, tokenABI, "transfer", to, amount)
+	return err
+}
+
+func (e *evm) emit(addr common.Address, topics []common.Hash, data []byte) {
+	e.logs = append(e.logs, &types.Log{Address: addr, Topics: topics, Data: data})
+}
+
+// tokenModel is an ERC20 with tokenLayout storage
+func tokenModel(symbol string, decimals uint8) *model {
+	move := func(e *evm, self, from, to common.Address, amount *big.Int) error {
+		balance := e.w.load(self, tokenLayout.BalanceKey(from))
+		if balance.Cmp(amount) < 0 {
+			return &reverted{reason: "ERC20: transfer amount exceeds balance"}
+		}
+		e.w.store(self, tokenLayout.BalanceKey(from), balance.Sub(balance, amount))
+		received := e.w.load(self, tokenLayout.BalanceKey(to))
+		e.w.store(self, tokenLayout.BalanceKey(to), received.Add(received, amount))
+		e.emit(self, []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())}, common.BigToHash(amount).Bytes())
+		return nil
+	}
+	return &model{abi: tokenABI, run: func(e *evm, self, caller common.Address, method string, args []interface{}) ([]interface{}, error) {
+		switch method {
+		case "balanceOf":
+			return []interface{}{e.w.load(self, tokenLayout.BalanceKey(args[0].(common.Address)))}, nil
+		case "allowance":
+			return []interface{}{e.w.load(self, tokenLayout.AllowanceKey(args[0].(common.Address), args[1].(common.Address)))}, nil
+		case "decimals":
+			return []interface{}{decimals}, nil
+		case "symbol":
+			return []interface{}{symbol}, nil
+		case "approve":
+			e.w.store(self, tokenLayout.AllowanceKey(caller, args[0].(common.Address)), args[1].(*big.Int))
+			return []interface{}{true}, nil
+		case "transfer":
+			return []interface{}{true}, move(e, self, caller, args[0].(common.Address), args[1].(*big.Int))
+		case "transferFrom":
+			from, amount := args[0].(common.Address), args[2].(*big.Int)
+			if caller != from {
+				key := tokenLayout.AllowanceKey(from, caller)
+				allowed := e.w.load(self, key)
+				if allowed.Cmp(amount) < 0 {
+					return nil, &reverted{reason: "ERC20: insufficient allowance"}
+				}
+				e.w.store(self, key, allowed.Sub(allowed, amount))
+			}
+			return []interface{}{true}, move(e, self, from, args[1].(common.Address), amount)
+		}
+		return nil, &reverted{}
+	}}
+}
+
+// pairModel is a UniswapV2 pair: swaps pay out first and then require the
+// pair's balances to keep k after the 0.3% fee, as the real pair does
+func pairModel() *model {
+	return &model{abi: pairABI, run: func(e *evm, self, caller common.Address, method string, args []interface{}) ([]interface{}, error) {
+		token0 := common.BigToAddress(e.w.load(self, pairToken0Slot))
+		token1 := common.BigToAddress(e.w.load(self, pairToken1Slot))
+		reserve0, reserve1 := unpackReserves(e.w.load(self, v2ReservesSlot))
+		switch method {
+		case "token0":
+			return []interface{}{token0}, nil
+		case "token1":
+			return []interface{}{token1}, nil
+		case "getReserves":
+			stamp := new(big.Int).Rsh(e.w.load(self, v2ReservesSlot), 224)
+			return []interface{}{reserve0, reserve1, uint32(stamp.Uint64())}, nil
+		case "swap":
+			out0, out1, to := args[0].(*big.Int), args[1].(*big.Int), args[2].(common.Address)
+			if out0.Sign() == 0 && out1.Sign() == 0 {
+				return nil, &reverted{reason: "UniswapV2: INSUFFICIENT_OUTPUT_AMOUNT"}
+			}
+			if out0.Cmp(reserve0) >= 0 || out1.Cmp(reserve1) >= 0 {
+				return nil, &reverted{reason: "UniswapV2: INSUFFICIENT_LIQUIDITY"}
+			}
+			if len(args[3].([]byte)) > 0 {
+				return nil, &reverted{reason: "flash swaps are not modelled"}
+			}
+			for _, pay := range []struct {
+				token  common.Address
+				amount *big.Int
+			}{{token0, out0}, {token1, out1}} {
+				if pay.amount.Sign() > 0 {
+					if err := e.transfer(pay.token, self, to, pay.amount); err != nil {
+						return nil, err
+					}
+				}
+			}
+			balance0, err := e.balanceOf(token0, self)
+			if err != nil {
+				return nil, err
+			}
+			balance1, err := e.balanceOf(token1, self)
+			if err != nil {
+				return nil, err
+			}
+			in0, in1 := paidIn(balance0, reserve0, out0), paidIn(balance1, reserve1, out1)
+			if in0.Sign() == 0 && in1.Sign() == 0 {
+				return nil, &reverted{reason: "UniswapV2: INSUFFICIENT_INPUT_AMOUNT"}
+			}
+			adjusted0 := new(big.Int).Sub(new(big.Int).Mul(balance0, big.NewInt(1000)), new(big.Int).Mul(in0, big.NewInt(3)))
+			adjusted1 := new(big.Int).Sub(new(big.Int).Mul(balance1, big.NewInt(1000)), new(big.Int).Mul(in1, big.NewInt(3)))
+			k := new(big.Int).Mul(new(big.Int).Mul(reserve0, reserve1), big.NewInt(1_000_000))
+			if new(big.Int).Mul(adjusted0, adjusted1).Cmp(k) < 0 {
+				return nil, &reverted{reason: "UniswapV2: K"}
+			}
+			e.w.store(self, v2ReservesSlot, packReserves(balance0, balance1, e.time))
+			e.emit(self, []common.Hash{syncTopic}, append(common.BigToHash(balance0).Bytes(), common.BigToHash(balance1).Bytes()...))
+			return nil, nil
+		}
+		return nil, &reverted{}
+	}}
+}
+
+// paidIn is what a swap paid into one side of a pair: its balance above the
+// reserve left after paying out
+func paidIn(balance, reserve, out *big.Int) *big.Int {
+	left := new(big.Int).Sub(reserve, out)
+	if balance.Cmp(left) <= 0 {
+		return new(big.Int)
+	}
+	return left.Sub(balance, left)
+}
+
+func packReserves(reserve0, reserve1 *big.Int, stamp uint64) *big.Int {
+	packed := new(big.Int).SetUint64(stamp & 0xffffffff)
+	packed.Lsh(packed, 112).Or(packed, new(big.Int).And(reserve1, mask112))
+	return packed.Lsh(packed, 112).Or(packed, new(big.Int).And(reserve0, mask112))
+}
+
+func unpackReserves(packed *big.Int) (*big.Int, *big.Int) {
+	reserve0 := new(big.Int).And(packed, mask112)
+	reserve1 := new(big.Int).And(new(big.Int).Rsh(packed, 112), mask112)
+	return reserve0, reserve1
+}
+
+// amountOut is UniswapV2's getAmountOut: amountIn after the 0.3% fee
+// against the constant product
+func amountOut(amountIn, reserveIn, reserveOut *big.Int) *big.Int {
+	in := new(big.Int).Mul(amountIn, big.NewInt(997))
+	num := new(big.Int).Mul(in, reserveOut)
+	den := new(big.Int).Add(new(big.Int).Mul(reserveIn, big.NewInt(1000)), in)
+	return num.Quo(num, den)
+}
+
+// routerModel is a UniswapV2 Router02 over pairs, keyed by their sorted
+// tokens as its factory would find them: swapExactTokensForTokens pulls
+// amountIn from the caller into the first pair, which needs the caller's
+// approval, and swaps along path, paying the last hop's output to to
+func routerModel(pairs map[[2]common.Address]common.Address) *model {
+	pairFor := func(a, b common.Address) (common.Address, bool) {
+		if bytes.Compare(a.Bytes(), b.Bytes()) > 0 {
+			a, b = b, a
+		}
+		pair, ok := pairs[[2]common.Address{a, b}]
+		return pair, ok
+	}
+	return &model{abi: routerABI, run: func(e *evm, self, caller common.Address, method string, args []interface{}) ([]interface{}, error) {
+		if method != "swapExactTokensForTokens" {
+			return nil, &reverted{}
+		}
+		amountIn, amountOutMin, path := args[0].(*big.Int), args[1].(*big.Int), args[2].([]common.Address)
+		to, deadline := args[3].(common.Address), args[4].(*big.Int)
+		if deadline.Cmp(new(big.Int).SetUint64(e.time)) < 0 {
+			return nil, &reverted{reason: "UniswapV2Router: EXPIRED"}
+		}
+		if len(path) < 2 {
+			return nil, &reverted{reason: "UniswapV2Library: INVALID_PATH"}
+		}
+		hops := make([]common.Address, len(path)-1)
+		amounts := []*big.Int{amountIn}
+		for i := range hops {
+			pair, ok := pairFor(path[i], path[i+1])
+			if !ok {
+				return nil, &reverted{}
+			}
+			reserves, err := e.invoke(self, pair, pairABI, "getReserves")
+			if err != nil {
+				return nil, err
+			}
+			reserveIn, reserveOut := reserves[0].(*big.Int), reserves[1].(*big.Int)
+			if bytes.Compare(path[i].Bytes(), path[i+1].Bytes()) > 0 {
+				reserveIn, reserveOut = reserveOut, reserveIn
+			}
+			hops[i] = pair
+			amounts = append(amounts, amountOut(amounts[i], reserveIn, reserveOut))
+		}
+		if amounts[len(amounts)-1].Cmp(amountOutMin) < 0 {
+			return nil, &reverted{reason: "UniswapV2Router: INSUFFICIENT_OUTPUT_AMOUNT"}
+		}
+		if _, err := e.invoke(self, path[0], tokenABI, "transferFrom", caller, hops[0], amountIn); err != nil {
+			return nil, err
+		}
+		for i, pair := range hops {
+			out0, out1 := new(big.Int), amounts[i+1]
+			if bytes.Compare(path[i].Bytes(), path[i+1].Bytes()) > 0 {
+				out0, out1 = amounts[i+1], new(big.Int)
+			}
+			recipient := to
+			if i < len(hops)-1 {
+				recipient = hops[i+1]
+			}
+			if _, err := e.invoke(self, pair, pairABI, "swap", out0, out1, recipient, []byte{}); err != nil {
+				return nil, err
+			}
+		}
+		return []interface{}{amounts}, nil
+	}}
+}
+
+// executorModel is the executor per docs/CANONICAL_SPECIFICATION.md, for
+// UniswapV2 routes: only its owner may execute; the loan comes from the
+// lender's balance, and each hop approves its routersOrPools entry, the V2
+// router, for the previous hop's output and swaps it there with
+// swapExactTokensForTokens. The call reverts unless the route returns the
+// loan plus the lender's premium. The profit stays in the executor and is
+// returned.
+func executorModel(lenders map[route.FlashSource]common.Address) *model {
+	return &model{abi: executorABI, run: func(e *evm, self, caller common.Address, method string, args []interface{}) ([]interface{}, error) {
+		owner := common.BigToAddress(e.w.load(self, ownerSlot))
+		switch method {
+		case "owner":
+			return []interface{}{owner}, nil
+		case "execute":
+		default:
+			return nil, &reverted{}
+		}
+		if caller != owner {
+			return nil, &reverted{reason: "Ownable: caller is not the owner"}
+		}
+		source, token, amount := route.FlashSource(args[0].(uint8)), args[1].(common.Address), args[2].(*big.Int)
+		lender, ok := lenders[source]
+		if !ok {
+			return nil, &reverted{reason: "unsupported flash source " + source.Name()}
+		}
+		r, err := route.DecodeRaw(token, args[3].([]byte))
+		if err != nil {
+			return nil, &reverted{reason: "invalid routeData"}
+		}
+		if err := e.transfer(token, lender, self, amount); err != nil {
+			return nil, err
+		}
+		in, tokenIn := amount, token
+		for _, step := range r.Steps {
+			if step.Adapter != route.AdapterUniV2 {
+				return nil, &reverted{reason: "unsupported protocol " + step.Adapter.Name()}
+			}
+			router := step.Target()
+			if _, err := e.invoke(self, tokenIn, tokenABI, "approve", router, in); err != nil {
+				return nil, err
+			}
+			amounts, err := e.invoke(self, router, routerABI, "swapExactTokensForTokens", in, new(big.Int), []common.Address{tokenIn, step.TokenOut}, self, new(big.Int).SetUint64(e.time))
+			if err != nil {
+				return nil, err
+			}
+			out := amounts[0].([]*big.Int)
+			in, tokenIn = out[len(out)-1], step.TokenOut
+		}
+		owed := new(big.Int).Add(amount, new(big.Int).Quo(new(big.Int).Mul(amount, new(big.Int).SetUint64(source.PremiumBps())), big.NewInt(10_000)))
+		if tokenIn != token || in.Cmp(owed) < 0 {
+			return nil, &reverted{reason: "route does not repay the flash loan"}
+		}
+		if err := e.transfer(token, self, lender, owed); err != nil {
+			return nil, err
+		}
+		return []interface{}{new(big.Int).Sub(in, owed)}, nil
+	}}
+}
+
+// minedTx is a transaction the node has mined
+type minedTx struct {
+	tx      *types.Transaction
+	from    common.Address
+	receipt *types.Receipt
+}
+
+// node is a local Ethereum node for one test: an in-memory chain served over
+// JSON-RPC, whose contracts are Go models keeping their state in the storage
+// slots the mainnet contracts use, so eth_call state overrides, storage
+// layout probes and reserve reads behave as they do on a real node. Every
+// transaction is mined into its own block.
+type node struct {
+	t      *testing.T
+	URL    string
+	Client *ethclient.Client
+
+	mu     sync.Mutex
+	state  world
+	models map[common.Address]*model
+	blocks []*types.Header
+	txs    map[common.Hash]*minedTx
+	byNum  map[uint64][]*minedTx
+}
+
+// startNode serves a new chain with chain id 1 until the test ends
+func startNode(t *testing.T) *node {
+	t.Helper()
+	n := &node{
+		t:      t,
+		state:  make(world),
+		models: make(map[common.Address]*model),
+		txs:    make(map[common.Hash]*minedTx),
+		byNum:  make(map[uint64][]*minedTx),
+	}
+	n.blocks = append(n.blocks, n.header(nil))
+	server := gethrpc.NewServer()
+	for namespace, service := range map[string]interface{}{"eth": &ethAPI{n}, "net": &netAPI{}, "web3": &netAPI{}} {
+		if err := server.RegisterName(namespace, service); err != nil {
+			t.Fatal(err)
+		}
+	}
+	http := httptest.NewServer(server)
+	t.Cleanup(func() {
+		http.Close()
+		server.Stop()
+	})
+	client, err := ethclient.Dial(http.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	n.URL, n.Client = http.URL, client
+	return n
+}
+
+// header is the next block's header on top of parent
+func (n *node) header(parent *types.Header) *types.Header {
+	h := &types.Header{
+		UncleHash:  types.EmptyUncleHash,
+		Root:       types.EmptyRootHash,
+		TxHash:     types.EmptyTxsHash,
+		Difficulty: new(big.Int),
+		Number:     new(big.Int),
+		GasLimit:   30_000_000,
+		Time:       uint64(time.Now().Unix()),
+		Extra:      []byte{},
+		BaseFee:    big.NewInt(1_000_000_000),
+	}
+	if parent != nil {
+		h.ParentHash = parent.Hash()
+		h.Number.Add(parent.Number, big.NewInt(1))
+		if h.Time <= parent.Time {
+			h.Time = parent.Time + 1
+		}
+	}
+	return h
+}
+
+func (n *node) head() *types.Header {
+	return n.blocks[len(n.blocks)-1]
+}
+
+// deploy puts m's code at addr
+func (n *node) deploy(addr common.Address, m *model) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.models[addr] = m
+	n.state.get(addr).code = []byte{0xfe}
+}
+
+// deployToken deploys an ERC20
+func (n *node) deployToken(addr common.Address, symbol string, decimals uint8) {
+	n.deploy(addr, tokenModel(symbol, decimals))
+}
+
+// deployPair deploys a UniswapV2 pair of token0 and token1, with the given
+// reserves held as its token balances
+func (n *node) deployPair(pair, token0, token1 common.Address, reserve0, reserve1 *big.Int) {
+	n.deploy(pair, pairModel())
+	n.setStorage(pair, pairToken0Slot, common.BytesToHash(token0.Bytes()))
+	n.setStorage(pair, pairToken1Slot, common.BytesToHash(token1.Bytes()))
+	n.seedV2(pair, token0, token1, reserve0, reserve1)
+}
+
+// deployRouter deploys a UniswapV2 router swapping through pairs
+func (n *node) deployRouter(addr common.Address, pairs ...common.Address) {
+	index := make(map[[2]common.Address]common.Address)
+	for _, pair := range pairs {
+		n.mu.Lock()
+		token0 := common.BigToAddress(n.state.load(pair, pairToken0Slot))
+		token1 := common.BigToAddress(n.state.load(pair, pairToken1Slot))
+		n.mu.Unlock()
+		index[[2]common.Address{token0, token1}] = pair
+	}
+	n.deploy(addr, routerModel(index))
+}
+
+// deployExecutor deploys the executor, owned by owner and borrowing from lenders
+func (n *node) deployExecutor(addr, owner common.Address, lenders map[route.FlashSource]common.Address) {
+	n.deploy(addr, executorModel(lenders))
+	n.setStorage(addr, ownerSlot, common.BytesToHash(owner.Bytes()))
+}
+
+// setStorage writes one storage slot of addr
+func (n *node) setStorage(addr common.Address, slot, value common.Hash) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.state.get(addr).storage[slot] = value
+}
+
+// setBalance sets addr's ether balance
+func (n *node) setBalance(addr common.Address, wei *big.Int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.state.get(addr).balance = new(big.Int).Set(wei)
+}
+
+// fund sets holder's balance of an ERC20 token
+func (n *node) fund(token, holder common.Address, amount *big.Int) {
+	n.setStorage(token, tokenLayout.BalanceKey(holder), common.BigToHash(amount))
+}
+
+// seedV2 sets a pair's reserves and backs them with matching token
+// balances, so swaps against it behave as if it had always held them
+func (n *node) seedV2(pair, token0, token1 common.Address, reserve0, reserve1 *big.Int) {
+	n.mu.Lock()
+	stamp := n.head().Time
+	n.mu.Unlock()
+	n.setStorage(pair, v2ReservesSlot, common.BigToHash(packReserves(reserve0, reserve1, stamp)))
+	n.fund(token0, pair, reserve0)
+	n.fund(token1, pair, reserve1)
+}
+
+// tokenBalance reads holder's ERC20 balance
+func (n *node) tokenBalance(token, holder common.Address) *big.Int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.state.load(token, tokenLayout.BalanceKey(holder))
+}
+
+// reserves reads a pair's reserves
+func (n *node) reserves(pair common.Address) (*big.Int, *big.Int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return unpackReserves(n.state.load(pair, v2ReservesSlot))
+}
+
+// mine seals count empty blocks
+func (n *node) mine(count int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for i := 0; i < count; i++ {
+		n.blocks = append(n.blocks, n.header(n.head()))
+	}
+}
+
+// send mines one unsigned transaction from from, as a dev node does for an
+// impersonated account, reporting a revert as an error
+func (n *node) send(from, to common.Address, data []byte) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	tx := types.NewTx(&types.LegacyTx{Nonce: n.state.get(from).nonce, To: &to, Gas: gasPerTx, GasPrice: new(big.Int), Data: data})
+	if receipt := n.include(tx, from); receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("call from %s to %s reverted", from.Hex(), to.Hex())
+	}
+	return nil
+}
+
+// sendRaw mines a signed transaction
+func (n *node) sendRaw(raw []byte) (*types.Receipt, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, ok := n.txs[tx.Hash()]; ok {
+		return nil, errors.New("already known")
+	}
+	if nonce := n.state.get(from).nonce; tx.Nonce() != nonce {
+		return nil, fmt.Errorf("nonce %d, want %d", tx.Nonce(), nonce)
+	}
+	return n.include(tx, from), nil
+}
+
+// include runs tx and mines it into a new block; a revert keeps only the
+// nonce increment
+func (n *node) include(tx *types.Transaction, from common.Address) *types.Receipt {
+	header := n.header(n.head())
+	n.state.get(from).nonce++
+	e := &evm{w: n.state.copy(), models: n.models, time: header.Time}
+	status := types.ReceiptStatusSuccessful
+	if _, err := e.call(from, *tx.To(), tx.Data()); err != nil {
+		status, e.logs = types.ReceiptStatusFailed, nil
+	} else {
+		n.state = e.w
+	}
+	header.TxHash = types.DeriveSha(types.Transactions{tx}, trie())
+	receipt := &types.Receipt{
+		Type:              tx.Type(),
+		Status:            status,
+		CumulativeGasUsed: gasPerTx,
+		Logs:              e.logs,
+		TxHash:            tx.Hash(),
+		GasUsed:           gasPerTx,
+		EffectiveGasPrice: header.BaseFee,
+		BlockNumber:       header.Number,
+	}
+	header.Bloom = types.CreateBloom(types.Receipts{receipt})
+	receipt.Bloom = header.Bloom
+	receipt.BlockHash = header.Hash()
+	for i, l := range receipt.Logs {
+		l.TxHash, l.BlockHash, l.BlockNumber, l.Index = tx.Hash(), receipt.BlockHash, header.Number.Uint64(), uint(i)
+	}
+	if receipt.Logs == nil {
+		receipt.Logs = []*types.Log{}
+	}
+	n.blocks = append(n.blocks, header)
+	mined := &minedTx{tx: tx, from: from, receipt: receipt}
+	n.txs[tx.Hash()] = mined
+	n.byNum[header.Number.Uint64()] = append(n.byNum[header.Number.Uint64()], mined)
+	return receipt
+}
+
+// call runs a read-only call against the head's state with overrides
+func (n *node) call(args callArgs, over map[common.Address]overrideArgs) ([]byte, error) {
+	n.mu.Lock()
+	w := n.state.copy()
+	e := &evm{w: w, models: n.models, time: n.head().Time}
+	n.mu.Unlock()
+	w.override(over)
+	if args.To == nil {
+		return nil, errors.New("contract creation is not supported")
+	}
+	var from common.Address
+	if args.From != nil {
+		from = *args.From
+	}
+	return e.call(from, *args.To, args.data())
+}
+
+// callArgs is an eth_call transaction object
+type callArgs struct {
+	From  *common.Address `json:"from"`
+	To    *common.Address `json:"to"`
+	Data  *hexutil.Bytes  `json:"data"`
+	Input *hexutil.Bytes  `json:"input"`
+}
+
+func (a callArgs) data() []byte {
+	if a.Input != nil {
+		return *a.Input
+	}
+	if a.Data != nil {
+		return *a.Data
+	}
+	return nil
+}
+
+// overrideArgs is one account's eth_call state override
+type overrideArgs struct {
+	Balance   *hexutil.Big                `json:"balance"`
+	Nonce     *hexutil.Uint64             `json:"nonce"`
+	Code      *hexutil.Bytes              `json:"code"`
+	State     map[common.Hash]common.Hash `json:"state"`
+	StateDiff map[common.Hash]common.Hash `json:"stateDiff"`
+}
+
+// ethAPI is the node's eth namespace
+type ethAPI struct{ n *node }
+
+func (api *ethAPI) ChainId() *hexutil.Big { return (*hexutil.Big)(big.NewInt(1)) }
+
+func (api *ethAPI) BlockNumber() hexutil.Uint64 {
+	api.n.mu.Lock()
+	defer api.n.mu.Unlock()
+	return hexutil.Uint64(api.n.head().Number.Uint64())
+}
+
+func (api *ethAPI) GetBlockByNumber(number gethrpc.BlockNumber, full bool) (map[string]interface{}, error) {
+	api.n.mu.Lock()
+	defer api.n.mu.Unlock()
+	h := api.n.head()
+	if number >= 0 {
+		if int(number) >= len(api.n.blocks) {
+			return nil, nil
+		}
+		h = api.n.blocks[number]
+	}
+	return api.n.block(h, full)
+}
+
+func (api *ethAPI) GetBlockByHash(hash common.Hash, full bool) (map[string]interface{}, error) {
+	api.n.mu.Lock()
+	defer api.n.mu.Unlock()
+	for _, h := range api.n.blocks {
+		if h.Hash() == hash {
+			return api.n.block(h, full)
+		}
+	}
+	return nil, nil
+}
+
+// block renders a header as eth_getBlockByNumber does
+func (n *node) block(h *types.Header, full bool) (map[string]interface{}, error) {
+	raw, err := h.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	txs := []interface{}{}
+	for _, m := range n.byNum[h.Number.Uint64()] {
+		if full {
+			txs = append(txs, n.transaction(m))
+		} else {
+			txs = append(txs, m.tx.Hash())
+		}
+	}
+	out["hash"], out["transactions"], out["uncles"], out["size"] = h.Hash(), txs, []common.Hash{}, hexutil.Uint64(h.Size())
+	return out, nil
+}
+
+// transaction renders a mined transaction as eth_getTransactionByHash does
+func (n *node) transaction(m *minedTx) map[string]interface{} {
+	raw, _ := m.tx.MarshalJSON()
+	var out map[string]interface{}
+	json.Unmarshal(raw, &out)
+	out["from"], out["blockHash"] = m.from, m.receipt.BlockHash
+	out["blockNumber"], out["transactionIndex"] = (*hexutil.Big)(m.receipt.BlockNumber), hexutil.Uint64(0)
+	return out
+}
+
+func (api *ethAPI) GetBalance(addr common.Address, block *gethrpc.BlockNumberOrHash) *hexutil.Big {
+	api.n.mu.Lock()
+	defer api.n.mu.Unlock()
+	return (*hexutil.Big)(new(big.Int).Set(api.n.state.get(addr).balance))
+}
+
+func (api *ethAPI) GetCode(addr common.Address, block *gethrpc.BlockNumberOrHash) hexutil.Bytes {
+	api.n.mu.Lock()
+	defer api.n.mu.Unlock()
+	return api.n.state.get(addr).code
+}
+
+func (api *ethAPI) GetStorageAt(addr common.Address, slot string, block *gethrpc.BlockNumberOrHash) hexutil.Bytes {
+	api.n.mu.Lock()
+	defer api.n.mu.Unlock()
+	value := api.n.state.get(addr).storage[common.HexToHash(slot)]
+	return value.Bytes()
+}
+
+func (api *ethAPI) GetTransactionCount(addr common.Address, block *gethrpc.BlockNumberOrHash) hexutil.Uint64 {
+	api.n.mu.Lock()
+	defer api.n.mu.Unlock()
+	return hexutil.Uint64(api.n.state.get(addr).nonce)
+}
+
+func (api *ethAPI) Call(args callArgs, block *gethrpc.BlockNumberOrHash, over *map[common.Address]overrideArgs) (hexutil.Bytes, error) {
+	var overrides map[common.Address]overrideArgs
+	if over != nil {
+		overrides = *over
+	}
+	return api.n.call(args, overrides)
+}
+
+func (api *ethAPI) EstimateGas(args callArgs, block *gethrpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	if _, err := api.n.call(args, nil); err != nil {
+		return 0, err
+	}
+	return gasPerTx, nil
+}
+
+func (api *ethAPI) GasPrice() *hexutil.Big {
+	api.n.mu.Lock()
+	defer api.n.mu.Unlock()
+	return (*hexutil.Big)(new(big.Int).Add(api.n.head().BaseFee, big.NewInt(1_000_000_000)))
+}
+
+func (api *ethAPI) MaxPriorityFeePerGas() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1_000_000_000))
+}
+
+func (api *ethAPI) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	receipt, err := api.n.sendRaw(raw)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return receipt.TxHash, nil
+}
+
+func (api *ethAPI) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	api.n.mu.Lock()
+	defer api.n.mu.Unlock()
+	if m, ok := api.n.txs[hash]; ok {
+		return m.receipt
+	}
+	return nil
+}
+
+func (api *ethAPI) GetTransactionByHash(hash common.Hash) map[string]interface{} {
+	api.n.mu.Lock()
+	defer api.n.mu.Unlock()
+	if m, ok := api.n.txs[hash]; ok {
+		return api.n.transaction(m)
+	}
+	return nil
+}
+
+// netAPI answers the net and web3 namespaces
+type netAPI struct{}
+
+func (netAPI) Version() string       { return "1" }
+func (netAPI) ClientVersion() string { return "titan-e2e" }
+
+// trie hashes transaction lists for headers
+func trie() types.TrieHasher { return &listHasher{} }
+
+// listHasher is a TrieHasher good enough for a local chain: it hashes the
+// encoded items in order rather than building a real trie
+type listHasher struct{ items [][]byte }
+
+func (h *listHasher) Reset() { h.items = nil }
+func (h *listHasher) Update(k, v []byte) error {
+	h.items = append(h.items, append(append([]byte{}, k...), v...))
+	return nil
+}
+func (h *listHasher) Hash() common.Hash { return crypto.Keccak256Hash(h.items...) }
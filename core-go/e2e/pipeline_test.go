@@ -0,0 +1,303 @@
+package e2e
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/dex"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/mevshare"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/pipeline"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/route"
+)
+
+// Mainnet fixtures, deployed on the local node at their mainnet addresses:
+// two UniswapV2-style USDC/WETH pairs (token0 USDC, token1 WETH) behind the
+// routers configured as UNIV2 and SUSHI, the Balancer vault lending WETH,
+// and the executor owned by the searcher
+var (
+	usdc        = common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	weth        = common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+	uniPair     = common.HexToAddress("0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc")
+	sushiPair   = common.HexToAddress("0x397FF1542f962076d0BFE58eA045FfA2d347ACa0")
+	uniRouter   = common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+	sushiRouter = common.HexToAddress("0xd9e1cE17f2641f24aE83637ab66a2cca9C378B9F")
+	vault       = common.HexToAddress(config.BalancerV3Vault)
+	executor    = common.HexToAddress("0x00000000000000000000000000000000000e2e02")
+	whale       = common.HexToAddress("0x00000000000000000000000000000000000e2e03")
+	searcherKey = mustKey("00000000000000000000000000000000000000000000000000000000000e2e01")
+	searcher    = crypto.PubkeyToAddress(searcherKey.PublicKey)
+)
+
+// The planted market: both pairs at 2,000 USDC per WETH, deep enough that a
+// backrun clears the commander's 500-token loan floor, and a whale swap
+// pushing WETH on sushiswap about 10% above uniswap
+var (
+	poolUSDC  = dollars(200_000_000)
+	poolWETH  = ether(100_000)
+	swapUSDC  = dollars(10_000_000)
+	vaultWETH = ether(10_000)
+)
+
+func mustKey(hex string) *ecdsa.PrivateKey {
+	key, err := crypto.HexToECDSA(hex)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func ether(n int64) *big.Int   { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+func dollars(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e6)) }
+
+// plantMarket starts a local node with both pairs level, and snapshots them from the node into dir's reserve cache, where the
+// daemon loads them
+func plantMarket(t *testing.T, dir string) *node {
+	t.Helper()
+	n := startNode(t)
+	n.deployToken(usdc, "USDC", 6)
+	n.deployToken(weth, "WETH", 18)
+	n.deployPair(uniPair, usdc, weth, poolUSDC, poolWETH)
+	n.deployPair(sushiPair, usdc, weth, poolUSDC, poolWETH)
+	n.deployRouter(uniRouter, uniPair)
+	n.deployRouter(sushiRouter, sushiPair)
+	n.fund(weth, vault, vaultWETH)
+	n.deployExecutor(executor, searcher, map[route.FlashSource]common.Address{route.FlashBalancer: vault})
+	n.setBalance(searcher, ether(1))
+
+	cache := reserves.NewCache()
+	discovery := dex.NewDiscovery(1, n.Client)
+	// Snapshots carry the configured router names, as discovery through those routers would
+	for name, pair := range map[string]common.Address{"UNIV2": uniPair, "SUSHI": sushiPair} {
+		s, err := discovery.FetchV2(context.Background(), name, pair, dex.V2FeeBps)
+		if err != nil {
+			t.Fatalf("snapshot %s: %v", name, err)
+		}
+		cache.Put(s)
+	}
+	if err := cache.Save(filepath.Join(dir, "data", "reserves_cache.json")); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// victim is the hinted transaction: the whale buys WETH with USDC on
+// sushiswap, leaving WETH there dearer than on uniswap
+type victim struct {
+	usdcIn  *big.Int
+	wethOut *big.Int
+	Hint    *mevshare.Hint
+}
+
+// newVictim quotes the whale's swap on sushiswap's reserves on the node
+func newVictim(n *node, usdcIn *big.Int) *victim {
+	reserveUSDC, reserveWETH := n.reserves(sushiPair)
+	out := amountOut(usdcIn, reserveUSDC, reserveWETH)
+	return &victim{
+		usdcIn:  usdcIn,
+		wethOut: out,
+		Hint:    syncHint(sushiPair, new(big.Int).Add(reserveUSDC, usdcIn), new(big.Int).Sub(reserveWETH, out)),
+	}
+}
+
+// land mines the whale's swap, leaving sushiswap as its hint said
+func (v *victim) land(n *node) error {
+	n.fund(usdc, whale, v.usdcIn)
+	transfer, err := tokenABI.Pack("transfer", sushiPair, v.usdcIn)
+	if err != nil {
+		return err
+	}
+	swap, err := pairABI.Pack("swap", new(big.Int), v.wethOut, whale, []byte{})
+	if err != nil {
+		return err
+	}
+	if err := n.send(whale, usdc, transfer); err != nil {
+		return err
+	}
+	return n.send(whale, sushiPair, swap)
+}
+
+// syncHint is a MEV-Share hint revealing a pending swap that leaves pair with
+// the given reserves
+func syncHint(pair common.Address, reserve0, reserve1 *big.Int) *mevshare.Hint {
+	data := append(common.BigToHash(reserve0).Bytes(), common.BigToHash(reserve1).Bytes()...)
+	return &mevshare.Hint{
+		Hash: common.BigToHash(new(big.Int).Add(reserve0, reserve1)),
+		Logs: []mevshare.HintLog{{Address: pair, Topics: []common.Hash{syncTopic}, Data: data}},
+	}
+}
+
+// routeProfit replays execute calldata's route on the node's reserves,
+// returning what the executor keeps after repaying the loan
+func routeProfit(n *node, data []byte) (*big.Int, error) {
+	call, err := route.UnpackExecute(data)
+	if err != nil {
+		return nil, err
+	}
+	r, err := route.DecodeRaw(call.Token, call.RouteData)
+	if err != nil {
+		return nil, err
+	}
+	pairs := map[common.Address]common.Address{uniRouter: uniPair, sushiRouter: sushiPair}
+	in, tokenIn := call.Amount, call.Token
+	for _, step := range r.Steps {
+		pair, ok := pairs[step.Target()]
+		if !ok {
+			return nil, fmt.Errorf("hop through %s, not a planted router", step.Target().Hex())
+		}
+		reserveUSDC, reserveWETH := n.reserves(pair)
+		if tokenIn == usdc {
+			in = amountOut(in, reserveUSDC, reserveWETH)
+		} else {
+			in = amountOut(in, reserveWETH, reserveUSDC)
+		}
+		tokenIn = step.TokenOut
+	}
+	return in.Sub(in, call.Amount), nil
+}
+
+// TestPipelineLandsPlantedBackrun runs `titan serve` with a signing key
+// against the local node and a local relay. The daemon must detect the
+// hinted backrun, size it, simulate it through the executor and submit it
+// as a bundle behind the hint; the relay lands the bundle as a builder
+// would, and the daemon must confirm the backrun from its receipt. The
+// executor keeps exactly what the route pays over the loan.
+func TestPipelineLandsPlantedBackrun(t *testing.T) {
+	dir := t.TempDir()
+	n := plantMarket(t, dir)
+	v := newVictim(n, swapUSDC)
+
+	type landing struct {
+		receipt *types.Receipt
+		profit  *big.Int
+	}
+	landed := make(chan landing, 1)
+	relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []mevshare.Bundle `json:"params"`
+		}
+		reply := func(result interface{}, err error) {
+			out := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result}
+			if err != nil {
+				out = map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32000, "message": err.Error()}}
+			}
+			json.NewEncoder(w).Encode(out)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "mev_sendBundle" || len(req.Params) != 1 {
+			reply(nil, fmt.Errorf("want one mev_sendBundle bundle, got %s (%v)", req.Method, err))
+			return
+		}
+		body := req.Params[0].Body
+		if len(body) != 2 || body[0].Hash == nil || *body[0].Hash != v.Hint.Hash || len(body[1].Tx) == 0 {
+			reply(nil, fmt.Errorf("want the backrun behind hint %s, got %+v", v.Hint.Hash.Hex(), body))
+			return
+		}
+		// Land the bundle as a builder would: the hinted swap, then the backrun
+		if err := v.land(n); err != nil {
+			reply(nil, err)
+			return
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(body[1].Tx); err != nil {
+			reply(nil, err)
+			return
+		}
+		profit, err := routeProfit(n, tx.Data())
+		if err != nil {
+			reply(nil, err)
+			return
+		}
+		receipt, err := n.sendRaw(body[1].Tx)
+		if err != nil {
+			reply(nil, err)
+			return
+		}
+		landed <- landing{receipt: receipt, profit: profit}
+		reply(map[string]interface{}{"bundleHash": crypto.Keccak256Hash(body[1].Tx)}, nil)
+	}))
+	defer relay.Close()
+
+	stream, hints := hintStream(t)
+	env := serveEnv(n, dir, stream)
+	env["PRIVATE_KEY"] = fmt.Sprintf("%x", crypto.FromECDSA(searcherKey))
+	env["MEV_SHARE_RELAY_URL"] = relay.URL
+	env["CONFIRMATIONS_ETHEREUM"] = "1"
+	daemon := startServe(t, dir, env)
+	daemon.waitForPrice()
+	hints <- v.Hint
+
+	o := daemon.waitForOpportunity()
+	var got landing
+	daemon.waitFor("the bundle to land", func() bool {
+		select {
+		case got = <-landed:
+			return true
+		default:
+			return false
+		}
+	})
+	if got.receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatal("Expected the landed backrun to succeed")
+	}
+	daemon.waitForEnd(o.ID)
+	daemon.stop()
+
+	r := readRecord(t, dir, o.ID)
+	want := []pipeline.Stage{pipeline.StageDetected, pipeline.StageScored, pipeline.StageSized, pipeline.StageSimulated, pipeline.StageSubmitted, pipeline.StageConfirmed}
+	if stages := stagesOf(r); fmt.Sprint(stages) != fmt.Sprint(want) {
+		t.Errorf("Expected stages %v, got %v (%+v)", want, stages, r.History)
+	}
+	if r.Tx == nil || r.Tx.Hash != got.receipt.TxHash || r.Tx.From != searcher {
+		t.Errorf("Expected %s from %s tracked, got %+v", got.receipt.TxHash.Hex(), searcher.Hex(), r.Tx)
+	}
+	if got.profit.Sign() <= 0 {
+		t.Fatalf("Expected the landed route profitable, got %s", got.profit)
+	}
+	if balance := n.tokenBalance(weth, executor); balance.Cmp(got.profit) != 0 {
+		t.Errorf("Expected the executor to keep %s WETH wei, got %s", got.profit, balance)
+	}
+	if balance := n.tokenBalance(weth, vault); balance.Cmp(vaultWETH) != 0 {
+		t.Errorf("Expected the vault repaid to %s, got %s", vaultWETH, balance)
+	}
+	if len(o.Explanation.Legs) != 2 || o.Explanation.Legs[0].Pool != sushiPair || o.Explanation.Legs[1].Pool != uniPair {
+		t.Errorf("Expected WETH→USDC on sushiswap then back on uniswap, got %s", o.Explanation.Route())
+	}
+}
+
+// stagesOf lists the stages a record went through
+func stagesOf(r pipeline.Record) []pipeline.Stage {
+	var stages []pipeline.Stage
+	for _, tr := range r.History {
+		stages = append(stages, tr.Stage)
+	}
+	return stages
+}
+
+// readRecord reads id's record from the pipeline log of a stopped daemon
+func readRecord(t *testing.T, dir, id string) pipeline.Record {
+	t.Helper()
+	lifecycle, err := pipeline.Open(filepath.Join(dir, "data", "pipeline.jsonl"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lifecycle.Close()
+	r, ok := lifecycle.Get(id)
+	if !ok {
+		t.Fatalf("Expected %s tracked in the pipeline log", id)
+	}
+	return r
+}
@@ -0,0 +1,362 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/mevshare"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/pipeline"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/prices"
+)
+
+// TestServeBackrunsPlantedArbitrage boots `titan serve` in watch-only mode
+// against the local node, with a local MEV-Share stream. A hint reveals the
+// whale's swap pushing WETH on sushiswap about 10% above uniswap; the daemon
+// must detect the backrun, score, size and simulate it through the executor,
+// which only succeeds if the route repays its loan, and end its lifecycle as
+// observed, showing it on the API and in the journal. Nothing reaches the chain.
+func TestServeBackrunsPlantedArbitrage(t *testing.T) {
+	dir := t.TempDir()
+	n := plantMarket(t, dir)
+	v := newVictim(n, swapUSDC)
+	stream, hints := hintStream(t)
+	daemon := startServe(t, dir, serveEnv(n, dir, stream), "--watch-only")
+	daemon.waitForPrice()
+	hints <- v.Hint
+
+	o := daemon.waitForOpportunity()
+	if len(o.Explanation.Legs) != 2 || o.Explanation.Legs[0].Pool != sushiPair || o.Explanation.Legs[1].Pool != uniPair {
+		t.Fatalf("Expected WETH→USDC on sushiswap then back on uniswap, got %s", o.Explanation.Route())
+	}
+	if o.Explanation.Decision == opportunity.DecisionReject {
+		t.Fatalf("Expected the backrun executable, got:\n%s", o.Explanation.Text())
+	}
+	daemon.waitForEnd(o.ID)
+	var status struct {
+		InFlight []pipeline.Record `json:"inFlight"`
+	}
+	if !daemon.get("/pipeline", &status) || len(status.InFlight) != 0 {
+		t.Errorf("Expected nothing in flight on the API, got %+v", status.InFlight)
+	}
+	daemon.stop()
+
+	r := readRecord(t, dir, o.ID)
+	want := []pipeline.Stage{pipeline.StageDetected, pipeline.StageScored, pipeline.StageSized, pipeline.StageSimulated, pipeline.StageObserved}
+	if stages := stagesOf(r); fmt.Sprint(stages) != fmt.Sprint(want) {
+		t.Errorf("Expected stages %v, got %v (%+v)", want, stages, r.History)
+	}
+	if r.Tx != nil {
+		t.Errorf("Expected nothing submitted in watch-only mode, got %s", r.Tx.Hash.Hex())
+	}
+	if balance := n.tokenBalance(weth, executor); balance.Sign() != 0 {
+		t.Errorf("Expected the simulation to leave the executor empty, got %s WETH wei", balance)
+	}
+	if reserveUSDC, _ := n.reserves(sushiPair); reserveUSDC.Cmp(poolUSDC) != 0 {
+		t.Errorf("Expected the hinted swap simulated, not mined, got sushiswap at %s USDC", reserveUSDC)
+	}
+
+	entries, err := journal.ReadAll(filepath.Join(dir, "data", "journal.jsonl"), journal.KindOpportunity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var journaled []string
+	for _, e := range entries {
+		var j opportunity.Opportunity
+		if err := json.Unmarshal(e.Data, &j); err == nil {
+			journaled = append(journaled, j.ID)
+		}
+	}
+	if len(journaled) != 1 || journaled[0] != o.ID {
+		t.Errorf("Expected only %s journaled, got %v", o.ID, journaled)
+	}
+}
+
+// TestServeFailsBackrunThatReverts plants the same hint, but uniswap has
+// already moved on chain since the daemon cached it, so the quote still
+// shows a profit the route can't pay. The executor must revert in
+// simulation and the daemon fail the backrun as a simulation revert.
+func TestServeFailsBackrunThatReverts(t *testing.T) {
+	dir := t.TempDir()
+	n := plantMarket(t, dir)
+	v := newVictim(n, swapUSDC)
+	// The same flow already went through uniswap, after the cache was saved
+	postUSDC, postWETH := new(big.Int).Add(poolUSDC, v.usdcIn), new(big.Int).Sub(poolWETH, v.wethOut)
+	n.seedV2(uniPair, usdc, weth, postUSDC, postWETH)
+	stream, hints := hintStream(t)
+	daemon := startServe(t, dir, serveEnv(n, dir, stream), "--watch-only")
+	daemon.waitForPrice()
+	hints <- v.Hint
+
+	o := daemon.waitForOpportunity()
+	daemon.waitForEnd(o.ID)
+	daemon.stop()
+
+	r := readRecord(t, dir, o.ID)
+	want := []pipeline.Stage{pipeline.StageDetected, pipeline.StageScored, pipeline.StageSized, pipeline.StageFailed}
+	if stages := stagesOf(r); fmt.Sprint(stages) != fmt.Sprint(want) {
+		t.Fatalf("Expected stages %v, got %v (%+v)", want, stages, r.History)
+	}
+	last := r.History[len(r.History)-1]
+	if last.Reason != failure.SimulationRevert || !strings.Contains(last.Note, "route does not repay the flash loan") {
+		t.Errorf("Expected a simulation revert for the unpaid loan, got [%s] %s", last.Reason, last.Note)
+	}
+}
+
+// hintStream serves MEV-Share hints sent on the returned channel as an SSE
+// stream until the test ends
+func hintStream(t *testing.T) (string, chan<- *mevshare.Hint) {
+	t.Helper()
+	hints := make(chan *mevshare.Hint, 1)
+	stream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case h := <-hints:
+				data, _ := json.Marshal(h)
+				fmt.Fprintf(w, "data: %s\n\n", data)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}))
+	t.Cleanup(stream.Close)
+	return stream.URL, hints
+}
+
+// serveEnv configures the daemon for the planted market on n, backrunning
+// 600 WETH through the executor with hints from stream
+func serveEnv(n *node, dir, stream string) map[string]string {
+	return map[string]string{
+		"RPC_ETHEREUM":              n.URL,
+		"EXECUTOR_ADDRESS_ETHEREUM": executor.Hex(),
+		"MEV_SHARE_ENABLED":         "true",
+		"MEV_SHARE_STREAM_URL":      stream,
+		"MEV_SHARE_BACKRUN_ETH":     "600",
+		"TITAN_DATA_DIR":            filepath.Join(dir, "data"),
+		"TITAN_API_ADDR":            fmt.Sprintf("127.0.0.1:%d", freePort(n.t)),
+		// The cached pairs must outlive the daemon's startup
+		"QUOTE_MAX_AGE_MS_ETHEREUM": "600000",
+		// The planted route is new; trade it at full size, not as a canary
+		"CANARY_FILLS": "0",
+	}
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// daemon is a `titan serve` process run for one test
+type daemon struct {
+	t      *testing.T
+	cmd    *exec.Cmd
+	api    string
+	out    *syncBuffer
+	exited chan struct{}
+	err    error // the process's exit, once exited is closed
+}
+
+// titan is built once for every test in the package
+var titan struct {
+	once sync.Once
+	dir  string
+	bin  string
+	err  error
+}
+
+// titanBinary builds cmd/titan, or returns the build from an earlier test
+func titanBinary(t *testing.T) string {
+	t.Helper()
+	titan.once.Do(func() {
+		if titan.dir, titan.err = os.MkdirTemp("", "titan-e2e"); titan.err != nil {
+			return
+		}
+		titan.bin = filepath.Join(titan.dir, "titan")
+		if out, err := exec.Command("go", "build", "-o", titan.bin, "../cmd/titan").CombinedOutput(); err != nil {
+			titan.err = fmt.Errorf("%v\n%s", err, out)
+		}
+	})
+	if titan.err != nil {
+		t.Fatalf("build titan: %v", titan.err)
+	}
+	return titan.bin
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if titan.dir != "" {
+		os.RemoveAll(titan.dir)
+	}
+	os.Exit(code)
+}
+
+// startServe runs `titan serve` in dir with only env set, so nothing from
+// the caller's environment, or a .env file, leaks in. The daemon is killed
+// when the test ends, and its output logged if it failed.
+func startServe(t *testing.T, dir string, env map[string]string, args ...string) *daemon {
+	t.Helper()
+	bin := titanBinary(t)
+	d := &daemon{t: t, api: "http://" + env["TITAN_API_ADDR"], out: &syncBuffer{}, exited: make(chan struct{})}
+	d.cmd = exec.Command(bin, append([]string{"serve"}, args...)...)
+	d.cmd.Dir = dir
+	d.cmd.Env = []string{"PATH=" + os.Getenv("PATH"), "HOME=" + dir}
+	for k, v := range env {
+		d.cmd.Env = append(d.cmd.Env, k+"="+v)
+	}
+	d.cmd.Stdout, d.cmd.Stderr = d.out, d.out
+	if err := d.cmd.Start(); err != nil {
+		t.Fatalf("start titan serve: %v", err)
+	}
+	go func() {
+		d.err = d.cmd.Wait()
+		close(d.exited)
+	}()
+	t.Cleanup(func() {
+		d.cmd.Process.Kill()
+		<-d.exited
+		if t.Failed() {
+			t.Logf("titan serve output:\n%s", d.out)
+		}
+	})
+	d.waitFor("the API", func() bool {
+		var health map[string]interface{}
+		return d.get("/health", &health)
+	})
+	return d
+}
+
+// get decodes the JSON at path on the daemon's API into v, reporting success
+func (d *daemon) get(path string, v interface{}) bool {
+	resp, err := http.Get(d.api + path)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(v) == nil
+}
+
+// waitFor polls cond for up to a minute, failing the test if the daemon
+// exits or cond never holds
+func (d *daemon) waitFor(what string, cond func() bool) {
+	d.t.Helper()
+	deadline := time.Now().Add(time.Minute)
+	for !cond() {
+		select {
+		case <-d.exited:
+			d.t.Fatalf("titan serve exited waiting for %s: %v", what, d.err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			d.t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// waitForPrice waits for the daemon to price ETH. With no Chainlink feed
+// on the node, ETH is priced off the cached pairs; backruns can't be valued
+// until it is.
+func (d *daemon) waitForPrice() {
+	d.t.Helper()
+	d.waitFor("an ETH price", func() bool {
+		var quotes []prices.Quote
+		if !d.get("/prices", &quotes) {
+			return false
+		}
+		for _, q := range quotes {
+			if q.ChainID == 1 && q.PriceE8 > 0 {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// waitForOpportunity waits for the first opportunity on the API
+func (d *daemon) waitForOpportunity() opportunity.Opportunity {
+	d.t.Helper()
+	var o opportunity.Opportunity
+	d.waitFor("the planted backrun on the API", func() bool {
+		var recent []opportunity.Opportunity
+		if !d.get("/opportunities", &recent) || len(recent) == 0 {
+			return false
+		}
+		o = recent[0]
+		return true
+	})
+	return o
+}
+
+// waitForEnd waits for id's lifecycle to end in the pipeline log, where
+// every transition is synced as it happens
+func (d *daemon) waitForEnd(id string) {
+	d.t.Helper()
+	logPath := filepath.Join(d.cmd.Dir, "data", "pipeline.jsonl")
+	d.waitFor("the backrun's lifecycle to end", func() bool {
+		raw, _ := os.ReadFile(logPath)
+		for _, line := range bytes.Split(raw, []byte("\n")) {
+			var tr pipeline.Transition
+			if json.Unmarshal(line, &tr) == nil && tr.ID == id && tr.Stage.Terminal() {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// stop interrupts the daemon and waits for it to shut down cleanly
+func (d *daemon) stop() {
+	d.t.Helper()
+	if err := d.cmd.Process.Signal(os.Interrupt); err != nil {
+		d.t.Fatal(err)
+	}
+	select {
+	case <-d.exited:
+		if d.err != nil {
+			d.t.Fatalf("titan serve: %v", d.err)
+		}
+	case <-time.After(30 * time.Second):
+		d.t.Fatal("titan serve did not shut down")
+	}
+}
+
+// syncBuffer collects a process's output while tests read it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,168 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+)
+
+// Severity levels
+const (
+	LevelInfo     = "info"
+	LevelWarning  = "warning"
+	LevelCritical = "critical"
+)
+
+// Message is a single alert or report delivered to operators
+type Message struct {
+	Level string
+	Title string
+	Body  string // Markdown
+}
+
+// Notifier delivers messages to one channel
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Dispatcher fans a message out to every configured channel
+type Dispatcher struct {
+	notifiers []Notifier
+}
+
+// NewDispatcher creates a dispatcher over the given channels
+func NewDispatcher(notifiers ...Notifier) *Dispatcher {
+	return &Dispatcher{notifiers: notifiers}
+}
+
+// FromConfig builds a dispatcher from alerting configuration; it always includes the log channel
+func FromConfig(cfg *config.AlertConfig) *Dispatcher {
+	d := NewDispatcher(LogNotifier{})
+	if cfg == nil {
+		return d
+	}
+	if cfg.SlackWebhook != "" {
+		d.Add(NewSlackNotifier(cfg.SlackWebhook))
+	}
+	if cfg.TelegramBotToken != "" && cfg.TelegramChatID != "" {
+		d.Add(NewTelegramNotifier(cfg.TelegramBotToken, cfg.TelegramChatID))
+	}
+	return d
+}
+
+// Add registers another channel
+func (d *Dispatcher) Add(n Notifier) {
+	d.notifiers = append(d.notifiers, n)
+}
+
+// Notify delivers to every channel, returning the joined delivery errors
+func (d *Dispatcher) Notify(ctx context.Context, msg Message) error {
+	var errs []error
+	for _, n := range d.notifiers {
+		if err := n.Notify(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LogNotifier writes messages to the process log
+type LogNotifier struct{}
+
+// Name returns the channel name
+func (LogNotifier) Name() string { return "log" }
+
+// Notify logs the message title
+func (LogNotifier) Notify(ctx context.Context, msg Message) error {
+	log.Printf("%s %s", levelEmoji(msg.Level), msg.Title)
+	return nil
+}
+
+// SlackNotifier posts to a Slack incoming webhook
+type SlackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewSlackNotifier creates a Slack webhook channel
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{url: webhookURL, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Name returns the channel name
+func (s *SlackNotifier) Name() string { return "slack" }
+
+// Notify posts the message
+func (s *SlackNotifier) Notify(ctx context.Context, msg Message) error {
+	text := fmt.Sprintf("%s *%s*\n%s", levelEmoji(msg.Level), msg.Title, msg.Body)
+	return postJSON(ctx, s.client, s.url, map[string]string{"text": text})
+}
+
+// TelegramNotifier sends messages through the Telegram Bot API
+type TelegramNotifier struct {
+	baseURL string
+	chatID  string
+	client  *http.Client
+}
+
+// NewTelegramNotifier creates a Telegram channel
+func NewTelegramNotifier(botToken, chatID string) *TelegramNotifier {
+	return &TelegramNotifier{
+		baseURL: "https://api.telegram.org/bot" + botToken,
+		chatID:  chatID,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the channel name
+func (t *TelegramNotifier) Name() string { return "telegram" }
+
+// Notify sends the message
+func (t *TelegramNotifier) Notify(ctx context.Context, msg Message) error {
+	text := fmt.Sprintf("%s %s\n\n%s", levelEmoji(msg.Level), msg.Title, msg.Body)
+	return postJSON(ctx, t.client, t.baseURL+"/sendMessage", map[string]string{
+		"chat_id": t.chatID,
+		"text":    text,
+	})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func levelEmoji(level string) string {
+	switch strings.ToLower(level) {
+	case LevelCritical:
+		return "🚨"
+	case LevelWarning:
+		return "⚠️"
+	default:
+		return "ℹ️"
+	}
+}
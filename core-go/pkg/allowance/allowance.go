@@ -0,0 +1,429 @@
+package allowance
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/execution"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+)
+
+// erc20ABI is the allowance read and the approve that revokes one
+const erc20ABI = `[
+{"inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"name":"allowance","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"name":"approve","outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"}
+]`
+
+var parsedERC20 = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// ERC20 event signatures: Approval finds spenders, Transfer finds spends
+var (
+	approvalTopic = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+	transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+)
+
+// maxBlocks bounds the range of one log query
+const maxBlocks = 2000
+
+// blockTimeSample is how many blocks back the average block time is measured
+const blockTimeSample = 10_000
+
+// Client reads a chain's heads, logs, transactions and contract state
+type Client interface {
+	chain.ChainReader
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+}
+
+// Dialer returns a client for a chain
+type Dialer func(chainID uint64) (Client, error)
+
+// Owner is an account whose approvals are kept clean
+type Owner struct {
+	Address common.Address
+	// Executor marks a contract; only its own code changes its approvals,
+	// so they are reported but never revoked from here
+	Executor bool
+}
+
+// Approval is one outstanding ERC20 allowance
+type Approval struct {
+	ChainID   uint64         `json:"chainId"`
+	Owner     common.Address `json:"owner"`
+	Executor  bool           `json:"executor,omitempty"`
+	Token     common.Address `json:"token"`
+	Symbol    string         `json:"symbol,omitempty"`
+	Spender   common.Address `json:"spender"`
+	Router    string         `json:"router,omitempty"` // configured router name, when listed
+	Allowance *big.Int       `json:"allowance"`
+	LastUsed  uint64         `json:"lastUsed,omitempty"` // block of the spender's last spend, 0 when none in the lookback
+	Unused    bool           `json:"unused"`             // no use within the unused window
+	Delisted  bool           `json:"delisted"`           // spender neither a configured router nor kept
+}
+
+// Due reports whether the approval should be revoked
+func (a Approval) Due() bool {
+	return a.Unused || a.Delisted
+}
+
+// Revocable reports whether the approval is due and its owner can revoke it
+func (a Approval) Revocable() bool {
+	return a.Due() && !a.Executor
+}
+
+// Reason describes why the approval is due
+func (a Approval) Reason() string {
+	var reasons []string
+	if a.Delisted {
+		reasons = append(reasons, "spender not a listed router")
+	}
+	if a.Unused {
+		reasons = append(reasons, "unused")
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// Revocation returns the owner's approve(spender, 0) on the token
+func Revocation(a Approval) (execution.Call, error) {
+	data, err := parsedERC20.Pack("approve", a.Spender, new(big.Int))
+	if err != nil {
+		return execution.Call{}, err
+	}
+	return execution.Call{ChainID: a.ChainID, From: a.Owner, To: a.Token, Data: data}, nil
+}
+
+type pair struct {
+	token, spender common.Address
+}
+
+// Hygiene finds standing approvals that widen the attack surface for no
+// benefit: those unused for the configured window and those to spenders
+// that are no longer listed. Approvals are discovered from the owner's
+// Approval events over the lookback and from every configured router on
+// every registered token. A spender last used an approval in the last
+// transaction sent to it that moved the owner's tokens, a Transfer from the
+// owner; approving again is not a use, and tokens need not emit Approval on
+// transferFrom. A nil Hygiene finds nothing. It is safe for concurrent use.
+type Hygiene struct {
+	cfg     config.AllowanceConfig
+	routers map[uint64]config.DexRouters
+	keep    map[common.Address]bool
+	dial    Dialer
+	tokens  func(chainID uint64) []tokens.Token
+	owners  func(chainID uint64) []Owner
+
+	// Revoke, when set, sends a due approval's revocation from its owner;
+	// nil only reports
+	Revoke func(ctx context.Context, a Approval) (common.Hash, error)
+	// OnDue, when set, is called for each due approval a sweep finds
+	OnDue func(a Approval)
+
+	mu  sync.Mutex
+	due map[uint64][]Approval // last sweep's findings, by chain
+
+	outstanding *metrics.GaugeVec
+	revoked     *metrics.CounterVec
+}
+
+// New creates the scan over owners' approvals, nil when cfg is nil; reg
+// may be nil
+func New(cfg *config.AllowanceConfig, routers map[uint64]config.DexRouters, dial Dialer, tokens func(chainID uint64) []tokens.Token, owners func(chainID uint64) []Owner, reg *metrics.Registry) *Hygiene {
+	if cfg == nil {
+		return nil
+	}
+	h := &Hygiene{
+		cfg:     *cfg,
+		routers: routers,
+		keep:    make(map[common.Address]bool, len(cfg.Keep)),
+		dial:    dial,
+		tokens:  tokens,
+		owners:  owners,
+		due:     make(map[uint64][]Approval),
+	}
+	for _, spender := range cfg.Keep {
+		h.keep[common.HexToAddress(spender)] = true
+	}
+	if reg != nil {
+		h.outstanding = reg.Gauge("titan_approvals_due", "Standing approvals unused or to delisted spenders", "chain")
+		h.revoked = reg.Counter("titan_approvals_revoked_total", "Standing approvals revoked", "chain")
+	}
+	return h
+}
+
+// Run sweeps chains every interval until ctx ends
+func (h *Hygiene) Run(ctx context.Context, interval time.Duration, chains ...uint64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, chainID := range chains {
+			if _, err := h.Sweep(ctx, chainID); err != nil && ctx.Err() == nil {
+				log.Printf("⚠️ Approvals on chain %d: %v", chainID, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep scans every owner on chainID, reports the due approvals and, when
+// Revoke is set, revokes those their owner can. It returns the approvals
+// still due.
+func (h *Hygiene) Sweep(ctx context.Context, chainID uint64) ([]Approval, error) {
+	if h == nil {
+		return nil, nil
+	}
+	var due []Approval
+	for _, owner := range h.owners(chainID) {
+		found, err := h.Scan(ctx, chainID, owner)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", owner.Address.Hex(), err)
+		}
+		for _, a := range found {
+			if !a.Due() {
+				continue
+			}
+			if h.OnDue != nil {
+				h.OnDue(a)
+			}
+			if h.Revoke == nil || !a.Revocable() {
+				due = append(due, a)
+				continue
+			}
+			hash, err := h.Revoke(ctx, a)
+			if err != nil {
+				log.Printf("⚠️ Revoking %s's %s approval for %s: %v", a.Owner.Hex(), a.Symbol, a.Spender.Hex(), err)
+				due = append(due, a)
+				continue
+			}
+			log.Printf("🔒 Revoked %s's %s approval for %s (%s) in %s", a.Owner.Hex(), a.Symbol, a.Spender.Hex(), a.Reason(), hash.Hex())
+			if h.revoked != nil {
+				h.revoked.Inc(strconv.FormatUint(chainID, 10))
+			}
+		}
+	}
+	h.mu.Lock()
+	h.due[chainID] = due
+	h.mu.Unlock()
+	if h.outstanding != nil {
+		h.outstanding.Set(float64(len(due)), strconv.FormatUint(chainID, 10))
+	}
+	return due, nil
+}
+
+// Due returns the approvals the last sweep of each chain left due
+func (h *Hygiene) Due() []Approval {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var out []Approval
+	for _, due := range h.due {
+		out = append(out, due...)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ChainID != out[j].ChainID {
+			return out[i].ChainID < out[j].ChainID
+		}
+		return less(out[i], out[j])
+	})
+	return out
+}
+
+// Scan lists owner's nonzero approvals on chainID, by token then spender
+func (h *Hygiene) Scan(ctx context.Context, chainID uint64, owner Owner) ([]Approval, error) {
+	client, err := h.dial(chainID)
+	if err != nil {
+		return nil, err
+	}
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lookback, err := blockBefore(ctx, client, head, days(h.cfg.LookbackDays))
+	if err != nil {
+		return nil, err
+	}
+	unused, err := blockBefore(ctx, client, head, days(h.cfg.UnusedDays))
+	if err != nil {
+		return nil, err
+	}
+	approved, last, err := history(ctx, client, owner.Address, lookback, head)
+	if err != nil {
+		return nil, err
+	}
+
+	routers := make(map[common.Address]string)
+	for name, rc := range h.routers[chainID] {
+		routers[common.HexToAddress(rc.Address)] = name
+	}
+	symbols := make(map[common.Address]string)
+	candidates := make(map[pair]bool, len(approved))
+	for p := range approved {
+		candidates[p] = true
+	}
+	for _, t := range h.tokens(chainID) {
+		if t.Address == (common.Address{}) {
+			continue
+		}
+		symbols[t.Address] = t.Symbol
+		for router := range routers {
+			candidates[pair{t.Address, router}] = true
+		}
+	}
+
+	var out []Approval
+	for p := range candidates {
+		amount, err := allowance(ctx, client, p.token, owner.Address, p.spender)
+		if err != nil {
+			log.Printf("⚠️ Allowance of %s on %s for %s: %v", owner.Address.Hex(), p.token.Hex(), p.spender.Hex(), err)
+			continue
+		}
+		if amount.Sign() == 0 {
+			continue
+		}
+		a := Approval{
+			ChainID:   chainID,
+			Owner:     owner.Address,
+			Executor:  owner.Executor,
+			Token:     p.token,
+			Symbol:    symbols[p.token],
+			Spender:   p.spender,
+			Router:    routers[p.spender],
+			Allowance: amount,
+			LastUsed:  last[p],
+		}
+		a.Unused = a.LastUsed < unused
+		a.Delisted = a.Router == "" && !h.keep[p.spender]
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return less(out[i], out[j]) })
+	return out, nil
+}
+
+// history reads owner's Approval and Transfer events between from and to. It
+// returns the token and spender pairs the owner approved, and for each pair
+// the block of its last spend: a Transfer from the owner in a transaction
+// sent to the spender.
+func history(ctx context.Context, client Client, owner common.Address, from, to uint64) (approved map[pair]bool, spent map[pair]uint64, err error) {
+	approved = make(map[pair]bool)
+	spent = make(map[pair]uint64)
+	targets := make(map[common.Hash]*common.Address) // each transaction's to
+	topics := [][]common.Hash{{approvalTopic, transferTopic}, {common.BytesToHash(owner.Bytes())}}
+	for start := from; start <= to; start += maxBlocks {
+		end := min(start+maxBlocks-1, to)
+		logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Topics:    topics,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("blocks %d-%d: %w", start, end, err)
+		}
+		for _, l := range logs {
+			if len(l.Topics) != 3 || l.Removed {
+				continue
+			}
+			if l.Topics[0] == approvalTopic {
+				approved[pair{l.Address, common.BytesToAddress(l.Topics[2].Bytes())}] = true
+				continue
+			}
+			target, ok := targets[l.TxHash]
+			if !ok {
+				tx, _, err := client.TransactionByHash(ctx, l.TxHash)
+				if err != nil {
+					return nil, nil, fmt.Errorf("transaction %s: %w", l.TxHash.Hex(), err)
+				}
+				target = tx.To()
+				targets[l.TxHash] = target
+			}
+			if target == nil {
+				continue
+			}
+			p := pair{l.Address, *target}
+			spent[p] = max(spent[p], l.BlockNumber)
+		}
+	}
+	return approved, spent, nil
+}
+
+// allowance reads owner's allowance for spender on token
+func allowance(ctx context.Context, client Client, token, owner, spender common.Address) (*big.Int, error) {
+	data, err := parsedERC20.Pack("allowance", owner, spender)
+	if err != nil {
+		return nil, err
+	}
+	out, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	values, err := parsedERC20.Unpack("allowance", out)
+	if err != nil {
+		return nil, err
+	}
+	return values[0].(*big.Int), nil
+}
+
+// blockBefore estimates the block age ago from head, at the chain's
+// average block time over the last blockTimeSample blocks
+func blockBefore(ctx context.Context, client Client, head uint64, age time.Duration) (uint64, error) {
+	sample := min(uint64(blockTimeSample), head)
+	if sample == 0 {
+		return 0, nil
+	}
+	newest, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(head))
+	if err != nil {
+		return 0, err
+	}
+	oldest, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(head-sample))
+	if err != nil {
+		return 0, err
+	}
+	if newest.Time <= oldest.Time {
+		return 0, fmt.Errorf("no block time between blocks %d and %d", head-sample, head)
+	}
+	blocks := uint64(age.Seconds()) * sample / (newest.Time - oldest.Time)
+	if blocks >= head {
+		return 0, nil
+	}
+	return head - blocks, nil
+}
+
+func days(n uint64) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
+
+// less orders approvals by owner, token then spender
+func less(a, b Approval) bool {
+	if a.Owner != b.Owner {
+		return a.Owner.Hex() < b.Owner.Hex()
+	}
+	if a.Token != b.Token {
+		return a.Token.Hex() < b.Token.Hex()
+	}
+	return a.Spender.Hex() < b.Spender.Hex()
+}
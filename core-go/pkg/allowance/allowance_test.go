@@ -0,0 +1,161 @@
+package allowance
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+)
+
+// logBackend serves Approval and Transfer logs by block range and owner,
+// and the transactions they were emitted in
+type logBackend struct {
+	*titantest.Backend
+	logs []types.Log
+	txs  map[common.Hash]*types.Transaction
+}
+
+func (b *logBackend) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	tx, ok := b.txs[hash]
+	if !ok {
+		return nil, false, ethereum.NotFound
+	}
+	return tx, false, nil
+}
+
+func (b *logBackend) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var out []types.Log
+	for _, l := range b.logs {
+		if l.BlockNumber < q.FromBlock.Uint64() || l.BlockNumber > q.ToBlock.Uint64() || l.Topics[1] != q.Topics[1][0] {
+			continue
+		}
+		out = append(out, l)
+	}
+	return out, nil
+}
+
+func TestHygieneFindsUnusedAndDelistedApprovals(t *testing.T) {
+	var (
+		owner    = common.HexToAddress("0x0a")
+		usdc     = common.HexToAddress("0x1001")
+		weth     = common.HexToAddress("0x1002")
+		dai      = common.HexToAddress("0x1003")
+		router   = common.HexToAddress("0x2001")
+		delisted = common.HexToAddress("0x2002")
+		lender   = common.HexToAddress("0x2003")
+	)
+	// An hour a block: a day is 24 blocks
+	backend := &logBackend{Backend: titantest.NewBackend(137), txs: make(map[common.Hash]*types.Transaction)}
+	backend.Mine(1000, time.Hour)
+	approval := func(token, spender common.Address, block uint64) types.Log {
+		return types.Log{Address: token, BlockNumber: block,
+			Topics: []common.Hash{approvalTopic, common.BytesToHash(owner.Bytes()), common.BytesToHash(spender.Bytes())}}
+	}
+	// spend is a Transfer of the owner's token in a transaction sent to to
+	spend := func(token, to common.Address, block uint64) types.Log {
+		tx := types.NewTx(&types.LegacyTx{Nonce: uint64(len(backend.txs)), To: &to})
+		backend.txs[tx.Hash()] = tx
+		return types.Log{Address: token, BlockNumber: block, TxHash: tx.Hash(),
+			Topics: []common.Hash{transferTopic, common.BytesToHash(owner.Bytes()), common.BytesToHash(common.HexToAddress("0xfeed").Bytes())}}
+	}
+	backend.logs = []types.Log{
+		approval(usdc, router, 500),
+		spend(usdc, router, 950),      // used two days ago
+		approval(weth, delisted, 500), // three weeks ago
+		approval(usdc, lender, 980),
+		spend(usdc, lender, 990),
+		approval(weth, router, 980),                     // approving again is not a use
+		spend(weth, common.HexToAddress("0x3001"), 985), // nor is a transfer the router did not make
+		spend(dai, router, 960),                         // a standing max approval, used and never re-approved
+	}
+	allowances := map[common.Address]map[common.Address]*big.Int{
+		usdc: {router: big.NewInt(100), lender: big.NewInt(7)},
+		weth: {router: math.MaxBig256, delisted: big.NewInt(5)},
+		dai:  {router: math.MaxBig256},
+	}
+	for token, spenders := range allowances {
+		spenders := spenders
+		backend.Handle(token, "allowance(address,address)", func(msg ethereum.CallMsg) ([]byte, error) {
+			args, err := parsedERC20.Methods["allowance"].Inputs.Unpack(msg.Data[4:])
+			if err != nil || args[0].(common.Address) != owner {
+				return common.LeftPadBytes(nil, 32), err
+			}
+			amount := spenders[args[1].(common.Address)]
+			if amount == nil {
+				amount = new(big.Int)
+			}
+			return common.LeftPadBytes(amount.Bytes(), 32), nil
+		})
+	}
+
+	cfg := &config.AllowanceConfig{UnusedDays: 10, LookbackDays: 30, Keep: []string{lender.Hex()}}
+	routers := map[uint64]config.DexRouters{137: {"QUICKSWAP": {Address: router.Hex()}}}
+	list := []tokens.Token{{Symbol: "USDC", Address: usdc}, {Symbol: "WETH", Address: weth}, {Symbol: "DAI", Address: dai}}
+	executor := false
+	reg := metrics.NewRegistry()
+	h := New(cfg, routers,
+		func(chainID uint64) (Client, error) { return backend, nil },
+		func(chainID uint64) []tokens.Token { return list },
+		func(chainID uint64) []Owner { return []Owner{{Address: owner, Executor: executor}} },
+		reg,
+	)
+
+	found, err := h.Scan(context.Background(), 137, Owner{Address: owner})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 5 {
+		t.Fatalf("Expected five standing approvals, got %+v", found)
+	}
+	kept, lent, unused, stale, standing := found[0], found[1], found[2], found[3], found[4]
+	if kept.Spender != router || kept.Router != "QUICKSWAP" || kept.LastUsed != 950 || kept.Due() {
+		t.Errorf("Expected the recently used router approval kept, got %+v", kept)
+	}
+	if lent.Spender != lender || lent.Due() {
+		t.Errorf("Expected the kept lender's approval left alone, got %+v", lent)
+	}
+	if unused.Spender != router || !unused.Unused || unused.Delisted || unused.Symbol != "WETH" || unused.LastUsed != 0 {
+		t.Errorf("Expected the never-used WETH approval due, got %+v", unused)
+	}
+	if stale.Spender != delisted || !stale.Unused || !stale.Delisted || stale.Allowance.Int64() != 5 {
+		t.Errorf("Expected the old router's approval due as delisted, got %+v", stale)
+	}
+	if standing.Token != dai || standing.Spender != router || standing.LastUsed != 960 || standing.Due() {
+		t.Errorf("Expected the max DAI approval kept by its recent spend, got %+v", standing)
+	}
+
+	var revoked []Approval
+	h.Revoke = func(ctx context.Context, a Approval) (common.Hash, error) {
+		call, err := Revocation(a)
+		if err != nil || call.To != a.Token || call.From != owner || !bytes.Equal(call.Data[:4], parsedERC20.Methods["approve"].ID) {
+			t.Errorf("Expected approve(spender, 0) on the token, got %+v: %v", call, err)
+		}
+		revoked = append(revoked, a)
+		return common.Hash{1}, nil
+	}
+	due, err := h.Sweep(context.Background(), 137)
+	if err != nil || len(due) != 0 || len(revoked) != 2 {
+		t.Errorf("Expected both due approvals revoked, got %d left and %d revoked: %v", len(due), len(revoked), err)
+	}
+
+	// An executor's approvals are only reported
+	executor, revoked = true, nil
+	var reported int
+	h.OnDue = func(a Approval) { reported++ }
+	if due, err := h.Sweep(context.Background(), 137); err != nil || len(due) != 2 || len(revoked) != 0 || reported != 2 {
+		t.Errorf("Expected the executor's due approvals reported, not revoked, got %d due, %d revoked", len(due), len(revoked))
+	}
+	if len(h.Due()) != 2 || reg.Value("titan_approvals_due", "137") != 2 {
+		t.Errorf("Expected two approvals left due, got %v", h.Due())
+	}
+}
@@ -0,0 +1,84 @@
+package amm
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/bigpool"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+var (
+	// ErrInsufficientLiquidity is returned when a pool cannot fill the requested amount
+	ErrInsufficientLiquidity = errors.New("amm: insufficient liquidity")
+	// ErrInvalidAmount is returned for zero or negative inputs
+	ErrInvalidAmount = errors.New("amm: invalid amount")
+)
+
+// GetAmountOut returns the constant-product (UniswapV2) output for amountIn,
+// charging feeBps on the input side
+func GetAmountOut(amountIn, reserveIn, reserveOut *big.Int, feeBps uint32) (*big.Int, error) {
+	if amountIn.Sign() <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if reserveIn.Sign() <= 0 || reserveOut.Sign() <= 0 {
+		return nil, ErrInsufficientLiquidity
+	}
+
+	// amountOut = amountIn*(1-fee)*reserveOut / (reserveIn + amountIn*(1-fee))
+	feeFactor, amountInWithFee, denominator := bigpool.Get(), bigpool.Get(), bigpool.Get()
+	defer bigpool.Put(feeFactor, amountInWithFee, denominator)
+
+	amountInWithFee.Mul(amountIn, feeFactor.SetUint64(uint64(units.BpsDenominator-feeBps)))
+	denominator.Mul(reserveIn, feeFactor.SetUint64(units.BpsDenominator))
+	denominator.Add(denominator, amountInWithFee)
+
+	amountOut := new(big.Int).Mul(amountInWithFee, reserveOut)
+	return amountOut.Quo(amountOut, denominator), nil
+}
+
+// GetAmountIn returns the input required to receive exactly amountOut
+func GetAmountIn(amountOut, reserveIn, reserveOut *big.Int, feeBps uint32) (*big.Int, error) {
+	if amountOut.Sign() <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if reserveIn.Sign() <= 0 || amountOut.Cmp(reserveOut) >= 0 {
+		return nil, ErrInsufficientLiquidity
+	}
+
+	// amountIn = reserveIn*amountOut / ((reserveOut-amountOut)*(1-fee)) + 1
+	factor, denominator := bigpool.Get(), bigpool.Get()
+	defer bigpool.Put(factor, denominator)
+
+	denominator.Sub(reserveOut, amountOut)
+	denominator.Mul(denominator, factor.SetUint64(uint64(units.BpsDenominator-feeBps)))
+
+	amountIn := new(big.Int).Mul(reserveIn, amountOut)
+	amountIn.Mul(amountIn, factor.SetUint64(units.BpsDenominator))
+	amountIn.Quo(amountIn, denominator)
+	return amountIn.Add(amountIn, factor.SetUint64(1)), nil
+}
+
+// Pool is a constant-product pool snapshot
+type Pool struct {
+	Reserve0 *big.Int
+	Reserve1 *big.Int
+	FeeBps   uint32
+}
+
+// Quote returns the output of swapping amountIn through the pool in the given direction
+func (p *Pool) Quote(amountIn *big.Int, zeroForOne bool) (*big.Int, error) {
+	if zeroForOne {
+		return GetAmountOut(amountIn, p.Reserve0, p.Reserve1, p.FeeBps)
+	}
+	return GetAmountOut(amountIn, p.Reserve1, p.Reserve0, p.FeeBps)
+}
+
+// QuoteIn returns the input needed to receive exactly amountOut from the pool
+// in the given direction
+func (p *Pool) QuoteIn(amountOut *big.Int, zeroForOne bool) (*big.Int, error) {
+	if zeroForOne {
+		return GetAmountIn(amountOut, p.Reserve0, p.Reserve1, p.FeeBps)
+	}
+	return GetAmountIn(amountOut, p.Reserve1, p.Reserve0, p.FeeBps)
+}
@@ -0,0 +1,84 @@
+package amm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+)
+
+func TestGetAmountOut(t *testing.T) {
+	// 1000 in against 1M/1M reserves at 30 bps: 9970000*1e6 / (1e10 + 9970000)
+	out, err := GetAmountOut(big.NewInt(1000), big.NewInt(1_000_000), big.NewInt(1_000_000), 30)
+	if err != nil {
+		t.Fatalf("GetAmountOut failed: %v", err)
+	}
+	if out.Int64() != 996 {
+		t.Errorf("Expected 996, got %s", out)
+	}
+
+	if _, err := GetAmountOut(big.NewInt(0), big.NewInt(1), big.NewInt(1), 30); err != ErrInvalidAmount {
+		t.Errorf("Expected ErrInvalidAmount, got %v", err)
+	}
+}
+
+func TestGetAmountInRoundTrip(t *testing.T) {
+	reserveIn := big.NewInt(5_000_000_000)
+	reserveOut := big.NewInt(2_000_000_000)
+	want := big.NewInt(12_345_678)
+
+	in, err := GetAmountIn(want, reserveIn, reserveOut, 30)
+	if err != nil {
+		t.Fatalf("GetAmountIn failed: %v", err)
+	}
+	out, _ := GetAmountOut(in, reserveIn, reserveOut, 30)
+	if out.Cmp(want) < 0 {
+		t.Errorf("Expected at least %s out for %s in, got %s", want, in, out)
+	}
+
+	if _, err := GetAmountIn(reserveOut, reserveIn, reserveOut, 30); err != ErrInsufficientLiquidity {
+		t.Errorf("Expected ErrInsufficientLiquidity, got %v", err)
+	}
+}
+
+func TestPoolQuoteInBothDirections(t *testing.T) {
+	p := &Pool{Reserve0: big.NewInt(5_000_000_000), Reserve1: big.NewInt(2_000_000_000), FeeBps: 30}
+	want := big.NewInt(1_000_000)
+	for _, zeroForOne := range []bool{true, false} {
+		in, err := p.QuoteIn(want, zeroForOne)
+		if err != nil {
+			t.Fatalf("QuoteIn failed: %v", err)
+		}
+		if out, _ := p.Quote(in, zeroForOne); out.Cmp(want) < 0 {
+			t.Errorf("zeroForOne=%v: expected at least %s out for %s in, got %s", zeroForOne, want, in, out)
+		}
+		less := new(big.Int).Sub(in, big.NewInt(2))
+		if out, _ := p.Quote(less, zeroForOne); out.Cmp(want) >= 0 {
+			t.Errorf("zeroForOne=%v: expected %s in to be about the least that delivers, got %s out", zeroForOne, in, out)
+		}
+	}
+}
+
+func TestGetAmountOutU256MatchesBig(t *testing.T) {
+	reserveIn, _ := new(big.Int).SetString("123456789012345678901234", 10)
+	reserveOut, _ := new(big.Int).SetString("987654321098765", 10)
+	amountIn := big.NewInt(5_000_000_000_000_000)
+
+	want, err := GetAmountOut(amountIn, reserveIn, reserveOut, 25)
+	if err != nil {
+		t.Fatalf("GetAmountOut failed: %v", err)
+	}
+
+	var got uint256.Int
+	if err := GetAmountOutU256(&got, uint256.MustFromBig(amountIn), uint256.MustFromBig(reserveIn), uint256.MustFromBig(reserveOut), 25); err != nil {
+		t.Fatalf("GetAmountOutU256 failed: %v", err)
+	}
+	if got.ToBig().Cmp(want) != 0 {
+		t.Errorf("Expected %s, got %s", want, got.ToBig())
+	}
+
+	maxU256 := new(uint256.Int).SetAllOne()
+	if err := GetAmountOutU256(&got, maxU256, uint256.NewInt(1), uint256.NewInt(1), 30); err != ErrOverflow {
+		t.Errorf("Expected ErrOverflow, got %v", err)
+	}
+}
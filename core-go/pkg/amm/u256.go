@@ -0,0 +1,49 @@
+package amm
+
+import (
+	"errors"
+
+	"github.com/holiman/uint256"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// ErrOverflow is returned when an intermediate value exceeds 256 bits
+var ErrOverflow = errors.New("amm: uint256 overflow")
+
+var bpsDenominatorU256 = uint256.NewInt(units.BpsDenominator)
+
+// GetAmountOutU256 is the allocation-free fixed-point variant of GetAmountOut.
+// The result is written into dst, which may alias none of the inputs.
+func GetAmountOutU256(dst, amountIn, reserveIn, reserveOut *uint256.Int, feeBps uint32) error {
+	if amountIn.IsZero() {
+		return ErrInvalidAmount
+	}
+	if reserveIn.IsZero() || reserveOut.IsZero() {
+		return ErrInsufficientLiquidity
+	}
+
+	var amountInWithFee, denominator uint256.Int
+	if _, overflow := amountInWithFee.MulOverflow(amountIn, uint256.NewInt(uint64(units.BpsDenominator-feeBps))); overflow {
+		return ErrOverflow
+	}
+	if _, overflow := denominator.MulOverflow(reserveIn, bpsDenominatorU256); overflow {
+		return ErrOverflow
+	}
+	if _, overflow := denominator.AddOverflow(&denominator, &amountInWithFee); overflow {
+		return ErrOverflow
+	}
+	// 512-bit intermediate product, so amountInWithFee*reserveOut never wraps
+	if _, overflow := dst.MulDivOverflow(&amountInWithFee, reserveOut, &denominator); overflow {
+		return ErrOverflow
+	}
+	return nil
+}
+
+// QuoteU256 is the fixed-point variant of Pool.Quote for hot loops over cached reserves
+func QuoteU256(dst, amountIn, reserve0, reserve1 *uint256.Int, feeBps uint32, zeroForOne bool) error {
+	if zeroForOne {
+		return GetAmountOutU256(dst, amountIn, reserve0, reserve1, feeBps)
+	}
+	return GetAmountOutU256(dst, amountIn, reserve1, reserve0, feeBps)
+}
@@ -0,0 +1,213 @@
+package amortize
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// DefaultWindow is how far back costs and executions are matched
+const DefaultWindow = 7 * 24 * time.Hour
+
+// ErrInvalidCost is returned recording a cost that can't be attributed
+var ErrInvalidCost = errors.New("amortize: invalid cost")
+
+// RouteCost is the amortization of one route's costs, or of a chain's shared
+// costs when Route is empty
+type RouteCost struct {
+	ChainID    uint64    `json:"chainId"`
+	Route      string    `json:"route,omitempty"`
+	CostUSD    units.USD `json:"costUsd"`
+	Executions int       `json:"executions"`
+	PerTrade   units.USD `json:"perTradeUsd"` // charged to the next trade
+}
+
+type key struct {
+	chainID uint64
+	route   string
+}
+
+// Ledger spreads one-off costs over the executions that benefit from them:
+// a route's own costs over its executions and a chain's shared costs over
+// every execution on the chain, both within a lookback window. The next trade
+// is counted as one of them, so a route that has never traded carries its
+// costs in full and a busy route is charged its share rather than nothing.
+// It is safe for concurrent use.
+type Ledger struct {
+	window time.Duration
+
+	mu         sync.Mutex
+	costs      map[key][]opportunity.Cost
+	executions map[key][]time.Time
+	now        func() time.Time
+
+	recorded *metrics.CounterVec
+}
+
+// New creates a ledger matching costs and executions within window (0 uses
+// DefaultWindow); reg may be nil
+func New(window time.Duration, reg *metrics.Registry) *Ledger {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	l := &Ledger{
+		window:     window,
+		costs:      make(map[key][]opportunity.Cost),
+		executions: make(map[key][]time.Time),
+		now:        time.Now,
+	}
+	if reg != nil {
+		l.recorded = reg.Counter("titan_amortized_costs_usd_total", "One-off costs recorded for amortization, in USD", "chain", "kind")
+	}
+	return l
+}
+
+// Validate checks a cost can be recorded
+func Validate(c *opportunity.Cost) error {
+	switch c.Kind {
+	case opportunity.CostApproval, opportunity.CostBridge, opportunity.CostDeploy:
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidCost, c.Kind)
+	}
+	if c.ChainID == 0 {
+		return fmt.Errorf("%w: chain is required", ErrInvalidCost)
+	}
+	if c.USD <= 0 {
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidCost)
+	}
+	return nil
+}
+
+// Observe takes costs and successful executions from the recorder; it has
+// the signature of opportunity.Recorder listeners
+func (l *Ledger) Observe(kind string, record interface{}) {
+	switch r := record.(type) {
+	case *opportunity.Cost:
+		l.addCost(*r)
+	case *opportunity.Execution:
+		if r.Success {
+			l.addExecution(*r)
+		}
+	}
+}
+
+// Load replays journaled costs and executions
+func (l *Ledger) Load(entries []journal.Entry) error {
+	for _, e := range entries {
+		switch e.Kind {
+		case journal.KindCost:
+			var c opportunity.Cost
+			if err := json.Unmarshal(e.Data, &c); err != nil {
+				return fmt.Errorf("bad cost entry at %s: %w", e.Time, err)
+			}
+			l.addCost(c)
+		case journal.KindExecution:
+			var x opportunity.Execution
+			if err := json.Unmarshal(e.Data, &x); err != nil {
+				return fmt.Errorf("bad execution entry at %s: %w", e.Time, err)
+			}
+			if x.Success {
+				l.addExecution(x)
+			}
+		}
+	}
+	return nil
+}
+
+func (l *Ledger) addCost(c opportunity.Cost) {
+	if c.Time.IsZero() {
+		c.Time = l.now()
+	}
+	l.mu.Lock()
+	k := key{c.ChainID, c.Route}
+	l.costs[k] = append(l.costs[k], c)
+	l.mu.Unlock()
+	if l.recorded != nil {
+		l.recorded.Add(c.USD.Float(), strconv.FormatUint(c.ChainID, 10), c.Kind)
+	}
+}
+
+func (l *Ledger) addExecution(x opportunity.Execution) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, k := range []key{{x.ChainID, x.Route}, {x.ChainID, ""}} {
+		l.executions[k] = append(l.executions[k], x.Time)
+	}
+}
+
+// Charge returns the share of one-off costs the next trade of route on
+// chainID should carry
+func (l *Ledger) Charge(chainID uint64, route string) units.USD {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	since := l.now().Add(-l.window)
+	charge := l.amortizeLocked(key{chainID, ""}, since).PerTrade
+	if route != "" {
+		charge += l.amortizeLocked(key{chainID, route}, since).PerTrade
+	}
+	return charge
+}
+
+// amortizeLocked spreads k's costs since the cutoff over its executions plus
+// the next trade, pruning older records; caller holds l.mu
+func (l *Ledger) amortizeLocked(k key, since time.Time) RouteCost {
+	rc := RouteCost{ChainID: k.chainID, Route: k.route}
+	costs := l.costs[k][:0]
+	for _, c := range l.costs[k] {
+		if c.Time.Before(since) {
+			continue
+		}
+		costs = append(costs, c)
+		rc.CostUSD += c.USD
+	}
+	l.costs[k] = costs
+	executions := l.executions[k][:0]
+	for _, at := range l.executions[k] {
+		if !at.Before(since) {
+			executions = append(executions, at)
+		}
+	}
+	l.executions[k] = executions
+	rc.Executions = len(executions)
+	rc.PerTrade = rc.CostUSD / units.USD(rc.Executions+1)
+	return rc
+}
+
+// Routes returns the amortization of every chain and route with costs in the
+// window, largest per-trade charge first
+func (l *Ledger) Routes() []RouteCost {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	since := l.now().Add(-l.window)
+	var out []RouteCost
+	for k := range l.costs {
+		if rc := l.amortizeLocked(k, since); rc.CostUSD > 0 {
+			out = append(out, rc)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].PerTrade != out[j].PerTrade {
+			return out[i].PerTrade > out[j].PerTrade
+		}
+		if out[i].ChainID != out[j].ChainID {
+			return out[i].ChainID < out[j].ChainID
+		}
+		return out[i].Route < out[j].Route
+	})
+	return out
+}
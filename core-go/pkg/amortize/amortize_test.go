@@ -0,0 +1,114 @@
+package amortize
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+func TestChargeSpreadsCosts(t *testing.T) {
+	reg := metrics.NewRegistry()
+	l := New(24*time.Hour, reg)
+	now := time.Unix(1_700_000_000, 0)
+	l.now = func() time.Time { return now }
+
+	l.Observe(journal.KindCost, &opportunity.Cost{Kind: opportunity.CostDeploy, ChainID: 137, USD: units.DollarsToUSD(30)})
+	l.Observe(journal.KindCost, &opportunity.Cost{Kind: opportunity.CostApproval, ChainID: 137, Route: "uniswap>sushi", USD: units.DollarsToUSD(8)})
+	if got := l.Charge(137, "uniswap>sushi"); got != units.DollarsToUSD(38) {
+		t.Errorf("Expected the first trade to carry $38, got %s", got)
+	}
+
+	for i := 0; i < 3; i++ {
+		l.Observe(journal.KindExecution, &opportunity.Execution{ChainID: 137, Route: "uniswap>sushi", Success: true, Time: now})
+	}
+	l.Observe(journal.KindExecution, &opportunity.Execution{ChainID: 137, Route: "quick>sushi", Success: true, Time: now})
+	l.Observe(journal.KindExecution, &opportunity.Execution{ChainID: 137, Route: "uniswap>sushi", Success: false, Time: now})
+
+	// $30 over 4 chain trades plus the next, $8 over 3 route trades plus the next
+	if got := l.Charge(137, "uniswap>sushi"); got != units.DollarsToUSD(8) {
+		t.Errorf("Expected $8 per trade, got %s", got)
+	}
+	if got := l.Charge(137, "quick>sushi"); got != units.DollarsToUSD(6) {
+		t.Errorf("Expected only the shared $6, got %s", got)
+	}
+	if got := l.Charge(1, "uniswap>sushi"); got != 0 {
+		t.Errorf("Expected nothing on a chain without costs, got %s", got)
+	}
+	if got := reg.Value("titan_amortized_costs_usd_total", "137", opportunity.CostDeploy); got != 30 {
+		t.Errorf("Expected $30 of deploy costs recorded, got %v", got)
+	}
+
+	routes := l.Routes()
+	if len(routes) != 2 || routes[0].Route != "" || routes[0].Executions != 4 || routes[1].PerTrade != units.DollarsToUSD(2) {
+		t.Errorf("Expected the shared cost first then the route, got %+v", routes)
+	}
+}
+
+func TestWindowDropsOldCosts(t *testing.T) {
+	l := New(time.Hour, nil)
+	now := time.Unix(1_700_000_000, 0)
+	l.now = func() time.Time { return now }
+	l.Observe(journal.KindCost, &opportunity.Cost{Kind: opportunity.CostBridge, ChainID: 10, USD: units.DollarsToUSD(12), Time: now.Add(-2 * time.Hour)})
+	l.Observe(journal.KindCost, &opportunity.Cost{Kind: opportunity.CostBridge, ChainID: 10, USD: units.DollarsToUSD(4)})
+	l.Observe(journal.KindExecution, &opportunity.Execution{ChainID: 10, Success: true, Time: now.Add(-2 * time.Hour)})
+
+	if got := l.Charge(10, ""); got != units.DollarsToUSD(4) {
+		t.Errorf("Expected only the recent $4 in full, got %s", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	now := time.Now()
+	entry := func(kind string, data interface{}) journal.Entry {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return journal.Entry{Time: now, Kind: kind, Data: raw}
+	}
+	l := New(0, nil)
+	err := l.Load([]journal.Entry{
+		entry(journal.KindCost, opportunity.Cost{Kind: opportunity.CostApproval, ChainID: 8453, Route: "aero>uni", USD: units.DollarsToUSD(9), Time: now}),
+		entry(journal.KindExecution, opportunity.Execution{ChainID: 8453, Route: "aero>uni", Success: true, Time: now}),
+		entry(journal.KindExecution, opportunity.Execution{ChainID: 8453, Route: "aero>uni", Success: true, Time: now}),
+		entry(journal.KindOpportunity, opportunity.Opportunity{ID: "ignored"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Charge(8453, "aero>uni"); got != units.DollarsToUSD(3) {
+		t.Errorf("Expected $3 per trade, got %s", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []opportunity.Cost{
+		{Kind: "lunch", ChainID: 1, USD: 1},
+		{Kind: opportunity.CostBridge, USD: 1},
+		{Kind: opportunity.CostBridge, ChainID: 1},
+	}
+	for _, c := range cases {
+		if err := Validate(&c); !errors.Is(err, ErrInvalidCost) {
+			t.Errorf("Expected %+v rejected, got %v", c, err)
+		}
+	}
+	if err := Validate(&opportunity.Cost{Kind: opportunity.CostDeploy, ChainID: 1, USD: 1}); err != nil {
+		t.Errorf("Expected a valid cost accepted, got %v", err)
+	}
+}
+
+func TestNilLedger(t *testing.T) {
+	var l *Ledger
+	if got := l.Charge(1, "a>b"); got != 0 {
+		t.Errorf("Expected a nil ledger to charge nothing, got %s", got)
+	}
+	if routes := l.Routes(); routes != nil {
+		t.Errorf("Expected no routes, got %+v", routes)
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+)
+
+// Server is the HTTP control and observability API
+type Server struct {
+	mux           *http.ServeMux
+	srv           *http.Server
+	opportunities *opportunity.Store
+	started       time.Time
+}
+
+// New creates an API server bound to addr
+func New(addr string, opportunities *opportunity.Store) *Server {
+	s := &Server{
+		mux:           http.NewServeMux(),
+		opportunities: opportunities,
+		started:       time.Now(),
+	}
+	s.srv = &http.Server{
+		Addr:              addr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	s.mux.HandleFunc("/health", s.handleHealth)
+	s.mux.HandleFunc("/opportunities", s.handleOpportunities)
+	s.mux.HandleFunc("/opportunities/", s.handleOpportunity)
+	return s
+}
+
+// Handle registers an additional route, letting subsystems expose their own endpoints
+func (s *Server) Handle(pattern string, handler http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// Handler returns the root handler, useful for tests
+func (s *Server) Handler() http.Handler {
+	return s.srv.Handler
+}
+
+// ListenAndServe serves until Shutdown is called
+func (s *Server) ListenAndServe() error {
+	if err := s.srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Shutdown gracefully stops the server
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"status":        "ok",
+		"uptimeSeconds": int64(time.Since(s.started).Seconds()),
+	})
+}
+
+// GET /opportunities?limit=N
+func (s *Server) handleOpportunities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+	WriteJSON(w, http.StatusOK, s.opportunities.Recent(limit))
+}
+
+// GET /opportunities/{id}[?format=text]
+// GET /opportunities/{id}/costs, for opportunities with a cost breakdown
+// (MEV-Share backruns); others return 404
+func (s *Server) handleOpportunity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	id, costs := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/opportunities/"), "/costs")
+	o, ok := s.opportunities.Get(id)
+	if !ok {
+		WriteError(w, http.StatusNotFound, "opportunity not found")
+		return
+	}
+	if costs {
+		if o.Explanation.Costs == nil {
+			WriteError(w, http.StatusNotFound, "no cost breakdown recorded")
+			return
+		}
+		WriteJSON(w, http.StatusOK, o.Explanation.Costs)
+		return
+	}
+	if r.URL.Query().Get("format") == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(o.Explanation.Text()))
+		return
+	}
+	WriteJSON(w, http.StatusOK, o)
+}
+
+// WriteJSON writes v as a JSON response
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// WriteError writes a JSON error response
+func WriteError(w http.ResponseWriter, status int, msg string) {
+	WriteJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+)
+
+// Role is what an API key may do; each role includes the ones below it
+type Role int
+
+// Roles, least privileged first
+const (
+	RoleViewer   Role = iota + 1 // read-only endpoints
+	RoleOperator                 // simulations and stopping chains
+	RoleAdmin                    // guardrails and enabling live execution
+)
+
+// String returns the role's configured name
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "viewer"
+	case RoleOperator:
+		return "operator"
+	case RoleAdmin:
+		return "admin"
+	}
+	return fmt.Sprintf("role(%d)", int(r))
+}
+
+// ParseRole parses viewer, operator or admin
+func ParseRole(s string) (Role, error) {
+	for _, r := range []Role{RoleViewer, RoleOperator, RoleAdmin} {
+		if strings.EqualFold(s, r.String()) {
+			return r, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown API role %q: want viewer, operator or admin", s)
+}
+
+// Key is an authenticated API caller
+type Key struct {
+	Name string `json:"name"`
+	Role Role   `json:"-"`
+}
+
+// AuditRecord is one mutating API call, allowed or not
+type AuditRecord struct {
+	Time   time.Time `json:"time"`
+	Key    string    `json:"key,omitempty"` // empty for unauthenticated callers
+	Role   string    `json:"role,omitempty"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	Body   string    `json:"body,omitempty"`
+	Status int       `json:"status"`
+	Remote string    `json:"remote"`
+}
+
+// maxAuditBody bounds the request body kept in an audit record
+const maxAuditBody = 4096
+
+// openPaths answer without a key: liveness probes and cluster peers' status polls
+var openPaths = map[string]bool{"/health": true, "/status": true}
+
+// Auth checks API keys sent as "Authorization: Bearer <key>" or "X-API-Key".
+// Reads need a viewer key and changes an operator key; handlers demand admin
+// for the most sensitive changes with Allow. Every mutating call is audited.
+type Auth struct {
+	// Audit receives each mutating call after it is answered; nil only logs
+	Audit func(AuditRecord)
+
+	keys map[[32]byte]Key // by SHA-256 of the secret
+}
+
+// NewAuth creates an authenticator over keys; nil when none are configured,
+// leaving the API open
+func NewAuth(keys []config.APIKey) (*Auth, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	a := &Auth{keys: make(map[[32]byte]Key, len(keys))}
+	for _, k := range keys {
+		role, err := ParseRole(k.Role)
+		if err != nil {
+			return nil, fmt.Errorf("API key %s: %w", k.Name, err)
+		}
+		if k.Secret == "" {
+			return nil, fmt.Errorf("API key %s: empty secret", k.Name)
+		}
+		sum := sha256.Sum256([]byte(k.Secret))
+		if _, dup := a.keys[sum]; dup {
+			return nil, fmt.Errorf("API key %s: secret already in use", k.Name)
+		}
+		a.keys[sum] = Key{Name: k.Name, Role: role}
+	}
+	return a, nil
+}
+
+// EnableAuth requires API keys on every endpoint but /health and /status; a
+// nil a leaves the API open
+func (s *Server) EnableAuth(a *Auth) {
+	if a != nil {
+		s.srv.Handler = a.wrap(s.mux)
+	}
+}
+
+type keyContext struct{}
+
+// KeyFrom returns the caller's key; false when the API runs without auth
+func KeyFrom(ctx context.Context) (Key, bool) {
+	k, ok := ctx.Value(keyContext{}).(Key)
+	return k, ok
+}
+
+// Allow reports whether the caller holds role, answering 403 when not.
+// Without auth every caller is allowed.
+func Allow(w http.ResponseWriter, r *http.Request, role Role) bool {
+	if k, ok := KeyFrom(r.Context()); ok && k.Role < role {
+		WriteError(w, http.StatusForbidden, fmt.Sprintf("%s role required", role))
+		return false
+	}
+	return true
+}
+
+// authenticate looks up the request's key
+func (a *Auth) authenticate(r *http.Request) (Key, bool) {
+	secret := r.Header.Get("X-API-Key")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		secret = bearer
+	}
+	if secret == "" {
+		return Key{}, false
+	}
+	k, ok := a.keys[sha256.Sum256([]byte(secret))]
+	return k, ok
+}
+
+func (a *Auth) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if openPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		mutating := r.Method != http.MethodGet && r.Method != http.MethodHead
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		var audit *AuditRecord
+		if mutating {
+			audit = &AuditRecord{Time: time.Now().UTC(), Method: r.Method, Path: r.URL.Path, Remote: r.RemoteAddr}
+			body, _ := io.ReadAll(io.LimitReader(r.Body, maxAuditBody))
+			audit.Body = string(body)
+			r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+			defer func() {
+				audit.Status = rec.status
+				a.record(*audit)
+			}()
+		}
+
+		k, ok := a.authenticate(r)
+		if !ok {
+			WriteError(rec, http.StatusUnauthorized, "API key required")
+			return
+		}
+		if audit != nil {
+			audit.Key, audit.Role = k.Name, k.Role.String()
+		}
+		need := RoleViewer
+		if mutating {
+			need = RoleOperator
+		}
+		if k.Role < need {
+			WriteError(rec, http.StatusForbidden, fmt.Sprintf("%s role required", need))
+			return
+		}
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), keyContext{}, k)))
+	})
+}
+
+func (a *Auth) record(rec AuditRecord) {
+	who := rec.Key
+	if who == "" {
+		who = "anonymous"
+	}
+	log.Printf("🔐 API %s %s by %s (%s): %d", rec.Method, rec.Path, who, rec.Remote, rec.Status)
+	if a.Audit != nil {
+		a.Audit(rec)
+	}
+}
+
+// statusRecorder remembers the status a handler answered with
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+)
+
+func TestAuthRoles(t *testing.T) {
+	controls := NewControls([]uint64{137}, nil)
+	s := New("", opportunity.NewStore(1))
+	s.EnableControls(controls)
+	auth, err := NewAuth([]config.APIKey{
+		{Name: "grafana", Role: "viewer", Secret: "v"},
+		{Name: "ops", Role: "operator", Secret: "o"},
+		{Name: "root", Role: "admin", Secret: "a"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var audits []AuditRecord
+	auth.Audit = func(rec AuditRecord) { audits = append(audits, rec) }
+	s.EnableAuth(auth)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	ctx := context.Background()
+
+	status := func(c *Client, method, path string, body interface{}) int {
+		err := c.Do(ctx, method, path, body, nil)
+		if err == nil {
+			return http.StatusOK
+		}
+		for _, code := range []int{401, 403} {
+			if strings.Contains(err.Error(), fmt.Sprintf("HTTP %d", code)) {
+				return code
+			}
+		}
+		t.Fatalf("Unexpected error from %s %s: %v", method, path, err)
+		return 0
+	}
+	anon, viewer, operator, admin := NewClient(srv.URL, ""), NewClient(srv.URL, "v"), NewClient(srv.URL, "o"), NewClient(srv.URL, "a")
+
+	if got := status(anon, http.MethodGet, "/health", nil); got != http.StatusOK {
+		t.Errorf("Expected /health open, got %d", got)
+	}
+	if got := status(anon, http.MethodGet, "/chains", nil); got != http.StatusUnauthorized {
+		t.Errorf("Expected 401 reading without a key, got %d", got)
+	}
+	if got := status(NewClient(srv.URL, "wrong"), http.MethodGet, "/chains", nil); got != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an unknown key, got %d", got)
+	}
+	if got := status(viewer, http.MethodGet, "/chains", nil); got != http.StatusOK {
+		t.Errorf("Expected a viewer to read, got %d", got)
+	}
+
+	off, on := map[string]bool{"enabled": false}, map[string]bool{"enabled": true}
+	if got := status(viewer, http.MethodPost, "/chains/137", off); got != http.StatusForbidden {
+		t.Errorf("Expected 403 for a viewer change, got %d", got)
+	}
+	if got := status(operator, http.MethodPost, "/chains/137", off); got != http.StatusOK || controls.Enabled(137) {
+		t.Errorf("Expected an operator to stop a chain, got %d", got)
+	}
+	if got := status(operator, http.MethodPost, "/chains/137", on); got != http.StatusForbidden || controls.Enabled(137) {
+		t.Errorf("Expected 403 for an operator enabling live execution, got %d", got)
+	}
+	if got := status(operator, http.MethodPut, "/guardrails", map[string]uint64{"maxSlippageBps": 10}); got != http.StatusForbidden {
+		t.Errorf("Expected 403 for an operator changing guardrails, got %d", got)
+	}
+	if got := status(admin, http.MethodPost, "/chains/137", on); got != http.StatusOK || !controls.Enabled(137) {
+		t.Errorf("Expected an admin to enable a chain, got %d", got)
+	}
+	if got := status(admin, http.MethodPut, "/guardrails", map[string]uint64{"maxSlippageBps": 10}); got != http.StatusOK || controls.Guardrails().MaxSlippageBps != 10 {
+		t.Errorf("Expected an admin to change guardrails, got %d", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/guardrails", nil)
+	req.Header.Set("X-API-Key", "v")
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected X-API-Key accepted, got %d", rec.Code)
+	}
+
+	// Every mutating call is audited, allowed or not; reads are not
+	if len(audits) != 6 {
+		t.Fatalf("Expected 6 audit records, got %d: %+v", len(audits), audits)
+	}
+	denied := audits[2]
+	if denied.Key != "ops" || denied.Role != "operator" || denied.Status != http.StatusForbidden || denied.Path != "/chains/137" || !strings.Contains(denied.Body, "true") {
+		t.Errorf("Expected the operator's denied enable recorded, got %+v", denied)
+	}
+	if last := audits[5]; last.Key != "root" || last.Method != http.MethodPut || last.Status != http.StatusOK {
+		t.Errorf("Expected the admin's guardrail change recorded, got %+v", last)
+	}
+}
+
+func TestNewAuthRejectsBadKeys(t *testing.T) {
+	if a, err := NewAuth(nil); a != nil || err != nil {
+		t.Errorf("Expected no keys to leave the API open, got %v, %v", a, err)
+	}
+	for _, keys := range [][]config.APIKey{
+		{{Name: "x", Role: "root", Secret: "s"}},
+		{{Name: "x", Role: "admin"}},
+		{{Name: "x", Role: "admin", Secret: "s"}, {Name: "y", Role: "viewer", Secret: "s"}},
+	} {
+		if _, err := NewAuth(keys); err == nil {
+			t.Errorf("Expected %+v rejected", keys)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Where a runtime change came from
+const (
+	SourceAPI    = "api"    // a control API call
+	SourceEnv    = "env"    // startup configuration differing from the last recorded value
+	SourceReload = "reload" // configuration re-read while running
+)
+
+// Actor is who made a change and through what
+type Actor struct {
+	Source string
+	Name   string
+}
+
+// Change is one setting moving from Old to New; Old is null the first time
+// a setting is recorded
+type Change struct {
+	Time    time.Time       `json:"time"`
+	Source  string          `json:"source"`
+	Actor   string          `json:"actor"`
+	Setting string          `json:"setting"` // e.g. guardrails.maxSlippageBps
+	Old     json.RawMessage `json:"old"`
+	New     json.RawMessage `json:"new"`
+}
+
+// ChangeLog is the append-only history of runtime setting changes. Sink
+// persists each change as it is recorded; history from earlier runs seeds
+// the log so startup values are compared against what was last in force.
+type ChangeLog struct {
+	mu      sync.RWMutex
+	changes []Change
+	current map[string]json.RawMessage
+	sink    func(Change) error
+}
+
+// NewChangeLog creates a log over earlier history, oldest first; sink may be nil
+func NewChangeLog(history []Change, sink func(Change) error) *ChangeLog {
+	l := &ChangeLog{current: make(map[string]json.RawMessage), sink: sink}
+	for _, c := range history {
+		l.changes = append(l.changes, c)
+		l.current[c.Setting] = c.New
+	}
+	return l
+}
+
+// Record appends a change for every setting in value that differs from its
+// last recorded value. A struct's JSON fields become settings named
+// prefix.field; anything else is the single setting prefix. A nil log
+// records nothing.
+func (l *ChangeLog) Record(by Actor, prefix string, value interface{}) error {
+	if l == nil {
+		return nil
+	}
+	values, err := flatten(prefix, value)
+	if err != nil {
+		return err
+	}
+	settings := make([]string, 0, len(values))
+	for s := range values {
+		settings = append(settings, s)
+	}
+	sort.Strings(settings)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now().UTC()
+	for _, setting := range settings {
+		old, known := l.current[setting]
+		if known && bytes.Equal(old, values[setting]) {
+			continue
+		}
+		c := Change{Time: now, Source: by.Source, Actor: by.Name, Setting: setting, Old: old, New: values[setting]}
+		if !known {
+			c.Old = json.RawMessage("null")
+		}
+		if l.sink != nil {
+			if err := l.sink(c); err != nil {
+				return err
+			}
+		}
+		l.changes = append(l.changes, c)
+		l.current[setting] = c.New
+	}
+	return nil
+}
+
+// Recent returns up to limit changes, newest first; limit <= 0 returns all
+func (l *ChangeLog) Recent(limit int) []Change {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if limit <= 0 || limit > len(l.changes) {
+		limit = len(l.changes)
+	}
+	out := make([]Change, limit)
+	for i := range out {
+		out[i] = l.changes[len(l.changes)-1-i]
+	}
+	return out
+}
+
+// flatten marshals v and, when it is a JSON object, splits it into one value
+// per field under prefix
+func flatten(prefix string, v interface{}) (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(raw, &fields) != nil {
+		return map[string]json.RawMessage{prefix: raw}, nil
+	}
+	out := make(map[string]json.RawMessage, len(fields))
+	for k, field := range fields {
+		out[prefix+"."+k] = field
+	}
+	return out, nil
+}
+
+// EnableChanges serves l at GET /changes?limit=100, newest first
+func (s *Server) EnableChanges(l *ChangeLog) {
+	s.mux.HandleFunc("/changes", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		limit := 100
+		if q := r.URL.Query().Get("limit"); q != "" {
+			n, err := strconv.Atoi(q)
+			if err != nil {
+				WriteError(w, http.StatusBadRequest, "invalid limit")
+				return
+			}
+			limit = n
+		}
+		WriteJSON(w, http.StatusOK, l.Recent(limit))
+	})
+}
+
+// apiActor names the caller of an API request: its key, or its address
+// when the API runs without auth
+func apiActor(r *http.Request) Actor {
+	if k, ok := KeyFrom(r.Context()); ok {
+		return Actor{Source: SourceAPI, Name: k.Name}
+	}
+	return Actor{Source: SourceAPI, Name: "anonymous@" + r.RemoteAddr}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+)
+
+func TestChangeLogRecordsOnlyDifferences(t *testing.T) {
+	var sunk []Change
+	history := []Change{{Source: SourceAPI, Actor: "root", Setting: "guardrails.maxSlippageBps", Old: []byte("50"), New: []byte("30")}}
+	l := NewChangeLog(history, func(c Change) error {
+		sunk = append(sunk, c)
+		return nil
+	})
+
+	// Startup config reverts slippage to 50 and records the other limits for the first time
+	startup := Actor{Source: SourceEnv, Name: "startup"}
+	if err := l.Record(startup, "guardrails", config.DefaultGuardrails()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sunk) != 4 {
+		t.Fatalf("Expected 4 changes, got %+v", sunk)
+	}
+	slippage := sunk[0]
+	if slippage.Setting != "guardrails.maxSlippageBps" || string(slippage.Old) != "30" || string(slippage.New) != "50" || slippage.Source != SourceEnv {
+		t.Errorf("Expected slippage 30 → 50 from env, got %+v", slippage)
+	}
+	if string(sunk[1].Old) != "null" {
+		t.Errorf("Expected a first recording to have a null old value, got %s", sunk[1].Old)
+	}
+
+	if err := l.Record(startup, "guardrails", config.DefaultGuardrails()); err != nil || len(sunk) != 4 {
+		t.Errorf("Expected unchanged values not recorded again, got %d changes (%v)", len(sunk), err)
+	}
+	recent := l.Recent(2)
+	if len(recent) != 2 || recent[0].Setting != "guardrails.minProfitUsd" || recent[1].Setting != "guardrails.minLoanUsd" {
+		t.Errorf("Expected newest first, got %+v", recent)
+	}
+	if len(l.Recent(0)) != 5 {
+		t.Errorf("Expected history plus 4 changes, got %d", len(l.Recent(0)))
+	}
+}
+
+func TestControlsRecordChanges(t *testing.T) {
+	failing := false
+	l := NewChangeLog(nil, func(Change) error {
+		if failing {
+			return errors.New("disk full")
+		}
+		return nil
+	})
+	controls := NewControls([]uint64{137}, nil)
+	if err := controls.Track(l); err != nil {
+		t.Fatal(err)
+	}
+	s := New("", opportunity.NewStore(1))
+	s.EnableControls(controls)
+	s.EnableChanges(l)
+	auth, err := NewAuth([]config.APIKey{{Name: "root", Role: "admin", Secret: "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.EnableAuth(auth)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	ctx := context.Background()
+	admin := NewClient(srv.URL, "a")
+
+	if err := admin.Do(ctx, http.MethodPost, "/chains/137", map[string]bool{"enabled": false}, nil); err != nil {
+		t.Fatal(err)
+	}
+	var changes []Change
+	if err := admin.Get(ctx, "/changes?limit=1", &changes); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("Expected 1 change, got %+v", changes)
+	}
+	c := changes[0]
+	if c.Setting != "chains.137.enabled" || c.Actor != "root" || c.Source != SourceAPI || string(c.Old) != "true" || string(c.New) != "false" {
+		t.Errorf("Expected root disabling polygon through the API, got %+v", c)
+	}
+
+	failing = true
+	g := controls.Guardrails()
+	g.MaxSlippageBps = 10
+	if err := controls.SetGuardrails(g, Actor{Source: SourceReload, Name: "SIGHUP"}); err == nil {
+		t.Errorf("Expected an unrecordable change refused")
+	}
+	if controls.Guardrails().MaxSlippageBps == 10 {
+		t.Errorf("Expected guardrails unchanged when the change can't be recorded")
+	}
+}
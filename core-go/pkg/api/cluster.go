@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Self labels this instance in a cluster view
+const Self = "self"
+
+// Status is one instance's summary, served at /status and merged at /cluster
+type Status struct {
+	RunID         string   `json:"runId"`
+	Shard         string   `json:"shard"`
+	Chains        []uint64 `json:"chains"`
+	Leader        *bool    `json:"leader,omitempty"`
+	WatchOnly     bool     `json:"watchOnly,omitempty"` // evaluating without executing
+	UptimeSeconds int64    `json:"uptimeSeconds"`
+}
+
+// InstanceStatus is an instance's status, or why it couldn't be fetched
+type InstanceStatus struct {
+	URL    string  `json:"url"`
+	Status *Status `json:"status,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// ClusterStatus merges the status of every instance in a sharded deployment
+type ClusterStatus struct {
+	Instances []InstanceStatus    `json:"instances"`
+	Owners    map[uint64][]string `json:"owners"`    // chain to the instances running it
+	Uncovered []uint64            `json:"uncovered"` // expected chains no reachable instance runs
+}
+
+// EnableCluster serves status() at /status and, merged with each peer's
+// /status, at /cluster; expected lists every chain the deployment should run
+func (s *Server) EnableCluster(status func() Status, peers []string, expected []uint64) {
+	self := func() Status {
+		st := status()
+		st.UptimeSeconds = int64(time.Since(s.started).Seconds())
+		return st
+	}
+	s.mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, self())
+	})
+	client := &http.Client{Timeout: 3 * time.Second}
+	s.mux.HandleFunc("/cluster", func(w http.ResponseWriter, r *http.Request) {
+		st := self()
+		instances := []InstanceStatus{{URL: Self, Status: &st}}
+		instances = append(instances, fetchPeers(r.Context(), client, peers)...)
+		WriteJSON(w, http.StatusOK, MergeCluster(instances, expected))
+	})
+}
+
+// MergeCluster works out which instances run each chain and which expected
+// chains nobody runs
+func MergeCluster(instances []InstanceStatus, expected []uint64) ClusterStatus {
+	c := ClusterStatus{Instances: instances, Owners: make(map[uint64][]string)}
+	for _, inst := range instances {
+		if inst.Status == nil {
+			continue
+		}
+		for _, id := range inst.Status.Chains {
+			c.Owners[id] = append(c.Owners[id], inst.URL)
+		}
+	}
+	for _, id := range expected {
+		if len(c.Owners[id]) == 0 {
+			c.Uncovered = append(c.Uncovered, id)
+		}
+	}
+	sort.Slice(c.Uncovered, func(i, j int) bool { return c.Uncovered[i] < c.Uncovered[j] })
+	return c
+}
+
+// fetchPeers GETs every peer's /status concurrently
+func fetchPeers(ctx context.Context, client *http.Client, peers []string) []InstanceStatus {
+	out := make([]InstanceStatus, len(peers))
+	var wg sync.WaitGroup
+	for i, peer := range peers {
+		wg.Add(1)
+		go func(i int, peer string) {
+			defer wg.Done()
+			out[i] = InstanceStatus{URL: peer}
+			st, err := fetchStatus(ctx, client, strings.TrimRight(peer, "/")+"/status")
+			if err != nil {
+				out[i].Error = err.Error()
+				return
+			}
+			out[i].Status = st
+		}(i, peer)
+	}
+	wg.Wait()
+	return out
+}
+
+func fetchStatus(ctx context.Context, client *http.Client, url string) (*Status, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	var st Status
+	if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
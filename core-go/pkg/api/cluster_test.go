@@ -0,0 +1,39 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+)
+
+func TestClusterAggregatesPeers(t *testing.T) {
+	peer := New("", opportunity.NewStore(1))
+	peer.EnableCluster(func() Status { return Status{Shard: "1/2", Chains: []uint64{1, 137}} }, nil, nil)
+	peerSrv := httptest.NewServer(peer.Handler())
+	defer peerSrv.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	defer down.Close()
+
+	self := New("", opportunity.NewStore(1))
+	self.EnableCluster(func() Status { return Status{Shard: "0/2", Chains: []uint64{10, 137}} },
+		[]string{peerSrv.URL + "/", down.URL}, []uint64{1, 10, 137, 8453})
+
+	rec := httptest.NewRecorder()
+	self.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cluster", nil))
+	var c ClusterStatus
+	if err := json.NewDecoder(rec.Body).Decode(&c); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Instances) != 3 || c.Instances[1].Status == nil || c.Instances[2].Error != "HTTP 404" {
+		t.Fatalf("Expected self, a reachable peer and a failed one, got %+v", c.Instances)
+	}
+	if owners := c.Owners[137]; len(owners) != 2 || owners[0] != Self {
+		t.Errorf("Expected chain 137 on both instances, got %v", owners)
+	}
+	if len(c.Uncovered) != 1 || c.Uncovered[0] != 8453 {
+		t.Errorf("Expected Base uncovered, got %v", c.Uncovered)
+	}
+}
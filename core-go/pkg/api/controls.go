@@ -0,0 +1,200 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+)
+
+// ErrUnknownChain is returned when toggling a chain this instance doesn't run
+var ErrUnknownChain = errors.New("api: chain not run by this instance")
+
+// ChainControl is one chain's runtime switch
+type ChainControl struct {
+	ChainID uint64 `json:"chainId"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Controls are operator switches adjustable while the daemon runs: which of
+// its chains may hand opportunities to the executor, and the sizing
+// guardrails the executor applies. They reset to configuration on restart.
+type Controls struct {
+	mu         sync.RWMutex
+	chains     map[uint64]bool
+	guardrails config.Guardrails
+	changes    *ChangeLog
+}
+
+// NewControls enables every chain in chains under guardrails g; a nil g uses
+// the built-in defaults
+func NewControls(chains []uint64, g *config.Guardrails) *Controls {
+	if g == nil {
+		g = config.DefaultGuardrails()
+	}
+	c := &Controls{chains: make(map[uint64]bool, len(chains)), guardrails: *g}
+	for _, id := range chains {
+		c.chains[id] = true
+	}
+	return c
+}
+
+// Enabled reports whether a chain may hand opportunities to the executor;
+// a nil Controls enables everything
+func (c *Controls) Enabled(chainID uint64) bool {
+	if c == nil {
+		return true
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.chains[chainID]
+}
+
+// Track records every change to the controls in l, starting with the
+// configured values wherever they differ from what was last in force
+func (c *Controls) Track(l *ChangeLog) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.changes = l
+	startup := Actor{Source: SourceEnv, Name: "startup"}
+	ids := make([]uint64, 0, len(c.chains))
+	for id := range c.chains {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		if err := l.Record(startup, chainSetting(id), c.chains[id]); err != nil {
+			return err
+		}
+	}
+	return l.Record(startup, "guardrails", c.guardrails)
+}
+
+// SetEnabled switches a chain on or off. The change is refused when it
+// can't be recorded.
+func (c *Controls) SetEnabled(chainID uint64, enabled bool, by Actor) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.chains[chainID]; !ok {
+		return ErrUnknownChain
+	}
+	if err := c.changes.Record(by, chainSetting(chainID), enabled); err != nil {
+		return fmt.Errorf("record change: %w", err)
+	}
+	c.chains[chainID] = enabled
+	return nil
+}
+
+func chainSetting(chainID uint64) string {
+	return fmt.Sprintf("chains.%d.enabled", chainID)
+}
+
+// Chains returns every chain's switch, by chain ID
+func (c *Controls) Chains() []ChainControl {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]ChainControl, 0, len(c.chains))
+	for id, enabled := range c.chains {
+		out = append(out, ChainControl{ChainID: id, Name: enum.ChainID(id).Name(), Enabled: enabled})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ChainID < out[j].ChainID })
+	return out
+}
+
+// Guardrails returns the live sizing limits
+func (c *Controls) Guardrails() config.Guardrails {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.guardrails
+}
+
+// SetGuardrails replaces the sizing limits after validating and recording them
+func (c *Controls) SetGuardrails(g config.Guardrails, by Actor) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.changes.Record(by, "guardrails", g); err != nil {
+		return fmt.Errorf("record change: %w", err)
+	}
+	c.guardrails = g
+	return nil
+}
+
+// EnableControls serves c at /chains and /guardrails. Under auth an operator
+// may stop a chain, but enabling one turns on live execution and, like
+// changing guardrails, needs an admin.
+//
+//	GET  /chains
+//	POST /chains/{id}  {"enabled": false}
+//	GET  /guardrails
+//	PUT  /guardrails   {"maxSlippageBps": 30}  (omitted fields are kept)
+func (s *Server) EnableControls(c *Controls) {
+	s.mux.HandleFunc("/chains", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		WriteJSON(w, http.StatusOK, c.Chains())
+	})
+	s.mux.HandleFunc("/chains/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		id, err := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/chains/"), 10, 64)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid chain id")
+			return
+		}
+		var body struct {
+			Enabled *bool `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Enabled == nil {
+			WriteError(w, http.StatusBadRequest, `body must be {"enabled": true|false}`)
+			return
+		}
+		if *body.Enabled && !Allow(w, r, RoleAdmin) {
+			return
+		}
+		if err := c.SetEnabled(id, *body.Enabled, apiActor(r)); errors.Is(err, ErrUnknownChain) {
+			WriteError(w, http.StatusNotFound, err.Error())
+			return
+		} else if err != nil {
+			WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		WriteJSON(w, http.StatusOK, ChainControl{ChainID: id, Name: enum.ChainID(id).Name(), Enabled: *body.Enabled})
+	})
+	s.mux.HandleFunc("/guardrails", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			WriteJSON(w, http.StatusOK, c.Guardrails())
+		case http.MethodPut:
+			if !Allow(w, r, RoleAdmin) {
+				return
+			}
+			g := c.Guardrails()
+			if err := json.NewDecoder(r.Body).Decode(&g); err != nil {
+				WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if err := c.SetGuardrails(g, apiActor(r)); err != nil {
+				WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			WriteJSON(w, http.StatusOK, g)
+		default:
+			WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	})
+}
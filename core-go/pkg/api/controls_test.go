@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+)
+
+func TestControlsToggleChainsAndGuardrails(t *testing.T) {
+	controls := NewControls([]uint64{137, 1}, &config.Guardrails{MinLoanUSD: 10000, MaxTVLShareBps: 2000, MaxSlippageBps: 50})
+	s := New("", opportunity.NewStore(1))
+	s.EnableControls(controls)
+	auth, err := NewAuth([]config.APIKey{{Name: "root", Role: "admin", Secret: "secret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.EnableAuth(auth)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	ctx := context.Background()
+
+	anon := NewClient(srv.URL, "")
+	admin := NewClient(strings.TrimPrefix(srv.URL, "http://"), "secret")
+	var chains []ChainControl
+	if err := admin.Get(ctx, "/chains", &chains); err != nil {
+		t.Fatal(err)
+	}
+	if len(chains) != 2 || chains[0].Name != "ethereum" || !chains[0].Enabled {
+		t.Fatalf("Expected ethereum first and enabled, got %+v", chains)
+	}
+	err = anon.Do(ctx, http.MethodPost, "/chains/137", map[string]bool{"enabled": false}, nil)
+	if err == nil || !strings.Contains(err.Error(), "HTTP 401") {
+		t.Fatalf("Expected 401 without a key, got %v", err)
+	}
+
+	if err := admin.Do(ctx, http.MethodPost, "/chains/137", map[string]bool{"enabled": false}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if controls.Enabled(137) || !controls.Enabled(1) {
+		t.Errorf("Expected only polygon disabled")
+	}
+	if err := admin.Do(ctx, http.MethodPost, "/chains/10", map[string]bool{"enabled": true}, nil); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("Expected 404 for a chain not run here, got %v", err)
+	}
+
+	var g config.Guardrails
+	if err := admin.Do(ctx, http.MethodPut, "/guardrails", map[string]uint64{"maxSlippageBps": 30}, &g); err != nil {
+		t.Fatal(err)
+	}
+	if g.MaxSlippageBps != 30 || g.MinLoanUSD != 10000 || controls.Guardrails().MaxSlippageBps != 30 {
+		t.Errorf("Expected slippage lowered and other limits kept, got %+v", g)
+	}
+	if err := admin.Do(ctx, http.MethodPut, "/guardrails", map[string]uint64{"maxTvlShareBps": 0}, nil); err == nil {
+		t.Errorf("Expected a zero TVL share to be rejected")
+	}
+	if controls.Guardrails().MaxTVLShareBps != 2000 {
+		t.Errorf("Expected rejected guardrails left unchanged, got %+v", controls.Guardrails())
+	}
+}
+
+func TestNilControlsEnableEverything(t *testing.T) {
+	var c *Controls
+	if !c.Enabled(1) {
+		t.Errorf("Expected nil controls to enable every chain")
+	}
+}
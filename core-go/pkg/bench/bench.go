@@ -0,0 +1,178 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/amm"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/multicall"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/pathfind"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+)
+
+// DefaultGraphSizes are the pool counts exercised by route search benchmarks
+var DefaultGraphSizes = []int{50, 200, 1000}
+
+// DefaultMulticallSizes are the calls-per-aggregate exercised by multicall benchmarks
+var DefaultMulticallSizes = []int{10, 100}
+
+// Result is a single benchmark measurement
+type Result struct {
+	Name        string
+	Ops         int
+	NsPerOp     int64
+	OpsPerSec   float64
+	AllocsPerOp int64
+	BytesPerOp  int64
+}
+
+// Case is a named benchmark body shared by `go test -bench` and `titan bench`
+type Case struct {
+	Name string
+	Fn   func(b *testing.B)
+}
+
+// Cases returns the standard hot-path benchmark set
+func Cases(graphSizes, multicallSizes []int) []Case {
+	cases := []Case{
+		{Name: "amm/GetAmountOut", Fn: Quote},
+		{Name: "amm/GetAmountOutU256", Fn: QuoteU256},
+	}
+	for _, n := range graphSizes {
+		cases = append(cases, Case{Name: fmt.Sprintf("pathfind/FindCycles/pools=%d", n), Fn: RouteSearch(n)})
+	}
+	for _, n := range multicallSizes {
+		cases = append(cases, Case{Name: fmt.Sprintf("multicall/Aggregate/calls=%d", n), Fn: Multicall(n)})
+	}
+	return cases
+}
+
+// Run executes cases with testing.Benchmark and returns their results
+func Run(cases []Case) []Result {
+	results := make([]Result, 0, len(cases))
+	for _, c := range cases {
+		r := testing.Benchmark(c.Fn)
+		res := Result{
+			Name:        c.Name,
+			Ops:         r.N,
+			NsPerOp:     r.NsPerOp(),
+			AllocsPerOp: r.AllocsPerOp(),
+			BytesPerOp:  r.AllocedBytesPerOp(),
+		}
+		if res.NsPerOp > 0 {
+			res.OpsPerSec = 1e9 / float64(res.NsPerOp)
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// Quote measures a single constant-product quote
+func Quote(b *testing.B) {
+	amountIn := big.NewInt(1_000_000_000)
+	reserveIn := new(big.Int).Exp(big.NewInt(10), big.NewInt(24), nil)
+	reserveOut := new(big.Int).Exp(big.NewInt(10), big.NewInt(12), nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := amm.GetAmountOut(amountIn, reserveIn, reserveOut, 30); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// QuoteU256 measures the allocation-free fixed-point quote
+func QuoteU256(b *testing.B) {
+	amountIn := uint256.NewInt(1_000_000_000)
+	reserveIn := new(uint256.Int).Exp(uint256.NewInt(10), uint256.NewInt(24))
+	reserveOut := new(uint256.Int).Exp(uint256.NewInt(10), uint256.NewInt(12))
+	var out uint256.Int
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := amm.GetAmountOutU256(&out, amountIn, reserveIn, reserveOut, 30); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// RouteSearch measures 3-hop cycle search on a synthetic graph with the given pool count
+func RouteSearch(pools int) func(b *testing.B) {
+	return func(b *testing.B) {
+		graph, start := SyntheticGraph(pools, 1)
+		amountIn := big.NewInt(1_000_000_000)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			graph.BestCycle(start, amountIn, 3)
+		}
+	}
+}
+
+// Multicall measures a balanceOf batch of the given size against the in-memory chain
+func Multicall(calls int) func(b *testing.B) {
+	return func(b *testing.B) {
+		chain := titantest.NewChain(1)
+		chain.Backend.EnableMulticall()
+		token := chain.Token(18)
+
+		batch := make([]multicall.Call, calls)
+		for i := range batch {
+			holder := titantest.Address(uint64(i + 1))
+			chain.Fund(token, holder, big.NewInt(int64(i)))
+			data := append(titantest.SelectorBalanceOf[:], titantest.EncodeAddress(holder)...)
+			batch[i] = multicall.Call{Target: token, AllowFailure: true, CallData: data}
+		}
+		client := multicall.New(chain.Backend, calls)
+		ctx := context.Background()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := client.Aggregate(ctx, batch, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// SyntheticGraph builds a deterministic random pool graph and returns it with a
+// well-connected start token
+func SyntheticGraph(pools int, seed int64) (*pathfind.Graph, common.Address) {
+	rng := rand.New(rand.NewSource(seed))
+	tokens := pools/4 + 2
+	graph := pathfind.NewGraph()
+	base := new(big.Int).Exp(big.NewInt(10), big.NewInt(21), nil)
+
+	for i := 0; i < pools; i++ {
+		t0 := rng.Intn(tokens)
+		t1 := rng.Intn(tokens - 1)
+		if t1 >= t0 {
+			t1++
+		}
+		// Bias a quarter of pools through token 0 so the start token has cycles
+		if i%4 == 0 {
+			t0 = 0
+			if t1 == 0 {
+				t1 = 1
+			}
+		}
+		r0 := new(big.Int).Mul(base, big.NewInt(int64(900+rng.Intn(200))))
+		r1 := new(big.Int).Mul(base, big.NewInt(int64(900+rng.Intn(200))))
+		graph.AddPool(
+			titantest.Address(uint64(1_000_000+i)),
+			titantest.Address(uint64(t0+1)),
+			titantest.Address(uint64(t1+1)),
+			&amm.Pool{Reserve0: r0, Reserve1: r1, FeeBps: 30},
+		)
+	}
+	return graph, titantest.Address(1)
+}
@@ -0,0 +1,41 @@
+package bench
+
+import "testing"
+
+func BenchmarkQuote(b *testing.B) {
+	Quote(b)
+}
+
+func BenchmarkQuoteU256(b *testing.B) {
+	QuoteU256(b)
+}
+
+func BenchmarkRouteSearch50(b *testing.B) {
+	RouteSearch(50)(b)
+}
+
+func BenchmarkRouteSearch200(b *testing.B) {
+	RouteSearch(200)(b)
+}
+
+func BenchmarkRouteSearch1000(b *testing.B) {
+	RouteSearch(1000)(b)
+}
+
+func BenchmarkMulticall10(b *testing.B) {
+	Multicall(10)(b)
+}
+
+func BenchmarkMulticall100(b *testing.B) {
+	Multicall(100)(b)
+}
+
+func TestSyntheticGraphHasCycles(t *testing.T) {
+	graph, start := SyntheticGraph(200, 1)
+	if graph.PoolCount() != 200 {
+		t.Errorf("Expected 200 pools, got %d", graph.PoolCount())
+	}
+	if len(graph.FindCycles(start, 3)) == 0 {
+		t.Error("Expected synthetic graph to contain cycles from the start token")
+	}
+}
@@ -0,0 +1,25 @@
+package bigpool
+
+import (
+	"math/big"
+	"sync"
+)
+
+var pool = sync.Pool{
+	New: func() interface{} { return new(big.Int) },
+}
+
+// Get returns a zeroed *big.Int from the pool. Callers must not retain it
+// after calling Put.
+func Get() *big.Int {
+	return pool.Get().(*big.Int).SetUint64(0)
+}
+
+// Put returns scratch integers to the pool
+func Put(xs ...*big.Int) {
+	for _, x := range xs {
+		if x != nil {
+			pool.Put(x)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package blackout
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	_ "time/tzdata" // window time zones resolve without the host's zoneinfo
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// Strategies blackout windows can name
+const (
+	StrategyBackrun = "backrun"
+)
+
+// weekdays maps config day names to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Window is one compiled blackout window
+type Window struct {
+	Name       string
+	strategies map[string]bool // empty covers every strategy
+	days       map[time.Weekday]bool
+	dates      map[string]bool
+	start, end int // minutes past midnight; end <= start wraps past midnight
+	loc        *time.Location
+	scale      float64 // profit floor multiplier; 0 pauses
+}
+
+// Verdict is what the windows active at a moment mean for a strategy
+type Verdict struct {
+	Window string  `json:"window,omitempty"` // the window deciding it, empty when none is active
+	Pause  bool    `json:"pause"`
+	Scale  float64 `json:"scale"` // min-profit multiplier, 1 outside raising windows
+}
+
+// Schedule decides when strategies may execute. Pausing windows win over
+// raising ones, and overlapping raises take the highest floor. A nil
+// schedule never blacks anything out.
+type Schedule struct {
+	windows []*Window
+	now     func() time.Time
+	active  *metrics.GaugeVec
+}
+
+// New compiles the configured windows; reg may be nil
+func New(windows []config.BlackoutWindow, reg *metrics.Registry) (*Schedule, error) {
+	s := &Schedule{now: time.Now}
+	for _, cw := range windows {
+		w, err := compile(cw)
+		if err != nil {
+			return nil, fmt.Errorf("blackout window %q: %w", cw.Name, err)
+		}
+		s.windows = append(s.windows, w)
+	}
+	if reg != nil {
+		s.active = reg.Gauge("titan_blackout_active", "1 while a blackout window is active", "window")
+	}
+	return s, nil
+}
+
+func compile(cw config.BlackoutWindow) (*Window, error) {
+	w := &Window{
+		Name:       cw.Name,
+		strategies: make(map[string]bool),
+		days:       make(map[time.Weekday]bool),
+		dates:      make(map[string]bool),
+		loc:        time.UTC,
+		scale:      cw.MinProfitScale,
+	}
+	if w.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	for _, name := range cw.Strategies {
+		w.strategies[strings.ToLower(name)] = true
+	}
+	for _, name := range cw.Days {
+		day, ok := weekdays[strings.ToLower(name)[:min(3, len(name))]]
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q", name)
+		}
+		w.days[day] = true
+	}
+	for _, date := range cw.Dates {
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return nil, fmt.Errorf("date %q: want YYYY-MM-DD", date)
+		}
+		w.dates[date] = true
+	}
+	var err error
+	if w.start, err = clock(cw.Start); err != nil {
+		return nil, fmt.Errorf("start: %w", err)
+	}
+	if w.end, err = clock(cw.End); err != nil {
+		return nil, fmt.Errorf("end: %w", err)
+	}
+	if w.start == w.end {
+		return nil, fmt.Errorf("start and end are both %s", cw.Start)
+	}
+	if cw.Timezone != "" {
+		if w.loc, err = time.LoadLocation(cw.Timezone); err != nil {
+			return nil, err
+		}
+	}
+	if w.scale != 0 && w.scale < 1 {
+		return nil, fmt.Errorf("minProfitScale %.2f would lower the floor", w.scale)
+	}
+	return w, nil
+}
+
+// clock parses HH:MM into minutes past midnight, allowing 24:00
+func clock(s string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("%q: want HH:MM", s)
+	}
+	return h*60 + m, nil
+}
+
+// covers reports whether w applies to strategy at t
+func (w *Window) covers(strategy string, t time.Time) bool {
+	if len(w.strategies) > 0 && !w.strategies[strategy] {
+		return false
+	}
+	return w.activeAt(t)
+}
+
+// activeAt reports whether t falls inside the window
+func (w *Window) activeAt(t time.Time) bool {
+	t = t.In(w.loc)
+	minute := t.Hour()*60 + t.Minute()
+	if w.start < w.end {
+		return minute >= w.start && minute < w.end && w.onDay(t)
+	}
+	// Wrapping past midnight: the early hours belong to the previous day's window
+	if minute >= w.start {
+		return w.onDay(t)
+	}
+	return minute < w.end && w.onDay(t.AddDate(0, 0, -1))
+}
+
+// onDay reports whether a window starting on t's date is scheduled
+func (w *Window) onDay(t time.Time) bool {
+	if len(w.dates) > 0 {
+		return w.dates[t.Format(time.DateOnly)]
+	}
+	return len(w.days) == 0 || w.days[t.Weekday()]
+}
+
+// At returns strategy's verdict at t
+func (s *Schedule) At(strategy string, t time.Time) Verdict {
+	v := Verdict{Scale: 1}
+	if s == nil {
+		return v
+	}
+	strategy = strings.ToLower(strategy)
+	for _, w := range s.windows {
+		if !w.covers(strategy, t) {
+			continue
+		}
+		switch {
+		case w.scale == 0 && !v.Pause:
+			v = Verdict{Window: w.Name, Pause: true, Scale: 1}
+		case !v.Pause && w.scale > v.Scale:
+			v.Window, v.Scale = w.Name, w.scale
+		}
+	}
+	return v
+}
+
+// Now returns strategy's verdict at the current time and refreshes the
+// active-window gauge
+func (s *Schedule) Now(strategy string) Verdict {
+	if s == nil {
+		return Verdict{Scale: 1}
+	}
+	now := s.now()
+	if s.active != nil {
+		for _, w := range s.windows {
+			active := 0.0
+			if w.activeAt(now) {
+				active = 1
+			}
+			s.active.Set(active, w.Name)
+		}
+	}
+	return s.At(strategy, now)
+}
+
+// Apply raises g's profit floor by the verdict's scale
+func (v Verdict) Apply(g config.Guardrails) config.Guardrails {
+	if v.Scale > 1 {
+		g.MinProfitUSD = uint64(math.Round(float64(g.MinProfitUSD) * v.Scale))
+	}
+	return g
+}
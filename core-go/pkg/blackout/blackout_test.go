@@ -0,0 +1,104 @@
+package blackout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+func at(t *testing.T, s string) time.Time {
+	t.Helper()
+	ts, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+func TestWindows(t *testing.T) {
+	s, err := New([]config.BlackoutWindow{
+		{Name: "weekend", Days: []string{"sat", "sun"}, Start: "00:00", End: "24:00", MinProfitScale: 3},
+		{Name: "cpi", Dates: []string{"2026-11-13"}, Start: "08:15", End: "08:45", Timezone: "America/New_York", Strategies: []string{"backrun"}},
+		{Name: "asia-open", Days: []string{"sun"}, Start: "23:00", End: "01:00", MinProfitScale: 2},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		time   string
+		want   Verdict
+		reason string
+	}{
+		{"2026-11-11T12:00:00Z", Verdict{Scale: 1}, "a weekday outside every window"},
+		{"2026-11-14T12:00:00Z", Verdict{Window: "weekend", Scale: 3}, "saturday"},
+		{"2026-11-13T13:30:00Z", Verdict{Window: "cpi", Pause: true, Scale: 1}, "the release in New York time"},
+		{"2026-11-13T08:30:00Z", Verdict{Scale: 1}, "the release hour read as UTC"},
+		{"2026-11-15T23:30:00Z", Verdict{Window: "weekend", Scale: 3}, "the higher floor of two overlapping raises"},
+		{"2026-11-16T00:30:00Z", Verdict{Window: "asia-open", Scale: 2}, "monday's early hours of a window starting sunday"},
+		{"2026-11-17T00:30:00Z", Verdict{Scale: 1}, "tuesday's early hours"},
+	}
+	for _, c := range cases {
+		if got := s.At(StrategyBackrun, at(t, c.time)); got != c.want {
+			t.Errorf("Expected %+v for %s, got %+v", c.want, c.reason, got)
+		}
+	}
+	if got := s.At("liquidation", at(t, "2026-11-13T13:30:00Z")); got.Pause {
+		t.Errorf("Expected the cpi window limited to backruns, got %+v", got)
+	}
+}
+
+func TestPauseWinsOverRaise(t *testing.T) {
+	s, err := New([]config.BlackoutWindow{
+		{Name: "thin", Start: "00:00", End: "06:00", MinProfitScale: 4},
+		{Name: "maintenance", Start: "02:00", End: "03:00"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.At(StrategyBackrun, at(t, "2026-11-11T02:30:00Z")); !got.Pause || got.Window != "maintenance" {
+		t.Errorf("Expected the pause to win, got %+v", got)
+	}
+	g := config.Guardrails{MinLoanUSD: 10000, MinProfitUSD: 10}
+	if got := s.At(StrategyBackrun, at(t, "2026-11-11T04:00:00Z")).Apply(g); got.MinProfitUSD != 40 || got.MinLoanUSD != 10000 {
+		t.Errorf("Expected the profit floor raised 4x, got %+v", got)
+	}
+}
+
+func TestNow(t *testing.T) {
+	reg := metrics.NewRegistry()
+	s, err := New([]config.BlackoutWindow{{Name: "night", Start: "22:00", End: "06:00"}}, reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return at(t, "2026-11-11T23:00:00Z") }
+	if !s.Now(StrategyBackrun).Pause {
+		t.Errorf("Expected the night window to pause")
+	}
+	if got := reg.Value("titan_blackout_active", "night"); got != 1 {
+		t.Errorf("Expected the window reported active, got %v", got)
+	}
+
+	var none *Schedule
+	if got := none.Now(StrategyBackrun); got.Pause || got.Scale != 1 {
+		t.Errorf("Expected a nil schedule to allow execution, got %+v", got)
+	}
+}
+
+func TestInvalidWindows(t *testing.T) {
+	for _, w := range []config.BlackoutWindow{
+		{Start: "00:00", End: "01:00"},
+		{Name: "day", Days: []string{"funday"}, Start: "00:00", End: "01:00"},
+		{Name: "date", Dates: []string{"13/11/2026"}, Start: "00:00", End: "01:00"},
+		{Name: "clock", Start: "25:00", End: "01:00"},
+		{Name: "empty", Start: "01:00", End: "01:00"},
+		{Name: "zone", Start: "00:00", End: "01:00", Timezone: "Mars/Olympus"},
+		{Name: "lower", Start: "00:00", End: "01:00", MinProfitScale: 0.5},
+	} {
+		if _, err := New([]config.BlackoutWindow{w}, nil); err == nil {
+			t.Errorf("Expected %+v rejected", w)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Quote sources
+const (
+	SourceConfig = "config" // estimated from static BridgeConfig ranges
+	SourceAPI    = "api"    // returned by a live bridge or aggregator API
+)
+
+// Request describes a cross-chain transfer to quote
+type Request struct {
+	FromChain uint64
+	ToChain   uint64
+	FromToken tokens.Token
+	ToToken   tokens.Token
+	Amount    units.Amount
+	Sender    common.Address
+	SizeUSD   units.USD // the amount's value when known; sizes calibrated estimates
+}
+
+// Notional is the transfer's value in USD: SizeUSD when set, else a
+// stablecoin amount at par, else 0
+func (r Request) Notional() units.USD {
+	if r.SizeUSD > 0 {
+		return r.SizeUSD
+	}
+	if r.FromToken.Stable() && r.Amount.Value != nil {
+		if usd, err := r.Amount.ToUSD(1e8); err == nil {
+			return usd
+		}
+	}
+	return 0
+}
+
+// Quote is a single bridge's offer for a Request
+type Quote struct {
+	Bridge    string
+	AmountOut units.Amount
+	FeeBps    uint32
+	FeeUSD    units.USD // fees + gas in USD when the source reports them, else 0
+	ETA       time.Duration
+	MaxETA    time.Duration
+	Source    string
+}
+
+// Adapter quotes transfers over one bridge protocol
+type Adapter interface {
+	Name() string
+	Quote(ctx context.Context, req Request) (*Quote, error)
+}
+
+// Result pairs an adapter with its quote or error
+type Result struct {
+	Bridge string
+	Quote  *Quote
+	Err    error
+}
+
+// Registry holds the bridge adapters available to the planner
+type Registry struct {
+	adapters []Adapter
+}
+
+// NewRegistry creates a registry with the given adapters
+func NewRegistry(adapters ...Adapter) *Registry {
+	return &Registry{adapters: adapters}
+}
+
+// FromConfig registers an adapter for every configured bridge: the Hop and
+// CCTP adapters when enabled, else a config estimate refined from realized
+// transfers where calibration has enough; calibration may be nil
+func FromConfig(cfg *config.Config, calibration *Calibrator) *Registry {
+	keys := make([]string, 0, len(cfg.IntentBasedBridges))
+	for key := range cfg.IntentBasedBridges {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	r := NewRegistry()
+	for _, key := range keys {
+		if key == "hop" && cfg.Hop != nil && cfg.Hop.Enabled {
+			r.Register(NewHopAdapter(cfg.Hop, cfg.IntentBasedBridges[key]))
+			continue
+		}
+		if key == "cctp" && cfg.CCTP != nil && cfg.CCTP.Enabled {
+			r.Register(NewCCTPAdapter(cfg.CCTP, cfg.IntentBasedBridges[key]))
+			continue
+		}
+		a := NewConfigAdapter(key, cfg.IntentBasedBridges[key])
+		a.Calibration = calibration
+		r.Register(a)
+	}
+	return r
+}
+
+// Register adds an adapter
+func (r *Registry) Register(a Adapter) {
+	r.adapters = append(r.adapters, a)
+}
+
+// Adapters returns the registered adapters
+func (r *Registry) Adapters() []Adapter {
+	return r.adapters
+}
+
+// QuoteAll queries every adapter concurrently, bounding each by timeout.
+// Successful quotes are sorted by amount received (best first), failures last.
+func (r *Registry) QuoteAll(ctx context.Context, req Request, timeout time.Duration) []Result {
+	results := make([]Result, len(r.adapters))
+	var wg sync.WaitGroup
+	for i, a := range r.adapters {
+		wg.Add(1)
+		go func(i int, a Adapter) {
+			defer wg.Done()
+			qctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			q, err := a.Quote(qctx, req)
+			results[i] = Result{Bridge: a.Name(), Quote: q, Err: err}
+		}(i, a)
+	}
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		qi, qj := results[i].Quote, results[j].Quote
+		if qi == nil || qj == nil {
+			return qj == nil && qi != nil
+		}
+		return qi.AmountOut.Cmp(qj.AmountOut) > 0
+	})
+	return results
+}
+
+// ConfigAdapter estimates quotes from a static BridgeConfig
+type ConfigAdapter struct {
+	key string
+	cfg *config.BridgeConfig
+
+	// Calibration, when set, replaces the configured fee and times with
+	// realized ones on routes it has enough transfers for
+	Calibration *Calibrator
+}
+
+// NewConfigAdapter creates an adapter for a configured bridge
+func NewConfigAdapter(key string, cfg *config.BridgeConfig) *ConfigAdapter {
+	return &ConfigAdapter{key: key, cfg: cfg}
+}
+
+// Name returns the bridge key
+func (a *ConfigAdapter) Name() string {
+	return a.key
+}
+
+// Quote charges the realized median fee when calibrated, else the midpoint
+// of the configured fee range
+func (a *ConfigAdapter) Quote(ctx context.Context, req Request) (*Quote, error) {
+	q := &Quote{
+		Bridge: a.key,
+		ETA:    time.Duration(a.cfg.TypicalTimeSeconds) * time.Second,
+		MaxETA: time.Duration(a.cfg.MaxTimeSeconds) * time.Second,
+		Source: SourceConfig,
+	}
+	if e, ok := a.Calibration.Estimate(a.key, req.FromChain, req.ToChain, req.Notional()); ok {
+		q.FeeBps = uint32(math.Min(math.Max(math.Ceil(e.FeeBps), 0), units.BpsDenominator))
+		q.ETA, q.MaxETA, q.Source = e.Fill, max(e.MaxFill, e.Fill), SourceRealized
+	} else if len(a.cfg.FeeRangeBps) == 2 {
+		q.FeeBps = (a.cfg.FeeRangeBps[0] + a.cfg.FeeRangeBps[1]) / 2
+	} else {
+		return nil, fmt.Errorf("bridge %s: fee range not configured", a.key)
+	}
+	feeBps := q.FeeBps
+
+	net := new(uint256.Int).Mul(req.Amount.Value, uint256.NewInt(uint64(10000-feeBps)))
+	net.Div(net, uint256.NewInt(10000))
+	out, err := units.New(req.FromToken.Address, net, req.Amount.Decimals).Rescale(req.ToToken.Address, req.ToToken.Decimals)
+	if err != nil {
+		return nil, err
+	}
+
+	q.AmountOut = out
+	return q, nil
+}
@@ -0,0 +1,78 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+func testRequest(t *testing.T) Request {
+	reg := tokens.Default()
+	from, _ := reg.Lookup(137, "USDC")
+	to, _ := reg.Lookup(56, "USDC")
+	amount, err := units.FromWhole(from.Address, 10_000, from.Decimals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Request{FromChain: 137, ToChain: 56, FromToken: from, ToToken: to, Amount: amount}
+}
+
+func TestConfigAdapterRescalesDecimals(t *testing.T) {
+	adapter := NewConfigAdapter("across", &config.BridgeConfig{
+		TypicalTimeSeconds: 30, MaxTimeSeconds: 180, FeeRangeBps: []uint32{5, 30},
+	})
+
+	q, err := adapter.Quote(context.Background(), testRequest(t))
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	if q.FeeBps != 17 {
+		t.Errorf("Expected midpoint fee 17 bps, got %d", q.FeeBps)
+	}
+	// 10,000 USDC (6dp) less 17 bps, delivered as 18dp BSC USDC
+	if q.AmountOut.Decimals != 18 || q.AmountOut.String() != "9983" {
+		t.Errorf("Expected 9983 at 18 decimals, got %s at %d", q.AmountOut, q.AmountOut.Decimals)
+	}
+}
+
+type failingAdapter struct{}
+
+func (failingAdapter) Name() string { return "down" }
+func (failingAdapter) Quote(ctx context.Context, req Request) (*Quote, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestQuoteAllSortsBestFirst(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fromChain") != "137" {
+			http.Error(w, "bad chain", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"tool":"stargate","estimate":{"toAmount":"9995000000000000000000","executionDuration":45,
+			"feeCosts":[{"amountUSD":"1.50"}],"gasCosts":[{"amountUSD":"0.25"}]}}`))
+	}))
+	defer srv.Close()
+
+	cfg, _ := config.LoadFromEnv()
+	reg := FromConfig(cfg, nil)
+	reg.Register(failingAdapter{})
+	reg.Register(NewLifiAdapter(srv.URL, ""))
+
+	results := reg.QuoteAll(context.Background(), testRequest(t), time.Second)
+	if results[0].Bridge != "lifi" || results[0].Quote.Bridge != "lifi:stargate" {
+		t.Fatalf("Expected LiFi quote first, got %+v", results[0])
+	}
+	if results[0].Quote.FeeBps != 5 || results[0].Quote.FeeUSD != 1_750_000 {
+		t.Errorf("Expected 5 bps / $1.75, got %d bps / %s", results[0].Quote.FeeBps, results[0].Quote.FeeUSD)
+	}
+	if last := results[len(results)-1]; last.Err == nil {
+		t.Errorf("Expected failing adapter last, got %+v", last)
+	}
+}
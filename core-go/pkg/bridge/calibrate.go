@@ -0,0 +1,429 @@
+package bridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/seal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// SourceRealized marks quotes estimated from realized transfers
+const SourceRealized = "realized"
+
+// sizeBounds are the upper bounds of the transfer size buckets, in USD;
+// fees with a fixed part cost more in bps on small transfers
+var sizeBounds = []uint64{1_000, 10_000, 100_000}
+
+// SizeBucket names the size bucket of a transfer worth usd, "" when unknown
+func SizeBucket(usd units.USD) string {
+	if usd <= 0 {
+		return ""
+	}
+	lower := uint64(0)
+	for _, bound := range sizeBounds {
+		if usd < units.DollarsToUSD(bound) {
+			return fmt.Sprintf("%s-%s", dollars(lower), dollars(bound))
+		}
+		lower = bound
+	}
+	return dollars(lower) + "+"
+}
+
+// dollars renders a whole-dollar bound, e.g. $10k
+func dollars(n uint64) string {
+	if n >= 1000 {
+		return fmt.Sprintf("$%dk", n/1000)
+	}
+	return fmt.Sprintf("$%d", n)
+}
+
+// Transfer is one realized bridge transfer
+type Transfer struct {
+	Bridge    string        `json:"bridge"`
+	FromChain uint64        `json:"fromChain"`
+	ToChain   uint64        `json:"toChain"`
+	SizeUSD   units.USD     `json:"sizeUsd"`
+	FeeBps    float64       `json:"feeBps"` // sent less received, of sent
+	Fill      time.Duration `json:"fill"`   // source fill to arrival
+	At        time.Time     `json:"at"`
+}
+
+// Realized builds the transfer record of a plan whose funds landed,
+// received being what arrived. Fees are only measurable when the same
+// asset lands as was sent.
+func Realized(p Plan, received units.Amount) (Transfer, error) {
+	if !p.Arrived || p.Sent.IsZero() {
+		return Transfer{}, fmt.Errorf("bridge: plan %s has not landed", p.ID)
+	}
+	if !strings.EqualFold(p.Transfer.FromToken.Symbol, p.Transfer.ToToken.Symbol) {
+		return Transfer{}, fmt.Errorf("bridge: plan %s swaps %s for %s, so its fee is not measurable",
+			p.ID, p.Transfer.FromToken.Symbol, p.Transfer.ToToken.Symbol)
+	}
+	sent := p.Transfer.Amount.Float()
+	if sent <= 0 {
+		return Transfer{}, fmt.Errorf("bridge: plan %s sent nothing", p.ID)
+	}
+	size := p.NotionalUSD
+	if size == 0 {
+		size = p.Transfer.Notional()
+	}
+	return Transfer{
+		Bridge:    p.Bridge,
+		FromChain: p.Transfer.FromChain,
+		ToChain:   p.Transfer.ToChain,
+		SizeUSD:   size,
+		FeeBps:    (sent - received.Float()) / sent * units.BpsDenominator,
+		Fill:      p.Landed.Sub(p.Sent),
+		At:        p.Landed,
+	}, nil
+}
+
+// Estimate is a bridge's realized cost on a route, for one size bucket or
+// every size when Size is empty
+type Estimate struct {
+	Bridge      string        `json:"bridge"`
+	FromChain   uint64        `json:"fromChain"`
+	ToChain     uint64        `json:"toChain"`
+	Size        string        `json:"size,omitempty"`
+	Transfers   int           `json:"transfers"`
+	FeeBps      float64       `json:"feeBps"`      // median
+	FeeRangeBps [2]float64    `json:"feeRangeBps"` // 10th to 90th percentile
+	Fill        time.Duration `json:"fill"`        // median
+	MaxFill     time.Duration `json:"maxFill"`     // 90th percentile
+}
+
+// Drift compares a bridge's realized costs with its configured ones
+type Drift struct {
+	Bridge         string        `json:"bridge"`
+	Transfers      int           `json:"transfers"`
+	FeeBps         float64       `json:"feeBps"` // realized median over every route
+	ConfiguredBps  []uint32      `json:"configuredBps"`
+	Fill           time.Duration `json:"fill"` // realized median
+	ConfiguredFill time.Duration `json:"configuredFill"`
+	ConfiguredMax  time.Duration `json:"configuredMax"`
+	Drifted        bool          `json:"drifted"`
+	Reasons        []string      `json:"reasons,omitempty"`
+}
+
+// Calibrator keeps each bridge's recent realized transfers by route and
+// size, estimating fees and fill times from them in place of the static
+// FeeRangeBps and times in BridgeConfig, and flags bridges whose realized
+// costs have drifted from the configured ones. Transfers are appended to a
+// store so calibration survives restarts. A nil calibrator estimates
+// nothing. It is safe for concurrent use.
+type Calibrator struct {
+	bridges map[string]*config.BridgeConfig
+	cfg     config.BridgeCalibrationConfig
+	store   *journal.Journal
+
+	// OnChange, when set, is called when a bridge starts or stops drifting
+	OnChange func(d Drift)
+
+	mu        sync.Mutex
+	transfers map[string][]Transfer // by bridge, route and size
+	drifted   map[string]bool
+
+	fee  *metrics.GaugeVec
+	fill *metrics.GaugeVec
+}
+
+// NewCalibrator creates a calibrator for the configured bridges; store and
+// reg may be nil
+func NewCalibrator(bridges map[string]*config.BridgeConfig, cfg *config.BridgeCalibrationConfig, store *journal.Journal, reg *metrics.Registry) *Calibrator {
+	c := &Calibrator{
+		bridges:   bridges,
+		cfg:       *cfg,
+		store:     store,
+		transfers: make(map[string][]Transfer),
+		drifted:   make(map[string]bool),
+	}
+	if reg != nil {
+		c.fee = reg.Gauge("titan_bridge_realized_fee_bps", "Median realized bridge fee in bps", "bridge")
+		c.fill = reg.Gauge("titan_bridge_realized_fill_seconds", "Median realized bridge fill time", "bridge")
+	}
+	return c
+}
+
+// ReadTransfers returns the stored transfers at or after since, oldest
+// first; a missing store has none
+func ReadTransfers(path string, keys *seal.Keyring, since time.Time) ([]Transfer, error) {
+	entries, err := journal.ReadSealed(path, journal.KindBridge, keys)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var out []Transfer
+	for _, e := range entries {
+		var t Transfer
+		if err := json.Unmarshal(e.Data, &t); err != nil {
+			return nil, fmt.Errorf("bad bridge entry at %s: %w", e.Time, err)
+		}
+		if !t.At.Before(since) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// Load replays stored transfers without appending them again
+func (c *Calibrator) Load(transfers []Transfer) {
+	if c == nil {
+		return
+	}
+	for _, t := range transfers {
+		c.add(t)
+	}
+	c.mu.Lock()
+	var drifts []Drift
+	for bridge := range c.bridgeSet() {
+		d := c.drift(bridge)
+		c.drifted[bridge] = d.Drifted
+		drifts = append(drifts, d)
+	}
+	c.mu.Unlock()
+	for _, d := range drifts {
+		c.observe(d)
+	}
+}
+
+// Record stores a realized transfer and re-checks its bridge for drift
+func (c *Calibrator) Record(t Transfer) error {
+	if c == nil {
+		return nil
+	}
+	if c.store != nil {
+		if err := c.store.Append(journal.KindBridge, t); err != nil {
+			return err
+		}
+	}
+	c.add(t)
+
+	c.mu.Lock()
+	d := c.drift(t.Bridge)
+	prev, seen := c.drifted[t.Bridge]
+	c.drifted[t.Bridge] = d.Drifted
+	c.mu.Unlock()
+
+	c.observe(d)
+	if prev != d.Drifted && (seen || d.Drifted) {
+		if d.Drifted {
+			log.Printf("🌉 Bridge %s costs drifted from config: %s", t.Bridge, strings.Join(d.Reasons, "; "))
+		} else {
+			log.Printf("🌉 Bridge %s costs back within config", t.Bridge)
+		}
+		if c.OnChange != nil {
+			c.OnChange(d)
+		}
+	}
+	return nil
+}
+
+// observe sets d's bridge gauges
+func (c *Calibrator) observe(d Drift) {
+	if c.fee != nil && d.Transfers > 0 {
+		c.fee.Set(d.FeeBps, d.Bridge)
+		c.fill.Set(d.Fill.Seconds(), d.Bridge)
+	}
+}
+
+// add keeps t among the window of its bridge, route and size
+func (c *Calibrator) add(t Transfer) {
+	key := calibrationKey(t.Bridge, t.FromChain, t.ToChain, SizeBucket(t.SizeUSD))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	kept := append(c.transfers[key], t)
+	if n := len(kept) - int(c.cfg.Window); n > 0 {
+		kept = kept[n:]
+	}
+	c.transfers[key] = kept
+}
+
+func calibrationKey(bridge string, from, to uint64, size string) string {
+	return fmt.Sprintf("%s %d %d %s", bridge, from, to, size)
+}
+
+// Estimate returns a bridge's realized cost on a route for transfers worth
+// size: from that size bucket when it has enough transfers, else from every
+// size on the route. ok is false until the route has enough.
+func (c *Calibrator) Estimate(bridge string, from, to uint64, size units.USD) (Estimate, bool) {
+	if c == nil {
+		return Estimate{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if bucket := SizeBucket(size); bucket != "" {
+		if e, ok := c.estimate(c.transfers[calibrationKey(bridge, from, to, bucket)]); ok {
+			e.Size = bucket
+			return e, true
+		}
+	}
+	prefix := calibrationKey(bridge, from, to, "")
+	var route []Transfer
+	for key, transfers := range c.transfers {
+		if strings.HasPrefix(key, prefix) {
+			route = append(route, transfers...)
+		}
+	}
+	return c.estimate(route)
+}
+
+// Estimates lists every bridge, route and size bucket with enough
+// transfers to estimate from
+func (c *Calibrator) Estimates() []Estimate {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []Estimate
+	for _, transfers := range c.transfers {
+		if e, ok := c.estimate(transfers); ok {
+			e.Size = SizeBucket(transfers[0].SizeUSD)
+			out = append(out, e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Bridge != b.Bridge {
+			return a.Bridge < b.Bridge
+		}
+		if a.FromChain != b.FromChain {
+			return a.FromChain < b.FromChain
+		}
+		if a.ToChain != b.ToChain {
+			return a.ToChain < b.ToChain
+		}
+		return a.Size < b.Size
+	})
+	return out
+}
+
+// Recent returns the realized transfers kept for bridge over every route
+// and size, oldest first
+func (c *Calibrator) Recent(bridge string) []Transfer {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []Transfer
+	for _, transfers := range c.transfers {
+		if len(transfers) > 0 && transfers[0].Bridge == bridge {
+			out = append(out, transfers...)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out
+}
+
+// estimate summarises transfers of one bridge and route
+func (c *Calibrator) estimate(transfers []Transfer) (Estimate, bool) {
+	if len(transfers) == 0 || uint64(len(transfers)) < c.cfg.MinTransfers {
+		return Estimate{}, false
+	}
+	fees, fills := samples(transfers)
+	return Estimate{
+		Bridge:      transfers[0].Bridge,
+		FromChain:   transfers[0].FromChain,
+		ToChain:     transfers[0].ToChain,
+		Transfers:   len(transfers),
+		FeeBps:      quantile(fees, 0.5),
+		FeeRangeBps: [2]float64{quantile(fees, 0.1), quantile(fees, 0.9)},
+		Fill:        time.Duration(quantile(fills, 0.5)),
+		MaxFill:     time.Duration(quantile(fills, 0.9)),
+	}, true
+}
+
+// samples returns transfers' fees and fill times, each sorted
+func samples(transfers []Transfer) (fees, fills []float64) {
+	for _, t := range transfers {
+		fees = append(fees, t.FeeBps)
+		fills = append(fills, float64(t.Fill))
+	}
+	sort.Float64s(fees)
+	sort.Float64s(fills)
+	return fees, fills
+}
+
+// quantile is the nearest-rank q-th value of sorted
+func quantile(sorted []float64, q float64) float64 {
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+// Drifts compares every bridge with realized transfers against its
+// configuration, by bridge
+func (c *Calibrator) Drifts() []Drift {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []Drift
+	for bridge := range c.bridgeSet() {
+		out = append(out, c.drift(bridge))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Bridge < out[j].Bridge })
+	return out
+}
+
+// bridgeSet lists the bridges with realized transfers; c.mu must be held
+func (c *Calibrator) bridgeSet() map[string]bool {
+	set := make(map[string]bool)
+	for _, transfers := range c.transfers {
+		if len(transfers) > 0 {
+			set[transfers[0].Bridge] = true
+		}
+	}
+	return set
+}
+
+// drift compares bridge's realized median fee and fill time over every
+// route with its configured range and maximum time; c.mu must be held
+func (c *Calibrator) drift(bridge string) Drift {
+	var all []Transfer
+	for _, transfers := range c.transfers {
+		if len(transfers) > 0 && transfers[0].Bridge == bridge {
+			all = append(all, transfers...)
+		}
+	}
+	d := Drift{Bridge: bridge, Transfers: len(all)}
+	b, configured := c.bridges[bridge]
+	if configured {
+		d.ConfiguredBps = b.FeeRangeBps
+		d.ConfiguredFill = time.Duration(b.TypicalTimeSeconds) * time.Second
+		d.ConfiguredMax = time.Duration(b.MaxTimeSeconds) * time.Second
+	}
+	if len(all) == 0 {
+		return d
+	}
+	fees, fills := samples(all)
+	d.FeeBps, d.Fill = quantile(fees, 0.5), time.Duration(quantile(fills, 0.5))
+	if !configured || uint64(len(all)) < c.cfg.MinTransfers {
+		return d
+	}
+	tolerance := float64(c.cfg.DriftToleranceBps)
+	if len(d.ConfiguredBps) == 2 {
+		if lo := float64(d.ConfiguredBps[0]); d.FeeBps < lo-tolerance {
+			d.Reasons = append(d.Reasons, fmt.Sprintf("fee %.1f bps below the configured %d-%d", d.FeeBps, d.ConfiguredBps[0], d.ConfiguredBps[1]))
+		}
+		if hi := float64(d.ConfiguredBps[1]); d.FeeBps > hi+tolerance {
+			d.Reasons = append(d.Reasons, fmt.Sprintf("fee %.1f bps above the configured %d-%d", d.FeeBps, d.ConfiguredBps[0], d.ConfiguredBps[1]))
+		}
+	}
+	if d.ConfiguredMax > 0 && d.Fill > d.ConfiguredMax {
+		d.Reasons = append(d.Reasons, fmt.Sprintf("fills in %s, past the configured %s maximum", d.Fill, d.ConfiguredMax))
+	}
+	d.Drifted = len(d.Reasons) > 0
+	return d
+}
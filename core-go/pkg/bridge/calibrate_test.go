@@ -0,0 +1,130 @@
+package bridge
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+func TestSizeBucket(t *testing.T) {
+	for usd, want := range map[units.USD]string{
+		0:                           "",
+		units.DollarsToUSD(500):     "$0-$1k",
+		units.DollarsToUSD(1_000):   "$1k-$10k",
+		units.DollarsToUSD(99_999):  "$10k-$100k",
+		units.DollarsToUSD(100_000): "$100k+",
+	} {
+		if got := SizeBucket(usd); got != want {
+			t.Errorf("Expected %s in %q, got %q", usd, want, got)
+		}
+	}
+}
+
+func TestRealizedFromLandedPlan(t *testing.T) {
+	req := testRequest(t)
+	tracker := NewTracker(PolicyConfirmFirst, nil)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+	p := testPlan(t, "p1", "", 10_000)
+	p.Transfer = req
+	if err := tracker.Open(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Submitted("p1", Source, common.Hash{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Filled("p1", Source); err != nil {
+		t.Fatal(err)
+	}
+	p, _ = tracker.Plan("p1")
+	received, _ := units.FromWhole(req.ToToken.Address, 9_990, req.ToToken.Decimals)
+	if _, err := Realized(p, received); err == nil {
+		t.Errorf("Expected a transfer in flight not measured")
+	}
+
+	now = now.Add(90 * time.Second)
+	if err := tracker.Arrived("p1"); err != nil {
+		t.Fatal(err)
+	}
+	p, _ = tracker.Plan("p1")
+	transfer, err := Realized(p, received)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transfer.FeeBps != 10 || transfer.Fill != 90*time.Second || transfer.SizeUSD != units.DollarsToUSD(10_000) {
+		t.Errorf("Expected 10 bps over 90s on $10k, got %+v", transfer)
+	}
+}
+
+func TestCalibratorEstimatesAndFlagsDrift(t *testing.T) {
+	store, err := journal.Open(filepath.Join(t.TempDir(), "bridges.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	bridges := map[string]*config.BridgeConfig{
+		"across": {TypicalTimeSeconds: 30, MaxTimeSeconds: 180, FeeRangeBps: []uint32{20, 40}},
+	}
+	cfg := &config.BridgeCalibrationConfig{MinTransfers: 3, Window: 4, DriftToleranceBps: 5}
+	reg := metrics.NewRegistry()
+	c := NewCalibrator(bridges, cfg, store, reg)
+	var changes []Drift
+	c.OnChange = func(d Drift) { changes = append(changes, d) }
+
+	at := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, fee := range []float64{30, 12, 8, 10, 9} {
+		err := c.Record(Transfer{Bridge: "across", FromChain: 137, ToChain: 56, SizeUSD: units.DollarsToUSD(5_000),
+			FeeBps: fee, Fill: time.Minute, At: at.Add(time.Duration(i) * time.Minute)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The window keeps the last four transfers: 12, 8, 10 and 9 bps
+	e, ok := c.Estimate("across", 137, 56, units.DollarsToUSD(2_000))
+	if !ok || e.Size != "$1k-$10k" || e.Transfers != 4 || e.FeeBps != 9 || e.FeeRangeBps != [2]float64{8, 12} {
+		t.Errorf("Expected the $1k-$10k bucket at 9 bps, got %+v, %v", e, ok)
+	}
+	if e, ok := c.Estimate("across", 137, 56, units.DollarsToUSD(50_000)); !ok || e.Size != "" {
+		t.Errorf("Expected the whole route for an uncalibrated size, got %+v, %v", e, ok)
+	}
+	if _, ok := c.Estimate("across", 56, 137, 0); ok {
+		t.Errorf("Expected no estimate for the reverse route")
+	}
+	if len(changes) != 1 || !changes[0].Drifted || len(changes[0].Reasons) != 1 {
+		t.Errorf("Expected one drift below the configured range, got %+v", changes)
+	}
+	if got := reg.Value("titan_bridge_realized_fee_bps", "across"); got != 9 {
+		t.Errorf("Expected the fee gauge at 9, got %v", got)
+	}
+
+	adapter := NewConfigAdapter("across", bridges["across"])
+	adapter.Calibration = c
+	q, err := adapter.Quote(context.Background(), testRequest(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Source != SourceRealized || q.FeeBps != 9 || q.ETA != time.Minute {
+		t.Errorf("Expected the realized fee and fill quoted, got %+v", q)
+	}
+
+	transfers, err := ReadTransfers(store.Path(), nil, at.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transfers) != 4 {
+		t.Fatalf("Expected 4 stored transfers since the first, got %d", len(transfers))
+	}
+	reloaded := NewCalibrator(bridges, cfg, nil, nil)
+	reloaded.Load(transfers)
+	if d := reloaded.Drifts(); len(d) != 1 || !d[0].Drifted || d[0].FeeBps != 9 {
+		t.Errorf("Expected the drift found again after a restart, got %+v", d)
+	}
+}
@@ -0,0 +1,236 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/execution"
+)
+
+// cctpABI is the slice of CCTP's TokenMessenger and MessageTransmitter used
+// to burn and mint
+const cctpABI = `[
+{"inputs":[{"name":"amount","type":"uint256"},{"name":"destinationDomain","type":"uint32"},{"name":"mintRecipient","type":"bytes32"},{"name":"burnToken","type":"address"}],"name":"depositForBurn","outputs":[{"name":"nonce","type":"uint64"}],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"name":"message","type":"bytes"},{"name":"attestation","type":"bytes"}],"name":"receiveMessage","outputs":[{"name":"success","type":"bool"}],"stateMutability":"nonpayable","type":"function"},
+{"anonymous":false,"inputs":[{"indexed":false,"name":"message","type":"bytes"}],"name":"MessageSent","type":"event"}
+]`
+
+var parsedCCTPABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(cctpABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// cctpChain is a chain's CCTP domain, native USDC and contracts
+type cctpChain struct {
+	domain             uint32
+	usdc               common.Address
+	tokenMessenger     common.Address
+	messageTransmitter common.Address
+}
+
+// cctpChains are the chains CCTP burns and mints native USDC on
+var cctpChains = map[uint64]cctpChain{
+	1: {0, common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"),
+		common.HexToAddress("0xBd3fa81B58Ba92a82136038B25aDec7066af3155"), common.HexToAddress("0x0a992d191DEeC32aFe36203Ad87D7d289a738F81")},
+	43114: {1, common.HexToAddress("0xB97EF9Ef8734C71904D8002F8b6Bc66Dd9c48a6E"),
+		common.HexToAddress("0x6B25532e1060CE10cc3B0A99e5683b91BFDe6982"), common.HexToAddress("0x8186359aF5F57FbB40c6b14A588d2A59C0C29880")},
+	10: {2, common.HexToAddress("0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85"),
+		common.HexToAddress("0x2B4069517957735bE00ceE0fadAE88a26365528f"), common.HexToAddress("0x4D41f22c5a0e5c74090899E5a8Fb597a8842b3e8")},
+	42161: {3, common.HexToAddress("0xaf88d065e77c8cC2239327C5EDb3A432268e5831"),
+		common.HexToAddress("0x19330d10D9Cc8751218eaf51E8885D058642E08A"), common.HexToAddress("0xC30362313FBBA5cf9163F0bb16a0e01f01A896ca")},
+	8453: {6, common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"),
+		common.HexToAddress("0x1682Ae6375C4E4A97e4B583BC394c861A46D8962"), common.HexToAddress("0xAD09780d193884d503182aD4588450C416D6F9D4")},
+	137: {7, common.HexToAddress("0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359"),
+		common.HexToAddress("0x9daF8c91AEFAE50b9c0E69629D3F6Ca40cA3B3FE"), common.HexToAddress("0xF3be9355363857F3e001be68856A2f96b4C39Ba9")},
+}
+
+// CCTPAdapter moves native USDC over Circle's Cross-Chain Transfer
+// Protocol: the source burns it through TokenMessenger's depositForBurn,
+// Circle attests the burn message once the source is final, and
+// MessageTransmitter's receiveMessage mints it on the destination. The
+// protocol charges no fee, so the amount sent is the amount received.
+type CCTPAdapter struct {
+	attestationURL string
+	poll           time.Duration
+	bridge         *config.BridgeConfig
+	client         *http.Client
+}
+
+// NewCCTPAdapter creates a CCTP adapter quoting ETAs from bridge's
+// configured times; bridge may be nil
+func NewCCTPAdapter(cfg *config.CCTPConfig, bridge *config.BridgeConfig) *CCTPAdapter {
+	if bridge == nil {
+		bridge = &config.BridgeConfig{}
+	}
+	return &CCTPAdapter{
+		attestationURL: strings.TrimRight(cfg.AttestationURL, "/"),
+		poll:           time.Duration(cfg.PollSecs) * time.Second,
+		bridge:         bridge,
+		client:         &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name returns "cctp"
+func (a *CCTPAdapter) Name() string {
+	return "cctp"
+}
+
+// route returns the CCTP chains for req, which must move native USDC
+func (a *CCTPAdapter) route(req Request) (from, to cctpChain, err error) {
+	from, ok := cctpChains[req.FromChain]
+	if !ok {
+		return from, to, fmt.Errorf("cctp does not serve chain %d", req.FromChain)
+	}
+	to, ok = cctpChains[req.ToChain]
+	if !ok {
+		return from, to, fmt.Errorf("cctp does not serve chain %d", req.ToChain)
+	}
+	if req.FromToken.Address != from.usdc || req.ToToken.Address != to.usdc {
+		return from, to, fmt.Errorf("cctp only moves native USDC, not %s to %s", req.FromToken.Symbol, req.ToToken.Symbol)
+	}
+	return from, to, nil
+}
+
+// Quote delivers the full amount after the configured times
+func (a *CCTPAdapter) Quote(ctx context.Context, req Request) (*Quote, error) {
+	if _, _, err := a.route(req); err != nil {
+		return nil, err
+	}
+	out, err := req.Amount.Rescale(req.ToToken.Address, req.ToToken.Decimals)
+	if err != nil {
+		return nil, err
+	}
+	return &Quote{
+		Bridge:    a.Name(),
+		AmountOut: out,
+		ETA:       time.Duration(a.bridge.TypicalTimeSeconds) * time.Second,
+		MaxETA:    time.Duration(a.bridge.MaxTimeSeconds) * time.Second,
+		Source:    SourceConfig,
+	}, nil
+}
+
+// Build constructs the source chain call burning req's USDC for the
+// sender's address on the destination; TokenMessenger must be approved for
+// the amount first
+func (a *CCTPAdapter) Build(req Request) (execution.Call, error) {
+	from, to, err := a.route(req)
+	if err != nil {
+		return execution.Call{}, err
+	}
+	data, err := parsedCCTPABI.Pack("depositForBurn", req.Amount.Big(), to.domain, common.BytesToHash(req.Sender.Bytes()), from.usdc)
+	if err != nil {
+		return execution.Call{}, err
+	}
+	return execution.Call{ChainID: req.FromChain, From: req.Sender, To: from.tokenMessenger, Data: data}, nil
+}
+
+// Message returns the burn message a depositForBurn transaction on chainID
+// emitted, which Circle attests and the destination mints against
+func (a *CCTPAdapter) Message(chainID uint64, receipt *types.Receipt) ([]byte, error) {
+	chain, ok := cctpChains[chainID]
+	if !ok {
+		return nil, fmt.Errorf("cctp does not serve chain %d", chainID)
+	}
+	event := parsedCCTPABI.Events["MessageSent"]
+	for _, l := range receipt.Logs {
+		if l.Address != chain.messageTransmitter || len(l.Topics) == 0 || l.Topics[0] != event.ID {
+			continue
+		}
+		values, err := event.Inputs.Unpack(l.Data)
+		if err != nil {
+			return nil, fmt.Errorf("cctp message: %w", err)
+		}
+		return values[0].([]byte), nil
+	}
+	return nil, fmt.Errorf("cctp: no burn message in %s", receipt.TxHash.Hex())
+}
+
+// Attestation asks Circle for message's attestation; it is nil until the
+// source chain is final and Circle has signed the burn
+func (a *CCTPAdapter) Attestation(ctx context.Context, message []byte) ([]byte, error) {
+	u := fmt.Sprintf("%s/attestations/%s", a.attestationURL, crypto.Keccak256Hash(message).Hex())
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("cctp attestation failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	// Circle answers 404 until it has seen the burn
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cctp attestation failed: HTTP %d: %s", resp.StatusCode, truncate(body, 200))
+	}
+	var answer struct {
+		Status      string `json:"status"`
+		Attestation string `json:"attestation"`
+	}
+	if err := json.Unmarshal(body, &answer); err != nil {
+		return nil, fmt.Errorf("cctp attestation: invalid response: %w", err)
+	}
+	// pending_confirmations until the source chain is final
+	if answer.Status != "complete" {
+		return nil, nil
+	}
+	attestation, err := hexutil.Decode(answer.Attestation)
+	if err != nil {
+		return nil, fmt.Errorf("cctp attestation: %w", err)
+	}
+	return attestation, nil
+}
+
+// Redeem waits for the attestation of the burn in receipt and constructs
+// the destination call minting it. The transfer has arrived once that call
+// succeeds.
+func (a *CCTPAdapter) Redeem(ctx context.Context, req Request, receipt *types.Receipt) (execution.Call, error) {
+	_, to, err := a.route(req)
+	if err != nil {
+		return execution.Call{}, err
+	}
+	message, err := a.Message(req.FromChain, receipt)
+	if err != nil {
+		return execution.Call{}, err
+	}
+	ticker := time.NewTicker(a.poll)
+	defer ticker.Stop()
+	for {
+		attestation, err := a.Attestation(ctx, message)
+		if err == nil && attestation != nil {
+			data, err := parsedCCTPABI.Pack("receiveMessage", message, attestation)
+			if err != nil {
+				return execution.Call{}, err
+			}
+			return execution.Call{ChainID: req.ToChain, From: req.Sender, To: to.messageTransmitter, Data: data}, nil
+		}
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				err = ctx.Err()
+			}
+			return execution.Call{}, fmt.Errorf("cctp burn %s not attested: %w", receipt.TxHash.Hex(), err)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+)
+
+func testCCTP(url string) *CCTPAdapter {
+	a := NewCCTPAdapter(&config.CCTPConfig{AttestationURL: url, PollSecs: 1}, &config.BridgeConfig{TypicalTimeSeconds: 780, MaxTimeSeconds: 1200})
+	a.poll = 10 * time.Millisecond
+	return a
+}
+
+func TestCCTPQuoteMovesNativeUSDCOnly(t *testing.T) {
+	a := testCCTP("")
+	req := hopRequest(t, 137, 42161)
+	q, err := a.Quote(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.AmountOut.Cmp(req.Amount) != 0 || q.FeeBps != 0 || q.ETA != 13*time.Minute {
+		t.Errorf("Expected the full amount in 13m at no fee, got %+v", q)
+	}
+
+	req.FromToken, _ = tokens.Default().Lookup(137, "USDC.E")
+	if _, err := a.Quote(context.Background(), req); err == nil {
+		t.Errorf("Expected bridged USDC.e refused")
+	}
+	if _, err := a.Quote(context.Background(), hopRequest(t, 137, 56)); err == nil {
+		t.Errorf("Expected a chain CCTP doesn't serve refused")
+	}
+}
+
+func TestCCTPBurnAttestAndMint(t *testing.T) {
+	message := []byte("burn message")
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/attestations/"+crypto.Keccak256Hash(message).Hex() {
+			http.Error(w, "unknown message", http.StatusBadRequest)
+			return
+		}
+		switch polls.Add(1) {
+		case 1:
+			http.NotFound(w, r)
+		case 2:
+			w.Write([]byte(`{"status":"pending_confirmations","attestation":"PENDING"}`))
+		default:
+			w.Write([]byte(`{"status":"complete","attestation":"0xa77e57"}`))
+		}
+	}))
+	defer srv.Close()
+	a := testCCTP(srv.URL)
+	req := hopRequest(t, 137, 42161)
+
+	burn, err := a.Build(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := parsedCCTPABI.Methods["depositForBurn"]
+	if burn.To != cctpChains[137].tokenMessenger || !bytes.Equal(burn.Data[:4], method.ID) {
+		t.Fatalf("Expected depositForBurn on polygon's TokenMessenger, got %+v", burn)
+	}
+	args, err := method.Inputs.Unpack(burn.Data[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipient := args[2].([32]byte)
+	if args[0].(*big.Int).Cmp(req.Amount.Big()) != 0 || args[1].(uint32) != 3 || common.BytesToAddress(recipient[:]) != req.Sender {
+		t.Errorf("Expected the amount burned for the sender on domain 3, got %v", args)
+	}
+
+	event := parsedCCTPABI.Events["MessageSent"]
+	data, err := event.Inputs.Pack(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt := &types.Receipt{Logs: []*types.Log{
+		{Address: common.HexToAddress("0x01"), Topics: []common.Hash{event.ID}, Data: data},
+		{Address: cctpChains[137].messageTransmitter, Topics: []common.Hash{event.ID}, Data: data},
+	}}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	mint, err := a.Redeem(ctx, req, receipt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := parsedCCTPABI.Pack("receiveMessage", message, hexutil.MustDecode("0xa77e57"))
+	if mint.ChainID != 42161 || mint.To != cctpChains[42161].messageTransmitter || !bytes.Equal(mint.Data, want) {
+		t.Errorf("Expected receiveMessage with the attestation on arbitrum, got %+v", mint)
+	}
+	if polls.Load() != 3 {
+		t.Errorf("Expected the attestation polled until complete, got %d polls", polls.Load())
+	}
+
+	if _, err := a.Redeem(ctx, req, &types.Receipt{}); err == nil || !strings.Contains(err.Error(), "no burn message") {
+		t.Errorf("Expected a receipt without a burn refused, got %v", err)
+	}
+}
+
+func TestFromConfigRegistersLiveAdapters(t *testing.T) {
+	cfg, _ := config.LoadFromEnv()
+	names := map[string]bool{}
+	for _, a := range FromConfig(cfg, nil).Adapters() {
+		names[a.Name()] = true
+		if _, estimated := a.(*ConfigAdapter); estimated == (a.Name() == "hop" || a.Name() == "cctp") {
+			t.Errorf("Expected %s estimated only when it has no live adapter, got %T", a.Name(), a)
+		}
+	}
+	if len(names) != 4 || !names["cctp"] {
+		t.Errorf("Expected the four configured bridges, got %v", names)
+	}
+}
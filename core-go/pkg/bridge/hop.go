@@ -0,0 +1,327 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/execution"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// hopABI is the slice of Hop's L1 bridge and L2 AMM wrapper used to send
+const hopABI = `[
+{"inputs":[{"name":"chainId","type":"uint256"},{"name":"recipient","type":"address"},{"name":"amount","type":"uint256"},{"name":"amountOutMin","type":"uint256"},{"name":"deadline","type":"uint256"},{"name":"relayer","type":"address"},{"name":"relayerFee","type":"uint256"}],"name":"sendToL2","outputs":[],"stateMutability":"payable","type":"function"},
+{"inputs":[{"name":"chainId","type":"uint256"},{"name":"recipient","type":"address"},{"name":"amount","type":"uint256"},{"name":"bonderFee","type":"uint256"},{"name":"amountOutMin","type":"uint256"},{"name":"deadline","type":"uint256"},{"name":"destinationAmountOutMin","type":"uint256"},{"name":"destinationDeadline","type":"uint256"}],"name":"swapAndSend","outputs":[],"stateMutability":"payable","type":"function"}
+]`
+
+var parsedHopABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(hopABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// hopChains are the chains Hop serves, by the slugs its API uses
+var hopChains = map[uint64]string{
+	1:     "ethereum",
+	10:    "optimism",
+	137:   "polygon",
+	8453:  "base",
+	42161: "arbitrum",
+	59144: "linea",
+}
+
+// hopToken is the Hop asset symbol for a token: the native token is ETH and
+// bridged variants such as USDC.e share their asset's pool
+func hopToken(symbol string, native bool) string {
+	if native {
+		return "ETH"
+	}
+	symbol = strings.ToUpper(symbol)
+	if base, _, ok := strings.Cut(symbol, "."); ok {
+		return base
+	}
+	return symbol
+}
+
+// HopQuote is Hop's answer to a quote request, amounts in the source
+// token's raw units and deadlines in unix seconds
+type HopQuote struct {
+	AmountIn                string `json:"amountIn"`
+	AmountOutMin            string `json:"amountOutMin"`
+	DestinationAmountOutMin string `json:"destinationAmountOutMin"`
+	BonderFee               string `json:"bonderFee"`
+	EstimatedReceived       string `json:"estimatedReceived"`
+	Deadline                int64  `json:"deadline"`
+	DestinationDeadline     int64  `json:"destinationDeadline"`
+}
+
+// HopTransfer is a sent transfer's status on Hop
+type HopTransfer struct {
+	TransferID          string `json:"transferId"`
+	Bonded              bool   `json:"bonded"`
+	BondTransactionHash string `json:"bondTransactionHash"`
+	BondedTimestamp     int64  `json:"bondedTimestamp"`
+}
+
+// hopContracts are one chain's Hop contracts for an asset
+type hopContracts struct {
+	L1Bridge     string `json:"l1Bridge"`
+	L2AmmWrapper string `json:"l2AmmWrapper"`
+}
+
+// HopAdapter quotes, builds and tracks transfers over Hop Protocol. Quotes
+// come from Hop's API; transfers leave Ethereum through the L1 bridge's
+// sendToL2 and L2s through the AMM wrapper's swapAndSend, and land once a
+// bonder fronts them on the destination.
+type HopAdapter struct {
+	baseURL      string
+	addressesURL string
+	slippageBps  uint64
+	bridge       *config.BridgeConfig
+	client       *http.Client
+
+	mu        sync.Mutex
+	contracts map[string]map[string]hopContracts // by asset and chain slug
+}
+
+// NewHopAdapter creates a Hop adapter quoting ETAs from bridge's configured
+// times; bridge may be nil
+func NewHopAdapter(cfg *config.HopConfig, bridge *config.BridgeConfig) *HopAdapter {
+	if bridge == nil {
+		bridge = &config.BridgeConfig{}
+	}
+	return &HopAdapter{
+		baseURL:      strings.TrimRight(cfg.APIURL, "/"),
+		addressesURL: cfg.AddressesURL,
+		slippageBps:  cfg.SlippageBps,
+		bridge:       bridge,
+		client:       &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name returns "hop"
+func (a *HopAdapter) Name() string {
+	return "hop"
+}
+
+// route returns Hop's slugs for req's chains and its asset
+func (a *HopAdapter) route(req Request) (from, to, asset string, err error) {
+	from, ok := hopChains[req.FromChain]
+	if !ok {
+		return "", "", "", fmt.Errorf("hop does not serve chain %d", req.FromChain)
+	}
+	to, ok = hopChains[req.ToChain]
+	if !ok {
+		return "", "", "", fmt.Errorf("hop does not serve chain %d", req.ToChain)
+	}
+	return from, to, hopToken(req.FromToken.Symbol, req.FromToken.Address == (common.Address{})), nil
+}
+
+// HopQuote requests GET /v1/quote for the transfer
+func (a *HopAdapter) HopQuote(ctx context.Context, req Request) (*HopQuote, error) {
+	from, to, asset, err := a.route(req)
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	q.Set("amount", req.Amount.Value.Dec())
+	q.Set("token", asset)
+	q.Set("fromChain", from)
+	q.Set("toChain", to)
+	q.Set("slippage", strconv.FormatFloat(float64(a.slippageBps)/100, 'f', -1, 64))
+	q.Set("network", "mainnet")
+
+	var hq HopQuote
+	if err := a.get(ctx, "/v1/quote?"+q.Encode(), &hq); err != nil {
+		return nil, fmt.Errorf("hop quote failed: %w", err)
+	}
+	return &hq, nil
+}
+
+// Quote prices the transfer at Hop's estimated amount received
+func (a *HopAdapter) Quote(ctx context.Context, req Request) (*Quote, error) {
+	hq, err := a.HopQuote(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	received, ok := new(big.Int).SetString(hq.EstimatedReceived, 10)
+	if !ok {
+		return nil, fmt.Errorf("hop quote: invalid estimatedReceived %q", hq.EstimatedReceived)
+	}
+	sent, err := units.FromBig(req.FromToken.Address, received, req.Amount.Decimals)
+	if err != nil {
+		return nil, err
+	}
+	out, err := sent.Rescale(req.ToToken.Address, req.ToToken.Decimals)
+	if err != nil {
+		return nil, err
+	}
+	return &Quote{
+		Bridge:    a.Name(),
+		AmountOut: out,
+		FeeBps:    impliedFeeBps(req.Amount, out),
+		ETA:       time.Duration(a.bridge.TypicalTimeSeconds) * time.Second,
+		MaxETA:    time.Duration(a.bridge.MaxTimeSeconds) * time.Second,
+		Source:    SourceAPI,
+	}, nil
+}
+
+// Build constructs the source chain call sending req as hq quoted it, to
+// the sender's address on the destination. ERC20 transfers need the
+// contract approved for the amount first; ETH is sent as value.
+func (a *HopAdapter) Build(ctx context.Context, req Request, hq *HopQuote) (execution.Call, error) {
+	from, _, asset, err := a.route(req)
+	if err != nil {
+		return execution.Call{}, err
+	}
+	contracts, err := a.contractsFor(ctx, asset, from)
+	if err != nil {
+		return execution.Call{}, err
+	}
+	amount := req.Amount.Big()
+	amountOutMin, err := hopAmount(hq.AmountOutMin)
+	if err != nil {
+		return execution.Call{}, err
+	}
+	destinationChain := new(big.Int).SetUint64(req.ToChain)
+
+	call := execution.Call{ChainID: req.FromChain, From: req.Sender}
+	if from == "ethereum" {
+		if contracts.L1Bridge == "" {
+			return execution.Call{}, fmt.Errorf("hop has no %s L1 bridge", asset)
+		}
+		call.To = common.HexToAddress(contracts.L1Bridge)
+		call.Data, err = parsedHopABI.Pack("sendToL2", destinationChain, req.Sender, amount, amountOutMin,
+			big.NewInt(hq.Deadline), common.Address{}, new(big.Int))
+	} else {
+		if contracts.L2AmmWrapper == "" {
+			return execution.Call{}, fmt.Errorf("hop has no %s AMM wrapper on %s", asset, from)
+		}
+		var bonderFee, destinationAmountOutMin *big.Int
+		if bonderFee, err = hopAmount(hq.BonderFee); err != nil {
+			return execution.Call{}, err
+		}
+		if destinationAmountOutMin, err = hopAmount(hq.DestinationAmountOutMin); err != nil {
+			return execution.Call{}, err
+		}
+		call.To = common.HexToAddress(contracts.L2AmmWrapper)
+		call.Data, err = parsedHopABI.Pack("swapAndSend", destinationChain, req.Sender, amount, bonderFee, amountOutMin,
+			big.NewInt(hq.Deadline), destinationAmountOutMin, big.NewInt(hq.DestinationDeadline))
+	}
+	if err != nil {
+		return execution.Call{}, err
+	}
+	if asset == "ETH" {
+		call.Value = amount
+	}
+	return call, nil
+}
+
+// hopAmount parses one of a quote's raw amounts; empty is zero
+func hopAmount(s string) (*big.Int, error) {
+	if s == "" {
+		return new(big.Int), nil
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("hop quote: invalid amount %q", s)
+	}
+	return n, nil
+}
+
+// contractsFor returns asset's Hop contracts on a chain, fetching Hop's
+// published addresses once
+func (a *HopAdapter) contractsFor(ctx context.Context, asset, chain string) (hopContracts, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.contracts == nil {
+		var core struct {
+			Bridges map[string]map[string]hopContracts `json:"bridges"`
+		}
+		if err := a.fetch(ctx, a.addressesURL, &core); err != nil {
+			return hopContracts{}, fmt.Errorf("hop addresses: %w", err)
+		}
+		a.contracts = core.Bridges
+	}
+	contracts, ok := a.contracts[asset][chain]
+	if !ok {
+		return hopContracts{}, fmt.Errorf("hop does not bridge %s from %s", asset, chain)
+	}
+	return contracts, nil
+}
+
+// Status requests GET /v1/transfer-status for a transfer sent in tx
+func (a *HopAdapter) Status(ctx context.Context, tx common.Hash) (*HopTransfer, error) {
+	q := url.Values{}
+	q.Set("transactionHash", tx.Hex())
+	q.Set("network", "mainnet")
+	var status HopTransfer
+	if err := a.get(ctx, "/v1/transfer-status?"+q.Encode(), &status); err != nil {
+		return nil, fmt.Errorf("hop transfer status: %w", err)
+	}
+	return &status, nil
+}
+
+// Track polls the transfer sent in tx every interval until a bonder fills
+// it on the destination, then records plan id's arrival with tracker
+func (a *HopAdapter) Track(ctx context.Context, tracker *Tracker, id string, tx common.Hash, interval time.Duration) (*HopTransfer, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status, err := a.Status(ctx, tx)
+		if err == nil && status.Bonded {
+			return status, tracker.Arrived(id)
+		}
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				err = ctx.Err()
+			}
+			return nil, fmt.Errorf("hop transfer %s not bonded: %w", tx.Hex(), err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// get decodes a JSON answer from the Hop API
+func (a *HopAdapter) get(ctx context.Context, path string, out interface{}) error {
+	return a.fetch(ctx, a.baseURL+path, out)
+}
+
+// fetch decodes a JSON document from u
+func (a *HopAdapter) fetch(ctx context.Context, u string, out interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := a.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, truncate(body, 200))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+const (
+	hopL1Bridge = "0x00000000000000000000000000000000000000b1"
+	hopWrapper  = "0x00000000000000000000000000000000000000a2"
+)
+
+// hopServer answers quotes, transfer status (bonded from the second poll)
+// and Hop's core config
+func hopServer(t *testing.T) *httptest.Server {
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/quote":
+			q := r.URL.Query()
+			if q.Get("token") != "USDC" || q.Get("slippage") != "0.5" || q.Get("amount") != "1000000000" {
+				http.Error(w, "bad query "+r.URL.RawQuery, http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"amountIn":"1000000000","amountOutMin":"995000000","destinationAmountOutMin":"994000000",
+				"bonderFee":"1500000","estimatedReceived":"998000000","deadline":1767225600,"destinationDeadline":1767229200}`))
+		case "/v1/transfer-status":
+			bonded := polls.Add(1) > 1
+			w.Write([]byte(`{"transferId":"0xabc","bonded":` + map[bool]string{true: "true", false: "false"}[bonded] + `}`))
+		case "/core.json":
+			w.Write([]byte(`{"bridges":{"USDC":{"ethereum":{"l1Bridge":"` + hopL1Bridge + `"},"polygon":{"l2AmmWrapper":"` + hopWrapper + `"}}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func hopRequest(t *testing.T, from, to uint64) Request {
+	reg := tokens.Default()
+	fromTok, _ := reg.Lookup(from, "USDC")
+	toTok, _ := reg.Lookup(to, "USDC")
+	amount, err := units.FromWhole(fromTok.Address, 1000, fromTok.Decimals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Request{FromChain: from, ToChain: to, FromToken: fromTok, ToToken: toTok, Amount: amount, Sender: common.HexToAddress("0x01")}
+}
+
+func testHop(srv *httptest.Server) *HopAdapter {
+	return NewHopAdapter(&config.HopConfig{APIURL: srv.URL, AddressesURL: srv.URL + "/core.json", SlippageBps: 50},
+		&config.BridgeConfig{TypicalTimeSeconds: 120, MaxTimeSeconds: 600})
+}
+
+func TestHopQuote(t *testing.T) {
+	a := testHop(hopServer(t))
+	q, err := a.Quote(context.Background(), hopRequest(t, 137, 42161))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Bridge != "hop" || q.AmountOut.String() != "998" || q.FeeBps != 20 || q.ETA != 2*time.Minute || q.Source != SourceAPI {
+		t.Errorf("Expected 998 USDC at 20 bps in 2m, got %+v", q)
+	}
+	if _, err := a.Quote(context.Background(), hopRequest(t, 137, 56)); err == nil {
+		t.Errorf("Expected a chain Hop doesn't serve refused")
+	}
+}
+
+func TestHopBuild(t *testing.T) {
+	a := testHop(hopServer(t))
+	req := hopRequest(t, 137, 42161)
+	hq, err := a.HopQuote(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call, err := a.Build(context.Background(), req, hq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := parsedHopABI.Methods["swapAndSend"]
+	if call.To != common.HexToAddress(hopWrapper) || !bytes.Equal(call.Data[:4], method.ID) || call.Value != nil {
+		t.Fatalf("Expected swapAndSend on the AMM wrapper, got %+v", call)
+	}
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args[0].(*big.Int).Uint64() != 42161 || args[1].(common.Address) != req.Sender || args[3].(*big.Int).Uint64() != 1_500_000 ||
+		args[6].(*big.Int).Uint64() != 994_000_000 || args[7].(*big.Int).Int64() != 1767229200 {
+		t.Errorf("Expected the quote's bonder fee, minimums and deadlines sent to arbitrum, got %v", args)
+	}
+
+	req = hopRequest(t, 1, 137)
+	call, err = a.Build(context.Background(), req, hq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if call.To != common.HexToAddress(hopL1Bridge) || !bytes.Equal(call.Data[:4], parsedHopABI.Methods["sendToL2"].ID) {
+		t.Errorf("Expected sendToL2 on the L1 bridge, got %+v", call)
+	}
+	if _, err := a.Build(context.Background(), hopRequest(t, 42161, 137), hq); err == nil {
+		t.Errorf("Expected a chain without published contracts refused")
+	}
+}
+
+func TestHopTrackWaitsForBonder(t *testing.T) {
+	a := testHop(hopServer(t))
+	tracker := NewTracker(PolicyConfirmFirst, nil)
+	if err := tracker.Open(testPlan(t, "p1", "", 1000)); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	status, err := a.Track(ctx, tracker, "p1", common.Hash{1}, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := tracker.Plan("p1"); !status.Bonded || !p.Arrived {
+		t.Errorf("Expected the plan arrived once bonded, got %+v", status)
+	}
+}
@@ -0,0 +1,148 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// DefaultLifiURL is the public LiFi API
+const DefaultLifiURL = "https://li.quest/v1"
+
+// LifiAdapter quotes transfers through the LiFi aggregator, which itself
+// routes over many bridges
+type LifiAdapter struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewLifiAdapter creates a LiFi adapter; apiKey may be empty for public rate limits
+func NewLifiAdapter(baseURL, apiKey string) *LifiAdapter {
+	if baseURL == "" {
+		baseURL = DefaultLifiURL
+	}
+	return &LifiAdapter{
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name returns "lifi"
+func (a *LifiAdapter) Name() string {
+	return "lifi"
+}
+
+type lifiCost struct {
+	AmountUSD string `json:"amountUSD"`
+}
+
+type lifiQuote struct {
+	Tool     string `json:"tool"`
+	Estimate struct {
+		FromAmount        string     `json:"fromAmount"`
+		ToAmount          string     `json:"toAmount"`
+		ExecutionDuration float64    `json:"executionDuration"`
+		FeeCosts          []lifiCost `json:"feeCosts"`
+		GasCosts          []lifiCost `json:"gasCosts"`
+	} `json:"estimate"`
+}
+
+// Quote requests GET /quote for the transfer
+func (a *LifiAdapter) Quote(ctx context.Context, req Request) (*Quote, error) {
+	q := url.Values{}
+	q.Set("fromChain", strconv.FormatUint(req.FromChain, 10))
+	q.Set("toChain", strconv.FormatUint(req.ToChain, 10))
+	q.Set("fromToken", req.FromToken.Address.Hex())
+	q.Set("toToken", req.ToToken.Address.Hex())
+	q.Set("fromAmount", req.Amount.Value.Dec())
+	q.Set("fromAddress", req.Sender.Hex())
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseURL+"/quote?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if a.apiKey != "" {
+		httpReq.Header.Set("x-lifi-api-key", a.apiKey)
+	}
+
+	resp, err := a.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("lifi quote failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("lifi quote failed: HTTP %d: %s", resp.StatusCode, truncate(body, 200))
+	}
+
+	var lq lifiQuote
+	if err := json.Unmarshal(body, &lq); err != nil {
+		return nil, fmt.Errorf("lifi quote: invalid response: %w", err)
+	}
+	toAmount, ok := new(big.Int).SetString(lq.Estimate.ToAmount, 10)
+	if !ok {
+		return nil, fmt.Errorf("lifi quote: invalid toAmount %q", lq.Estimate.ToAmount)
+	}
+	out, err := units.FromBig(req.ToToken.Address, toAmount, req.ToToken.Decimals)
+	if err != nil {
+		return nil, err
+	}
+
+	name := "lifi"
+	if lq.Tool != "" {
+		name = "lifi:" + lq.Tool
+	}
+	eta := time.Duration(lq.Estimate.ExecutionDuration * float64(time.Second))
+	return &Quote{
+		Bridge:    name,
+		AmountOut: out,
+		FeeBps:    impliedFeeBps(req.Amount, out),
+		FeeUSD:    sumUSD(lq.Estimate.FeeCosts) + sumUSD(lq.Estimate.GasCosts),
+		ETA:       eta,
+		MaxETA:    eta,
+		Source:    SourceAPI,
+	}, nil
+}
+
+// impliedFeeBps derives the all-in cost from input vs output, normalising decimals
+func impliedFeeBps(in, out units.Amount) uint32 {
+	normalized, err := out.Rescale(in.Token, in.Decimals)
+	if err != nil || in.IsZero() || normalized.Cmp(in) >= 0 {
+		return 0
+	}
+	diff := new(big.Int).Sub(in.Big(), normalized.Big())
+	diff.Mul(diff, big.NewInt(10000))
+	return uint32(diff.Quo(diff, in.Big()).Uint64())
+}
+
+func sumUSD(costs []lifiCost) units.USD {
+	var total units.USD
+	for _, c := range costs {
+		f, err := strconv.ParseFloat(c.AmountUSD, 64)
+		if err == nil {
+			total += units.USD(f * 1e6)
+		}
+	}
+	return total
+}
+
+func truncate(b []byte, n int) string {
+	if len(b) > n {
+		return string(b[:n]) + "..."
+	}
+	return string(b)
+}
@@ -0,0 +1,400 @@
+package bridge
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Policy decides when each leg of a cross-chain plan may execute
+type Policy string
+
+// Atomicity policies
+const (
+	// PolicyConfirmFirst runs the destination leg only after the source leg
+	// filled and the bridged funds arrived, so a failed source costs nothing
+	// on the destination chain
+	PolicyConfirmFirst Policy = config.CrossChainConfirmFirst
+	// PolicyPrepositioned runs both legs at once, paying for the destination
+	// leg from inventory already there; the bridge transfer rebalances after
+	PolicyPrepositioned Policy = config.CrossChainPrepositioned
+)
+
+// Side names a leg of a cross-chain plan
+type Side int
+
+// Plan legs
+const (
+	Source Side = iota
+	Destination
+)
+
+// String returns the leg name
+func (s Side) String() string {
+	if s == Source {
+		return "source"
+	}
+	return "destination"
+}
+
+// LegState is the execution state of one leg
+type LegState string
+
+// Leg states
+const (
+	LegPending   LegState = "pending"
+	LegSubmitted LegState = "submitted"
+	LegFilled    LegState = "filled"
+	LegFailed    LegState = "failed"
+)
+
+// Plan statuses
+const (
+	PlanOpen     = "open"
+	PlanComplete = "complete" // both legs filled and the transfer arrived
+	PlanAborted  = "aborted"  // a leg failed before the other one ran
+	PlanExposed  = "exposed"  // one leg filled and the other failed
+)
+
+var (
+	// ErrUnknownPlan is returned for a plan ID the tracker has not seen
+	ErrUnknownPlan = errors.New("bridge: unknown plan")
+	// ErrDuplicatePlan is returned when opening a plan ID twice
+	ErrDuplicatePlan = errors.New("bridge: plan already open")
+	// ErrUnknownPolicy is returned for a plan with an unrecognised policy
+	ErrUnknownPolicy = errors.New("bridge: unknown atomicity policy")
+	// ErrInsufficientInventory is returned when a prepositioned plan's
+	// destination leg is not covered by unreserved inventory
+	ErrInsufficientInventory = errors.New("bridge: insufficient destination inventory")
+	// ErrNotReady is returned when the policy does not yet allow a leg to run
+	ErrNotReady = errors.New("bridge: leg not ready")
+	// ErrPlanClosed is returned for legs of an aborted or exposed plan
+	ErrPlanClosed = errors.New("bridge: plan closed")
+	// ErrLegState is returned for a leg transition from the wrong state
+	ErrLegState = errors.New("bridge: invalid leg transition")
+)
+
+// Leg is one side's trade of a cross-chain plan
+type Leg struct {
+	ChainID uint64
+	Spend   units.Amount // token and amount the leg consumes
+	Gas     uint64       // estimated gas limit, 0 when not estimated
+	State   LegState
+	TxHash  common.Hash
+	Err     string
+}
+
+// Plan is a cross-chain arbitrage: a source leg, a bridge transfer and a
+// destination leg, run in the order its Policy allows
+type Plan struct {
+	ID          string
+	Policy      Policy
+	Bridge      string
+	Transfer    Request
+	Source      Leg
+	Destination Leg
+	// Arrived reports the transfer landed: before the destination leg under
+	// PolicyConfirmFirst, as the closing rebalance under PolicyPrepositioned
+	Arrived bool
+	Opened  time.Time
+	Sent    time.Time // the source leg filled and the transfer left
+	Landed  time.Time // the transfer arrived
+	// ExpectedProfitUSD is the plan's profit before timing risk and
+	// NotionalUSD the value carried over the bridge
+	ExpectedProfitUSD units.USD
+	NotionalUSD       units.USD
+	// Haircut is the price drift risked while the transfer is in flight,
+	// charged against the profit by TimingRisk's Screen
+	Haircut units.USD
+}
+
+// NetProfit is the expected profit after the timing risk haircut
+func (p *Plan) NetProfit() units.USD {
+	return p.ExpectedProfitUSD - p.Haircut
+}
+
+// Leg returns the plan's leg for side
+func (p *Plan) Leg(side Side) *Leg {
+	if side == Source {
+		return &p.Source
+	}
+	return &p.Destination
+}
+
+// Status summarises the plan's progress
+func (p *Plan) Status() string {
+	for _, side := range []Side{Source, Destination} {
+		if p.Leg(side).State != LegFailed {
+			continue
+		}
+		switch p.Leg(1 - side).State {
+		case LegFilled:
+			return PlanExposed
+		case LegPending, LegFailed:
+			return PlanAborted
+		}
+	}
+	if p.Source.State == LegFilled && p.Destination.State == LegFilled && p.Arrived {
+		return PlanComplete
+	}
+	return PlanOpen
+}
+
+// InFlight reports whether the transfer has left the source chain and not yet landed
+func (p *Plan) InFlight() bool {
+	return p.Source.State == LegFilled && !p.Arrived
+}
+
+// Inventory reports token balances available to destination legs
+type Inventory interface {
+	Available(chainID uint64, token common.Address) (units.Amount, error)
+}
+
+// Screens chains plan screens into one for Tracker's Screen, running them in
+// order; nil screens are skipped and the first error refuses the plan
+func Screens(screens ...func(p *Plan) error) func(p *Plan) error {
+	return func(p *Plan) error {
+		for _, screen := range screens {
+			if screen == nil {
+				continue
+			}
+			if err := screen(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Tracker holds open cross-chain plans and enforces each plan's policy on
+// when its legs may run
+type Tracker struct {
+	// Screen, when set, vets each plan before it opens; it may shrink the
+	// plan's amounts, and an error refuses the plan
+	Screen func(p *Plan) error
+
+	mu            sync.Mutex
+	defaultPolicy Policy
+	inventory     Inventory
+	plans         map[string]*Plan
+	reserved      map[string]units.Amount // prepositioned destination spend by plan ID
+	now           func() time.Time
+}
+
+// NewTracker creates a tracker applying defaultPolicy to plans without one;
+// inventory may be nil when prepositioned plans are not used
+func NewTracker(defaultPolicy Policy, inventory Inventory) *Tracker {
+	return &Tracker{
+		defaultPolicy: defaultPolicy,
+		inventory:     inventory,
+		plans:         make(map[string]*Plan),
+		reserved:      make(map[string]units.Amount),
+		now:           time.Now,
+	}
+}
+
+// Open starts tracking p. A prepositioned plan reserves its destination
+// spend against inventory so concurrent plans cannot count the same funds.
+func (t *Tracker) Open(p Plan) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.plans[p.ID]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicatePlan, p.ID)
+	}
+	if p.Policy == "" {
+		p.Policy = t.defaultPolicy
+	}
+	if t.Screen != nil {
+		if err := t.Screen(&p); err != nil {
+			return err
+		}
+	}
+	switch p.Policy {
+	case PolicyConfirmFirst:
+	case PolicyPrepositioned:
+		if err := t.reserve(p); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownPolicy, p.Policy)
+	}
+	p.Source = Leg{ChainID: p.Source.ChainID, Spend: p.Source.Spend, Gas: p.Source.Gas, State: LegPending}
+	p.Destination = Leg{ChainID: p.Destination.ChainID, Spend: p.Destination.Spend, Gas: p.Destination.Gas, State: LegPending}
+	p.Arrived, p.Sent, p.Landed = false, time.Time{}, time.Time{}
+	p.Opened = t.now()
+	t.plans[p.ID] = &p
+	return nil
+}
+
+// reserve checks the destination spend against inventory not held by other plans
+func (t *Tracker) reserve(p Plan) error {
+	leg := p.Destination
+	if t.inventory == nil {
+		return fmt.Errorf("%w: no inventory source", ErrInsufficientInventory)
+	}
+	available, err := t.inventory.Available(leg.ChainID, leg.Spend.Token)
+	if err != nil {
+		return fmt.Errorf("inventory on chain %d: %w", leg.ChainID, err)
+	}
+	held := new(uint256.Int)
+	for id, r := range t.reserved {
+		if t.plans[id].Destination.ChainID == leg.ChainID && r.Token == leg.Spend.Token {
+			held.Add(held, r.Value)
+		}
+	}
+	free := new(uint256.Int)
+	if available.Value.Cmp(held) > 0 {
+		free.Sub(available.Value, held)
+	}
+	if free.Cmp(leg.Spend.Value) < 0 {
+		return fmt.Errorf("%w: need %s, %s free on chain %d", ErrInsufficientInventory,
+			leg.Spend, units.New(leg.Spend.Token, free, leg.Spend.Decimals), leg.ChainID)
+	}
+	t.reserved[p.ID] = leg.Spend
+	return nil
+}
+
+// Ready returns nil when the plan's policy allows side's leg to be submitted
+// now, ErrNotReady while it must wait and ErrPlanClosed once it never will
+func (t *Tracker) Ready(id string, side Side) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, err := t.plan(id)
+	if err != nil {
+		return err
+	}
+	return ready(p, side)
+}
+
+func ready(p *Plan, side Side) error {
+	if status := p.Status(); status == PlanAborted || status == PlanExposed {
+		return fmt.Errorf("%w: %s is %s", ErrPlanClosed, p.ID, status)
+	}
+	if state := p.Leg(side).State; state != LegPending {
+		return fmt.Errorf("%w: %s leg of %s is %s", ErrLegState, side, p.ID, state)
+	}
+	if side == Destination && p.Policy == PolicyConfirmFirst {
+		if p.Source.State != LegFilled {
+			return fmt.Errorf("%w: %s waiting for source fill", ErrNotReady, p.ID)
+		}
+		if !p.Arrived {
+			return fmt.Errorf("%w: %s waiting for %s transfer", ErrNotReady, p.ID, p.Bridge)
+		}
+	}
+	return nil
+}
+
+// Submitted records side's transaction, refusing legs the policy does not
+// yet allow
+func (t *Tracker) Submitted(id string, side Side, tx common.Hash) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, err := t.plan(id)
+	if err != nil {
+		return err
+	}
+	if err := ready(p, side); err != nil {
+		return err
+	}
+	leg := p.Leg(side)
+	leg.State, leg.TxHash = LegSubmitted, tx
+	return nil
+}
+
+// Filled records that side's submitted transaction succeeded
+func (t *Tracker) Filled(id string, side Side) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, err := t.plan(id)
+	if err != nil {
+		return err
+	}
+	leg := p.Leg(side)
+	if leg.State != LegSubmitted {
+		return fmt.Errorf("%w: %s leg of %s is %s, not submitted", ErrLegState, side, id, leg.State)
+	}
+	leg.State = LegFilled
+	if side == Source {
+		p.Sent = t.now()
+	}
+	t.settle(p)
+	return nil
+}
+
+// Failed records that side's leg failed or will not be attempted
+func (t *Tracker) Failed(id string, side Side, reason error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, err := t.plan(id)
+	if err != nil {
+		return err
+	}
+	leg := p.Leg(side)
+	if leg.State != LegPending && leg.State != LegSubmitted {
+		return fmt.Errorf("%w: %s leg of %s is already %s", ErrLegState, side, id, leg.State)
+	}
+	leg.State = LegFailed
+	if reason != nil {
+		leg.Err = reason.Error()
+	}
+	t.settle(p)
+	return nil
+}
+
+// Arrived records that the plan's bridge transfer landed on the destination chain
+func (t *Tracker) Arrived(id string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, err := t.plan(id)
+	if err != nil {
+		return err
+	}
+	p.Arrived, p.Landed = true, t.now()
+	return nil
+}
+
+// settle frees a prepositioned reservation once the destination leg can no
+// longer spend it
+func (t *Tracker) settle(p *Plan) {
+	if p.Destination.State == LegFilled || p.Destination.State == LegFailed || p.Status() == PlanAborted {
+		delete(t.reserved, p.ID)
+	}
+}
+
+// Plan returns a copy of the plan with id
+func (t *Tracker) Plan(id string) (Plan, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.plans[id]
+	if !ok {
+		return Plan{}, false
+	}
+	return *p, true
+}
+
+// Plans returns copies of every tracked plan, oldest first
+func (t *Tracker) Plans() []Plan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]Plan, 0, len(t.plans))
+	for _, p := range t.plans {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Opened.Before(out[j].Opened) })
+	return out
+}
+
+func (t *Tracker) plan(id string) (*Plan, error) {
+	p, ok := t.plans[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPlan, id)
+	}
+	return p, nil
+}
@@ -0,0 +1,109 @@
+package bridge
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+type staticInventory map[uint64]units.Amount
+
+func (s staticInventory) Available(chainID uint64, token common.Address) (units.Amount, error) {
+	return s[chainID], nil
+}
+
+func usdc(t *testing.T, whole uint64) units.Amount {
+	a, err := units.FromWhole(titantest.Address(0xc0), whole, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func testPlan(t *testing.T, id string, policy Policy, spend uint64) Plan {
+	return Plan{
+		ID: id, Policy: policy, Bridge: "across",
+		Source:      Leg{ChainID: 137, Spend: usdc(t, spend)},
+		Destination: Leg{ChainID: 56, Spend: usdc(t, spend)},
+	}
+}
+
+func TestConfirmFirstWaitsForSourceAndBridge(t *testing.T) {
+	tracker := NewTracker(PolicyConfirmFirst, nil)
+	if err := tracker.Open(testPlan(t, "p1", "", 1000)); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	if err := tracker.Submitted("p1", Destination, common.Hash{}); !errors.Is(err, ErrNotReady) {
+		t.Errorf("Expected destination to wait for the source, got %v", err)
+	}
+	if err := tracker.Submitted("p1", Source, common.Hash{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Filled("p1", Source); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Ready("p1", Destination); !errors.Is(err, ErrNotReady) {
+		t.Errorf("Expected destination to wait for the bridge, got %v", err)
+	}
+	if err := tracker.Arrived("p1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Submitted("p1", Destination, common.Hash{2}); err != nil {
+		t.Fatalf("Expected destination to run after arrival, got %v", err)
+	}
+	if err := tracker.Filled("p1", Destination); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := tracker.Plan("p1"); p.Status() != PlanComplete || p.Policy != PolicyConfirmFirst {
+		t.Errorf("Expected complete confirm-first plan, got %s %s", p.Policy, p.Status())
+	}
+
+	// A failed source aborts the plan before anything happens on the destination
+	if err := tracker.Open(testPlan(t, "p2", PolicyConfirmFirst, 1000)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Failed("p2", Source, errors.New("reverted")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Ready("p2", Destination); !errors.Is(err, ErrPlanClosed) {
+		t.Errorf("Expected aborted plan to refuse the destination leg, got %v", err)
+	}
+}
+
+func TestPrepositionedReservesInventory(t *testing.T) {
+	tracker := NewTracker(PolicyConfirmFirst, staticInventory{56: usdc(t, 1500)})
+	if err := tracker.Open(testPlan(t, "p1", PolicyPrepositioned, 1000)); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := tracker.Open(testPlan(t, "p2", PolicyPrepositioned, 1000)); !errors.Is(err, ErrInsufficientInventory) {
+		t.Errorf("Expected reserved inventory to block a second plan, got %v", err)
+	}
+
+	// Both legs may run at once
+	for _, side := range []Side{Source, Destination} {
+		if err := tracker.Submitted("p1", side, common.Hash{}); err != nil {
+			t.Fatalf("Expected %s leg to run immediately, got %v", side, err)
+		}
+	}
+	if err := tracker.Filled("p1", Destination); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Failed("p1", Source, errors.New("reverted")); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := tracker.Plan("p1"); p.Status() != PlanExposed {
+		t.Errorf("Expected exposed plan, got %s", p.Status())
+	}
+
+	// The filled destination leg released its reservation
+	if err := tracker.Open(testPlan(t, "p2", PolicyPrepositioned, 1000)); err != nil {
+		t.Errorf("Expected inventory to be free again, got %v", err)
+	}
+	if err := tracker.Open(testPlan(t, "p3", "bogus", 1)); !errors.Is(err, ErrUnknownPolicy) {
+		t.Errorf("Expected ErrUnknownPolicy, got %v", err)
+	}
+}
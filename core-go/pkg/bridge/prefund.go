@@ -0,0 +1,166 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/execution"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// refuelABI is Bungee Refuel's deposit, which pays out native gas on the
+// destination chain for native value sent on the source
+const refuelABI = `[
+{"inputs":[{"name":"destinationChainId","type":"uint256"},{"name":"_to","type":"address"}],"name":"depositNativeToken","outputs":[],"stateMutability":"payable","type":"function"}
+]`
+
+var parsedRefuelABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(refuelABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// ErrDestinationGas is returned when a plan's destination wallet cannot pay
+// for its leg's gas
+var ErrDestinationGas = errors.New("bridge: destination wallet short of gas")
+
+// weiPerUSD converts wei at a PriceDecimals price into USD: 10^(18 + 8 - 6)
+var weiPerUSD = new(big.Int).Exp(big.NewInt(10), big.NewInt(18+units.PriceDecimals-units.USDDecimals), nil)
+
+// NativeBalance reads a wallet's native balance on a chain, in wei
+type NativeBalance func(ctx context.Context, chainID uint64, wallet common.Address) (*big.Int, error)
+
+// FeeCap returns the max fee per gas bid on a chain now, in wei
+type FeeCap func(ctx context.Context, chainID uint64) (*big.Int, error)
+
+// NativePrice returns a chain's native token price in USD at PriceDecimals
+type NativePrice func(chainID uint64) (priceE8 uint64, err error)
+
+// GasCheck is a destination wallet's native balance against the gas its
+// plan's leg needs
+type GasCheck struct {
+	Plan      string         `json:"plan"`
+	ChainID   uint64         `json:"chainId"`
+	Wallet    common.Address `json:"wallet"`
+	GasLimit  uint64         `json:"gasLimit"`
+	FeeCap    *big.Int       `json:"feeCap"`
+	Required  *big.Int       `json:"required"` // wei, with headroom
+	Balance   *big.Int       `json:"balance"`
+	Shortfall *big.Int       `json:"shortfall,omitempty"`
+	// TopUp, when the wallet is short and refuel is on, sends gas from the
+	// source chain over the refuel bridge; the plan waits until it lands
+	TopUp    *execution.Call `json:"topUp,omitempty"`
+	TopUpUSD units.USD       `json:"topUpUsd,omitempty"`
+}
+
+// Funded reports whether the wallet covers the leg
+func (c GasCheck) Funded() bool {
+	return c.Shortfall == nil || c.Shortfall.Sign() == 0
+}
+
+// Prefunder checks, before a cross-chain plan executes, that the wallet
+// receiving its transfer holds the native gas to run the destination leg;
+// bridged tokens are stranded on a chain whose wallet cannot pay gas. A
+// short wallet can be topped up from the source chain over a gas-refuel
+// bridge. A nil Prefunder passes every plan.
+type Prefunder struct {
+	cfg     config.GasPrefundConfig
+	balance NativeBalance
+	feeCap  FeeCap
+	price   NativePrice
+}
+
+// NewPrefunder creates the check, nil when cfg disables it; price may be
+// nil, leaving short wallets without a top-up
+func NewPrefunder(cfg *config.GasPrefundConfig, balance NativeBalance, feeCap FeeCap, price NativePrice) *Prefunder {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	return &Prefunder{cfg: *cfg, balance: balance, feeCap: feeCap, price: price}
+}
+
+// Check compares p's destination wallet, the transfer's sender whom every
+// adapter delivers to, with its leg's gas limit at the chain's current fee
+// cap and the configured headroom. It returns ErrDestinationGas when the
+// wallet is short, with the top-up to send when refuel is on.
+func (f *Prefunder) Check(ctx context.Context, p Plan) (GasCheck, error) {
+	c := GasCheck{Plan: p.ID, ChainID: p.Destination.ChainID, Wallet: p.Transfer.Sender, GasLimit: p.Destination.Gas}
+	if f == nil {
+		return c, nil
+	}
+	if c.GasLimit == 0 {
+		c.GasLimit = f.cfg.DefaultGasLimit
+	}
+	feeCap, err := f.feeCap(ctx, c.ChainID)
+	if err != nil {
+		return c, fmt.Errorf("chain %d fees: %w", c.ChainID, err)
+	}
+	gasWei := new(big.Int).Mul(new(big.Int).SetUint64(c.GasLimit), feeCap)
+	c.FeeCap, c.Required = feeCap, units.MulBps(gasWei, f.cfg.HeadroomBps)
+	if c.Balance, err = f.balance(ctx, c.ChainID, c.Wallet); err != nil {
+		return c, fmt.Errorf("chain %d balance of %s: %w", c.ChainID, c.Wallet.Hex(), err)
+	}
+	if c.Balance.Cmp(c.Required) >= 0 {
+		return c, nil
+	}
+	c.Shortfall = new(big.Int).Sub(c.Required, c.Balance)
+	short := fmt.Errorf("%w: %s holds %s wei on chain %d, the leg needs %s", ErrDestinationGas, c.Wallet.Hex(), c.Balance, c.ChainID, c.Required)
+	if !f.cfg.Refuel {
+		return c, short
+	}
+	if c.TopUp, c.TopUpUSD, err = f.topUp(p, gasWei, c.Balance); err != nil {
+		return c, fmt.Errorf("%v; no top-up: %w", short, err)
+	}
+	log.Printf("⛽ Plan %s: %s short of gas on chain %d, refueling %s from chain %d",
+		p.ID, c.Wallet.Hex(), c.ChainID, c.TopUpUSD, c.TopUp.ChainID)
+	return c, short
+}
+
+// topUp builds the refuel deposit on p's source chain bringing the
+// destination wallet from balance to the target share of gasWei
+func (f *Prefunder) topUp(p Plan, gasWei, balance *big.Int) (*execution.Call, units.USD, error) {
+	if f.price == nil {
+		return nil, 0, errors.New("no native prices")
+	}
+	from, to := p.Source.ChainID, p.Destination.ChainID
+	destE8, err := f.price(to)
+	if err != nil {
+		return nil, 0, err
+	}
+	srcE8, err := f.price(from)
+	if err != nil {
+		return nil, 0, err
+	}
+	if srcE8 == 0 {
+		return nil, 0, fmt.Errorf("chain %d native token priced at zero", from)
+	}
+	want := new(big.Int).Sub(units.MulBps(gasWei, f.cfg.RefuelTargetBps), balance)
+	worth := new(big.Int).Mul(want, new(big.Int).SetUint64(destE8))
+	usd := units.USD(new(big.Int).Quo(worth, weiPerUSD).Int64())
+	if limit := units.DollarsToUSD(f.cfg.RefuelMaxUSD); usd > limit {
+		return nil, 0, fmt.Errorf("top-up of %s exceeds the %s cap", usd, limit)
+	}
+	// The same worth in the source chain's native token, rounded up
+	value := new(big.Int).Add(worth, new(big.Int).SetUint64(srcE8-1))
+	value.Quo(value, new(big.Int).SetUint64(srcE8))
+	data, err := parsedRefuelABI.Pack("depositNativeToken", new(big.Int).SetUint64(to), p.Transfer.Sender)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &execution.Call{
+		ChainID: from,
+		From:    p.Transfer.Sender,
+		To:      common.HexToAddress(f.cfg.RefuelContract),
+		Data:    data,
+		Value:   value,
+	}, usd, nil
+}
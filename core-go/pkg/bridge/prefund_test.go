@@ -0,0 +1,72 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+)
+
+func testPrefunder(cfg config.GasPrefundConfig, balance, feeCap *big.Int) *Prefunder {
+	prices := map[uint64]uint64{137: 50_000_000, 42161: 3000_00000000} // $0.50 POL, $3000 ETH
+	return NewPrefunder(&cfg,
+		func(ctx context.Context, chainID uint64, wallet common.Address) (*big.Int, error) { return balance, nil },
+		func(ctx context.Context, chainID uint64) (*big.Int, error) { return feeCap, nil },
+		func(chainID uint64) (uint64, error) { return prices[chainID], nil },
+	)
+}
+
+func TestPrefunderChecksDestinationGas(t *testing.T) {
+	cfg := config.GasPrefundConfig{Enabled: true, DefaultGasLimit: 600_000, HeadroomBps: 15000,
+		RefuelContract: "0xb584D4bE1A5470CA1a8778E9B86c81e165204599", RefuelTargetBps: 30000, RefuelMaxUSD: 25}
+	p := testPlan(t, "p1", "", 1000)
+	p.Source.ChainID, p.Destination.ChainID = 137, 42161
+	p.Destination.Gas = 500_000
+	p.Transfer.Sender = common.HexToAddress("0x01")
+
+	c, err := testPrefunder(cfg, big.NewInt(1e14), gas.Gwei(1)).Check(context.Background(), p)
+	if err == nil || c.Required.Cmp(big.NewInt(75e13)) != 0 {
+		t.Fatalf("Expected 7.5e14 wei required at 1 gwei, got %v: %v", c.Required, err)
+	}
+	// 500k gas at 0.1 gwei is 5e13 wei, so 7.5e13 is required
+	c, err = testPrefunder(cfg, big.NewInt(1e14), big.NewInt(1e8)).Check(context.Background(), p)
+	if err != nil || !c.Funded() || c.TopUp != nil {
+		t.Errorf("Expected a wallet holding 1e14 wei funded, got %+v: %v", c, err)
+	}
+
+	c, err = testPrefunder(cfg, big.NewInt(2e13), big.NewInt(1e8)).Check(context.Background(), p)
+	if !errors.Is(err, ErrDestinationGas) || c.Funded() || c.Shortfall.Cmp(big.NewInt(55e12)) != 0 || c.TopUp != nil {
+		t.Errorf("Expected a 5.5e13 wei shortfall without a top-up, got %+v: %v", c, err)
+	}
+
+	cfg.Refuel = true
+	c, err = testPrefunder(cfg, big.NewInt(2e13), big.NewInt(1e8)).Check(context.Background(), p)
+	if !errors.Is(err, ErrDestinationGas) || c.TopUp == nil {
+		t.Fatalf("Expected the plan held for a top-up, got %+v: %v", c, err)
+	}
+	// 1.3e14 wei of ETH is $0.39, or 0.78 POL sent from polygon
+	if c.TopUpUSD != 390_000 || c.TopUp.ChainID != 137 || c.TopUp.Value.Cmp(big.NewInt(78e16)) != 0 {
+		t.Errorf("Expected $0.39 of POL refueled from polygon, got %s: %+v", c.TopUpUSD, c.TopUp)
+	}
+	args, err := parsedRefuelABI.Methods["depositNativeToken"].Inputs.Unpack(c.TopUp.Data[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args[0].(*big.Int).Uint64() != 42161 || args[1].(common.Address) != p.Transfer.Sender {
+		t.Errorf("Expected gas delivered to the wallet on arbitrum, got %v", args)
+	}
+
+	// At 100 gwei the top-up is worth $390, past the $25 cap
+	if _, err := testPrefunder(cfg, big.NewInt(0), gas.Gwei(100)).Check(context.Background(), p); !strings.Contains(err.Error(), "cap") {
+		t.Errorf("Expected an oversized top-up refused, got %v", err)
+	}
+	var disabled *Prefunder
+	if c, err := disabled.Check(context.Background(), p); err != nil || !c.Funded() {
+		t.Errorf("Expected a nil prefunder to pass, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package bridge
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// ErrTimingRisk is returned when a plan's expected profit does not cover the
+// price drift it risks while its transfer is in flight
+var ErrTimingRisk = errors.New("bridge: profit does not cover timing risk")
+
+// Volatility reports a token's hourly volatility in bps; ok is false when it
+// is not measured
+type Volatility func(t tokens.Token) (hourlyBps float64, ok bool)
+
+// Assessment is the price drift risked holding a token over a bridge
+type Assessment struct {
+	Bridge        string        `json:"bridge"`
+	Exposure      time.Duration `json:"exposure"`      // time in flight the drift is measured over
+	VolatilityBps float64       `json:"volatilityBps"` // hourly
+	Measured      bool          `json:"measured"`      // false when the fallback volatility was assumed
+	DriftBps      float64       `json:"driftBps"`      // one standard deviation over the exposure
+	HaircutBps    float64       `json:"haircutBps"`
+	HaircutUSD    units.USD     `json:"haircutUsd"`
+}
+
+// TimingRisk charges cross-chain plans for the time their transfer is in
+// flight. A confirm-first plan holds the bridged token until it lands, so the
+// destination leg trades at whatever the price has drifted to: one standard
+// deviation is the hourly volatility times √(time/1h). Variance grows with
+// time, so the exposure blends the bridge's typical and maximum times by the
+// share of transfers assumed late, and the haircut is the drift at the
+// configured confidence. Stablecoins carry none. A nil TimingRisk charges
+// nothing.
+type TimingRisk struct {
+	bridges    map[string]*config.BridgeConfig
+	cfg        config.BridgeRiskConfig
+	volatility Volatility
+}
+
+// NewTimingRisk creates a risk model over the configured bridges; vol may be
+// nil, leaving every token at the fallback volatility
+func NewTimingRisk(bridges map[string]*config.BridgeConfig, cfg *config.BridgeRiskConfig, vol Volatility) *TimingRisk {
+	return &TimingRisk{bridges: bridges, cfg: *cfg, volatility: vol}
+}
+
+// Exposure blends a bridge's typical and maximum times by the late share
+func (r *TimingRisk) Exposure(eta, maxETA time.Duration) time.Duration {
+	if maxETA < eta {
+		maxETA = eta
+	}
+	return time.Duration((1-r.cfg.LateShare)*float64(eta) + r.cfg.LateShare*float64(maxETA))
+}
+
+// Assess prices the drift of notional in token over a bridge taking eta,
+// and maxETA when late
+func (r *TimingRisk) Assess(bridge string, eta, maxETA time.Duration, token tokens.Token, notional units.USD) Assessment {
+	a := Assessment{Bridge: bridge, Exposure: r.Exposure(eta, maxETA)}
+	if token.Stable() {
+		a.Measured = true
+		return a
+	}
+	a.VolatilityBps = r.cfg.FallbackVolBps
+	if r.volatility != nil {
+		if bps, ok := r.volatility(token); ok {
+			a.VolatilityBps, a.Measured = bps, true
+		}
+	}
+	a.DriftBps = a.VolatilityBps * math.Sqrt(a.Exposure.Hours())
+	a.HaircutBps = math.Min(r.cfg.Confidence*a.DriftBps, units.BpsDenominator)
+	a.HaircutUSD = units.USD(float64(notional) * a.HaircutBps / units.BpsDenominator)
+	return a
+}
+
+// AssessQuote prices the drift of notional in token over a quote's times
+func (r *TimingRisk) AssessQuote(q *Quote, token tokens.Token, notional units.USD) Assessment {
+	return r.Assess(q.Bridge, q.ETA, q.MaxETA, token, notional)
+}
+
+// Screen charges a plan's haircut and refuses it when nothing is left of
+// the expected profit; it has the signature of Tracker's Screen. Only
+// confirm-first plans wait on the transfer; prepositioned ones fill both
+// legs at once.
+func (r *TimingRisk) Screen(p *Plan) error {
+	if r == nil || p.Policy != PolicyConfirmFirst {
+		return nil
+	}
+	b, ok := r.bridges[p.Bridge]
+	if !ok {
+		return fmt.Errorf("%w: no timings for bridge %s", ErrTimingRisk, p.Bridge)
+	}
+	a := r.Assess(p.Bridge, time.Duration(b.TypicalTimeSeconds)*time.Second, time.Duration(b.MaxTimeSeconds)*time.Second,
+		p.Transfer.FromToken, p.NotionalUSD)
+	p.Haircut = a.HaircutUSD
+	if p.NetProfit() <= 0 {
+		log.Printf("⏳ Plan %s refused: %s haircut over %s on %s eats its %s profit",
+			p.ID, a.HaircutUSD, a.Exposure, p.Bridge, p.ExpectedProfitUSD)
+		return fmt.Errorf("%w: %s expected, %s haircut over %s", ErrTimingRisk, p.ExpectedProfitUSD, a.HaircutUSD, a.Exposure)
+	}
+	return nil
+}
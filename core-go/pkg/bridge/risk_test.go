@@ -0,0 +1,91 @@
+package bridge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+func testRisk(vol Volatility) *TimingRisk {
+	return NewTimingRisk(map[string]*config.BridgeConfig{
+		"slow": {TypicalTimeSeconds: 3600, MaxTimeSeconds: 3600},
+	}, &config.BridgeRiskConfig{Confidence: 2, LateShare: 0.1, FallbackVolBps: 100}, vol)
+}
+
+func TestExposureBlendsLateTransfers(t *testing.T) {
+	r := testRisk(nil)
+	if got := r.Exposure(time.Minute, 11*time.Minute); got != 2*time.Minute {
+		t.Errorf("Expected 2m of exposure, got %s", got)
+	}
+	if got := r.Exposure(time.Minute, 0); got != time.Minute {
+		t.Errorf("Expected the typical time without a max, got %s", got)
+	}
+}
+
+func TestAssessScalesDriftWithTime(t *testing.T) {
+	weth, _ := tokens.Default().Lookup(137, "WETH")
+	r := testRisk(func(tokens.Token) (float64, bool) { return 50, true })
+
+	a := r.Assess("slow", 4*time.Hour, 4*time.Hour, weth, units.DollarsToUSD(10_000))
+	if !a.Measured || a.DriftBps != 100 || a.HaircutBps != 200 || a.HaircutUSD != units.DollarsToUSD(200) {
+		t.Errorf("Expected 100 bps of drift over 4h and a $200 haircut, got %+v", a)
+	}
+
+	usdc, _ := tokens.Default().Lookup(137, "USDC")
+	if a := r.Assess("slow", 4*time.Hour, 4*time.Hour, usdc, units.DollarsToUSD(10_000)); a.HaircutUSD != 0 {
+		t.Errorf("Expected no haircut on a stablecoin, got %+v", a)
+	}
+}
+
+func TestScreenChargesConfirmFirstPlans(t *testing.T) {
+	weth, _ := tokens.Default().Lookup(137, "WETH")
+	r := testRisk(nil)
+	p := testPlan(t, "p1", PolicyConfirmFirst, 1000)
+	p.Bridge = "slow"
+	p.Transfer.FromToken = weth
+	p.NotionalUSD, p.ExpectedProfitUSD = units.DollarsToUSD(10_000), units.DollarsToUSD(150)
+
+	// 100 bps an hour at two standard deviations
+	if err := r.Screen(&p); !errors.Is(err, ErrTimingRisk) {
+		t.Errorf("Expected $150 not to cover a $200 haircut, got %v", err)
+	}
+	p.ExpectedProfitUSD = units.DollarsToUSD(250)
+	if err := r.Screen(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.NetProfit() != units.DollarsToUSD(50) {
+		t.Errorf("Expected $50 left after the haircut, got %s", p.NetProfit())
+	}
+
+	p.Policy, p.Haircut, p.ExpectedProfitUSD = PolicyPrepositioned, 0, 0
+	if err := r.Screen(&p); err != nil || p.Haircut != 0 {
+		t.Errorf("Expected a prepositioned plan left alone, got %v and %s", err, p.Haircut)
+	}
+
+	p.Policy, p.Bridge = PolicyConfirmFirst, "unknown"
+	if err := r.Screen(&p); !errors.Is(err, ErrTimingRisk) {
+		t.Errorf("Expected a bridge without timings refused, got %v", err)
+	}
+}
+
+func TestScreensRunInOrder(t *testing.T) {
+	var ran []string
+	refuse := errors.New("refused")
+	tracker := NewTracker(PolicyConfirmFirst, nil)
+	tracker.Screen = Screens(
+		func(*Plan) error { ran = append(ran, "first"); return nil },
+		nil,
+		func(*Plan) error { ran = append(ran, "second"); return refuse },
+		func(*Plan) error { ran = append(ran, "third"); return nil },
+	)
+	if err := tracker.Open(testPlan(t, "p1", "", 1000)); !errors.Is(err, refuse) {
+		t.Errorf("Expected the plan refused, got %v", err)
+	}
+	if len(ran) != 2 {
+		t.Errorf("Expected screens to stop at the refusal, got %v", ran)
+	}
+}
@@ -0,0 +1,309 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Bridge health
+const (
+	HealthUnknown  = "unknown" // not quoted yet
+	HealthOK       = "ok"
+	HealthDegraded = "degraded" // some quotes fail, costs drifted or transfers overdue
+	HealthDown     = "down"     // every reference quote fails
+)
+
+// referenceSender requests reference quotes; aggregators require an address
+// but do not check balances for quotes
+var referenceSender = common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+// quoteTimeout bounds each adapter's reference quote
+const quoteTimeout = 15 * time.Second
+
+// RefQuote is a bridge's current quote for one reference size
+type RefQuote struct {
+	SizeUSD  uint64        `json:"sizeUsd"`
+	FeeBps   uint32        `json:"feeBps"`
+	FeeUSD   float64       `json:"feeUsd,omitempty"` // when the source reports it
+	Received string        `json:"received,omitempty"`
+	ETA      time.Duration `json:"eta,omitempty"`
+	Source   string        `json:"source,omitempty"`
+	Err      string        `json:"error,omitempty"`
+}
+
+// Status is one bridge's row on the status page: its current quotes,
+// realized fill times and the outcome of plans sent over it
+type Status struct {
+	Bridge  string   `json:"bridge"`
+	Live    bool     `json:"live"` // quoted by the protocol's API rather than estimated
+	Health  string   `json:"health"`
+	Reasons []string `json:"reasons,omitempty"`
+
+	Quotes        []RefQuote `json:"quotes"`
+	QuotedAt      time.Time  `json:"quotedAt"`
+	QuoteFailures uint64     `json:"quoteFailures"` // reference quotes failed since start
+
+	Transfers    int           `json:"transfers"` // realized, within the calibration window
+	Fill         time.Duration `json:"fill"`      // median
+	MaxFill      time.Duration `json:"maxFill"`   // 90th percentile
+	LastFill     time.Duration `json:"lastFill"`
+	LastTransfer time.Time     `json:"lastTransfer"`
+	Drifted      bool          `json:"drifted"`
+
+	InFlight  int `json:"inFlight"`
+	Overdue   int `json:"overdue"` // in flight past the bridge's maximum time
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"` // plans aborted or left exposed
+}
+
+// Monitor aggregates each registered bridge's health for the status page:
+// it periodically quotes reference sizes over a reference route, and adds
+// realized fill times from calibration and plan outcomes from the tracker.
+// Tracker and calibration may be nil. It is safe for concurrent use.
+type Monitor struct {
+	registry    *Registry
+	tracker     *Tracker
+	calibration *Calibrator
+	bridges     map[string]*config.BridgeConfig
+	cfg         config.BridgeStatusConfig
+
+	// OnChange, when set, is called when a quoted bridge's health changes
+	OnChange func(s Status)
+
+	mu       sync.Mutex
+	quotes   map[string][]RefQuote
+	failures map[string]uint64
+	quotedAt time.Time
+	health   map[string]string
+	now      func() time.Time
+
+	healthy       *metrics.GaugeVec
+	quoteFailures *metrics.CounterVec
+}
+
+// NewMonitor creates a monitor of registry's bridges; reg may be nil
+func NewMonitor(registry *Registry, tracker *Tracker, calibration *Calibrator, bridges map[string]*config.BridgeConfig, cfg *config.BridgeStatusConfig, reg *metrics.Registry) *Monitor {
+	m := &Monitor{
+		registry:    registry,
+		tracker:     tracker,
+		calibration: calibration,
+		bridges:     bridges,
+		cfg:         *cfg,
+		quotes:      make(map[string][]RefQuote),
+		failures:    make(map[string]uint64),
+		health:      make(map[string]string),
+		now:         time.Now,
+	}
+	if reg != nil {
+		m.healthy = reg.Gauge("titan_bridge_healthy", "1 when a bridge quotes every reference size without drift or overdue transfers", "bridge")
+		m.quoteFailures = reg.Counter("titan_bridge_quote_failures_total", "Failed bridge reference quotes", "bridge")
+	}
+	return m
+}
+
+// Run refreshes the reference quotes every interval until ctx ends
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := m.Refresh(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Bridge status: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh quotes every bridge for each reference size
+func (m *Monitor) Refresh(ctx context.Context) error {
+	reg := tokens.Default()
+	from, err := reg.Lookup(m.cfg.FromChain, m.cfg.Token)
+	if err != nil {
+		return fmt.Errorf("reference token: %w", err)
+	}
+	to, err := reg.Lookup(m.cfg.ToChain, m.cfg.Token)
+	if err != nil {
+		return fmt.Errorf("reference token: %w", err)
+	}
+
+	quotes := make(map[string][]RefQuote)
+	failed := make(map[string]uint64)
+	for _, size := range m.cfg.SizesUSD {
+		amount, err := units.FromWhole(from.Address, size, from.Decimals)
+		if err != nil {
+			return err
+		}
+		req := Request{FromChain: m.cfg.FromChain, ToChain: m.cfg.ToChain, FromToken: from, ToToken: to,
+			Amount: amount, Sender: referenceSender, SizeUSD: units.DollarsToUSD(size)}
+		for _, r := range m.registry.QuoteAll(ctx, req, quoteTimeout) {
+			rq := RefQuote{SizeUSD: size}
+			if r.Err != nil {
+				rq.Err = r.Err.Error()
+				failed[r.Bridge]++
+			} else {
+				rq.FeeBps, rq.FeeUSD, rq.Received = r.Quote.FeeBps, r.Quote.FeeUSD.Float(), r.Quote.AmountOut.String()
+				rq.ETA, rq.Source = r.Quote.ETA, r.Quote.Source
+			}
+			quotes[r.Bridge] = append(quotes[r.Bridge], rq)
+		}
+	}
+
+	m.mu.Lock()
+	m.quotes, m.quotedAt = quotes, m.now()
+	for bridge, n := range failed {
+		m.failures[bridge] += n
+	}
+	m.mu.Unlock()
+	for bridge, n := range failed {
+		if m.quoteFailures != nil {
+			m.quoteFailures.Add(float64(n), bridge)
+		}
+	}
+
+	for _, s := range m.Statuses() {
+		m.observe(s)
+	}
+	return nil
+}
+
+// observe sets s's gauge and reports a change of health
+func (m *Monitor) observe(s Status) {
+	if s.Health == HealthUnknown {
+		return
+	}
+	if m.healthy != nil {
+		healthy := 0.0
+		if s.Health == HealthOK {
+			healthy = 1
+		}
+		m.healthy.Set(healthy, s.Bridge)
+	}
+	m.mu.Lock()
+	prev, seen := m.health[s.Bridge]
+	m.health[s.Bridge] = s.Health
+	m.mu.Unlock()
+	if prev == s.Health || (!seen && s.Health == HealthOK) {
+		return
+	}
+	if s.Health == HealthOK {
+		log.Printf("🌉 Bridge %s healthy again", s.Bridge)
+	} else {
+		log.Printf("🌉 Bridge %s %s: %v", s.Bridge, s.Health, s.Reasons)
+	}
+	if m.OnChange != nil {
+		m.OnChange(s)
+	}
+}
+
+// Statuses reports every registered bridge, by name
+func (m *Monitor) Statuses() []Status {
+	if m == nil {
+		return nil
+	}
+	var plans []Plan
+	if m.tracker != nil {
+		plans = m.tracker.Plans()
+	}
+	drifts := make(map[string]Drift)
+	for _, d := range m.calibration.Drifts() {
+		drifts[d.Bridge] = d
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	out := make([]Status, 0, len(m.registry.Adapters()))
+	for _, a := range m.registry.Adapters() {
+		_, estimated := a.(*ConfigAdapter)
+		s := Status{
+			Bridge:        a.Name(),
+			Live:          !estimated,
+			Quotes:        m.quotes[a.Name()],
+			QuotedAt:      m.quotedAt,
+			QuoteFailures: m.failures[a.Name()],
+			Drifted:       drifts[a.Name()].Drifted,
+		}
+		if recent := m.calibration.Recent(s.Bridge); len(recent) > 0 {
+			_, fills := samples(recent)
+			last := recent[len(recent)-1]
+			s.Transfers, s.LastFill, s.LastTransfer = len(recent), last.Fill, last.At
+			s.Fill, s.MaxFill = time.Duration(quantile(fills, 0.5)), time.Duration(quantile(fills, 0.9))
+		}
+		m.plans(&s, plans, now)
+		m.assess(&s, drifts[s.Bridge])
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Bridge < out[j].Bridge })
+	return out
+}
+
+// plans counts the outcomes of plans sent over s's bridge
+func (m *Monitor) plans(s *Status, plans []Plan, now time.Time) {
+	var limit time.Duration
+	if b, ok := m.bridges[s.Bridge]; ok {
+		limit = time.Duration(b.MaxTimeSeconds) * time.Second
+	}
+	for _, p := range plans {
+		if p.Bridge != s.Bridge {
+			continue
+		}
+		switch p.Status() {
+		case PlanComplete:
+			s.Completed++
+		case PlanAborted, PlanExposed:
+			s.Failed++
+		}
+		if p.InFlight() {
+			s.InFlight++
+			if limit > 0 && now.Sub(p.Sent) > limit {
+				s.Overdue++
+			}
+		}
+	}
+}
+
+// assess grades s from its quotes, d's drift and overdue transfers
+func (m *Monitor) assess(s *Status, d Drift) {
+	if len(s.Quotes) == 0 {
+		s.Health = HealthUnknown
+		return
+	}
+	failed := 0
+	var lastErr string
+	for _, q := range s.Quotes {
+		if q.Err != "" {
+			failed++
+			lastErr = q.Err
+		}
+	}
+	if failed == len(s.Quotes) {
+		s.Health, s.Reasons = HealthDown, []string{"every reference quote failed: " + lastErr}
+		return
+	}
+	if failed > 0 {
+		s.Reasons = append(s.Reasons, fmt.Sprintf("%d of %d reference quotes failed: %s", failed, len(s.Quotes), lastErr))
+	}
+	if s.Drifted {
+		s.Reasons = append(s.Reasons, d.Reasons...)
+	}
+	if s.Overdue > 0 {
+		s.Reasons = append(s.Reasons, fmt.Sprintf("%d transfers in flight past the configured maximum", s.Overdue))
+	}
+	s.Health = HealthOK
+	if len(s.Reasons) > 0 {
+		s.Health = HealthDegraded
+	}
+}
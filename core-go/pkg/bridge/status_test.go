@@ -0,0 +1,109 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// capAdapter quotes like ConfigAdapter up to a size cap and fails above it
+type capAdapter struct {
+	*ConfigAdapter
+	cap units.USD
+}
+
+func (a capAdapter) Quote(ctx context.Context, req Request) (*Quote, error) {
+	if req.Notional() > a.cap {
+		return nil, errors.New("insufficient liquidity")
+	}
+	return a.ConfigAdapter.Quote(ctx, req)
+}
+
+func TestMonitorAggregatesBridgeHealth(t *testing.T) {
+	bridges := map[string]*config.BridgeConfig{
+		"across":   {TypicalTimeSeconds: 30, MaxTimeSeconds: 180, FeeRangeBps: []uint32{4, 8}},
+		"stargate": {TypicalTimeSeconds: 60, MaxTimeSeconds: 300, FeeRangeBps: []uint32{6, 10}},
+	}
+	calibration := NewCalibrator(bridges, &config.BridgeCalibrationConfig{MinTransfers: 2, Window: 10, DriftToleranceBps: 1}, nil, nil)
+	estimated := NewConfigAdapter("across", bridges["across"])
+	estimated.Calibration = calibration
+	registry := NewRegistry(
+		estimated,
+		capAdapter{NewConfigAdapter("stargate", bridges["stargate"]), units.DollarsToUSD(10_000)},
+		failingAdapter{},
+	)
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker(PolicyConfirmFirst, nil)
+	tracker.now = func() time.Time { return now }
+	for _, id := range []string{"p1", "p2"} {
+		if err := tracker.Open(testPlan(t, id, "", 1000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tracker.Submitted("p1", Source, common.Hash{1})
+	tracker.Filled("p1", Source)
+	tracker.Failed("p2", Source, errors.New("reverted"))
+
+	for i, fill := range []time.Duration{40 * time.Second, 20 * time.Second, 30 * time.Second} {
+		calibration.Record(Transfer{Bridge: "across", FromChain: 137, ToChain: 42161, SizeUSD: units.DollarsToUSD(5_000),
+			FeeBps: 5, Fill: fill, At: now.Add(time.Duration(i) * time.Minute)})
+	}
+
+	cfg := &config.BridgeStatusConfig{RefreshSecs: 60, FromChain: 137, ToChain: 42161, Token: "USDC", SizesUSD: []uint64{1_000, 100_000}}
+	reg := metrics.NewRegistry()
+	m := NewMonitor(registry, tracker, calibration, bridges, cfg, reg)
+	m.now = func() time.Time { return now.Add(5 * time.Minute) }
+	var changes []Status
+	m.OnChange = func(s Status) { changes = append(changes, s) }
+
+	if s := m.Statuses(); len(s) != 3 || s[0].Health != HealthUnknown {
+		t.Fatalf("Expected three bridges unknown before quoting, got %+v", s)
+	}
+	if err := m.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	statuses := m.Statuses()
+	across, down, stargate := statuses[0], statuses[1], statuses[2]
+
+	if across.Live || !down.Live || len(across.Quotes) != 2 || across.Quotes[1].SizeUSD != 100_000 || across.Quotes[1].FeeBps != 5 || across.Quotes[1].Source != SourceRealized {
+		t.Errorf("Expected across estimated and both sizes quoted at the realized 5 bps, got %+v", across.Quotes)
+	}
+	if across.Transfers != 3 || across.Fill != 30*time.Second || across.MaxFill != 40*time.Second || across.LastFill != 30*time.Second {
+		t.Errorf("Expected the realized fill times, got %+v", across)
+	}
+	// p1 left five minutes ago on a bridge that takes three at most
+	if across.InFlight != 1 || across.Overdue != 1 || across.Failed != 1 || across.Health != HealthDegraded {
+		t.Errorf("Expected across degraded by an overdue transfer, got %+v", across)
+	}
+	if down.Health != HealthDown || down.QuoteFailures != 2 {
+		t.Errorf("Expected the failing bridge down, got %+v", down)
+	}
+	if stargate.Health != HealthDegraded || stargate.Quotes[0].Err != "" || stargate.Quotes[1].Err == "" {
+		t.Errorf("Expected stargate degraded by its failed $100k quote, got %+v", stargate)
+	}
+	if len(changes) != 3 {
+		t.Errorf("Expected every unhealthy bridge reported, got %d", len(changes))
+	}
+	if got := reg.Value("titan_bridge_quote_failures_total", "down"); got != 2 {
+		t.Errorf("Expected 2 failed quotes counted, got %v", got)
+	}
+
+	tracker.Arrived("p1")
+	changes = nil
+	if err := m.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s := m.Statuses()[0]; s.Health != HealthOK || len(changes) != 1 || changes[0].Bridge != "across" {
+		t.Errorf("Expected across healthy again once its transfer landed, got %+v, %d changes", s, len(changes))
+	}
+	if got := reg.Value("titan_bridge_healthy", "across"); got != 1 {
+		t.Errorf("Expected the health gauge at 1, got %v", got)
+	}
+}
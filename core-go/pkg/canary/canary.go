@@ -0,0 +1,162 @@
+package canary
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// GuardrailCanary names the sizing guardrail canary routes are bound by
+const GuardrailCanary = "canary"
+
+// RouteFills is how far a route has come towards full sizing
+type RouteFills struct {
+	ChainID   uint64 `json:"chainId"`
+	Route     string `json:"route"`
+	Fills     int    `json:"fills"`
+	Graduated bool   `json:"graduated"` // trades at full size
+}
+
+type key struct {
+	chainID uint64
+	route   string
+}
+
+// Tracker trades routes it hasn't seen fill at a canary size until they have
+// filled successfully enough times, so an adapter or token the models get
+// wrong loses a fraction of a trade rather than a full one. Failed fills
+// don't count. A nil tracker never caps anything. It is safe for concurrent
+// use.
+type Tracker struct {
+	fills   int
+	sizeBps uint64
+
+	mu     sync.Mutex
+	routes map[key]int // successful fills
+
+	sized *metrics.CounterVec
+}
+
+// New creates a tracker sizing routes at sizeBps of their size until they
+// have filled fills times; reg may be nil
+func New(fills int, sizeBps uint64, reg *metrics.Registry) *Tracker {
+	t := &Tracker{fills: fills, sizeBps: sizeBps, routes: make(map[key]int)}
+	if reg != nil {
+		t.sized = reg.Counter("titan_canary_sized_total", "Opportunities sized down as canaries on unproven routes", "chain")
+	}
+	return t
+}
+
+// FromConfig creates the configured tracker, or nil when canaries are disabled
+func FromConfig(cfg *config.CanaryConfig, reg *metrics.Registry) *Tracker {
+	if cfg == nil || cfg.Fills == 0 {
+		return nil
+	}
+	return New(int(cfg.Fills), cfg.SizeBps, reg)
+}
+
+// Observe counts successful executions from the recorder; it has the
+// signature of opportunity.Recorder listeners
+func (t *Tracker) Observe(kind string, record interface{}) {
+	if x, ok := record.(*opportunity.Execution); ok && x.Success {
+		t.fill(x.ChainID, x.Route)
+	}
+}
+
+// Load replays journaled executions
+func (t *Tracker) Load(entries []journal.Entry) error {
+	for _, e := range entries {
+		if e.Kind != journal.KindExecution {
+			continue
+		}
+		var x opportunity.Execution
+		if err := json.Unmarshal(e.Data, &x); err != nil {
+			return fmt.Errorf("bad execution entry at %s: %w", e.Time, err)
+		}
+		if x.Success {
+			t.fill(x.ChainID, x.Route)
+		}
+	}
+	return nil
+}
+
+func (t *Tracker) fill(chainID uint64, route string) {
+	if t == nil || route == "" {
+		return
+	}
+	t.mu.Lock()
+	t.routes[key{chainID, route}]++
+	t.mu.Unlock()
+}
+
+// Size returns the size to trade amount at on route: the canary share while
+// the route is unproven, with the guardrail that bound it, or amount and nil
+// once it has graduated
+func (t *Tracker) Size(chainID uint64, route string, amount *big.Int) (*big.Int, *opportunity.Guardrail) {
+	if t == nil {
+		return amount, nil
+	}
+	t.mu.Lock()
+	fills := t.routes[key{chainID, route}]
+	t.mu.Unlock()
+	if fills >= t.fills {
+		return amount, nil
+	}
+	sized := units.MulBps(amount, t.sizeBps)
+	if t.sized != nil {
+		t.sized.Inc(strconv.FormatUint(chainID, 10))
+	}
+	return sized, &opportunity.Guardrail{
+		Name:   GuardrailCanary,
+		Limit:  fmt.Sprintf("%d bps until %d fills", t.sizeBps, t.fills),
+		Actual: fmt.Sprintf("%d fills", fills),
+		Bound:  true,
+	}
+}
+
+// Proven returns how far route is through its canary fills, from 0 for a
+// new route to 1 once it has graduated
+func (t *Tracker) Proven(chainID uint64, route string) float64 {
+	if t == nil {
+		return 1
+	}
+	t.mu.Lock()
+	fills := t.routes[key{chainID, route}]
+	t.mu.Unlock()
+	if fills >= t.fills {
+		return 1
+	}
+	return float64(fills) / float64(t.fills)
+}
+
+// Routes returns every route that has filled, unproven ones first
+func (t *Tracker) Routes() []RouteFills {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]RouteFills, 0, len(t.routes))
+	for k, fills := range t.routes {
+		out = append(out, RouteFills{ChainID: k.chainID, Route: k.route, Fills: fills, Graduated: fills >= t.fills})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Fills != out[j].Fills {
+			return out[i].Fills < out[j].Fills
+		}
+		if out[i].ChainID != out[j].ChainID {
+			return out[i].ChainID < out[j].ChainID
+		}
+		return out[i].Route < out[j].Route
+	})
+	return out
+}
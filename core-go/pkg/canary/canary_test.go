@@ -0,0 +1,76 @@
+package canary
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+)
+
+func TestSizeUntilGraduated(t *testing.T) {
+	reg := metrics.NewRegistry()
+	tr := New(2, 1000, reg)
+	full := big.NewInt(5000)
+
+	size, bound := tr.Size(1, "uniswap>sushi", full)
+	if size.Int64() != 500 || bound == nil || bound.Name != GuardrailCanary {
+		t.Errorf("Expected a new route sized at 10%%, got %s (%+v)", size, bound)
+	}
+	tr.Observe(journal.KindExecution, &opportunity.Execution{ChainID: 1, Route: "uniswap>sushi", Success: true})
+	tr.Observe(journal.KindExecution, &opportunity.Execution{ChainID: 1, Route: "uniswap>sushi", Success: false})
+	if _, bound := tr.Size(1, "uniswap>sushi", full); bound == nil {
+		t.Errorf("Expected a failed fill not to count")
+	}
+	if got := tr.Proven(1, "uniswap>sushi"); got != 0.5 {
+		t.Errorf("Expected the route halfway proven, got %v", got)
+	}
+	tr.Observe(journal.KindExecution, &opportunity.Execution{ChainID: 1, Route: "uniswap>sushi", Success: true})
+	if size, bound := tr.Size(1, "uniswap>sushi", full); size.Cmp(full) != 0 || bound != nil {
+		t.Errorf("Expected the route at full size after 2 fills, got %s (%+v)", size, bound)
+	}
+	if _, bound := tr.Size(10, "uniswap>sushi", full); bound == nil {
+		t.Errorf("Expected the same route on another chain still a canary")
+	}
+	if got := reg.Value("titan_canary_sized_total", "1"); got != 2 {
+		t.Errorf("Expected 2 canaries sized on chain 1, got %v", got)
+	}
+
+	routes := tr.Routes()
+	if len(routes) != 1 || !routes[0].Graduated || routes[0].Fills != 2 {
+		t.Errorf("Expected the graduated route listed, got %+v", routes)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	raw, err := json.Marshal(opportunity.Execution{ChainID: 137, Route: "quick>sushi", Success: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := New(1, 500, nil)
+	if err := tr.Load([]journal.Entry{{Time: time.Now(), Kind: journal.KindExecution, Data: raw}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, bound := tr.Size(137, "quick>sushi", big.NewInt(100)); bound != nil {
+		t.Errorf("Expected the journaled fill to graduate the route, got %+v", bound)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	tr := FromConfig(&config.CanaryConfig{Fills: 0, SizeBps: 1000}, nil)
+	if tr != nil {
+		t.Fatalf("Expected no tracker with canaries disabled")
+	}
+	full := big.NewInt(100)
+	if size, bound := tr.Size(1, "a>b", full); size != full || bound != nil {
+		t.Errorf("Expected a nil tracker to keep the size, got %s", size)
+	}
+	tr.Observe(journal.KindExecution, &opportunity.Execution{ChainID: 1, Route: "a>b", Success: true})
+	if got := tr.Proven(1, "a>b"); got != 1 {
+		t.Errorf("Expected every route proven without canaries, got %v", got)
+	}
+}
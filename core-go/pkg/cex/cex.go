@@ -0,0 +1,196 @@
+package cex
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+)
+
+var (
+	// ErrNoTicker is returned when no venue has quoted an asset
+	ErrNoTicker = errors.New("cex: no ticker")
+	// ErrStale is returned when every quote for an asset is older than the board's max age
+	ErrStale = errors.New("cex: ticker is stale")
+)
+
+// Ticker is a venue's top of book for one asset, quoted in USD (or a USD
+// stablecoin where the venue has no USD market)
+type Ticker struct {
+	Venue     string    `json:"venue"`
+	Asset     string    `json:"asset"`
+	Symbol    string    `json:"symbol"`
+	Bid       float64   `json:"bid"`
+	Ask       float64   `json:"ask"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Mid returns the midpoint of bid and ask
+func (t Ticker) Mid() float64 {
+	return (t.Bid + t.Ask) / 2
+}
+
+// Reference is the cross-venue price of an asset
+type Reference struct {
+	Asset     string    `json:"asset"`
+	PriceE8   uint64    `json:"priceE8"`
+	Venues    []string  `json:"venues"`
+	UpdatedAt time.Time `json:"updatedAt"` // oldest quote used
+}
+
+// Price returns the reference price as a float
+func (r Reference) Price() float64 {
+	return float64(r.PriceE8) / 1e8
+}
+
+// Depeg is a stablecoin trading away from one dollar
+type Depeg struct {
+	Asset        string  `json:"asset"`
+	Price        float64 `json:"price"`
+	DeviationBps float64 `json:"deviationBps"`
+	ThresholdBps float64 `json:"thresholdBps"`
+	Venues       int     `json:"venues"`
+}
+
+// String describes the depeg for alerts
+func (d Depeg) String() string {
+	return fmt.Sprintf("%s at $%.4f (%+.0f bps, threshold %.0f bps, %d venues)", d.Asset, d.Price, d.DeviationBps, d.ThresholdBps, d.Venues)
+}
+
+// Board holds the latest ticker per venue and asset. It is read-only market
+// data: nothing here places orders.
+type Board struct {
+	maxAge time.Duration
+
+	mu      sync.RWMutex
+	tickers map[[2]string]Ticker // venue, asset
+	now     func() time.Time
+
+	price *metrics.GaugeVec
+}
+
+// NewBoard creates a board that ignores quotes older than maxAge; reg may be
+// nil to skip metrics
+func NewBoard(maxAge time.Duration, reg *metrics.Registry) *Board {
+	b := &Board{maxAge: maxAge, tickers: make(map[[2]string]Ticker), now: time.Now}
+	if reg != nil {
+		b.price = reg.Gauge("titan_cex_mid_usd", "CEX mid price in USD", "venue", "asset")
+	}
+	return b
+}
+
+// Set records a ticker
+func (b *Board) Set(t Ticker) {
+	t.Asset = strings.ToUpper(t.Asset)
+	b.mu.Lock()
+	b.tickers[[2]string{t.Venue, t.Asset}] = t
+	b.mu.Unlock()
+	if b.price != nil {
+		b.price.Set(t.Mid(), t.Venue, t.Asset)
+	}
+}
+
+// Reference returns the median mid across venues with a fresh quote
+func (b *Board) Reference(asset string) (Reference, error) {
+	asset = strings.ToUpper(asset)
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var mids []float64
+	ref := Reference{Asset: asset}
+	seen := false
+	for key, t := range b.tickers {
+		if key[1] != asset {
+			continue
+		}
+		seen = true
+		if b.maxAge > 0 && b.now().Sub(t.UpdatedAt) > b.maxAge {
+			continue
+		}
+		if t.Bid <= 0 || t.Ask <= 0 {
+			continue
+		}
+		mids = append(mids, t.Mid())
+		ref.Venues = append(ref.Venues, t.Venue)
+		if ref.UpdatedAt.IsZero() || t.UpdatedAt.Before(ref.UpdatedAt) {
+			ref.UpdatedAt = t.UpdatedAt
+		}
+	}
+	if len(mids) == 0 {
+		if seen {
+			return Reference{}, fmt.Errorf("%w for %s", ErrStale, asset)
+		}
+		return Reference{}, fmt.Errorf("%w for %s", ErrNoTicker, asset)
+	}
+	sort.Float64s(mids)
+	sort.Strings(ref.Venues)
+	median := mids[len(mids)/2]
+	if len(mids)%2 == 0 {
+		median = (mids[len(mids)/2-1] + median) / 2
+	}
+	ref.PriceE8 = uint64(math.Round(median * 1e8))
+	return ref, nil
+}
+
+// DeviationBps compares an on-chain price to the reference, positive when
+// the DEX is above the CEX
+func (b *Board) DeviationBps(asset string, dexPriceE8 uint64) (float64, error) {
+	ref, err := b.Reference(asset)
+	if err != nil {
+		return 0, err
+	}
+	if ref.PriceE8 == 0 {
+		return 0, fmt.Errorf("%w for %s", ErrNoTicker, asset)
+	}
+	return (float64(dexPriceE8) - float64(ref.PriceE8)) / float64(ref.PriceE8) * 10000, nil
+}
+
+// Feature returns the DEX-vs-CEX deviation as an unweighted score
+// component, so it is recorded alongside a score without moving it
+func (b *Board) Feature(asset string, dexPriceE8 uint64) (opportunity.ScoreComponent, error) {
+	dev, err := b.DeviationBps(asset, dexPriceE8)
+	if err != nil {
+		return opportunity.ScoreComponent{}, err
+	}
+	return opportunity.ScoreComponent{Name: "cex_deviation_bps_" + strings.ToLower(asset), Value: dev}, nil
+}
+
+// Depegs returns the stablecoins whose reference price is more than
+// thresholdBps from one dollar; assets without a fresh quote are skipped
+func (b *Board) Depegs(stables []string, thresholdBps float64) []Depeg {
+	var out []Depeg
+	for _, asset := range stables {
+		ref, err := b.Reference(asset)
+		if err != nil {
+			continue
+		}
+		dev := (ref.Price() - 1) * 10000
+		if math.Abs(dev) > thresholdBps {
+			out = append(out, Depeg{Asset: ref.Asset, Price: ref.Price(), DeviationBps: dev, ThresholdBps: thresholdBps, Venues: len(ref.Venues)})
+		}
+	}
+	return out
+}
+
+// Tickers returns every recorded ticker, sorted by asset then venue
+func (b *Board) Tickers() []Ticker {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	out := make([]Ticker, 0, len(b.tickers))
+	for _, t := range b.tickers {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Asset != out[j].Asset {
+			return out[i].Asset < out[j].Asset
+		}
+		return out[i].Venue < out[j].Venue
+	})
+	return out
+}
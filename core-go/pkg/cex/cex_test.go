@@ -0,0 +1,115 @@
+package cex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestBoardReferenceAndStaleness(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	board := NewBoard(30*time.Second, nil)
+	board.now = func() time.Time { return now }
+
+	board.Set(Ticker{Venue: "binance", Asset: "eth", Bid: 2999, Ask: 3001, UpdatedAt: now})
+	board.Set(Ticker{Venue: "coinbase", Asset: "ETH", Bid: 3009, Ask: 3011, UpdatedAt: now.Add(-10 * time.Second)})
+	board.Set(Ticker{Venue: "kraken", Asset: "ETH", Bid: 1, Ask: 1, UpdatedAt: now.Add(-time.Minute)})
+
+	ref, err := board.Reference("ETH")
+	if err != nil {
+		t.Fatalf("Reference failed: %v", err)
+	}
+	if ref.PriceE8 != 3005_00000000 || len(ref.Venues) != 2 {
+		t.Errorf("Expected $3005 from two fresh venues, got %d from %v", ref.PriceE8, ref.Venues)
+	}
+	if dev, _ := board.DeviationBps("ETH", 3035_00000000); dev < 99 || dev > 101 {
+		t.Errorf("Expected ~100 bps DEX premium, got %.2f", dev)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := board.Reference("ETH"); !errors.Is(err, ErrStale) {
+		t.Errorf("Expected ErrStale, got %v", err)
+	}
+	if _, err := board.Reference("BTC"); !errors.Is(err, ErrNoTicker) {
+		t.Errorf("Expected ErrNoTicker, got %v", err)
+	}
+}
+
+func TestBoardDepegs(t *testing.T) {
+	board := NewBoard(0, nil)
+	board.Set(Ticker{Venue: "binance", Asset: "USDC", Bid: 0.9999, Ask: 1.0001, UpdatedAt: time.Now()})
+	board.Set(Ticker{Venue: "coinbase", Asset: "DAI", Bid: 0.97, Ask: 0.972, UpdatedAt: time.Now()})
+
+	depegs := board.Depegs([]string{"USDC", "DAI", "USDT"}, 50)
+	if len(depegs) != 1 || depegs[0].Asset != "DAI" || depegs[0].DeviationBps > -280 {
+		t.Errorf("Expected DAI ~290 bps under peg, got %+v", depegs)
+	}
+}
+
+func TestFeedsParse(t *testing.T) {
+	binance := NewBinanceFeed("wss://example", []string{"ETH", "USDT"})
+	if got := binance.URL(); got != "wss://example/stream?streams=ethusdt@bookTicker" {
+		t.Errorf("Expected one ETH stream, got %s", got)
+	}
+	tickers, err := binance.Parse([]byte(`{"stream":"ethusdt@bookTicker","data":{"u":1,"s":"ETHUSDT","b":"3000.10","B":"1","a":"3000.20","A":"2"}}`))
+	if err != nil || len(tickers) != 1 || tickers[0].Asset != "ETH" || tickers[0].Bid != 3000.10 {
+		t.Errorf("Expected ETH ticker, got %+v, %v", tickers, err)
+	}
+
+	coinbase := NewCoinbaseFeed("", []string{"ETH", "USDC"})
+	sub, _ := coinbase.Subscription()
+	if !strings.Contains(string(sub), `"product_ids":["ETH-USD"]`) {
+		t.Errorf("Expected ETH-USD subscription only, got %s", sub)
+	}
+	if tickers, err := coinbase.Parse([]byte(`{"type":"subscriptions","channels":[]}`)); err != nil || len(tickers) != 0 {
+		t.Errorf("Expected control message to be skipped, got %+v, %v", tickers, err)
+	}
+	tickers, err = coinbase.Parse([]byte(`{"type":"ticker","product_id":"ETH-USD","price":"3001","best_bid":"3000.5","best_ask":"3001.5"}`))
+	if err != nil || len(tickers) != 1 || tickers[0].Mid() != 3001 {
+		t.Errorf("Expected ETH mid 3001, got %+v, %v", tickers, err)
+	}
+	if _, err := coinbase.Parse([]byte(`{"type":"error","message":"Failed to subscribe","reason":"bad product"}`)); err == nil {
+		t.Error("Expected error message to fail the connection")
+	}
+}
+
+func TestStreamReconnects(t *testing.T) {
+	var conns atomic.Int32
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// Each connection sends one quote and drops, as a flaky venue would
+		bid := "3000"
+		if conns.Add(1) > 1 {
+			bid = "3100"
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"data":{"s":"ETHUSDT","b":"`+bid+`","a":"`+bid+`"}}`))
+	}))
+	defer srv.Close()
+
+	board := NewBoard(time.Minute, nil)
+	stream := NewStream(NewBinanceFeed("ws"+strings.TrimPrefix(srv.URL, "http"), []string{"ETH"}), board)
+	stream.Backoff = time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go stream.Run(ctx)
+
+	for ctx.Err() == nil {
+		if ref, err := board.Reference("ETH"); err == nil && ref.Price() == 3100 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Errorf("Expected the reconnected stream to update ETH, got %d connections", conns.Load())
+}
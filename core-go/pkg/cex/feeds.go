@@ -0,0 +1,191 @@
+package cex
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+)
+
+// Feed describes one venue's public ticker websocket
+type Feed interface {
+	Name() string
+	URL() string
+	// Subscription is sent after connecting; nil when the URL selects the streams
+	Subscription() ([]byte, error)
+	// Parse returns the tickers in one message, none for control messages
+	Parse(msg []byte) ([]Ticker, error)
+}
+
+// BinanceFeed reads Binance spot book tickers. Binance lists few USD pairs,
+// so assets are quoted against USDT.
+type BinanceFeed struct {
+	baseURL string
+	symbols map[string]string // venue symbol to asset
+	now     func() time.Time
+}
+
+// NewBinanceFeed creates a feed for assets; an empty baseURL uses the public stream
+func NewBinanceFeed(baseURL string, assets []string) *BinanceFeed {
+	if baseURL == "" {
+		baseURL = "wss://stream.binance.com:9443"
+	}
+	f := &BinanceFeed{baseURL: strings.TrimRight(baseURL, "/"), symbols: make(map[string]string), now: time.Now}
+	for _, asset := range assets {
+		asset = strings.ToUpper(asset)
+		if asset == "USDT" {
+			continue // the quote currency itself
+		}
+		f.symbols[asset+"USDT"] = asset
+	}
+	return f
+}
+
+// Name returns the venue name
+func (f *BinanceFeed) Name() string { return "binance" }
+
+// URL returns the combined-stream URL for every symbol
+func (f *BinanceFeed) URL() string {
+	streams := make([]string, 0, len(f.symbols))
+	for symbol := range f.symbols {
+		streams = append(streams, strings.ToLower(symbol)+"@bookTicker")
+	}
+	sort.Strings(streams)
+	return f.baseURL + "/stream?streams=" + strings.Join(streams, "/")
+}
+
+// Subscription is nil; the URL selects the streams
+func (f *BinanceFeed) Subscription() ([]byte, error) { return nil, nil }
+
+type binanceMessage struct {
+	Stream string `json:"stream"`
+	Data   struct {
+		Symbol string `json:"s"`
+		Bid    string `json:"b"`
+		Ask    string `json:"a"`
+		// Quantities are decoded only so their upper-case keys don't
+		// match Bid and Ask under encoding/json's case folding
+		BidQty string `json:"B"`
+		AskQty string `json:"A"`
+	} `json:"data"`
+}
+
+// Parse reads a combined-stream bookTicker message
+func (f *BinanceFeed) Parse(msg []byte) ([]Ticker, error) {
+	var m binanceMessage
+	if err := json.Unmarshal(msg, &m); err != nil {
+		return nil, err
+	}
+	asset, ok := f.symbols[m.Data.Symbol]
+	if !ok {
+		return nil, nil
+	}
+	return quote(f.Name(), asset, m.Data.Symbol, m.Data.Bid, m.Data.Ask, f.now())
+}
+
+// CoinbaseFeed reads Coinbase Exchange ticker messages for USD products
+type CoinbaseFeed struct {
+	url      string
+	products map[string]string // product ID to asset
+	now      func() time.Time
+}
+
+// NewCoinbaseFeed creates a feed for assets; an empty url uses the public feed
+func NewCoinbaseFeed(url string, assets []string) *CoinbaseFeed {
+	if url == "" {
+		url = "wss://ws-feed.exchange.coinbase.com"
+	}
+	f := &CoinbaseFeed{url: url, products: make(map[string]string), now: time.Now}
+	for _, asset := range assets {
+		asset = strings.ToUpper(asset)
+		if asset == "USDC" {
+			continue // Coinbase treats USDC as USD and lists no USDC-USD book
+		}
+		f.products[asset+"-USD"] = asset
+	}
+	return f
+}
+
+// Name returns the venue name
+func (f *CoinbaseFeed) Name() string { return "coinbase" }
+
+// URL returns the feed URL
+func (f *CoinbaseFeed) URL() string { return f.url }
+
+// Subscription subscribes to the ticker channel of every product
+func (f *CoinbaseFeed) Subscription() ([]byte, error) {
+	ids := make([]string, 0, len(f.products))
+	for id := range f.products {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return json.Marshal(map[string]interface{}{
+		"type":        "subscribe",
+		"product_ids": ids,
+		"channels":    []string{"ticker"},
+	})
+}
+
+type coinbaseMessage struct {
+	Type      string `json:"type"`
+	ProductID string `json:"product_id"`
+	BestBid   string `json:"best_bid"`
+	BestAsk   string `json:"best_ask"`
+	Message   string `json:"message"`
+	Reason    string `json:"reason"`
+}
+
+// Parse reads a ticker message; subscription acknowledgements and
+// heartbeats carry no prices, and error messages fail the connection
+func (f *CoinbaseFeed) Parse(msg []byte) ([]Ticker, error) {
+	var m coinbaseMessage
+	if err := json.Unmarshal(msg, &m); err != nil {
+		return nil, err
+	}
+	switch m.Type {
+	case "ticker":
+	case "error":
+		return nil, fmt.Errorf("coinbase: %s: %s", m.Message, m.Reason)
+	default:
+		return nil, nil
+	}
+	asset, ok := f.products[m.ProductID]
+	if !ok {
+		return nil, nil
+	}
+	return quote(f.Name(), asset, m.ProductID, m.BestBid, m.BestAsk, f.now())
+}
+
+// quote parses a bid/ask pair into a ticker stamped with the local receive
+// time, so staleness does not depend on the venue's clock
+func quote(venue, asset, symbol, bid, ask string, at time.Time) ([]Ticker, error) {
+	b, err := strconv.ParseFloat(bid, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s bid: %w", venue, symbol, err)
+	}
+	a, err := strconv.ParseFloat(ask, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s ask: %w", venue, symbol, err)
+	}
+	return []Ticker{{Venue: venue, Asset: asset, Symbol: symbol, Bid: b, Ask: a, UpdatedAt: at}}, nil
+}
+
+// FeedsFromConfig creates a feed per configured venue
+func FeedsFromConfig(cfg *config.CEXConfig) ([]Feed, error) {
+	var feeds []Feed
+	for _, venue := range cfg.Venues {
+		switch strings.ToLower(venue) {
+		case "binance":
+			feeds = append(feeds, NewBinanceFeed("", cfg.Assets))
+		case "coinbase":
+			feeds = append(feeds, NewCoinbaseFeed("", cfg.Assets))
+		default:
+			return nil, fmt.Errorf("cex: unknown venue %q", venue)
+		}
+	}
+	return feeds, nil
+}
@@ -0,0 +1,101 @@
+package cex
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Stream keeps a feed's websocket connected and copies its tickers to a board
+type Stream struct {
+	feed  Feed
+	board *Board
+
+	// Backoff is the first reconnect delay, doubled after each failed
+	// connection up to MaxBackoff and reset once data flows again
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// IdleTimeout drops a connection that has been silent this long; venues
+	// sometimes stop sending without closing the socket
+	IdleTimeout time.Duration
+
+	dialer *websocket.Dialer
+}
+
+// NewStream creates a stream feeding board
+func NewStream(feed Feed, board *Board) *Stream {
+	return &Stream{
+		feed:        feed,
+		board:       board,
+		Backoff:     time.Second,
+		MaxBackoff:  time.Minute,
+		IdleTimeout: 30 * time.Second,
+		dialer:      &websocket.Dialer{HandshakeTimeout: 10 * time.Second},
+	}
+}
+
+// Run reconnects until ctx is cancelled
+func (s *Stream) Run(ctx context.Context) error {
+	backoff := s.Backoff
+	for {
+		received, err := s.connect(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if received {
+			backoff = s.Backoff
+		}
+		log.Printf("⚠️ %s ticker stream: %v; reconnecting in %s", s.feed.Name(), err, backoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > s.MaxBackoff {
+			backoff = s.MaxBackoff
+		}
+	}
+}
+
+// connect reads one connection until it fails, reporting whether any ticker arrived
+func (s *Stream) connect(ctx context.Context) (bool, error) {
+	conn, _, err := s.dialer.DialContext(ctx, s.feed.URL(), nil)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	sub, err := s.feed.Subscription()
+	if err != nil {
+		return false, err
+	}
+	if sub != nil {
+		if err := conn.WriteMessage(websocket.TextMessage, sub); err != nil {
+			return false, fmt.Errorf("subscribe: %w", err)
+		}
+	}
+
+	received := false
+	for {
+		if s.IdleTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(s.IdleTimeout))
+		}
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return received, err
+		}
+		tickers, err := s.feed.Parse(msg)
+		if err != nil {
+			return received, err
+		}
+		for _, t := range tickers {
+			s.board.Set(t)
+			received = true
+		}
+	}
+}
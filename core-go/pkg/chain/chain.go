@@ -0,0 +1,68 @@
+package chain
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ChainReader is the read-only node access the core needs: heads, eth_call
+// and contract state. *ethclient.Client and titantest.Backend satisfy it.
+type ChainReader interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error)
+	StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error)
+}
+
+// TxReader looks up submitted transactions and confirmed account nonces
+type TxReader interface {
+	TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+// ChainWriter is the node access needed to price, nonce and send transactions
+type ChainWriter interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// FeeHistoryReader reads eth_feeHistory; *ethclient.Client satisfies it,
+// though not every provider serves the method
+type FeeHistoryReader interface {
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// Client is a full node connection
+type Client interface {
+	ChainReader
+	TxReader
+	ChainWriter
+	Close()
+}
+
+// Dialer opens a node connection for an RPC URL
+type Dialer func(rpcURL string) (Client, error)
+
+var (
+	_ Client           = (*ethclient.Client)(nil)
+	_ FeeHistoryReader = (*ethclient.Client)(nil)
+)
+
+// Dial connects to rpcURL with go-ethereum's ethclient, the default Client
+func Dial(rpcURL string) (Client, error) {
+	client, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
@@ -0,0 +1,203 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// ErrInjected is the error injected calls and dropped subscriptions fail with
+var ErrInjected = errors.New("chaos: injected fault")
+
+// ErrNoSubscriptions is returned subscribing through a client without
+// subscription support
+var ErrNoSubscriptions = errors.New("chaos: client does not support subscriptions")
+
+// Faults describes what to inject into node connections
+type Faults struct {
+	Latency   time.Duration   // added to every affected call
+	Jitter    time.Duration   // random extra latency up to this bound
+	ErrorRate float64         // share of affected calls that fail
+	DropRate  float64         // chance each delivered head drops its subscription
+	Methods   map[string]bool // JSON-RPC methods affected, empty for all
+}
+
+// HeadSubscriber is a connection that streams new heads, as
+// *ethclient.Client and titantest.Backend do
+type HeadSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// Injector wraps node connections so their calls are delayed and failed,
+// and their subscriptions dropped, at the configured rates. It is safe for
+// concurrent use.
+type Injector struct {
+	faults Faults
+
+	mu     sync.Mutex
+	rand   *rand.Rand
+	counts *metrics.CounterVec
+}
+
+// New creates an injector drawing faults from seed, so a failing test can
+// be replayed; reg may be nil
+func New(f Faults, seed int64, reg *metrics.Registry) *Injector {
+	i := &Injector{faults: f, rand: rand.New(rand.NewSource(seed))}
+	if reg != nil {
+		i.counts = reg.Counter("titan_chaos_faults_total", "Injected faults by kind and method", "kind", "method")
+	}
+	return i
+}
+
+// FromConfig creates an injector from configuration, nil when no fault is
+// configured
+func FromConfig(cfg *config.ChaosConfig, reg *metrics.Registry) *Injector {
+	if !cfg.Enabled() {
+		return nil
+	}
+	f := Faults{
+		Latency:   time.Duration(cfg.LatencyMs) * time.Millisecond,
+		Jitter:    time.Duration(cfg.JitterMs) * time.Millisecond,
+		ErrorRate: cfg.ErrorRate,
+		DropRate:  cfg.DropRate,
+	}
+	if len(cfg.Methods) > 0 {
+		f.Methods = make(map[string]bool, len(cfg.Methods))
+		for _, m := range cfg.Methods {
+			f.Methods[m] = true
+		}
+	}
+	log.Printf("🐒 Chaos enabled: latency %s (+%s jitter), %.0f%% errors, %.0f%% head drops", f.Latency, f.Jitter, f.ErrorRate*100, f.DropRate*100)
+	return New(f, time.Now().UnixNano(), reg)
+}
+
+// Dialer wraps dial so every connection it opens injects faults; a nil
+// injector returns dial unchanged
+func (i *Injector) Dialer(dial chain.Dialer) chain.Dialer {
+	if i == nil {
+		return dial
+	}
+	return func(rpcURL string) (chain.Client, error) {
+		c, err := dial(rpcURL)
+		if err != nil {
+			return nil, err
+		}
+		return i.Wrap(c), nil
+	}
+}
+
+// Wrap returns c with faults injected
+func (i *Injector) Wrap(c chain.Client) chain.Client {
+	return &faultyClient{Client: c, inj: i}
+}
+
+// chance reports whether an event of probability p happens
+func (i *Injector) chance(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.rand.Float64() < p
+}
+
+// delay returns the latency to add to one call
+func (i *Injector) delay() time.Duration {
+	d := i.faults.Latency
+	if i.faults.Jitter > 0 {
+		i.mu.Lock()
+		d += time.Duration(i.rand.Int63n(int64(i.faults.Jitter) + 1))
+		i.mu.Unlock()
+	}
+	return d
+}
+
+func (i *Injector) count(kind, method string) {
+	if i.counts != nil {
+		i.counts.Inc(kind, method)
+	}
+}
+
+// inject delays a call to method and decides whether it fails
+func (i *Injector) inject(ctx context.Context, method string) error {
+	if len(i.faults.Methods) > 0 && !i.faults.Methods[method] {
+		return nil
+	}
+	if d := i.delay(); d > 0 {
+		i.count("latency", method)
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	if i.chance(i.faults.ErrorRate) {
+		i.count("error", method)
+		return fmt.Errorf("%s: %w", method, ErrInjected)
+	}
+	return nil
+}
+
+// droppedSubscription forwards heads from an upstream subscription until a
+// drop is injected, then ends with ErrInjected
+type droppedSubscription struct {
+	upstream ethereum.Subscription
+	err      chan error
+	quit     chan struct{}
+	once     sync.Once
+}
+
+func (s *droppedSubscription) Err() <-chan error { return s.err }
+
+func (s *droppedSubscription) Unsubscribe() {
+	s.once.Do(func() {
+		close(s.quit)
+		s.upstream.Unsubscribe()
+	})
+}
+
+// subscribe relays heads from in to out, dropping the subscription at the
+// configured rate
+func (i *Injector) subscribe(upstream ethereum.Subscription, in <-chan *types.Header, out chan<- *types.Header) ethereum.Subscription {
+	sub := &droppedSubscription{upstream: upstream, err: make(chan error, 1), quit: make(chan struct{})}
+	go func() {
+		defer close(sub.err)
+		for {
+			select {
+			case <-sub.quit:
+				return
+			case err, ok := <-upstream.Err():
+				if ok && err != nil {
+					sub.err <- err
+				}
+				return
+			case h := <-in:
+				if i.chance(i.faults.DropRate) {
+					i.count("drop", "eth_subscribe")
+					upstream.Unsubscribe()
+					sub.err <- ErrInjected
+					return
+				}
+				select {
+				case out <- h:
+				case <-sub.quit:
+					return
+				}
+			}
+		}
+	}()
+	return sub
+}
@@ -0,0 +1,145 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+)
+
+// backendClient completes the in-memory chain into a full node connection
+type backendClient struct {
+	*titantest.Backend
+	chain.TxReader
+	chain.ChainWriter
+}
+
+func wrap(t *testing.T, f Faults, reg *metrics.Registry) (chain.Client, *titantest.Backend) {
+	t.Helper()
+	backend := titantest.NewBackend(137)
+	dial := New(f, 1, reg).Dialer(func(string) (chain.Client, error) {
+		return backendClient{Backend: backend}, nil
+	})
+	client, err := dial("memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, backend
+}
+
+func TestInjectedErrors(t *testing.T) {
+	reg := metrics.NewRegistry()
+	client, _ := wrap(t, Faults{ErrorRate: 0.5, Methods: map[string]bool{"eth_blockNumber": true}}, reg)
+	ctx := context.Background()
+
+	failed := 0
+	for i := 0; i < 200; i++ {
+		if _, err := client.BlockNumber(ctx); err != nil {
+			if !errors.Is(err, ErrInjected) {
+				t.Fatalf("Expected ErrInjected, got %v", err)
+			}
+			failed++
+		}
+	}
+	if failed < 60 || failed > 140 {
+		t.Errorf("Expected about half the calls failed, got %d of 200", failed)
+	}
+	if got := reg.Value("titan_chaos_faults_total", "error", "eth_blockNumber"); got != float64(failed) {
+		t.Errorf("Expected %d faults counted, got %v", failed, got)
+	}
+	for i := 0; i < 50; i++ {
+		if _, err := client.ChainID(ctx); err != nil {
+			t.Fatalf("Expected methods outside the list untouched, got %v", err)
+		}
+	}
+}
+
+func TestInjectedLatency(t *testing.T) {
+	client, _ := wrap(t, Faults{Latency: 30 * time.Millisecond}, nil)
+
+	start := time.Now()
+	if _, err := client.BlockNumber(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Expected the call delayed 30ms, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := client.BlockNumber(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the delay to honour the deadline, got %v", err)
+	}
+}
+
+func TestDroppedSubscription(t *testing.T) {
+	client, backend := wrap(t, Faults{DropRate: 1}, nil)
+	heads := make(chan *types.Header, 1)
+	sub, err := client.(HeadSubscriber).SubscribeNewHead(context.Background(), heads)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go backend.Mine(1, 2*time.Second)
+
+	select {
+	case err := <-sub.Err():
+		if !errors.Is(err, ErrInjected) {
+			t.Errorf("Expected the subscription dropped with ErrInjected, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the subscription dropped")
+	}
+	if len(heads) != 0 {
+		t.Errorf("Expected the head swallowed by the drop")
+	}
+	sub.Unsubscribe()
+}
+
+func TestSubscriptionPassesHeads(t *testing.T) {
+	client, backend := wrap(t, Faults{Latency: time.Millisecond}, nil)
+	heads := make(chan *types.Header, 1)
+	sub, err := client.(HeadSubscriber).SubscribeNewHead(context.Background(), heads)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	go backend.Mine(1, 2*time.Second)
+
+	select {
+	case h := <-heads:
+		if h.Number.Uint64() != 1 {
+			t.Errorf("Expected block 1, got %d", h.Number.Uint64())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the head delivered")
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	if FromConfig(&config.ChaosConfig{}, nil) != nil {
+		t.Errorf("Expected no injector without faults")
+	}
+	dial := func(string) (chain.Client, error) { return nil, nil }
+	var none *Injector
+	if none.Dialer(dial) == nil {
+		t.Errorf("Expected a nil injector to keep the dialer")
+	}
+
+	cfg := &config.ChaosConfig{ErrorRate: 0.1}
+	if err := cfg.Validate(config.EnvProduction); err == nil {
+		t.Errorf("Expected faults refused in production")
+	}
+	if err := cfg.Validate("staging"); err != nil {
+		t.Errorf("Expected faults allowed in staging, got %v", err)
+	}
+	if err := (&config.ChaosConfig{DropRate: 2}).Validate("staging"); err == nil {
+		t.Errorf("Expected a rate above 1 rejected")
+	}
+}
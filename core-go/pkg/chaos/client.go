@@ -0,0 +1,129 @@
+package chaos
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+)
+
+// faultyClient injects faults before passing each call through
+type faultyClient struct {
+	chain.Client
+	inj *Injector
+}
+
+var _ chain.Client = (*faultyClient)(nil)
+
+func (c *faultyClient) ChainID(ctx context.Context) (*big.Int, error) {
+	if err := c.inj.inject(ctx, "eth_chainId"); err != nil {
+		return nil, err
+	}
+	return c.Client.ChainID(ctx)
+}
+
+func (c *faultyClient) BlockNumber(ctx context.Context) (uint64, error) {
+	if err := c.inj.inject(ctx, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return c.Client.BlockNumber(ctx)
+}
+
+func (c *faultyClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if err := c.inj.inject(ctx, "eth_getBlockByNumber"); err != nil {
+		return nil, err
+	}
+	return c.Client.HeaderByNumber(ctx, number)
+}
+
+func (c *faultyClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if err := c.inj.inject(ctx, "eth_call"); err != nil {
+		return nil, err
+	}
+	return c.Client.CallContract(ctx, msg, blockNumber)
+}
+
+func (c *faultyClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if err := c.inj.inject(ctx, "eth_getCode"); err != nil {
+		return nil, err
+	}
+	return c.Client.CodeAt(ctx, contract, blockNumber)
+}
+
+func (c *faultyClient) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	if err := c.inj.inject(ctx, "eth_getStorageAt"); err != nil {
+		return nil, err
+	}
+	return c.Client.StorageAt(ctx, account, key, blockNumber)
+}
+
+func (c *faultyClient) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if err := c.inj.inject(ctx, "eth_getTransactionReceipt"); err != nil {
+		return nil, err
+	}
+	return c.Client.TransactionReceipt(ctx, hash)
+}
+
+func (c *faultyClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if err := c.inj.inject(ctx, "eth_getTransactionByHash"); err != nil {
+		return nil, false, err
+	}
+	return c.Client.TransactionByHash(ctx, hash)
+}
+
+func (c *faultyClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	if err := c.inj.inject(ctx, "eth_getTransactionCount"); err != nil {
+		return 0, err
+	}
+	return c.Client.NonceAt(ctx, account, blockNumber)
+}
+
+func (c *faultyClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	if err := c.inj.inject(ctx, "eth_getTransactionCount"); err != nil {
+		return 0, err
+	}
+	return c.Client.PendingNonceAt(ctx, account)
+}
+
+func (c *faultyClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	if err := c.inj.inject(ctx, "eth_maxPriorityFeePerGas"); err != nil {
+		return nil, err
+	}
+	return c.Client.SuggestGasTipCap(ctx)
+}
+
+func (c *faultyClient) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	if err := c.inj.inject(ctx, "eth_estimateGas"); err != nil {
+		return 0, err
+	}
+	return c.Client.EstimateGas(ctx, call)
+}
+
+func (c *faultyClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := c.inj.inject(ctx, "eth_sendRawTransaction"); err != nil {
+		return err
+	}
+	return c.Client.SendTransaction(ctx, tx)
+}
+
+// SubscribeNewHead subscribes through the wrapped client, which must be a
+// HeadSubscriber, and drops the subscription at the configured rate
+func (c *faultyClient) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	subscriber, ok := c.Client.(HeadSubscriber)
+	if !ok {
+		return nil, ErrNoSubscriptions
+	}
+	if err := c.inj.inject(ctx, "eth_subscribe"); err != nil {
+		return nil, err
+	}
+	in := make(chan *types.Header)
+	upstream, err := subscriber.SubscribeNewHead(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return c.inj.subscribe(upstream, in, ch), nil
+}
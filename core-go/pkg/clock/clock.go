@@ -0,0 +1,215 @@
+package clock
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// ntpEpoch is the NTP era 0 epoch, 1900-01-01 UTC
+var ntpEpoch = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// queryTimeout bounds one server's answer
+const queryTimeout = 5 * time.Second
+
+// Query asks an NTP server how far this host's wall clock is off: positive
+// when the host is behind. The round trip is timed on the monotonic clock,
+// so a clock step mid-query doesn't skew the answer.
+func Query(ctx context.Context, server string) (time.Duration, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", server)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(queryTimeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	conn.SetDeadline(deadline)
+
+	// LI 0, version 4, mode 3 (client); the server echoes our transmit
+	// timestamp as the originate timestamp
+	req := make([]byte, 48)
+	req[0] = 0x23
+	sent := time.Now()
+	binary.BigEndian.PutUint64(req[40:], toNTP(sent))
+	if _, err := conn.Write(req); err != nil {
+		return 0, err
+	}
+	resp := make([]byte, 48)
+	n, err := conn.Read(resp)
+	if err != nil {
+		return 0, err
+	}
+	received := sent.Add(time.Since(sent))
+	if n < 48 {
+		return 0, fmt.Errorf("ntp %s: short answer of %d bytes", server, n)
+	}
+	if mode := resp[0] & 7; mode != 4 {
+		return 0, fmt.Errorf("ntp %s: answer in mode %d", server, mode)
+	}
+	if stratum := resp[1]; stratum == 0 || stratum > 15 {
+		return 0, fmt.Errorf("ntp %s: unsynchronized or refused (stratum %d)", server, stratum)
+	}
+	if !bytes.Equal(resp[24:32], req[40:48]) {
+		return 0, fmt.Errorf("ntp %s: answer to another request", server)
+	}
+	rx := fromNTP(binary.BigEndian.Uint64(resp[32:]))
+	tx := fromNTP(binary.BigEndian.Uint64(resp[40:]))
+	return (rx.Sub(sent) + tx.Sub(received)) / 2, nil
+}
+
+// toNTP converts t to a 64-bit NTP timestamp
+func toNTP(t time.Time) uint64 {
+	d := t.Sub(ntpEpoch)
+	secs := uint64(d / time.Second)
+	frac := uint64(d%time.Second) << 32 / uint64(time.Second)
+	return secs<<32 | frac
+}
+
+// fromNTP converts a 64-bit NTP timestamp to a time
+func fromNTP(ts uint64) time.Time {
+	secs, frac := ts>>32, ts&0xffffffff
+	return ntpEpoch.Add(time.Duration(secs)*time.Second + time.Duration(frac*uint64(time.Second)>>32))
+}
+
+// Status is the host clock's measured offset
+type Status struct {
+	OffsetMs float64   `json:"offsetMs"` // positive when the host is behind
+	Drifted  bool      `json:"drifted"`  // the offset is beyond the threshold
+	Servers  int       `json:"servers"`  // servers that answered
+	Checked  time.Time `json:"checked"`
+}
+
+// Monitor measures the host's wall clock against NTP servers. Deadlines and
+// TTLs run on the monotonic clock and don't care, but everything compared
+// with a chain or venue timestamp does: quote freshness, price feed age,
+// sequencer lag and webhook signatures all silently break on a drifting VM.
+// A nil monitor measures nothing. It is safe for concurrent use.
+type Monitor struct {
+	servers  []string
+	maxDrift time.Duration
+	query    func(ctx context.Context, server string) (time.Duration, error)
+
+	// OnChange, when set, is called when the offset crosses the threshold
+	// either way
+	OnChange func(s Status)
+
+	mu     sync.Mutex
+	status Status
+	seen   bool
+
+	offset *metrics.GaugeVec
+}
+
+// New creates a monitor of servers flagging offsets beyond maxDrift; reg
+// may be nil
+func New(servers []string, maxDrift time.Duration, reg *metrics.Registry) *Monitor {
+	m := &Monitor{servers: servers, maxDrift: maxDrift, query: Query}
+	if reg != nil {
+		m.offset = reg.Gauge("titan_clock_offset_seconds", "Host wall clock offset from NTP, positive when behind")
+	}
+	return m
+}
+
+// FromConfig creates a monitor, or nil when it is off
+func FromConfig(cfg *config.ClockConfig, reg *metrics.Registry) *Monitor {
+	if cfg == nil || !cfg.Enabled || len(cfg.Servers) == 0 {
+		return nil
+	}
+	return New(cfg.Servers, time.Duration(cfg.MaxDriftMs)*time.Millisecond, reg)
+}
+
+// Run polls every interval until ctx is done
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := m.Poll(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Clock drift check: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll queries every server and takes the median offset, so one bad server
+// can't raise the alarm. When none answers the last status is kept.
+func (m *Monitor) Poll(ctx context.Context) error {
+	var offsets []time.Duration
+	var failed []string
+	for _, server := range m.servers {
+		offset, err := m.query(ctx, server)
+		if err != nil {
+			failed = append(failed, err.Error())
+			continue
+		}
+		offsets = append(offsets, offset)
+	}
+	if len(offsets) == 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	offset := offsets[len(offsets)/2]
+	if len(offsets)%2 == 0 {
+		offset = (offsets[len(offsets)/2-1] + offset) / 2
+	}
+	drift := offset
+	if drift < 0 {
+		drift = -drift
+	}
+	s := Status{
+		OffsetMs: float64(offset) / float64(time.Millisecond),
+		Drifted:  drift > m.maxDrift,
+		Servers:  len(offsets),
+		Checked:  time.Now(),
+	}
+
+	m.mu.Lock()
+	prev, seen := m.status, m.seen
+	m.status, m.seen = s, true
+	m.mu.Unlock()
+	if m.offset != nil {
+		m.offset.Set(offset.Seconds())
+	}
+	if m.OnChange != nil && prev.Drifted != s.Drifted && (seen || s.Drifted) {
+		m.OnChange(s)
+	}
+	return nil
+}
+
+// Status returns the last measurement
+func (m *Monitor) Status() Status {
+	if m == nil {
+		return Status{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+// MaxDrift is the threshold past which the clock counts as drifted
+func (m *Monitor) MaxDrift() time.Duration {
+	if m == nil {
+		return 0
+	}
+	return m.maxDrift
+}
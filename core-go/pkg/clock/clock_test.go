@@ -0,0 +1,125 @@
+package clock
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// ntpServer answers one request with a clock ahead of the host by skew,
+// at the given stratum
+func ntpServer(t *testing.T, skew time.Duration, stratum byte) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		req := make([]byte, 48)
+		_, addr, err := conn.ReadFrom(req)
+		if err != nil {
+			return
+		}
+		resp := make([]byte, 48)
+		resp[0], resp[1] = 0x24, stratum // version 4, mode 4 (server)
+		copy(resp[24:32], req[40:48])
+		now := toNTP(time.Now().Add(skew))
+		binary.BigEndian.PutUint64(resp[32:], now)
+		binary.BigEndian.PutUint64(resp[40:], now)
+		conn.WriteTo(resp, addr)
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestQuery(t *testing.T) {
+	offset, err := Query(context.Background(), ntpServer(t, 2*time.Second, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset < 1900*time.Millisecond || offset > 2100*time.Millisecond {
+		t.Errorf("Expected the host about 2s behind, got %s", offset)
+	}
+	if _, err := Query(context.Background(), ntpServer(t, 0, 0)); err == nil || !strings.Contains(err.Error(), "stratum 0") {
+		t.Errorf("Expected a kiss-of-death answer refused, got %v", err)
+	}
+}
+
+func TestNTPTimestampRoundTrip(t *testing.T) {
+	at := time.Date(2026, 10, 16, 12, 0, 0, 250_000_000, time.UTC)
+	if got := fromNTP(toNTP(at)); got.Sub(at).Abs() > time.Microsecond {
+		t.Errorf("Expected %s back, got %s", at, got)
+	}
+}
+
+func TestMonitorAlertsOnMedianDrift(t *testing.T) {
+	offsets := map[string]time.Duration{"a": 10 * time.Millisecond, "b": -5 * time.Millisecond, "c": 30 * time.Second}
+	reg := metrics.NewRegistry()
+	m := New([]string{"a", "b", "c"}, 500*time.Millisecond, reg)
+	m.query = func(_ context.Context, server string) (time.Duration, error) {
+		if offset, ok := offsets[server]; ok {
+			return offset, nil
+		}
+		return 0, errors.New("timeout")
+	}
+	var changes []Status
+	m.OnChange = func(s Status) { changes = append(changes, s) }
+
+	if err := m.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s := m.Status(); s.Drifted || s.OffsetMs != 10 || s.Servers != 3 {
+		t.Errorf("Expected one bad server outvoted, got %+v", s)
+	}
+	if got := reg.Value("titan_clock_offset_seconds"); got != 0.01 {
+		t.Errorf("Expected the offset gauge at 0.01, got %v", got)
+	}
+
+	offsets["a"], offsets["b"] = -2*time.Second, -time.Second
+	if err := m.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s := m.Status(); !s.Drifted || s.OffsetMs != -1000 {
+		t.Errorf("Expected the host 1s ahead flagged, got %+v", s)
+	}
+
+	offsets = nil
+	if err := m.Poll(context.Background()); err == nil {
+		t.Errorf("Expected an error when no server answers")
+	}
+	if !m.Status().Drifted {
+		t.Errorf("Expected the last status kept")
+	}
+
+	offsets = map[string]time.Duration{"a": 0, "b": time.Millisecond}
+	if err := m.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s := m.Status(); s.Drifted || s.OffsetMs != 0.5 {
+		t.Errorf("Expected the mean of the middle pair, got %+v", s)
+	}
+	if len(changes) != 2 || !changes[0].Drifted || changes[1].Drifted {
+		t.Errorf("Expected a drift then a recovery, got %+v", changes)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	if FromConfig(&config.ClockConfig{Enabled: false, Servers: []string{"pool.ntp.org"}}, nil) != nil {
+		t.Errorf("Expected no monitor when disabled")
+	}
+	m := FromConfig(&config.ClockConfig{Enabled: true, Servers: []string{"pool.ntp.org"}, MaxDriftMs: 250}, nil)
+	if m.MaxDrift() != 250*time.Millisecond {
+		t.Errorf("Expected a 250ms threshold, got %s", m.MaxDrift())
+	}
+	var nilMonitor *Monitor
+	if nilMonitor.Status().Drifted {
+		t.Errorf("Expected a nil monitor never drifted")
+	}
+}
@@ -0,0 +1,245 @@
+package commander
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/simulation"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// minFloorUnits is the minimum loan size in whole token units
+const minFloorUnits = 500
+
+// TitanCommander handles loan optimization and risk management
+type TitanCommander struct {
+	chainID            uint64
+	provider           chain.ChainReader
+	aavePool           *common.Address // nil sizes against the Balancer V3 Vault
+	
+	// Guardrails (Real Money Limits), percentages in basis points
+	MinLoanUSD         uint64
+	MaxTVLShareBps     uint64
+	MaxSlippageBps     uint64
+}
+
+// New creates a new TitanCommander instance
+func New(chainID uint64, provider chain.ChainReader) *TitanCommander {
+	return &TitanCommander{
+		chainID:        chainID,
+		provider:       provider,
+		MinLoanUSD:     10000, // Minimum trade size ($10k)
+		MaxTVLShareBps: 2000,  // Max % of pool to borrow (20%)
+		MaxSlippageBps: 50,    // 0.5% max slippage
+	}
+}
+
+// NewFromConfig creates a TitanCommander with guardrails taken from config;
+// nil guardrails keep the built-in limits
+func NewFromConfig(chainID uint64, provider chain.ChainReader, g *config.Guardrails) *TitanCommander {
+	tc := New(chainID, provider)
+	if g != nil {
+		tc.MinLoanUSD = g.MinLoanUSD
+		tc.MaxTVLShareBps = g.MaxTVLShareBps
+		tc.MaxSlippageBps = g.MaxSlippageBps
+	}
+	return tc
+}
+
+// SizeAgainstAave sizes loans against pool's Aave V3 reserves, for trades
+// borrowing from Aave rather than the Balancer V3 Vault
+func (tc *TitanCommander) SizeAgainstAave(pool common.Address) {
+	tc.aavePool = &pool
+}
+
+// lenderLiquidity returns what the configured lender can lend of token
+func (tc *TitanCommander) lenderLiquidity(token common.Address) (*big.Int, error) {
+	if tc.aavePool != nil {
+		return simulation.GetAaveTVL(tc.provider, token, *tc.aavePool)
+	}
+	return simulation.GetProviderTVL(tc.provider, token, common.HexToAddress(config.BalancerV3Vault))
+}
+
+// Guardrail names reported in sizing decisions
+const (
+	GuardrailDecimals    = "decimals"
+	GuardrailMaxTVLShare = "max_tvl_share"
+	GuardrailMinFloor    = "min_floor"
+)
+
+// SizingDecision records how a loan size was derived, for explainability
+type SizingDecision struct {
+	Token     common.Address
+	Decimals  uint8
+	Requested *big.Int
+	Amount    *big.Int // 0 means abort
+	Liquidity *big.Int // nil in paper mode
+	MaxCap    *big.Int // nil in paper mode
+	MinFloor  *big.Int
+	PaperMode bool
+	BoundBy   string // guardrail that set or blocked the size; empty if the request was used as-is
+}
+
+// Guardrails returns the evaluated guardrails for an opportunity explanation
+func (d *SizingDecision) Guardrails() []opportunity.Guardrail {
+	var out []opportunity.Guardrail
+	if d.MaxCap != nil {
+		out = append(out, opportunity.Guardrail{
+			Name:   GuardrailMaxTVLShare,
+			Limit:  formatRaw(d.Token, d.MaxCap, d.Decimals),
+			Actual: formatRaw(d.Token, d.Requested, d.Decimals),
+			Bound:  d.BoundBy == GuardrailMaxTVLShare,
+		})
+	}
+	if d.MinFloor != nil {
+		out = append(out, opportunity.Guardrail{
+			Name:   GuardrailMinFloor,
+			Limit:  formatRaw(d.Token, d.MinFloor, d.Decimals),
+			Actual: formatRaw(d.Token, d.Amount, d.Decimals),
+			Bound:  d.BoundBy == GuardrailMinFloor,
+		})
+	}
+	if d.BoundBy == GuardrailDecimals {
+		out = append(out, opportunity.Guardrail{
+			Name:   GuardrailDecimals,
+			Limit:  fmt.Sprintf("<= %d", units.MaxTokenDecimals),
+			Actual: fmt.Sprint(d.Decimals),
+			Bound:  true,
+		})
+	}
+	return out
+}
+
+// OptimizeLoanSize performs binary search to find the maximum safe loan amount
+// Returns: Safe amount or 0 (abort)
+func (tc *TitanCommander) OptimizeLoanSize(
+	tokenAddress common.Address,
+	targetAmountRaw *big.Int,
+	decimals uint8,
+) (*big.Int, error) {
+	decision, err := tc.SizeLoan(tokenAddress, targetAmountRaw, decimals)
+	return decision.Amount, err
+}
+
+// SizeLoan applies every sizing guardrail and reports which one bound the result
+func (tc *TitanCommander) SizeLoan(
+	tokenAddress common.Address,
+	targetAmountRaw *big.Int,
+	decimals uint8,
+) (*SizingDecision, error) {
+	decision := &SizingDecision{
+		Token:     tokenAddress,
+		Decimals:  decimals,
+		Requested: new(big.Int).Set(targetAmountRaw),
+		Amount:    big.NewInt(0),
+	}
+	
+	// GUARD 0: Decimals Check (extreme or malicious decimals() values)
+	if err := units.ValidateDecimals(decimals); err != nil {
+		log.Printf("❌ Rejecting %s: %v", tokenAddress.Hex(), err)
+		decision.BoundBy = GuardrailDecimals
+		return decision, err
+	}
+	if decimals == 0 {
+		log.Printf("⚠️ Token %s has 0 decimals; floor is %d whole units", tokenAddress.Hex(), minFloorUnits)
+	}
+	
+	minFloor, err := tc.calculateMinFloor(tokenAddress, decimals)
+	if err != nil {
+		decision.BoundBy = GuardrailDecimals
+		return decision, err
+	}
+	decision.MinFloor = minFloor.Big()
+	
+	// Check TVL (Total Value Locked) at the lender
+	poolLiquidity, err := tc.lenderLiquidity(tokenAddress)
+	if err != nil || poolLiquidity.Cmp(big.NewInt(0)) == 0 {
+		// In PAPER mode, skip vault checks
+		decision.PaperMode = true
+		tc.validatePaperModeAmount(decision)
+		return decision, nil
+	}
+	decision.Liquidity = poolLiquidity
+	
+	// Calculate caps
+	maxCap := tc.calculateMaxCap(poolLiquidity)
+	decision.MaxCap = maxCap
+	requestedAmount := new(big.Int).Set(targetAmountRaw)
+	
+	// GUARD 1: Liquidity Check
+	if requestedAmount.Cmp(maxCap) > 0 {
+		log.Printf("⚠️ Liquidity Constraint: Requested %s, Cap %s. Scaling down.", 
+			requestedAmount.String(), maxCap.String())
+		requestedAmount = maxCap
+		decision.BoundBy = GuardrailMaxTVLShare
+	}
+	
+	// GUARD 2: Floor Check
+	if requestedAmount.Cmp(decision.MinFloor) < 0 {
+		log.Printf("❌ Trade too small for profitability (%s < %s). Aborting.",
+			formatRaw(tokenAddress, requestedAmount, decimals), minFloor.String())
+		decision.BoundBy = GuardrailMinFloor
+		return decision, nil
+	}
+	
+	log.Printf("✅ Loan Sizing Optimized: %s (Cap: %s)",
+		formatRaw(tokenAddress, requestedAmount, decimals), formatRaw(tokenAddress, maxCap, decimals))
+	decision.Amount = requestedAmount
+	return decision, nil
+}
+
+// validatePaperModeAmount validates amount in paper mode
+func (tc *TitanCommander) validatePaperModeAmount(decision *SizingDecision) {
+	token, decimals := decision.Token, decision.Decimals
+	
+	if decision.Requested.Cmp(decision.MinFloor) < 0 {
+		log.Printf("Trade too small (%s < %s)", formatRaw(token, decision.Requested, decimals), formatRaw(token, decision.MinFloor, decimals))
+		decision.BoundBy = GuardrailMinFloor
+		return
+	}
+	
+	log.Printf("✅ PAPER MODE: Using requested amount %s", formatRaw(token, decision.Requested, decimals))
+	decision.Amount = new(big.Int).Set(decision.Requested)
+}
+
+// calculateMaxCap calculates maximum cap based on TVL
+func (tc *TitanCommander) calculateMaxCap(poolLiquidity *big.Int) *big.Int {
+	// max_cap = pool_liquidity * MAX_TVL_SHARE_BPS / 10000 (rounds down)
+	return units.MulBps(poolLiquidity, tc.MaxTVLShareBps)
+}
+
+// MinAmountOut applies the slippage guardrail to an expected output amount
+func (tc *TitanCommander) MinAmountOut(expectedOut *big.Int) *big.Int {
+	if tc.MaxSlippageBps >= units.BpsDenominator {
+		return big.NewInt(0)
+	}
+	return units.MulBps(expectedOut, units.BpsDenominator-tc.MaxSlippageBps)
+}
+
+// calculateMinFloor calculates minimum floor based on decimals
+func (tc *TitanCommander) calculateMinFloor(tokenAddress common.Address, decimals uint8) (units.Amount, error) {
+	// 500 units of stablecoin/ETH
+	if err := units.ValidateDecimals(decimals); err != nil {
+		return units.Amount{}, err
+	}
+	return units.FromWhole(tokenAddress, minFloorUnits, decimals)
+}
+
+// formatRaw renders a raw amount in human units for logs, falling back to raw digits
+func formatRaw(tokenAddress common.Address, raw *big.Int, decimals uint8) string {
+	amount, err := units.FromBig(tokenAddress, raw, decimals)
+	if err != nil {
+		return raw.String()
+	}
+	return amount.String()
+}
+
+// ChainID returns the chain ID
+func (tc *TitanCommander) ChainID() uint64 {
+	return tc.chainID
+}
@@ -0,0 +1,121 @@
+package commander
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+func TestCalculateMaxCapBps(t *testing.T) {
+	tc := New(137, nil)
+
+	// 20% of 1,000,000.000001 USDC must round down, never up
+	liquidity := big.NewInt(1_000_000_000_001)
+	if got := tc.calculateMaxCap(liquidity); got.Cmp(big.NewInt(200_000_000_000)) != 0 {
+		t.Errorf("Expected cap 200000000000, got %s", got)
+	}
+
+	tc.MaxTVLShareBps = 1
+	if got := tc.calculateMaxCap(big.NewInt(9_999)); got.Sign() != 0 {
+		t.Errorf("Expected 1 bps of 9999 to round to 0, got %s", got)
+	}
+}
+
+func TestMinAmountOut(t *testing.T) {
+	tc := New(137, nil)
+
+	if got := tc.MinAmountOut(big.NewInt(1_000_000)); got.Cmp(big.NewInt(995_000)) != 0 {
+		t.Errorf("Expected 995000 at 50 bps slippage, got %s", got)
+	}
+
+	tc.MaxSlippageBps = units.BpsDenominator
+	if got := tc.MinAmountOut(big.NewInt(1_000_000)); got.Sign() != 0 {
+		t.Errorf("Expected 0 at 100%% slippage, got %s", got)
+	}
+}
+
+func TestOptimizeLoanSizeRejectsExtremeDecimals(t *testing.T) {
+	// Rejection happens before any provider access, so no chain is needed
+	tc := New(137, nil)
+	token := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+
+	amount, err := tc.OptimizeLoanSize(token, big.NewInt(1), 255)
+	if !errors.Is(err, units.ErrUnsupportedDecimals) {
+		t.Errorf("Expected ErrUnsupportedDecimals, got %v", err)
+	}
+	if amount.Sign() != 0 {
+		t.Errorf("Expected zero amount on rejection, got %s", amount)
+	}
+}
+
+func TestCalculateMinFloor(t *testing.T) {
+	tc := New(1, nil)
+	token := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+
+	floor, err := tc.calculateMinFloor(token, 0)
+	if err != nil || floor.Big().Int64() != 500 {
+		t.Errorf("Expected floor of 500 raw units at 0 decimals, got %s (%v)", floor.Big(), err)
+	}
+
+	floor, err = tc.calculateMinFloor(token, 24)
+	if err != nil || floor.String() != "500" {
+		t.Errorf("Expected floor of 500 tokens at 24 decimals, got %s (%v)", floor, err)
+	}
+}
+
+func TestSizeLoanAgainstVaultLiquidity(t *testing.T) {
+	backend := titantest.NewBackend(137)
+	token := common.HexToAddress("0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359")
+	vault := common.HexToAddress(config.BalancerV3Vault)
+	backend.SetBalance(token, vault, big.NewInt(1_000_000_000_000)) // 1M USDC
+
+	tc := NewFromConfig(137, backend, &config.Guardrails{MinLoanUSD: 10000, MaxTVLShareBps: 2000, MaxSlippageBps: 50})
+	decision, err := tc.SizeLoan(token, big.NewInt(500_000_000_000), 6)
+	if err != nil {
+		t.Fatalf("SizeLoan failed: %v", err)
+	}
+	if decision.PaperMode {
+		t.Fatal("Expected vault liquidity to be read from the backend")
+	}
+	if decision.Amount.Cmp(big.NewInt(200_000_000_000)) != 0 {
+		t.Errorf("Expected amount capped at 200000000000, got %s", decision.Amount)
+	}
+	if decision.BoundBy != GuardrailMaxTVLShare {
+		t.Errorf("Expected %s to bind, got %q", GuardrailMaxTVLShare, decision.BoundBy)
+	}
+}
+
+func TestSizeLoanAgainstAaveReserves(t *testing.T) {
+	backend := titantest.NewBackend(137)
+	token := common.HexToAddress("0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359")
+	pool, aToken := titantest.Address(1), titantest.Address(2)
+	backend.Handle(pool, "getReserveData(address)", func(ethereum.CallMsg) ([]byte, error) {
+		data := make([]byte, 15*32)
+		copy(data[8*32:], titantest.EncodeAddress(aToken))
+		return data, nil
+	})
+	backend.SetBalance(token, aToken, big.NewInt(100_000_000_000))                           // 100k USDC in the reserve
+	backend.SetBalance(token, common.HexToAddress(config.BalancerV3Vault), big.NewInt(1e15)) // ignored
+
+	tc := NewFromConfig(137, backend, &config.Guardrails{MinLoanUSD: 10000, MaxTVLShareBps: 2000, MaxSlippageBps: 50})
+	tc.SizeAgainstAave(pool)
+	decision, err := tc.SizeLoan(token, big.NewInt(500_000_000_000), 6)
+	if err != nil {
+		t.Fatalf("SizeLoan failed: %v", err)
+	}
+	if decision.PaperMode || decision.Amount.Cmp(big.NewInt(20_000_000_000)) != 0 {
+		t.Errorf("Expected 20%% of the Aave reserve, got %s (paper %v)", decision.Amount, decision.PaperMode)
+	}
+
+	// An empty reserve has nothing to lend
+	decision, err = tc.SizeLoan(common.HexToAddress("0x8f3Cf7ad23Cd3CaDbD9735AFf958023239c6A063"), titantest.Units(1000, 18), 18)
+	if err != nil || !decision.PaperMode {
+		t.Errorf("Expected paper mode for an unlisted token, got %+v (%v)", decision, err)
+	}
+}
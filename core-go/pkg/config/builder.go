@@ -0,0 +1,255 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// hexAddress matches a 0x-prefixed 20-byte hex address
+var hexAddress = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// Builder assembles a Config in code for programs embedding the core instead
+// of running the titan binary. Methods chain; the first error is kept and
+// returned by Build.
+//
+//	cfg, err := config.NewBuilder().
+//		AddChain(137, config.ChainConfig{Name: "polygon", RPC: rpc}).
+//		AddDexRouter(137, "QUICKSWAP", config.RouterConfig{Address: router, Type: config.RouterTypeV2}).
+//		SetGuardrails(config.Guardrails{MinLoanUSD: 5000, MaxTVLShareBps: 1000, MaxSlippageBps: 30}).
+//		Build()
+type Builder struct {
+	cfg *Config
+	err error
+}
+
+// NewBuilder starts from an empty chain set with default API, alert, AI and
+// guardrail settings; nothing is read from the environment
+func NewBuilder() *Builder {
+	return &Builder{cfg: &Config{
+		Chains:             make(map[uint64]*ChainConfig),
+		DexRouters:         make(map[uint64]DexRouters),
+		IntentBasedBridges: make(map[string]*BridgeConfig),
+		AI: &AIConfig{
+			TARScoringEnabled:         true,
+			AIPredictionEnabled:       true,
+			AIPredictionMinConfidence: 0.8,
+			CatBoostModelEnabled:      true,
+			HFConfidenceThreshold:     0.8,
+			HFTimeoutMs:               2000,
+			MLConfidenceThreshold:     0.75,
+			PumpProbabilityThreshold:  0.2,
+			SelfLearningEnabled:       true,
+			RouteIntelligenceEnabled:  true,
+			RealTimeDataEnabled:       true,
+		},
+		API:              &APIConfig{Addr: "127.0.0.1:8090"},
+		Alerts:           &AlertConfig{},
+		Guardrails:       DefaultGuardrails(),
+		CrossChainPolicy: CrossChainConfirmFirst,
+		DataDir:          "data",
+	}}
+}
+
+// DefaultGuardrails returns the built-in sizing limits
+func DefaultGuardrails() *Guardrails {
+	return &Guardrails{MinLoanUSD: 10000, MaxTVLShareBps: 2000, MaxSlippageBps: 50, MinProfitUSD: 10}
+}
+
+func (b *Builder) fail(format string, args ...interface{}) *Builder {
+	if b.err == nil {
+		b.err = fmt.Errorf("config: "+format, args...)
+	}
+	return b
+}
+
+// AddChain registers a chain, replacing any previous entry for chainID
+func (b *Builder) AddChain(chainID uint64, chain ChainConfig) *Builder {
+	if chainID == 0 {
+		return b.fail("chain ID must be non-zero")
+	}
+	if chain.Name == "" {
+		return b.fail("chain %d has no name", chainID)
+	}
+	for _, f := range []struct{ name, addr string }{
+		{"aavePool", chain.AavePool},
+		{"uniswapRouter", chain.UniswapRouter},
+		{"curveRouter", chain.CurveRouter},
+		{"wrappedNative", chain.WrappedNative},
+		{"nativeUsdFeed", chain.NativeUSDFeed},
+		{"feeCurrency", chain.FeeCurrency},
+		{"timeboostAuction", chain.TimeboostAuction},
+	} {
+		if f.addr != "" && !hexAddress.MatchString(f.addr) {
+			return b.fail("chain %d %s %q is not a hex address", chainID, f.name, f.addr)
+		}
+	}
+	if chain.Confirmations == 0 {
+		chain.Confirmations = 1
+	}
+	if chain.SimulationDepth == "" {
+		chain.SimulationDepth = SimulationDepthCall
+	}
+	if !ValidSimulationDepth(chain.SimulationDepth) {
+		return b.fail("chain %d: unknown simulation depth %q", chainID, chain.SimulationDepth)
+	}
+	b.cfg.Chains[chainID] = &chain
+	return b
+}
+
+// AddDexRouter registers a DEX router by name (upper-cased) on chainID
+func (b *Builder) AddDexRouter(chainID uint64, name string, router RouterConfig) *Builder {
+	if name == "" {
+		return b.fail("router on chain %d has no name", chainID)
+	}
+	if !hexAddress.MatchString(router.Address) {
+		return b.fail("router %s on chain %d: address %q is not a hex address", name, chainID, router.Address)
+	}
+	if router.Factory != "" && !hexAddress.MatchString(router.Factory) {
+		return b.fail("router %s on chain %d: factory %q is not a hex address", name, chainID, router.Factory)
+	}
+	switch router.Type {
+	case "", RouterTypeV2, RouterTypeV3, RouterTypeSolidly, RouterTypeAggregator:
+	default:
+		return b.fail("router %s on chain %d: unknown type %q", name, chainID, router.Type)
+	}
+	if b.cfg.DexRouters[chainID] == nil {
+		b.cfg.DexRouters[chainID] = DexRouters{}
+	}
+	b.cfg.DexRouters[chainID][strings.ToUpper(name)] = &router
+	return b
+}
+
+// SetGuardrails replaces the sizing limits
+func (b *Builder) SetGuardrails(g Guardrails) *Builder {
+	if err := g.Validate(); err != nil {
+		return b.fail("%v", err)
+	}
+	b.cfg.Guardrails = &g
+	return b
+}
+
+// SetSimulation replaces the simulation escalation thresholds
+func (b *Builder) SetSimulation(s SimulationConfig) *Builder {
+	b.cfg.Simulation = &s
+	return b
+}
+
+// SetMEVShare enables MEV-Share backrunning with the given endpoints
+func (b *Builder) SetMEVShare(m MEVShareConfig) *Builder {
+	b.cfg.MEVShare = &m
+	return b
+}
+
+// SetBuilderPayment sets the share of bundle profit paid to block builders
+func (b *Builder) SetBuilderPayment(p BuilderPaymentConfig) *Builder {
+	if err := p.Validate(); err != nil {
+		return b.fail("%v", err)
+	}
+	b.cfg.BuilderPayment = &p
+	return b
+}
+
+// SetCrossChainPolicy sets the default atomicity policy for cross-chain plans
+func (b *Builder) SetCrossChainPolicy(policy string) *Builder {
+	if !ValidCrossChainPolicy(policy) {
+		return b.fail("unknown cross-chain policy %q", policy)
+	}
+	b.cfg.CrossChainPolicy = policy
+	return b
+}
+
+// SetHedge enables the perp hedge on in-flight bridge transfers
+func (b *Builder) SetHedge(h HedgeConfig) *Builder {
+	b.cfg.Hedge = &h
+	return b
+}
+
+// SetCEX enables the CEX reference price feeds
+func (b *Builder) SetCEX(c CEXConfig) *Builder {
+	b.cfg.CEX = &c
+	return b
+}
+
+// SetWebhooks sets the outbound event webhooks
+func (b *Builder) SetWebhooks(w WebhookConfig) *Builder {
+	if len(w.URLs) > 0 && w.Secret == "" {
+		return b.fail("webhooks need a signing secret")
+	}
+	b.cfg.Webhooks = &w
+	return b
+}
+
+// SetEventBus sets the brokers the event stream is published to
+func (b *Builder) SetEventBus(e EventBusConfig) *Builder {
+	b.cfg.EventBus = &e
+	return b
+}
+
+// SetLeader enables active/standby leader election
+func (b *Builder) SetLeader(l LeaderConfig) *Builder {
+	if err := l.Validate(); err != nil {
+		return b.fail("%v", err)
+	}
+	b.cfg.Leader = &l
+	return b
+}
+
+// SetShard assigns this instance a subset of the chains
+func (b *Builder) SetShard(s ShardConfig) *Builder {
+	if err := s.Validate(); err != nil {
+		return b.fail("%v", err)
+	}
+	b.cfg.Shard = &s
+	return b
+}
+
+// SetProfiling sets pprof, snapshot and leak detection options
+func (b *Builder) SetProfiling(p ProfilingConfig) *Builder {
+	b.cfg.Profiling = &p
+	return b
+}
+
+// SetDataDir sets the directory for the journal and other state
+func (b *Builder) SetDataDir(dir string) *Builder {
+	b.cfg.DataDir = dir
+	return b
+}
+
+// SetAPIAddr sets the control API listen address
+func (b *Builder) SetAPIAddr(addr string) *Builder {
+	b.cfg.API.Addr = addr
+	return b
+}
+
+// AddTokenList adds a token-list URL or file path to ingest
+func (b *Builder) AddTokenList(source string) *Builder {
+	b.cfg.TokenLists = append(b.cfg.TokenLists, source)
+	return b
+}
+
+// Build returns the assembled config or the first error recorded
+func (b *Builder) Build() (*Config, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.cfg.Chains) == 0 {
+		return nil, errors.New("config: no chains added")
+	}
+	for chainID, routers := range b.cfg.DexRouters {
+		if _, ok := b.cfg.Chains[chainID]; !ok {
+			for name := range routers {
+				return nil, fmt.Errorf("config: router %s references unknown chain %d", name, chainID)
+			}
+		}
+	}
+	if b.cfg.Shard != nil {
+		for _, id := range b.cfg.Shard.Chains {
+			if _, ok := b.cfg.Chains[id]; !ok {
+				return nil, fmt.Errorf("config: shard references unknown chain %d", id)
+			}
+		}
+	}
+	return b.cfg, nil
+}
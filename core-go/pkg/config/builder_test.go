@@ -0,0 +1,95 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const testRouter = "0xa5E0829CaCEd8fFDD4De3c43696c57F7D7A678ff"
+
+func TestBuilder(t *testing.T) {
+	cfg, err := NewBuilder().
+		AddChain(137, ChainConfig{Name: "polygon", RPC: "http://localhost:8545"}).
+		AddDexRouter(137, "quickswap", RouterConfig{Address: testRouter, Type: RouterTypeV2, FeeBps: 30}).
+		SetGuardrails(Guardrails{MinLoanUSD: 5000, MaxTVLShareBps: 1000, MaxSlippageBps: 30}).
+		SetDataDir("/tmp/titan").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	chain, ok := cfg.GetChain(137)
+	if !ok || chain.Name != "polygon" {
+		t.Fatalf("Expected polygon chain, got %+v", chain)
+	}
+	if chain.Confirmations != 1 {
+		t.Errorf("Expected default of 1 confirmation, got %d", chain.Confirmations)
+	}
+	if cfg.IsChainSupported(1) {
+		t.Error("Expected builder to start without default chains")
+	}
+	if r := cfg.DexRouters[137]["QUICKSWAP"]; r == nil || r.FeeBps != 30 {
+		t.Errorf("Expected QUICKSWAP router with 30 bps, got %+v", r)
+	}
+	if cfg.Guardrails.MaxTVLShareBps != 1000 {
+		t.Errorf("Expected 1000 bps TVL share, got %d", cfg.Guardrails.MaxTVLShareBps)
+	}
+	if cfg.DataDir != "/tmp/titan" {
+		t.Errorf("Expected /tmp/titan, got %s", cfg.DataDir)
+	}
+	if cfg.API == nil || cfg.AI == nil || cfg.Alerts == nil {
+		t.Error("Expected default API, AI and alert sections")
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	cases := map[string]struct {
+		b    *Builder
+		want string
+	}{
+		"no chains": {NewBuilder(), "no chains"},
+		"no name":   {NewBuilder().AddChain(137, ChainConfig{}), "no name"},
+		"bad address": {
+			NewBuilder().AddChain(137, ChainConfig{Name: "polygon", AavePool: "0x123"}),
+			"aavePool",
+		},
+		"bad router": {
+			NewBuilder().AddChain(137, ChainConfig{Name: "polygon"}).AddDexRouter(137, "X", RouterConfig{Address: "nope"}),
+			"not a hex address",
+		},
+		"unknown chain": {
+			NewBuilder().AddChain(137, ChainConfig{Name: "polygon"}).AddDexRouter(1, "X", RouterConfig{Address: testRouter}),
+			"unknown chain 1",
+		},
+		"guardrails": {
+			NewBuilder().AddChain(137, ChainConfig{Name: "polygon"}).SetGuardrails(Guardrails{MaxTVLShareBps: 20000}),
+			"out of range",
+		},
+		"cross-chain policy": {
+			NewBuilder().AddChain(137, ChainConfig{Name: "polygon"}).SetCrossChainPolicy("yolo"),
+			"unknown cross-chain policy",
+		},
+		"leader backend": {
+			NewBuilder().AddChain(137, ChainConfig{Name: "polygon"}).SetLeader(LeaderConfig{Backend: "zookeeper", URL: "zk://x", TTLSecs: 10}),
+			"unknown leader election backend",
+		},
+		"shard index": {
+			NewBuilder().AddChain(137, ChainConfig{Name: "polygon"}).SetShard(ShardConfig{Count: 2, Index: 2}),
+			"out of range",
+		},
+		"shard chain": {
+			NewBuilder().AddChain(137, ChainConfig{Name: "polygon"}).SetShard(ShardConfig{Count: 1, Chains: []uint64{1}}),
+			"unknown chain 1",
+		},
+		"first error wins": {
+			NewBuilder().AddChain(0, ChainConfig{}).AddChain(137, ChainConfig{}),
+			"non-zero",
+		},
+	}
+	for name, tc := range cases {
+		_, err := tc.b.Build()
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: Expected error containing %q, got %v", name, tc.want, err)
+		}
+	}
+}
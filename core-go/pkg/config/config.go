@@ -0,0 +1,2200 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/seal"
+)
+
+// BalancerV3Vault is the deterministic Balancer V3 Vault address across all chains
+const BalancerV3Vault = "0xbA1333333333a1BA1108E8412f11850A5C319bA9"
+
+// ChainConfig represents configuration for a single blockchain
+type ChainConfig struct {
+	Name             string
+	RPC              string
+	WSS              string
+	AavePool         string
+	UniswapRouter    string
+	CurveRouter      string
+	Native           string
+	WrappedNative    string // ERC20 wrapper of the native gas token (WETH, WMATIC, ...)
+	NativeUSDFeed    string // Chainlink native/USD aggregator
+	Confirmations    uint64 // block depth before a trade is final; CONFIRMATIONS_<NAME> overrides
+	GasStationURL    string // optional gas station fee API
+	FeeCurrency      string // ERC20 to pay gas in, on chains that support it (Celo)
+	SimulationDepth  string // quote, call or fork; SIMULATION_DEPTH_<NAME> overrides
+	ForkRPC          string // forked node (e.g. anvil --fork-url) for fork simulation
+	SequencerRPC     string // direct sequencer endpoint on L2s; SEQUENCER_RPC_<NAME> overrides
+	SequencerFeed    string // Chainlink L2 sequencer uptime feed; SEQUENCER_FEED_<NAME> overrides
+	BackfillRPC      string // cheaper endpoint for discovery and backfills; BACKFILL_RPC_<NAME>
+	RegionRPCs       map[string]string // region to a regional endpoint for latency-sensitive traffic; REGION_RPCS_<NAME>, e.g. "us-east=https://..."
+	TimeboostAuction string // Arbitrum express lane auction contract; empty disables timeboost
+	QuoteMaxAgeMs    uint64 // quotes older than this are invalid, 0 unbounded; QUOTE_MAX_AGE_MS_<NAME> overrides
+	QuoteMaxBlocks   uint64 // quotes this many blocks behind the head are invalid, 0 unbounded; QUOTE_MAX_BLOCKS_<NAME>
+}
+
+// quoteFreshness is each chain's default quote freshness window, about one
+// or two block times: milliseconds, then blocks
+var quoteFreshness = map[string][2]uint64{
+	"ethereum":  {12000, 1},
+	"polygon":   {4000, 2},
+	"arbitrum":  {1000, 4},
+	"optimism":  {2000, 1},
+	"base":      {2000, 1},
+	"bsc":       {3000, 1},
+	"avalanche": {2000, 1},
+	"zksync":    {2000, 2},
+	"mantle":    {2000, 1},
+	"celo":      {5000, 1},
+}
+
+// Simulation depths, cheapest first
+const (
+	SimulationDepthQuote = "quote" // local quote math only
+	SimulationDepthCall  = "call"  // eth_call of the execution against latest state
+	SimulationDepthFork  = "fork"  // full bundle on a forked node
+)
+
+// SimulationConfig holds the profit thresholds that escalate a candidate past
+// its chain's configured simulation depth
+type SimulationConfig struct {
+	EscalateCallUSD uint64 // simulate at least by eth_call at this expected profit, 0 never
+	EscalateForkUSD uint64 // simulate on a fork at this expected profit, 0 never
+}
+
+// MEVShareConfig holds the Flashbots MEV-Share endpoints used for backrunning
+// Ethereum orderflow
+type MEVShareConfig struct {
+	Enabled      bool
+	StreamURL    string  // SSE hint stream
+	RelayURL     string  // mev_sendBundle endpoint
+	BackrunInETH float64 // wrapped native amount each backrun cycle is quoted with
+	AuthKey      string  // signs relay requests for reputation; holds no funds
+}
+
+// BuilderPaymentPriorityFee is the builder payment mode: the payment is
+// folded into the backrun's priority fee. Paying block.coinbase directly
+// would need a transfer the executor's execute entry point cannot make.
+const BuilderPaymentPriorityFee = "priority_fee"
+
+// BuilderPaymentConfig sets how much of a bundle's expected profit is paid to
+// the block builder, trading margin for inclusion probability. Nothing is paid
+// unless a share is configured.
+type BuilderPaymentConfig struct {
+	Mode     string
+	ShareBps uint64 // share of expected profit paid, 0-10000; 0 pays nothing
+	MaxUSD   uint64 // cap per bundle in whole dollars, 0 uncapped
+}
+
+// HedgeConfig configures the optional perp hedge on funds in flight across a bridge
+type HedgeConfig struct {
+	Enabled        bool
+	BaseURL        string            // perp exchange REST endpoint
+	APIKey         string
+	APISecret      string
+	MinNotionalUSD uint64            // transfers worth less stay unhedged
+	Markets        map[string]string // token symbol to perp market, e.g. WETH=ETHUSDT
+}
+
+// CEXConfig configures read-only CEX ticker feeds used as reference prices
+type CEXConfig struct {
+	Enabled     bool
+	Venues      []string // binance, coinbase
+	Assets      []string // asset symbols, e.g. ETH, USDC
+	Stablecoins []string // assets watched for depegs
+	MaxAgeSecs  uint64   // tickers older than this are ignored
+	DepegBps    uint64   // alert when a stablecoin is further than this from $1
+}
+
+// WebhookConfig configures outbound event webhooks
+type WebhookConfig struct {
+	URLs   []string
+	Secret string   // HMAC-SHA256 key for the X-Titan-Signature header
+	Events []string // event types sent: opportunity, execution, reversal, alert
+}
+
+// EventBusConfig configures the message brokers the internal event stream
+// is published to; each is enabled by setting its URL
+type EventBusConfig struct {
+	NATSURL       string // nats://[user:pass@]host:port
+	NATSSubject   string // events go to <subject>.<type>
+	NATSJetStream bool   // wait for JetStream acks
+	RedisURL      string // redis://[:password@]host:port[/db]
+	RedisStream   string
+	RedisMaxLen   uint64 // approximate stream cap, 0 uncapped
+	KafkaRESTURL  string // Kafka REST Proxy base URL
+	KafkaTopic    string
+}
+
+// ProfilingConfig controls pprof endpoints, profile snapshots and goroutine
+// leak detection
+type ProfilingConfig struct {
+	PprofEnabled  bool   // serve /debug/pprof on the API
+	SnapshotSecs  uint64 // heap and goroutine snapshot interval, 0 disables
+	SnapshotKeep  uint64 // snapshots of each kind kept per run
+	LeakSamples   uint64 // consecutive per-minute increases flagged as a leak
+	MaxGoroutines uint64 // per-module goroutine limit, 0 unlimited
+}
+
+// HeartbeatConfig controls heartbeats from critical loops to an external
+// monitor such as healthchecks.io
+type HeartbeatConfig struct {
+	URL          string // ping URL base; "{check}" is replaced by the check name, else it is appended
+	DeadlineSecs uint64 // a loop silent for longer is reported failed
+	IntervalSecs uint64 // how often checks are evaluated and pinged
+}
+
+// ChaosConfig injects faults into node connections so failover, retry and
+// reconnect paths are exercised outside production
+type ChaosConfig struct {
+	LatencyMs uint64   // added to every call
+	JitterMs  uint64   // random extra latency up to this bound
+	ErrorRate float64  // share of calls failed with an injected error
+	DropRate  float64  // chance each delivered head drops its subscription
+	Methods   []string // JSON-RPC methods affected, empty for all
+}
+
+// VolatilityConfig sets the realized-volatility regimes that widen the
+// min-profit and slippage guardrails in turbulent markets and tighten them
+// in calm ones
+type VolatilityConfig struct {
+	WindowSecs   uint64 // lookback realized volatility is measured over
+	CalmBps      uint64 // at or below this the market is calm
+	HighBps      uint64 // at or above this volatility is high
+	CalmScaleBps uint64 // guardrail multiplier when calm, in bps of 1x
+	HighScaleBps uint64 // guardrail multiplier when volatility is high, in bps of 1x
+}
+
+// ExposureConfig lists the wallets whose balances count as inventory
+type ExposureConfig struct {
+	Wallets     []string // addresses watched on every configured chain
+	RefreshSecs uint64   // how often balances are read
+}
+
+// CanaryConfig caps newly seen routes at a fraction of their size until they
+// have filled successfully a few times
+type CanaryConfig struct {
+	Fills   uint64 // successful fills before full sizing; 0 disables canaries
+	SizeBps uint64 // canary size as a share of the full size
+}
+
+// Scoring experiment modes
+const (
+	ExperimentShadow = "shadow" // the control decides; the candidate is only scored
+	ExperimentSplit  = "split"  // each opportunity is decided by one arm
+)
+
+// ExperimentConfig runs a candidate scoring model beside the control one
+type ExperimentConfig struct {
+	Control           string  // scoring model deciding by default
+	ControlMinScore   float64 // scores below this are rejected
+	Candidate         string  // model under evaluation; empty disables the experiment
+	CandidateMinScore float64
+	Mode              string
+	SplitBps          uint64 // share of opportunities the candidate decides in split mode
+}
+
+// SignalsConfig lists the social and news sources ingested when
+// REAL_TIME_DATA_ENABLED is on
+type SignalsConfig struct {
+	RSSFeeds     []string // RSS or Atom feed URLs
+	TwitterToken string   // API v2 bearer token; empty skips Twitter
+	TwitterQuery string   // recent search query, e.g. "$ETH OR $PEPE -is:retweet"
+	PollSecs     uint64
+	WindowMins   uint64 // mentions and pump probability are judged over this window
+}
+
+// WhaleConfig watches large transfers of universe tokens to and from exchanges
+type WhaleConfig struct {
+	Exchanges  map[string]string // address to exchange name; empty disables the monitor
+	MinUSD     uint64            // smallest transfer that counts as a whale
+	WindowMins uint64            // flow is summed over this window
+	PollSecs   uint64
+}
+
+// LiquidityConfig tracks pool TVL and volume trends, shrinking the max TVL
+// share for pools whose liquidity is draining and raising it for pools that
+// stay deep
+type LiquidityConfig struct {
+	Enabled      bool
+	WindowHours  uint64 // trends are measured over this lookback
+	PollSecs     uint64
+	DrainBps     uint64 // TVL this far below the window's peak marks a pool draining
+	DeepUSD      uint64 // TVL never below this over the window marks a pool deep
+	DeepScaleBps uint64 // max TVL share multiplier for deep pools, in bps of 1x
+	MaxShareBps  uint64 // ceiling on a deep pool's share
+}
+
+// MinLoanConfig derives each chain's minimum trade size from what gas costs
+// there, in place of the single MinLoanUSD guardrail, which still applies to
+// chains whose gas cost isn't known yet
+type MinLoanConfig struct {
+	Dynamic     bool
+	EdgeBps     uint64 // spread a trade earns over its size after fees; the floor's edge pays for gas
+	FloorUSD    uint64 // no chain's minimum goes below this
+	CeilingUSD  uint64 // nor above this
+	RefreshSecs uint64
+}
+
+// SequencerConfig pauses execution on L2s whose sequencer is down, recently
+// restarted or not producing blocks, as quotes there are meaningless
+type SequencerConfig struct {
+	Enabled    bool
+	GraceSecs  uint64 // execution stays paused this long after the sequencer comes back
+	MaxLagSecs uint64 // a head block older than this marks the sequencer lagging, 0 never
+	PollSecs   uint64
+}
+
+// TransportConfig tunes the HTTP transport under a provider's JSON-RPC
+// connections; ethclient's defaults keep two idle connections per host and
+// never time out, which latency-critical traffic can't afford
+type TransportConfig struct {
+	DialTimeoutMs   uint64 `json:"dialTimeoutMs"`   // TCP connect and TLS handshake
+	ReadTimeoutMs   uint64 `json:"readTimeoutMs"`   // wait for a response's headers once a request is sent, 0 never
+	MaxIdleConns    uint64 `json:"maxIdleConns"`    // kept open per host
+	IdleTimeoutSecs uint64 `json:"idleTimeoutSecs"` // idle connections close after this
+	HTTP2           bool   `json:"http2"`
+	Proxy           string `json:"proxy"` // http, https or socks5 URL; empty uses HTTP_PROXY and friends
+}
+
+// RegionConfig places this host among the regions RPC endpoints are tagged
+// with, so latency-sensitive traffic goes to the fastest nearby endpoint
+type RegionConfig struct {
+	Region    string // this host's region; empty treats every region as local
+	Failover  bool   // use other regions' endpoints while every local one is down
+	ProbeSecs uint64 // how often each regional endpoint's latency is measured
+}
+
+// ClockConfig checks the host clock against NTP, since anything compared
+// with a chain or venue timestamp breaks on a drifting VM
+type ClockConfig struct {
+	Enabled    bool
+	Servers    []string // NTP servers, host or host:port
+	MaxDriftMs uint64   // alert beyond this offset either way
+	PollSecs   uint64
+}
+
+// BridgeRiskConfig prices the price drift a confirm-first cross-chain plan
+// is exposed to while its transfer is in flight
+type BridgeRiskConfig struct {
+	Confidence     float64 // z-score of the drift charged, e.g. 1.65 for 95% one-sided
+	LateShare      float64 // share of transfers assumed to take the bridge's MaxTimeSeconds
+	FallbackVolBps float64 // hourly volatility assumed for tokens without a measurement
+}
+
+// BridgeCalibrationConfig tunes how realized transfers replace a bridge's
+// configured fee range and times
+type BridgeCalibrationConfig struct {
+	MinTransfers      uint64 // realized transfers needed before they override the config
+	Window            uint64 // most recent transfers kept per bridge, route and size
+	DriftToleranceBps uint64 // realized median fee this far outside the configured range is flagged
+}
+
+// HopConfig points the Hop Protocol adapter at Hop's API and published
+// contract addresses
+type HopConfig struct {
+	Enabled      bool   // quote and build Hop transfers live; off leaves hop to its configured estimate
+	APIURL       string // quote and transfer status API
+	AddressesURL string // Hop's core config with bridge contracts by token and chain
+	SlippageBps  uint64
+}
+
+// CCTPConfig points the Circle CCTP adapter at Circle's attestation service
+type CCTPConfig struct {
+	Enabled        bool   // burn and mint native USDC; off leaves cctp to its configured estimate
+	AttestationURL string // Circle's attestation API
+	PollSecs       uint64 // how often a burn's attestation is polled for
+}
+
+// BridgeStatusConfig sets what the bridge status page quotes: each bridge
+// is asked for the reference sizes of a stablecoin over one route
+type BridgeStatusConfig struct {
+	RefreshSecs uint64
+	FromChain   uint64
+	ToChain     uint64
+	Token       string   // a stablecoin, so sizes in dollars are amounts
+	SizesUSD    []uint64 // reference transfer sizes in whole dollars
+}
+
+// GasPrefundConfig sets how much native gas a cross-chain plan's
+// destination wallet must hold before the plan executes, and whether a
+// shortfall is topped up over a gas-refuel bridge
+type GasPrefundConfig struct {
+	Enabled         bool
+	DefaultGasLimit uint64 // assumed for destination legs without an estimate
+	HeadroomBps     uint64 // balance required, of the leg's gas at the current fee cap
+	Refuel          bool   // build a top-up from the source chain when short
+	RefuelContract  string // Bungee Refuel, the same address on every chain it serves
+	RefuelTargetBps uint64 // balance a top-up brings the wallet to, of the leg's gas
+	RefuelMaxUSD    uint64 // largest top-up sent, in whole dollars
+}
+
+// AllowanceConfig sets which standing ERC20 approvals from the executors
+// and the signing wallet are revoked: those unused for UnusedDays and those
+// to spenders that are neither a configured router nor kept
+type AllowanceConfig struct {
+	Enabled      bool     // scan on a schedule while serving
+	IntervalSecs uint64   // between scheduled scans
+	UnusedDays   uint64   // an approval without use for this long is revoked
+	LookbackDays uint64   // how far back approvals and their spends are read from events
+	Keep         []string // spenders approved besides the routers, e.g. bridges and lenders
+	AutoRevoke   bool     // the scheduled scan sends revocations rather than only alerting
+}
+
+// Pump guard actions
+const (
+	PumpGuardBlock    = "block"    // refuse the route
+	PumpGuardDownsize = "downsize" // trade it at a fraction of its size
+	PumpGuardOff      = "off"
+)
+
+// PumpGuardConfig is what happens to routes that hold a token the pump
+// model flags above PUMP_PROBABILITY_THRESHOLD
+type PumpGuardConfig struct {
+	Action  string
+	SizeBps uint64 // share of the size kept when downsizing
+}
+
+// ModelsConfig locates versioned scoring model artifacts
+type ModelsConfig struct {
+	Source string   // directory or http(s) base URL; empty disables the registry
+	Names  []string // models to serve, each under <source>/<name>/
+}
+
+// Scoring stages, in their default order when all are enabled
+const (
+	ScoringFilters = "filters" // blackout windows and the min-profit floor
+	ScoringTAR     = "tar"     // token analysis and risk: trending tokens
+	ScoringML      = "ml"      // the scoring models and any experiment between them
+	ScoringRoutes  = "routes"  // route intelligence: how proven the route is
+	ScoringRisk    = "risk"    // market risk from the volatility regime
+)
+
+// ScoringStage is one step of the scoring pipeline
+type ScoringStage struct {
+	Name    string  `json:"name"`
+	Enabled bool    `json:"enabled"`
+	Weight  float64 `json:"weight"` // share of the composite score; 0 gates without scoring
+}
+
+// UnmarshalJSON defaults omitted fields to an enabled stage of weight 1
+func (s *ScoringStage) UnmarshalJSON(data []byte) error {
+	type plain ScoringStage
+	p := plain{Enabled: true, Weight: 1}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*s = ScoringStage(p)
+	return nil
+}
+
+// ScoringConfig orders the stages opportunities are scored through
+type ScoringConfig struct {
+	Stages   []ScoringStage `json:"stages"`
+	MinScore float64        `json:"minScore"` // weighted mean of stage scores below which opportunities are rejected
+}
+
+// BlackoutWindow is a recurring or dated period in which strategies keep
+// scanning but don't execute, or execute only above a raised profit floor
+type BlackoutWindow struct {
+	Name           string   `json:"name"`
+	Strategies     []string `json:"strategies"`     // empty covers every strategy
+	Days           []string `json:"days"`           // weekdays, e.g. "sat"; empty is every day
+	Dates          []string `json:"dates"`          // YYYY-MM-DD, for one-off events
+	Start          string   `json:"start"`          // HH:MM, inclusive
+	End            string   `json:"end"`            // HH:MM, exclusive; before Start wraps past midnight
+	Timezone       string   `json:"timezone"`       // IANA name; UTC when empty
+	MinProfitScale float64  `json:"minProfitScale"` // above 1 raises the profit floor instead of pausing
+}
+
+// StrategyMain names the process-wide strategy configured from the environment
+const StrategyMain = "main"
+
+// StrategyKindBackrun is the kind of strategy instances that backrun hints
+const StrategyKindBackrun = "backrun"
+
+// StrategyConfig is a named strategy instance run beside the main one with
+// its own token universe, thresholds, wallets and risk budget
+type StrategyConfig struct {
+	Name           string         `json:"name"`
+	Kind           string         `json:"kind"`           // strategy it runs, e.g. backrun
+	Tokens         []string       `json:"tokens"`         // symbols or addresses every leg must stay within; empty is unrestricted
+	Guardrails     *Guardrails    `json:"-"`              // nil follows the live process guardrails
+	Wallets        []string       `json:"wallets"`        // addresses it executes from
+	DailyLossUSD   uint64         `json:"dailyLossUsd"`   // realized loss in a UTC day that pauses it; 0 is unbounded
+	MaxInFlightUSD uint64         `json:"maxInFlightUsd"` // notional it may have in flight at once; 0 is unbounded
+	Scoring        []ScoringStage `json:"scoring"`        // stages it is scored through; empty follows the process pipeline
+}
+
+// ValidateStrategies checks strategy instances are uniquely named and usable
+func ValidateStrategies(strategies []StrategyConfig) error {
+	seen := map[string]bool{StrategyMain: true}
+	for _, s := range strategies {
+		if s.Name == "" {
+			return fmt.Errorf("strategy name is required")
+		}
+		if seen[strings.ToLower(s.Name)] {
+			return fmt.Errorf("duplicate strategy %q", s.Name)
+		}
+		seen[strings.ToLower(s.Name)] = true
+		if s.Kind != StrategyKindBackrun {
+			return fmt.Errorf("strategy %q: unknown kind %q", s.Name, s.Kind)
+		}
+		if err := ValidateScoringStages(s.Scoring); err != nil {
+			return fmt.Errorf("strategy %q: %w", s.Name, err)
+		}
+		for _, wallet := range s.Wallets {
+			if !common.IsHexAddress(wallet) {
+				return fmt.Errorf("strategy %q: invalid wallet %q", s.Name, wallet)
+			}
+		}
+		if s.Guardrails != nil {
+			if err := s.Guardrails.Validate(); err != nil {
+				return fmt.Errorf("strategy %q: %w", s.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// EnvProduction is the deployment environment fault injection is refused in
+const EnvProduction = "production"
+
+// Leader election backends
+const (
+	LeaderRedis = "redis"
+	LeaderEtcd  = "etcd"
+)
+
+// LeaderConfig enables active/standby leader election; only the leader
+// executes, while every instance keeps scanning
+type LeaderConfig struct {
+	Backend    string // redis or etcd; empty disables election
+	URL        string // redis:// URL or etcd v3 HTTP gateway endpoint
+	Key        string
+	TTLSecs    uint64 // a dead leader is replaced within about this long
+	InstanceID string // defaults to hostname and PID
+}
+
+// Cross-chain atomicity policies
+const (
+	CrossChainConfirmFirst  = "confirm_first" // destination leg waits for the source fill and bridge arrival
+	CrossChainPrepositioned = "prepositioned" // both legs run at once from destination inventory
+)
+
+// Router types understood by the DEX adapters
+const (
+	RouterTypeV2         = "v2"
+	RouterTypeV3         = "v3"
+	RouterTypeSolidly    = "solidly"
+	RouterTypeAggregator = "aggregator"
+)
+
+// RouterConfig describes a DEX router and the metadata adapters need
+type RouterConfig struct {
+	Address               string `json:"address"`
+	Type                  string `json:"type"` // empty means detect on startup
+	Version               string `json:"version,omitempty"`
+	FeeBps                uint32 `json:"feeBps"`
+	Factory               string `json:"factory,omitempty"` // resolved via router.factory() when empty
+	SupportsFeeOnTransfer bool   `json:"supportsFeeOnTransfer"`
+}
+
+// DexRouters represents DEX routers for a chain, keyed by name
+type DexRouters map[string]*RouterConfig
+
+// v2Router returns a UniswapV2 Router02-style router entry
+func v2Router(address, factory string, feeBps uint32) *RouterConfig {
+	return &RouterConfig{
+		Address:               address,
+		Type:                  RouterTypeV2,
+		Version:               "router02",
+		FeeBps:                feeBps,
+		Factory:               factory,
+		SupportsFeeOnTransfer: true,
+	}
+}
+
+// BridgeConfig represents configuration for a bridge protocol
+type BridgeConfig struct {
+	Name               string
+	TypicalTimeSeconds uint32
+	MaxTimeSeconds     uint32
+	FeeRangeBps        []uint32
+	Description        string
+}
+
+// AIConfig holds AI and scoring configuration
+type AIConfig struct {
+	TARScoringEnabled          bool
+	AIPredictionEnabled        bool
+	AIPredictionMinConfidence  float64
+	CatBoostModelEnabled       bool
+	HFConfidenceThreshold      float64
+	HFEndpointURL              string // HuggingFace inference endpoint scoring news sentiment; empty disables it
+	HFToken                    string
+	HFTimeoutMs                uint64
+	MLConfidenceThreshold      float64
+	PumpProbabilityThreshold   float64
+	SelfLearningEnabled        bool
+	RouteIntelligenceEnabled   bool
+	RealTimeDataEnabled        bool
+}
+
+// APIConfig holds configuration for the control API
+type APIConfig struct {
+	Addr string
+	Keys []APIKey // no keys leaves the API open
+}
+
+// APIKey is a control API credential with a viewer, operator or admin role
+type APIKey struct {
+	Name   string
+	Role   string
+	Secret string
+}
+
+// Guardrails holds the real-money limits applied when sizing loans
+type Guardrails struct {
+	MinLoanUSD     uint64 `json:"minLoanUsd"`     // minimum trade size in whole dollars
+	MaxTVLShareBps uint64 `json:"maxTvlShareBps"` // max share of lender liquidity to borrow
+	MaxSlippageBps uint64 `json:"maxSlippageBps"` // max accepted slippage on expected output
+	MinProfitUSD   uint64 `json:"minProfitUsd"`   // minimum net profit in whole dollars
+}
+
+// AlertConfig holds operator alerting channels
+type AlertConfig struct {
+	SlackWebhook     string
+	TelegramBotToken string
+	TelegramChatID   string
+	SentryDSN        string // crash reports; empty disables Sentry
+	SentryEnv        string
+}
+
+// Config holds all configuration for the Titan system
+type Config struct {
+	Chains               map[uint64]*ChainConfig
+	DexRouters           map[uint64]DexRouters
+	IntentBasedBridges   map[string]*BridgeConfig
+	LifiSupportedChains  []uint64
+	AI                   *AIConfig
+	API                  *APIConfig
+	Alerts               *AlertConfig
+	Guardrails           *Guardrails
+	Simulation           *SimulationConfig
+	MEVShare             *MEVShareConfig
+	BuilderPayment       *BuilderPaymentConfig
+	CrossChainPolicy     string // default atomicity policy for cross-chain plans
+	Hedge                *HedgeConfig
+	CEX                  *CEXConfig
+	Webhooks             *WebhookConfig
+	EventBus             *EventBusConfig
+	Leader               *LeaderConfig
+	Shard                *ShardConfig
+	Profiling            *ProfilingConfig
+	Heartbeat            *HeartbeatConfig
+	Chaos                *ChaosConfig
+	Volatility           *VolatilityConfig
+	Exposure             *ExposureConfig
+	Canary               *CanaryConfig
+	Experiment           *ExperimentConfig
+	Models               *ModelsConfig
+	Signals              *SignalsConfig
+	PumpGuard            *PumpGuardConfig
+	Whale                *WhaleConfig
+	Scoring              *ScoringConfig
+	Liquidity            *LiquidityConfig
+	MinLoan              *MinLoanConfig
+	Sequencer            *SequencerConfig
+	Blackouts            []BlackoutWindow // execution windows loaded from the config file
+	Strategies           []StrategyConfig // named instances run beside the main strategy, from the config file
+	AmortizeWindowHours  uint64           // one-off costs are spread over executions in this lookback
+	RPCPricing           map[string]float64 // provider host suffix to USD per million compute units
+	Transport            *TransportConfig   // every provider's RPC transport
+	Region               *RegionConfig
+	Clock                *ClockConfig
+	BridgeRisk           *BridgeRiskConfig
+	BridgeCalibration    *BridgeCalibrationConfig
+	Hop                  *HopConfig
+	CCTP                 *CCTPConfig
+	BridgeStatus         *BridgeStatusConfig
+	GasPrefund           *GasPrefundConfig
+	Allowances           *AllowanceConfig
+	ProviderTransport    map[string]*TransportConfig // provider host suffix to its own transport, from the config file
+	DataDir              string
+	TokenLists           []string // token-list URLs or file paths ingested into the registry
+	Environment          string   // deployment environment, e.g. production or staging
+	WatchOnly            bool     // evaluate everything but never execute, so no signer is needed
+	StateKeys            *seal.Keyring // seals state at rest and opens sealed secrets; nil keeps plaintext
+}
+
+// LoadFromEnv loads configuration from environment variables
+func LoadFromEnv() (*Config, error) {
+	config := &Config{
+		Chains:              loadChains(),
+		DexRouters:          loadDexRouters(),
+		IntentBasedBridges:  loadBridges(),
+		LifiSupportedChains: []uint64{1, 137, 42161, 10, 8453, 56, 43114, 250, 59144, 534352, 5000, 324, 81457, 42220, 204},
+		AI:                  loadAIConfig(),
+		API:                 loadAPIConfig(),
+		Alerts:              loadAlertConfig(),
+		Guardrails:          loadGuardrails(),
+		Simulation:          loadSimulationConfig(),
+		MEVShare:            loadMEVShareConfig(),
+		BuilderPayment:      loadBuilderPaymentConfig(),
+		CrossChainPolicy:    getEnv("CROSS_CHAIN_POLICY", CrossChainConfirmFirst),
+		Hedge:               loadHedgeConfig(),
+		CEX:                 loadCEXConfig(),
+		Webhooks:            loadWebhookConfig(),
+		EventBus:            loadEventBusConfig(),
+		Leader:              loadLeaderConfig(),
+		Profiling:           loadProfilingConfig(),
+		Heartbeat:           loadHeartbeatConfig(),
+		Chaos:               loadChaosConfig(),
+		Volatility:          loadVolatilityConfig(),
+		Exposure:            loadExposureConfig(),
+		Canary:              loadCanaryConfig(),
+		Experiment:          loadExperimentConfig(),
+		Models:              loadModelsConfig(),
+		Signals:             loadSignalsConfig(),
+		PumpGuard:           loadPumpGuardConfig(),
+		Whale:               loadWhaleConfig(),
+		Scoring:             loadScoringConfig(),
+		Liquidity:           loadLiquidityConfig(),
+		MinLoan:             loadMinLoanConfig(),
+		Sequencer:           loadSequencerConfig(),
+		AmortizeWindowHours: getUintEnv("AMORTIZE_WINDOW_HOURS", 168),
+		RPCPricing:          loadRPCPricing(),
+		Transport:           loadTransportConfig(),
+		Region:              loadRegionConfig(),
+		Clock:               loadClockConfig(),
+		BridgeRisk:          loadBridgeRiskConfig(),
+		BridgeCalibration:   loadBridgeCalibrationConfig(),
+		Hop:                 loadHopConfig(),
+		CCTP:                loadCCTPConfig(),
+		BridgeStatus:        loadBridgeStatusConfig(),
+		GasPrefund:          loadGasPrefundConfig(),
+		Allowances:          loadAllowanceConfig(),
+		DataDir:             getEnv("TITAN_DATA_DIR", "data"),
+		TokenLists:          getListEnv("TOKEN_LISTS"),
+		Environment:         getEnv("TITAN_ENV", EnvProduction),
+		WatchOnly:           getBoolEnv("WATCH_ONLY", false),
+	}
+	
+	if path := getEnv("TITAN_CONFIG", ""); path != "" {
+		if err := applyConfigFile(config, path); err != nil {
+			return nil, err
+		}
+	}
+	
+	if path := getEnv("DEX_ROUTERS_FILE", ""); path != "" {
+		if err := applyRouterOverrides(config.DexRouters, path); err != nil {
+			return nil, err
+		}
+	}
+	
+	if err := config.Guardrails.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.BuilderPayment.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if !ValidCrossChainPolicy(config.CrossChainPolicy) {
+		return nil, fmt.Errorf("unknown cross-chain policy %q", config.CrossChainPolicy)
+	}
+	
+	if len(config.Webhooks.URLs) > 0 && config.Webhooks.Secret == "" {
+		return nil, fmt.Errorf("WEBHOOK_URLS set without WEBHOOK_SECRET")
+	}
+	
+	if err := config.Leader.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Chaos.Validate(config.Environment); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Volatility.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Exposure.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Canary.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Experiment.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Models.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.AI.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Signals.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.PumpGuard.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Whale.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Scoring.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Liquidity.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.MinLoan.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Sequencer.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Transport.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Region.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Clock.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.BridgeRisk.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.BridgeCalibration.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Hop.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.CCTP.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.BridgeStatus.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.GasPrefund.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if err := config.Allowances.Validate(); err != nil {
+		return nil, err
+	}
+	
+	for host, t := range config.ProviderTransport {
+		if err := t.Validate(); err != nil {
+			return nil, fmt.Errorf("transport for %s: %w", host, err)
+		}
+	}
+	
+	if err := ValidateStrategies(config.Strategies); err != nil {
+		return nil, err
+	}
+	
+	shard, err := loadShardConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := shard.Validate(); err != nil {
+		return nil, err
+	}
+	for _, id := range shard.Chains {
+		if !config.IsChainSupported(id) {
+			return nil, fmt.Errorf("SHARD_CHAINS: unknown chain %d", id)
+		}
+	}
+	config.Shard = shard
+	
+	// Secrets may be given sealed so .env files don't hold them in the clear
+	config.StateKeys, err = seal.ParseKeyring(os.Getenv("TITAN_STATE_KEYS"))
+	if err != nil {
+		return nil, fmt.Errorf("TITAN_STATE_KEYS: %w", err)
+	}
+	for i, key := range config.API.Keys {
+		if config.API.Keys[i].Secret, err = config.StateKeys.OpenString(key.Secret); err != nil {
+			return nil, fmt.Errorf("API key %s: %w", key.Name, err)
+		}
+	}
+	
+	for chainID, chain := range config.Chains {
+		if !ValidSimulationDepth(chain.SimulationDepth) {
+			return nil, fmt.Errorf("chain %d: unknown simulation depth %q", chainID, chain.SimulationDepth)
+		}
+	}
+	
+	return config, nil
+}
+
+func loadChains() map[uint64]*ChainConfig {
+	chains := make(map[uint64]*ChainConfig)
+	
+	// Ethereum Mainnet
+	chains[1] = &ChainConfig{
+		Name:          "ethereum",
+		RPC:           getEnv("RPC_ETHEREUM", ""),
+		WSS:           getEnv("WSS_ETHEREUM", ""),
+		AavePool:      "0x87870Bca3F3fD6335C3F4ce8392D69350B4fA4E2",
+		UniswapRouter: "0xE592427A0AEce92De3Edee1F18E0157C05861564",
+		CurveRouter:   "0x99a58482BD75cbab83b27EC03CA68fF489b5788f",
+		Native:        "ETH",
+		WrappedNative: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2",
+		NativeUSDFeed: "0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419",
+		Confirmations: 3,
+	}
+	
+	// Polygon
+	chains[137] = &ChainConfig{
+		Name:          "polygon",
+		RPC:           getEnv("RPC_POLYGON", ""),
+		WSS:           getEnv("WSS_POLYGON", ""),
+		AavePool:      "0x794a61358D6845594F94dc1DB02A252b5b4814aD",
+		UniswapRouter: "0xE592427A0AEce92De3Edee1F18E0157C05861564",
+		CurveRouter:   "0x445FE580eF8d70FF569aB36e80c647af338db351",
+		Native:        "MATIC",
+		WrappedNative: "0x0d500B1d8E8eF31E21C99d1Db9A6444d3ADf1270",
+		NativeUSDFeed: "0xAB594600376Ec9fD91F8e885dADF0CE036862dE0",
+		Confirmations: 5,
+		GasStationURL: getEnv("GAS_STATION_POLYGON", ""),
+	}
+	
+	// Arbitrum
+	chains[42161] = &ChainConfig{
+		Name:             "arbitrum",
+		RPC:              getEnv("RPC_ARBITRUM", ""),
+		WSS:              getEnv("WSS_ARBITRUM", ""),
+		AavePool:         "0x794a61358D6845594F94dc1DB02A252b5b4814aD",
+		UniswapRouter:    "0xE592427A0AEce92De3Edee1F18E0157C05861564",
+		CurveRouter:      "0x0000000000000000000000000000000000000000",
+		Native:           "ETH",
+		WrappedNative:    "0x82aF49447D8a07e3bd95BD0d56f35241523fBab1",
+		NativeUSDFeed:    "0x639Fe6ab55C921f74e7fac1ee960C0B6293ba612",
+		Confirmations:    1,
+		SequencerRPC:     "https://arb1-sequencer.arbitrum.io/rpc",
+		SequencerFeed:    "0xFdB631F5EE196F0ed6FAa767959853A9F217697D",
+		TimeboostAuction: getEnv("TIMEBOOST_AUCTION_ARBITRUM", ""),
+	}
+	
+	// Optimism
+	chains[10] = &ChainConfig{
+		Name:          "optimism",
+		RPC:           getEnv("RPC_OPTIMISM", ""),
+		WSS:           getEnv("WSS_OPTIMISM", ""),
+		AavePool:      "0x794a61358D6845594F94dc1DB02A252b5b4814aD",
+		UniswapRouter: "0xE592427A0AEce92De3Edee1F18E0157C05861564",
+		CurveRouter:   "0x0000000000000000000000000000000000000000",
+		Native:        "ETH",
+		WrappedNative: "0x4200000000000000000000000000000000000006",
+		NativeUSDFeed: "0x13e3Ee699D1909E989722E753853AE30b17e08c5",
+		Confirmations: 1,
+		SequencerRPC:  "https://mainnet-sequencer.optimism.io",
+		SequencerFeed: "0x371EAD81c9102C9BF4874A9075FFFf170F2Ee389",
+	}
+	
+	// Base
+	chains[8453] = &ChainConfig{
+		Name:          "base",
+		RPC:           getEnv("RPC_BASE", ""),
+		WSS:           getEnv("WSS_BASE", ""),
+		AavePool:      "0x0000000000000000000000000000000000000000",
+		UniswapRouter: "0x2626664c2603336E57B271c5C0b26F421741e481",
+		CurveRouter:   "0x0000000000000000000000000000000000000000",
+		Native:        "ETH",
+		WrappedNative: "0x4200000000000000000000000000000000000006",
+		NativeUSDFeed: "0x71041dddad3595F9CEd3DcCFBe3D1F4b0a16Bb70",
+		Confirmations: 1,
+		SequencerRPC:  "https://mainnet-sequencer.base.org",
+		SequencerFeed: "0xBCF85224fc0756B9Fa45aA7892530B47e10b6433",
+	}
+	
+	// BNB Smart Chain
+	chains[56] = &ChainConfig{
+		Name:          "bsc",
+		RPC:           getEnv("RPC_BSC", ""),
+		WSS:           getEnv("WSS_BSC", ""),
+		AavePool:      "0x6807dc923806fE8Fd134338EABCA509979a7e0cB",
+		UniswapRouter: "0xB971eF87ede563556b2ED4b1C0b0019111Dd85d2",
+		CurveRouter:   "0x0000000000000000000000000000000000000000",
+		Native:        "BNB",
+		WrappedNative: "0xbb4CdB9CBd36B01bD1cBaEBF2De08d9173bc095c",
+		NativeUSDFeed: "0x0567F2323251f0Aab15c8dFb1967E4e8A7D2aeE",
+		Confirmations: 3,
+	}
+	
+	// Avalanche C-Chain
+	chains[43114] = &ChainConfig{
+		Name:          "avalanche",
+		RPC:           getEnv("RPC_AVALANCHE", ""),
+		WSS:           getEnv("WSS_AVALANCHE", ""),
+		AavePool:      "0x794a61358D6845594F94dc1DB02A252b5b4814aD",
+		UniswapRouter: "0xbb00FF08d01D300023C629E8fFfFcb65A5a578cE",
+		CurveRouter:   "0x0000000000000000000000000000000000000000",
+		Native:        "AVAX",
+		WrappedNative: "0xB31f66AA3C1e785363F0875A1B74E27b85FD66c7",
+		NativeUSDFeed: "0x0A77230d17318075983913bC2145DB16C7366156",
+		Confirmations: 1,
+	}
+	
+	// zkSync Era (EIP-712 transactions, see the execution package)
+	chains[324] = &ChainConfig{
+		Name:          "zksync",
+		RPC:           getEnv("RPC_ZKSYNC", ""),
+		WSS:           getEnv("WSS_ZKSYNC", ""),
+		AavePool:      "0x0000000000000000000000000000000000000000",
+		UniswapRouter: "0x0000000000000000000000000000000000000000",
+		CurveRouter:   "0x0000000000000000000000000000000000000000",
+		Native:        "ETH",
+		WrappedNative: "0x5AEa5775959fBC2557Cc8789bC1bf90A239D9a91",
+		NativeUSDFeed: "0x6D41d1dc818112880b40e26BD6FD347E41008eDA",
+		Confirmations: 1,
+	}
+	
+	// Mantle (gas paid in MNT)
+	chains[5000] = &ChainConfig{
+		Name:          "mantle",
+		RPC:           getEnv("RPC_MANTLE", ""),
+		WSS:           getEnv("WSS_MANTLE", ""),
+		AavePool:      "0x0000000000000000000000000000000000000000",
+		UniswapRouter: "0x0000000000000000000000000000000000000000",
+		CurveRouter:   "0x0000000000000000000000000000000000000000",
+		Native:        "MNT",
+		WrappedNative: "0x78c1b0C915c4FAA5FffA6CAbf0219DA63d7f4cb8",
+		Confirmations: 1,
+	}
+	
+	// Celo (gas payable in CELO or an allowlisted fee currency)
+	chains[42220] = &ChainConfig{
+		Name:          "celo",
+		RPC:           getEnv("RPC_CELO", ""),
+		WSS:           getEnv("WSS_CELO", ""),
+		AavePool:      "0x0000000000000000000000000000000000000000",
+		UniswapRouter: "0x0000000000000000000000000000000000000000",
+		CurveRouter:   "0x0000000000000000000000000000000000000000",
+		Native:        "CELO",
+		WrappedNative: "0x471EcE3750Da237f93B8E339c536989b8978a438", // CELO is itself an ERC20
+		Confirmations: 1,
+		FeeCurrency:   getEnv("CELO_FEE_CURRENCY", ""),
+	}
+	
+	for _, chain := range chains {
+		name := strings.ToUpper(chain.Name)
+		chain.Confirmations = getUintEnv("CONFIRMATIONS_"+name, chain.Confirmations)
+		chain.SimulationDepth = getEnv("SIMULATION_DEPTH_"+name, SimulationDepthCall)
+		chain.ForkRPC = getEnv("FORK_RPC_"+name, "")
+		chain.SequencerRPC = getEnv("SEQUENCER_RPC_"+name, chain.SequencerRPC)
+		chain.SequencerFeed = getEnv("SEQUENCER_FEED_"+name, chain.SequencerFeed)
+		chain.BackfillRPC = getEnv("BACKFILL_RPC_"+name, "")
+		chain.RegionRPCs = loadRegionRPCs("REGION_RPCS_" + name)
+		window := quoteFreshness[chain.Name]
+		chain.QuoteMaxAgeMs = getUintEnv("QUOTE_MAX_AGE_MS_"+name, window[0])
+		chain.QuoteMaxBlocks = getUintEnv("QUOTE_MAX_BLOCKS_"+name, window[1])
+	}
+	
+	return chains
+}
+
+func loadDexRouters() map[uint64]DexRouters {
+	dexRouters := make(map[uint64]DexRouters)
+	
+	// Ethereum DEX routers
+	dexRouters[1] = DexRouters{
+		"UNIV2": v2Router("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D", "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f", 30),
+		"SUSHI": v2Router("0xd9e1cE17f2641f24aE83637ab66a2cca9C378B9F", "0xC0AEe478e3658e2610c5F7A4A2E1777cE9e4f2Ac", 30),
+	}
+	
+	// Polygon DEX routers
+	dexRouters[137] = DexRouters{
+		"QUICKSWAP": v2Router("0xa5E0829CaCEd8fFDD4De3c43696c57F7D7A678ff", "0x5757371414417b8C6CAad45bAeF941aBc7d3Ab32", 30),
+		"SUSHI":     v2Router("0x1b02dA8Cb0d097eB8D57A175b88c7D8b47997506", "0xc35DADB65012eC5796536bD9864eD8773aBc74C4", 30),
+		"APE":       v2Router("0xC0788A3aD43d79aa53B09c2EaCc313A787d1d607", "0xCf083Be4164828f00cAE704EC15a36D711491284", 20),
+	}
+	
+	// Arbitrum DEX routers
+	dexRouters[42161] = DexRouters{
+		"CAMELOT": v2Router("0xc873fEcbd354f5A56E00E710B90EF4201db2448d", "0x6EcCab422D763aC031210895C81787E87B43A652", 30),
+		"SUSHI":   v2Router("0x1b02dA8Cb0d097eB8D57A175b88c7D8b47997506", "0xc35DADB65012eC5796536bD9864eD8773aBc74C4", 30),
+	}
+	
+	return dexRouters
+}
+
+// routerOverride is a partial RouterConfig; nil fields keep their defaults
+type routerOverride struct {
+	Address               string  `json:"address"`
+	Type                  string  `json:"type"`
+	Version               string  `json:"version"`
+	FeeBps                *uint32 `json:"feeBps"`
+	Factory               string  `json:"factory"`
+	SupportsFeeOnTransfer *bool   `json:"supportsFeeOnTransfer"`
+}
+
+// applyRouterOverrides merges a JSON file of per-chain router entries over the
+// built-in table
+//
+//	{"137": {"QUICKSWAP": {"feeBps": 25}, "NEWDEX": {"address": "0x...", "type": "v2", "feeBps": 30}}}
+//
+// The file may be YAML instead when named .yaml or .yml. Values may reference
+// ${VAR} environment variables.
+func applyRouterOverrides(dexRouters map[uint64]DexRouters, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read router overrides: %w", err)
+	}
+	data, err = expandDocument(path, data)
+	if err != nil {
+		return fmt.Errorf("failed to expand router overrides %s: %w", path, err)
+	}
+	var overrides map[uint64]map[string]*routerOverride
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return fmt.Errorf("failed to parse router overrides %s: %w", path, err)
+	}
+	return mergeRouterOverrides(dexRouters, overrides)
+}
+
+// mergeRouterOverrides applies partial router entries over dexRouters
+func mergeRouterOverrides(dexRouters map[uint64]DexRouters, overrides map[uint64]map[string]*routerOverride) error {
+	for chainID, routers := range overrides {
+		if dexRouters[chainID] == nil {
+			dexRouters[chainID] = DexRouters{}
+		}
+		for name, override := range routers {
+			name = strings.ToUpper(name)
+			merged := dexRouters[chainID][name]
+			if merged == nil {
+				merged = &RouterConfig{} // type left empty for detection
+			} else {
+				copied := *merged
+				merged = &copied
+			}
+			if override.Address != "" {
+				merged.Address = override.Address
+			}
+			if override.Type != "" {
+				merged.Type = override.Type
+			}
+			if override.Version != "" {
+				merged.Version = override.Version
+			}
+			if override.FeeBps != nil {
+				merged.FeeBps = *override.FeeBps
+			}
+			if override.Factory != "" {
+				merged.Factory = override.Factory
+			}
+			if override.SupportsFeeOnTransfer != nil {
+				merged.SupportsFeeOnTransfer = *override.SupportsFeeOnTransfer
+			}
+			if merged.Address == "" {
+				return fmt.Errorf("router %s on chain %d has no address", name, chainID)
+			}
+			dexRouters[chainID][name] = merged
+		}
+	}
+	return nil
+}
+
+func loadBridges() map[string]*BridgeConfig {
+	bridges := make(map[string]*BridgeConfig)
+	
+	bridges["across"] = &BridgeConfig{
+		Name:               "Across Protocol",
+		TypicalTimeSeconds: 30,
+		MaxTimeSeconds:     180,
+		FeeRangeBps:        []uint32{5, 30},
+		Description:        "Fastest intent-based bridge using solver network",
+	}
+	
+	bridges["stargate"] = &BridgeConfig{
+		Name:               "Stargate Finance",
+		TypicalTimeSeconds: 60,
+		MaxTimeSeconds:     300,
+		FeeRangeBps:        []uint32{6, 50},
+		Description:        "Fast and reliable LayerZero-based bridge",
+	}
+	
+	bridges["hop"] = &BridgeConfig{
+		Name:               "Hop Protocol",
+		TypicalTimeSeconds: 120,
+		MaxTimeSeconds:     600,
+		FeeRangeBps:        []uint32{10, 100},
+		Description:        "Popular bridge with good liquidity",
+	}
+	
+	bridges["cctp"] = &BridgeConfig{
+		Name:               "Circle CCTP",
+		TypicalTimeSeconds: 780,
+		MaxTimeSeconds:     1200,
+		FeeRangeBps:        []uint32{0, 0},
+		Description:        "Native USDC burned on the source and minted on the destination once Circle attests",
+	}
+	
+	return bridges
+}
+
+// GetChain returns configuration for a specific chain
+func (c *Config) GetChain(chainID uint64) (*ChainConfig, bool) {
+	chain, ok := c.Chains[chainID]
+	return chain, ok
+}
+
+// ValidSimulationDepth reports whether depth is a known simulation depth
+func ValidSimulationDepth(depth string) bool {
+	switch depth {
+	case SimulationDepthQuote, SimulationDepthCall, SimulationDepthFork:
+		return true
+	}
+	return false
+}
+
+// IsChainSupported checks if a chain is supported
+func (c *Config) IsChainSupported(chainID uint64) bool {
+	_, ok := c.Chains[chainID]
+	return ok
+}
+
+// getEnv retrieves an environment variable with a default value
+func getEnv(key, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return strings.TrimSpace(value)
+}
+
+// getBoolEnv retrieves a boolean environment variable with a default value
+func getBoolEnv(key string, defaultValue bool) bool {
+	value := strings.ToLower(getEnv(key, ""))
+	if value == "" {
+		return defaultValue
+	}
+	return value == "true" || value == "1" || value == "yes"
+}
+
+// getFloatEnv retrieves a float environment variable with a default value
+func getFloatEnv(key string, defaultValue float64) float64 {
+	value := getEnv(key, "")
+	if value == "" {
+		return defaultValue
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return f
+}
+
+// getListEnv retrieves a comma-separated environment variable, dropping empty items
+func getListEnv(key string) []string {
+	var out []string
+	for _, item := range strings.Split(getEnv(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// getUintEnv retrieves an unsigned integer environment variable with a default value
+func getUintEnv(key string, defaultValue uint64) uint64 {
+	value := getEnv(key, "")
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return n
+}
+
+// loadAIConfig loads AI and scoring configuration from environment
+func loadAIConfig() *AIConfig {
+	return &AIConfig{
+		TARScoringEnabled:         getBoolEnv("TAR_SCORING_ENABLED", true),
+		AIPredictionEnabled:       getBoolEnv("AI_PREDICTION_ENABLED", true),
+		AIPredictionMinConfidence: getFloatEnv("AI_PREDICTION_MIN_CONFIDENCE", 0.8),
+		CatBoostModelEnabled:      getBoolEnv("CATBOOST_MODEL_ENABLED", true),
+		HFConfidenceThreshold:     getFloatEnv("HF_CONFIDENCE_THRESHOLD", 0.8),
+		HFEndpointURL:             getEnv("HF_ENDPOINT_URL", ""),
+		HFToken:                   getEnv("HF_API_TOKEN", ""),
+		HFTimeoutMs:               getUintEnv("HF_TIMEOUT_MS", 2000),
+		MLConfidenceThreshold:     getFloatEnv("ML_CONFIDENCE_THRESHOLD", 0.75),
+		PumpProbabilityThreshold:  getFloatEnv("PUMP_PROBABILITY_THRESHOLD", 0.2),
+		SelfLearningEnabled:       getBoolEnv("SELF_LEARNING_ENABLED", true),
+		RouteIntelligenceEnabled:  getBoolEnv("ROUTE_INTELLIGENCE_ENABLED", true),
+		RealTimeDataEnabled:       getBoolEnv("REAL_TIME_DATA_ENABLED", true),
+	}
+}
+
+// Validate checks the HuggingFace endpoint, when set, is usable
+func (a *AIConfig) Validate() error {
+	if a.HFEndpointURL == "" {
+		return nil
+	}
+	if u, err := url.Parse(a.HFEndpointURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("HF_ENDPOINT_URL %q is not an http(s) URL", a.HFEndpointURL)
+	}
+	if a.HFTimeoutMs == 0 {
+		return fmt.Errorf("HF_TIMEOUT_MS must be positive")
+	}
+	return nil
+}
+
+// loadAPIConfig loads control API configuration from environment
+func loadAPIConfig() *APIConfig {
+	cfg := &APIConfig{Addr: getEnv("TITAN_API_ADDR", "127.0.0.1:8090")}
+	// TITAN_API_KEYS="alice:admin:s3cret,grafana:viewer:..."; malformed entries
+	// are kept so the API refuses to start rather than run with fewer keys
+	for _, item := range getListEnv("TITAN_API_KEYS") {
+		parts := strings.SplitN(item, ":", 3)
+		for len(parts) < 3 {
+			parts = append(parts, "")
+		}
+		cfg.Keys = append(cfg.Keys, APIKey{Name: parts[0], Role: parts[1], Secret: parts[2]})
+	}
+	// The single pre-role token stays an admin key
+	if token := os.Getenv("TITAN_API_TOKEN"); token != "" {
+		cfg.Keys = append(cfg.Keys, APIKey{Name: "token", Role: "admin", Secret: token})
+	}
+	return cfg
+}
+
+// Validate checks the guardrails are usable limits
+func (g *Guardrails) Validate() error {
+	if g.MaxTVLShareBps == 0 || g.MaxTVLShareBps > 10000 {
+		return fmt.Errorf("max TVL share %d bps out of range (1-10000)", g.MaxTVLShareBps)
+	}
+	if g.MaxSlippageBps >= 10000 {
+		return fmt.Errorf("max slippage %d bps out of range (0-9999)", g.MaxSlippageBps)
+	}
+	return nil
+}
+
+// loadGuardrails loads sizing limits from environment
+func loadGuardrails() *Guardrails {
+	return &Guardrails{
+		MinLoanUSD:     getUintEnv("MIN_LOAN_USD", 10000),
+		MaxTVLShareBps: getUintEnv("MAX_TVL_SHARE_BPS", 2000),
+		MaxSlippageBps: getUintEnv("MAX_SLIPPAGE_BPS", 50),
+		MinProfitUSD:   getUintEnv("MIN_PROFIT_USD", 10),
+	}
+}
+
+// loadSimulationConfig loads simulation escalation thresholds from environment
+func loadSimulationConfig() *SimulationConfig {
+	return &SimulationConfig{
+		EscalateCallUSD: getUintEnv("SIM_ESCALATE_CALL_USD", 100),
+		EscalateForkUSD: getUintEnv("SIM_ESCALATE_FORK_USD", 5000),
+	}
+}
+
+// loadMEVShareConfig loads the MEV-Share endpoints from environment
+func loadMEVShareConfig() *MEVShareConfig {
+	return &MEVShareConfig{
+		Enabled:      getBoolEnv("MEV_SHARE_ENABLED", false),
+		StreamURL:    getEnv("MEV_SHARE_STREAM_URL", "https://mev-share.flashbots.net"),
+		RelayURL:     getEnv("MEV_SHARE_RELAY_URL", "https://relay.flashbots.net"),
+		BackrunInETH: getFloatEnv("MEV_SHARE_BACKRUN_ETH", 1),
+		AuthKey:      getEnv("MEV_SHARE_AUTH_KEY", ""),
+	}
+}
+
+// loadBuilderPaymentConfig loads bundle builder payments from environment
+func loadBuilderPaymentConfig() *BuilderPaymentConfig {
+	return &BuilderPaymentConfig{
+		Mode:     getEnv("BUILDER_PAYMENT_MODE", BuilderPaymentPriorityFee),
+		ShareBps: getUintEnv("BUILDER_PAYMENT_BPS", 0),
+		MaxUSD:   getUintEnv("BUILDER_PAYMENT_MAX_USD", 0),
+	}
+}
+
+// Validate checks the payment mode and share
+func (b *BuilderPaymentConfig) Validate() error {
+	if b.Mode != BuilderPaymentPriorityFee {
+		return fmt.Errorf("unsupported builder payment mode %q: want %s", b.Mode, BuilderPaymentPriorityFee)
+	}
+	if b.ShareBps > 10000 {
+		return fmt.Errorf("builder payment %d bps out of range (0-10000)", b.ShareBps)
+	}
+	return nil
+}
+
+// loadHedgeConfig loads the in-flight hedge venue from environment
+func loadHedgeConfig() *HedgeConfig {
+	markets := make(map[string]string)
+	for _, pair := range getListEnv("HEDGE_MARKETS") {
+		if symbol, market, ok := strings.Cut(pair, "="); ok {
+			markets[strings.ToUpper(strings.TrimSpace(symbol))] = strings.TrimSpace(market)
+		}
+	}
+	if len(markets) == 0 {
+		markets = map[string]string{
+			"WETH": "ETHUSDT", "ETH": "ETHUSDT",
+			"WBTC": "BTCUSDT",
+			"WMATIC": "MATICUSDT", "MATIC": "MATICUSDT",
+			"WBNB": "BNBUSDT", "BNB": "BNBUSDT",
+			"WAVAX": "AVAXUSDT", "AVAX": "AVAXUSDT",
+		}
+	}
+	return &HedgeConfig{
+		Enabled:        getBoolEnv("HEDGE_ENABLED", false),
+		BaseURL:        getEnv("HEDGE_BASE_URL", "https://fapi.binance.com"),
+		APIKey:         getEnv("HEDGE_API_KEY", ""),
+		APISecret:      getEnv("HEDGE_API_SECRET", ""),
+		MinNotionalUSD: getUintEnv("HEDGE_MIN_USD", 1000),
+		Markets:        markets,
+	}
+}
+
+// loadCEXConfig loads the CEX reference feeds from environment
+func loadCEXConfig() *CEXConfig {
+	cfg := &CEXConfig{
+		Enabled:     getBoolEnv("CEX_FEEDS_ENABLED", false),
+		Venues:      getListEnv("CEX_VENUES"),
+		Assets:      getListEnv("CEX_ASSETS"),
+		Stablecoins: getListEnv("CEX_STABLECOINS"),
+		MaxAgeSecs:  getUintEnv("CEX_MAX_AGE_SECONDS", 30),
+		DepegBps:    getUintEnv("DEPEG_ALERT_BPS", 50),
+	}
+	if len(cfg.Venues) == 0 {
+		cfg.Venues = []string{"binance", "coinbase"}
+	}
+	if len(cfg.Assets) == 0 {
+		cfg.Assets = []string{"ETH", "BTC", "MATIC", "BNB", "AVAX", "USDC", "USDT", "DAI"}
+	}
+	if len(cfg.Stablecoins) == 0 {
+		cfg.Stablecoins = []string{"USDC", "USDT", "DAI"}
+	}
+	return cfg
+}
+
+// loadWebhookConfig loads outbound webhooks from environment
+func loadWebhookConfig() *WebhookConfig {
+	events := getListEnv("WEBHOOK_EVENTS")
+	if len(events) == 0 {
+		events = []string{"opportunity", "execution", "reversal", "alert"}
+	}
+	return &WebhookConfig{
+		URLs:   getListEnv("WEBHOOK_URLS"),
+		Secret: getEnv("WEBHOOK_SECRET", ""),
+		Events: events,
+	}
+}
+
+// loadEventBusConfig loads the event bus brokers from environment
+func loadEventBusConfig() *EventBusConfig {
+	return &EventBusConfig{
+		NATSURL:       getEnv("EVENTS_NATS_URL", ""),
+		NATSSubject:   getEnv("EVENTS_NATS_SUBJECT", "titan.events"),
+		NATSJetStream: getBoolEnv("EVENTS_NATS_JETSTREAM", false),
+		RedisURL:      getEnv("EVENTS_REDIS_URL", ""),
+		RedisStream:   getEnv("EVENTS_REDIS_STREAM", "titan:events"),
+		RedisMaxLen:   getUintEnv("EVENTS_REDIS_MAXLEN", 100000),
+		KafkaRESTURL:  getEnv("EVENTS_KAFKA_REST_URL", ""),
+		KafkaTopic:    getEnv("EVENTS_KAFKA_TOPIC", "titan.events"),
+	}
+}
+
+// loadProfilingConfig loads profiling and leak detection from environment
+func loadProfilingConfig() *ProfilingConfig {
+	return &ProfilingConfig{
+		PprofEnabled:  getBoolEnv("PPROF_ENABLED", false),
+		SnapshotSecs:  getUintEnv("PROFILE_SNAPSHOT_SECONDS", 0),
+		SnapshotKeep:  getUintEnv("PROFILE_SNAPSHOT_KEEP", 48),
+		LeakSamples:   getUintEnv("LEAK_CHECK_SAMPLES", 10),
+		MaxGoroutines: getUintEnv("LEAK_MAX_GOROUTINES", 1000),
+	}
+}
+
+// loadHeartbeatConfig loads external heartbeat monitoring from environment
+func loadHeartbeatConfig() *HeartbeatConfig {
+	return &HeartbeatConfig{
+		URL:          getEnv("HEARTBEAT_URL", ""),
+		DeadlineSecs: getUintEnv("HEARTBEAT_DEADLINE_SECONDS", 180),
+		IntervalSecs: getUintEnv("HEARTBEAT_INTERVAL_SECONDS", 30),
+	}
+}
+
+// loadChaosConfig loads fault injection from environment
+func loadChaosConfig() *ChaosConfig {
+	return &ChaosConfig{
+		LatencyMs: getUintEnv("CHAOS_LATENCY_MS", 0),
+		JitterMs:  getUintEnv("CHAOS_JITTER_MS", 0),
+		ErrorRate: getFloatEnv("CHAOS_ERROR_RATE", 0),
+		DropRate:  getFloatEnv("CHAOS_DROP_RATE", 0),
+		Methods:   getListEnv("CHAOS_METHODS"),
+	}
+}
+
+// Enabled reports whether any fault is injected
+func (c *ChaosConfig) Enabled() bool {
+	return c != nil && (c.LatencyMs > 0 || c.JitterMs > 0 || c.ErrorRate > 0 || c.DropRate > 0)
+}
+
+// Validate checks the rates are probabilities and refuses faults in production
+func (c *ChaosConfig) Validate(environment string) error {
+	if !c.Enabled() {
+		return nil
+	}
+	if c.ErrorRate < 0 || c.ErrorRate > 1 || c.DropRate < 0 || c.DropRate > 1 {
+		return fmt.Errorf("chaos rates must be between 0 and 1")
+	}
+	if environment == EnvProduction {
+		return fmt.Errorf("fault injection is disabled in production; set TITAN_ENV to a test environment")
+	}
+	return nil
+}
+
+// loadVolatilityConfig loads the volatility regimes from environment
+func loadVolatilityConfig() *VolatilityConfig {
+	return &VolatilityConfig{
+		WindowSecs:   getUintEnv("VOL_WINDOW_SECONDS", 1800),
+		CalmBps:      getUintEnv("VOL_CALM_BPS", 25),
+		HighBps:      getUintEnv("VOL_HIGH_BPS", 100),
+		CalmScaleBps: getUintEnv("VOL_CALM_SCALE_BPS", 7500),
+		HighScaleBps: getUintEnv("VOL_HIGH_SCALE_BPS", 20000),
+	}
+}
+
+// Validate checks the regimes are ordered and the multipliers positive
+func (v *VolatilityConfig) Validate() error {
+	if v.WindowSecs == 0 {
+		return fmt.Errorf("volatility window must be positive")
+	}
+	if v.CalmBps >= v.HighBps {
+		return fmt.Errorf("calm volatility %d bps must be below high %d bps", v.CalmBps, v.HighBps)
+	}
+	if v.CalmScaleBps == 0 || v.HighScaleBps == 0 {
+		return fmt.Errorf("volatility scales must be positive")
+	}
+	return nil
+}
+
+// loadExposureConfig loads the watched inventory wallets from environment
+func loadExposureConfig() *ExposureConfig {
+	return &ExposureConfig{
+		Wallets:     getListEnv("EXPOSURE_WALLETS"),
+		RefreshSecs: getUintEnv("EXPOSURE_REFRESH_SECONDS", 60),
+	}
+}
+
+// Validate checks the wallets are addresses
+func (e *ExposureConfig) Validate() error {
+	for _, wallet := range e.Wallets {
+		if !common.IsHexAddress(wallet) {
+			return fmt.Errorf("invalid exposure wallet %q", wallet)
+		}
+	}
+	if e.RefreshSecs == 0 {
+		return fmt.Errorf("exposure refresh interval must be positive")
+	}
+	return nil
+}
+
+// loadCanaryConfig loads canary sizing for new routes from environment
+func loadCanaryConfig() *CanaryConfig {
+	return &CanaryConfig{
+		Fills:   getUintEnv("CANARY_FILLS", 3),
+		SizeBps: getUintEnv("CANARY_SIZE_BPS", 1000),
+	}
+}
+
+// Validate checks the canary size is a usable share
+func (c *CanaryConfig) Validate() error {
+	if c.Fills > 0 && (c.SizeBps == 0 || c.SizeBps >= 10000) {
+		return fmt.Errorf("canary size %d bps out of range (1-9999)", c.SizeBps)
+	}
+	return nil
+}
+
+// loadExperimentConfig loads the scoring experiment from environment
+func loadExperimentConfig() *ExperimentConfig {
+	return &ExperimentConfig{
+		Control:           getEnv("EXPERIMENT_CONTROL", "return"),
+		ControlMinScore:   getFloatEnv("EXPERIMENT_CONTROL_MIN_SCORE", 0),
+		Candidate:         getEnv("EXPERIMENT_CANDIDATE", ""),
+		CandidateMinScore: getFloatEnv("EXPERIMENT_CANDIDATE_MIN_SCORE", 0),
+		Mode:              getEnv("EXPERIMENT_MODE", ExperimentShadow),
+		SplitBps:          getUintEnv("EXPERIMENT_SPLIT_BPS", 5000),
+	}
+}
+
+// Validate checks the experiment's mode and split
+func (e *ExperimentConfig) Validate() error {
+	if e.Mode != ExperimentShadow && e.Mode != ExperimentSplit {
+		return fmt.Errorf("unknown experiment mode %q", e.Mode)
+	}
+	if e.SplitBps > 10000 {
+		return fmt.Errorf("experiment split %d bps out of range (0-10000)", e.SplitBps)
+	}
+	return nil
+}
+
+// loadSignalsConfig loads social and news sources from environment
+func loadSignalsConfig() *SignalsConfig {
+	return &SignalsConfig{
+		RSSFeeds:     getListEnv("SIGNALS_RSS_FEEDS"),
+		TwitterToken: getEnv("TWITTER_BEARER_TOKEN", ""),
+		TwitterQuery: getEnv("SIGNALS_TWITTER_QUERY", ""),
+		PollSecs:     getUintEnv("SIGNALS_POLL_SECONDS", 60),
+		WindowMins:   getUintEnv("SIGNALS_WINDOW_MINUTES", 60),
+	}
+}
+
+// Validate checks the sources are usable
+func (s *SignalsConfig) Validate() error {
+	for _, feed := range s.RSSFeeds {
+		if u, err := url.Parse(feed); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("signal feed %q is not an http(s) URL", feed)
+		}
+	}
+	if s.TwitterToken != "" && s.TwitterQuery == "" {
+		return fmt.Errorf("TWITTER_BEARER_TOKEN is set but SIGNALS_TWITTER_QUERY is empty")
+	}
+	if s.PollSecs == 0 || s.WindowMins == 0 {
+		return fmt.Errorf("signal poll interval and window must be positive")
+	}
+	if s.WindowMins >= 24*60 {
+		return fmt.Errorf("signal window must be under the 24h baseline it is compared to")
+	}
+	return nil
+}
+
+// loadPumpGuardConfig loads the pump guard from environment
+func loadPumpGuardConfig() *PumpGuardConfig {
+	return &PumpGuardConfig{
+		Action:  getEnv("PUMP_GUARD_ACTION", PumpGuardBlock),
+		SizeBps: getUintEnv("PUMP_GUARD_SIZE_BPS", 2500),
+	}
+}
+
+// Validate checks the action is known and the downsize is a usable share
+func (p *PumpGuardConfig) Validate() error {
+	switch p.Action {
+	case PumpGuardBlock, PumpGuardOff:
+	case PumpGuardDownsize:
+		if p.SizeBps == 0 || p.SizeBps >= 10000 {
+			return fmt.Errorf("pump guard size %d bps out of range (1-9999)", p.SizeBps)
+		}
+	default:
+		return fmt.Errorf("unknown pump guard action %q", p.Action)
+	}
+	return nil
+}
+
+// loadWhaleConfig loads the whale monitor from environment; WHALE_EXCHANGES
+// is a list of name=address pairs, e.g. "binance=0x28C6...,coinbase=0x71660..."
+func loadWhaleConfig() *WhaleConfig {
+	exchanges := make(map[string]string)
+	for _, pair := range getListEnv("WHALE_EXCHANGES") {
+		name, address, ok := strings.Cut(pair, "=")
+		if !ok {
+			// kept malformed so Validate refuses it
+			name, address = "", pair
+		}
+		exchanges[strings.TrimSpace(address)] = strings.TrimSpace(name)
+	}
+	return &WhaleConfig{
+		Exchanges:  exchanges,
+		MinUSD:     getUintEnv("WHALE_MIN_USD", 1000000),
+		WindowMins: getUintEnv("WHALE_WINDOW_MINUTES", 60),
+		PollSecs:   getUintEnv("WHALE_POLL_SECONDS", 15),
+	}
+}
+
+// Validate checks every exchange is a named address
+func (w *WhaleConfig) Validate() error {
+	for address, name := range w.Exchanges {
+		if name == "" || !common.IsHexAddress(address) {
+			return fmt.Errorf("invalid whale exchange %q (expected name=address)", name+"="+address)
+		}
+	}
+	if w.MinUSD == 0 || w.WindowMins == 0 || w.PollSecs == 0 {
+		return fmt.Errorf("whale threshold, window and poll interval must be positive")
+	}
+	return nil
+}
+
+// loadScoringConfig loads the scoring pipeline from environment.
+// SCORING_STAGES lists stages in order, each optionally weighted as
+// name:weight, and SCORING_<STAGE>_ENABLED=false turns one off without
+// reordering the rest. The older TAR_SCORING_ENABLED, AI_PREDICTION_ENABLED
+// and ROUTE_INTELLIGENCE_ENABLED flags still turn off their stages.
+func loadScoringConfig() *ScoringConfig {
+	legacy := map[string]string{
+		ScoringTAR:    "TAR_SCORING_ENABLED",
+		ScoringML:     "AI_PREDICTION_ENABLED",
+		ScoringRoutes: "ROUTE_INTELLIGENCE_ENABLED",
+	}
+	cfg := &ScoringConfig{MinScore: getFloatEnv("SCORING_MIN_SCORE", 0)}
+	order := getListEnv("SCORING_STAGES")
+	if len(order) == 0 {
+		order = []string{ScoringFilters, ScoringML}
+	}
+	for _, entry := range order {
+		name, weight, weighted := strings.Cut(entry, ":")
+		stage := ScoringStage{Name: strings.ToLower(strings.TrimSpace(name)), Weight: 1}
+		if weighted {
+			w, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+			if err != nil {
+				w = -1 // refused by Validate
+			}
+			stage.Weight = w
+		}
+		enabled := true
+		if flag, ok := legacy[stage.Name]; ok {
+			enabled = getBoolEnv(flag, true)
+		}
+		stage.Enabled = getBoolEnv("SCORING_"+strings.ToUpper(stage.Name)+"_ENABLED", enabled)
+		cfg.Stages = append(cfg.Stages, stage)
+	}
+	return cfg
+}
+
+// Validate checks the pipeline's stages and threshold
+func (s *ScoringConfig) Validate() error {
+	if err := ValidateScoringStages(s.Stages); err != nil {
+		return err
+	}
+	if s.MinScore < 0 || s.MinScore > 1 {
+		return fmt.Errorf("scoring min score %v out of range (0-1)", s.MinScore)
+	}
+	return nil
+}
+
+// ValidateScoringStages checks stages are known, listed once and weighted
+// non-negatively
+func ValidateScoringStages(stages []ScoringStage) error {
+	seen := make(map[string]bool)
+	for _, stage := range stages {
+		switch stage.Name {
+		case ScoringFilters, ScoringTAR, ScoringML, ScoringRoutes, ScoringRisk:
+		default:
+			return fmt.Errorf("unknown scoring stage %q", stage.Name)
+		}
+		if seen[stage.Name] {
+			return fmt.Errorf("scoring stage %q listed twice", stage.Name)
+		}
+		seen[stage.Name] = true
+		if stage.Weight < 0 {
+			return fmt.Errorf("scoring stage %q has a negative or malformed weight", stage.Name)
+		}
+	}
+	return nil
+}
+
+// loadLiquidityConfig loads the pool trend tracker from environment
+func loadLiquidityConfig() *LiquidityConfig {
+	return &LiquidityConfig{
+		Enabled:      getBoolEnv("POOL_TRENDS_ENABLED", false),
+		WindowHours:  getUintEnv("POOL_TREND_WINDOW_HOURS", 24),
+		PollSecs:     getUintEnv("POOL_TREND_POLL_SECONDS", 300),
+		DrainBps:     getUintEnv("POOL_DRAIN_BPS", 2000),
+		DeepUSD:      getUintEnv("POOL_DEEP_USD", 5000000),
+		DeepScaleBps: getUintEnv("POOL_DEEP_SCALE_BPS", 15000),
+		MaxShareBps:  getUintEnv("POOL_MAX_SHARE_BPS", 3000),
+	}
+}
+
+// Validate checks the trend window and share bounds
+func (l *LiquidityConfig) Validate() error {
+	if !l.Enabled {
+		return nil
+	}
+	if l.WindowHours == 0 || l.PollSecs == 0 {
+		return fmt.Errorf("pool trend window and poll interval must be positive")
+	}
+	if l.DrainBps == 0 || l.DrainBps >= 10000 {
+		return fmt.Errorf("pool drain threshold %d bps out of range (1-9999)", l.DrainBps)
+	}
+	if l.DeepScaleBps < 10000 {
+		return fmt.Errorf("pool deep scale %d bps must be at least 10000 (1x)", l.DeepScaleBps)
+	}
+	if l.MaxShareBps == 0 || l.MaxShareBps > 10000 {
+		return fmt.Errorf("pool max share %d bps out of range (1-10000)", l.MaxShareBps)
+	}
+	return nil
+}
+
+// loadMinLoanConfig loads the per-chain minimum trade size from environment
+func loadMinLoanConfig() *MinLoanConfig {
+	return &MinLoanConfig{
+		Dynamic:     getBoolEnv("MIN_LOAN_DYNAMIC", true),
+		EdgeBps:     getUintEnv("MIN_LOAN_EDGE_BPS", 30),
+		FloorUSD:    getUintEnv("MIN_LOAN_FLOOR_USD", 100),
+		CeilingUSD:  getUintEnv("MIN_LOAN_CEILING_USD", 10000),
+		RefreshSecs: getUintEnv("MIN_LOAN_REFRESH_SECONDS", 60),
+	}
+}
+
+// Validate checks the edge and bounds
+func (m *MinLoanConfig) Validate() error {
+	if !m.Dynamic {
+		return nil
+	}
+	if m.EdgeBps == 0 || m.EdgeBps > 10000 {
+		return fmt.Errorf("min loan edge %d bps out of range (1-10000)", m.EdgeBps)
+	}
+	if m.FloorUSD > m.CeilingUSD {
+		return fmt.Errorf("min loan floor $%d above ceiling $%d", m.FloorUSD, m.CeilingUSD)
+	}
+	if m.RefreshSecs == 0 {
+		return fmt.Errorf("min loan refresh interval must be positive")
+	}
+	return nil
+}
+
+// loadSequencerConfig loads the L2 sequencer monitor from environment. The
+// grace period follows Chainlink's advice of an hour after a restart, while
+// the backlog queued during the outage lands.
+func loadSequencerConfig() *SequencerConfig {
+	return &SequencerConfig{
+		Enabled:    getBoolEnv("SEQUENCER_MONITOR_ENABLED", true),
+		GraceSecs:  getUintEnv("SEQUENCER_GRACE_SECONDS", 3600),
+		MaxLagSecs: getUintEnv("SEQUENCER_MAX_LAG_SECONDS", 60),
+		PollSecs:   getUintEnv("SEQUENCER_POLL_SECONDS", 15),
+	}
+}
+
+// Validate checks the poll interval
+func (s *SequencerConfig) Validate() error {
+	if s.Enabled && s.PollSecs == 0 {
+		return fmt.Errorf("sequencer poll interval must be positive")
+	}
+	return nil
+}
+
+// loadTransportConfig loads the RPC transport from environment
+func loadTransportConfig() *TransportConfig {
+	d := DefaultTransport()
+	return &TransportConfig{
+		DialTimeoutMs:   getUintEnv("RPC_DIAL_TIMEOUT_MS", d.DialTimeoutMs),
+		ReadTimeoutMs:   getUintEnv("RPC_READ_TIMEOUT_MS", d.ReadTimeoutMs),
+		MaxIdleConns:    getUintEnv("RPC_MAX_IDLE_CONNS", d.MaxIdleConns),
+		IdleTimeoutSecs: getUintEnv("RPC_IDLE_TIMEOUT_SECONDS", d.IdleTimeoutSecs),
+		HTTP2:           getBoolEnv("RPC_HTTP2", d.HTTP2),
+		Proxy:           getEnv("RPC_PROXY", ""),
+	}
+}
+
+// Validate checks the dial timeout and proxy URL
+func (t *TransportConfig) Validate() error {
+	if t.DialTimeoutMs == 0 {
+		return fmt.Errorf("RPC dial timeout must be positive")
+	}
+	if t.Proxy == "" {
+		return nil
+	}
+	u, err := url.Parse(t.Proxy)
+	if err != nil {
+		return fmt.Errorf("bad RPC proxy: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("RPC proxy %q must be an http, https or socks5 URL", u.Redacted())
+	}
+	if u.Host == "" {
+		return fmt.Errorf("RPC proxy %q has no host", u.Redacted())
+	}
+	return nil
+}
+
+// TransportFor returns the transport for a provider host: the longest
+// ProviderTransport suffix it ends with, else the shared Transport
+func (c *Config) TransportFor(host string) TransportConfig {
+	t, best := DefaultTransport(), -1
+	if c.Transport != nil {
+		t = *c.Transport
+	}
+	for suffix, o := range c.ProviderTransport {
+		if (host == suffix || strings.HasSuffix(host, "."+suffix)) && len(suffix) > best {
+			t, best = *o, len(suffix)
+		}
+	}
+	return t
+}
+
+// DefaultTransport is the transport with no environment overrides
+func DefaultTransport() TransportConfig {
+	return TransportConfig{DialTimeoutMs: 2000, ReadTimeoutMs: 10000, MaxIdleConns: 32, IdleTimeoutSecs: 90, HTTP2: true}
+}
+
+// loadRegionConfig loads this host's region from environment
+func loadRegionConfig() *RegionConfig {
+	return &RegionConfig{
+		Region:    strings.ToLower(getEnv("TITAN_REGION", "")),
+		Failover:  getBoolEnv("RPC_REGION_FAILOVER", false),
+		ProbeSecs: getUintEnv("RPC_LATENCY_PROBE_SECONDS", 30),
+	}
+}
+
+// Validate checks the probe interval
+func (r *RegionConfig) Validate() error {
+	if r.ProbeSecs == 0 {
+		return fmt.Errorf("RPC latency probe interval must be positive")
+	}
+	return nil
+}
+
+// loadClockConfig loads the clock drift check from environment
+func loadClockConfig() *ClockConfig {
+	servers := getListEnv("NTP_SERVERS")
+	if len(servers) == 0 {
+		servers = []string{"time.cloudflare.com", "time.google.com", "pool.ntp.org"}
+	}
+	return &ClockConfig{
+		Enabled:    getBoolEnv("CLOCK_CHECK_ENABLED", true),
+		Servers:    servers,
+		MaxDriftMs: getUintEnv("CLOCK_MAX_DRIFT_MS", 500),
+		PollSecs:   getUintEnv("CLOCK_POLL_SECONDS", 300),
+	}
+}
+
+// Validate checks the threshold and poll interval
+func (c *ClockConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.MaxDriftMs == 0 {
+		return fmt.Errorf("clock drift threshold must be positive")
+	}
+	if c.PollSecs == 0 {
+		return fmt.Errorf("clock poll interval must be positive")
+	}
+	return nil
+}
+
+// loadBridgeRiskConfig loads the bridge timing risk model from environment
+func loadBridgeRiskConfig() *BridgeRiskConfig {
+	return &BridgeRiskConfig{
+		Confidence:     getFloatEnv("BRIDGE_RISK_CONFIDENCE", 1.65),
+		LateShare:      getFloatEnv("BRIDGE_RISK_LATE_SHARE", 0.1),
+		FallbackVolBps: getFloatEnv("BRIDGE_RISK_FALLBACK_VOL_BPS", 100),
+	}
+}
+
+// Validate checks the confidence and late share
+func (c *BridgeRiskConfig) Validate() error {
+	if c.Confidence < 0 {
+		return fmt.Errorf("bridge risk confidence must not be negative")
+	}
+	if c.LateShare < 0 || c.LateShare > 1 {
+		return fmt.Errorf("bridge risk late share must be between 0 and 1")
+	}
+	if c.FallbackVolBps < 0 {
+		return fmt.Errorf("bridge risk fallback volatility must not be negative")
+	}
+	return nil
+}
+
+// loadBridgeCalibrationConfig loads bridge fee calibration from environment
+func loadBridgeCalibrationConfig() *BridgeCalibrationConfig {
+	return &BridgeCalibrationConfig{
+		MinTransfers:      getUintEnv("BRIDGE_CALIBRATION_MIN_TRANSFERS", 5),
+		Window:            getUintEnv("BRIDGE_CALIBRATION_WINDOW", 50),
+		DriftToleranceBps: getUintEnv("BRIDGE_FEE_DRIFT_TOLERANCE_BPS", 5),
+	}
+}
+
+// Validate checks the sample sizes
+func (c *BridgeCalibrationConfig) Validate() error {
+	if c.MinTransfers == 0 {
+		return fmt.Errorf("bridge calibration needs at least one transfer")
+	}
+	if c.Window < c.MinTransfers {
+		return fmt.Errorf("bridge calibration window of %d is below its minimum of %d transfers", c.Window, c.MinTransfers)
+	}
+	return nil
+}
+
+// loadHopConfig loads the Hop Protocol adapter from environment
+func loadHopConfig() *HopConfig {
+	return &HopConfig{
+		Enabled:      getBoolEnv("HOP_ENABLED", true),
+		APIURL:       getEnv("HOP_API_URL", "https://api.hop.exchange"),
+		AddressesURL: getEnv("HOP_ADDRESSES_URL", "https://assets.hop.exchange/mainnet/v1-core-config.json"),
+		SlippageBps:  getUintEnv("HOP_SLIPPAGE_BPS", 50),
+	}
+}
+
+// Validate checks the endpoints and slippage
+func (c *HopConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.APIURL == "" || c.AddressesURL == "" {
+		return fmt.Errorf("hop needs an API and an addresses URL")
+	}
+	if c.SlippageBps == 0 || c.SlippageBps >= 10000 {
+		return fmt.Errorf("hop slippage must be between 1 and 9999 bps")
+	}
+	return nil
+}
+
+// loadCCTPConfig loads the Circle CCTP adapter from environment
+func loadCCTPConfig() *CCTPConfig {
+	return &CCTPConfig{
+		Enabled:        getBoolEnv("CCTP_ENABLED", true),
+		AttestationURL: getEnv("CCTP_ATTESTATION_URL", "https://iris-api.circle.com"),
+		PollSecs:       getUintEnv("CCTP_ATTESTATION_POLL_SECONDS", 15),
+	}
+}
+
+// Validate checks the attestation endpoint and poll interval
+func (c *CCTPConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.AttestationURL == "" {
+		return fmt.Errorf("cctp needs an attestation URL")
+	}
+	if c.PollSecs == 0 {
+		return fmt.Errorf("cctp attestation poll interval must be positive")
+	}
+	return nil
+}
+
+// loadBridgeStatusConfig loads the bridge status page's reference quotes
+// from environment
+func loadBridgeStatusConfig() *BridgeStatusConfig {
+	cfg := &BridgeStatusConfig{
+		RefreshSecs: getUintEnv("BRIDGE_STATUS_REFRESH_SECONDS", 300),
+		FromChain:   getUintEnv("BRIDGE_STATUS_FROM_CHAIN", 137),
+		ToChain:     getUintEnv("BRIDGE_STATUS_TO_CHAIN", 42161),
+		Token:       getEnv("BRIDGE_STATUS_TOKEN", "USDC"),
+	}
+	sizes := getListEnv("BRIDGE_STATUS_SIZES_USD")
+	if len(sizes) == 0 {
+		sizes = []string{"1000", "10000", "100000"}
+	}
+	for _, size := range sizes {
+		n, err := strconv.ParseUint(size, 10, 64)
+		if err != nil {
+			n = 0 // refused by Validate
+		}
+		cfg.SizesUSD = append(cfg.SizesUSD, n)
+	}
+	return cfg
+}
+
+// Validate checks the route, sizes and refresh interval
+func (c *BridgeStatusConfig) Validate() error {
+	if c.RefreshSecs == 0 {
+		return fmt.Errorf("bridge status refresh interval must be positive")
+	}
+	if c.FromChain == c.ToChain {
+		return fmt.Errorf("bridge status route must cross chains, got %d to %d", c.FromChain, c.ToChain)
+	}
+	if c.Token == "" {
+		return fmt.Errorf("bridge status needs a reference token")
+	}
+	for _, size := range c.SizesUSD {
+		if size == 0 {
+			return fmt.Errorf("bridge status reference sizes must be positive whole dollars")
+		}
+	}
+	return nil
+}
+
+// loadGasPrefundConfig loads the destination gas check from environment
+func loadGasPrefundConfig() *GasPrefundConfig {
+	return &GasPrefundConfig{
+		Enabled:         getBoolEnv("GAS_PREFUND_ENABLED", true),
+		DefaultGasLimit: getUintEnv("GAS_PREFUND_DEFAULT_GAS", 600_000),
+		HeadroomBps:     getUintEnv("GAS_PREFUND_HEADROOM_BPS", 15000),
+		Refuel:          getBoolEnv("GAS_REFUEL_ENABLED", false),
+		RefuelContract:  getEnv("GAS_REFUEL_CONTRACT", "0xb584D4bE1A5470CA1a8778E9B86c81e165204599"),
+		RefuelTargetBps: getUintEnv("GAS_REFUEL_TARGET_BPS", 30000),
+		RefuelMaxUSD:    getUintEnv("GAS_REFUEL_MAX_USD", 25),
+	}
+}
+
+// Validate checks the headroom and refuel settings
+func (c *GasPrefundConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.DefaultGasLimit == 0 {
+		return fmt.Errorf("gas prefund default gas limit must be positive")
+	}
+	if c.HeadroomBps < 10000 {
+		return fmt.Errorf("gas prefund headroom of %d bps is below the leg's own gas", c.HeadroomBps)
+	}
+	if !c.Refuel {
+		return nil
+	}
+	if !common.IsHexAddress(c.RefuelContract) {
+		return fmt.Errorf("invalid gas refuel contract %q", c.RefuelContract)
+	}
+	if c.RefuelTargetBps < c.HeadroomBps {
+		return fmt.Errorf("gas refuel target of %d bps is below the %d bps required", c.RefuelTargetBps, c.HeadroomBps)
+	}
+	if c.RefuelMaxUSD == 0 {
+		return fmt.Errorf("gas refuel cap must be positive")
+	}
+	return nil
+}
+
+// loadAllowanceConfig loads the approval hygiene settings from environment
+func loadAllowanceConfig() *AllowanceConfig {
+	return &AllowanceConfig{
+		Enabled:      getBoolEnv("ALLOWANCE_HYGIENE_ENABLED", false),
+		IntervalSecs: getUintEnv("ALLOWANCE_HYGIENE_INTERVAL_SECONDS", 86400),
+		UnusedDays:   getUintEnv("ALLOWANCE_UNUSED_DAYS", 30),
+		LookbackDays: getUintEnv("ALLOWANCE_LOOKBACK_DAYS", 90),
+		Keep:         getListEnv("ALLOWANCE_KEEP_SPENDERS"),
+		AutoRevoke:   getBoolEnv("ALLOWANCE_AUTO_REVOKE", false),
+	}
+}
+
+// Validate checks the windows and kept spenders
+func (c *AllowanceConfig) Validate() error {
+	if c.UnusedDays == 0 {
+		return fmt.Errorf("allowance unused days must be positive")
+	}
+	if c.LookbackDays < c.UnusedDays {
+		return fmt.Errorf("allowance lookback of %d days is shorter than the %d unused days", c.LookbackDays, c.UnusedDays)
+	}
+	if c.Enabled && c.IntervalSecs == 0 {
+		return fmt.Errorf("allowance hygiene interval must be positive")
+	}
+	for _, spender := range c.Keep {
+		if !common.IsHexAddress(spender) {
+			return fmt.Errorf("invalid kept spender %q", spender)
+		}
+	}
+	return nil
+}
+
+// loadRegionRPCs parses a list of region=url pairs
+func loadRegionRPCs(key string) map[string]string {
+	var rpcs map[string]string
+	for _, pair := range getListEnv(key) {
+		region, url, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(url) == "" {
+			continue
+		}
+		if rpcs == nil {
+			rpcs = make(map[string]string)
+		}
+		rpcs[strings.ToLower(strings.TrimSpace(region))] = strings.TrimSpace(url)
+	}
+	return rpcs
+}
+
+// loadModelsConfig loads the model registry from environment
+func loadModelsConfig() *ModelsConfig {
+	return &ModelsConfig{
+		Source: getEnv("MODELS_SOURCE", ""),
+		Names:  getListEnv("MODELS"),
+	}
+}
+
+// Validate checks a registry source names the models it serves
+func (m *ModelsConfig) Validate() error {
+	if m.Source != "" && len(m.Names) == 0 {
+		return fmt.Errorf("MODELS_SOURCE is set but MODELS names no models")
+	}
+	for _, name := range m.Names {
+		if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+			return fmt.Errorf("invalid model name %q", name)
+		}
+	}
+	return nil
+}
+
+// loadRPCPricing parses RPC_PRICING, e.g. "alchemy.com=0.45,quiknode.pro=0.50";
+// unpriced providers are treated as free
+func loadRPCPricing() map[string]float64 {
+	pricing := make(map[string]float64)
+	for _, pair := range getListEnv("RPC_PRICING") {
+		host, price, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		if usd, err := strconv.ParseFloat(strings.TrimSpace(price), 64); err == nil && usd >= 0 {
+			pricing[strings.ToLower(strings.TrimSpace(host))] = usd
+		}
+	}
+	return pricing
+}
+
+// loadLeaderConfig loads leader election from environment
+func loadLeaderConfig() *LeaderConfig {
+	return &LeaderConfig{
+		Backend:    getEnv("LEADER_ELECTION", ""),
+		URL:        getEnv("LEADER_URL", ""),
+		Key:        getEnv("LEADER_KEY", "titan:leader"),
+		TTLSecs:    getUintEnv("LEADER_TTL_SECONDS", 10),
+		InstanceID: getEnv("INSTANCE_ID", ""),
+	}
+}
+
+// Validate checks the backend is known and has an endpoint
+func (l *LeaderConfig) Validate() error {
+	switch l.Backend {
+	case "":
+		return nil
+	case LeaderRedis, LeaderEtcd:
+	default:
+		return fmt.Errorf("unknown leader election backend %q", l.Backend)
+	}
+	if l.URL == "" {
+		return fmt.Errorf("%s leader election needs a URL", l.Backend)
+	}
+	if l.TTLSecs < 3 {
+		return fmt.Errorf("leader TTL %ds too short (minimum 3)", l.TTLSecs)
+	}
+	return nil
+}
+
+// ValidCrossChainPolicy reports whether policy is a known cross-chain atomicity policy
+func ValidCrossChainPolicy(policy string) bool {
+	return policy == CrossChainConfirmFirst || policy == CrossChainPrepositioned
+}
+
+// loadAlertConfig loads alerting channels from environment
+func loadAlertConfig() *AlertConfig {
+	return &AlertConfig{
+		SlackWebhook:     getEnv("SLACK_WEBHOOK", ""),
+		TelegramBotToken: getEnv("TELEGRAM_BOT_TOKEN", ""),
+		TelegramChatID:   getEnv("TELEGRAM_CHAT_ID", ""),
+		SentryDSN:        getEnv("SENTRY_DSN", ""),
+		SentryEnv:        getEnv("SENTRY_ENVIRONMENT", getEnv("TITAN_ENV", EnvProduction)),
+	}
+}
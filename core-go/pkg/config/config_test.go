@@ -0,0 +1,859 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestLoadFromEnv(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if len(config.Chains) < 5 {
+		t.Errorf("Expected at least 5 chains, got %d", len(config.Chains))
+	}
+
+	if config.Chains[1].Name != "ethereum" {
+		t.Errorf("Expected ethereum for chain 1, got %s", config.Chains[1].Name)
+	}
+
+	if config.Chains[137].Name != "polygon" {
+		t.Errorf("Expected polygon for chain 137, got %s", config.Chains[137].Name)
+	}
+}
+
+func TestGetChain(t *testing.T) {
+	config, _ := LoadFromEnv()
+
+	chain, ok := config.GetChain(1)
+	if !ok {
+		t.Error("Expected to find chain 1")
+	}
+
+	if chain.Name != "ethereum" {
+		t.Errorf("Expected ethereum, got %s", chain.Name)
+	}
+
+	_, ok = config.GetChain(999999)
+	if ok {
+		t.Error("Expected not to find chain 999999")
+	}
+}
+
+func TestIsChainSupported(t *testing.T) {
+	config, _ := LoadFromEnv()
+
+	if !config.IsChainSupported(1) {
+		t.Error("Expected chain 1 to be supported")
+	}
+
+	if !config.IsChainSupported(137) {
+		t.Error("Expected chain 137 to be supported")
+	}
+
+	if config.IsChainSupported(999999) {
+		t.Error("Expected chain 999999 to not be supported")
+	}
+}
+
+func TestBalancerV3Vault(t *testing.T) {
+	if BalancerV3Vault != "0xbA1333333333a1BA1108E8412f11850A5C319bA9" {
+		t.Errorf("Expected correct Balancer V3 Vault address, got %s", BalancerV3Vault)
+	}
+}
+
+func TestDexRouterMetadata(t *testing.T) {
+	config, _ := LoadFromEnv()
+
+	ape := config.DexRouters[137]["APE"]
+	if ape == nil {
+		t.Fatal("Expected APE router on chain 137")
+	}
+	if ape.FeeBps != 20 {
+		t.Errorf("Expected APE fee 20 bps, got %d", ape.FeeBps)
+	}
+	if ape.Type != RouterTypeV2 || !ape.SupportsFeeOnTransfer {
+		t.Errorf("Expected fee-on-transfer capable v2 router, got %+v", ape)
+	}
+}
+
+func TestRouterOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routers.json")
+	overrides := `{"137": {"quickswap": {"feeBps": 25, "supportsFeeOnTransfer": false}, "NEWDEX": {"address": "0x0000000000000000000000000000000000000abc", "type": "solidly"}}}`
+	if err := os.WriteFile(path, []byte(overrides), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DEX_ROUTERS_FILE", path)
+
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	quick := config.DexRouters[137]["QUICKSWAP"]
+	if quick.FeeBps != 25 || quick.SupportsFeeOnTransfer {
+		t.Errorf("Expected QUICKSWAP override (25 bps, no FoT), got %+v", quick)
+	}
+	if quick.Address != "0xa5E0829CaCEd8fFDD4De3c43696c57F7D7A678ff" {
+		t.Errorf("Expected QUICKSWAP address to be kept, got %s", quick.Address)
+	}
+	if newDex := config.DexRouters[137]["NEWDEX"]; newDex == nil || newDex.Type != RouterTypeSolidly {
+		t.Errorf("Expected NEWDEX solidly router, got %+v", newDex)
+	}
+
+	// Defaults are not mutated by overrides
+	fresh := loadDexRouters()
+	if fresh[137]["QUICKSWAP"].FeeBps != 30 {
+		t.Errorf("Expected default QUICKSWAP fee 30, got %d", fresh[137]["QUICKSWAP"].FeeBps)
+	}
+}
+
+func TestConfirmations(t *testing.T) {
+	t.Setenv("CONFIRMATIONS_POLYGON", "12")
+	config, _ := LoadFromEnv()
+
+	if got := config.Chains[137].Confirmations; got != 12 {
+		t.Errorf("Expected polygon override of 12 confirmations, got %d", got)
+	}
+	if got := config.Chains[42161].Confirmations; got != 1 {
+		t.Errorf("Expected 1 confirmation on arbitrum, got %d", got)
+	}
+	for id, chain := range config.Chains {
+		if chain.Confirmations == 0 {
+			t.Errorf("Expected chain %d to require at least 1 confirmation", id)
+		}
+	}
+}
+
+func TestSimulationDepth(t *testing.T) {
+	t.Setenv("SIMULATION_DEPTH_ARBITRUM", "quote")
+	t.Setenv("FORK_RPC_ETHEREUM", "http://127.0.0.1:8545")
+	config, _ := LoadFromEnv()
+
+	if got := config.Chains[42161].SimulationDepth; got != SimulationDepthQuote {
+		t.Errorf("Expected quote depth on arbitrum, got %s", got)
+	}
+	if got := config.Chains[137].SimulationDepth; got != SimulationDepthCall {
+		t.Errorf("Expected default call depth on polygon, got %s", got)
+	}
+	if got := config.Chains[1].ForkRPC; got != "http://127.0.0.1:8545" {
+		t.Errorf("Expected ethereum fork RPC, got %q", got)
+	}
+	if config.Simulation.EscalateForkUSD != 5000 {
+		t.Errorf("Expected fork escalation at $5000, got %d", config.Simulation.EscalateForkUSD)
+	}
+}
+
+func TestBuilderPayment(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if config.BuilderPayment.ShareBps != 0 {
+		t.Errorf("Expected builders paid nothing unless opted in, got %d bps", config.BuilderPayment.ShareBps)
+	}
+
+	t.Setenv("BUILDER_PAYMENT_BPS", "9000")
+	t.Setenv("BUILDER_PAYMENT_MAX_USD", "250")
+	config, err = LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if config.BuilderPayment.ShareBps != 9000 || config.BuilderPayment.MaxUSD != 250 {
+		t.Errorf("Expected 9000 bps capped at $250, got %+v", config.BuilderPayment)
+	}
+	if config.BuilderPayment.Mode != BuilderPaymentPriorityFee {
+		t.Errorf("Expected priority_fee mode by default, got %s", config.BuilderPayment.Mode)
+	}
+
+	t.Setenv("BUILDER_PAYMENT_MODE", "coinbase")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Error("Expected error for coinbase payments, which the executor cannot make")
+	}
+	t.Setenv("BUILDER_PAYMENT_MODE", BuilderPaymentPriorityFee)
+
+	t.Setenv("BUILDER_PAYMENT_BPS", "10000")
+	if _, err := LoadFromEnv(); err != nil {
+		t.Errorf("Expected the whole profit to be a valid share, got %v", err)
+	}
+	t.Setenv("BUILDER_PAYMENT_BPS", "10001")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Error("Expected error for a share above 100%")
+	}
+}
+
+func TestCrossChainPolicy(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if config.CrossChainPolicy != CrossChainConfirmFirst {
+		t.Errorf("Expected confirm_first by default, got %s", config.CrossChainPolicy)
+	}
+
+	t.Setenv("CROSS_CHAIN_POLICY", "yolo")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Error("Expected error for an unknown policy")
+	}
+}
+
+func TestHedgeMarkets(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if config.Hedge.Enabled || config.Hedge.Markets["WETH"] != "ETHUSDT" {
+		t.Errorf("Expected hedging off with default markets, got %+v", config.Hedge)
+	}
+
+	t.Setenv("HEDGE_MARKETS", "weth=ETH-PERP, WBTC=BTC-PERP, bogus")
+	config, err = LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if len(config.Hedge.Markets) != 2 || config.Hedge.Markets["WETH"] != "ETH-PERP" {
+		t.Errorf("Expected two overridden markets, got %v", config.Hedge.Markets)
+	}
+}
+
+func TestCEXConfig(t *testing.T) {
+	t.Setenv("CEX_VENUES", "binance")
+	t.Setenv("DEPEG_ALERT_BPS", "100")
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if len(config.CEX.Venues) != 1 || config.CEX.DepegBps != 100 {
+		t.Errorf("Expected binance only with 100 bps depeg alerts, got %+v", config.CEX)
+	}
+	if len(config.CEX.Stablecoins) != 3 || config.CEX.MaxAgeSecs != 30 {
+		t.Errorf("Expected default stablecoins and max age, got %+v", config.CEX)
+	}
+}
+
+func TestWebhookConfig(t *testing.T) {
+	t.Setenv("WEBHOOK_URLS", "https://example.com/hook")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Error("Expected error for webhooks without a secret")
+	}
+
+	t.Setenv("WEBHOOK_SECRET", "s3cret")
+	t.Setenv("WEBHOOK_EVENTS", "alert")
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if len(config.Webhooks.URLs) != 1 || len(config.Webhooks.Events) != 1 || config.Webhooks.Events[0] != "alert" {
+		t.Errorf("Expected one alert-only webhook, got %+v", config.Webhooks)
+	}
+}
+
+func TestEventBusConfig(t *testing.T) {
+	t.Setenv("EVENTS_REDIS_URL", "redis://localhost:6379/1")
+	t.Setenv("EVENTS_NATS_JETSTREAM", "true")
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	bus := config.EventBus
+	if bus.RedisURL == "" || bus.RedisStream != "titan:events" || bus.RedisMaxLen != 100000 {
+		t.Errorf("Expected Redis stream with defaults, got %+v", bus)
+	}
+	if bus.NATSURL != "" || !bus.NATSJetStream || bus.NATSSubject != "titan.events" {
+		t.Errorf("Expected NATS unset with JetStream defaults, got %+v", bus)
+	}
+}
+
+func TestLeaderConfig(t *testing.T) {
+	t.Setenv("LEADER_ELECTION", "redis")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Error("Expected error for leader election without a URL")
+	}
+
+	t.Setenv("LEADER_URL", "redis://localhost:6379")
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if config.Leader.Key != "titan:leader" || config.Leader.TTLSecs != 10 {
+		t.Errorf("Expected default key and TTL, got %+v", config.Leader)
+	}
+}
+
+func TestShardConfig(t *testing.T) {
+	t.Setenv("SHARD_CHAINS", "137,nope")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Error("Expected error for a bad chain ID")
+	}
+	t.Setenv("SHARD_CHAINS", "")
+	t.Setenv("SHARD_COUNT", "3")
+	t.Setenv("SHARD_INDEX", "3")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Error("Expected error for an index outside the shard count")
+	}
+
+	// Every chain lands on exactly one of three shards
+	owners := make(map[uint64]int)
+	var all []uint64
+	for index := uint64(0); index < 3; index++ {
+		t.Setenv("SHARD_INDEX", strconv.FormatUint(index, 10))
+		config, err := LoadFromEnv()
+		if err != nil {
+			t.Fatalf("LoadFromEnv failed: %v", err)
+		}
+		all = config.ApplyShard()
+		for id := range config.Chains {
+			owners[id]++
+		}
+		for id := range config.DexRouters {
+			if _, ok := config.Chains[id]; !ok {
+				t.Errorf("Expected routers for chain %d dropped with the chain", id)
+			}
+		}
+	}
+	if len(owners) != len(all) {
+		t.Errorf("Expected all %d chains covered, got %d", len(all), len(owners))
+	}
+	for id, n := range owners {
+		if n != 1 {
+			t.Errorf("Expected chain %d on one shard, got %d", id, n)
+		}
+	}
+
+	explicit := &ShardConfig{Count: 3, Index: 0, Chains: []uint64{137}}
+	if !explicit.Owns(137) || explicit.Owns(1) || explicit.String() != "chains 137" {
+		t.Errorf("Expected the explicit list to override the hash, got %s", explicit)
+	}
+}
+
+func TestProfilingConfig(t *testing.T) {
+	t.Setenv("PPROF_ENABLED", "true")
+	t.Setenv("PROFILE_SNAPSHOT_SECONDS", "900")
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	p := config.Profiling
+	if !p.PprofEnabled || p.SnapshotSecs != 900 || p.SnapshotKeep != 48 || p.LeakSamples != 10 {
+		t.Errorf("Expected pprof with 15-minute snapshots and defaults, got %+v", p)
+	}
+}
+
+func TestProviderTierConfig(t *testing.T) {
+	t.Setenv("BACKFILL_RPC_POLYGON", "https://polygon-rpc.com")
+	t.Setenv("RPC_PRICING", "Alchemy.com=0.45, quiknode.pro=0.5, bad, infura.io=free")
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if config.Chains[137].BackfillRPC != "https://polygon-rpc.com" || config.Chains[1].BackfillRPC != "" {
+		t.Errorf("Expected a Polygon backfill endpoint only")
+	}
+	if len(config.RPCPricing) != 2 || config.RPCPricing["alchemy.com"] != 0.45 {
+		t.Errorf("Expected two parsed prices, got %v", config.RPCPricing)
+	}
+}
+
+func TestChaosConfig(t *testing.T) {
+	t.Setenv("CHAOS_ERROR_RATE", "0.2")
+	t.Setenv("CHAOS_METHODS", "eth_call, eth_blockNumber")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Fatal("Expected fault injection refused in the default production environment")
+	}
+	t.Setenv("TITAN_ENV", "staging")
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	c := config.Chaos
+	if !c.Enabled() || c.ErrorRate != 0.2 || len(c.Methods) != 2 || config.Alerts.SentryEnv != "staging" {
+		t.Errorf("Expected 20%% errors on two methods in staging, got %+v", c)
+	}
+}
+
+func TestQuoteFreshness(t *testing.T) {
+	t.Setenv("QUOTE_MAX_AGE_MS_ARBITRUM", "500")
+	config, _ := LoadFromEnv()
+
+	if got := config.Chains[42161].QuoteMaxAgeMs; got != 500 {
+		t.Errorf("Expected arbitrum override of 500ms, got %d", got)
+	}
+	if c := config.Chains[1]; c.QuoteMaxAgeMs != 12000 || c.QuoteMaxBlocks != 1 {
+		t.Errorf("Expected 12000ms and 1 block on ethereum, got %dms and %d", c.QuoteMaxAgeMs, c.QuoteMaxBlocks)
+	}
+}
+
+func TestVolatilityConfig(t *testing.T) {
+	t.Setenv("VOL_HIGH_SCALE_BPS", "30000")
+	t.Setenv("MIN_PROFIT_USD", "25")
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if v := config.Volatility; v.HighScaleBps != 30000 || v.CalmScaleBps != 7500 || v.WindowSecs != 1800 {
+		t.Errorf("Expected a 3x high-volatility scale over 30 minutes, got %+v", v)
+	}
+	if got := config.Guardrails.MinProfitUSD; got != 25 {
+		t.Errorf("Expected min profit of $25, got %d", got)
+	}
+
+	t.Setenv("VOL_CALM_BPS", "200")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a calm threshold above the high one rejected")
+	}
+}
+
+func TestExposureConfig(t *testing.T) {
+	t.Setenv("EXPOSURE_WALLETS", "0x00000000000000000000000000000000000000aa, 0x00000000000000000000000000000000000000bb")
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if e := config.Exposure; len(e.Wallets) != 2 || e.RefreshSecs != 60 {
+		t.Errorf("Expected 2 wallets refreshed every minute, got %+v", e)
+	}
+
+	t.Setenv("EXPOSURE_WALLETS", "treasury")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a wallet that isn't an address rejected")
+	}
+}
+
+func TestWatchOnly(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if config.WatchOnly {
+		t.Errorf("Expected execution enabled by default")
+	}
+	t.Setenv("WATCH_ONLY", "true")
+	if config, _ = LoadFromEnv(); !config.WatchOnly {
+		t.Errorf("Expected WATCH_ONLY to enable watch-only mode")
+	}
+}
+
+func TestCanaryConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if c := config.Canary; c.Fills != 3 || c.SizeBps != 1000 {
+		t.Errorf("Expected 3 canary fills at 10%%, got %+v", c)
+	}
+	t.Setenv("CANARY_SIZE_BPS", "10000")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a full-size canary rejected")
+	}
+	t.Setenv("CANARY_FILLS", "0")
+	if _, err := LoadFromEnv(); err != nil {
+		t.Errorf("Expected the size ignored with canaries disabled, got %v", err)
+	}
+}
+
+func TestExperimentConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if e := config.Experiment; e.Candidate != "" || e.Control != "return" || e.Mode != ExperimentShadow {
+		t.Errorf("Expected no experiment by default, got %+v", e)
+	}
+	t.Setenv("EXPERIMENT_MODE", "coin-flip")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected an unknown mode rejected")
+	}
+}
+
+func TestModelsConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if config.Models.Source != "" {
+		t.Errorf("Expected no model registry by default, got %+v", config.Models)
+	}
+	t.Setenv("MODELS_SOURCE", "https://models.example.com/titan")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a source without models rejected")
+	}
+	t.Setenv("MODELS", "fill-probability, ../etc")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a model name escaping the source rejected")
+	}
+	t.Setenv("MODELS", "fill-probability")
+	config, err = LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if len(config.Models.Names) != 1 || config.Models.Names[0] != "fill-probability" {
+		t.Errorf("Expected fill-probability, got %+v", config.Models.Names)
+	}
+}
+
+func TestHFEndpointConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if config.AI.HFEndpointURL != "" || config.AI.HFTimeoutMs != 2000 {
+		t.Errorf("Expected no HF endpoint and a 2s timeout by default, got %+v", config.AI)
+	}
+	t.Setenv("HF_ENDPOINT_URL", "sentiment.example.com")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected an endpoint without a scheme rejected")
+	}
+	t.Setenv("HF_ENDPOINT_URL", "https://sentiment.example.com/v1")
+	t.Setenv("HF_TIMEOUT_MS", "0")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a zero timeout rejected")
+	}
+}
+
+func TestSignalsConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if s := config.Signals; len(s.RSSFeeds) != 0 || s.PollSecs != 60 || s.WindowMins != 60 {
+		t.Errorf("Expected no sources polled every minute by default, got %+v", s)
+	}
+	t.Setenv("TWITTER_BEARER_TOKEN", "token")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a Twitter token without a query rejected")
+	}
+	t.Setenv("SIGNALS_TWITTER_QUERY", "$PEPE")
+	t.Setenv("SIGNALS_RSS_FEEDS", "https://news.example.com/rss, feed.xml")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a relative feed URL rejected")
+	}
+}
+
+func TestPumpGuardConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if g := config.PumpGuard; g.Action != PumpGuardBlock || g.SizeBps != 2500 {
+		t.Errorf("Expected trending routes blocked by default, got %+v", g)
+	}
+	t.Setenv("PUMP_GUARD_ACTION", PumpGuardDownsize)
+	t.Setenv("PUMP_GUARD_SIZE_BPS", "10000")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a downsize to full size rejected")
+	}
+	t.Setenv("PUMP_GUARD_ACTION", "hedge")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected an unknown action rejected")
+	}
+}
+
+func TestWhaleConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if w := config.Whale; len(w.Exchanges) != 0 || w.MinUSD != 1000000 {
+		t.Errorf("Expected no exchanges and a $1M threshold by default, got %+v", w)
+	}
+	t.Setenv("WHALE_EXCHANGES", "binance=0x28C6c06298d514Db089934071355E5743bf21d60")
+	config, err = LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if config.Whale.Exchanges["0x28C6c06298d514Db089934071355E5743bf21d60"] != "binance" {
+		t.Errorf("Expected binance's hot wallet, got %+v", config.Whale.Exchanges)
+	}
+	t.Setenv("WHALE_EXCHANGES", "0x28C6c06298d514Db089934071355E5743bf21d60")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected an unnamed exchange rejected")
+	}
+}
+
+func TestScoringConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if s := config.Scoring.Stages; len(s) != 2 || s[0].Name != ScoringFilters || s[1].Name != ScoringML || !s[1].Enabled {
+		t.Errorf("Expected filters then ml by default, got %+v", s)
+	}
+	t.Setenv("SCORING_STAGES", "risk, filters, tar:0.5, ml:2")
+	t.Setenv("SCORING_ML_ENABLED", "false")
+	t.Setenv("TAR_SCORING_ENABLED", "false")
+	t.Setenv("SCORING_MIN_SCORE", "0.6")
+	config, err = LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	s := config.Scoring.Stages
+	if len(s) != 4 || s[0].Name != ScoringRisk || s[2].Weight != 0.5 || s[3].Weight != 2 || config.Scoring.MinScore != 0.6 {
+		t.Fatalf("Expected the listed order and weights, got %+v", config.Scoring)
+	}
+	if !s[0].Enabled || s[2].Enabled || s[3].Enabled {
+		t.Errorf("Expected tar and ml off by their flags, got %+v", s)
+	}
+	t.Setenv("SCORING_STAGES", "filters,oracle")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected an unknown stage rejected")
+	}
+	t.Setenv("SCORING_STAGES", "filters:heavy")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a malformed weight rejected")
+	}
+}
+
+func TestLiquidityConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if l := config.Liquidity; l.Enabled || l.WindowHours != 24 || l.DrainBps != 2000 || l.DeepScaleBps != 15000 {
+		t.Errorf("Expected the tracker off with a 24h window by default, got %+v", l)
+	}
+	t.Setenv("POOL_TRENDS_ENABLED", "true")
+	t.Setenv("POOL_DEEP_SCALE_BPS", "5000")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a deep scale below 1 rejected")
+	}
+	t.Setenv("POOL_DEEP_SCALE_BPS", "20000")
+	t.Setenv("POOL_MAX_SHARE_BPS", "20000")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a max share above 100%% rejected")
+	}
+}
+
+func TestMinLoanConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if m := config.MinLoan; !m.Dynamic || m.EdgeBps != 30 || m.CeilingUSD != 10000 {
+		t.Errorf("Expected dynamic floors at 30 bps up to $10k by default, got %+v", m)
+	}
+	t.Setenv("MIN_LOAN_FLOOR_USD", "20000")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a floor above the ceiling rejected")
+	}
+	t.Setenv("MIN_LOAN_DYNAMIC", "false")
+	if _, err := LoadFromEnv(); err != nil {
+		t.Errorf("Expected bounds ignored when static, got %v", err)
+	}
+}
+
+func TestSequencerConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if s := config.Sequencer; !s.Enabled || s.GraceSecs != 3600 || s.MaxLagSecs != 60 {
+		t.Errorf("Expected the monitor on with an hour's grace by default, got %+v", s)
+	}
+	if feed := config.Chains[42161].SequencerFeed; feed != "0xFdB631F5EE196F0ed6FAa767959853A9F217697D" {
+		t.Errorf("Expected arbitrum's uptime feed, got %q", feed)
+	}
+	if feed := config.Chains[1].SequencerFeed; feed != "" {
+		t.Errorf("Expected no uptime feed on ethereum, got %q", feed)
+	}
+	t.Setenv("SEQUENCER_FEED_BASE", "0x0000000000000000000000000000000000000001")
+	t.Setenv("SEQUENCER_POLL_SECONDS", "0")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a zero poll interval rejected")
+	}
+	t.Setenv("SEQUENCER_POLL_SECONDS", "5")
+	config, err = LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if feed := config.Chains[8453].SequencerFeed; feed != "0x0000000000000000000000000000000000000001" {
+		t.Errorf("Expected the overridden feed, got %q", feed)
+	}
+}
+
+func TestTransportConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if tr := config.TransportFor("eth-mainnet.g.alchemy.com"); tr != DefaultTransport() {
+		t.Errorf("Expected the default transport, got %+v", tr)
+	}
+	t.Setenv("RPC_PROXY", "ftp://proxy:21")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected an ftp proxy rejected")
+	}
+	t.Setenv("RPC_PROXY", "socks5://127.0.0.1:1080")
+	t.Setenv("RPC_HTTP2", "false")
+	config, err = LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if tr := config.TransportFor("rpc.ankr.com"); tr.Proxy != "socks5://127.0.0.1:1080" || tr.HTTP2 {
+		t.Errorf("Expected the proxied HTTP/1.1 transport, got %+v", tr)
+	}
+}
+
+func TestRegionConfig(t *testing.T) {
+	t.Setenv("TITAN_REGION", "EU-West")
+	t.Setenv("REGION_RPCS_ARBITRUM", "us-east=https://us-east.arb.example, eu-west=https://eu-west.arb.example,bad")
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if r := config.Region; r.Region != "eu-west" || r.Failover || r.ProbeSecs != 30 {
+		t.Errorf("Expected this host in eu-west without failover, got %+v", r)
+	}
+	if rpcs := config.Chains[42161].RegionRPCs; len(rpcs) != 2 || rpcs["eu-west"] != "https://eu-west.arb.example" {
+		t.Errorf("Expected arbitrum's two regional endpoints, got %v", rpcs)
+	}
+	if rpcs := config.Chains[1].RegionRPCs; rpcs != nil {
+		t.Errorf("Expected no regional endpoints on ethereum, got %v", rpcs)
+	}
+	t.Setenv("RPC_LATENCY_PROBE_SECONDS", "0")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a zero probe interval rejected")
+	}
+}
+
+func TestClockConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if c := config.Clock; !c.Enabled || c.MaxDriftMs != 500 || len(c.Servers) != 3 {
+		t.Errorf("Expected the check on against three public servers, got %+v", c)
+	}
+	t.Setenv("NTP_SERVERS", "ntp.internal:123")
+	t.Setenv("CLOCK_MAX_DRIFT_MS", "0")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a zero threshold rejected")
+	}
+	t.Setenv("CLOCK_MAX_DRIFT_MS", "250")
+	config, err = LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if c := config.Clock; len(c.Servers) != 1 || c.Servers[0] != "ntp.internal:123" || c.MaxDriftMs != 250 {
+		t.Errorf("Expected the internal server, got %+v", c)
+	}
+}
+
+func TestBridgeRiskConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if c := config.BridgeRisk; c.Confidence != 1.65 || c.LateShare != 0.1 || c.FallbackVolBps != 100 {
+		t.Errorf("Expected the default risk model, got %+v", c)
+	}
+	t.Setenv("BRIDGE_RISK_LATE_SHARE", "1.5")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a late share above 1 rejected")
+	}
+}
+
+func TestBridgeCalibrationConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if c := config.BridgeCalibration; c.MinTransfers != 5 || c.Window != 50 || c.DriftToleranceBps != 5 {
+		t.Errorf("Expected the default calibration, got %+v", c)
+	}
+	t.Setenv("BRIDGE_CALIBRATION_WINDOW", "3")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a window below the minimum rejected")
+	}
+}
+
+func TestHopConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if c := config.Hop; !c.Enabled || c.APIURL != "https://api.hop.exchange" || c.SlippageBps != 50 {
+		t.Errorf("Expected Hop live against its public API, got %+v", c)
+	}
+	t.Setenv("HOP_SLIPPAGE_BPS", "10000")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected full slippage rejected")
+	}
+}
+
+func TestCCTPConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if c := config.CCTP; !c.Enabled || c.AttestationURL != "https://iris-api.circle.com" || c.PollSecs != 15 {
+		t.Errorf("Expected CCTP on against Circle's attestation API, got %+v", c)
+	}
+	if b := config.IntentBasedBridges["cctp"]; b == nil || b.FeeRangeBps[1] != 0 {
+		t.Errorf("Expected CCTP among the bridges at no fee, got %+v", b)
+	}
+	t.Setenv("CCTP_ATTESTATION_POLL_SECONDS", "0")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a zero poll interval rejected")
+	}
+}
+
+func TestBridgeStatusConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if c := config.BridgeStatus; c.FromChain != 137 || c.ToChain != 42161 || c.Token != "USDC" || len(c.SizesUSD) != 3 || c.SizesUSD[2] != 100_000 {
+		t.Errorf("Expected $1k-$100k USDC quoted from polygon to arbitrum, got %+v", c)
+	}
+	t.Setenv("BRIDGE_STATUS_SIZES_USD", "500,lots")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a malformed reference size rejected")
+	}
+	t.Setenv("BRIDGE_STATUS_SIZES_USD", "")
+	t.Setenv("BRIDGE_STATUS_TO_CHAIN", "137")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a route within one chain rejected")
+	}
+}
+
+func TestGasPrefundConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if c := config.GasPrefund; !c.Enabled || c.Refuel || c.HeadroomBps != 15000 || c.DefaultGasLimit != 600_000 {
+		t.Errorf("Expected the check on at 1.5x without refuel, got %+v", c)
+	}
+	t.Setenv("GAS_REFUEL_ENABLED", "true")
+	t.Setenv("GAS_REFUEL_TARGET_BPS", "12000")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a refuel target below the required balance rejected")
+	}
+	t.Setenv("GAS_REFUEL_TARGET_BPS", "")
+	t.Setenv("GAS_REFUEL_CONTRACT", "bungee")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected an invalid refuel contract rejected")
+	}
+}
+
+func TestAllowanceConfig(t *testing.T) {
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if c := config.Allowances; c.Enabled || c.AutoRevoke || c.UnusedDays != 30 || c.LookbackDays != 90 {
+		t.Errorf("Expected hygiene off, reporting only, over 30 of 90 days, got %+v", c)
+	}
+	t.Setenv("ALLOWANCE_KEEP_SPENDERS", "0xBA12222222228d8Ba445958a75a0704d566BF2C8, across")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected an invalid kept spender rejected")
+	}
+	t.Setenv("ALLOWANCE_KEEP_SPENDERS", "")
+	t.Setenv("ALLOWANCE_LOOKBACK_DAYS", "7")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Errorf("Expected a lookback shorter than the unused window rejected")
+	}
+}
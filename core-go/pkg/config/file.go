@@ -0,0 +1,425 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxIncludeDepth bounds nested includes
+const maxIncludeDepth = 8
+
+// fileConfig is one config file (TITAN_CONFIG) or included fragment, JSON or,
+// with a .yaml or .yml extension, YAML. Fragments are merged in include order,
+// then the including file's own values on top.
+//
+//	{
+//	  "include": ["chains.d/*.json"],
+//	  "dataDir": "/var/lib/titan",
+//	  "chains": {"137": {"rpc": "${RPC_POLYGON}", "confirmations": 8}},
+//	  "dexRouters": {"137": {"QUICKSWAP": {"feeBps": 25}}},
+//	  "tokenLists": ["https://tokens.uniswap.org"],
+//	  "guardrails": {"maxSlippageBps": 30},
+//	  "blackouts": [{"name": "weekend", "days": ["sat", "sun"], "start": "00:00", "end": "24:00", "minProfitScale": 3}],
+//	  "strategies": [{"name": "lst-experiment", "kind": "backrun", "tokens": ["WETH", "wstETH"], "guardrails": {"minProfitUsd": 25}, "maxInFlightUsd": 5000, "scoring": [{"name": "filters"}, {"name": "risk"}]}],
+//	  "scoring": {"stages": [{"name": "filters"}, {"name": "tar", "weight": 0.5}, {"name": "ml", "enabled": false}], "minScore": 0.6},
+//	  "rpcTransport": {"alchemy.com": {"proxy": "socks5://10.0.0.2:1080", "readTimeoutMs": 3000}}
+//	}
+type fileConfig struct {
+	Include    []string                              `json:"include"`
+	DataDir    string                                `json:"dataDir"`
+	Chains     map[uint64]*chainOverride             `json:"chains"`
+	DexRouters map[uint64]map[string]*routerOverride `json:"dexRouters"`
+	TokenLists []string                              `json:"tokenLists"`
+	Guardrails json.RawMessage                       `json:"guardrails"` // omitted limits are kept
+	Blackouts  []BlackoutWindow                      `json:"blackouts"`
+	Strategies []strategyOverride                    `json:"strategies"`
+	Scoring    *ScoringConfig                        `json:"scoring"` // replaces the environment's pipeline
+
+	RPCTransport map[string]json.RawMessage `json:"rpcTransport"` // by provider host suffix; omitted settings keep the shared transport's
+}
+
+// strategyOverride is a strategy instance whose guardrails are merged over
+// the process guardrails, so it only names the limits it changes
+type strategyOverride struct {
+	StrategyConfig
+	Guardrails json.RawMessage `json:"guardrails"`
+}
+
+// chainOverride is a partial ChainConfig; empty or nil fields keep their defaults
+type chainOverride struct {
+	Name             string  `json:"name"`
+	RPC              string  `json:"rpc"`
+	WSS              string  `json:"wss"`
+	AavePool         string  `json:"aavePool"`
+	UniswapRouter    string  `json:"uniswapRouter"`
+	CurveRouter      string  `json:"curveRouter"`
+	Native           string  `json:"native"`
+	WrappedNative    string  `json:"wrappedNative"`
+	NativeUSDFeed    string  `json:"nativeUsdFeed"`
+	Confirmations    *uint64 `json:"confirmations"`
+	GasStationURL    string  `json:"gasStationUrl"`
+	FeeCurrency      string  `json:"feeCurrency"`
+	SimulationDepth  string  `json:"simulationDepth"`
+	ForkRPC          string  `json:"forkRpc"`
+	SequencerRPC     string  `json:"sequencerRpc"`
+	SequencerFeed    string  `json:"sequencerFeed"`
+	BackfillRPC      string  `json:"backfillRpc"`
+	TimeboostAuction string  `json:"timeboostAuction"`
+
+	RegionRPCs map[string]string `json:"regionRpcs"` // merged over the environment's by region
+}
+
+// applyConfigFile merges a config file and its includes over config
+func applyConfigFile(config *Config, path string) error {
+	return applyConfigFileDepth(config, path, make(map[string]bool), 0)
+}
+
+func applyConfigFileDepth(config *Config, path string, visiting map[string]bool, depth int) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if visiting[abs] {
+		return fmt.Errorf("config include cycle at %s", path)
+	}
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("config includes nested deeper than %d at %s", maxIncludeDepth, path)
+	}
+	visiting[abs] = true
+	defer delete(visiting, abs)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	data, err = expandDocument(path, data)
+	if err != nil {
+		return fmt.Errorf("failed to expand config %s: %w", path, err)
+	}
+	var fc fileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	for _, pattern := range fc.Include {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("bad include %q in %s: %w", pattern, path, err)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			if err := applyConfigFileDepth(config, match, visiting, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	if fc.DataDir != "" {
+		config.DataDir = fc.DataDir
+	}
+	config.TokenLists = append(config.TokenLists, fc.TokenLists...)
+	config.Blackouts = append(config.Blackouts, fc.Blackouts...)
+	if fc.Scoring != nil {
+		config.Scoring = fc.Scoring
+	}
+	for chainID, override := range fc.Chains {
+		if err := mergeChain(config.Chains, chainID, override); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := mergeRouterOverrides(config.DexRouters, fc.DexRouters); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if len(fc.Guardrails) > 0 {
+		if config.Guardrails == nil {
+			config.Guardrails = DefaultGuardrails()
+		}
+		if err := json.Unmarshal(fc.Guardrails, config.Guardrails); err != nil {
+			return fmt.Errorf("%s: guardrails: %w", path, err)
+		}
+	}
+	for host, raw := range fc.RPCTransport {
+		t := DefaultTransport()
+		if config.Transport != nil {
+			t = *config.Transport
+		}
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return fmt.Errorf("%s: rpcTransport %q: %w", path, host, err)
+		}
+		if config.ProviderTransport == nil {
+			config.ProviderTransport = make(map[string]*TransportConfig)
+		}
+		config.ProviderTransport[strings.ToLower(host)] = &t
+	}
+	for _, o := range fc.Strategies {
+		strategy := o.StrategyConfig
+		if len(o.Guardrails) > 0 {
+			g := *DefaultGuardrails()
+			if config.Guardrails != nil {
+				g = *config.Guardrails
+			}
+			if err := json.Unmarshal(o.Guardrails, &g); err != nil {
+				return fmt.Errorf("%s: strategy %q guardrails: %w", path, o.Name, err)
+			}
+			strategy.Guardrails = &g
+		}
+		config.Strategies = append(config.Strategies, strategy)
+	}
+	return nil
+}
+
+// mergeChain applies a partial chain entry, adding the chain if it is new
+func mergeChain(chains map[uint64]*ChainConfig, chainID uint64, o *chainOverride) error {
+	chain := chains[chainID]
+	if chain == nil {
+		chain = &ChainConfig{Confirmations: 1, SimulationDepth: SimulationDepthCall}
+	}
+	set := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	set(&chain.Name, o.Name)
+	set(&chain.RPC, o.RPC)
+	set(&chain.WSS, o.WSS)
+	set(&chain.AavePool, o.AavePool)
+	set(&chain.UniswapRouter, o.UniswapRouter)
+	set(&chain.CurveRouter, o.CurveRouter)
+	set(&chain.Native, o.Native)
+	set(&chain.WrappedNative, o.WrappedNative)
+	set(&chain.NativeUSDFeed, o.NativeUSDFeed)
+	set(&chain.GasStationURL, o.GasStationURL)
+	set(&chain.FeeCurrency, o.FeeCurrency)
+	set(&chain.SimulationDepth, o.SimulationDepth)
+	set(&chain.ForkRPC, o.ForkRPC)
+	set(&chain.SequencerRPC, o.SequencerRPC)
+	set(&chain.SequencerFeed, o.SequencerFeed)
+	set(&chain.BackfillRPC, o.BackfillRPC)
+	set(&chain.TimeboostAuction, o.TimeboostAuction)
+	for region, url := range o.RegionRPCs {
+		if chain.RegionRPCs == nil {
+			chain.RegionRPCs = make(map[string]string)
+		}
+		chain.RegionRPCs[strings.ToLower(region)] = url
+	}
+	if o.Confirmations != nil {
+		chain.Confirmations = *o.Confirmations
+	}
+	if chain.Name == "" {
+		return fmt.Errorf("chain %d has no name", chainID)
+	}
+	if !ValidSimulationDepth(chain.SimulationDepth) {
+		return fmt.Errorf("chain %d: unknown simulation depth %q", chainID, chain.SimulationDepth)
+	}
+	chains[chainID] = chain
+	return nil
+}
+
+// envRef matches ${VAR} and ${VAR:-default}
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// wholeEnvRef matches a value that is nothing but one environment reference
+var wholeEnvRef = regexp.MustCompile(`^` + envRef.String() + `$`)
+
+// jsonNumber matches a JSON number literal
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// isYAML reports whether path names a YAML document
+func isYAML(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// expandDocument parses a JSON or YAML document, by path's extension, and
+// returns it as JSON with its environment references expanded
+func expandDocument(path string, data []byte) ([]byte, error) {
+	var doc interface{}
+	if isYAML(path) {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		doc = jsonCompatible(doc)
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return nil, err
+		}
+	}
+	return expandJSON(doc)
+}
+
+// jsonCompatible converts a decoded YAML value's maps to string-keyed ones,
+// so numeric keys such as chain IDs marshal as JSON object keys
+func jsonCompatible(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = jsonCompatible(item)
+		}
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[fmt.Sprint(k)] = jsonCompatible(item)
+		}
+		return out
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonCompatible(item)
+		}
+	}
+	return v
+}
+
+// expandJSON replaces environment references inside the string values of a
+// parsed document and marshals it. Expansion happens after parsing, so values
+// containing quotes cannot alter the document's structure. A value that is a
+// single reference into a numeric or boolean field takes that field's type, so
+// such fields can be set from the environment too; everywhere else it stays a
+// string, however it reads. A reference to an unset variable without a default
+// is an error, so missing secrets fail loudly.
+func expandJSON(doc interface{}) ([]byte, error) {
+	var missing []string
+	doc = expandValue(doc, reflect.TypeOf(fileConfig{}), &missing)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("unset environment variables: %s", strings.Join(missing, ", "))
+	}
+	return json.Marshal(doc)
+}
+
+// lookupRef resolves one ${VAR} or ${VAR:-default} match, recording unset
+// variables without a default
+func lookupRef(m []string, missing *[]string) string {
+	if value, ok := os.LookupEnv(m[1]); ok && value != "" {
+		return value
+	}
+	if m[2] != "" {
+		return m[3]
+	}
+	*missing = append(*missing, m[1])
+	return ""
+}
+
+// expandValue expands v, which decodes into a value of type t; t is nil where
+// the document goes beyond what the loader decodes
+func expandValue(v interface{}, t reflect.Type, missing *[]string) interface{} {
+	t = indirect(t)
+	switch v := v.(type) {
+	case string:
+		if m := wholeEnvRef.FindStringSubmatch(v); m != nil {
+			return typedValue(lookupRef(m, missing), t)
+		}
+		return envRef.ReplaceAllStringFunc(v, func(ref string) string {
+			return lookupRef(envRef.FindStringSubmatch(ref), missing)
+		})
+	case map[string]interface{}:
+		for k, item := range v {
+			var field reflect.Type
+			switch {
+			case t == nil:
+			case t.Kind() == reflect.Struct:
+				field = fieldType(t, k)
+			case t.Kind() == reflect.Map:
+				field = t.Elem()
+			}
+			v[k] = expandValue(item, field, missing)
+		}
+	case []interface{}:
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		for i, item := range v {
+			v[i] = expandValue(item, elem, missing)
+		}
+	}
+	return v
+}
+
+// typedValue returns an expanded reference as a JSON number or boolean when
+// its target field is one and it reads as one, and as a string otherwise
+func typedValue(value string, t reflect.Type) interface{} {
+	if t == nil {
+		return value
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if jsonNumber.MatchString(value) {
+			return json.Number(value)
+		}
+	case reflect.Bool:
+		if value == "true" || value == "false" {
+			return value == "true"
+		}
+	}
+	return value
+}
+
+// rawMessage is the type of fields the loader decodes in a second step
+var rawMessage = reflect.TypeOf(json.RawMessage{})
+
+// rawTargets types those fields by JSON name
+var rawTargets = map[string]reflect.Type{
+	"guardrails":   reflect.TypeOf(Guardrails{}),
+	"rpcTransport": reflect.TypeOf(map[string]TransportConfig{}),
+}
+
+// fieldType returns the type of struct t's field named key in JSON, matched
+// case-insensitively as encoding/json does, or nil when t has none. Declared
+// fields shadow those of embedded structs.
+func fieldType(t reflect.Type, key string) reflect.Type {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		switch {
+		case name == "-" || !f.IsExported() && !f.Anonymous:
+			continue
+		case f.Anonymous && name == "":
+			if inner := indirect(f.Type); inner.Kind() == reflect.Struct {
+				embedded = append(embedded, inner)
+			}
+			continue
+		case name == "":
+			name = f.Name
+		}
+		if !strings.EqualFold(name, key) {
+			continue
+		}
+		if target, ok := rawTargets[name]; ok && (f.Type == rawMessage || f.Type.Kind() == reflect.Map && f.Type.Elem() == rawMessage) {
+			return target
+		}
+		return f.Type
+	}
+	for _, inner := range embedded {
+		if field := fieldType(inner, key); field != nil {
+			return field
+		}
+	}
+	return nil
+}
+
+// indirect returns the type pointers in t point to
+func indirect(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigFileIncludesAndExpansion(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "titan.json"), `{
+		"include": ["chains.d/*.json"],
+		"dataDir": "${TITAN_TEST_HOME}/data",
+		"chains": {"137": {"confirmations": 8}},
+		"guardrails": {"maxSlippageBps": 30},
+		"blackouts": [{"name": "weekend", "days": ["sat", "sun"], "start": "00:00", "end": "24:00", "minProfitScale": 3}]
+	}`)
+	writeFile(t, filepath.Join(dir, "chains.d", "10-polygon.json"), `{
+		"chains": {"137": {"rpc": "${TITAN_TEST_RPC}", "confirmations": 3}},
+		"dexRouters": {"137": {"QUICKSWAP": {"feeBps": 25}}}
+	}`)
+	writeFile(t, filepath.Join(dir, "chains.d", "20-linea.json"), `{
+		"chains": {"59144": {"name": "linea", "rpc": "${TITAN_TEST_LINEA:-https://rpc.linea.build}", "native": "ETH"}}
+	}`)
+	t.Setenv("TITAN_CONFIG", filepath.Join(dir, "titan.json"))
+	t.Setenv("TITAN_TEST_HOME", "/srv/titan")
+	t.Setenv("TITAN_TEST_RPC", `https://polygon.example/key?a="b"`)
+
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.DataDir != "/srv/titan/data" {
+		t.Errorf("Expected expanded data dir, got %s", config.DataDir)
+	}
+	polygon := config.Chains[137]
+	if polygon.RPC != `https://polygon.example/key?a="b"` {
+		t.Errorf("Expected RPC from env, got %s", polygon.RPC)
+	}
+	if polygon.Confirmations != 8 {
+		t.Errorf("Expected including file to override fragment confirmations, got %d", polygon.Confirmations)
+	}
+	if polygon.Native != "MATIC" {
+		t.Errorf("Expected unset fields to keep defaults, got native %q", polygon.Native)
+	}
+	if config.DexRouters[137]["QUICKSWAP"].FeeBps != 25 {
+		t.Errorf("Expected router override from fragment, got %d", config.DexRouters[137]["QUICKSWAP"].FeeBps)
+	}
+	if g := config.Guardrails; g.MaxSlippageBps != 30 || g.MaxTVLShareBps != 2000 {
+		t.Errorf("Expected slippage from the file and other limits kept, got %+v", g)
+	}
+	linea, ok := config.Chains[59144]
+	if !ok || linea.RPC != "https://rpc.linea.build" || linea.Confirmations != 1 {
+		t.Errorf("Expected new linea chain with default RPC, got %+v", linea)
+	}
+	if len(config.Blackouts) != 1 || config.Blackouts[0].Name != "weekend" || config.Blackouts[0].MinProfitScale != 3 {
+		t.Errorf("Expected the weekend blackout from the file, got %+v", config.Blackouts)
+	}
+}
+
+func TestConfigFileYAMLAndTypedExpansion(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "titan.json"), `{
+		"include": ["chains.d/*.yaml"],
+		"guardrails": {"maxSlippageBps": "${TITAN_TEST_SLIPPAGE}"},
+		"strategies": [{"name": "${TITAN_TEST_STRATEGY}", "kind": "backrun", "guardrails": {"minProfitUsd": "${TITAN_TEST_SLIPPAGE}"}}]
+	}`)
+	writeFile(t, filepath.Join(dir, "chains.d", "polygon.yaml"), `
+chains:
+  137:
+    name: ${TITAN_TEST_CHAIN_NAME}
+    rpc: ${TITAN_TEST_RPC}
+    confirmations: ${TITAN_TEST_CONFIRMATIONS}
+dexRouters:
+  137:
+    QUICKSWAP:
+      feeBps: 25
+blackouts:
+  - name: weekend
+    days: [sat, sun]
+    start: "00:00"
+    end: "24:00"
+    minProfitScale: ${TITAN_TEST_SCALE:-2.5}
+`)
+	t.Setenv("TITAN_CONFIG", filepath.Join(dir, "titan.json"))
+	t.Setenv("TITAN_TEST_RPC", "https://polygon.example/12345")
+	t.Setenv("TITAN_TEST_CONFIRMATIONS", "12")
+	t.Setenv("TITAN_TEST_SLIPPAGE", "40")
+	// Values that read as numbers or booleans stay strings in string fields
+	t.Setenv("TITAN_TEST_CHAIN_NAME", "137")
+	t.Setenv("TITAN_TEST_STRATEGY", "true")
+
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	polygon := config.Chains[137]
+	if polygon.Name != "137" || polygon.RPC != "https://polygon.example/12345" || polygon.Confirmations != 12 {
+		t.Errorf("Expected RPC and confirmations from the YAML fragment's env, got %+v", polygon)
+	}
+	if config.DexRouters[137]["QUICKSWAP"].FeeBps != 25 {
+		t.Errorf("Expected router override from the YAML fragment, got %d", config.DexRouters[137]["QUICKSWAP"].FeeBps)
+	}
+	if config.Guardrails.MaxSlippageBps != 40 {
+		t.Errorf("Expected numeric guardrail from env, got %d", config.Guardrails.MaxSlippageBps)
+	}
+	if len(config.Strategies) != 1 || config.Strategies[0].Name != "true" || config.Strategies[0].Guardrails.MinProfitUSD != 40 {
+		t.Errorf("Expected a strategy named from env with a numeric guardrail, got %+v", config.Strategies)
+	}
+	if len(config.Blackouts) != 1 || config.Blackouts[0].MinProfitScale != 2.5 || len(config.Blackouts[0].Days) != 2 {
+		t.Errorf("Expected the YAML blackout with its defaulted scale, got %+v", config.Blackouts)
+	}
+
+	t.Setenv("TITAN_TEST_CONFIRMATIONS", "twelve")
+	if _, err := LoadFromEnv(); err == nil || !strings.Contains(err.Error(), "polygon.yaml") {
+		t.Errorf("Expected a non-numeric confirmations value rejected, got %v", err)
+	}
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "secret.json"), `{"chains": {"1": {"rpc": "${TITAN_TEST_UNSET_KEY}"}}}`)
+	t.Setenv("TITAN_CONFIG", filepath.Join(dir, "secret.json"))
+	if _, err := LoadFromEnv(); err == nil || !strings.Contains(err.Error(), "TITAN_TEST_UNSET_KEY") {
+		t.Errorf("Expected unset variable to be reported, got %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "a.json"), `{"include": ["b.json"]}`)
+	writeFile(t, filepath.Join(dir, "b.json"), `{"include": ["a.json"]}`)
+	t.Setenv("TITAN_CONFIG", filepath.Join(dir, "a.json"))
+	if _, err := LoadFromEnv(); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Expected include cycle error, got %v", err)
+	}
+}
+
+func TestConfigFileStrategies(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "titan.json"), `{
+		"guardrails": {"maxSlippageBps": 30},
+		"strategies": [
+			{"name": "lst", "kind": "backrun", "tokens": ["WETH", "wstETH"], "guardrails": {"minProfitUsd": 25}, "dailyLossUsd": 200},
+			{"name": "wide", "kind": "backrun", "wallets": ["0x00000000000000000000000000000000000000a1"]}
+		]
+	}`)
+	t.Setenv("TITAN_CONFIG", filepath.Join(dir, "titan.json"))
+
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if len(config.Strategies) != 2 {
+		t.Fatalf("Expected 2 strategies, got %+v", config.Strategies)
+	}
+	lst := config.Strategies[0]
+	if g := lst.Guardrails; g == nil || g.MinProfitUSD != 25 || g.MaxSlippageBps != 30 {
+		t.Errorf("Expected lst's profit floor over the process guardrails, got %+v", g)
+	}
+	if len(lst.Tokens) != 2 || lst.DailyLossUSD != 200 {
+		t.Errorf("Expected lst's universe and budget, got %+v", lst)
+	}
+	if config.Strategies[1].Guardrails != nil {
+		t.Errorf("Expected wide to follow the live guardrails, got %+v", config.Strategies[1].Guardrails)
+	}
+
+	writeFile(t, filepath.Join(dir, "titan.json"), `{
+		"scoring": {"stages": [{"name": "filters"}, {"name": "ml", "enabled": false}, {"name": "tar", "weight": 0.5}], "minScore": 0.5},
+		"strategies": [{"name": "fast", "kind": "backrun", "scoring": [{"name": "filters"}, {"name": "risk"}]}]
+	}`)
+	config, err = LoadFromEnv()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if s := config.Scoring.Stages; len(s) != 3 || !s[0].Enabled || s[0].Weight != 1 || s[1].Enabled || s[2].Weight != 0.5 {
+		t.Errorf("Expected the file's pipeline with omitted fields defaulted, got %+v", s)
+	}
+	if s := config.Strategies[0].Scoring; len(s) != 2 || s[1].Name != ScoringRisk {
+		t.Errorf("Expected fast to skip ml, got %+v", s)
+	}
+	writeFile(t, filepath.Join(dir, "titan.json"), `{"strategies": [{"name": "x", "kind": "backrun", "scoring": [{"name": "ml"}, {"name": "ml"}]}]}`)
+	if _, err := LoadFromEnv(); err == nil || !strings.Contains(err.Error(), "twice") {
+		t.Errorf("Expected a repeated stage rejected, got %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "titan.json"), `{"strategies": [{"name": "main", "kind": "backrun"}]}`)
+	if _, err := LoadFromEnv(); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("Expected the reserved main name rejected, got %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "titan.json"), `{"strategies": [{"name": "x", "kind": "sandwich"}]}`)
+	if _, err := LoadFromEnv(); err == nil || !strings.Contains(err.Error(), "unknown kind") {
+		t.Errorf("Expected an unknown kind rejected, got %v", err)
+	}
+}
+
+func TestConfigFileTransport(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "titan.json"), `{
+		"rpcTransport": {
+			"alchemy.com": {"proxy": "http://egress:3128", "readTimeoutMs": 3000},
+			"g.alchemy.com": {"http2": false}
+		}
+	}`)
+	t.Setenv("TITAN_CONFIG", filepath.Join(dir, "titan.json"))
+	t.Setenv("RPC_MAX_IDLE_CONNS", "64")
+
+	config, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if tr := config.TransportFor("arb-mainnet.alchemy.com"); tr.Proxy != "http://egress:3128" || tr.ReadTimeoutMs != 3000 || tr.MaxIdleConns != 64 {
+		t.Errorf("Expected alchemy's proxy over the shared transport, got %+v", tr)
+	}
+	if tr := config.TransportFor("eth-mainnet.g.alchemy.com"); tr.HTTP2 || tr.Proxy != "" {
+		t.Errorf("Expected the longest suffix to win, got %+v", tr)
+	}
+	if tr := config.TransportFor("notalchemy.com"); tr.Proxy != "" {
+		t.Errorf("Expected only whole labels to match, got %+v", tr)
+	}
+
+	writeFile(t, filepath.Join(dir, "titan.json"), `{"rpcTransport": {"ankr.com": {"dialTimeoutMs": 0}}}`)
+	if _, err := LoadFromEnv(); err == nil || !strings.Contains(err.Error(), "ankr.com") {
+		t.Errorf("Expected a zero dial timeout rejected, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ShardConfig splits the configured chains across instances, either by
+// chain ID hash or by an explicit list, so no one process runs all of them
+type ShardConfig struct {
+	Count  uint64   // instances sharing the chains by hash; 1 runs everything
+	Index  uint64   // this instance's position, 0 to Count-1
+	Chains []uint64 // explicit chain IDs; overrides the hash when set
+	Peers  []string // other instances' API base URLs, for cluster status
+}
+
+// ShardOf returns the index of the instance that owns chainID among count;
+// FNV-1a over the big-endian ID keeps it stable across releases and languages
+func ShardOf(chainID, count uint64) uint64 {
+	if count <= 1 {
+		return 0
+	}
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], chainID)
+	h := fnv.New64a()
+	h.Write(b[:])
+	return h.Sum64() % count
+}
+
+// Owns reports whether this instance runs chainID
+func (s *ShardConfig) Owns(chainID uint64) bool {
+	if s == nil {
+		return true
+	}
+	if len(s.Chains) > 0 {
+		for _, id := range s.Chains {
+			if id == chainID {
+				return true
+			}
+		}
+		return false
+	}
+	return ShardOf(chainID, s.Count) == s.Index
+}
+
+// String describes the assignment for logs and status
+func (s *ShardConfig) String() string {
+	if s == nil {
+		return "all"
+	}
+	if len(s.Chains) > 0 {
+		ids := make([]string, len(s.Chains))
+		for i, id := range s.Chains {
+			ids[i] = strconv.FormatUint(id, 10)
+		}
+		return "chains " + strings.Join(ids, ",")
+	}
+	if s.Count <= 1 {
+		return "all"
+	}
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// Validate checks the index is within the shard count
+func (s *ShardConfig) Validate() error {
+	if s.Count == 0 {
+		return fmt.Errorf("shard count must be at least 1")
+	}
+	if s.Index >= s.Count {
+		return fmt.Errorf("shard index %d out of range for %d shards", s.Index, s.Count)
+	}
+	return nil
+}
+
+// ApplyShard drops the chains and routers other instances own; it returns
+// the chain IDs that were configured before, for cluster coverage checks
+func (c *Config) ApplyShard() []uint64 {
+	all := make([]uint64, 0, len(c.Chains))
+	for id := range c.Chains {
+		all = append(all, id)
+		if !c.Shard.Owns(id) {
+			delete(c.Chains, id)
+			delete(c.DexRouters, id)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	return all
+}
+
+// loadShardConfig loads the chain assignment from environment
+func loadShardConfig() (*ShardConfig, error) {
+	s := &ShardConfig{
+		Count: getUintEnv("SHARD_COUNT", 1),
+		Index: getUintEnv("SHARD_INDEX", 0),
+		Peers: getListEnv("SHARD_PEERS"),
+	}
+	for _, item := range getListEnv("SHARD_CHAINS") {
+		id, err := strconv.ParseUint(item, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("SHARD_CHAINS: bad chain ID %q", item)
+		}
+		s.Chains = append(s.Chains, id)
+	}
+	return s, nil
+}
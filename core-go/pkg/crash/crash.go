@@ -0,0 +1,158 @@
+package crash
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/alert"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/profile"
+)
+
+// Default restart backoff bounds
+const (
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = time.Minute
+)
+
+// reportTimeout bounds delivering one crash to every reporter
+const reportTimeout = 10 * time.Second
+
+// Crash is a recovered panic in a supervised component
+type Crash struct {
+	Module   string
+	Value    string // the panic value
+	Stack    string
+	Time     time.Time
+	Restarts int // restarts of the module so far, including the one this crash triggers
+}
+
+// Reporter delivers crash reports
+type Reporter interface {
+	Name() string
+	Report(ctx context.Context, c Crash) error
+}
+
+// Supervisor runs long-lived components, recovering their panics: each crash
+// is counted, reported, and the component restarted with exponential
+// backoff instead of taking the daemon down
+type Supervisor struct {
+	// MinBackoff and MaxBackoff bound the delay before a restart; a component
+	// that ran longer than MaxBackoff before crashing restarts after MinBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	reporters []Reporter
+	crashes   *metrics.CounterVec
+}
+
+// NewSupervisor creates a supervisor counting crashes in reg (which may be
+// nil) and reporting them to reporters
+func NewSupervisor(reg *metrics.Registry, reporters ...Reporter) *Supervisor {
+	s := &Supervisor{MinBackoff: DefaultMinBackoff, MaxBackoff: DefaultMaxBackoff, reporters: reporters}
+	if reg != nil {
+		s.crashes = reg.Counter("titan_crashes_total", "Recovered panics by module", "module")
+	}
+	return s
+}
+
+// Go runs fn in a new goroutine labelled with module, as profile.Go does,
+// under supervision
+func (s *Supervisor) Go(ctx context.Context, module string, fn func(ctx context.Context)) {
+	profile.Go(ctx, module, func(ctx context.Context) { s.Run(ctx, module, fn) })
+}
+
+// Run calls fn until it returns without panicking or ctx is done
+func (s *Supervisor) Run(ctx context.Context, module string, fn func(ctx context.Context)) {
+	backoff := s.MinBackoff
+	for restarts := 1; ; restarts++ {
+		started := time.Now()
+		c := call(ctx, module, fn)
+		if c == nil || ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > s.MaxBackoff {
+			backoff = s.MinBackoff
+		}
+		c.Restarts = restarts
+		if s.crashes != nil {
+			s.crashes.Inc(module)
+		}
+		log.Printf("💥 %s panicked: %s; restarting in %s (restart %d)", module, c.Value, backoff, restarts)
+		s.report(*c)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > s.MaxBackoff {
+			backoff = s.MaxBackoff
+		}
+	}
+}
+
+// call runs fn once, returning its panic if it had one
+func call(ctx context.Context, module string, fn func(ctx context.Context)) (c *Crash) {
+	defer func() {
+		if v := recover(); v != nil {
+			c = &Crash{Module: module, Value: fmt.Sprint(v), Stack: string(debug.Stack()), Time: time.Now().UTC()}
+		}
+	}()
+	fn(ctx)
+	return nil
+}
+
+// report delivers c to every reporter; it outlives the component's context
+// so crashes during shutdown are still reported
+func (s *Supervisor) report(c Crash) {
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+	for _, r := range s.reporters {
+		if err := r.Report(ctx, c); err != nil {
+			log.Printf("⚠️ Crash report via %s failed: %v", r.Name(), err)
+		}
+	}
+}
+
+// maxAlertStack bounds the stack trace included in an alert
+const maxAlertStack = 3000
+
+// AlertReporter reports crashes through the operator alert channels
+type AlertReporter struct {
+	Alerts *alert.Dispatcher
+}
+
+// Name implements Reporter
+func (AlertReporter) Name() string { return "alerts" }
+
+// Report implements Reporter
+func (a AlertReporter) Report(ctx context.Context, c Crash) error {
+	stack := c.Stack
+	if len(stack) > maxAlertStack {
+		stack = stack[:maxAlertStack] + "\n…"
+	}
+	return a.Alerts.Notify(ctx, alert.Message{
+		Level: alert.LevelCritical,
+		Title: fmt.Sprintf("%s panicked (restart %d)", c.Module, c.Restarts),
+		Body:  fmt.Sprintf("%s\n```\n%s\n```", c.Value, stack),
+	})
+}
+
+// FromConfig creates a supervisor reporting crashes through alerts and, when
+// a DSN is configured, Sentry
+func FromConfig(cfg *config.AlertConfig, alerts *alert.Dispatcher, reg *metrics.Registry) (*Supervisor, error) {
+	reporters := []Reporter{AlertReporter{Alerts: alerts}}
+	if cfg != nil && cfg.SentryDSN != "" {
+		sentry, err := NewSentryReporter(cfg.SentryDSN, cfg.SentryEnv)
+		if err != nil {
+			return nil, err
+		}
+		reporters = append(reporters, sentry)
+	}
+	return NewSupervisor(reg, reporters...), nil
+}
@@ -0,0 +1,108 @@
+package crash
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+type recordingReporter struct {
+	mu      sync.Mutex
+	crashes []Crash
+}
+
+func (r *recordingReporter) Name() string { return "recording" }
+
+func (r *recordingReporter) Report(ctx context.Context, c Crash) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.crashes = append(r.crashes, c)
+	return nil
+}
+
+func TestSupervisorRestartsAfterPanic(t *testing.T) {
+	reg := metrics.NewRegistry()
+	reporter := &recordingReporter{}
+	s := NewSupervisor(reg, reporter)
+	s.MinBackoff, s.MaxBackoff = time.Millisecond, 4*time.Millisecond
+
+	calls := 0
+	s.Run(context.Background(), "scanner", func(ctx context.Context) {
+		calls++
+		if calls <= 3 {
+			var m map[string]int
+			m["boom"]++ // nil map write
+		}
+	})
+
+	if calls != 4 {
+		t.Errorf("Expected 3 restarts and a clean final run, got %d calls", calls)
+	}
+	if got := reg.Value("titan_crashes_total", "scanner"); got != 3 {
+		t.Errorf("Expected 3 crashes counted, got %v", got)
+	}
+	if len(reporter.crashes) != 3 {
+		t.Fatalf("Expected 3 reports, got %d", len(reporter.crashes))
+	}
+	c := reporter.crashes[2]
+	if c.Module != "scanner" || c.Restarts != 3 || !strings.Contains(c.Value, "nil map") || !strings.Contains(c.Stack, "crash_test.go") {
+		t.Errorf("Expected the third crash with its stack, got %+v", c)
+	}
+}
+
+func TestSupervisorStopsWithContext(t *testing.T) {
+	s := NewSupervisor(nil)
+	s.MinBackoff = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx, "execution", func(ctx context.Context) { panic("boom") })
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the supervisor to stop waiting to restart once cancelled")
+	}
+}
+
+func TestSentryReporter(t *testing.T) {
+	var gotPath, gotAuth string
+	var event sentryEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("X-Sentry-Auth")
+		json.NewDecoder(r.Body).Decode(&event)
+	}))
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "http://", "http://pubkey@", 1) + "/sentry/42"
+	sentry, err := NewSentryReporter(dsn, "staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sentry.Report(context.Background(), Crash{Module: "strategy", Value: "boom", Stack: "goroutine 1", Time: time.Now(), Restarts: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/sentry/api/42/store/" {
+		t.Errorf("Expected the project store endpoint, got %s", gotPath)
+	}
+	if !strings.Contains(gotAuth, "sentry_key=pubkey") {
+		t.Errorf("Expected the DSN key in the auth header, got %s", gotAuth)
+	}
+	if event.Tags["module"] != "strategy" || event.Environment != "staging" || event.Extra["stack"] != "goroutine 1" || len(event.EventID) != 32 {
+		t.Errorf("Unexpected event %+v", event)
+	}
+
+	if _, err := NewSentryReporter("https://o1.ingest.sentry.io/42", ""); err == nil {
+		t.Errorf("Expected a DSN without a key rejected")
+	}
+}
@@ -0,0 +1,103 @@
+package crash
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// SentryReporter sends crashes to Sentry's store endpoint
+type SentryReporter struct {
+	endpoint    string
+	auth        string
+	environment string
+	client      *http.Client
+}
+
+// NewSentryReporter creates a reporter from a project DSN such as
+// https://<key>@o123.ingest.sentry.io/456
+func NewSentryReporter(dsn, environment string) (*SentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sentry DSN: %w", err)
+	}
+	key := u.User.Username()
+	project := path.Base(u.Path)
+	if key == "" || u.Host == "" || project == "." || project == "/" {
+		return nil, fmt.Errorf("sentry DSN %q: want scheme://key@host/project", u.Redacted())
+	}
+	prefix := strings.TrimSuffix(path.Dir(u.Path), "/")
+	return &SentryReporter{
+		endpoint:    fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, prefix, project),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=titan-core/1.0, sentry_key=%s", key),
+		environment: environment,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// sentryEvent is the subset of Sentry's event payload Titan sends
+type sentryEvent struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Platform    string                 `json:"platform"`
+	Level       string                 `json:"level"`
+	Logger      string                 `json:"logger"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Message     string                 `json:"message"`
+	Tags        map[string]string      `json:"tags"`
+	Extra       map[string]interface{} `json:"extra"`
+}
+
+// Name implements Reporter
+func (s *SentryReporter) Name() string { return "sentry" }
+
+// Report implements Reporter
+func (s *SentryReporter) Report(ctx context.Context, c Crash) error {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	host, _ := os.Hostname()
+	body, err := json.Marshal(sentryEvent{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   c.Time.UTC().Format(time.RFC3339),
+		Platform:    "go",
+		Level:       "fatal",
+		Logger:      c.Module,
+		ServerName:  host,
+		Environment: s.environment,
+		Message:     fmt.Sprintf("panic in %s: %s", c.Module, c.Value),
+		Tags:        map[string]string{"module": c.Module},
+		Extra:       map[string]interface{}{"stack": c.Stack, "restarts": c.Restarts},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sentry: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
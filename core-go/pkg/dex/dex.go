@@ -0,0 +1,324 @@
+package dex
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+)
+
+const dexABI = `[
+{"inputs":[],"name":"factory","outputs":[{"type":"address"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"type":"address"},{"type":"address"}],"name":"getPair","outputs":[{"type":"address"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"type":"address"},{"type":"address"},{"type":"uint24"}],"name":"getPool","outputs":[{"type":"address"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"getReserves","outputs":[{"type":"uint112"},{"type":"uint112"},{"type":"uint32"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"token0","outputs":[{"type":"address"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"token1","outputs":[{"type":"address"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"liquidity","outputs":[{"type":"uint128"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"slot0","outputs":[{"type":"uint160"}],"stateMutability":"view","type":"function"}
+]`
+
+// V3FeeTiers are the standard UniswapV3 fee tiers in hundredths of a basis point
+var V3FeeTiers = []uint32{100, 500, 3000, 10000}
+
+// V2FeeBps is the standard UniswapV2 swap fee
+const V2FeeBps = 30
+
+var parsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(dexABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+var q96 = new(big.Int).Lsh(big.NewInt(1), 96)
+
+// Backend is the chain access discovery needs
+type Backend interface {
+	ethereum.ContractCaller
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// Router is a DEX router address and its pool model
+type Router struct {
+	Name                  string
+	Address               common.Address
+	Kind                  string
+	FeeBps                uint32         // 0 uses V2FeeBps for V2 pools
+	Factory               common.Address // zero resolves via router.factory()
+	SupportsFeeOnTransfer bool
+}
+
+// RoutersFromConfig lists the routers configured for a chain: every DexRouters
+// entry plus the chain's Uniswap router as V3. Aggregators have no pools and are
+// skipped; routers with no type (see DetectRouterTypes) are treated as V2.
+func RoutersFromConfig(cfg *config.Config, chainID uint64) []Router {
+	var routers []Router
+	names := make([]string, 0, len(cfg.DexRouters[chainID]))
+	for name := range cfg.DexRouters[chainID] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rc := cfg.DexRouters[chainID][name]
+		if rc.Type == config.RouterTypeAggregator {
+			continue
+		}
+		kind := reserves.KindV2
+		if rc.Type == config.RouterTypeV3 {
+			kind = reserves.KindV3
+		}
+		routers = append(routers, Router{
+			Name:                  name,
+			Address:               common.HexToAddress(rc.Address),
+			Kind:                  kind,
+			FeeBps:                rc.FeeBps,
+			Factory:               common.HexToAddress(rc.Factory),
+			SupportsFeeOnTransfer: rc.SupportsFeeOnTransfer,
+		})
+	}
+
+	if chain, ok := cfg.GetChain(chainID); ok && chain.UniswapRouter != "" {
+		addr := common.HexToAddress(chain.UniswapRouter)
+		if addr != (common.Address{}) {
+			routers = append(routers, Router{Name: "UNIV3", Address: addr, Kind: reserves.KindV3})
+		}
+	}
+	return routers
+}
+
+// Discovery finds pools for token pairs through router factories
+type Discovery struct {
+	chainID uint64
+	backend Backend
+
+	mu        sync.Mutex
+	factories map[common.Address]common.Address
+}
+
+// NewDiscovery creates a pool discovery for a chain
+func NewDiscovery(chainID uint64, backend Backend) *Discovery {
+	return &Discovery{
+		chainID:   chainID,
+		backend:   backend,
+		factories: make(map[common.Address]common.Address),
+	}
+}
+
+// DiscoverPair queries every router's factory for tokenA/tokenB pools and
+// snapshots their reserves. Per-router failures are returned alongside results.
+func (d *Discovery) DiscoverPair(ctx context.Context, routers []Router, tokenA, tokenB common.Address) ([]*reserves.Snapshot, []error) {
+	var snapshots []*reserves.Snapshot
+	var errs []error
+	for _, r := range routers {
+		found, err := d.discoverRouter(ctx, r, tokenA, tokenB)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Name, err))
+		}
+		snapshots = append(snapshots, found...)
+	}
+	return snapshots, errs
+}
+
+func (d *Discovery) discoverRouter(ctx context.Context, r Router, tokenA, tokenB common.Address) ([]*reserves.Snapshot, error) {
+	factory := r.Factory
+	if factory == (common.Address{}) {
+		var err error
+		if factory, err = d.Factory(ctx, r.Address); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.Kind != reserves.KindV3 {
+		pair, err := d.callAddress(ctx, factory, "getPair", tokenA, tokenB)
+		if err != nil {
+			return nil, err
+		}
+		if pair == (common.Address{}) {
+			return nil, nil
+		}
+		feeBps := r.FeeBps
+		if feeBps == 0 {
+			feeBps = V2FeeBps
+		}
+		s, err := d.FetchV2(ctx, r.Name, pair, feeBps)
+		if err != nil {
+			return nil, err
+		}
+		return []*reserves.Snapshot{s}, nil
+	}
+
+	var out []*reserves.Snapshot
+	for _, fee := range V3FeeTiers {
+		pool, err := d.callAddress(ctx, factory, "getPool", tokenA, tokenB, new(big.Int).SetUint64(uint64(fee)))
+		if err != nil {
+			return out, err
+		}
+		if pool == (common.Address{}) {
+			continue
+		}
+		s, err := d.FetchV3(ctx, r.Name, pool, fee)
+		if err != nil {
+			return out, err
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// Factory returns (and memoizes) the factory behind a router
+func (d *Discovery) Factory(ctx context.Context, router common.Address) (common.Address, error) {
+	d.mu.Lock()
+	factory, ok := d.factories[router]
+	d.mu.Unlock()
+	if ok {
+		return factory, nil
+	}
+
+	factory, err := d.callAddress(ctx, router, "factory")
+	if err != nil {
+		return common.Address{}, err
+	}
+	d.mu.Lock()
+	d.factories[router] = factory
+	d.mu.Unlock()
+	return factory, nil
+}
+
+// FetchV2 snapshots a constant-product pair
+func (d *Discovery) FetchV2(ctx context.Context, dexName string, pair common.Address, feeBps uint32) (*reserves.Snapshot, error) {
+	out, err := d.call(ctx, pair, "getReserves")
+	if err != nil {
+		return nil, err
+	}
+	token0, token1, err := d.pairTokens(ctx, pair)
+	if err != nil {
+		return nil, err
+	}
+	return &reserves.Snapshot{
+		ChainID:   d.chainID,
+		Dex:       dexName,
+		Kind:      reserves.KindV2,
+		Pool:      pair,
+		Token0:    token0,
+		Token1:    token1,
+		Reserve0:  out[0].(*big.Int),
+		Reserve1:  out[1].(*big.Int),
+		FeeBps:    feeBps,
+		Block:     d.blockNumber(ctx),
+		FetchedAt: time.Now(),
+	}, nil
+}
+
+// FetchV3 snapshots a concentrated-liquidity pool as virtual reserves of the active range
+func (d *Discovery) FetchV3(ctx context.Context, dexName string, pool common.Address, fee uint32) (*reserves.Snapshot, error) {
+	sqrtPrice, err := d.callWord(ctx, pool, "slot0")
+	if err != nil {
+		return nil, err
+	}
+	out, err := d.call(ctx, pool, "liquidity")
+	if err != nil {
+		return nil, err
+	}
+	liquidity := out[0].(*big.Int)
+	token0, token1, err := d.pairTokens(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	reserve0, reserve1 := new(big.Int), new(big.Int)
+	if sqrtPrice.Sign() > 0 {
+		reserve0.Mul(liquidity, q96).Quo(reserve0, sqrtPrice)
+		reserve1.Mul(liquidity, sqrtPrice).Quo(reserve1, q96)
+	}
+	return &reserves.Snapshot{
+		ChainID:   d.chainID,
+		Dex:       dexName,
+		Kind:      reserves.KindV3,
+		Pool:      pool,
+		Token0:    token0,
+		Token1:    token1,
+		Reserve0:  reserve0,
+		Reserve1:  reserve1,
+		FeeBps:    fee / 100,
+		Liquidity: liquidity,
+		Block:     d.blockNumber(ctx),
+		FetchedAt: time.Now(),
+	}, nil
+}
+
+func (d *Discovery) pairTokens(ctx context.Context, pool common.Address) (common.Address, common.Address, error) {
+	token0, err := d.callAddress(ctx, pool, "token0")
+	if err != nil {
+		return common.Address{}, common.Address{}, err
+	}
+	token1, err := d.callAddress(ctx, pool, "token1")
+	if err != nil {
+		return common.Address{}, common.Address{}, err
+	}
+	return token0, token1, nil
+}
+
+func (d *Discovery) blockNumber(ctx context.Context) uint64 {
+	n, err := d.backend.BlockNumber(ctx)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func (d *Discovery) raw(ctx context.Context, to common.Address, method string, args ...interface{}) ([]byte, error) {
+	data, err := parsedABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := d.backend.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s on %s: %w", method, to.Hex(), err)
+	}
+	return out, nil
+}
+
+func (d *Discovery) call(ctx context.Context, to common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	out, err := d.raw(ctx, to, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	values, err := parsedABI.Unpack(method, out)
+	if err != nil {
+		return nil, fmt.Errorf("%s on %s: %w", method, to.Hex(), err)
+	}
+	return values, nil
+}
+
+func (d *Discovery) callWord(ctx context.Context, to common.Address, method string) (*big.Int, error) {
+	out, err := d.raw(ctx, to, method)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) < 32 {
+		return nil, fmt.Errorf("%s on %s: short return data", method, to.Hex())
+	}
+	return new(big.Int).SetBytes(out[:32]), nil
+}
+
+func (d *Discovery) callAddress(ctx context.Context, to common.Address, method string, args ...interface{}) (common.Address, error) {
+	out, err := d.raw(ctx, to, method, args...)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(out) < 32 {
+		return common.Address{}, fmt.Errorf("%s on %s: short return data", method, to.Hex())
+	}
+	return common.BytesToAddress(out[12:32]), nil
+}
@@ -0,0 +1,84 @@
+package dex
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+)
+
+func TestDiscoverV2Pair(t *testing.T) {
+	chain := titantest.NewChain(137)
+	weth := chain.Token(18)
+	usdc := chain.Token(6)
+	pool := chain.Pool(weth, usdc, titantest.Units(1_000, 18), titantest.Units(3_000_000, 6))
+
+	router, factory := titantest.Address(0xa1), titantest.Address(0xf1)
+	chain.Backend.Handle(router, "factory()", func(ethereum.CallMsg) ([]byte, error) {
+		return titantest.EncodeAddress(factory), nil
+	})
+	chain.Backend.Handle(factory, "getPair(address,address)", func(ethereum.CallMsg) ([]byte, error) {
+		return titantest.EncodeAddress(pool.Address), nil
+	})
+
+	d := NewDiscovery(137, chain.Backend)
+	snapshots, errs := d.DiscoverPair(context.Background(),
+		[]Router{{Name: "QUICKSWAP", Address: router, Kind: reserves.KindV2}}, usdc, weth)
+	if len(errs) != 0 {
+		t.Fatalf("Unexpected discovery errors: %v", errs)
+	}
+	if len(snapshots) != 1 {
+		t.Fatalf("Expected 1 pool, got %d", len(snapshots))
+	}
+	s := snapshots[0]
+	if s.Pool != pool.Address || s.Token0 != weth || s.Reserve1.Cmp(titantest.Units(3_000_000, 6)) != 0 {
+		t.Errorf("Unexpected snapshot %+v", s)
+	}
+
+	// 1 WETH into a 1000 WETH pool: ~30 bps fee + ~10 bps slippage
+	impact, err := s.PriceImpactBps(weth, titantest.Units(1, 18))
+	if err != nil {
+		t.Fatalf("PriceImpactBps failed: %v", err)
+	}
+	if impact < 39 || impact > 41 {
+		t.Errorf("Expected ~40 bps impact, got %d", impact)
+	}
+
+	// Factory lookups are memoized per router
+	calls := chain.Backend.Calls
+	d.Factory(context.Background(), router)
+	if chain.Backend.Calls != calls {
+		t.Error("Expected factory lookup to be served from memo")
+	}
+}
+
+func TestFetchV3VirtualReserves(t *testing.T) {
+	chain := titantest.NewChain(1)
+	pool := titantest.Address(0xb3)
+	token0, token1 := chain.Token(18), chain.Token(18)
+
+	// sqrtPriceX96 = 2^96 means price 1.0, so both virtual reserves equal L
+	chain.Backend.Handle(pool, "slot0()", func(ethereum.CallMsg) ([]byte, error) {
+		return titantest.EncodeUint(new(big.Int).Lsh(big.NewInt(1), 96)), nil
+	})
+	chain.Backend.Handle(pool, "liquidity()", func(ethereum.CallMsg) ([]byte, error) {
+		return titantest.EncodeUint(big.NewInt(5_000_000)), nil
+	})
+	chain.Backend.Handle(pool, "token0()", func(ethereum.CallMsg) ([]byte, error) {
+		return titantest.EncodeAddress(token0), nil
+	})
+	chain.Backend.Handle(pool, "token1()", func(ethereum.CallMsg) ([]byte, error) {
+		return titantest.EncodeAddress(token1), nil
+	})
+
+	s, err := NewDiscovery(1, chain.Backend).FetchV3(context.Background(), "UNIV3", pool, 500)
+	if err != nil {
+		t.Fatalf("FetchV3 failed: %v", err)
+	}
+	if s.FeeBps != 5 || s.Reserve0.Int64() != 5_000_000 || s.Reserve1.Int64() != 5_000_000 {
+		t.Errorf("Unexpected V3 snapshot: fee=%d r0=%s r1=%s", s.FeeBps, s.Reserve0, s.Reserve1)
+	}
+}
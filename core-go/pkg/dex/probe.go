@@ -0,0 +1,181 @@
+package dex
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+)
+
+// ErrNotContract is returned when a router address has no deployed code
+var ErrNotContract = errors.New("dex: no contract code at address")
+
+// CodeBackend is the chain access router detection needs
+type CodeBackend interface {
+	Backend
+	CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// Detection confidence levels
+const (
+	ConfidenceHigh   = "high"
+	ConfidenceMedium = "medium"
+	ConfidenceLow    = "low"
+)
+
+// Classification is the detected type of a router and the evidence for it
+type Classification struct {
+	Address    common.Address
+	Type       string // a config.RouterType* value, or empty when unknown
+	Factory    common.Address
+	Confidence string
+	Evidence   []string
+}
+
+// probe is a read-only call whose success is evidence for a router type
+type probe struct {
+	signature string
+	args      []byte
+	routerTyp string
+	weight    int
+}
+
+// Interface probes called on the router itself
+var routerProbes = []probe{
+	{signature: "WETH()", routerTyp: config.RouterTypeV2, weight: 3},
+	{signature: "WETH9()", routerTyp: config.RouterTypeV3, weight: 3},
+	{signature: "weth()", routerTyp: config.RouterTypeSolidly, weight: 3},
+	{signature: "defaultFactory()", routerTyp: config.RouterTypeSolidly, weight: 3},
+}
+
+// Interface probes called on the router's factory with zero-address tokens
+var factoryProbes = []probe{
+	{signature: "getPair(address,address)", args: make([]byte, 64), routerTyp: config.RouterTypeV2, weight: 2},
+	{signature: "getPool(address,address,uint24)", args: make([]byte, 96), routerTyp: config.RouterTypeV3, weight: 2},
+	{signature: "getPair(address,address,bool)", args: make([]byte, 96), routerTyp: config.RouterTypeSolidly, weight: 2},
+}
+
+// Function selectors searched for in router bytecode
+var bytecodeSelectors = []probe{
+	{signature: "swapExactTokensForTokens(uint256,uint256,address[],address,uint256)", routerTyp: config.RouterTypeV2, weight: 2},
+	{signature: "exactInputSingle((address,address,uint24,address,uint256,uint256,uint256,uint160))", routerTyp: config.RouterTypeV3, weight: 2},
+	{signature: "exactInputSingle((address,address,uint24,address,uint256,uint256,uint160))", routerTyp: config.RouterTypeV3, weight: 2},
+	{signature: "swapExactTokensForTokens(uint256,uint256,(address,address,bool)[],address,uint256)", routerTyp: config.RouterTypeSolidly, weight: 2},
+	{signature: "swapExactTokensForTokens(uint256,uint256,(address,address,bool,address)[],address,uint256)", routerTyp: config.RouterTypeSolidly, weight: 2},
+	{signature: "swap(address,(address,address,address,address,uint256,uint256,uint256),bytes,bytes)", routerTyp: config.RouterTypeAggregator, weight: 3},
+	{signature: "unoswap(address,uint256,uint256,uint256[])", routerTyp: config.RouterTypeAggregator, weight: 3},
+	{signature: "transformERC20(address,address,uint256,uint256,(uint32,bytes)[])", routerTyp: config.RouterTypeAggregator, weight: 3},
+}
+
+// eip1167Prefix is the runtime code prefix of a minimal proxy
+var eip1167Prefix = common.FromHex("0x363d3d373d3d3d363d73")
+
+// Classify identifies a router's type from the interfaces it answers and the
+// selectors present in its bytecode
+func Classify(ctx context.Context, backend CodeBackend, router common.Address) (*Classification, error) {
+	code, err := backend.CodeAt(ctx, router, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotContract, router.Hex())
+	}
+
+	c := &Classification{Address: router}
+	scores := make(map[string]int)
+	score := func(p probe, evidence string) {
+		scores[p.routerTyp] += p.weight
+		c.Evidence = append(c.Evidence, evidence)
+	}
+
+	if out, err := probeCall(ctx, backend, router, "factory()", nil); err == nil && len(out) >= 32 {
+		c.Factory = common.BytesToAddress(out[12:32])
+	}
+	if c.Factory == (common.Address{}) {
+		if out, err := probeCall(ctx, backend, router, "defaultFactory()", nil); err == nil && len(out) >= 32 {
+			c.Factory = common.BytesToAddress(out[12:32])
+		}
+	}
+
+	for _, p := range routerProbes {
+		if _, err := probeCall(ctx, backend, router, p.signature, p.args); err == nil {
+			score(p, "router answers "+p.signature)
+		}
+	}
+	if c.Factory != (common.Address{}) {
+		for _, p := range factoryProbes {
+			if _, err := probeCall(ctx, backend, c.Factory, p.signature, p.args); err == nil {
+				score(p, "factory answers "+p.signature)
+			}
+		}
+	}
+
+	if bytes.HasPrefix(code, eip1167Prefix) {
+		c.Evidence = append(c.Evidence, "EIP-1167 proxy: bytecode heuristics unavailable")
+	} else {
+		for _, p := range bytecodeSelectors {
+			sel := crypto.Keccak256([]byte(p.signature))[:4]
+			if bytes.Contains(code, append([]byte{0x63}, sel...)) {
+				score(p, "bytecode dispatches "+p.signature)
+			}
+		}
+	}
+
+	best := 0
+	types := make([]string, 0, len(scores))
+	for typ := range scores {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	for _, typ := range types {
+		if scores[typ] > best {
+			best, c.Type = scores[typ], typ
+		}
+	}
+	switch {
+	case best >= 6:
+		c.Confidence = ConfidenceHigh
+	case best >= 3:
+		c.Confidence = ConfidenceMedium
+	default:
+		c.Confidence = ConfidenceLow
+	}
+	return c, nil
+}
+
+// DetectRouterTypes classifies every router with no configured type and fills
+// in its type and, when missing, its factory. Per-router failures are returned.
+func DetectRouterTypes(ctx context.Context, backend CodeBackend, routers config.DexRouters) []error {
+	var errs []error
+	for name, rc := range routers {
+		if rc.Type != "" {
+			continue
+		}
+		c, err := Classify(ctx, backend, common.HexToAddress(rc.Address))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		if c.Type == "" {
+			errs = append(errs, fmt.Errorf("%s: could not classify router %s", name, rc.Address))
+			continue
+		}
+		rc.Type = c.Type
+		if rc.Factory == "" && c.Factory != (common.Address{}) {
+			rc.Factory = c.Factory.Hex()
+		}
+	}
+	return errs
+}
+
+func probeCall(ctx context.Context, backend Backend, to common.Address, signature string, args []byte) ([]byte, error) {
+	data := append(crypto.Keccak256([]byte(signature))[:4], args...)
+	return backend.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+}
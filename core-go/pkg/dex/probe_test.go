@@ -0,0 +1,92 @@
+package dex
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+)
+
+func answer(b *titantest.Backend, contract common.Address, signatures ...string) {
+	for _, sig := range signatures {
+		b.Handle(contract, sig, func(ethereum.CallMsg) ([]byte, error) {
+			return make([]byte, 32), nil
+		})
+	}
+}
+
+// dispatcher returns fake bytecode that PUSH4es each signature's selector
+func dispatcher(signatures ...string) []byte {
+	code := []byte{0x60, 0x80}
+	for _, sig := range signatures {
+		code = append(code, 0x63)
+		code = append(code, crypto.Keccak256([]byte(sig))[:4]...)
+	}
+	return code
+}
+
+func TestClassifyV2Router(t *testing.T) {
+	backend := titantest.NewBackend(137)
+	router, factory := titantest.Address(0xa1), titantest.Address(0xf1)
+	backend.Handle(router, "factory()", func(ethereum.CallMsg) ([]byte, error) {
+		return titantest.EncodeAddress(factory), nil
+	})
+	answer(backend, router, "WETH()")
+	answer(backend, factory, "getPair(address,address)")
+	backend.SetCode(router, dispatcher("swapExactTokensForTokens(uint256,uint256,address[],address,uint256)"))
+
+	c, err := Classify(context.Background(), backend, router)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Type != config.RouterTypeV2 || c.Confidence != ConfidenceHigh {
+		t.Errorf("Expected high-confidence v2, got %s/%s (%v)", c.Type, c.Confidence, c.Evidence)
+	}
+	if c.Factory != factory {
+		t.Errorf("Expected factory %s, got %s", factory.Hex(), c.Factory.Hex())
+	}
+}
+
+func TestClassifyAggregatorFromBytecode(t *testing.T) {
+	backend := titantest.NewBackend(1)
+	router := titantest.Address(0xa2)
+	backend.SetCode(router, dispatcher(
+		"swap(address,(address,address,address,address,uint256,uint256,uint256),bytes,bytes)",
+		"unoswap(address,uint256,uint256,uint256[])",
+	))
+
+	c, err := Classify(context.Background(), backend, router)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Type != config.RouterTypeAggregator {
+		t.Errorf("Expected aggregator, got %q (%v)", c.Type, c.Evidence)
+	}
+}
+
+func TestDetectRouterTypes(t *testing.T) {
+	backend := titantest.NewBackend(10)
+	solidly, missing := titantest.Address(0xa3), titantest.Address(0xa4)
+	answer(backend, solidly, "weth()", "defaultFactory()")
+
+	routers := config.DexRouters{
+		"VELO":    {Address: solidly.Hex()},
+		"MISSING": {Address: missing.Hex()},
+		"KNOWN":   {Address: missing.Hex(), Type: config.RouterTypeV2},
+	}
+	errs := DetectRouterTypes(context.Background(), backend, routers)
+	if len(errs) != 1 || !errors.Is(errs[0], ErrNotContract) {
+		t.Errorf("Expected a single ErrNotContract, got %v", errs)
+	}
+	if routers["VELO"].Type != config.RouterTypeSolidly {
+		t.Errorf("Expected VELO detected as solidly, got %q", routers["VELO"].Type)
+	}
+	if routers["KNOWN"].Type != config.RouterTypeV2 {
+		t.Errorf("Expected configured type to be kept, got %q", routers["KNOWN"].Type)
+	}
+}
@@ -0,0 +1,352 @@
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/dex"
+)
+
+// Target kinds
+const (
+	KindRouter      = "router"
+	KindVault       = "vault"
+	KindLendingPool = "lending_pool"
+	KindSwapRouter  = "swap_router"
+	KindCurveRouter = "curve_router"
+)
+
+// Finding kinds
+const (
+	FindingMissing      = "missing_code"
+	FindingCodeChanged  = "code_changed"
+	FindingUpgraded     = "implementation_changed"
+	FindingTypeMismatch = "type_mismatch"
+	FindingSuperseded   = "superseded"
+)
+
+// eip1967ImplementationSlot is bytes32(uint256(keccak256("eip1967.proxy.implementation")) - 1)
+var eip1967ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+
+// Target is a configured contract address to verify
+type Target struct {
+	ChainID    uint64         `json:"chainId"`
+	Kind       string         `json:"kind"`
+	Name       string         `json:"name"`
+	Address    common.Address `json:"address"`
+	RouterType string         `json:"routerType,omitempty"` // configured type, routers only
+}
+
+// key identifies the target's contract in the baseline
+func (t Target) key() string {
+	return fmt.Sprintf("%d:%s", t.ChainID, t.Address.Hex())
+}
+
+// TargetsFromConfig lists every non-zero contract address in cfg
+func TargetsFromConfig(cfg *config.Config) []Target {
+	var out []Target
+	add := func(chainID uint64, kind, name, address string) {
+		if !common.IsHexAddress(address) || common.HexToAddress(address) == (common.Address{}) {
+			return
+		}
+		out = append(out, Target{ChainID: chainID, Kind: kind, Name: name, Address: common.HexToAddress(address)})
+	}
+	for chainID, chain := range cfg.Chains {
+		add(chainID, KindVault, "BALANCER_V3_VAULT", config.BalancerV3Vault)
+		add(chainID, KindLendingPool, "AAVE_POOL", chain.AavePool)
+		add(chainID, KindSwapRouter, "UNISWAP_ROUTER", chain.UniswapRouter)
+		add(chainID, KindCurveRouter, "CURVE_ROUTER", chain.CurveRouter)
+	}
+	for chainID, routers := range cfg.DexRouters {
+		for name, rc := range routers {
+			before := len(out)
+			add(chainID, KindRouter, name, rc.Address)
+			if len(out) > before {
+				out[len(out)-1].RouterType = rc.Type
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ChainID != out[j].ChainID {
+			return out[i].ChainID < out[j].ChainID
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// successor is a known replacement for a deprecated deployment
+type successor struct {
+	address common.Address
+	label   string
+}
+
+// knownSuccessors maps deprecated deployments to their replacements on the same chain
+var knownSuccessors = map[common.Address]successor{
+	// Uniswap V3 SwapRouter -> SwapRouter02 (Ethereum, Polygon, Arbitrum, Optimism)
+	common.HexToAddress("0xE592427A0AEce92De3Edee1F18E0157C05861564"): {common.HexToAddress("0x68b3465833fb72A70ecDF485E0e4C7bD8665Fc45"), "Uniswap SwapRouter02"},
+	// Balancer V2 Vault -> V3 Vault
+	common.HexToAddress("0xBA12222222228d8Ba445958a75a0704d566BF2C8"): {common.HexToAddress(config.BalancerV3Vault), "Balancer V3 Vault"},
+	// Aave V2 LendingPool (Polygon) -> Aave V3 Pool
+	common.HexToAddress("0x8dFf5E27EA6b7AC08EbFdf9eB090F32ee9a30fcf"): {common.HexToAddress("0x794a61358D6845594F94dc1DB02A252b5b4814aD"), "Aave V3 Pool"},
+}
+
+// Finding is a discrepancy between configuration and the chain
+type Finding struct {
+	Target     Target `json:"target"`
+	Kind       string `json:"kind"`
+	Detail     string `json:"detail"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// String renders the finding on one line
+func (f Finding) String() string {
+	s := fmt.Sprintf("chain %d %s %s (%s): %s — %s", f.Target.ChainID, f.Target.Kind, f.Target.Name, f.Target.Address.Hex(), f.Kind, f.Detail)
+	if f.Suggestion != "" {
+		s += "; suggestion: " + f.Suggestion
+	}
+	return s
+}
+
+// Fingerprint is the observed on-chain identity of a contract
+type Fingerprint struct {
+	CodeHash       common.Hash    `json:"codeHash"`
+	Implementation common.Address `json:"implementation,omitempty"` // EIP-1967 proxies only
+	FirstSeen      time.Time      `json:"firstSeen"`
+}
+
+// Baseline is the accepted fingerprint of every checked contract, persisted as JSON
+type Baseline struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]Fingerprint
+}
+
+// LoadBaseline reads a baseline file; a missing file yields an empty baseline
+func LoadBaseline(path string) (*Baseline, error) {
+	b := &Baseline{path: path, entries: make(map[string]Fingerprint)}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &b.entries); err != nil {
+		return nil, fmt.Errorf("invalid baseline %s: %w", path, err)
+	}
+	return b, nil
+}
+
+// Get returns the accepted fingerprint of a target
+func (b *Baseline) Get(t Target) (Fingerprint, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fp, ok := b.entries[t.key()]
+	return fp, ok
+}
+
+// Accept records fp as the expected fingerprint of a target
+func (b *Baseline) Accept(t Target, fp Fingerprint) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[t.key()] = fp
+}
+
+// Save writes the baseline atomically
+func (b *Baseline) Save() error {
+	b.mu.Lock()
+	raw, err := json.MarshalIndent(b.entries, "", "  ")
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0o755); err != nil {
+		return err
+	}
+	tmp := b.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, b.path)
+}
+
+// Checker compares configured contracts against chain state and a baseline.
+// Contracts seen for the first time are accepted into the baseline; later
+// changes are reported until they are accepted explicitly.
+type Checker struct {
+	baseline *Baseline
+	backends func(chainID uint64) (chain.ChainReader, error)
+}
+
+// NewChecker creates a checker; backends resolves a chain's reader
+func NewChecker(baseline *Baseline, backends func(chainID uint64) (chain.ChainReader, error)) *Checker {
+	return &Checker{baseline: baseline, backends: backends}
+}
+
+// Observe reads a target's current fingerprint
+func (c *Checker) Observe(ctx context.Context, t Target) (Fingerprint, error) {
+	backend, err := c.backends(t.ChainID)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+	code, err := backend.CodeAt(ctx, t.Address, nil)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+	if len(code) == 0 {
+		return Fingerprint{}, nil
+	}
+	fp := Fingerprint{CodeHash: crypto.Keccak256Hash(code), FirstSeen: time.Now().UTC()}
+	slot, err := backend.StorageAt(ctx, t.Address, eip1967ImplementationSlot, nil)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+	fp.Implementation = common.BytesToAddress(slot)
+	return fp, nil
+}
+
+// Check verifies every target and returns the findings. Per-target read
+// errors are returned alongside the findings of the targets that succeeded.
+func (c *Checker) Check(ctx context.Context, targets []Target) ([]Finding, error) {
+	var findings []Finding
+	var errs []error
+	accepted := false
+	for _, t := range targets {
+		fp, err := c.Observe(ctx, t)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("chain %d %s: %w", t.ChainID, t.Name, err))
+			continue
+		}
+		replacement, superseded := knownSuccessors[t.Address]
+
+		if fp.CodeHash == (common.Hash{}) {
+			f := Finding{Target: t, Kind: FindingMissing, Detail: "no contract code at address"}
+			if superseded {
+				f.Suggestion = fmt.Sprintf("replace with %s %s", replacement.label, replacement.address.Hex())
+			}
+			findings = append(findings, f)
+			continue
+		}
+		if superseded {
+			findings = append(findings, Finding{
+				Target:     t,
+				Kind:       FindingSuperseded,
+				Detail:     "deployment has a newer version",
+				Suggestion: fmt.Sprintf("migrate to %s %s", replacement.label, replacement.address.Hex()),
+			})
+		}
+
+		prev, known := c.baseline.Get(t)
+		switch {
+		case !known:
+			c.baseline.Accept(t, fp)
+			accepted = true
+		case prev.Implementation != fp.Implementation:
+			findings = append(findings, Finding{
+				Target:     t,
+				Kind:       FindingUpgraded,
+				Detail:     fmt.Sprintf("proxy implementation moved from %s to %s", prev.Implementation.Hex(), fp.Implementation.Hex()),
+				Suggestion: "review the new implementation, re-run `titan router detect`, then `titan drift --accept`",
+			})
+		case prev.CodeHash != fp.CodeHash:
+			findings = append(findings, Finding{
+				Target:     t,
+				Kind:       FindingCodeChanged,
+				Detail:     fmt.Sprintf("code hash changed from %s to %s", prev.CodeHash.Hex(), fp.CodeHash.Hex()),
+				Suggestion: "verify the deployment, then `titan drift --accept`",
+			})
+		}
+
+		if t.Kind == KindRouter && t.RouterType != "" {
+			if f, ok := c.checkRouterType(ctx, t); ok {
+				findings = append(findings, f)
+			}
+		}
+	}
+	if accepted {
+		if err := c.baseline.Save(); err != nil {
+			errs = append(errs, fmt.Errorf("saving baseline: %w", err))
+		}
+	}
+	return findings, errors.Join(errs...)
+}
+
+// checkRouterType reports a router whose interface no longer matches its configured type
+func (c *Checker) checkRouterType(ctx context.Context, t Target) (Finding, bool) {
+	backend, err := c.backends(t.ChainID)
+	if err != nil {
+		return Finding{}, false
+	}
+	cls, err := dex.Classify(ctx, backend, t.Address)
+	if err != nil || cls.Type == "" || cls.Type == t.RouterType || cls.Confidence != dex.ConfidenceHigh {
+		return Finding{}, false
+	}
+	return Finding{
+		Target:     t,
+		Kind:       FindingTypeMismatch,
+		Detail:     fmt.Sprintf("configured as %s but behaves like %s (%s)", t.RouterType, cls.Type, strings.Join(cls.Evidence, ", ")),
+		Suggestion: fmt.Sprintf("set type to %q in DEX_ROUTERS_FILE", cls.Type),
+	}, true
+}
+
+// AcceptAll records every target's current fingerprint as expected
+func (c *Checker) AcceptAll(ctx context.Context, targets []Target) error {
+	var errs []error
+	for _, t := range targets {
+		fp, err := c.Observe(ctx, t)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("chain %d %s: %w", t.ChainID, t.Name, err))
+			continue
+		}
+		if fp.CodeHash != (common.Hash{}) {
+			c.baseline.Accept(t, fp)
+		}
+	}
+	if err := c.baseline.Save(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Run checks targets every interval until ctx is cancelled, reporting each
+// finding to notify once until it clears
+func (c *Checker) Run(ctx context.Context, interval time.Duration, targets []Target, notify func(Finding)) {
+	reported := make(map[string]bool)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		findings, err := c.Check(ctx, targets)
+		if err != nil {
+			log.Printf("⚠️ Config drift check: %v", err)
+		}
+		current := make(map[string]bool, len(findings))
+		for _, f := range findings {
+			id := f.Target.key() + "/" + f.Kind + "/" + f.Detail
+			current[id] = true
+			if !reported[id] && notify != nil {
+				notify(f)
+			}
+		}
+		reported = current
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package drift
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+)
+
+func TestCheckDetectsDrift(t *testing.T) {
+	backend := titantest.NewBackend(137)
+	router := titantest.Address(0xa1)
+	proxy := titantest.Address(0xa2)
+	gone := titantest.Address(0xa3)
+	backend.SetCode(router, []byte{0x60, 0x01})
+	backend.SetCode(proxy, []byte{0x60, 0x02})
+	backend.SetStorage(proxy, eip1967ImplementationSlot, common.BytesToHash(titantest.Address(0xb1).Bytes()))
+	backend.SetCode(gone, []byte{0x60, 0x03})
+
+	targets := []Target{
+		{ChainID: 137, Kind: KindSwapRouter, Name: "ROUTER", Address: router},
+		{ChainID: 137, Kind: KindLendingPool, Name: "PROXY", Address: proxy},
+		{ChainID: 137, Kind: KindVault, Name: "GONE", Address: gone},
+	}
+	path := filepath.Join(t.TempDir(), "codehashes.json")
+	baseline, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := NewChecker(baseline, func(uint64) (chain.ChainReader, error) { return backend, nil })
+
+	// First sight establishes the baseline
+	findings, err := checker.Check(context.Background(), targets)
+	if err != nil || len(findings) != 0 {
+		t.Fatalf("Expected a clean first run, got %v (%v)", findings, err)
+	}
+
+	backend.SetCode(router, []byte{0x60, 0x04})
+	backend.SetStorage(proxy, eip1967ImplementationSlot, common.BytesToHash(titantest.Address(0xb2).Bytes()))
+	backend.SetCode(gone, nil)
+
+	reloaded, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker = NewChecker(reloaded, func(uint64) (chain.ChainReader, error) { return backend, nil })
+	findings, err = checker.Check(context.Background(), targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"ROUTER": FindingCodeChanged, "PROXY": FindingUpgraded, "GONE": FindingMissing}
+	if len(findings) != len(want) {
+		t.Fatalf("Expected %d findings, got %v", len(want), findings)
+	}
+	for _, f := range findings {
+		if want[f.Target.Name] != f.Kind {
+			t.Errorf("Expected %s for %s, got %s", want[f.Target.Name], f.Target.Name, f.Kind)
+		}
+	}
+
+	if err := checker.AcceptAll(context.Background(), targets); err != nil {
+		t.Fatal(err)
+	}
+	findings, _ = checker.Check(context.Background(), targets[:2])
+	if len(findings) != 0 {
+		t.Errorf("Expected accepted changes to clear, got %v", findings)
+	}
+}
+
+func TestSupersededDeploymentSuggestsReplacement(t *testing.T) {
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := titantest.NewBackend(1)
+	var swapRouter Target
+	for _, target := range TargetsFromConfig(cfg) {
+		if target.ChainID == 1 && target.Kind == KindSwapRouter {
+			swapRouter = target
+		}
+	}
+	backend.SetCode(swapRouter.Address, []byte{0x60, 0x01})
+
+	checker := NewChecker(&Baseline{path: filepath.Join(t.TempDir(), "b.json"), entries: map[string]Fingerprint{}},
+		func(uint64) (chain.ChainReader, error) { return backend, nil })
+	findings, err := checker.Check(context.Background(), []Target{swapRouter})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Kind != FindingSuperseded {
+		t.Fatalf("Expected the V3 SwapRouter to be flagged as superseded, got %v", findings)
+	}
+	if findings[0].Suggestion != "migrate to Uniswap SwapRouter02 0x68b3465833fb72A70ecDF485E0e4C7bD8665Fc45" {
+		t.Errorf("Unexpected suggestion %q", findings[0].Suggestion)
+	}
+}
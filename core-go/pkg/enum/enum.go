@@ -3,8 +3,10 @@ package enum
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
 )
 
 // ChainID represents supported blockchain networks
@@ -73,6 +75,17 @@ func FromU64(value uint64) (ChainID, error) {
 	}
 }
 
+// FromName converts a chain name (as returned by Name) to ChainID
+func FromName(name string) (ChainID, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, chain := range AllChains() {
+		if chain.Name() == name {
+			return chain, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported chain name: %q", name)
+}
+
 // AllChains returns all supported chain IDs
 func AllChains() []ChainID {
 	return []ChainID{
@@ -82,25 +95,36 @@ func AllChains() []ChainID {
 	}
 }
 
-// ProviderManager manages Web3 provider connections
+// ProviderManager manages Web3 provider connections. It is safe for concurrent use.
 type ProviderManager struct {
-	providers map[uint64]*ethclient.Client
+	mu        sync.Mutex
+	providers map[uint64]chain.Client
+	dial      chain.Dialer
 }
 
 // NewProviderManager creates a new provider manager
 func NewProviderManager() *ProviderManager {
+	return NewProviderManagerWithDialer(chain.Dial)
+}
+
+// NewProviderManagerWithDialer creates a provider manager that opens
+// connections with dial, e.g. to hand out in-memory backends in tests
+func NewProviderManagerWithDialer(dial chain.Dialer) *ProviderManager {
 	return &ProviderManager{
-		providers: make(map[uint64]*ethclient.Client),
+		providers: make(map[uint64]chain.Client),
+		dial:      dial,
 	}
 }
 
 // GetProvider returns a provider for the specified chain
-func (pm *ProviderManager) GetProvider(chainID uint64, rpcURL string) (*ethclient.Client, error) {
+func (pm *ProviderManager) GetProvider(chainID uint64, rpcURL string) (chain.Client, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
 	if provider, ok := pm.providers[chainID]; ok {
 		return provider, nil
 	}
 	
-	client, err := ethclient.Dial(rpcURL)
+	client, err := pm.dial(rpcURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to chain %d: %w", chainID, err)
 	}
@@ -127,14 +151,22 @@ func (pm *ProviderManager) TestConnection(ctx context.Context, chainID uint64, r
 }
 
 // GetAllProviders returns all active providers
-func (pm *ProviderManager) GetAllProviders() map[uint64]*ethclient.Client {
-	return pm.providers
+func (pm *ProviderManager) GetAllProviders() map[uint64]chain.Client {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	out := make(map[uint64]chain.Client, len(pm.providers))
+	for id, provider := range pm.providers {
+		out[id] = provider
+	}
+	return out
 }
 
 // CloseAll closes all provider connections
 func (pm *ProviderManager) CloseAll() {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
 	for _, provider := range pm.providers {
 		provider.Close()
 	}
-	pm.providers = make(map[uint64]*ethclient.Client)
+	pm.providers = make(map[uint64]chain.Client)
 }
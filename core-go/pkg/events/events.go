@@ -0,0 +1,166 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/alert"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+)
+
+// TypeAlert is the event type for alert messages; other types are the
+// journal kinds passed in by the opportunity recorder
+const TypeAlert = "alert"
+
+// queueSize bounds events waiting for delivery; later events are dropped
+const queueSize = 4096
+
+// Event is one message on the bus. Consumers deduplicate redelivered
+// events on ID.
+type Event struct {
+	ID   string      `json:"id"`
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// Sink is a message broker the bus publishes to. Publish returns once the
+// broker has accepted the event, so a nil error means it can be replayed.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, e Event, body []byte) error
+	Close() error
+}
+
+// Bus fans the internal event stream out to brokers in the background,
+// so a slow or unavailable broker never blocks the caller
+type Bus struct {
+	sinks []Sink
+	queue chan Event
+
+	// Retries is how many times a failed publish is retried per sink
+	Retries int
+	// Backoff is the first retry delay, doubled on each retry
+	Backoff time.Duration
+
+	now func() time.Time
+}
+
+// NewBus creates a bus publishing to sinks
+func NewBus(sinks ...Sink) *Bus {
+	return &Bus{
+		sinks:   sinks,
+		queue:   make(chan Event, queueSize),
+		Retries: 5,
+		Backoff: 500 * time.Millisecond,
+		now:     time.Now,
+	}
+}
+
+// FromConfig creates a bus with every configured sink, or nil when none is
+// configured
+func FromConfig(cfg *config.EventBusConfig) (*Bus, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	var sinks []Sink
+	if cfg.NATSURL != "" {
+		sink, err := NewNATSSink(cfg.NATSURL, cfg.NATSSubject, cfg.NATSJetStream)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if cfg.RedisURL != "" {
+		sink, err := NewRedisSink(cfg.RedisURL, cfg.RedisStream, cfg.RedisMaxLen)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if cfg.KafkaRESTURL != "" {
+		sinks = append(sinks, NewKafkaSink(cfg.KafkaRESTURL, cfg.KafkaTopic))
+	}
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+	return NewBus(sinks...), nil
+}
+
+// Publish queues an event of eventType; it has the signature of a
+// Recorder listener so journal kinds pass straight through
+func (b *Bus) Publish(eventType string, data interface{}) {
+	e := Event{ID: newID(), Type: eventType, Time: b.now().UTC(), Data: data}
+	select {
+	case b.queue <- e:
+	default:
+		log.Printf("⚠️ Event bus queue full, dropping %s event %s", e.Type, e.ID)
+	}
+}
+
+// Name returns the alert channel name
+func (b *Bus) Name() string { return "events" }
+
+// Notify publishes an alert event, so the bus can join an alert.Dispatcher
+func (b *Bus) Notify(ctx context.Context, msg alert.Message) error {
+	b.Publish(TypeAlert, map[string]string{"level": msg.Level, "title": msg.Title, "body": msg.Body})
+	return nil
+}
+
+// Run publishes queued events until ctx is cancelled, then closes the sinks
+func (b *Bus) Run(ctx context.Context) {
+	defer func() {
+		for _, s := range b.sinks {
+			s.Close()
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-b.queue:
+			body, err := json.Marshal(e)
+			if err != nil {
+				log.Printf("❌ Event bus %s event %s: %v", e.Type, e.ID, err)
+				continue
+			}
+			for _, s := range b.sinks {
+				if err := b.send(ctx, s, e, body); err != nil && ctx.Err() == nil {
+					log.Printf("❌ Event bus %s event %s to %s: %v", e.Type, e.ID, s.Name(), err)
+				}
+			}
+		}
+	}
+}
+
+// send publishes to one sink, retrying with exponential backoff
+func (b *Bus) send(ctx context.Context, s Sink, e Event, body []byte) error {
+	backoff := b.Backoff
+	var err error
+	for attempt := 0; attempt <= b.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		if err = s.Publish(ctx, e, body); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("after %d attempts: %w", b.Retries+1, err)
+}
+
+// newID returns a random event ID consumers can deduplicate retries on
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "evt_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,232 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/alert"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+)
+
+func TestBusPublishesToRedisAndKafka(t *testing.T) {
+	redisSrv, err := titantest.NewRedis()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redisSrv.Close()
+
+	var mu sync.Mutex
+	var records []json.RawMessage
+	kafka := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/topics/titan.events" || r.Header.Get("Content-Type") != kafkaContentType {
+			t.Errorf("Unexpected request %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		var body struct {
+			Records []struct {
+				Key   string          `json:"key"`
+				Value json.RawMessage `json:"value"`
+			} `json:"records"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		defer mu.Unlock()
+		for _, rec := range body.Records {
+			records = append(records, rec.Value)
+		}
+		io.WriteString(w, `{"offsets":[{"partition":0,"offset":1,"error_code":null,"error":null}]}`)
+	}))
+	defer kafka.Close()
+
+	bus, err := FromConfig(&config.EventBusConfig{
+		RedisURL: redisSrv.URL(), RedisStream: "titan:events", RedisMaxLen: 2,
+		KafkaRESTURL: kafka.URL + "/", KafkaTopic: "titan.events",
+	})
+	if err != nil || bus == nil {
+		t.Fatalf("Expected a bus, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bus.Run(ctx)
+
+	recorder := opportunity.NewRecorder(opportunity.NewStore(10), nil)
+	recorder.Listen(bus.Publish)
+	recorder.Record(&opportunity.Opportunity{ID: "opp-1"})
+	recorder.RecordExecution(&opportunity.Execution{OpportunityID: "opp-1"})
+	alert.NewDispatcher(bus).Notify(ctx, alert.Message{Level: alert.LevelCritical, Title: "Halted"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(records)
+		mu.Unlock()
+		if n == 3 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	stream := redisSrv.Stream("titan:events")
+	if len(stream) != 2 || stream[0]["type"] != journal.KindExecution || stream[1]["type"] != TypeAlert {
+		t.Fatalf("Expected the stream trimmed to the last two events, got %v", stream)
+	}
+	var e Event
+	if err := json.Unmarshal([]byte(stream[1]["event"]), &e); err != nil || e.ID != stream[1]["id"] {
+		t.Errorf("Expected the event JSON with a matching ID, got %+v, %v", e, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(records) != 3 {
+		t.Fatalf("Expected three Kafka records, got %d", len(records))
+	}
+	if err := json.Unmarshal(records[0], &e); err != nil || e.Type != journal.KindOpportunity {
+		t.Errorf("Expected the opportunity first, got %+v, %v", e, err)
+	}
+}
+
+func TestKafkaSinkRecordError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"offsets":[{"partition":null,"offset":null,"error_code":40403,"error":"Topic not found"}]}`)
+	}))
+	defer srv.Close()
+	err := NewKafkaSink(srv.URL, "missing").Publish(context.Background(), Event{Type: "alert"}, []byte(`{}`))
+	if err == nil || !strings.Contains(err.Error(), "40403") {
+		t.Errorf("Expected the record error, got %v", err)
+	}
+}
+
+// fakeNATS is a NATS server that acks JetStream publishes, refusing the
+// first one and answering duplicates by Nats-Msg-Id
+type fakeNATS struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	subjects []string
+	ids      map[string]bool
+	rejected bool
+}
+
+func newFakeNATS(t *testing.T) *fakeNATS {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeNATS{ln: ln, ids: make(map[string]bool)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeNATS) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	io.WriteString(conn, `INFO {"server_id":"fake","headers":true}`+"\r\n")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "PING":
+			io.WriteString(conn, "PONG\r\n")
+		case "PUB", "HPUB":
+			total, _ := strconv.Atoi(fields[len(fields)-1])
+			msg := make([]byte, total+2)
+			io.ReadFull(r, msg)
+			if fields[0] == "PUB" {
+				f.record(fields[1], "")
+				continue
+			}
+			id := strings.TrimSpace(strings.SplitN(strings.SplitN(string(msg), "Nats-Msg-Id: ", 2)[1], "\r\n", 2)[0])
+			ack := f.record(fields[1], id)
+			// A server PING while the client waits for the ack must be answered
+			fmt.Fprintf(conn, "PING\r\nMSG %s 1 %d\r\n%s\r\n", fields[2], len(ack), ack)
+		}
+	}
+}
+
+func (f *fakeNATS) record(subject, id string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if id != "" && !f.rejected {
+		f.rejected = true
+		return `{"error":{"code":503,"description":"stream unavailable"}}`
+	}
+	if id != "" && f.ids[id] {
+		return `{"stream":"TITAN","seq":1,"duplicate":true}`
+	}
+	f.ids[id] = true
+	f.subjects = append(f.subjects, subject)
+	return fmt.Sprintf(`{"stream":"TITAN","seq":%d}`, len(f.subjects))
+}
+
+func (f *fakeNATS) published() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.subjects...)
+}
+
+func TestNATSSink(t *testing.T) {
+	srv := newFakeNATS(t)
+	defer srv.ln.Close()
+	ctx := context.Background()
+
+	core, err := NewNATSSink("nats://"+srv.ln.Addr().String(), "titan.events", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer core.Close()
+	if err := core.Publish(ctx, Event{ID: "evt_1", Type: "alert"}, []byte(`{}`)); err != nil {
+		t.Fatalf("Core publish failed: %v", err)
+	}
+
+	js, err := NewNATSSink("nats://token@"+srv.ln.Addr().String(), "titan.events", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer js.Close()
+	bus := NewBus(js)
+	bus.Backoff = time.Millisecond
+	e := Event{ID: "evt_2", Type: "execution"}
+	if err := bus.send(ctx, js, e, []byte(`{}`)); err != nil {
+		t.Fatalf("Expected the rejected publish to succeed on retry, got %v", err)
+	}
+	if err := js.Publish(ctx, e, []byte(`{}`)); err != nil {
+		t.Fatalf("Expected duplicate to be acked, got %v", err)
+	}
+
+	got := srv.published()
+	if len(got) != 2 || got[0] != "titan.events.alert" || got[1] != "titan.events.execution" {
+		t.Errorf("Expected one core and one deduplicated JetStream publish, got %v", got)
+	}
+	if _, err := NewNATSSink("tls://localhost", "x", false); err == nil {
+		t.Error("Expected unsupported scheme to fail")
+	}
+}
+
+func TestFromConfigWithoutSinks(t *testing.T) {
+	if bus, err := FromConfig(&config.EventBusConfig{RedisStream: "titan:events"}); bus != nil || err != nil {
+		t.Errorf("Expected no bus, got %v, %v", bus, err)
+	}
+}
@@ -0,0 +1,238 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsInbox is the reply subject JetStream acks are sent to
+const natsInbox = "_INBOX.titan"
+
+// NATSSink publishes each event to subject.<type>. With JetStream on it
+// waits for the stream's ack and sets Nats-Msg-Id so the server drops
+// retried duplicates; otherwise a PING round trip confirms the server
+// processed the publish.
+type NATSSink struct {
+	addr      string
+	subject   string
+	jetStream bool
+	connect   []byte
+
+	// Timeout bounds dialing and each publish
+	Timeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewNATSSink creates a sink for nats://[user:pass@|token@]host[:port];
+// the connection is opened on first publish
+func NewNATSSink(rawURL, subject string, jetStream bool) (*NATSSink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "nats" {
+		return nil, fmt.Errorf("nats: unsupported scheme %q", u.Scheme)
+	}
+	s := &NATSSink{addr: u.Host, subject: subject, jetStream: jetStream, Timeout: 5 * time.Second}
+	if u.Port() == "" {
+		s.addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	opts := map[string]interface{}{"verbose": false, "pedantic": false, "headers": true, "name": "titan-core"}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			opts["user"], opts["pass"] = u.User.Username(), pass
+		} else {
+			opts["auth_token"] = u.User.Username()
+		}
+	}
+	if s.connect, err = json.Marshal(opts); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Name returns the sink name
+func (s *NATSSink) Name() string { return "nats" }
+
+// Publish sends the event and waits for confirmation
+func (s *NATSSink) Publish(ctx context.Context, e Event, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		if err := s.dial(ctx); err != nil {
+			return err
+		}
+	}
+	err := s.publish(ctx, s.subject+"."+e.Type, e.ID, body)
+	if err != nil {
+		var replyErr natsError
+		if !errors.As(err, &replyErr) {
+			s.closeLocked()
+		}
+	}
+	return err
+}
+
+// Close closes the connection
+func (s *NATSSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeLocked()
+}
+
+func (s *NATSSink) closeLocked() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn, s.r = nil, nil
+	return err
+}
+
+// natsError is a JetStream rejection; the connection stays usable
+type natsError string
+
+func (e natsError) Error() string { return "nats: " + string(e) }
+
+func (s *NATSSink) dial(ctx context.Context) error {
+	d := net.Dialer{Timeout: s.Timeout}
+	conn, err := d.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.conn, s.r = conn, bufio.NewReader(conn)
+	s.setDeadline(ctx)
+	// The server greets with INFO; CONNECT then PING confirms auth succeeded
+	if _, err := s.r.ReadString('\n'); err != nil {
+		s.closeLocked()
+		return err
+	}
+	handshake := "CONNECT " + string(s.connect) + "\r\nPING\r\n"
+	if s.jetStream {
+		handshake += "SUB " + natsInbox + " 1\r\n"
+	}
+	if _, err := io.WriteString(conn, handshake); err != nil {
+		s.closeLocked()
+		return err
+	}
+	if _, err := s.await(false); err != nil {
+		s.closeLocked()
+		return err
+	}
+	return nil
+}
+
+func (s *NATSSink) publish(ctx context.Context, subject, id string, body []byte) error {
+	s.setDeadline(ctx)
+	var msg strings.Builder
+	if s.jetStream {
+		header := "NATS/1.0\r\nNats-Msg-Id: " + id + "\r\n\r\n"
+		fmt.Fprintf(&msg, "HPUB %s %s %d %d\r\n%s", subject, natsInbox, len(header), len(header)+len(body), header)
+	} else {
+		fmt.Fprintf(&msg, "PUB %s %d\r\n", subject, len(body))
+	}
+	msg.Write(body)
+	msg.WriteString("\r\n")
+	if !s.jetStream {
+		msg.WriteString("PING\r\n")
+	}
+	if _, err := io.WriteString(s.conn, msg.String()); err != nil {
+		return err
+	}
+	ack, err := s.await(s.jetStream)
+	if err != nil || !s.jetStream {
+		return err
+	}
+	var result struct {
+		Stream string `json:"stream"`
+		Error  *struct {
+			Description string `json:"description"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(ack, &result); err != nil {
+		return fmt.Errorf("nats: bad ack: %w", err)
+	}
+	if result.Error != nil {
+		return natsError(result.Error.Description)
+	}
+	if result.Stream == "" {
+		return natsError("no stream matches " + subject)
+	}
+	return nil
+}
+
+// await reads protocol lines until a PONG, or a MSG payload when wantMsg,
+// answering server PINGs on the way
+func (s *NATSSink) await(wantMsg bool) ([]byte, error) {
+	for {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		op, rest, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(op) {
+		case "PING":
+			if _, err := io.WriteString(s.conn, "PONG\r\n"); err != nil {
+				return nil, err
+			}
+		case "PONG":
+			if !wantMsg {
+				return nil, nil
+			}
+		case "-ERR":
+			return nil, fmt.Errorf("nats: %s", strings.Trim(rest, "' "))
+		case "MSG", "HMSG":
+			payload, err := s.readMsg(strings.ToUpper(op), strings.Fields(rest))
+			if err != nil {
+				return nil, err
+			}
+			if wantMsg {
+				return payload, nil
+			}
+		}
+	}
+}
+
+// readMsg reads a MSG or HMSG payload, dropping any headers
+func (s *NATSSink) readMsg(op string, fields []string) ([]byte, error) {
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("nats: bad %s line", op)
+	}
+	total, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil {
+		return nil, fmt.Errorf("nats: bad %s size", op)
+	}
+	headers := 0
+	if op == "HMSG" {
+		if headers, err = strconv.Atoi(fields[len(fields)-2]); err != nil || headers > total {
+			return nil, fmt.Errorf("nats: bad %s header size", op)
+		}
+	}
+	buf := make([]byte, total+2)
+	if _, err := io.ReadFull(s.r, buf); err != nil {
+		return nil, err
+	}
+	return buf[headers:total], nil
+}
+
+func (s *NATSSink) setDeadline(ctx context.Context) {
+	deadline := time.Now().Add(s.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	s.conn.SetDeadline(deadline)
+}
@@ -0,0 +1,43 @@
+package events
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/redis"
+)
+
+// RedisSink appends events to a Redis stream with XADD. Consumers replay
+// from any entry ID with XREAD or XREADGROUP.
+type RedisSink struct {
+	client *redis.Client
+	stream string
+	maxLen uint64
+}
+
+// NewRedisSink creates a sink for stream at url, trimming it to about
+// maxLen entries (0 keeps everything)
+func NewRedisSink(url, stream string, maxLen uint64) (*RedisSink, error) {
+	client, err := redis.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisSink{client: client, stream: stream, maxLen: maxLen}, nil
+}
+
+// Name returns the sink name
+func (s *RedisSink) Name() string { return "redis" }
+
+// Publish appends the event with its type and JSON body as fields
+func (s *RedisSink) Publish(ctx context.Context, e Event, body []byte) error {
+	args := []string{"XADD", s.stream}
+	if s.maxLen > 0 {
+		args = append(args, "MAXLEN", "~", strconv.FormatUint(s.maxLen, 10))
+	}
+	args = append(args, "*", "id", e.ID, "type", e.Type, "event", string(body))
+	_, err := s.client.String(ctx, args...)
+	return err
+}
+
+// Close closes the connection
+func (s *RedisSink) Close() error { return s.client.Close() }
@@ -0,0 +1,114 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrAccessListUnsupported is returned when the node doesn't implement
+// eth_createAccessList
+var ErrAccessListUnsupported = errors.New("execution: eth_createAccessList not supported")
+
+// CreateAccessList asks the node for the addresses and slots call touches,
+// returning the list and the gas the call uses with it applied
+func CreateAccessList(ctx context.Context, client RPC, call Call) (types.AccessList, uint64, error) {
+	var result struct {
+		AccessList types.AccessList `json:"accessList"`
+		GasUsed    hexutil.Uint64   `json:"gasUsed"`
+		Error      string           `json:"error"`
+	}
+	if err := client.CallContext(ctx, &result, "eth_createAccessList", callArgs(call), "pending"); err != nil {
+		if methodUnsupported(err) {
+			return nil, 0, fmt.Errorf("%w: %v", ErrAccessListUnsupported, err)
+		}
+		return nil, 0, fmt.Errorf("access list creation failed: %w", err)
+	}
+	if result.Error != "" {
+		return nil, 0, fmt.Errorf("access list creation failed: %s", result.Error)
+	}
+	return result.AccessList, uint64(result.GasUsed), nil
+}
+
+// AccessLister attaches access lists to calls when they lower gas, which
+// they do for multi-pool swaps that read many cold slots. Chains whose
+// node lacks eth_createAccessList are remembered and skipped afterwards.
+type AccessLister struct {
+	mu          sync.Mutex
+	unsupported map[uint64]bool
+}
+
+// NewAccessLister creates an access lister
+func NewAccessLister() *AccessLister {
+	return &AccessLister{unsupported: make(map[uint64]bool)}
+}
+
+// Attach returns call with an access list set if the adapter's transaction
+// type carries one, the node can generate it, and it saves gas over the
+// plain estimate. Otherwise call comes back unchanged; errors are only
+// returned when the call itself fails.
+func (l *AccessLister) Attach(ctx context.Context, client RPC, adapter Adapter, call Call) (Call, error) {
+	if !supportsAccessList(adapter) || l.skip(call.ChainID) {
+		return call, nil
+	}
+	call.AccessList = nil
+	list, withList, err := CreateAccessList(ctx, client, call)
+	if errors.Is(err, ErrAccessListUnsupported) {
+		l.mu.Lock()
+		l.unsupported[call.ChainID] = true
+		l.mu.Unlock()
+		log.Printf("⚠️ Chain %d: %v; sending without access lists", call.ChainID, err)
+		return call, nil
+	}
+	if err != nil || len(list) == 0 {
+		return call, err
+	}
+	// Listing an address costs 2400 gas up front and each slot 1900, so a
+	// list only pays off when it saves more cold accesses than it adds
+	var without hexutil.Uint64
+	if err := client.CallContext(ctx, &without, "eth_estimateGas", callArgs(call)); err != nil {
+		return call, fmt.Errorf("gas estimation failed: %w", err)
+	}
+	if withList < uint64(without) {
+		call.AccessList = list
+	}
+	return call, nil
+}
+
+func (l *AccessLister) skip(chainID uint64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.unsupported[chainID]
+}
+
+// supportsAccessList reports whether the adapter's transaction type has an
+// access list field; zkSync's EIP-712 transactions don't
+func supportsAccessList(adapter Adapter) bool {
+	switch adapter.(type) {
+	case EVMAdapter, MantleAdapter, *CeloAdapter:
+		return true
+	}
+	return false
+}
+
+// methodUnsupported recognises the errors nodes and providers return for
+// methods they don't implement or have disabled
+func methodUnsupported(err error) bool {
+	var rpcErr interface{ ErrorCode() int }
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601 {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"method not found", "not supported", "unsupported method", "does not exist", "not available"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type rpcCodeError struct {
+	code int
+	msg  string
+}
+
+func (e rpcCodeError) Error() string  { return e.msg }
+func (e rpcCodeError) ErrorCode() int { return e.code }
+
+// accessListRPC serves eth_createAccessList and eth_estimateGas, or fails
+// eth_createAccessList with err
+type accessListRPC struct {
+	fakeRPC
+	err   error
+	calls map[string]int
+}
+
+func (r *accessListRPC) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	r.calls[method]++
+	if method == "eth_createAccessList" && r.err != nil {
+		return r.err
+	}
+	return r.fakeRPC.CallContext(ctx, result, method, args...)
+}
+
+func newAccessListRPC(gasWith, gasWithout uint64) *accessListRPC {
+	pool := common.HexToAddress("0x88e6A0c2dDD26FEEb64F039a2c41296FcB3f5640")
+	return &accessListRPC{
+		calls: make(map[string]int),
+		fakeRPC: fakeRPC{results: map[string]interface{}{
+			"eth_createAccessList": map[string]interface{}{
+				"accessList": types.AccessList{{Address: pool, StorageKeys: []common.Hash{{1}, {2}}}},
+				"gasUsed":    hexutil.Uint64(gasWith),
+			},
+			"eth_estimateGas": hexutil.Uint64(gasWithout),
+		}},
+	}
+}
+
+func TestAccessListerAttachesWhenCheaper(t *testing.T) {
+	ctx := context.Background()
+	lister := NewAccessLister()
+	client := newAccessListRPC(180_000, 184_000)
+	call, err := lister.Attach(ctx, client, For(1), testCall(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(call.AccessList) != 1 || len(call.AccessList[0].StorageKeys) != 2 {
+		t.Fatalf("Expected the node's access list, got %+v", call.AccessList)
+	}
+	if _, err := For(1).EstimateGas(ctx, client, call); err != nil || client.args[0].(map[string]interface{})["accessList"] == nil {
+		t.Errorf("Expected the estimate to include the access list, got %v", client.args)
+	}
+
+	key, _ := crypto.GenerateKey()
+	raw, _, err := For(1).Sign(call, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil || len(tx.AccessList()) != 1 {
+		t.Errorf("Expected the signed transaction to carry the list, got %v", err)
+	}
+
+	// A list that costs more than it saves is dropped
+	call, _ = lister.Attach(ctx, newAccessListRPC(190_000, 184_000), For(1), testCall(1))
+	if call.AccessList != nil {
+		t.Errorf("Expected no access list when it raises gas, got %+v", call.AccessList)
+	}
+}
+
+func TestAccessListerFallsBack(t *testing.T) {
+	ctx := context.Background()
+	lister := NewAccessLister()
+	client := newAccessListRPC(1, 2)
+	client.err = rpcCodeError{-32601, "the method eth_createAccessList does not exist/is not available"}
+	for i := 0; i < 2; i++ {
+		call, err := lister.Attach(ctx, client, For(137), testCall(137))
+		if err != nil || call.AccessList != nil {
+			t.Fatalf("Expected a graceful fallback, got %+v, %v", call.AccessList, err)
+		}
+	}
+	if client.calls["eth_createAccessList"] != 1 {
+		t.Errorf("Expected the unsupported chain to be remembered, got %d attempts", client.calls["eth_createAccessList"])
+	}
+
+	client = newAccessListRPC(1, 2)
+	if _, err := lister.Attach(ctx, client, NewZkSyncAdapter(), testCall(324)); err != nil || client.calls["eth_createAccessList"] != 0 {
+		t.Errorf("Expected zkSync transactions to skip access lists, got %v", err)
+	}
+
+	client.err = errors.New("execution reverted")
+	if _, err := lister.Attach(ctx, client, For(1), testCall(1)); err == nil || errors.Is(err, ErrAccessListUnsupported) {
+		t.Errorf("Expected a revert to surface, got %v", err)
+	}
+}
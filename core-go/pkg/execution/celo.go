@@ -0,0 +1,154 @@
+package execution
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+)
+
+// CeloFeeCurrencyTxType is the EIP-2718 type of CIP-64 fee-currency transactions
+const CeloFeeCurrencyTxType = 0x7b
+
+// FeeCurrency is an ERC20 Celo accepts for gas in place of CELO. Tokens with
+// fewer than 18 decimals are registered through an adapter contract: fees are
+// quoted and passed in the adapter's 18-decimal units but debited from Token.
+type FeeCurrency struct {
+	Symbol   string
+	Address  common.Address // passed as the transaction's feeCurrency
+	Token    common.Address // ERC20 actually debited
+	Decimals uint8          // of Token
+	USDPeg   bool
+}
+
+// celoFeeCurrencies are the fee currencies allowlisted on Celo mainnet
+var celoFeeCurrencies = []FeeCurrency{
+	{Symbol: "cUSD", Address: common.HexToAddress("0x765DE816845861e75A25fCA122bb6898B8B1282a"), Token: common.HexToAddress("0x765DE816845861e75A25fCA122bb6898B8B1282a"), Decimals: 18, USDPeg: true},
+	{Symbol: "USDC", Address: common.HexToAddress("0x2F25deB3848C207fc8E0c34035B3Ba7fC157602B"), Token: common.HexToAddress("0xcebA9300f2b948710d2653dD7B07f33A8B32118C"), Decimals: 6, USDPeg: true},
+	{Symbol: "USDT", Address: common.HexToAddress("0x0E2A3e05bc9A16F5292A6170456A710cb89C6f72"), Token: common.HexToAddress("0x48065fbBE25f71C9282ddf5e1cD6D6A887483D5e"), Decimals: 6, USDPeg: true},
+}
+
+// CeloFeeCurrency resolves a fee currency by symbol, adapter address or token address
+func CeloFeeCurrency(symbolOrAddress string) (FeeCurrency, bool) {
+	for _, fc := range celoFeeCurrencies {
+		if strings.EqualFold(fc.Symbol, symbolOrAddress) ||
+			(common.IsHexAddress(symbolOrAddress) && (common.HexToAddress(symbolOrAddress) == fc.Address || common.HexToAddress(symbolOrAddress) == fc.Token)) {
+			return fc, true
+		}
+	}
+	return FeeCurrency{}, false
+}
+
+// CeloAdapter handles Celo. Without a fee currency it behaves like a standard
+// EIP-1559 chain paying in CELO; with one it signs CIP-64 transactions and
+// the call's fees must be quoted in that currency (see QuoteFees).
+type CeloAdapter struct {
+	FeeCurrency *FeeCurrency
+}
+
+// Name returns the adapter name
+func (c *CeloAdapter) Name() string { return "celo" }
+
+// EstimateGas includes the fee currency, whose debit and credit cost extra intrinsic gas
+func (c *CeloAdapter) EstimateGas(ctx context.Context, client RPC, call Call) (uint64, error) {
+	args := callArgs(call)
+	if c.FeeCurrency != nil {
+		args["feeCurrency"] = c.FeeCurrency.Address
+	}
+	return estimateGas(ctx, client, args)
+}
+
+// QuoteFees prices gas in the fee currency and applies policy. Without a fee
+// currency the chain's gas oracle should be used instead.
+func (c *CeloAdapter) QuoteFees(ctx context.Context, client RPC, policy gas.Policy) (gas.Fees, error) {
+	var params []interface{}
+	if c.FeeCurrency != nil {
+		params = append(params, c.FeeCurrency.Address)
+	}
+	var price, tip hexutil.Big
+	if err := client.CallContext(ctx, &price, "eth_gasPrice", params...); err != nil {
+		return gas.Fees{}, err
+	}
+	if err := client.CallContext(ctx, &tip, "eth_maxPriorityFeePerGas", params...); err != nil {
+		return gas.Fees{}, err
+	}
+	baseFee := new(big.Int).Sub((*big.Int)(&price), (*big.Int)(&tip))
+	if baseFee.Sign() < 0 {
+		baseFee.SetUint64(0)
+	}
+	return policy.Apply(gas.Fees{BaseFee: baseFee, TipCap: (*big.Int)(&tip), Source: "celo"})
+}
+
+// Sign signs a CIP-64 transaction, or a standard one without a fee currency
+func (c *CeloAdapter) Sign(call Call, key *ecdsa.PrivateKey) ([]byte, common.Hash, error) {
+	if c.FeeCurrency == nil {
+		return EVMAdapter{}.Sign(call, key)
+	}
+	if _, err := maxFee(call); err != nil {
+		return nil, common.Hash{}, err
+	}
+	unsigned := c.fields(call)
+	payload, err := rlp.EncodeToBytes(unsigned)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	sig, err := crypto.Sign(crypto.Keccak256(append([]byte{CeloFeeCurrencyTxType}, payload...)), key)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+
+	signed := append(unsigned, uint64(sig[64]), new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64]))
+	payload, err = rlp.EncodeToBytes(signed)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	raw := append([]byte{CeloFeeCurrencyTxType}, payload...)
+	return raw, crypto.Keccak256Hash(raw), nil
+}
+
+// fields returns the unsigned CIP-64 payload
+func (c *CeloAdapter) fields(call Call) []interface{} {
+	return []interface{}{
+		call.ChainID,
+		call.Nonce,
+		tipOrZero(call),
+		call.Fees.FeeCap,
+		call.GasLimit,
+		call.To,
+		valueOrZero(call.Value),
+		call.Data,
+		call.AccessList, // nil encodes as an empty list
+		c.FeeCurrency.Address,
+	}
+}
+
+// MaxCost reports the fee in the debited token's own units, scaling down
+// from the adapter's 18-decimal quote and rounding up
+func (c *CeloAdapter) MaxCost(ctx context.Context, client RPC, call Call) (Cost, error) {
+	fee, err := maxFee(call)
+	if err != nil {
+		return Cost{}, err
+	}
+	if c.FeeCurrency == nil {
+		return NativeCost(call, fee), nil
+	}
+	fc := c.FeeCurrency
+	if fc.Decimals < nativeDecimals {
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(nativeDecimals-fc.Decimals)), nil)
+		fee.Add(fee, new(big.Int).Sub(scale, big.NewInt(1)))
+		fee.Quo(fee, scale)
+	}
+	return Cost{
+		Value:       valueOrZero(call.Value),
+		Fee:         fee,
+		FeeToken:    fc.Token,
+		FeeDecimals: fc.Decimals,
+		FeeUSDPeg:   fc.USDPeg,
+	}, nil
+}
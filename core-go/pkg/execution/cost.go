@@ -0,0 +1,89 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// ErrInsufficientFunds is returned when the sender cannot cover a transaction's worst-case cost
+var ErrInsufficientFunds = errors.New("execution: insufficient funds")
+
+// balanceOfSelector is the ERC20 balanceOf(address) selector
+var balanceOfSelector = crypto.Keccak256([]byte("balanceOf(address)"))[:4]
+
+// Cost is the worst-case spend of a transaction, split by the asset that pays it
+type Cost struct {
+	Value       *big.Int       `json:"value"`    // native value transferred
+	Fee         *big.Int       `json:"fee"`      // max gas fee, in FeeToken base units
+	FeeToken    common.Address `json:"feeToken"` // zero when gas is paid in the native token
+	FeeDecimals uint8          `json:"feeDecimals"`
+	FeeUSDPeg   bool           `json:"feeUsdPeg"` // fee token is a USD stablecoin
+}
+
+// NativeCost is the cost of a call whose gas is paid in the native token
+func NativeCost(call Call, fee *big.Int) Cost {
+	return Cost{Value: valueOrZero(call.Value), Fee: fee, FeeDecimals: nativeDecimals}
+}
+
+// nativeDecimals is the decimals of every supported chain's native gas token
+const nativeDecimals = 18
+
+// maxFee is the gas limit times the fee cap
+func maxFee(call Call) (*big.Int, error) {
+	if call.Fees.FeeCap == nil {
+		return nil, errors.New("execution: fees not set")
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(call.GasLimit), call.Fees.FeeCap), nil
+}
+
+// FeeUSD values the gas fee; nativeUSD prices native wei on the transaction's chain
+func (c Cost) FeeUSD(nativeUSD func(wei *big.Int) (units.USD, error)) (units.USD, error) {
+	if c.FeeToken == (common.Address{}) {
+		return nativeUSD(c.Fee)
+	}
+	if !c.FeeUSDPeg {
+		return 0, fmt.Errorf("no USD price for fee token %s", c.FeeToken.Hex())
+	}
+	amount, err := units.FromBig(c.FeeToken, c.Fee, c.FeeDecimals)
+	if err != nil {
+		return 0, err
+	}
+	return amount.ToUSD(1e8)
+}
+
+// CheckBalance verifies from holds enough native token for the value (and the
+// fee, when gas is paid natively) and enough fee token otherwise
+func CheckBalance(ctx context.Context, client RPC, from common.Address, cost Cost) error {
+	var native hexutil.Big
+	if err := client.CallContext(ctx, &native, "eth_getBalance", from, "latest"); err != nil {
+		return err
+	}
+	needNative := valueOrZero(cost.Value)
+	if cost.FeeToken == (common.Address{}) {
+		needNative = new(big.Int).Add(needNative, cost.Fee)
+	}
+	if have := (*big.Int)(&native); have.Cmp(needNative) < 0 {
+		return fmt.Errorf("%w: native balance %s, need %s", ErrInsufficientFunds, have, needNative)
+	}
+	if cost.FeeToken == (common.Address{}) {
+		return nil
+	}
+
+	var out hexutil.Bytes
+	data := append(append([]byte{}, balanceOfSelector...), common.LeftPadBytes(from.Bytes(), 32)...)
+	args := map[string]interface{}{"to": cost.FeeToken, "data": hexutil.Bytes(data)}
+	if err := client.CallContext(ctx, &out, "eth_call", args, "latest"); err != nil {
+		return fmt.Errorf("fee token balance: %w", err)
+	}
+	if have := new(big.Int).SetBytes(out); have.Cmp(cost.Fee) < 0 {
+		return fmt.Errorf("%w: fee token %s balance %s, need %s", ErrInsufficientFunds, cost.FeeToken.Hex(), have, cost.Fee)
+	}
+	return nil
+}
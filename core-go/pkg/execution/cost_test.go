@@ -0,0 +1,153 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+func TestMantleCostIncludesL1Fee(t *testing.T) {
+	call := testCall(5000)
+	node := &fakeRPC{results: map[string]interface{}{
+		"eth_call": hexutil.Bytes(common.LeftPadBytes(big.NewInt(1_000_000).Bytes(), 32)),
+	}}
+	cost, err := For(5000).MaxCost(context.Background(), node, call)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1.5M gas at 0.25 gwei plus the oracle's L1 fee, all in MNT
+	want := new(big.Int).Add(big.NewInt(1_500_000*250_000_000), big.NewInt(1_000_000))
+	if cost.Fee.Cmp(want) != 0 || cost.FeeToken != (common.Address{}) {
+		t.Errorf("Expected native fee %s, got %s in %s", want, cost.Fee, cost.FeeToken.Hex())
+	}
+	if to := node.args[0].(map[string]interface{})["to"]; to != MantleGasPriceOracle {
+		t.Errorf("Expected L1 fee from the gas price oracle, got %v", to)
+	}
+}
+
+// oracleRPC answers eth_calls to the gas price oracle by selector
+type oracleRPC map[string]int64
+
+func (o oracleRPC) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	call := args[0].(map[string]interface{})
+	v, ok := o[call["data"].(hexutil.Bytes).String()]
+	if !ok || call["to"] != GasPriceOracle {
+		return errors.New("execution reverted")
+	}
+	*result.(*hexutil.Bytes) = common.LeftPadBytes(big.NewInt(v).Bytes(), 32)
+	return nil
+}
+
+func TestEcotoneL1FeeReadsOracle(t *testing.T) {
+	selector := func(sig string) string { return hexutil.Encode(crypto.Keccak256([]byte(sig))[:4]) }
+	node := oracleRPC{
+		selector("l1BaseFee()"):         20e9,
+		selector("blobBaseFee()"):       1,
+		selector("baseFeeScalar()"):     1368,
+		selector("blobBaseFeeScalar()"): 810949,
+	}
+	fee, err := EcotoneL1Fee(context.Background(), node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee.BaseFee.Int64() != 20e9 || fee.BlobBaseFee.Int64() != 1 || fee.BaseFeeScalar != 1368 || fee.BlobBaseFeeScalar != 810949 {
+		t.Errorf("Expected the oracle's Ecotone parameters, got %+v", fee)
+	}
+
+	// Before Ecotone the oracle has no blob base fee
+	delete(node, selector("blobBaseFee()"))
+	if _, err := EcotoneL1Fee(context.Background(), node); err == nil {
+		t.Error("Expected an error from a pre-Ecotone oracle")
+	}
+	if !OPStack(8453) || OPStack(5000) {
+		t.Error("Expected Base on the OP stack and Mantle, with its own oracle, off it")
+	}
+}
+
+func TestCeloFeeCurrencyCost(t *testing.T) {
+	adapter, err := FromConfig(42220, &config.ChainConfig{FeeCurrency: "usdc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := testCall(42220)
+	call.GasLimit = 100_000
+	call.Fees = gas.Fees{TipCap: gas.Gwei(1), FeeCap: big.NewInt(25_000_000_001)}
+
+	cost, err := adapter.MaxCost(context.Background(), nil, call)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2.5000000001e15 adapter units is 0.0025000000001 USDC, rounded up to 2501 base units
+	if cost.Fee.Int64() != 2501 || cost.FeeDecimals != 6 {
+		t.Errorf("Expected 2501 USDC base units, got %s (%d decimals)", cost.Fee, cost.FeeDecimals)
+	}
+	usd, err := cost.FeeUSD(nil)
+	if err != nil || usd != units.USD(2501) {
+		t.Errorf("Expected $0.002501, got %s (%v)", usd, err)
+	}
+
+	if _, err := FromConfig(1, &config.ChainConfig{FeeCurrency: "usdc"}); err == nil {
+		t.Error("Expected fee currencies to be refused off Celo")
+	}
+}
+
+func TestCeloSignsCIP64(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	fc, _ := CeloFeeCurrency("cUSD")
+	adapter := &CeloAdapter{FeeCurrency: &fc}
+	call := testCall(42220)
+
+	raw, hash, err := adapter.Sign(call, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw[0] != CeloFeeCurrencyTxType || hash != crypto.Keccak256Hash(raw) {
+		t.Fatalf("Expected type 0x7b transaction hashed over its encoding")
+	}
+	var fields []rlp.RawValue
+	if err := rlp.DecodeBytes(raw[1:], &fields); err != nil || len(fields) != 13 {
+		t.Fatalf("Expected 13 fields, got %d (%v)", len(fields), err)
+	}
+	var feeCurrency common.Address
+	if err := rlp.DecodeBytes(fields[9], &feeCurrency); err != nil || feeCurrency != fc.Address {
+		t.Errorf("Expected fee currency %s, got %s", fc.Address.Hex(), feeCurrency.Hex())
+	}
+
+	unsigned, _ := rlp.EncodeToBytes(adapter.fields(call))
+	var v uint64
+	var r, s big.Int
+	rlp.DecodeBytes(fields[10], &v)
+	rlp.DecodeBytes(fields[11], &r)
+	rlp.DecodeBytes(fields[12], &s)
+	sig := append(append(common.LeftPadBytes(r.Bytes(), 32), common.LeftPadBytes(s.Bytes(), 32)...), byte(v))
+	pub, err := crypto.SigToPub(crypto.Keccak256(append([]byte{CeloFeeCurrencyTxType}, unsigned...)), sig)
+	if err != nil || crypto.PubkeyToAddress(*pub) != Sender(key) {
+		t.Errorf("Expected signature to recover the sender (%v)", err)
+	}
+}
+
+func TestCheckBalanceChecksFeeToken(t *testing.T) {
+	from := common.HexToAddress("0x1")
+	cost := Cost{Value: big.NewInt(0), Fee: big.NewInt(5000), FeeToken: common.HexToAddress("0xcafe"), FeeDecimals: 6}
+	node := &fakeRPC{results: map[string]interface{}{
+		"eth_getBalance": (*hexutil.Big)(big.NewInt(0)),
+		"eth_call":       hexutil.Bytes(common.LeftPadBytes(big.NewInt(4999).Bytes(), 32)),
+	}}
+	if err := CheckBalance(context.Background(), node, from, cost); !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("Expected insufficient fee token balance, got %v", err)
+	}
+
+	node.results["eth_call"] = hexutil.Bytes(common.LeftPadBytes(big.NewInt(5000).Bytes(), 32))
+	if err := CheckBalance(context.Background(), node, from, cost); err != nil {
+		t.Errorf("Expected fee token to cover the fee with no native balance, got %v", err)
+	}
+}
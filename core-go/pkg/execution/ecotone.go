@@ -0,0 +1,68 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/route"
+)
+
+// GasPriceOracle is the OP-stack predeploy reporting the L1 data fee parameters
+var GasPriceOracle = common.HexToAddress("0x420000000000000000000000000000000000000F")
+
+// OPStack reports whether chainID is an OP-stack chain, where calldata pays
+// an L1 data fee priced by the Ecotone formula
+func OPStack(chainID uint64) bool {
+	switch enum.ChainID(chainID) {
+	case enum.Optimism, enum.Base, enum.OpBNB:
+		return true
+	}
+	return false
+}
+
+// EcotoneL1Fee reads the Ecotone L1 data fee parameters from the chain's gas
+// price oracle. Chains not yet on Ecotone have no blob base fee and fail.
+func EcotoneL1Fee(ctx context.Context, client RPC) (route.L1Fee, error) {
+	var fee route.L1Fee
+	var err error
+	if fee.BaseFee, err = oracleUint(ctx, client, "l1BaseFee()"); err != nil {
+		return route.L1Fee{}, err
+	}
+	if fee.BlobBaseFee, err = oracleUint(ctx, client, "blobBaseFee()"); err != nil {
+		return route.L1Fee{}, err
+	}
+	scalar, err := oracleUint(ctx, client, "baseFeeScalar()")
+	if err != nil {
+		return route.L1Fee{}, err
+	}
+	blobScalar, err := oracleUint(ctx, client, "blobBaseFeeScalar()")
+	if err != nil {
+		return route.L1Fee{}, err
+	}
+	if !scalar.IsUint64() || scalar.Uint64() > 0xffffffff || !blobScalar.IsUint64() || blobScalar.Uint64() > 0xffffffff {
+		return route.L1Fee{}, fmt.Errorf("gas price oracle: scalars %s and %s exceed uint32", scalar, blobScalar)
+	}
+	fee.BaseFeeScalar, fee.BlobBaseFeeScalar = uint32(scalar.Uint64()), uint32(blobScalar.Uint64())
+	return fee, nil
+}
+
+// oracleUint calls a no-argument view of the gas price oracle returning one word
+func oracleUint(ctx context.Context, client RPC, method string) (*big.Int, error) {
+	var out hexutil.Bytes
+	args := map[string]interface{}{
+		"to":   GasPriceOracle,
+		"data": hexutil.Bytes(crypto.Keccak256([]byte(method))[:4]),
+	}
+	if err := client.CallContext(ctx, &out, "eth_call", args, "latest"); err != nil {
+		return nil, fmt.Errorf("gas price oracle %s: %w", method, err)
+	}
+	if len(out) != 32 {
+		return nil, fmt.Errorf("gas price oracle %s: %d-byte result", method, len(out))
+	}
+	return new(big.Int).SetBytes(out), nil
+}
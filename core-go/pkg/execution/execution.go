@@ -0,0 +1,176 @@
+package execution
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+)
+
+// ErrHashMismatch is returned when the node reports a different hash than the adapter computed
+var ErrHashMismatch = errors.New("execution: node returned unexpected transaction hash")
+
+// gasBufferBps pads node gas estimates so small state changes don't cause out-of-gas reverts
+const gasBufferBps = 12000
+
+// Call is a contract call ready to be signed and broadcast
+type Call struct {
+	ChainID  uint64
+	From     common.Address
+	To       common.Address
+	Data     []byte
+	Value    *big.Int
+	Nonce    uint64
+	GasLimit uint64
+	Fees     gas.Fees
+	// AccessList pre-warms addresses and slots (EIP-2930); nil for none
+	AccessList types.AccessList
+}
+
+// RPC is the raw JSON-RPC access adapters need; *rpc.Client satisfies it
+type RPC interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// Adapter builds, signs and estimates transactions in a chain's native format
+type Adapter interface {
+	Name() string
+	// EstimateGas returns a padded gas limit for call
+	EstimateGas(ctx context.Context, client RPC, call Call) (uint64, error)
+	// Sign returns the raw transaction bytes and the hash the chain will report
+	Sign(call Call, key *ecdsa.PrivateKey) (raw []byte, hash common.Hash, err error)
+	// MaxCost returns the most call can spend, in the assets the chain charges
+	MaxCost(ctx context.Context, client RPC, call Call) (Cost, error)
+}
+
+// For returns the execution adapter for a chain, paying gas in the native token
+func For(chainID uint64) Adapter {
+	switch enum.ChainID(chainID) {
+	case enum.ZkSync:
+		return NewZkSyncAdapter()
+	case enum.Mantle:
+		return MantleAdapter{}
+	case enum.Celo:
+		return &CeloAdapter{}
+	}
+	return EVMAdapter{}
+}
+
+// FromConfig returns the adapter for a chain with its configured fee currency, if any
+func FromConfig(chainID uint64, chain *config.ChainConfig) (Adapter, error) {
+	if chain == nil || chain.FeeCurrency == "" {
+		return For(chainID), nil
+	}
+	if enum.ChainID(chainID) != enum.Celo {
+		return nil, fmt.Errorf("chain %d does not support fee currencies", chainID)
+	}
+	fc, ok := CeloFeeCurrency(chain.FeeCurrency)
+	if !ok {
+		return nil, fmt.Errorf("unknown Celo fee currency %q", chain.FeeCurrency)
+	}
+	return &CeloAdapter{FeeCurrency: &fc}, nil
+}
+
+// Broadcast submits a signed transaction and checks the node agrees on its hash
+func Broadcast(ctx context.Context, client RPC, raw []byte, want common.Hash) error {
+	var got common.Hash
+	if err := client.CallContext(ctx, &got, "eth_sendRawTransaction", hexutil.Encode(raw)); err != nil {
+		return err
+	}
+	if got != want {
+		return fmt.Errorf("%w: sent %s, node reported %s", ErrHashMismatch, want.Hex(), got.Hex())
+	}
+	return nil
+}
+
+// EVMAdapter signs standard EIP-1559 transactions
+type EVMAdapter struct{}
+
+// Name returns the adapter name
+func (EVMAdapter) Name() string { return "evm" }
+
+// EstimateGas calls eth_estimateGas and pads the result
+func (EVMAdapter) EstimateGas(ctx context.Context, client RPC, call Call) (uint64, error) {
+	return estimateGas(ctx, client, callArgs(call))
+}
+
+// Sign builds and signs a dynamic-fee transaction
+func (EVMAdapter) Sign(call Call, key *ecdsa.PrivateKey) ([]byte, common.Hash, error) {
+	if call.Fees.TipCap == nil || call.Fees.FeeCap == nil {
+		return nil, common.Hash{}, errors.New("execution: fees not set")
+	}
+	chainID := new(big.Int).SetUint64(call.ChainID)
+	to := call.To
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:    chainID,
+		Nonce:      call.Nonce,
+		GasTipCap:  call.Fees.TipCap,
+		GasFeeCap:  call.Fees.FeeCap,
+		Gas:        call.GasLimit,
+		To:         &to,
+		Value:      valueOrZero(call.Value),
+		Data:       call.Data,
+		AccessList: call.AccessList,
+	})
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	return raw, tx.Hash(), nil
+}
+
+// MaxCost is gas limit times fee cap in native wei
+func (EVMAdapter) MaxCost(ctx context.Context, client RPC, call Call) (Cost, error) {
+	fee, err := maxFee(call)
+	if err != nil {
+		return Cost{}, err
+	}
+	return NativeCost(call, fee), nil
+}
+
+// callArgs renders the eth_estimateGas call object shared by all adapters
+func callArgs(call Call) map[string]interface{} {
+	args := map[string]interface{}{
+		"from":  call.From,
+		"to":    call.To,
+		"data":  hexutil.Bytes(call.Data),
+		"value": (*hexutil.Big)(valueOrZero(call.Value)),
+	}
+	if len(call.AccessList) > 0 {
+		args["accessList"] = call.AccessList
+	}
+	return args
+}
+
+// estimateGas runs eth_estimateGas with args and applies the safety buffer
+func estimateGas(ctx context.Context, client RPC, args map[string]interface{}) (uint64, error) {
+	var estimate hexutil.Uint64
+	if err := client.CallContext(ctx, &estimate, "eth_estimateGas", args); err != nil {
+		return 0, fmt.Errorf("gas estimation failed: %w", err)
+	}
+	return uint64(estimate) * gasBufferBps / 10000, nil
+}
+
+// Sender returns the address that signs with key
+func Sender(key *ecdsa.PrivateKey) common.Address {
+	return crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func valueOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
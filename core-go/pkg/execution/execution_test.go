@@ -0,0 +1,168 @@
+package execution
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+)
+
+// fakeRPC records the last request and answers each method with a canned result
+type fakeRPC struct {
+	method  string
+	args    []interface{}
+	results map[string]interface{}
+}
+
+func (f *fakeRPC) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	f.method, f.args = method, args
+	raw, err := json.Marshal(f.results[method])
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, result)
+}
+
+func testCall(chainID uint64) Call {
+	return Call{
+		ChainID:  chainID,
+		From:     common.HexToAddress("0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"),
+		To:       common.HexToAddress("0x000000000000000000000000000000000000dEaD"),
+		Data:     []byte{0xde, 0xad, 0xbe, 0xef},
+		Value:    big.NewInt(7),
+		Nonce:    3,
+		GasLimit: 1_500_000,
+		Fees:     gas.Fees{TipCap: gas.Gwei(0), FeeCap: big.NewInt(250_000_000)},
+	}
+}
+
+func TestEVMAdapterSignsDynamicFeeTx(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	call := testCall(1)
+	call.From = Sender(key)
+	call.Fees.TipCap = gas.Gwei(1)
+	call.Fees.FeeCap = gas.Gwei(50)
+
+	raw, hash, err := For(1).Sign(call, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		t.Fatal(err)
+	}
+	if tx.Type() != types.DynamicFeeTxType || tx.Hash() != hash {
+		t.Errorf("Expected dynamic-fee tx with hash %s, got type %d hash %s", hash, tx.Type(), tx.Hash())
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), &tx)
+	if err != nil || from != call.From {
+		t.Errorf("Expected sender %s, got %s (%v)", call.From, from, err)
+	}
+}
+
+func TestZkSyncDigestMatchesTypedData(t *testing.T) {
+	call := testCall(324)
+	z := NewZkSyncAdapter()
+
+	typed := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {{Name: "name", Type: "string"}, {Name: "version", Type: "string"}, {Name: "chainId", Type: "uint256"}},
+			"Transaction": {
+				{Name: "txType", Type: "uint256"}, {Name: "from", Type: "uint256"}, {Name: "to", Type: "uint256"},
+				{Name: "gasLimit", Type: "uint256"}, {Name: "gasPerPubdataByteLimit", Type: "uint256"},
+				{Name: "maxFeePerGas", Type: "uint256"}, {Name: "maxPriorityFeePerGas", Type: "uint256"},
+				{Name: "paymaster", Type: "uint256"}, {Name: "nonce", Type: "uint256"}, {Name: "value", Type: "uint256"},
+				{Name: "data", Type: "bytes"}, {Name: "factoryDeps", Type: "bytes32[]"}, {Name: "paymasterInput", Type: "bytes"},
+			},
+		},
+		PrimaryType: "Transaction",
+		Domain:      apitypes.TypedDataDomain{Name: "zkSync", Version: "2", ChainId: math.NewHexOrDecimal256(324)},
+		Message: apitypes.TypedDataMessage{
+			"txType":                 "113",
+			"from":                   new(big.Int).SetBytes(call.From.Bytes()).String(),
+			"to":                     new(big.Int).SetBytes(call.To.Bytes()).String(),
+			"gasLimit":               "1500000",
+			"gasPerPubdataByteLimit": "50000",
+			"maxFeePerGas":           "250000000",
+			"maxPriorityFeePerGas":   "0",
+			"paymaster":              "0",
+			"nonce":                  "3",
+			"value":                  "7",
+			"data":                   "0xdeadbeef",
+			"factoryDeps":            []interface{}{},
+			"paymasterInput":         "0x",
+		},
+	}
+	want, _, err := apitypes.TypedDataAndHash(typed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := z.Digest(call); got != common.BytesToHash(want) {
+		t.Errorf("Expected EIP-712 digest %x, got %s", want, got)
+	}
+}
+
+func TestZkSyncAdapterSerializesType71(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	call := testCall(324)
+	call.From = Sender(key)
+	z := For(324).(*ZkSyncAdapter)
+
+	raw, hash, err := z.Sign(call, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw[0] != ZkSyncTxType {
+		t.Fatalf("Expected type 0x71, got %#x", raw[0])
+	}
+	var fields []rlp.RawValue
+	if err := rlp.DecodeBytes(raw[1:], &fields); err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 16 {
+		t.Fatalf("Expected 16 fields, got %d", len(fields))
+	}
+	var custom []byte
+	if err := rlp.DecodeBytes(fields[14], &custom); err != nil || len(custom) != 65 {
+		t.Fatalf("Expected 65-byte custom signature, got %d (%v)", len(custom), err)
+	}
+
+	sig := append(append([]byte{}, custom[:64]...), custom[64]-27)
+	pub, err := crypto.SigToPub(z.Digest(call).Bytes(), sig)
+	if err != nil || crypto.PubkeyToAddress(*pub) != call.From {
+		t.Errorf("Expected signature to recover %s (%v)", call.From, err)
+	}
+
+	node := &fakeRPC{results: map[string]interface{}{"eth_sendRawTransaction": hash}}
+	if err := Broadcast(context.Background(), node, raw, hash); err != nil {
+		t.Errorf("Unexpected broadcast error: %v", err)
+	}
+	if node.method != "eth_sendRawTransaction" {
+		t.Errorf("Expected eth_sendRawTransaction, got %s", node.method)
+	}
+}
+
+func TestZkSyncEstimateSendsEIP712Meta(t *testing.T) {
+	call := testCall(324)
+	node := &fakeRPC{results: map[string]interface{}{"eth_estimateGas": hexutil.Uint64(1_000_000)}}
+	limit, err := NewZkSyncAdapter().EstimateGas(context.Background(), node, call)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit != 1_200_000 {
+		t.Errorf("Expected buffered limit 1200000, got %d", limit)
+	}
+	args := node.args[0].(map[string]interface{})
+	if args["type"] != hexutil.Uint64(ZkSyncTxType) || args["eip712Meta"] == nil {
+		t.Errorf("Expected EIP-712 estimate arguments, got %v", args)
+	}
+}
@@ -0,0 +1,84 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MantleGasPriceOracle is the L2 predeploy that prices L1 data fees
+var MantleGasPriceOracle = common.HexToAddress("0x420000000000000000000000000000000000000F")
+
+var (
+	getL1FeeSelector = crypto.Keccak256([]byte("getL1Fee(bytes)"))[:4]
+	bytesArgs        = abi.Arguments{{Type: mustType("bytes")}}
+)
+
+// MantleAdapter handles Mantle, where gas is paid in MNT and every
+// transaction also pays an L1 data fee that the gas price oracle converts
+// from ETH to MNT. Transactions themselves are standard EIP-1559.
+type MantleAdapter struct {
+	EVMAdapter
+}
+
+// Name returns the adapter name
+func (MantleAdapter) Name() string { return "mantle" }
+
+// MaxCost adds the oracle's L1 data fee, already in MNT, to the L2 execution fee
+func (m MantleAdapter) MaxCost(ctx context.Context, client RPC, call Call) (Cost, error) {
+	fee, err := maxFee(call)
+	if err != nil {
+		return Cost{}, err
+	}
+	l1Fee, err := m.L1Fee(ctx, client, call)
+	if err != nil {
+		return Cost{}, err
+	}
+	return NativeCost(call, fee.Add(fee, l1Fee)), nil
+}
+
+// L1Fee asks the gas price oracle for the data fee of call's serialized
+// transaction. The unsigned encoding is used; the oracle pads for the signature.
+func (m MantleAdapter) L1Fee(ctx context.Context, client RPC, call Call) (*big.Int, error) {
+	to := call.To
+	unsigned, err := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   new(big.Int).SetUint64(call.ChainID),
+		Nonce:     call.Nonce,
+		GasTipCap: tipOrZero(call),
+		GasFeeCap: call.Fees.FeeCap,
+		Gas:       call.GasLimit,
+		To:        &to,
+		Value:     valueOrZero(call.Value),
+		Data:      call.Data,
+	}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	packed, err := bytesArgs.Pack(unsigned)
+	if err != nil {
+		return nil, err
+	}
+	var out hexutil.Bytes
+	args := map[string]interface{}{
+		"to":   MantleGasPriceOracle,
+		"data": hexutil.Bytes(append(append([]byte{}, getL1FeeSelector...), packed...)),
+	}
+	if err := client.CallContext(ctx, &out, "eth_call", args, "latest"); err != nil {
+		return nil, fmt.Errorf("L1 fee: %w", err)
+	}
+	return new(big.Int).SetBytes(out), nil
+}
+
+func mustType(name string) abi.Type {
+	t, err := abi.NewType(name, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
@@ -0,0 +1,149 @@
+package execution
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/enum"
+)
+
+// ErrConditionalUnsupported is returned by relays that cannot honour submission conditions
+var ErrConditionalUnsupported = errors.New("execution: relay does not support conditional submission")
+
+// Conditions restrict when a sequencer may include a transaction
+// (eth_sendRawTransactionConditional on Arbitrum and OP Stack chains). The
+// sequencer drops the transaction instead of including it once they fail.
+type Conditions struct {
+	// KnownAccounts pins storage slots to the values the trade was priced against
+	KnownAccounts  map[common.Address]map[common.Hash]common.Hash `json:"knownAccounts,omitempty"`
+	BlockNumberMin *hexutil.Uint64                                `json:"blockNumberMin,omitempty"`
+	BlockNumberMax *hexutil.Uint64                                `json:"blockNumberMax,omitempty"`
+	TimestampMin   *hexutil.Uint64                                `json:"timestampMin,omitempty"`
+	TimestampMax   *hexutil.Uint64                                `json:"timestampMax,omitempty"`
+}
+
+// Relay submits signed transactions along one path: a public node, a
+// sequencer endpoint or a priority lane
+type Relay interface {
+	Name() string
+	// Submit sends raw and checks the receiver reports hash; cond may be nil
+	Submit(ctx context.Context, raw []byte, hash common.Hash, cond *Conditions) error
+}
+
+// RPCRelay submits through a JSON-RPC endpoint
+type RPCRelay struct {
+	name        string
+	client      RPC
+	conditional bool
+}
+
+// NewRPCRelay creates a relay; conditional marks endpoints that accept
+// eth_sendRawTransactionConditional
+func NewRPCRelay(name string, client RPC, conditional bool) *RPCRelay {
+	return &RPCRelay{name: name, client: client, conditional: conditional}
+}
+
+// Name returns the relay name
+func (r *RPCRelay) Name() string { return r.name }
+
+// Submit sends eth_sendRawTransaction, or its conditional form when cond is set
+func (r *RPCRelay) Submit(ctx context.Context, raw []byte, hash common.Hash, cond *Conditions) error {
+	if cond == nil {
+		return Broadcast(ctx, r.client, raw, hash)
+	}
+	if !r.conditional {
+		return ErrConditionalUnsupported
+	}
+	var got common.Hash
+	if err := r.client.CallContext(ctx, &got, "eth_sendRawTransactionConditional", hexutil.Encode(raw), cond); err != nil {
+		return err
+	}
+	if got != hash {
+		return fmt.Errorf("%w: sent %s, node reported %s", ErrHashMismatch, hash.Hex(), got.Hex())
+	}
+	return nil
+}
+
+// FallbackRelay tries each relay in order until one accepts the transaction
+type FallbackRelay struct {
+	relays []Relay
+}
+
+// NewFallbackRelay creates a relay over relays, preferred first
+func NewFallbackRelay(relays ...Relay) *FallbackRelay {
+	return &FallbackRelay{relays: relays}
+}
+
+// Name lists the relays in order
+func (f *FallbackRelay) Name() string {
+	name := ""
+	for i, r := range f.relays {
+		if i > 0 {
+			name += ">"
+		}
+		name += r.Name()
+	}
+	return name
+}
+
+// Submit returns nil on the first acceptance, else every relay's error.
+// A hash mismatch stops the chain: the transaction may already be out.
+func (f *FallbackRelay) Submit(ctx context.Context, raw []byte, hash common.Hash, cond *Conditions) error {
+	var errs []error
+	for _, r := range f.relays {
+		err := r.Submit(ctx, raw, hash, cond)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", r.Name(), err))
+		if errors.Is(err, ErrHashMismatch) || ctx.Err() != nil {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RelayOptions supply what RelayFromConfig cannot read from config
+type RelayOptions struct {
+	Public RPC                           // the chain's regular RPC node, always the last resort
+	Dial   func(url string) (RPC, error) // opens sequencer endpoints
+	// ExpressLaneKey signs Arbitrum timeboost submissions; nil skips the express lane
+	ExpressLaneKey *ecdsa.PrivateKey
+}
+
+// RelayFromConfig returns the submission path for a chain. L2s with a
+// sequencer endpoint submit there first (conditional submission supported),
+// Arbitrum tries the timeboost express lane ahead of that when an auction
+// contract and controller key are configured, and the public node is the
+// fallback everywhere.
+func RelayFromConfig(ctx context.Context, chainID uint64, chain *config.ChainConfig, opts RelayOptions) (Relay, error) {
+	public := NewRPCRelay("public", opts.Public, false)
+	if chain == nil || chain.SequencerRPC == "" || opts.Dial == nil {
+		return NewFallbackRelay(public), nil
+	}
+	seqClient, err := opts.Dial(chain.SequencerRPC)
+	if err != nil {
+		return nil, fmt.Errorf("%s sequencer: %w", chain.Name, err)
+	}
+	sequencer := NewRPCRelay("sequencer", seqClient, true)
+
+	relays := []Relay{sequencer, public}
+	if enum.ChainID(chainID) == enum.Arbitrum && chain.TimeboostAuction != "" && opts.ExpressLaneKey != nil {
+		if !common.IsHexAddress(chain.TimeboostAuction) {
+			return nil, fmt.Errorf("invalid timeboost auction address %q", chain.TimeboostAuction)
+		}
+		auction := common.HexToAddress(chain.TimeboostAuction)
+		timing, err := FetchRoundTiming(ctx, opts.Public, auction)
+		if err != nil {
+			return nil, fmt.Errorf("timeboost round timing: %w", err)
+		}
+		lane := NewExpressLane(seqClient, chainID, auction, timing, opts.ExpressLaneKey)
+		relays = append([]Relay{lane}, relays...)
+	}
+	return NewFallbackRelay(relays...), nil
+}
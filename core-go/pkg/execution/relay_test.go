@@ -0,0 +1,172 @@
+package execution
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+)
+
+// relayRPC answers submission methods, failing any listed in reject
+type relayRPC struct {
+	hash    common.Hash
+	reject  map[string]error
+	methods []string
+	args    [][]interface{}
+}
+
+func (r *relayRPC) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	r.methods = append(r.methods, method)
+	r.args = append(r.args, args)
+	if err := r.reject[method]; err != nil {
+		return err
+	}
+	if h, ok := result.(*common.Hash); ok {
+		*h = r.hash
+	}
+	return nil
+}
+
+func TestRPCRelayConditionalSubmission(t *testing.T) {
+	hash := common.HexToHash("0xabc")
+	node := &relayRPC{hash: hash}
+	max := hexutil.Uint64(100)
+	cond := &Conditions{BlockNumberMax: &max}
+
+	if err := NewRPCRelay("sequencer", node, true).Submit(context.Background(), []byte{0x01}, hash, cond); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if node.methods[0] != "eth_sendRawTransactionConditional" {
+		t.Errorf("Expected conditional submission, got %s", node.methods[0])
+	}
+	if err := NewRPCRelay("public", node, false).Submit(context.Background(), []byte{0x01}, hash, cond); !errors.Is(err, ErrConditionalUnsupported) {
+		t.Errorf("Expected ErrConditionalUnsupported, got %v", err)
+	}
+	if err := NewRPCRelay("public", node, false).Submit(context.Background(), []byte{0x01}, hash, nil); err != nil {
+		t.Errorf("Expected plain submission to succeed, got %v", err)
+	}
+}
+
+func TestFallbackRelayOrder(t *testing.T) {
+	hash := common.HexToHash("0xabc")
+	down := &relayRPC{hash: hash, reject: map[string]error{"eth_sendRawTransaction": errors.New("connection refused")}}
+	up := &relayRPC{hash: hash}
+
+	relay := NewFallbackRelay(NewRPCRelay("sequencer", down, true), NewRPCRelay("public", up, false))
+	if relay.Name() != "sequencer>public" {
+		t.Errorf("Expected sequencer>public, got %s", relay.Name())
+	}
+	if err := relay.Submit(context.Background(), []byte{0x01}, hash, nil); err != nil {
+		t.Fatalf("Expected public fallback to accept, got %v", err)
+	}
+	if len(down.methods) != 1 || len(up.methods) != 1 {
+		t.Errorf("Expected one attempt per relay, got %d and %d", len(down.methods), len(up.methods))
+	}
+
+	// A node reporting another hash may already have the transaction
+	wrong := &relayRPC{hash: common.HexToHash("0xdef")}
+	up = &relayRPC{hash: hash}
+	relay = NewFallbackRelay(NewRPCRelay("sequencer", wrong, true), NewRPCRelay("public", up, false))
+	if err := relay.Submit(context.Background(), []byte{0x01}, hash, nil); !errors.Is(err, ErrHashMismatch) {
+		t.Errorf("Expected ErrHashMismatch, got %v", err)
+	}
+	if len(up.methods) != 0 {
+		t.Errorf("Expected fallback to stop after a hash mismatch")
+	}
+}
+
+func TestExpressLaneSubmission(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	auction := common.HexToAddress("0x5fcb496a31b7AE91e7c9078Ec662bd7A55cd3079")
+	timing := RoundTiming{Offset: time.Unix(1_700_000_000, 0), Duration: time.Minute}
+	seq := &relayRPC{}
+
+	lane := NewExpressLane(seq, 42161, auction, timing, key)
+	now := timing.Offset.Add(10*time.Minute + time.Second)
+	lane.now = func() time.Time { return now }
+
+	raw := []byte{0x02, 0xf8, 0x01}
+	for i := 0; i < 2; i++ {
+		if err := lane.Submit(context.Background(), raw, common.Hash{}, nil); err != nil {
+			t.Fatalf("Submit failed: %v", err)
+		}
+	}
+	if seq.methods[0] != "timeboost_sendExpressLaneTransaction" {
+		t.Errorf("Expected timeboost_sendExpressLaneTransaction, got %s", seq.methods[0])
+	}
+
+	var sub struct {
+		ChainID        *hexutil.Big   `json:"chainId"`
+		Round          hexutil.Uint64 `json:"round"`
+		SequenceNumber hexutil.Uint64 `json:"sequenceNumber"`
+		Signature      hexutil.Bytes  `json:"signature"`
+	}
+	encoded, _ := json.Marshal(seq.args[1][0])
+	if err := json.Unmarshal(encoded, &sub); err != nil {
+		t.Fatal(err)
+	}
+	if sub.Round != 10 || sub.SequenceNumber != 1 || sub.ChainID.ToInt().Uint64() != 42161 {
+		t.Errorf("Expected round 10 seq 1 on 42161, got %s", encoded)
+	}
+
+	sig := append([]byte(nil), sub.Signature...)
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(accounts.TextHash(lane.message(10, 1, raw)), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crypto.PubkeyToAddress(*pub) != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("Expected signature from the controller key")
+	}
+
+	// A rejected submission keeps its sequence number; a new round restarts at 0
+	seq.reject = map[string]error{"timeboost_sendExpressLaneTransaction": errors.New("not express lane controller")}
+	lane.Submit(context.Background(), raw, common.Hash{}, nil)
+	seq.reject = nil
+	now = now.Add(time.Minute)
+	lane.Submit(context.Background(), raw, common.Hash{}, nil)
+	encoded, _ = json.Marshal(seq.args[3][0])
+	json.Unmarshal(encoded, &sub)
+	if sub.Round != 11 || sub.SequenceNumber != 0 {
+		t.Errorf("Expected round 11 seq 0, got %s", encoded)
+	}
+}
+
+func TestRelayFromConfig(t *testing.T) {
+	public := &relayRPC{}
+	dial := func(url string) (RPC, error) { return &relayRPC{}, nil }
+
+	relay, err := RelayFromConfig(context.Background(), 1, &config.ChainConfig{Name: "ethereum"}, RelayOptions{Public: public, Dial: dial})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if relay.Name() != "public" {
+		t.Errorf("Expected public only, got %s", relay.Name())
+	}
+
+	arbitrum := &config.ChainConfig{Name: "arbitrum", SequencerRPC: "https://arb1-sequencer.arbitrum.io/rpc"}
+	relay, err = RelayFromConfig(context.Background(), 42161, arbitrum, RelayOptions{Public: public, Dial: dial})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if relay.Name() != "sequencer>public" {
+		t.Errorf("Expected sequencer>public, got %s", relay.Name())
+	}
+}
+
+func TestRoundTiming(t *testing.T) {
+	timing := RoundTiming{Offset: time.Unix(1000, 0), Duration: time.Minute}
+	if got := timing.Round(time.Unix(999, 0)); got != 0 {
+		t.Errorf("Expected round 0 before offset, got %d", got)
+	}
+	if got := timing.Round(time.Unix(1000+150, 0)); got != 2 {
+		t.Errorf("Expected round 2, got %d", got)
+	}
+}
@@ -0,0 +1,146 @@
+package execution
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// timeboostDomain prefixes every signed timeboost message
+var timeboostDomain = crypto.Keccak256([]byte("TIMEBOOST_BID"))
+
+// roundTimingInfoSelector is keccak256("roundTimingInfo()")[:4]
+var roundTimingInfoSelector = crypto.Keccak256([]byte("roundTimingInfo()"))[:4]
+
+// RoundTiming is an express lane auction's round schedule
+type RoundTiming struct {
+	Offset   time.Time     // start of round 0
+	Duration time.Duration // length of each round
+}
+
+// Round returns the round in progress at t
+func (rt RoundTiming) Round(t time.Time) uint64 {
+	if rt.Duration <= 0 || t.Before(rt.Offset) {
+		return 0
+	}
+	return uint64(t.Sub(rt.Offset) / rt.Duration)
+}
+
+// FetchRoundTiming reads roundTimingInfo() from the auction contract
+func FetchRoundTiming(ctx context.Context, client RPC, auction common.Address) (RoundTiming, error) {
+	var out hexutil.Bytes
+	args := map[string]interface{}{"to": auction, "data": hexutil.Bytes(roundTimingInfoSelector)}
+	if err := client.CallContext(ctx, &out, "eth_call", args, "latest"); err != nil {
+		return RoundTiming{}, err
+	}
+	if len(out) < 64 {
+		return RoundTiming{}, fmt.Errorf("roundTimingInfo: short return data (%d bytes)", len(out))
+	}
+	offset := new(big.Int).SetBytes(out[:32]).Int64()
+	duration := new(big.Int).SetBytes(out[32:64]).Uint64()
+	if duration == 0 {
+		return RoundTiming{}, fmt.Errorf("roundTimingInfo: zero round duration")
+	}
+	return RoundTiming{
+		Offset:   time.Unix(offset, 0),
+		Duration: time.Duration(duration) * time.Second,
+	}, nil
+}
+
+// ExpressLane submits through Arbitrum's timeboost express lane, which
+// sequences the round controller's transactions ahead of everyone else's.
+// Only the controller for the current round is accepted; the sequencer
+// rejects anyone else, and a FallbackRelay moves on to the normal path.
+type ExpressLane struct {
+	client  RPC
+	chainID *big.Int
+	auction common.Address
+	timing  RoundTiming
+	key     *ecdsa.PrivateKey
+	now     func() time.Time
+
+	mu    sync.Mutex
+	round uint64
+	seq   uint64 // next sequence number within round
+}
+
+// NewExpressLane creates an express lane relay signing with the controller key
+func NewExpressLane(client RPC, chainID uint64, auction common.Address, timing RoundTiming, key *ecdsa.PrivateKey) *ExpressLane {
+	return &ExpressLane{
+		client:  client,
+		chainID: new(big.Int).SetUint64(chainID),
+		auction: auction,
+		timing:  timing,
+		key:     key,
+		now:     time.Now,
+	}
+}
+
+// Name returns "timeboost"
+func (e *ExpressLane) Name() string { return "timeboost" }
+
+type expressLaneSubmission struct {
+	ChainID                *hexutil.Big   `json:"chainId"`
+	Round                  hexutil.Uint64 `json:"round"`
+	AuctionContractAddress common.Address `json:"auctionContractAddress"`
+	Transaction            hexutil.Bytes  `json:"transaction"`
+	Options                *Conditions    `json:"options"`
+	SequenceNumber         hexutil.Uint64 `json:"sequenceNumber"`
+	Signature              hexutil.Bytes  `json:"signature"`
+}
+
+// Submit signs and sends timeboost_sendExpressLaneTransaction. Sequence
+// numbers restart each round and advance only on acceptance.
+func (e *ExpressLane) Submit(ctx context.Context, raw []byte, hash common.Hash, cond *Conditions) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	round := e.timing.Round(e.now())
+	if round != e.round {
+		e.round, e.seq = round, 0
+	}
+	sig, err := crypto.Sign(accounts.TextHash(e.message(round, e.seq, raw)), e.key)
+	if err != nil {
+		return err
+	}
+	sig[64] += 27
+
+	sub := expressLaneSubmission{
+		ChainID:                (*hexutil.Big)(e.chainID),
+		Round:                  hexutil.Uint64(round),
+		AuctionContractAddress: e.auction,
+		Transaction:            raw,
+		Options:                cond,
+		SequenceNumber:         hexutil.Uint64(e.seq),
+		Signature:              sig,
+	}
+	if err := e.client.CallContext(ctx, nil, "timeboost_sendExpressLaneTransaction", sub); err != nil {
+		return err
+	}
+	e.seq++
+	return nil
+}
+
+// message is the byte string the controller signs, matching the sequencer's
+// encoding: domain, chain ID (32 bytes), auction contract, round and
+// sequence number (8 bytes each), then the raw transaction
+func (e *ExpressLane) message(round, seq uint64, raw []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(timeboostDomain)
+	buf.Write(common.LeftPadBytes(e.chainID.Bytes(), 32))
+	buf.Write(e.auction.Bytes())
+	buf.Write(binary.BigEndian.AppendUint64(nil, round))
+	buf.Write(binary.BigEndian.AppendUint64(nil, seq))
+	buf.Write(raw)
+	return buf.Bytes()
+}
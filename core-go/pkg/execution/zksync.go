@@ -0,0 +1,140 @@
+package execution
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ZkSyncTxType is the EIP-2718 type of zkSync Era EIP-712 transactions
+const ZkSyncTxType = 0x71
+
+// DefaultGasPerPubdata is the pubdata gas limit zkSync SDKs sign by default
+const DefaultGasPerPubdata = 50000
+
+var (
+	zkSyncDomainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId)"))
+	zkSyncTxTypeHash     = crypto.Keccak256Hash([]byte("Transaction(uint256 txType,uint256 from,uint256 to,uint256 gasLimit," +
+		"uint256 gasPerPubdataByteLimit,uint256 maxFeePerGas,uint256 maxPriorityFeePerGas,uint256 paymaster," +
+		"uint256 nonce,uint256 value,bytes data,bytes32[] factoryDeps,bytes paymasterInput)"))
+)
+
+// ZkSyncAdapter signs zkSync Era type 0x71 transactions. The chain meters L1
+// pubdata separately from execution, so gas limits must come from the zkSync
+// node's own estimate and priority fees are ignored by the operator.
+type ZkSyncAdapter struct {
+	GasPerPubdata uint64
+}
+
+// NewZkSyncAdapter creates an adapter with the default pubdata limit
+func NewZkSyncAdapter() *ZkSyncAdapter {
+	return &ZkSyncAdapter{GasPerPubdata: DefaultGasPerPubdata}
+}
+
+// Name returns the adapter name
+func (z *ZkSyncAdapter) Name() string { return "zksync" }
+
+// EstimateGas asks the node to estimate as an EIP-712 transaction, which
+// includes the pubdata cost a plain eth_estimateGas call would miss
+func (z *ZkSyncAdapter) EstimateGas(ctx context.Context, client RPC, call Call) (uint64, error) {
+	args := callArgs(call)
+	args["type"] = hexutil.Uint64(ZkSyncTxType)
+	args["eip712Meta"] = map[string]interface{}{"gasPerPubdata": hexutil.Uint64(z.GasPerPubdata)}
+	return estimateGas(ctx, client, args)
+}
+
+// Sign signs the EIP-712 digest and serializes the transaction
+func (z *ZkSyncAdapter) Sign(call Call, key *ecdsa.PrivateKey) ([]byte, common.Hash, error) {
+	if call.Fees.FeeCap == nil {
+		return nil, common.Hash{}, errors.New("execution: fees not set")
+	}
+	digest := z.Digest(call)
+	sig, err := crypto.Sign(digest.Bytes(), key)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+
+	r, s, v := sig[:32], sig[32:64], sig[64]
+	// The custom signature field carries the 65-byte r||s||v form with v in {27, 28}
+	custom := append(append([]byte{}, sig[:64]...), v+27)
+	fields := []interface{}{
+		call.Nonce,
+		tipOrZero(call),
+		call.Fees.FeeCap,
+		call.GasLimit,
+		call.To,
+		valueOrZero(call.Value),
+		call.Data,
+		uint64(v),
+		new(big.Int).SetBytes(r),
+		new(big.Int).SetBytes(s),
+		call.ChainID,
+		call.From,
+		z.GasPerPubdata,
+		[][]byte{}, // factory deps
+		custom,
+		[]interface{}{}, // no paymaster
+	}
+	payload, err := rlp.EncodeToBytes(fields)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	raw := append([]byte{ZkSyncTxType}, payload...)
+	// zkSync identifies the transaction by its signed digest and signature
+	hash := crypto.Keccak256Hash(digest.Bytes(), crypto.Keccak256(custom))
+	return raw, hash, nil
+}
+
+// MaxCost is gas limit times fee cap in ETH; the limit already covers pubdata
+func (z *ZkSyncAdapter) MaxCost(ctx context.Context, client RPC, call Call) (Cost, error) {
+	fee, err := maxFee(call)
+	if err != nil {
+		return Cost{}, err
+	}
+	return NativeCost(call, fee), nil
+}
+
+// Digest returns the EIP-712 hash the sender signs
+func (z *ZkSyncAdapter) Digest(call Call) common.Hash {
+	domain := crypto.Keccak256Hash(
+		zkSyncDomainTypeHash.Bytes(),
+		crypto.Keccak256([]byte("zkSync")),
+		crypto.Keccak256([]byte("2")),
+		word(new(big.Int).SetUint64(call.ChainID)),
+	)
+	message := crypto.Keccak256Hash(
+		zkSyncTxTypeHash.Bytes(),
+		word(big.NewInt(ZkSyncTxType)),
+		common.LeftPadBytes(call.From.Bytes(), 32),
+		common.LeftPadBytes(call.To.Bytes(), 32),
+		word(new(big.Int).SetUint64(call.GasLimit)),
+		word(new(big.Int).SetUint64(z.GasPerPubdata)),
+		word(call.Fees.FeeCap),
+		word(tipOrZero(call)),
+		word(new(big.Int)), // paymaster
+		word(new(big.Int).SetUint64(call.Nonce)),
+		word(valueOrZero(call.Value)),
+		crypto.Keccak256(call.Data),
+		crypto.Keccak256(), // factory deps
+		crypto.Keccak256(), // paymaster input
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain.Bytes(), message.Bytes())
+}
+
+// word encodes x as a 32-byte big-endian ABI word
+func word(x *big.Int) []byte {
+	return common.LeftPadBytes(x.Bytes(), 32)
+}
+
+func tipOrZero(call Call) *big.Int {
+	if call.Fees.TipCap == nil {
+		return new(big.Int)
+	}
+	return call.Fees.TipCap
+}
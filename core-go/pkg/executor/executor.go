@@ -0,0 +1,159 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/execution"
+)
+
+// executorABI is the executor's admin surface: OpenZeppelin Ownable and
+// Pausable, and the owner's withdraw sweeping a token's balance to the owner
+const executorABI = `[
+{"inputs":[],"name":"owner","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"paused","outputs":[{"name":"","type":"bool"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"pause","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[],"name":"unpause","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"name":"newOwner","type":"address"}],"name":"transferOwnership","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"name":"token","type":"address"}],"name":"withdraw","outputs":[],"stateMutability":"nonpayable","type":"function"}
+]`
+
+// balanceOfABI reads the token balance a withdraw would sweep
+const balanceOfABI = `[{"inputs":[{"name":"account","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+var (
+	parsedExecutor  = mustParse(executorABI)
+	parsedBalanceOf = mustParse(balanceOfABI)
+)
+
+func mustParse(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// Admin actions, as recorded in the audit log
+const (
+	ActionPause             = "pause"
+	ActionUnpause           = "unpause"
+	ActionTransferOwnership = "transfer-ownership"
+	ActionRescue            = "rescue"
+)
+
+// Action is one owner call on an executor, recorded in the audit log
+// whether it was sent, declined or failed
+type Action struct {
+	Time     time.Time      `json:"time"`
+	ChainID  uint64         `json:"chainId"`
+	Executor common.Address `json:"executor"`
+	Action   string         `json:"action"`
+	Target   string         `json:"target,omitempty"` // new owner or rescued token
+	Sender   common.Address `json:"sender"`
+	TxHash   common.Hash    `json:"txHash,omitempty"`
+	Status   string         `json:"status"` // submitted, confirmed, reverted, declined or failed
+	Error    string         `json:"error,omitempty"`
+}
+
+// Action statuses
+const (
+	StatusSubmitted = "submitted"
+	StatusConfirmed = "confirmed"
+	StatusReverted  = "reverted"
+	StatusDeclined  = "declined" // the operator answered no
+	StatusFailed    = "failed"   // not sent
+)
+
+// Executor binds a deployed executor's admin functions: views read through
+// the caller, and owner calls are returned unsigned for the execution
+// adapter to sign
+type Executor struct {
+	chainID uint64
+	address common.Address
+	caller  ethereum.ContractCaller
+}
+
+// New binds the executor at address on chainID
+func New(chainID uint64, address common.Address, caller ethereum.ContractCaller) *Executor {
+	return &Executor{chainID: chainID, address: address, caller: caller}
+}
+
+// Address returns the executor's address
+func (e *Executor) Address() common.Address {
+	return e.address
+}
+
+// Owner reads the executor's owner, the only sender its execute and admin
+// functions accept
+func (e *Executor) Owner(ctx context.Context) (common.Address, error) {
+	var owner common.Address
+	err := e.call(ctx, parsedExecutor, e.address, &owner, "owner")
+	return owner, err
+}
+
+// Paused reads whether execution is paused
+func (e *Executor) Paused(ctx context.Context) (bool, error) {
+	var paused bool
+	err := e.call(ctx, parsedExecutor, e.address, &paused, "paused")
+	return paused, err
+}
+
+// Balance reads the executor's balance of token, what a rescue sweeps
+func (e *Executor) Balance(ctx context.Context, token common.Address) (*big.Int, error) {
+	balance := new(big.Int)
+	err := e.call(ctx, parsedBalanceOf, token, &balance, "balanceOf", e.address)
+	return balance, err
+}
+
+// Pause returns from's call halting execution
+func (e *Executor) Pause(from common.Address) (execution.Call, error) {
+	return e.transact(from, "pause")
+}
+
+// Unpause returns from's call resuming execution
+func (e *Executor) Unpause(from common.Address) (execution.Call, error) {
+	return e.transact(from, "unpause")
+}
+
+// TransferOwnership returns from's call handing the executor to owner
+func (e *Executor) TransferOwnership(from, owner common.Address) (execution.Call, error) {
+	return e.transact(from, "transferOwnership", owner)
+}
+
+// Rescue returns from's call sweeping the executor's token balance to its
+// owner
+func (e *Executor) Rescue(from, token common.Address) (execution.Call, error) {
+	return e.transact(from, "withdraw", token)
+}
+
+// transact packs method into an unsigned call from from
+func (e *Executor) transact(from common.Address, method string, args ...interface{}) (execution.Call, error) {
+	data, err := parsedExecutor.Pack(method, args...)
+	if err != nil {
+		return execution.Call{}, err
+	}
+	return execution.Call{ChainID: e.chainID, From: from, To: e.address, Data: data}, nil
+}
+
+// call reads method on contract into out
+func (e *Executor) call(ctx context.Context, parsed abi.ABI, contract common.Address, out interface{}, method string, args ...interface{}) error {
+	data, err := parsed.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+	ret, err := e.caller.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("executor %s %s: %w", e.address.Hex(), method, err)
+	}
+	if err := parsed.UnpackIntoInterface(out, method, ret); err != nil {
+		return fmt.Errorf("executor %s %s: %w", e.address.Hex(), method, err)
+	}
+	return nil
+}
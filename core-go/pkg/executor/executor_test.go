@@ -0,0 +1,67 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+)
+
+func TestExecutorReadsStateAndBuildsOwnerCalls(t *testing.T) {
+	var (
+		contract = common.HexToAddress("0xe0")
+		owner    = common.HexToAddress("0x0a")
+		usdc     = common.HexToAddress("0x1001")
+	)
+	backend := titantest.NewBackend(137)
+	backend.Handle(contract, "owner()", func(msg ethereum.CallMsg) ([]byte, error) {
+		return common.LeftPadBytes(owner.Bytes(), 32), nil
+	})
+	backend.Handle(contract, "paused()", func(msg ethereum.CallMsg) ([]byte, error) {
+		return common.LeftPadBytes([]byte{1}, 32), nil
+	})
+	backend.Handle(usdc, "balanceOf(address)", func(msg ethereum.CallMsg) ([]byte, error) {
+		if common.BytesToAddress(msg.Data[4:]) != contract {
+			return common.LeftPadBytes(nil, 32), nil
+		}
+		return common.LeftPadBytes(big.NewInt(2_500_000).Bytes(), 32), nil
+	})
+	e := New(137, contract, backend)
+	ctx := context.Background()
+
+	if got, err := e.Owner(ctx); err != nil || got != owner {
+		t.Errorf("Expected owner %s, got %s: %v", owner.Hex(), got.Hex(), err)
+	}
+	if paused, err := e.Paused(ctx); err != nil || !paused {
+		t.Errorf("Expected the executor paused, got %v: %v", paused, err)
+	}
+	if balance, err := e.Balance(ctx, usdc); err != nil || balance.Int64() != 2_500_000 {
+		t.Errorf("Expected 2.5 USDC held, got %v: %v", balance, err)
+	}
+
+	next := common.HexToAddress("0x0b")
+	call, err := e.TransferOwnership(owner, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := parsedExecutor.Methods["transferOwnership"]
+	if call.ChainID != 137 || call.From != owner || call.To != contract || !bytes.Equal(call.Data[:4], method.ID) {
+		t.Fatalf("Expected transferOwnership from the owner to the executor, got %+v", call)
+	}
+	if args, err := method.Inputs.Unpack(call.Data[4:]); err != nil || args[0].(common.Address) != next {
+		t.Errorf("Expected ownership handed to %s, got %v: %v", next.Hex(), args, err)
+	}
+	rescue, err := e.Rescue(owner, usdc)
+	if err != nil || !bytes.Equal(rescue.Data[:4], parsedExecutor.Methods["withdraw"].ID) {
+		t.Errorf("Expected a withdraw of the token, got %x: %v", rescue.Data, err)
+	}
+	pause, _ := e.Pause(owner)
+	unpause, _ := e.Unpause(owner)
+	if !bytes.Equal(pause.Data, parsedExecutor.Methods["pause"].ID) || !bytes.Equal(unpause.Data, parsedExecutor.Methods["unpause"].ID) {
+		t.Errorf("Expected bare pause and unpause calls, got %x and %x", pause.Data, unpause.Data)
+	}
+}
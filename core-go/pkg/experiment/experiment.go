@@ -0,0 +1,229 @@
+package experiment
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/models"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+)
+
+// Arm names
+const (
+	ArmControl   = "control"
+	ArmCandidate = "candidate"
+)
+
+// Model scores an opportunity; higher is better
+type Model interface {
+	Score(o *opportunity.Opportunity) float64
+}
+
+// ModelFunc adapts a function to Model
+type ModelFunc func(o *opportunity.Opportunity) float64
+
+// Score calls f
+func (f ModelFunc) Score(o *opportunity.Opportunity) float64 { return f(o) }
+
+// Models are the scoring models an experiment can name
+var Models = map[string]Model{
+	// return is the strategy's own score, e.g. a backrun's return on its size
+	"return": ModelFunc(func(o *opportunity.Opportunity) float64 { return o.Explanation.Score }),
+	// net-profit ranks by dollars kept after gas, fees and one-off costs
+	"net-profit": ModelFunc(func(o *opportunity.Opportunity) float64 { return o.Explanation.NetProfitUSD.Float() }),
+	// regime-adjusted discounts the return by the volatility regime, as
+	// quotes are more likely to move before inclusion in turbulent markets
+	"regime-adjusted": ModelFunc(func(o *opportunity.Opportunity) float64 {
+		for _, c := range o.Explanation.Components {
+			if c.Name == "volatility_regime" {
+				return o.Explanation.Score / (1 + c.Value)
+			}
+		}
+		return o.Explanation.Score
+	}),
+}
+
+// Features are the inputs a registry model scores an opportunity on: its
+// score, profit and costs in dollars, gas, hop count and each score
+// component by name
+func Features(o *opportunity.Opportunity) map[string]float64 {
+	x := o.Explanation
+	f := map[string]float64{
+		"score":            x.Score,
+		"net_profit_usd":   x.NetProfitUSD.Float(),
+		"gross_profit_usd": x.GrossProfitUSD.Float(),
+		"gas_usd":          x.GasUSD.Float(),
+		"fees_usd":         x.FeesUSD.Float(),
+		"gas_units":        float64(x.GasUnits),
+		"hops":             float64(len(x.Legs)),
+	}
+	for _, c := range x.Components {
+		f[c.Name] = c.Value
+	}
+	return f
+}
+
+// Arm is one side of an experiment: a model and the score it accepts from
+type Arm struct {
+	Name     string
+	Model    string
+	MinScore float64
+}
+
+// Experiment scores every opportunity under a control and a candidate model,
+// each either built in or served by the model registry, whose version at the
+// time is recorded with the score. In shadow mode the control decides and the candidate's verdict is only
+// recorded; in split mode a stable share of opportunities, by ID, is decided
+// by the candidate. Either way both verdicts are kept on the opportunity's
+// explanation, so reports can compare the models' hit rates and PnL on
+// production traffic. Without a candidate the control alone decides, which
+// lets a registry model gate trades. A nil experiment accepts everything and
+// records nothing.
+type Experiment struct {
+	arms     []Arm // control, then the candidate if any
+	models   []Model
+	registry *models.Registry
+	mode     string
+	splitBps uint64
+
+	// Signals adds features from outside the opportunity, such as news
+	// sentiment, to those registry models score on; it may be nil
+	Signals SignalSource
+
+	decisions *metrics.CounterVec
+}
+
+// SignalSource supplies features from outside an opportunity
+type SignalSource func(o *opportunity.Opportunity) map[string]float64
+
+// MergeSignals combines sources into one, skipping nil ones; a later
+// source's feature replaces an earlier one of the same name
+func MergeSignals(sources ...SignalSource) SignalSource {
+	var live []SignalSource
+	for _, s := range sources {
+		if s != nil {
+			live = append(live, s)
+		}
+	}
+	if len(live) == 0 {
+		return nil
+	}
+	return func(o *opportunity.Opportunity) map[string]float64 {
+		out := make(map[string]float64)
+		for _, s := range live {
+			for name, v := range s(o) {
+				out[name] = v
+			}
+		}
+		return out
+	}
+}
+
+// New creates an experiment between control and candidate, whose model may
+// be empty to run the control alone; registry serves models that aren't built
+// in and may be nil, as may reg
+func New(control, candidate Arm, mode string, splitBps uint64, registry *models.Registry, reg *metrics.Registry) (*Experiment, error) {
+	e := &Experiment{mode: mode, splitBps: splitBps, registry: registry}
+	control.Name, candidate.Name = ArmControl, ArmCandidate
+	arms := []Arm{control}
+	if candidate.Model != "" {
+		arms = append(arms, candidate)
+	}
+	for _, arm := range arms {
+		model, ok := Models[arm.Model]
+		if !ok && !registry.Serves(arm.Model) {
+			return nil, fmt.Errorf("unknown scoring model %q", arm.Model)
+		}
+		e.arms, e.models = append(e.arms, arm), append(e.models, model)
+	}
+	if mode != config.ExperimentShadow && mode != config.ExperimentSplit {
+		return nil, fmt.Errorf("unknown experiment mode %q", mode)
+	}
+	if reg != nil {
+		e.decisions = reg.Counter("titan_experiment_decisions_total", "Opportunities scored by each experiment arm, by whether it would accept them", "arm", "model", "decision")
+	}
+	return e, nil
+}
+
+// FromConfig creates the configured experiment, or nil without a candidate
+// unless the control is a registry model
+func FromConfig(cfg *config.ExperimentConfig, registry *models.Registry, reg *metrics.Registry) (*Experiment, error) {
+	if cfg == nil || (cfg.Candidate == "" && !registry.Serves(cfg.Control)) {
+		return nil, nil
+	}
+	return New(
+		Arm{Model: cfg.Control, MinScore: cfg.ControlMinScore},
+		Arm{Model: cfg.Candidate, MinScore: cfg.CandidateMinScore},
+		cfg.Mode, cfg.SplitBps, registry, reg,
+	)
+}
+
+// Decide scores o under every arm, records the trial on its explanation and
+// reports whether the deciding arm accepts it; a registry model that hasn't
+// loaded rejects
+func (e *Experiment) Decide(o *opportunity.Opportunity) bool {
+	if e == nil || o.Explanation == nil {
+		return true
+	}
+	trial := &opportunity.Trial{Decider: ArmControl}
+	if e.mode == config.ExperimentSplit && len(e.arms) > 1 && bucket(o.ID) < e.splitBps {
+		trial.Decider = ArmCandidate
+	}
+	accept := true
+	var features map[string]float64
+	for i, arm := range e.arms {
+		ta := opportunity.TrialArm{Arm: arm.Name, Model: arm.Model}
+		if model := e.models[i]; model != nil {
+			ta.Score = model.Score(o)
+			ta.Accept = ta.Score >= arm.MinScore
+		} else if loaded, ok := e.registry.Get(arm.Model); ok {
+			if features == nil {
+				features = e.Inputs(o)
+			}
+			ta.Score, ta.Version = loaded.Score(features), loaded.Version
+			ta.Accept = ta.Score >= arm.MinScore
+		}
+		trial.Arms = append(trial.Arms, ta)
+		if arm.Name == trial.Decider {
+			accept = ta.Accept
+		}
+		if e.decisions != nil {
+			decision := opportunity.DecisionReject
+			if ta.Accept {
+				decision = opportunity.DecisionExecute
+			}
+			e.decisions.Inc(arm.Name, arm.Model, decision)
+		}
+	}
+	o.Explanation.Trial = trial
+	return accept
+}
+
+// Arms returns the control and candidate arms
+func (e *Experiment) Arms() []Arm {
+	if e == nil {
+		return nil
+	}
+	return append([]Arm(nil), e.arms...)
+}
+
+// Inputs returns the features registry models score o on: its own merged
+// with the signals about it
+func (e *Experiment) Inputs(o *opportunity.Opportunity) map[string]float64 {
+	f := Features(o)
+	if e != nil && e.Signals != nil {
+		for name, v := range e.Signals(o) {
+			f[name] = v
+		}
+	}
+	return f
+}
+
+// bucket maps id to a stable point in [0, 10000)
+func bucket(id string) uint64 {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return uint64(h.Sum32() % 10000)
+}
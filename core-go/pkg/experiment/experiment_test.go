@@ -0,0 +1,132 @@
+package experiment
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/models"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+func candidate(id string, score float64, netUSD uint64) *opportunity.Opportunity {
+	return &opportunity.Opportunity{ID: id, Explanation: &opportunity.Explanation{Score: score, NetProfitUSD: units.DollarsToUSD(netUSD)}}
+}
+
+func TestShadowKeepsControlDecision(t *testing.T) {
+	reg := metrics.NewRegistry()
+	e, err := New(Arm{Model: "return", MinScore: 0.01}, Arm{Model: "net-profit", MinScore: 50}, config.ExperimentShadow, 5000, nil, reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := candidate("a", 0.02, 20)
+	if !e.Decide(o) {
+		t.Errorf("Expected the control's acceptance used in shadow mode")
+	}
+	trial := o.Explanation.Trial
+	if trial == nil || trial.Decider != ArmControl || len(trial.Arms) != 2 {
+		t.Fatalf("Expected both arms recorded, got %+v", trial)
+	}
+	if trial.Arms[1].Score != 20 || trial.Arms[1].Accept {
+		t.Errorf("Expected the candidate to score $20 and reject, got %+v", trial.Arms[1])
+	}
+	if got := reg.Value("titan_experiment_decisions_total", ArmCandidate, "net-profit", opportunity.DecisionReject); got != 1 {
+		t.Errorf("Expected the candidate's rejection counted, got %v", got)
+	}
+}
+
+func TestSplitIsStable(t *testing.T) {
+	e, err := New(Arm{Model: "return"}, Arm{Model: "net-profit", MinScore: 50}, config.ExperimentSplit, 5000, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deciders := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		o := candidate(fmt.Sprintf("opp-%d", i), 0.02, 20)
+		accept := e.Decide(o)
+		deciders[o.Explanation.Trial.Decider]++
+		if accept != (o.Explanation.Trial.Decider == ArmControl) {
+			t.Fatalf("Expected only the control to accept a $20 trade, got %v from %s", accept, o.Explanation.Trial.Decider)
+		}
+		again := candidate(o.ID, 0.02, 20)
+		e.Decide(again)
+		if again.Explanation.Trial.Decider != o.Explanation.Trial.Decider {
+			t.Fatalf("Expected %s to land in the same arm every time", o.ID)
+		}
+	}
+	if deciders[ArmControl] < 60 || deciders[ArmCandidate] < 60 {
+		t.Errorf("Expected a roughly even split, got %v", deciders)
+	}
+}
+
+func TestRegimeAdjusted(t *testing.T) {
+	o := candidate("a", 0.03, 0)
+	o.Explanation.Components = []opportunity.ScoreComponent{{Name: "volatility_regime", Value: 2}}
+	if got := Models["regime-adjusted"].Score(o); got != 0.01 {
+		t.Errorf("Expected the return cut to a third in a high regime, got %v", got)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	if e, err := FromConfig(&config.ExperimentConfig{Control: "return", Mode: config.ExperimentShadow}, nil, nil); e != nil || err != nil {
+		t.Errorf("Expected no experiment without a candidate, got %v, %v", e, err)
+	}
+	if !(*Experiment)(nil).Decide(candidate("a", 0, 0)) {
+		t.Errorf("Expected a nil experiment to accept")
+	}
+	if _, err := FromConfig(&config.ExperimentConfig{Control: "return", Candidate: "vibes", Mode: config.ExperimentShadow}, nil, nil); err == nil {
+		t.Errorf("Expected an unknown model rejected")
+	}
+}
+
+func TestRegistryModelRecordsVersion(t *testing.T) {
+	dir := t.TempDir()
+	for path, body := range map[string]string{
+		"fill/CURRENT":          "v7",
+		"fill/v7/manifest.json": `{"name": "fill", "version": "v7", "format": "linear", "artifact": "model.json"}`,
+		"fill/v7/model.json":    `{"bias": -10, "weights": {"net_profit_usd": 1}}`,
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	registry := models.New(dir, []string{"fill"}, nil)
+	e, err := FromConfig(&config.ExperimentConfig{Control: "fill", Mode: config.ExperimentSplit, SplitBps: 10000}, registry, nil)
+	if err != nil || e == nil {
+		t.Fatalf("Expected a control-only experiment on a registry model, got %v, %v", e, err)
+	}
+	if e.Decide(candidate("a", 0, 20)) {
+		t.Errorf("Expected a registry model that hasn't loaded to reject")
+	}
+	if err := registry.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	o := candidate("a", 0, 20)
+	if !e.Decide(o) {
+		t.Errorf("Expected $20 net to score 10 and accept")
+	}
+	if arms := o.Explanation.Trial.Arms; len(arms) != 1 || arms[0].Version != "v7" || arms[0].Score != 10 {
+		t.Errorf("Expected the control scored by fill@v7, got %+v", arms)
+	}
+}
+
+func TestMergeSignals(t *testing.T) {
+	if MergeSignals(nil, nil) != nil {
+		t.Errorf("Expected no source from nil ones")
+	}
+	news := func(o *opportunity.Opportunity) map[string]float64 { return map[string]float64{"a": 1, "b": 1} }
+	whales := func(o *opportunity.Opportunity) map[string]float64 { return map[string]float64{"b": 2} }
+	f := MergeSignals(news, nil, whales)(&opportunity.Opportunity{})
+	if len(f) != 2 || f["a"] != 1 || f["b"] != 2 {
+		t.Errorf("Expected features merged with the later source winning, got %v", f)
+	}
+}
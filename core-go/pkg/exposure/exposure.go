@@ -0,0 +1,433 @@
+package exposure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/bridge"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/multicall"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/pipeline"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+const balancesABI = `[
+	{"inputs":[{"name":"account","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"addr","type":"address"}],"name":"getEthBalance","outputs":[{"name":"balance","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`
+
+var parsedABI = mustParse(balancesABI)
+
+// ErrUnpriced is returned valuing a token no price source covers
+var ErrUnpriced = errors.New("exposure: no price")
+
+// Valuer prices raw token amounts in USD; the zero address is the chain's
+// native token
+type Valuer interface {
+	USD(chainID uint64, token common.Address, raw *big.Int) (units.USD, error)
+}
+
+// Dialer returns a contract caller for a chain
+type Dialer func(chainID uint64) (ethereum.ContractCaller, error)
+
+// Holding is one wallet's balance of one token
+type Holding struct {
+	ChainID uint64         `json:"chainId"`
+	Wallet  common.Address `json:"wallet"`
+	Symbol  string         `json:"symbol"`
+	Token   common.Address `json:"token"` // zero for the native token
+	Amount  string         `json:"amount"`
+	USD     units.USD      `json:"usd"`
+	Priced  bool           `json:"priced"`
+
+	raw      *big.Int
+	decimals uint8
+}
+
+// Position is the inventory of one token on a chain across every wallet
+type Position struct {
+	ChainID uint64         `json:"chainId"`
+	Symbol  string         `json:"symbol"`
+	Token   common.Address `json:"token"`
+	Amount  string         `json:"amount"`
+	USD     units.USD      `json:"usd"`
+	Priced  bool           `json:"priced"`
+}
+
+// Transfer is bridged notional that has left its source chain and not yet landed
+type Transfer struct {
+	Plan      string    `json:"plan"`
+	Bridge    string    `json:"bridge"`
+	FromChain uint64    `json:"fromChain"`
+	ToChain   uint64    `json:"toChain"`
+	Symbol    string    `json:"symbol"`
+	Amount    string    `json:"amount"`
+	USD       units.USD `json:"usd"`
+	Since     time.Time `json:"since"`
+}
+
+// Pending is an opportunity whose transaction is submitted but not final
+type Pending struct {
+	ID          string         `json:"id"`
+	ChainID     uint64         `json:"chainId"`
+	Stage       pipeline.Stage `json:"stage"`
+	Tx          common.Hash    `json:"tx"`
+	NotionalUSD units.USD      `json:"notionalUsd"`
+	GasUSD      units.USD      `json:"gasUsd"` // spent even if the transaction reverts
+	Since       time.Time      `json:"since"`
+}
+
+// ChainTotal sums one chain's exposure; bridging counts against the source chain
+type ChainTotal struct {
+	ChainID      uint64    `json:"chainId"`
+	InventoryUSD units.USD `json:"inventoryUsd"`
+	BridgingUSD  units.USD `json:"bridgingUsd"`
+	PendingUSD   units.USD `json:"pendingUsd"`
+}
+
+// Snapshot is the current exposure. TotalUSD is what the wallets own,
+// inventory plus transfers in flight; pending notional is already counted in
+// inventory or borrowed, so it is reported beside the total rather than in it.
+type Snapshot struct {
+	Time         time.Time         `json:"time"`
+	BalancesAt   time.Time         `json:"balancesAt"` // when wallet balances were last read
+	TotalUSD     units.USD         `json:"totalUsd"`
+	InventoryUSD units.USD         `json:"inventoryUsd"`
+	BridgingUSD  units.USD         `json:"bridgingUsd"`
+	PendingUSD   units.USD         `json:"pendingUsd"`
+	Chains       []ChainTotal      `json:"chains"`
+	Inventory    []Position        `json:"inventory"`
+	Wallets      []Holding         `json:"wallets"`
+	Bridging     []Transfer        `json:"bridging"`
+	Pending      []Pending         `json:"pending"`
+	Errors       map[uint64]string `json:"errors,omitempty"` // chains whose balances couldn't be read
+}
+
+// Book tracks wallet balances per chain and combines them with the bridge
+// and pipeline trackers into a USD exposure snapshot. Balances are read on
+// Refresh so serving a snapshot never waits on RPC. It is safe for
+// concurrent use.
+type Book struct {
+	// Value prices holdings, transfers and pending notional; nil leaves them unpriced
+	Value Valuer
+	// Bridges supplies in-flight transfers; nil reports none
+	Bridges *bridge.Tracker
+	// Lifecycle and Store supply submitted opportunities; nil reports none
+	Lifecycle *pipeline.Machine
+	Store     *opportunity.Store
+
+	wallets map[uint64][]common.Address
+	dial    Dialer
+	tokens  func(chainID uint64) []tokens.Token
+
+	mu       sync.Mutex
+	holdings map[uint64][]Holding
+	errs     map[uint64]string
+	readAt   time.Time
+	now      func() time.Time
+
+	exposure *metrics.GaugeVec
+}
+
+// New creates a book reading each chain's wallets' balance of every listed
+// token, the zero address being the native token; reg may be nil
+func New(wallets map[uint64][]common.Address, dial Dialer, list func(chainID uint64) []tokens.Token, reg *metrics.Registry) *Book {
+	b := &Book{
+		wallets:  wallets,
+		dial:     dial,
+		tokens:   list,
+		holdings: make(map[uint64][]Holding),
+		errs:     make(map[uint64]string),
+		now:      time.Now,
+	}
+	if reg != nil {
+		b.exposure = reg.Gauge("titan_exposure_usd", "USD exposure by chain: inventory, bridging or pending", "chain", "kind")
+	}
+	return b
+}
+
+// WalletsFromConfig watches the configured wallets and strategy instances'
+// wallets on every chain, plus each chain's executor from executors when set
+func WalletsFromConfig(cfg *config.Config, chains []uint64, executors map[uint64]string) map[uint64][]common.Address {
+	configured := append([]string(nil), cfg.Exposure.Wallets...)
+	for _, s := range cfg.Strategies {
+		configured = append(configured, s.Wallets...)
+	}
+	out := make(map[uint64][]common.Address, len(chains))
+	for _, id := range chains {
+		var wallets []common.Address
+		seen := make(map[common.Address]bool)
+		add := func(w string) {
+			if a := common.HexToAddress(w); !seen[a] {
+				seen[a] = true
+				wallets = append(wallets, a)
+			}
+		}
+		for _, w := range configured {
+			add(w)
+		}
+		if e := executors[id]; common.IsHexAddress(e) {
+			add(e)
+		}
+		if len(wallets) > 0 {
+			out[id] = wallets
+		}
+	}
+	return out
+}
+
+// Run refreshes balances every interval until ctx is done
+func (b *Book) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := b.Refresh(ctx); err != nil {
+			log.Printf("⚠️ Exposure: %v", err)
+		}
+		b.Snapshot()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh reads every wallet's balances. A chain that fails keeps its last
+// balances and is reported in the snapshot's errors.
+func (b *Book) Refresh(ctx context.Context) error {
+	var errs []error
+	for chainID, wallets := range b.wallets {
+		holdings, err := b.read(ctx, chainID, wallets)
+		b.mu.Lock()
+		if err != nil {
+			b.errs[chainID] = err.Error()
+			errs = append(errs, fmt.Errorf("chain %d: %w", chainID, err))
+		} else {
+			delete(b.errs, chainID)
+			b.holdings[chainID] = holdings
+		}
+		b.mu.Unlock()
+	}
+	b.mu.Lock()
+	b.readAt = b.now()
+	b.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// read fetches chainID's native and token balances in one multicall
+func (b *Book) read(ctx context.Context, chainID uint64, wallets []common.Address) ([]Holding, error) {
+	caller, err := b.dial(chainID)
+	if err != nil {
+		return nil, err
+	}
+	list := b.tokens(chainID)
+	if len(list) == 0 {
+		return nil, nil
+	}
+	var calls []multicall.Call
+	for _, wallet := range wallets {
+		for _, t := range list {
+			call := multicall.Call{Target: t.Address, AllowFailure: true}
+			if t.Address == (common.Address{}) {
+				call.Target = common.HexToAddress(multicall.Multicall3Address)
+				call.CallData, err = parsedABI.Pack("getEthBalance", wallet)
+			} else {
+				call.CallData, err = parsedABI.Pack("balanceOf", wallet)
+			}
+			if err != nil {
+				return nil, err
+			}
+			calls = append(calls, call)
+		}
+	}
+	results, err := multicall.New(caller, 0).Aggregate(ctx, calls, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Holding
+	for i, res := range results {
+		wallet, t := wallets[i/len(list)], list[i%len(list)]
+		if !res.Success || len(res.ReturnData) < 32 {
+			continue
+		}
+		raw := new(big.Int).SetBytes(res.ReturnData[:32])
+		if raw.Sign() == 0 {
+			continue
+		}
+		amount, err := units.FromBig(t.Address, raw, t.Decimals)
+		if err != nil {
+			return nil, fmt.Errorf("%s balance of %s: %w", t.Symbol, wallet.Hex(), err)
+		}
+		out = append(out, Holding{ChainID: chainID, Wallet: wallet, Symbol: t.Symbol, Token: t.Address, Amount: amount.String(), raw: raw, decimals: t.Decimals})
+	}
+	return out, nil
+}
+
+// lookup returns a stored opportunity
+func (b *Book) lookup(id string) (*opportunity.Opportunity, bool) {
+	if b.Store == nil {
+		return nil, false
+	}
+	return b.Store.Get(id)
+}
+
+// value prices raw, reporting whether a price was found
+func (b *Book) value(chainID uint64, token common.Address, raw *big.Int) (units.USD, bool) {
+	if b.Value == nil || raw == nil {
+		return 0, false
+	}
+	usd, err := b.Value.USD(chainID, token, raw)
+	if err != nil {
+		return 0, false
+	}
+	return usd, true
+}
+
+// Snapshot prices the last read balances and the live bridge and pipeline
+// state, and refreshes the exposure gauge
+func (b *Book) Snapshot() Snapshot {
+	b.mu.Lock()
+	s := Snapshot{Time: b.now(), BalancesAt: b.readAt, Errors: make(map[uint64]string, len(b.errs))}
+	var holdings []Holding
+	for _, hs := range b.holdings {
+		holdings = append(holdings, hs...)
+	}
+	for id, err := range b.errs {
+		s.Errors[id] = err
+	}
+	b.mu.Unlock()
+
+	chains := make(map[uint64]*ChainTotal)
+	total := func(chainID uint64) *ChainTotal {
+		c := chains[chainID]
+		if c == nil {
+			c = &ChainTotal{ChainID: chainID}
+			chains[chainID] = c
+		}
+		return c
+	}
+	for id := range b.wallets {
+		total(id)
+	}
+
+	type positionKey struct {
+		chainID uint64
+		token   common.Address
+	}
+	positions := make(map[positionKey]*Position)
+	raws := make(map[positionKey]*big.Int)
+	decimals := make(map[positionKey]uint8)
+	for _, h := range holdings {
+		h.USD, h.Priced = b.value(h.ChainID, h.Token, h.raw)
+		s.Wallets = append(s.Wallets, h)
+		s.InventoryUSD += h.USD
+		total(h.ChainID).InventoryUSD += h.USD
+
+		k := positionKey{h.ChainID, h.Token}
+		if positions[k] == nil {
+			positions[k] = &Position{ChainID: h.ChainID, Symbol: h.Symbol, Token: h.Token, Priced: true}
+			raws[k] = new(big.Int)
+			decimals[k] = h.decimals
+		}
+		p := positions[k]
+		raws[k].Add(raws[k], h.raw)
+		p.USD += h.USD
+		p.Priced = p.Priced && h.Priced
+	}
+	for k, p := range positions {
+		if amount, err := units.FromBig(k.token, raws[k], decimals[k]); err == nil {
+			p.Amount = amount.String()
+		}
+		s.Inventory = append(s.Inventory, *p)
+	}
+
+	if b.Bridges != nil {
+		for _, p := range b.Bridges.Plans() {
+			if !p.InFlight() {
+				continue
+			}
+			req := p.Transfer
+			t := Transfer{Plan: p.ID, Bridge: p.Bridge, FromChain: req.FromChain, ToChain: req.ToChain, Symbol: req.FromToken.Symbol, Since: p.Opened}
+			if req.Amount.Value != nil {
+				t.Amount = req.Amount.String()
+				t.USD, _ = b.value(req.FromChain, req.FromToken.Address, req.Amount.Big())
+			}
+			s.Bridging = append(s.Bridging, t)
+			s.BridgingUSD += t.USD
+			total(req.FromChain).BridgingUSD += t.USD
+		}
+	}
+
+	if b.Lifecycle != nil {
+		for _, r := range b.Lifecycle.InFlight() {
+			if r.Tx == nil {
+				continue
+			}
+			p := Pending{ID: r.ID, ChainID: r.ChainID, Stage: r.Stage, Tx: r.Tx.Hash, Since: r.Since}
+			if o, ok := b.lookup(r.ID); ok {
+				p.NotionalUSD, _ = b.value(o.ChainID, o.TokenIn, o.AmountIn)
+				if o.Explanation != nil {
+					p.GasUSD = o.Explanation.GasUSD
+				}
+			}
+			s.Pending = append(s.Pending, p)
+			s.PendingUSD += p.NotionalUSD
+			total(r.ChainID).PendingUSD += p.NotionalUSD
+		}
+	}
+	s.TotalUSD = s.InventoryUSD + s.BridgingUSD
+
+	for _, c := range chains {
+		s.Chains = append(s.Chains, *c)
+		if b.exposure != nil {
+			chain := strconv.FormatUint(c.ChainID, 10)
+			b.exposure.Set(c.InventoryUSD.Float(), chain, "inventory")
+			b.exposure.Set(c.BridgingUSD.Float(), chain, "bridging")
+			b.exposure.Set(c.PendingUSD.Float(), chain, "pending")
+		}
+	}
+	sort.Slice(s.Chains, func(i, j int) bool { return s.Chains[i].ChainID < s.Chains[j].ChainID })
+	sort.Slice(s.Inventory, func(i, j int) bool {
+		if s.Inventory[i].USD != s.Inventory[j].USD {
+			return s.Inventory[i].USD > s.Inventory[j].USD
+		}
+		if s.Inventory[i].ChainID != s.Inventory[j].ChainID {
+			return s.Inventory[i].ChainID < s.Inventory[j].ChainID
+		}
+		return strings.ToLower(s.Inventory[i].Symbol) < strings.ToLower(s.Inventory[j].Symbol)
+	})
+	sort.Slice(s.Wallets, func(i, j int) bool {
+		if s.Wallets[i].ChainID != s.Wallets[j].ChainID {
+			return s.Wallets[i].ChainID < s.Wallets[j].ChainID
+		}
+		if s.Wallets[i].Wallet != s.Wallets[j].Wallet {
+			return s.Wallets[i].Wallet.Hex() < s.Wallets[j].Wallet.Hex()
+		}
+		return s.Wallets[i].USD > s.Wallets[j].USD
+	})
+	return s
+}
+
+func mustParse(def string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(def))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
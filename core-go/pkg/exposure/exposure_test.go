@@ -0,0 +1,187 @@
+package exposure
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/bridge"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/multicall"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/pipeline"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/prices"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+type fixture struct {
+	chain             *titantest.Chain
+	usdc, weth, token common.Address
+	registry          *tokens.Registry
+	prices            *Prices
+}
+
+// newFixture prices ETH at $2000 and TOKEN at 0.01 ETH through a cached pool
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{chain: titantest.NewChain(1), registry: tokens.NewRegistry()}
+	f.chain.Backend.EnableMulticall()
+	f.usdc, f.weth, f.token = f.chain.Token(6), f.chain.Token(18), f.chain.Token(18)
+	f.registry.Add(tokens.Token{ChainID: 1, Symbol: "USDC", Address: f.usdc, Decimals: 6})
+	f.registry.Add(tokens.Token{ChainID: 1, Symbol: "WETH", Address: f.weth, Decimals: 18})
+	f.registry.Add(tokens.Token{ChainID: 1, Symbol: "TOKEN", Address: f.token, Decimals: 18})
+	if err := f.registry.SetWrappedNative(1, "ETH", f.weth); err != nil {
+		t.Fatal(err)
+	}
+
+	native := prices.NewTracker(time.Hour, nil)
+	native.Set(prices.Quote{ChainID: 1, PriceE8: 2000e8, UpdatedAt: time.Now()})
+	pools := reserves.NewCache()
+	pools.Put(&reserves.Snapshot{ChainID: 1, Pool: titantest.Address(9), Token0: f.token, Token1: f.weth,
+		Reserve0: titantest.Units(1_000_000, 18), Reserve1: titantest.Units(10_000, 18), FetchedAt: time.Now()})
+	f.prices = &Prices{Native: native, Registry: f.registry, Pools: pools, MaxAge: time.Hour}
+	return f
+}
+
+// fundNative answers Multicall3 getEthBalance from balances
+func (f *fixture) fundNative(balances map[common.Address]*big.Int) {
+	f.chain.Backend.Handle(common.HexToAddress(multicall.Multicall3Address), "getEthBalance(address)",
+		func(msg ethereum.CallMsg) ([]byte, error) {
+			balance := balances[common.BytesToAddress(msg.Data[16:36])]
+			if balance == nil {
+				balance = new(big.Int)
+			}
+			return titantest.EncodeUint(balance), nil
+		})
+}
+
+func (f *fixture) book(wallets map[uint64][]common.Address, reg *metrics.Registry) *Book {
+	list := func(chainID uint64) []tokens.Token {
+		if chainID != 1 {
+			return nil
+		}
+		return append([]tokens.Token{{ChainID: 1, Symbol: "ETH", Decimals: 18}}, f.registry.Chain(1)...)
+	}
+	dial := func(chainID uint64) (ethereum.ContractCaller, error) {
+		if chainID != 1 {
+			return nil, errors.New("no RPC")
+		}
+		return f.chain.Backend, nil
+	}
+	b := New(wallets, dial, list, reg)
+	b.Value = f.prices
+	return b
+}
+
+func TestSnapshotPricesInventory(t *testing.T) {
+	f := newFixture(t)
+	hot, treasury := titantest.Address(0xa1), titantest.Address(0xa2)
+	f.chain.Fund(f.usdc, hot, titantest.Units(2000, 6)).Fund(f.usdc, treasury, titantest.Units(500, 6))
+	f.chain.Fund(f.token, treasury, titantest.Units(100, 18))
+	f.fundNative(map[common.Address]*big.Int{hot: titantest.Units(1, 18)})
+
+	reg := metrics.NewRegistry()
+	b := f.book(map[uint64][]common.Address{1: {hot, treasury}, 10: {hot}}, reg)
+	if err := b.Refresh(context.Background()); err == nil {
+		t.Errorf("Expected the chain without RPC reported")
+	}
+	s := b.Snapshot()
+
+	// $2500 USDC + 1 ETH at $2000 + 100 TOKEN at 0.01 ETH
+	if s.InventoryUSD != units.DollarsToUSD(6500) || s.TotalUSD != s.InventoryUSD {
+		t.Errorf("Expected $6500 of inventory, got %s (total %s)", s.InventoryUSD, s.TotalUSD)
+	}
+	if len(s.Wallets) != 4 {
+		t.Errorf("Expected 4 non-zero holdings, got %+v", s.Wallets)
+	}
+	if len(s.Inventory) != 3 || s.Inventory[0].Symbol != "USDC" || s.Inventory[0].Amount != "2500" {
+		t.Errorf("Expected 2500 USDC across wallets first, got %+v", s.Inventory)
+	}
+	if s.Errors[10] == "" {
+		t.Errorf("Expected chain 10's read error, got %+v", s.Errors)
+	}
+	if len(s.Chains) != 2 || s.Chains[0].InventoryUSD != s.InventoryUSD {
+		t.Errorf("Expected inventory on chain 1 and an empty chain 10, got %+v", s.Chains)
+	}
+	if got := reg.Value("titan_exposure_usd", "1", "inventory"); got != 6500 {
+		t.Errorf("Expected the inventory gauge at 6500, got %v", got)
+	}
+}
+
+func TestSnapshotIncludesBridgingAndPending(t *testing.T) {
+	f := newFixture(t)
+	b := f.book(nil, nil)
+
+	bridges := bridge.NewTracker(bridge.PolicyConfirmFirst, nil)
+	usdc, _ := f.registry.ByAddress(1, f.usdc)
+	amount, err := units.FromWhole(f.usdc, 750, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := bridge.Plan{ID: "p1", Bridge: "across", Transfer: bridge.Request{FromChain: 1, ToChain: 10, FromToken: usdc, Amount: amount}}
+	plan.Source.ChainID, plan.Destination.ChainID = 1, 10
+	if err := bridges.Open(plan); err != nil {
+		t.Fatal(err)
+	}
+	if err := bridges.Submitted("p1", bridge.Source, common.HexToHash("0x01")); err != nil {
+		t.Fatal(err)
+	}
+	if err := bridges.Filled("p1", bridge.Source); err != nil {
+		t.Fatal(err)
+	}
+	b.Bridges = bridges
+
+	lifecycle, err := pipeline.Open(filepath.Join(t.TempDir(), "pipeline.jsonl"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lifecycle.Close()
+	store := opportunity.NewStore(10)
+	store.Add(&opportunity.Opportunity{ID: "o1", ChainID: 1, TokenIn: f.weth, AmountIn: titantest.Units(2, 18),
+		Explanation: &opportunity.Explanation{GasUSD: units.DollarsToUSD(4)}})
+	if err := lifecycle.Start("o1", 1); err != nil {
+		t.Fatal(err)
+	}
+	for _, stage := range []pipeline.Stage{pipeline.StageScored, pipeline.StageSized, pipeline.StageSimulated} {
+		if err := lifecycle.Advance("o1", stage, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lifecycle.Submit("o1", pipeline.TxRef{Hash: common.HexToHash("0x02")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := lifecycle.Start("o2", 1); err != nil {
+		t.Fatal(err)
+	}
+	b.Lifecycle, b.Store = lifecycle, store
+
+	s := b.Snapshot()
+	if len(s.Bridging) != 1 || s.BridgingUSD != units.DollarsToUSD(750) || s.TotalUSD != units.DollarsToUSD(750) {
+		t.Errorf("Expected $750 in flight to chain 10, got %+v", s.Bridging)
+	}
+	if len(s.Pending) != 1 || s.Pending[0].NotionalUSD != units.DollarsToUSD(4000) || s.Pending[0].GasUSD != units.DollarsToUSD(4) {
+		t.Errorf("Expected only the submitted 2 ETH trade pending, got %+v", s.Pending)
+	}
+	if len(s.Chains) != 1 || s.Chains[0].BridgingUSD != s.BridgingUSD || s.Chains[0].PendingUSD != s.PendingUSD {
+		t.Errorf("Expected bridging and pending charged to chain 1, got %+v", s.Chains)
+	}
+}
+
+func TestPricesRejectsUnknownTokens(t *testing.T) {
+	f := newFixture(t)
+	if _, err := f.prices.USD(1, titantest.Address(0xdead), big.NewInt(1)); !errors.Is(err, ErrUnpriced) {
+		t.Errorf("Expected an unknown token unpriced, got %v", err)
+	}
+	f.registry.Add(tokens.Token{ChainID: 1, Symbol: "ILLIQ", Address: titantest.Address(0xbeef), Decimals: 18})
+	if _, err := f.prices.USD(1, titantest.Address(0xbeef), big.NewInt(1)); !errors.Is(err, ErrUnpriced) {
+		t.Errorf("Expected a token without a pool unpriced, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package exposure
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/prices"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Prices values tokens from the pricing layer: the native token and its
+// wrapper at the tracked native price, stablecoins at par and other tokens
+// at the spot ratio of their deepest cached pool against the wrapped native
+type Prices struct {
+	Native   *prices.Tracker
+	Registry *tokens.Registry
+	Pools    *reserves.Cache
+	MaxAge   time.Duration // pool snapshots older than this are ignored
+}
+
+// USD values raw units of token on chainID
+func (p *Prices) USD(chainID uint64, token common.Address, raw *big.Int) (units.USD, error) {
+	wrapped, hasWrapped := p.Registry.WrappedNative(chainID)
+	if token == (common.Address{}) || (hasWrapped && token == wrapped.Address) {
+		return p.Native.NativeToUSD(chainID, raw)
+	}
+	t, ok := p.Registry.ByAddress(chainID, token)
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown token %s on chain %d", ErrUnpriced, token.Hex(), chainID)
+	}
+	if t.Stable() {
+		amount, err := units.FromBig(token, raw, t.Decimals)
+		if err != nil {
+			return 0, err
+		}
+		return amount.ToUSD(1e8)
+	}
+	if !hasWrapped || p.Pools == nil {
+		return 0, fmt.Errorf("%w: no pool to price %s on chain %d", ErrUnpriced, t.Symbol, chainID)
+	}
+	for _, s := range p.Pools.Pair(chainID, token, wrapped.Address, p.MaxAge) {
+		tokenReserve, nativeReserve := s.Reserve0, s.Reserve1
+		if s.Token0 != token {
+			tokenReserve, nativeReserve = s.Reserve1, s.Reserve0
+		}
+		if tokenReserve.Sign() == 0 {
+			continue
+		}
+		wei := new(big.Int).Mul(raw, nativeReserve)
+		return p.Native.NativeToUSD(chainID, wei.Quo(wei, tokenReserve))
+	}
+	return 0, fmt.Errorf("%w: no cached %s/%s pool on chain %d", ErrUnpriced, t.Symbol, wrapped.Symbol, chainID)
+}
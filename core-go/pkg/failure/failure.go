@@ -0,0 +1,114 @@
+package failure
+
+import (
+	"strings"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// Reason is the canonical category of a rejected or failed opportunity,
+// shared by every pipeline stage, metric and report
+type Reason string
+
+// Failure reasons
+const (
+	StaleQuote            Reason = "STALE_QUOTE"            // reserves or quote moved before execution
+	Frontrun              Reason = "FRONTRUN"               // another transaction took the opportunity first
+	InsufficientLiquidity Reason = "INSUFFICIENT_LIQUIDITY" // pool or lender could not fill the size
+	GasSpike              Reason = "GAS_SPIKE"              // fees rose past what the profit covers
+	RPCError              Reason = "RPC_ERROR"              // node or provider failure
+	GuardrailFloor        Reason = "GUARDRAIL_FLOOR"        // a sizing or profit guardrail blocked it
+	ScoringReject         Reason = "SCORING_REJECT"         // scored below the execution threshold
+	SimulationRevert      Reason = "SIMULATION_REVERT"      // simulation failed before submission
+	Reverted              Reason = "REVERTED"               // mined but reverted, cause unknown
+	Dropped               Reason = "DROPPED"                // left the mempool or its nonce was reused
+	Interrupted           Reason = "INTERRUPTED"            // the process stopped mid-lifecycle
+	Duplicate             Reason = "DUPLICATE"              // an equivalent plan was already submitted
+	Unknown               Reason = "UNKNOWN"
+)
+
+// Reasons lists every reason, for exhaustive reports
+var Reasons = []Reason{
+	StaleQuote, Frontrun, InsufficientLiquidity, GasSpike, RPCError, GuardrailFloor,
+	ScoringReject, SimulationRevert, Reverted, Dropped, Interrupted, Duplicate, Unknown,
+}
+
+// Valid reports whether r is one of Reasons
+func (r Reason) Valid() bool {
+	for _, have := range Reasons {
+		if have == r {
+			return true
+		}
+	}
+	return false
+}
+
+// classifiers map free-text reasons to categories; the first match wins, so
+// specific phrases come before generic ones
+var classifiers = []struct {
+	reason Reason
+	words  []string
+}{
+	{Duplicate, []string{"duplicate"}},
+	{Interrupted, []string{"interrupted"}},
+	{Frontrun, []string{"frontrun", "front-run", "sandwich", "already taken"}},
+	{StaleQuote, []string{"stale", "price moved", "insufficient_output_amount", "too little received", "k invariant"}},
+	{InsufficientLiquidity, []string{"insufficient liquidity", "insufficient_liquidity", "tvl", "not enough liquidity"}},
+	{GasSpike, []string{"gas spike", "underpriced", "fee cap", "max fee", "gas price", "gas too"}},
+	{RPCError, []string{"rpc", "timeout", "timed out", "connection", "rate limit", "429", "eof"}},
+	{GuardrailFloor, []string{"guardrail", "below minimum", "floor", "min_profit", "exceeds max"}},
+	{ScoringReject, []string{"score"}},
+	{SimulationRevert, []string{"simulation", "simulated"}},
+	{Dropped, []string{"dropped", "nonce", "replaced"}},
+	{Reverted, []string{"revert"}},
+}
+
+// Classify maps a free-text reason, such as one journaled before categories
+// existed, to its category
+func Classify(text string) Reason {
+	if r := Reason(strings.ToUpper(strings.TrimSpace(text))); r.Valid() {
+		return r
+	}
+	lower := strings.ToLower(text)
+	for _, c := range classifiers {
+		for _, w := range c.words {
+			if strings.Contains(lower, w) {
+				return c.reason
+			}
+		}
+	}
+	return Unknown
+}
+
+// Of returns r when it is set, otherwise the category of text
+func Of(r Reason, text string) Reason {
+	if r != "" {
+		return r
+	}
+	return Classify(text)
+}
+
+// Counter exports failures as titan_failures_total by stage and reason
+type Counter struct {
+	total *metrics.CounterVec
+}
+
+// NewCounter registers the failure counter; every caller shares one family.
+// A nil reg returns a nil counter, which counts nothing.
+func NewCounter(reg *metrics.Registry) *Counter {
+	if reg == nil {
+		return nil
+	}
+	return &Counter{total: reg.Counter("titan_failures_total", "Rejected and failed opportunities by stage and reason", "stage", "reason")}
+}
+
+// Inc counts one failure at stage; a nil counter does nothing
+func (c *Counter) Inc(stage string, r Reason) {
+	if c == nil {
+		return
+	}
+	if r == "" {
+		r = Unknown
+	}
+	c.total.Inc(stage, string(r))
+}
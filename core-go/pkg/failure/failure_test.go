@@ -0,0 +1,44 @@
+package failure
+
+import (
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+func TestClassify(t *testing.T) {
+	cases := map[string]Reason{
+		"net profit below minimum":                                         GuardrailFloor,
+		"reverted in block 123":                                            Reverted,
+		"execution reverted: UniswapV2: INSUFFICIENT_OUTPUT_AMOUNT":        StaleQuote,
+		"dropped from mempool with nonce 4 unused":                         Dropped,
+		"interrupted in sized before submission":                           Interrupted,
+		"Post \"https://rpc\": context deadline exceeded (Client.Timeout)": RPCError,
+		"GAS_SPIKE":      GasSpike,
+		"something else": Unknown,
+	}
+	for text, want := range cases {
+		if got := Classify(text); got != want {
+			t.Errorf("Classify(%q): Expected %s, got %s", text, want, got)
+		}
+	}
+	if got := Of(Frontrun, "reverted"); got != Frontrun {
+		t.Errorf("Expected an explicit reason to win, got %s", got)
+	}
+}
+
+func TestCounter(t *testing.T) {
+	reg := metrics.NewRegistry()
+	c := NewCounter(reg)
+	c.Inc("submitted", Reverted)
+	NewCounter(reg).Inc("submitted", Reverted)
+	c.Inc("scored", "")
+	if got := reg.Value("titan_failures_total", "submitted", string(Reverted)); got != 2 {
+		t.Errorf("Expected 2 reverted failures, got %v", got)
+	}
+	if got := reg.Value("titan_failures_total", "scored", string(Unknown)); got != 1 {
+		t.Errorf("Expected 1 unknown failure, got %v", got)
+	}
+	var none *Counter
+	none.Inc("scored", Unknown)
+}
@@ -0,0 +1,75 @@
+package freshness
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// ErrStale is returned for a quote outside its chain's freshness window
+var ErrStale = errors.New("freshness: quote is stale")
+
+// Window bounds how old a quote may be; a zero field leaves that bound off
+type Window struct {
+	MaxAge    time.Duration
+	MaxBlocks uint64
+}
+
+// Policy enforces each chain's freshness window wherever a quote is about to
+// be acted on, so one stale quote can't slip through on a fast chain. A nil
+// policy accepts every quote.
+type Policy struct {
+	windows map[uint64]Window
+	now     func() time.Time
+	stale   *metrics.CounterVec
+}
+
+// New creates a policy from per-chain windows; reg may be nil
+func New(windows map[uint64]Window, reg *metrics.Registry) *Policy {
+	p := &Policy{windows: windows, now: time.Now}
+	if reg != nil {
+		p.stale = reg.Counter("titan_stale_quotes_total", "Quotes rejected as stale by chain and stage", "chain", "stage")
+	}
+	return p
+}
+
+// FromConfig creates a policy from each chain's quote freshness settings
+func FromConfig(cfg *config.Config, reg *metrics.Registry) *Policy {
+	windows := make(map[uint64]Window, len(cfg.Chains))
+	for id, c := range cfg.Chains {
+		windows[id] = Window{MaxAge: time.Duration(c.QuoteMaxAgeMs) * time.Millisecond, MaxBlocks: c.QuoteMaxBlocks}
+	}
+	return New(windows, reg)
+}
+
+// Window returns chainID's window; chains without one are unbounded
+func (p *Policy) Window(chainID uint64) Window {
+	if p == nil {
+		return Window{}
+	}
+	return p.windows[chainID]
+}
+
+// Check returns ErrStale when a quote taken at quotedAt against quotedBlock
+// is outside chainID's window at head. A zero quotedBlock or head skips the
+// block bound. stage names where the quote was caught, for the metric.
+func (p *Policy) Check(stage string, chainID uint64, quotedAt time.Time, quotedBlock, head uint64) error {
+	if p == nil {
+		return nil
+	}
+	w := p.windows[chainID]
+	var err error
+	if age := p.now().Sub(quotedAt); w.MaxAge > 0 && age > w.MaxAge {
+		err = fmt.Errorf("%w: %s old (max %s on chain %d)", ErrStale, age.Round(time.Millisecond), w.MaxAge, chainID)
+	} else if w.MaxBlocks > 0 && quotedBlock > 0 && head > quotedBlock && head-quotedBlock > w.MaxBlocks {
+		err = fmt.Errorf("%w: %d blocks behind head (max %d on chain %d)", ErrStale, head-quotedBlock, w.MaxBlocks, chainID)
+	}
+	if err != nil && p.stale != nil {
+		p.stale.Inc(strconv.FormatUint(chainID, 10), stage)
+	}
+	return err
+}
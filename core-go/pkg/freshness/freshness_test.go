@@ -0,0 +1,64 @@
+package freshness
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+func TestCheck(t *testing.T) {
+	reg := metrics.NewRegistry()
+	p := New(map[uint64]Window{42161: {MaxAge: time.Second, MaxBlocks: 4}}, reg)
+	now := time.Unix(1_700_000_000, 0)
+	p.now = func() time.Time { return now }
+
+	if err := p.Check("quote", 42161, now.Add(-500*time.Millisecond), 100, 104); err != nil {
+		t.Errorf("Expected a fresh quote accepted, got %v", err)
+	}
+	if err := p.Check("quote", 42161, now.Add(-2*time.Second), 100, 100); !errors.Is(err, ErrStale) {
+		t.Errorf("Expected an old quote rejected, got %v", err)
+	}
+	if err := p.Check("submit", 42161, now, 100, 105); !errors.Is(err, ErrStale) {
+		t.Errorf("Expected a quote 5 blocks behind rejected, got %v", err)
+	}
+	if err := p.Check("submit", 42161, now, 0, 105); err != nil {
+		t.Errorf("Expected an unknown quote block to skip the block bound, got %v", err)
+	}
+	if err := p.Check("quote", 1, now.Add(-time.Hour), 1, 1_000); err != nil {
+		t.Errorf("Expected a chain without a window unbounded, got %v", err)
+	}
+	if got := reg.Value("titan_stale_quotes_total", "42161", "submit"); got != 1 {
+		t.Errorf("Expected 1 stale quote at submit, got %v", got)
+	}
+}
+
+func TestStaleClassifies(t *testing.T) {
+	p := New(map[uint64]Window{1: {MaxBlocks: 1}}, nil)
+	err := p.Check("submit", 1, time.Now(), 10, 12)
+	if got := failure.Classify(err.Error()); got != failure.StaleQuote {
+		t.Errorf("Expected %s, got %s", failure.StaleQuote, got)
+	}
+}
+
+func TestNilPolicy(t *testing.T) {
+	var p *Policy
+	if err := p.Check("quote", 1, time.Time{}, 1, 1_000); err != nil {
+		t.Errorf("Expected a nil policy to accept every quote, got %v", err)
+	}
+	if w := p.Window(1); w != (Window{}) {
+		t.Errorf("Expected an unbounded window, got %+v", w)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	cfg := &config.Config{Chains: map[uint64]*config.ChainConfig{
+		137: {QuoteMaxAgeMs: 4000, QuoteMaxBlocks: 2},
+	}}
+	if w := FromConfig(cfg, nil).Window(137); w.MaxAge != 4*time.Second || w.MaxBlocks != 2 {
+		t.Errorf("Expected 4s and 2 blocks, got %+v", w)
+	}
+}
@@ -0,0 +1,174 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+var (
+	// ErrNoEstimate is returned when no source could estimate a chain's fees
+	ErrNoEstimate = errors.New("gas: no fee estimate")
+	// ErrFeeCeiling is returned when the base fee alone exceeds the policy's ceiling
+	ErrFeeCeiling = errors.New("gas: base fee above ceiling")
+)
+
+// Fees is an EIP-1559 fee suggestion in wei
+type Fees struct {
+	BaseFee *big.Int `json:"baseFee"`
+	TipCap  *big.Int `json:"tipCap"`
+	FeeCap  *big.Int `json:"feeCap"` // nil from sources that only report base fee and tip
+	Source  string   `json:"source"`
+}
+
+// Source estimates current fees for a chain
+type Source interface {
+	Name() string
+	Estimate(ctx context.Context, chainID uint64) (Fees, error)
+}
+
+// Policy tunes raw estimates for a chain's fee market
+type Policy struct {
+	MinTip               *big.Int // priority fee floor the network enforces; nil for none
+	MaxFeeCap            *big.Int // never bid above this; nil for uncapped
+	BaseFeeMultiplierBps uint64   // base fee headroom so the bid survives increases
+	TipMultiplierBps     uint64   // bump over the suggested tip
+}
+
+// DefaultPolicy applies to chains without a tuned policy: the usual 2x base fee headroom
+var DefaultPolicy = Policy{BaseFeeMultiplierBps: 20000, TipMultiplierBps: 10000}
+
+// DefaultPolicies returns the chain-tuned fee policies
+func DefaultPolicies() map[uint64]Policy {
+	return map[uint64]Policy{
+		// Ethereum: standard headroom, refuse to bid into extreme congestion
+		1: {MaxFeeCap: Gwei(500), BaseFeeMultiplierBps: 20000, TipMultiplierBps: 10000},
+		// Polygon: validators reject tips under 30 gwei and the base fee spikes
+		// several-fold within a few blocks, so bid with extra headroom
+		137: {MinTip: Gwei(30), MaxFeeCap: Gwei(5000), BaseFeeMultiplierBps: 30000, TipMultiplierBps: 12500},
+		// Rollups: sequencer base fees move slowly and tips buy little
+		42161: {BaseFeeMultiplierBps: 12500, TipMultiplierBps: 10000},
+		10:    {BaseFeeMultiplierBps: 12500, TipMultiplierBps: 10000},
+		8453:  {BaseFeeMultiplierBps: 12500, TipMultiplierBps: 10000},
+		5000:  {BaseFeeMultiplierBps: 12500, TipMultiplierBps: 10000},
+		// zkSync Era: the operator ignores priority fees; the base fee tracks L1 gas
+		324: {BaseFeeMultiplierBps: 15000, TipMultiplierBps: 0},
+	}
+}
+
+// Gwei returns n gwei in wei
+func Gwei(n uint64) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(n), big.NewInt(1e9))
+}
+
+// Apply turns a raw estimate into the fees to bid. The fee cap covers the
+// base fee with headroom plus the tip, or the source's own cap if higher,
+// and is clamped to MaxFeeCap.
+func (p Policy) Apply(f Fees) (Fees, error) {
+	if f.TipCap == nil {
+		return Fees{}, fmt.Errorf("%s: missing tip", f.Source)
+	}
+	baseFee := f.BaseFee
+	if baseFee == nil {
+		baseFee = new(big.Int)
+	}
+
+	tip := units.MulBps(f.TipCap, p.TipMultiplierBps)
+	if p.MinTip != nil && tip.Cmp(p.MinTip) < 0 {
+		tip = new(big.Int).Set(p.MinTip)
+	}
+	feeCap := units.MulBps(baseFee, p.BaseFeeMultiplierBps)
+	feeCap.Add(feeCap, tip)
+	if f.FeeCap != nil && f.FeeCap.Cmp(feeCap) > 0 {
+		feeCap = new(big.Int).Set(f.FeeCap)
+	}
+
+	if p.MaxFeeCap != nil && feeCap.Cmp(p.MaxFeeCap) > 0 {
+		if baseFee.Cmp(p.MaxFeeCap) >= 0 {
+			return Fees{}, fmt.Errorf("%w: base fee %s wei, ceiling %s wei", ErrFeeCeiling, baseFee, p.MaxFeeCap)
+		}
+		feeCap = new(big.Int).Set(p.MaxFeeCap)
+		if tip.Cmp(feeCap) > 0 {
+			tip = new(big.Int).Set(feeCap)
+		}
+	}
+	return Fees{BaseFee: new(big.Int).Set(baseFee), TipCap: tip, FeeCap: feeCap, Source: f.Source}, nil
+}
+
+// Oracle suggests fees per chain from the first source that answers,
+// tuned by the chain's policy
+type Oracle struct {
+	sources []Source
+
+	mu       sync.RWMutex
+	policies map[uint64]Policy
+
+	tip    *metrics.GaugeVec
+	feeCap *metrics.GaugeVec
+}
+
+// NewOracle creates an oracle with the default chain policies
+func NewOracle(reg *metrics.Registry, sources ...Source) *Oracle {
+	o := &Oracle{sources: sources, policies: DefaultPolicies()}
+	if reg != nil {
+		o.tip = reg.Gauge("titan_gas_tip_gwei", "Suggested priority fee", "chain")
+		o.feeCap = reg.Gauge("titan_gas_fee_cap_gwei", "Suggested max fee", "chain")
+	}
+	return o
+}
+
+// SetPolicy replaces a chain's fee policy
+func (o *Oracle) SetPolicy(chainID uint64, p Policy) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.policies[chainID] = p
+}
+
+// Policy returns the policy applied to a chain
+func (o *Oracle) Policy(chainID uint64) Policy {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if p, ok := o.policies[chainID]; ok {
+		return p
+	}
+	return DefaultPolicy
+}
+
+// Suggest returns the fees to bid on a chain
+func (o *Oracle) Suggest(ctx context.Context, chainID uint64) (Fees, error) {
+	var errs []error
+	for _, src := range o.sources {
+		raw, err := src.Estimate(ctx, chainID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", src.Name(), err))
+			continue
+		}
+		raw.Source = src.Name()
+		fees, err := o.Policy(chainID).Apply(raw)
+		if err != nil {
+			return Fees{}, err
+		}
+		if o.tip != nil {
+			chain := strconv.FormatUint(chainID, 10)
+			o.tip.Set(toGwei(fees.TipCap), chain)
+			o.feeCap.Set(toGwei(fees.FeeCap), chain)
+		}
+		return fees, nil
+	}
+	if len(errs) == 0 {
+		return Fees{}, ErrNoEstimate
+	}
+	return Fees{}, errors.Join(errs...)
+}
+
+// toGwei converts wei to gwei for display
+func toGwei(wei *big.Int) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e9)).Float64()
+	return f
+}
@@ -0,0 +1,197 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// fixedSource returns the same estimate for every chain, or err
+type fixedSource struct {
+	name string
+	fees Fees
+	err  error
+}
+
+func (f *fixedSource) Name() string { return f.name }
+
+func (f *fixedSource) Estimate(ctx context.Context, chainID uint64) (Fees, error) {
+	return f.fees, f.err
+}
+
+func TestPolygonPolicyEnforcesMinimumTip(t *testing.T) {
+	p := DefaultPolicies()[137]
+	fees, err := p.Apply(Fees{BaseFee: Gwei(100), TipCap: Gwei(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fees.TipCap.Cmp(Gwei(30)) != 0 {
+		t.Errorf("Expected 30 gwei tip floor, got %s", fees.TipCap)
+	}
+	// 3x base fee headroom plus the tip
+	if fees.FeeCap.Cmp(Gwei(330)) != 0 {
+		t.Errorf("Expected 330 gwei fee cap, got %s", fees.FeeCap)
+	}
+}
+
+func TestPolicyCeiling(t *testing.T) {
+	p := Policy{MaxFeeCap: Gwei(100), BaseFeeMultiplierBps: 20000, TipMultiplierBps: 10000}
+
+	fees, err := p.Apply(Fees{BaseFee: Gwei(60), TipCap: Gwei(5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fees.FeeCap.Cmp(Gwei(100)) != 0 {
+		t.Errorf("Expected fee cap clamped to 100 gwei, got %s", fees.FeeCap)
+	}
+
+	if _, err := p.Apply(Fees{BaseFee: Gwei(150), TipCap: Gwei(5)}); !errors.Is(err, ErrFeeCeiling) {
+		t.Errorf("Expected ErrFeeCeiling during a spike, got %v", err)
+	}
+}
+
+func TestGasStationSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"safeLow":{"maxPriorityFee":30,"maxFee":60.5},"standard":{"maxPriorityFee":35.5,"maxFee":90},"fast":{"maxPriorityFee":50,"maxFee":120},"estimatedBaseFee":40.25,"blockTime":2,"blockNumber":1}`))
+	}))
+	defer srv.Close()
+
+	src := NewGasStationSource(map[uint64]string{137: srv.URL})
+	fees, err := src.Estimate(context.Background(), 137)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fees.TipCap.Cmp(big.NewInt(35_500_000_000)) != 0 || fees.BaseFee.Cmp(big.NewInt(40_250_000_000)) != 0 {
+		t.Errorf("Unexpected standard tier %+v", fees)
+	}
+	if _, err := src.Estimate(context.Background(), 1); err == nil {
+		t.Error("Expected an error for a chain without a gas station")
+	}
+}
+
+func TestOracleFallsBackAcrossSources(t *testing.T) {
+	reg := metrics.NewRegistry()
+	o := NewOracle(reg,
+		&fixedSource{name: "gasstation", err: errors.New("timeout")},
+		&fixedSource{name: "node", fees: Fees{BaseFee: Gwei(50), TipCap: Gwei(40)}},
+	)
+	fees, err := o.Suggest(context.Background(), 137)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fees.Source != "node" || fees.TipCap.Cmp(Gwei(50)) != 0 {
+		t.Errorf("Expected node estimate with 1.25x tip, got %s from %s", fees.TipCap, fees.Source)
+	}
+	if got := reg.Value("titan_gas_tip_gwei", "137"); got != 50 {
+		t.Errorf("Expected tip gauge 50, got %v", got)
+	}
+
+	empty := NewOracle(nil)
+	if _, err := empty.Suggest(context.Background(), 1); !errors.Is(err, ErrNoEstimate) {
+		t.Errorf("Expected ErrNoEstimate, got %v", err)
+	}
+}
+
+func TestModelAveragesObservedHops(t *testing.T) {
+	m := NewModel(nil)
+	if got := m.Estimate(137, "univ2"); got != DefaultSwapGas["univ2"] {
+		t.Errorf("Expected default before observations, got %d", got)
+	}
+	m.Observe(137, "univ2", 100_000)
+	m.Observe(137, "univ2", 150_000)
+	if got := m.Estimate(137, "univ2"); got != 110_000 {
+		t.Errorf("Expected 110000, got %d", got)
+	}
+	if m.Samples(137, "univ2") != 2 || m.Samples(1, "univ2") != 0 {
+		t.Errorf("Expected samples counted per chain")
+	}
+	var none *Model
+	if got := none.Estimate(1, "balancer"); got != fallbackSwapGas {
+		t.Errorf("Expected fallback for unknown adapter, got %d", got)
+	}
+}
+
+func TestHistoryFitsRouteShapes(t *testing.T) {
+	h := NewHistory()
+	h.MinSamples = 3
+	v2 := func(hops int) Shape {
+		s := Shape{ChainID: 137}
+		for i := 0; i < hops; i++ {
+			s.Adapters = append(s.Adapters, "univ2")
+		}
+		return s
+	}
+	if est := h.Estimate(v2(2)); est.Source != SourceDefault || est.Gas != txOverheadGas+2*DefaultSwapGas["univ2"] || est.Low >= est.Gas {
+		t.Errorf("Expected a default estimate with an interval, got %+v", est)
+	}
+
+	// gas = 50000 + 90000 per univ2 hop + 130000 per univ3 hop, ±1000
+	mixed := Shape{ChainID: 137, Adapters: []string{"univ2", "univ3"}}
+	for i, jitter := range []uint64{0, 1000, 0, 1000} {
+		h.Observe(v2(2), 230_000+jitter)
+		h.Observe(v2(3), 320_000+jitter)
+		if i < 2 {
+			h.Observe(Shape{ChainID: 137, Adapters: []string{"univ3"}}, 180_000+jitter)
+		}
+	}
+	est := h.Estimate(mixed)
+	if est.Source != SourceFit || est.Gas < 265_000 || est.Gas > 275_000 || est.Low > est.Gas || est.High < est.Gas {
+		t.Errorf("Expected a fitted estimate near 270000, got %+v", est)
+	}
+
+	est = h.Estimate(v2(2))
+	if est.Source != SourceShape || est.Gas != 230_500 || est.Samples != 4 {
+		t.Errorf("Expected the 2-hop shape mean 230500 over 4 samples, got %+v", est)
+	}
+	if est.Low > 230_000 || est.High < 231_000 {
+		t.Errorf("Expected the interval to cover the observations, got %+v", est)
+	}
+}
+
+// historyNode serves a fixed fee history, or refuses it
+type historyNode struct {
+	chain.Client
+	rewards [][]*big.Int
+	err     error
+}
+
+func (h *historyNode) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return &ethereum.FeeHistory{Reward: h.rewards}, h.err
+}
+
+func (h *historyNode) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(1), BaseFee: Gwei(20)}, nil
+}
+
+func (h *historyNode) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return Gwei(7), nil
+}
+
+func TestNodeSourceTipsFromFeeHistory(t *testing.T) {
+	node := &historyNode{rewards: [][]*big.Int{{Gwei(1)}, {Gwei(3)}, {Gwei(2)}}}
+	src := NewNodeSource(func(uint64) (chain.Client, error) { return node, nil })
+	fees, err := src.Estimate(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fees.TipCap.Cmp(Gwei(7)) != 0 {
+		t.Errorf("Expected the node's suggestion without a capability check, got %s", fees.TipCap)
+	}
+
+	src.FeeHistory = func(uint64) bool { return true }
+	if fees, _ = src.Estimate(context.Background(), 1); fees.TipCap.Cmp(Gwei(2)) != 0 {
+		t.Errorf("Expected the median fee history tip, got %s", fees.TipCap)
+	}
+	node.err = errors.New("method not found")
+	if fees, _ = src.Estimate(context.Background(), 1); fees.TipCap.Cmp(Gwei(7)) != 0 {
+		t.Errorf("Expected a fallback to the node's suggestion, got %s", fees.TipCap)
+	}
+}
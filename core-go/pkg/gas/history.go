@@ -0,0 +1,309 @@
+package gas
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// z95 is the two-sided 95% normal quantile
+const z95 = 1.96
+
+// txOverheadGas is the gas of an execution outside its swaps: intrinsic gas,
+// flash loan and repayment, used before any history exists
+const txOverheadGas = 80_000
+
+// Shape is a route's gas-relevant structure: the chain and each hop's adapter
+type Shape struct {
+	ChainID  uint64
+	Adapters []string // route adapter names in hop order, e.g. univ2, univ3
+}
+
+// Key identifies the shape, e.g. "137:univ2>univ3"
+func (s Shape) Key() string {
+	return strconv.FormatUint(s.ChainID, 10) + ":" + strings.Join(s.Adapters, ">")
+}
+
+// Sources of an Estimate, most specific first
+const (
+	SourceShape   = "shape"   // executions of the same shape
+	SourceFit     = "fit"     // per-adapter regression over the chain's executions
+	SourceDefault = "default" // per-hop model with no execution history
+)
+
+// Estimate is a pre-trade gas estimate with a 95% prediction interval for
+// one more execution
+type Estimate struct {
+	Gas     uint64 `json:"gas"`
+	Low     uint64 `json:"low"`
+	High    uint64 `json:"high"`
+	Samples int    `json:"samples"`
+	Source  string `json:"source"`
+}
+
+// History fits gas models from executed transactions' receipts: per route
+// shape once a shape has MinSamples executions, otherwise a per-chain linear
+// fit of gas on the number of hops through each adapter
+type History struct {
+	// MinSamples is the executions a shape needs before its own mean is used
+	MinSamples int
+	// MaxSamples bounds the executions kept per chain for the fit, oldest dropped
+	MaxSamples int
+	// Hops prices swaps when a chain has no usable history; nil uses defaults
+	Hops *Model
+	// DefaultSpread widens default estimates, as a fraction of Gas
+	DefaultSpread float64
+
+	mu      sync.Mutex
+	shapes  map[string]*moments
+	samples map[uint64][]sample
+	fits    map[uint64]*fit // cached until the chain's next observation
+}
+
+type sample struct {
+	adapters []string
+	gas      float64
+}
+
+// moments accumulates a running mean and variance (Welford)
+type moments struct {
+	n    int
+	mean float64
+	m2   float64
+}
+
+func (m *moments) add(x float64) {
+	m.n++
+	d := x - m.mean
+	m.mean += d / float64(m.n)
+	m.m2 += d * (x - m.mean)
+}
+
+func (m *moments) stddev() float64 {
+	if m.n < 2 {
+		return 0
+	}
+	return math.Sqrt(m.m2 / float64(m.n-1))
+}
+
+// NewHistory creates an empty history
+func NewHistory() *History {
+	return &History{
+		MinSamples:    5,
+		MaxSamples:    5000,
+		DefaultSpread: 0.25,
+		shapes:        make(map[string]*moments),
+		samples:       make(map[uint64][]sample),
+		fits:          make(map[uint64]*fit),
+	}
+}
+
+// Observe records the gas an executed route of shape used
+func (h *History) Observe(shape Shape, gasUsed uint64) {
+	if gasUsed == 0 || len(shape.Adapters) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := shape.Key()
+	m := h.shapes[key]
+	if m == nil {
+		m = &moments{}
+		h.shapes[key] = m
+	}
+	m.add(float64(gasUsed))
+
+	s := append(h.samples[shape.ChainID], sample{adapters: append([]string(nil), shape.Adapters...), gas: float64(gasUsed)})
+	if h.MaxSamples > 0 && len(s) > h.MaxSamples {
+		s = s[len(s)-h.MaxSamples:]
+	}
+	h.samples[shape.ChainID] = s
+	delete(h.fits, shape.ChainID)
+}
+
+// Estimate predicts the gas of one execution of shape
+func (h *History) Estimate(shape Shape) Estimate {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if m := h.shapes[shape.Key()]; m != nil && m.n >= h.MinSamples {
+		half := z95 * m.stddev() * math.Sqrt(1+1/float64(m.n))
+		return interval(m.mean, half, m.n, SourceShape)
+	}
+	if f := h.fitLocked(shape.ChainID); f != nil {
+		if mean, half, ok := f.predict(shape.Adapters); ok {
+			return interval(mean, half, f.n, SourceFit)
+		}
+	}
+	gas := float64(txOverheadGas)
+	for _, a := range shape.Adapters {
+		gas += float64(h.Hops.Estimate(shape.ChainID, a))
+	}
+	return interval(gas, gas*h.DefaultSpread, 0, SourceDefault)
+}
+
+func interval(mean, half float64, n int, source string) Estimate {
+	low := mean - half
+	if low < 0 {
+		low = 0
+	}
+	return Estimate{
+		Gas:     uint64(math.Round(mean)),
+		Low:     uint64(math.Round(low)),
+		High:    uint64(math.Round(mean + half)),
+		Samples: n,
+		Source:  source,
+	}
+}
+
+// fitLocked returns the chain's cached fit, refitting after new observations;
+// nil when there are too few executions to fit; the caller holds mu
+func (h *History) fitLocked(chainID uint64) *fit {
+	if f, ok := h.fits[chainID]; ok {
+		return f
+	}
+	f := fitSamples(h.samples[chainID], h.MinSamples)
+	h.fits[chainID] = f
+	return f
+}
+
+// fit is an ordinary least squares fit of gas = base + Σ coef[adapter] × hops
+type fit struct {
+	adapters []string // feature order after the intercept
+	coef     []float64
+	inv      [][]float64 // (XᵀX)⁻¹, for prediction variance
+	sigma2   float64     // residual variance
+	n        int
+}
+
+// fitSamples fits samples, returning nil when they cannot pin down every
+// coefficient with at least minExtra spare observations
+func fitSamples(samples []sample, minExtra int) *fit {
+	seen := make(map[string]bool)
+	for _, s := range samples {
+		for _, a := range s.adapters {
+			seen[a] = true
+		}
+	}
+	f := &fit{n: len(samples)}
+	for a := range seen {
+		f.adapters = append(f.adapters, a)
+	}
+	sort.Strings(f.adapters)
+	k := len(f.adapters) + 1
+	if f.n < k+minExtra {
+		return nil
+	}
+
+	xtx := make([][]float64, k)
+	for i := range xtx {
+		xtx[i] = make([]float64, k)
+	}
+	xty := make([]float64, k)
+	for _, s := range samples {
+		x := f.features(s.adapters)
+		for i := range x {
+			xty[i] += x[i] * s.gas
+			for j := range x {
+				xtx[i][j] += x[i] * x[j]
+			}
+		}
+	}
+	if f.inv = invert(xtx); f.inv == nil {
+		return nil
+	}
+	f.coef = make([]float64, k)
+	for i := range f.coef {
+		for j := range xty {
+			f.coef[i] += f.inv[i][j] * xty[j]
+		}
+	}
+	var rss float64
+	for _, s := range samples {
+		r := s.gas - dot(f.coef, f.features(s.adapters))
+		rss += r * r
+	}
+	f.sigma2 = rss / float64(f.n-k)
+	return f
+}
+
+// features returns the intercept and the hop count per fitted adapter
+func (f *fit) features(adapters []string) []float64 {
+	x := make([]float64, len(f.adapters)+1)
+	x[0] = 1
+	for _, a := range adapters {
+		if i := sort.SearchStrings(f.adapters, a); i < len(f.adapters) && f.adapters[i] == a {
+			x[i+1]++
+		}
+	}
+	return x
+}
+
+// predict returns the mean and 95% half-width for adapters; false when the
+// shape uses an adapter the fit has never seen
+func (f *fit) predict(adapters []string) (float64, float64, bool) {
+	for _, a := range adapters {
+		if i := sort.SearchStrings(f.adapters, a); i == len(f.adapters) || f.adapters[i] != a {
+			return 0, 0, false
+		}
+	}
+	x := f.features(adapters)
+	var leverage float64
+	for i := range x {
+		for j := range x {
+			leverage += x[i] * f.inv[i][j] * x[j]
+		}
+	}
+	return dot(f.coef, x), z95 * math.Sqrt(f.sigma2*(1+leverage)), true
+}
+
+func dot(a, b []float64) float64 {
+	var s float64
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+// invert returns the inverse of a by Gauss-Jordan elimination, or nil when
+// a is singular
+func invert(a [][]float64) [][]float64 {
+	n := len(a)
+	m := make([][]float64, n)
+	for i := range a {
+		m[i] = make([]float64, 2*n)
+		copy(m[i], a[i])
+		m[i][n+i] = 1
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(m[r][col]) > math.Abs(m[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(m[pivot][col]) < 1e-9 {
+			return nil
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		p := m[col][col]
+		for j := range m[col] {
+			m[col][j] /= p
+		}
+		for r := 0; r < n; r++ {
+			if r == col || m[r][col] == 0 {
+				continue
+			}
+			factor := m[r][col]
+			for j := range m[r] {
+				m[r][j] -= factor * m[col][j]
+			}
+		}
+	}
+	out := make([][]float64, n)
+	for i := range m {
+		out[i] = m[i][n:]
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package gas
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// DefaultSwapGas is the typical gas of one swap per route adapter, used
+// until the model has observed an adapter on a chain
+var DefaultSwapGas = map[string]uint64{
+	"univ2": 110_000,
+	"univ3": 140_000,
+	"curve": 180_000,
+}
+
+// fallbackSwapGas prices a hop through an adapter with no default
+const fallbackSwapGas = 150_000
+
+// Model estimates per-hop swap gas by chain and adapter from observed hops,
+// such as traced simulations, as an exponentially weighted moving average
+type Model struct {
+	// Alpha weights each new observation, 0 to 1
+	Alpha float64
+
+	mu      sync.RWMutex
+	est     map[modelKey]float64
+	samples map[modelKey]int
+
+	estimate *metrics.GaugeVec
+}
+
+type modelKey struct {
+	chainID uint64
+	adapter string
+}
+
+// NewModel creates an empty model; reg may be nil
+func NewModel(reg *metrics.Registry) *Model {
+	m := &Model{Alpha: 0.2, est: make(map[modelKey]float64), samples: make(map[modelKey]int)}
+	if reg != nil {
+		m.estimate = reg.Gauge("titan_gas_swap_units", "Modelled gas of one swap hop", "chain", "adapter")
+	}
+	return m
+}
+
+// Observe records the gas one hop through adapter used on a chain
+func (m *Model) Observe(chainID uint64, adapter string, gasUsed uint64) {
+	k := modelKey{chainID, adapter}
+	m.mu.Lock()
+	est, ok := m.est[k]
+	if ok {
+		est += m.Alpha * (float64(gasUsed) - est)
+	} else {
+		est = float64(gasUsed)
+	}
+	m.est[k] = est
+	m.samples[k]++
+	m.mu.Unlock()
+
+	if m.estimate != nil {
+		m.estimate.Set(est, strconv.FormatUint(chainID, 10), adapter)
+	}
+}
+
+// Estimate returns the expected gas of one hop through adapter on a chain
+func (m *Model) Estimate(chainID uint64, adapter string) uint64 {
+	if m != nil {
+		m.mu.RLock()
+		est, ok := m.est[modelKey{chainID, adapter}]
+		m.mu.RUnlock()
+		if ok {
+			return uint64(est + 0.5)
+		}
+	}
+	if gas, ok := DefaultSwapGas[adapter]; ok {
+		return gas
+	}
+	return fallbackSwapGas
+}
+
+// Samples returns how many hops through adapter the model has observed on a chain
+func (m *Model) Samples(chainID uint64, adapter string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.samples[modelKey{chainID, adapter}]
+}
@@ -0,0 +1,85 @@
+package gas
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+)
+
+// Dialer returns the node connection for a chain
+type Dialer func(chainID uint64) (chain.Client, error)
+
+// feeHistoryBlocks is how many recent blocks the fee history tip spans
+const feeHistoryBlocks = 10
+
+// NodeSource estimates fees from the latest header and the node's tip suggestion
+type NodeSource struct {
+	dial Dialer
+
+	// FeeHistory, when set, reports whether a chain's node serves
+	// eth_feeHistory; the tip is then the median of the recent blocks'
+	// median priority fees rather than the node's own suggestion
+	FeeHistory func(chainID uint64) bool
+}
+
+// NewNodeSource creates a source over the chains' RPC nodes
+func NewNodeSource(dial Dialer) *NodeSource {
+	return &NodeSource{dial: dial}
+}
+
+// Name returns the source name
+func (n *NodeSource) Name() string { return "node" }
+
+// Estimate reads the pending base fee and suggested tip; pre-London chains report a zero base fee
+func (n *NodeSource) Estimate(ctx context.Context, chainID uint64) (Fees, error) {
+	backend, err := n.dial(chainID)
+	if err != nil {
+		return Fees{}, err
+	}
+	header, err := backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return Fees{}, err
+	}
+	tip, err := n.tip(ctx, chainID, backend)
+	if err != nil {
+		return Fees{}, err
+	}
+	baseFee := header.BaseFee
+	if baseFee == nil {
+		baseFee = new(big.Int)
+	}
+	return Fees{BaseFee: baseFee, TipCap: tip}, nil
+}
+
+// tip reads the fee history where the node serves it, falling back to
+// eth_maxPriorityFeePerGas when it doesn't or the read fails
+func (n *NodeSource) tip(ctx context.Context, chainID uint64, backend chain.Client) (*big.Int, error) {
+	if reader, ok := backend.(chain.FeeHistoryReader); ok && n.FeeHistory != nil && n.FeeHistory(chainID) {
+		if tip, err := historyTip(ctx, reader); err == nil {
+			return tip, nil
+		}
+	}
+	return backend.SuggestGasTipCap(ctx)
+}
+
+// historyTip returns the median of the last blocks' median priority fees
+func historyTip(ctx context.Context, reader chain.FeeHistoryReader) (*big.Int, error) {
+	history, err := reader.FeeHistory(ctx, feeHistoryBlocks, nil, []float64{50})
+	if err != nil {
+		return nil, err
+	}
+	var tips []*big.Int
+	for _, rewards := range history.Reward {
+		if len(rewards) > 0 && rewards[0] != nil {
+			tips = append(tips, rewards[0])
+		}
+	}
+	if len(tips) == 0 {
+		return nil, fmt.Errorf("fee history has no rewards")
+	}
+	sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+	return new(big.Int).Set(tips[len(tips)/2]), nil
+}
@@ -0,0 +1,120 @@
+package gas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+)
+
+// PolygonGasStationURL is the public Polygon PoS gas station (v2 API)
+const PolygonGasStationURL = "https://gasstation.polygon.technology/v2"
+
+// Gas station speed tiers
+const (
+	SpeedSafeLow  = "safeLow"
+	SpeedStandard = "standard"
+	SpeedFast     = "fast"
+)
+
+// stationTier is one speed tier of a gas station response, in gwei
+type stationTier struct {
+	MaxPriorityFee float64 `json:"maxPriorityFee"`
+	MaxFee         float64 `json:"maxFee"`
+}
+
+// stationResponse is the Polygon gas station v2 payload
+type stationResponse struct {
+	SafeLow          stationTier `json:"safeLow"`
+	Standard         stationTier `json:"standard"`
+	Fast             stationTier `json:"fast"`
+	EstimatedBaseFee float64     `json:"estimatedBaseFee"`
+}
+
+// GasStationSource reads fees from gas station HTTP APIs
+type GasStationSource struct {
+	Speed string // tier to bid at; defaults to standard
+
+	urls   map[uint64]string
+	client *http.Client
+}
+
+// NewGasStationSource creates a source over per-chain gas station URLs
+func NewGasStationSource(urls map[uint64]string) *GasStationSource {
+	return &GasStationSource{Speed: SpeedStandard, urls: urls, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// GasStationsFromConfig collects each chain's configured gas station URL
+func GasStationsFromConfig(cfg *config.Config) map[uint64]string {
+	urls := make(map[uint64]string)
+	for chainID, chain := range cfg.Chains {
+		if chain.GasStationURL != "" {
+			urls[chainID] = chain.GasStationURL
+		}
+	}
+	return urls
+}
+
+// Name returns the source name
+func (g *GasStationSource) Name() string { return "gasstation" }
+
+// Estimate fetches the configured speed tier
+func (g *GasStationSource) Estimate(ctx context.Context, chainID uint64) (Fees, error) {
+	url, ok := g.urls[chainID]
+	if !ok {
+		return Fees{}, fmt.Errorf("no gas station for chain %d", chainID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Fees{}, err
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return Fees{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Fees{}, fmt.Errorf("gas station returned %s", resp.Status)
+	}
+	var body stationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return Fees{}, fmt.Errorf("bad gas station response: %w", err)
+	}
+
+	tier := body.Standard
+	switch g.Speed {
+	case SpeedSafeLow:
+		tier = body.SafeLow
+	case SpeedFast:
+		tier = body.Fast
+	}
+	tip, err := gweiToWei(tier.MaxPriorityFee)
+	if err != nil {
+		return Fees{}, err
+	}
+	feeCap, err := gweiToWei(tier.MaxFee)
+	if err != nil {
+		return Fees{}, err
+	}
+	baseFee, err := gweiToWei(body.EstimatedBaseFee)
+	if err != nil {
+		return Fees{}, err
+	}
+	if tip.Sign() == 0 {
+		return Fees{}, fmt.Errorf("gas station returned no %s tier", g.Speed)
+	}
+	return Fees{BaseFee: baseFee, TipCap: tip, FeeCap: feeCap}, nil
+}
+
+// gweiToWei converts a decimal gwei amount to wei
+func gweiToWei(gwei float64) (*big.Int, error) {
+	if math.IsNaN(gwei) || gwei < 0 || gwei > math.MaxUint64/1e9 {
+		return nil, fmt.Errorf("invalid gwei amount %v", gwei)
+	}
+	return new(big.Int).SetUint64(uint64(math.Round(gwei * 1e9))), nil
+}
@@ -0,0 +1,584 @@
+package graphql
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/seal"
+)
+
+// maxPage bounds the nodes one page returns
+const maxPage = 500
+
+// schema is the query surface; lists are newest first
+const schema = `
+schema {
+	query: Query
+}
+
+scalar Time
+scalar Long
+
+type Query {
+	trades(filter: TradeFilter, first: Int = 50, after: String): TradeConnection!
+	opportunities(filter: OpportunityFilter, first: Int = 50, after: String): OpportunityConnection!
+	routes(chainId: Int, since: Time, until: Time, first: Int = 50): [Route!]!
+	metrics(prefix: String): [Metric!]!
+}
+
+input TradeFilter {
+	chainId: Int
+	route: String
+	success: Boolean
+	category: String
+	since: Time
+	until: Time
+	includeReversed: Boolean
+}
+
+input OpportunityFilter {
+	chainId: Int
+	strategy: String
+	route: String
+	decision: String
+	category: String
+	rejectedBy: String
+	since: Time
+	until: Time
+}
+
+type TradeConnection {
+	totalCount: Int!
+	hasNextPage: Boolean!
+	endCursor: String
+	nodes: [Trade!]!
+}
+
+type OpportunityConnection {
+	totalCount: Int!
+	hasNextPage: Boolean!
+	endCursor: String
+	nodes: [Opportunity!]!
+}
+
+type Trade {
+	opportunityId: String!
+	chainId: Int!
+	route: String!
+	txHash: String!
+	block: Long!
+	success: Boolean!
+	reason: String
+	category: String
+	predictedProfitUsd: Float!
+	realizedProfitUsd: Float!
+	gasUsd: Float!
+	pnlUsd: Float!
+	reversed: Boolean!
+	time: Time!
+	opportunity: Opportunity
+}
+
+type Opportunity {
+	id: String!
+	chainId: Int!
+	block: Long!
+	detectedAt: Time!
+	strategy: String
+	route: String!
+	decision: String!
+	reason: String
+	category: String
+	score: Float!
+	grossProfitUsd: Float!
+	netProfitUsd: Float!
+	gasUsd: Float!
+	pipelineScore: Float
+	rejectedBy: String
+	trade: Trade
+}
+
+type Route {
+	chainId: Int!
+	route: String!
+	executions: Int!
+	successes: Int!
+	hitRate: Float!
+	pnlUsd: Float!
+}
+
+type Metric {
+	name: String!
+	labels: [Label!]!
+	value: Float!
+}
+
+type Label {
+	name: String!
+	value: String!
+}
+`
+
+// Long is a 64-bit integer, for block numbers past GraphQL's 32-bit Int
+type Long int64
+
+// ImplementsGraphQLType maps Long to the schema's Long scalar
+func (Long) ImplementsGraphQLType(name string) bool { return name == "Long" }
+
+// UnmarshalGraphQL reads a Long argument
+func (l *Long) UnmarshalGraphQL(input interface{}) error {
+	switch v := input.(type) {
+	case int32:
+		*l = Long(v)
+	case float64:
+		*l = Long(v)
+	case string:
+		n, err := strconv.ParseInt(v, 0, 64)
+		if err != nil {
+			return err
+		}
+		*l = Long(n)
+	default:
+		return fmt.Errorf("wrong type for Long: %T", input)
+	}
+	return nil
+}
+
+// Server answers GraphQL queries over the trade journal and a metrics
+// registry, so dashboards and research can ask for the history they need
+// without a bespoke REST endpoint each. The journal is read per query, so
+// answers include everything journaled up to it.
+type Server struct {
+	handler http.Handler
+}
+
+// New creates a server over the journal at path, opened with keys, and
+// reg's metrics; keys and reg may be nil
+func New(path string, keys *seal.Keyring, reg *metrics.Registry) (*Server, error) {
+	s, err := gql.ParseSchema(schema, &resolver{path: path, keys: keys, reg: reg})
+	if err != nil {
+		return nil, err
+	}
+	return &Server{handler: &relay.Handler{Schema: s}}, nil
+}
+
+// ServeHTTP answers a query POSTed as {"query": ..., "variables": ...}
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST a GraphQL query", http.StatusMethodNotAllowed)
+		return
+	}
+	s.handler.ServeHTTP(w, r)
+}
+
+// trade is one journaled execution
+type trade struct {
+	opportunity.Execution
+	reversed bool
+}
+
+// history is the journal as of one query, in journal order
+type history struct {
+	opportunities []*opportunity.Opportunity
+	byID          map[string]*opportunity.Opportunity
+	trades        []*trade
+	byOpportunity map[string]*trade // latest trade of each opportunity
+}
+
+type resolver struct {
+	path string
+	keys *seal.Keyring
+	reg  *metrics.Registry
+}
+
+// load reads the journal; a missing one is empty
+func (r *resolver) load() (*history, error) {
+	entries, err := journal.ReadSealed(r.path, "", r.keys)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	h := &history{byID: make(map[string]*opportunity.Opportunity), byOpportunity: make(map[string]*trade)}
+	reversed := make(map[string]bool) // by opportunity and block hash
+	for _, e := range entries {
+		switch e.Kind {
+		case journal.KindOpportunity:
+			var o opportunity.Opportunity
+			if err := json.Unmarshal(e.Data, &o); err != nil {
+				return nil, fmt.Errorf("bad opportunity entry at %s: %w", e.Time, err)
+			}
+			if o.Explanation == nil {
+				o.Explanation = &opportunity.Explanation{}
+			}
+			if o.DetectedAt.IsZero() {
+				o.DetectedAt = e.Time
+			}
+			h.opportunities = append(h.opportunities, &o)
+			h.byID[o.ID] = &o
+		case journal.KindExecution:
+			t := &trade{}
+			if err := json.Unmarshal(e.Data, &t.Execution); err != nil {
+				return nil, fmt.Errorf("bad execution entry at %s: %w", e.Time, err)
+			}
+			if t.Time.IsZero() {
+				t.Time = e.Time
+			}
+			h.trades = append(h.trades, t)
+			h.byOpportunity[t.OpportunityID] = t
+		case journal.KindReversal:
+			var v opportunity.Reversal
+			if err := json.Unmarshal(e.Data, &v); err != nil {
+				return nil, fmt.Errorf("bad reversal entry at %s: %w", e.Time, err)
+			}
+			reversed[v.OpportunityID+v.BlockHash.Hex()] = true
+		}
+	}
+	for _, t := range h.trades {
+		t.reversed = reversed[t.OpportunityID+t.BlockHash.Hex()]
+	}
+	return h, nil
+}
+
+// page returns n items of a list after the cursor, which encodes an offset
+func page(total int, first int32, after *string) (from, to int, err error) {
+	if after != nil && *after != "" {
+		raw, err := base64.StdEncoding.DecodeString(*after)
+		if err != nil {
+			return 0, 0, fmt.Errorf("bad cursor %q", *after)
+		}
+		if from, err = strconv.Atoi(string(raw)); err != nil || from < 0 {
+			return 0, 0, fmt.Errorf("bad cursor %q", *after)
+		}
+	}
+	n := int(first)
+	if n <= 0 || n > maxPage {
+		n = maxPage
+	}
+	if from > total {
+		from = total
+	}
+	to = from + n
+	if to > total {
+		to = total
+	}
+	return from, to, nil
+}
+
+// connection is a page of nodes
+type connection struct {
+	total, to int
+}
+
+func (c connection) TotalCount() int32 { return int32(c.total) }
+func (c connection) HasNextPage() bool { return c.to < c.total }
+func (c connection) EndCursor() *string {
+	if c.to == 0 {
+		return nil
+	}
+	cursor := base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(c.to)))
+	return &cursor
+}
+
+// window reports whether t falls in [since, until)
+func window(t time.Time, since, until *gql.Time) bool {
+	return (since == nil || !t.Before(since.Time)) && (until == nil || t.Before(until.Time))
+}
+
+type tradeFilter struct {
+	ChainID         *int32
+	Route           *string
+	Success         *bool
+	Category        *string
+	Since           *gql.Time
+	Until           *gql.Time
+	IncludeReversed *bool
+}
+
+func (f *tradeFilter) match(t *trade) bool {
+	if f == nil {
+		return !t.reversed
+	}
+	return (f.IncludeReversed != nil && *f.IncludeReversed || !t.reversed) &&
+		(f.ChainID == nil || uint64(*f.ChainID) == t.ChainID) &&
+		(f.Route == nil || *f.Route == t.Route) &&
+		(f.Success == nil || *f.Success == t.Success) &&
+		(f.Category == nil || *f.Category == string(t.Category)) &&
+		window(t.Time, f.Since, f.Until)
+}
+
+type tradeConnection struct {
+	connection
+	nodes []*tradeResolver
+}
+
+func (c *tradeConnection) Nodes() []*tradeResolver { return c.nodes }
+
+// Trades lists executions, newest first; reorged ones only on request
+func (r *resolver) Trades(args struct {
+	Filter *tradeFilter
+	First  int32
+	After  *string
+}) (*tradeConnection, error) {
+	h, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	var matched []*trade
+	for i := len(h.trades) - 1; i >= 0; i-- {
+		if args.Filter.match(h.trades[i]) {
+			matched = append(matched, h.trades[i])
+		}
+	}
+	from, to, err := page(len(matched), args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+	c := &tradeConnection{connection: connection{total: len(matched), to: to}}
+	for _, t := range matched[from:to] {
+		c.nodes = append(c.nodes, &tradeResolver{h, t})
+	}
+	return c, nil
+}
+
+type opportunityFilter struct {
+	ChainID    *int32
+	Strategy   *string
+	Route      *string
+	Decision   *string
+	Category   *string
+	RejectedBy *string
+	Since      *gql.Time
+	Until      *gql.Time
+}
+
+func (f *opportunityFilter) match(o *opportunity.Opportunity) bool {
+	if f == nil {
+		return true
+	}
+	x := o.Explanation
+	var rejectedBy string
+	if x.Breakdown != nil {
+		rejectedBy = x.Breakdown.RejectedBy
+	}
+	return (f.ChainID == nil || uint64(*f.ChainID) == o.ChainID) &&
+		(f.Strategy == nil || strings.EqualFold(*f.Strategy, o.Strategy)) &&
+		(f.Route == nil || *f.Route == x.Route()) &&
+		(f.Decision == nil || *f.Decision == x.Decision) &&
+		(f.Category == nil || *f.Category == string(x.Category)) &&
+		(f.RejectedBy == nil || *f.RejectedBy == rejectedBy) &&
+		window(o.DetectedAt, f.Since, f.Until)
+}
+
+type opportunityConnection struct {
+	connection
+	nodes []*opportunityResolver
+}
+
+func (c *opportunityConnection) Nodes() []*opportunityResolver { return c.nodes }
+
+// Opportunities lists scored opportunities, newest first
+func (r *resolver) Opportunities(args struct {
+	Filter *opportunityFilter
+	First  int32
+	After  *string
+}) (*opportunityConnection, error) {
+	h, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	var matched []*opportunity.Opportunity
+	for i := len(h.opportunities) - 1; i >= 0; i-- {
+		if args.Filter.match(h.opportunities[i]) {
+			matched = append(matched, h.opportunities[i])
+		}
+	}
+	from, to, err := page(len(matched), args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+	c := &opportunityConnection{connection: connection{total: len(matched), to: to}}
+	for _, o := range matched[from:to] {
+		c.nodes = append(c.nodes, &opportunityResolver{h, o})
+	}
+	return c, nil
+}
+
+// routeStat aggregates the executions over one route on one chain
+type routeStat struct {
+	chainID    uint64
+	route      string
+	executions int
+	successes  int
+	pnl        float64
+}
+
+func (s *routeStat) ChainID() int32    { return int32(s.chainID) }
+func (s *routeStat) Route() string     { return s.route }
+func (s *routeStat) Executions() int32 { return int32(s.executions) }
+func (s *routeStat) Successes() int32  { return int32(s.successes) }
+func (s *routeStat) PnlUSD() float64   { return s.pnl }
+func (s *routeStat) HitRate() float64 {
+	if s.executions == 0 {
+		return 0
+	}
+	return float64(s.successes) / float64(s.executions)
+}
+
+// Routes ranks routes by PnL net of gas over their executions in the window
+func (r *resolver) Routes(args struct {
+	ChainID *int32
+	Since   *gql.Time
+	Until   *gql.Time
+	First   int32
+}) ([]*routeStat, error) {
+	h, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[string]*routeStat)
+	for _, t := range h.trades {
+		if t.reversed || (args.ChainID != nil && uint64(*args.ChainID) != t.ChainID) || !window(t.Time, args.Since, args.Until) {
+			continue
+		}
+		k := strconv.FormatUint(t.ChainID, 10) + "/" + t.Route
+		s := stats[k]
+		if s == nil {
+			s = &routeStat{chainID: t.ChainID, route: t.Route}
+			stats[k] = s
+		}
+		s.executions++
+		s.pnl += (t.RealizedProfitUSD - t.GasUSD).Float()
+		if t.Success {
+			s.successes++
+		}
+	}
+	out := make([]*routeStat, 0, len(stats))
+	for _, s := range stats {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].pnl != out[j].pnl {
+			return out[i].pnl > out[j].pnl
+		}
+		if out[i].chainID != out[j].chainID {
+			return out[i].chainID < out[j].chainID
+		}
+		return out[i].route < out[j].route
+	})
+	_, to, _ := page(len(out), args.First, nil)
+	return out[:to], nil
+}
+
+type metricResolver struct{ metrics.Sample }
+
+type labelResolver struct{ name, value string }
+
+func (m metricResolver) Name() string   { return m.Sample.Name }
+func (m metricResolver) Value() float64 { return m.Sample.Value }
+func (m metricResolver) Labels() []labelResolver {
+	out := make([]labelResolver, 0, len(m.Sample.Labels))
+	for name, value := range m.Sample.Labels {
+		out = append(out, labelResolver{name, value})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out
+}
+
+func (l labelResolver) Name() string  { return l.name }
+func (l labelResolver) Value() string { return l.value }
+
+// Metrics returns the current value of every series whose name has prefix
+func (r *resolver) Metrics(args struct{ Prefix *string }) []metricResolver {
+	if r.reg == nil {
+		return []metricResolver{}
+	}
+	out := []metricResolver{}
+	for _, s := range r.reg.Samples() {
+		if args.Prefix == nil || strings.HasPrefix(s.Name, *args.Prefix) {
+			out = append(out, metricResolver{s})
+		}
+	}
+	return out
+}
+
+type tradeResolver struct {
+	h *history
+	t *trade
+}
+
+func (r *tradeResolver) OpportunityID() string       { return r.t.OpportunityID }
+func (r *tradeResolver) ChainID() int32              { return int32(r.t.ChainID) }
+func (r *tradeResolver) Route() string               { return r.t.Route }
+func (r *tradeResolver) TxHash() string              { return r.t.TxHash.Hex() }
+func (r *tradeResolver) Block() Long                 { return Long(r.t.Block) }
+func (r *tradeResolver) Success() bool               { return r.t.Success }
+func (r *tradeResolver) Reason() *string             { return optional(r.t.Reason) }
+func (r *tradeResolver) Category() *string           { return optional(string(r.t.Category)) }
+func (r *tradeResolver) PredictedProfitUSD() float64 { return r.t.PredictedProfitUSD.Float() }
+func (r *tradeResolver) RealizedProfitUSD() float64  { return r.t.RealizedProfitUSD.Float() }
+func (r *tradeResolver) GasUSD() float64             { return r.t.GasUSD.Float() }
+func (r *tradeResolver) PnlUSD() float64             { return (r.t.RealizedProfitUSD - r.t.GasUSD).Float() }
+func (r *tradeResolver) Reversed() bool              { return r.t.reversed }
+func (r *tradeResolver) Time() gql.Time              { return gql.Time{Time: r.t.Time} }
+func (r *tradeResolver) Opportunity() *opportunityResolver {
+	if o, ok := r.h.byID[r.t.OpportunityID]; ok {
+		return &opportunityResolver{r.h, o}
+	}
+	return nil
+}
+
+type opportunityResolver struct {
+	h *history
+	o *opportunity.Opportunity
+}
+
+func (r *opportunityResolver) ID() string              { return r.o.ID }
+func (r *opportunityResolver) ChainID() int32          { return int32(r.o.ChainID) }
+func (r *opportunityResolver) Block() Long             { return Long(r.o.Block) }
+func (r *opportunityResolver) DetectedAt() gql.Time    { return gql.Time{Time: r.o.DetectedAt} }
+func (r *opportunityResolver) Strategy() *string       { return optional(r.o.Strategy) }
+func (r *opportunityResolver) Route() string           { return r.o.Explanation.Route() }
+func (r *opportunityResolver) Decision() string        { return r.o.Explanation.Decision }
+func (r *opportunityResolver) Reason() *string         { return optional(r.o.Explanation.Reason) }
+func (r *opportunityResolver) Category() *string       { return optional(string(r.o.Explanation.Category)) }
+func (r *opportunityResolver) Score() float64          { return r.o.Explanation.Score }
+func (r *opportunityResolver) GrossProfitUSD() float64 { return r.o.Explanation.GrossProfitUSD.Float() }
+func (r *opportunityResolver) NetProfitUSD() float64   { return r.o.Explanation.NetProfitUSD.Float() }
+func (r *opportunityResolver) GasUSD() float64         { return r.o.Explanation.GasUSD.Float() }
+func (r *opportunityResolver) PipelineScore() *float64 {
+	if bd := r.o.Explanation.Breakdown; bd != nil {
+		return &bd.Score
+	}
+	return nil
+}
+func (r *opportunityResolver) RejectedBy() *string {
+	if bd := r.o.Explanation.Breakdown; bd != nil {
+		return optional(bd.RejectedBy)
+	}
+	return nil
+}
+func (r *opportunityResolver) Trade() *tradeResolver {
+	if t, ok := r.h.byOpportunity[r.o.ID]; ok {
+		return &tradeResolver{r.h, t}
+	}
+	return nil
+}
+
+// optional returns nil for an empty string
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
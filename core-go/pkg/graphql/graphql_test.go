@@ -0,0 +1,174 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+func newServer(t *testing.T) *Server {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j, err := journal.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	base := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	legs := []opportunity.Leg{{Dex: "uniswap"}, {Dex: "sushi"}}
+	records := []struct {
+		kind string
+		data interface{}
+	}{
+		{journal.KindOpportunity, opportunity.Opportunity{ID: "a", ChainID: 1, DetectedAt: base, Explanation: &opportunity.Explanation{Legs: legs, Decision: opportunity.DecisionExecute, Score: 0.7}}},
+		{journal.KindExecution, opportunity.Execution{OpportunityID: "a", ChainID: 1, Route: "uniswap>sushi", Success: true, Block: 5000000000, RealizedProfitUSD: units.DollarsToUSD(30), GasUSD: units.DollarsToUSD(5), Time: base}},
+		{journal.KindOpportunity, opportunity.Opportunity{ID: "b", ChainID: 137, DetectedAt: base.Add(time.Hour), Explanation: &opportunity.Explanation{Legs: legs, Decision: opportunity.DecisionReject, Breakdown: &opportunity.ScoreBreakdown{Score: 0.2, RejectedBy: "ml"}}}},
+		{journal.KindOpportunity, opportunity.Opportunity{ID: "c", ChainID: 1, DetectedAt: base.Add(2 * time.Hour), Explanation: &opportunity.Explanation{Legs: legs, Decision: opportunity.DecisionExecute}}},
+		{journal.KindExecution, opportunity.Execution{OpportunityID: "c", ChainID: 1, Route: "uniswap>sushi", Success: false, Reason: "reverted", GasUSD: units.DollarsToUSD(4), BlockHash: common.HexToHash("0x01"), Time: base.Add(2 * time.Hour)}},
+		{journal.KindExecution, opportunity.Execution{OpportunityID: "c", ChainID: 1, Route: "uniswap>sushi", Success: true, RealizedProfitUSD: units.DollarsToUSD(8), BlockHash: common.HexToHash("0x02"), Time: base.Add(3 * time.Hour)}},
+		{journal.KindReversal, opportunity.Reversal{OpportunityID: "c", BlockHash: common.HexToHash("0x02")}},
+	}
+	for _, r := range records {
+		if err := j.Append(r.kind, r.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reg := metrics.NewRegistry()
+	reg.Counter("titan_test_total", "Test", "chain").Inc("1")
+	reg.Histogram("titan_test_seconds", "Test", []float64{1}).Observe(0.5)
+	s, err := New(path, nil, reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// query posts q and decodes the data, failing on GraphQL errors
+func query(t *testing.T, s *Server, q string, variables map[string]interface{}, out interface{}) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]interface{}{"query": q, "variables": variables})
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%v: %s", err, rec.Body)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("query failed: %+v", resp.Errors)
+	}
+	if err := json.Unmarshal(resp.Data, out); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTradesFilterAndPaginate(t *testing.T) {
+	s := newServer(t)
+	const q = `query($after: String) {
+		trades(filter: {chainId: 1}, first: 1, after: $after) {
+			totalCount hasNextPage endCursor
+			nodes { opportunityId success reason block pnlUsd opportunity { id decision } }
+		}
+	}`
+	type page struct {
+		Trades struct {
+			TotalCount  int
+			HasNextPage bool
+			EndCursor   string
+			Nodes       []struct {
+				OpportunityID string
+				Success       bool
+				Reason        *string
+				Block         int64
+				PnlUSD        float64
+				Opportunity   struct{ ID, Decision string }
+			}
+		}
+	}
+	var first page
+	query(t, s, q, nil, &first)
+	if first.Trades.TotalCount != 2 || !first.Trades.HasNextPage || len(first.Trades.Nodes) != 1 {
+		t.Fatalf("Expected the first of two unreversed trades, got %+v", first.Trades)
+	}
+	if n := first.Trades.Nodes[0]; n.OpportunityID != "c" || n.Success || n.Reason == nil || *n.Reason != "reverted" {
+		t.Errorf("Expected the newest trade to be c's reverted one, got %+v", n)
+	}
+
+	var second page
+	query(t, s, q, map[string]interface{}{"after": first.Trades.EndCursor}, &second)
+	if second.Trades.HasNextPage || len(second.Trades.Nodes) != 1 {
+		t.Fatalf("Expected the last page, got %+v", second.Trades)
+	}
+	if n := second.Trades.Nodes[0]; n.OpportunityID != "a" || n.Block != 5000000000 || n.PnlUSD != 25 || n.Opportunity.Decision != opportunity.DecisionExecute {
+		t.Errorf("Expected a's trade joined with its opportunity, got %+v", n)
+	}
+}
+
+func TestOpportunitiesRoutesAndMetrics(t *testing.T) {
+	s := newServer(t)
+	var out struct {
+		Opportunities struct {
+			TotalCount int
+			Nodes      []struct {
+				ID            string
+				PipelineScore *float64
+				RejectedBy    *string
+				Trade         *struct{ Success bool }
+			}
+		}
+		Routes []struct {
+			Route      string
+			Executions int
+			HitRate    float64
+			PnlUSD     float64
+		}
+		Metrics []struct {
+			Name   string
+			Labels []struct{ Name, Value string }
+			Value  float64
+		}
+	}
+	query(t, s, `{
+		opportunities(filter: {decision: "reject", since: "2026-01-02T00:30:00Z"}) {
+			totalCount nodes { id pipelineScore rejectedBy trade { success } }
+		}
+		routes(chainId: 1) { route executions hitRate pnlUsd }
+		metrics(prefix: "titan_test") { name labels { name value } value }
+	}`, nil, &out)
+
+	if out.Opportunities.TotalCount != 1 {
+		t.Fatalf("Expected only b rejected in the window, got %+v", out.Opportunities)
+	}
+	if b := out.Opportunities.Nodes[0]; b.ID != "b" || b.PipelineScore == nil || *b.PipelineScore != 0.2 || *b.RejectedBy != "ml" || b.Trade != nil {
+		t.Errorf("Expected b's breakdown without a trade, got %+v", b)
+	}
+	if len(out.Routes) != 1 || out.Routes[0].Executions != 2 || out.Routes[0].HitRate != 0.5 || out.Routes[0].PnlUSD != 21 {
+		t.Errorf("Expected the reorged trade left out of the route, got %+v", out.Routes)
+	}
+	if len(out.Metrics) != 3 || out.Metrics[0].Name != "titan_test_seconds_count" || out.Metrics[2].Labels[0].Value != "1" {
+		t.Errorf("Expected the counter and the histogram's count and sum, got %+v", out.Metrics)
+	}
+}
+
+func TestRejectsGet(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newServer(t).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected GET refused, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,212 @@
+package heartbeat
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// Default deadline and evaluation interval
+const (
+	DefaultDeadline = 3 * time.Minute
+	DefaultInterval = 30 * time.Second
+)
+
+// Check is one monitored loop's state
+type Check struct {
+	Name     string    `json:"name"`
+	LastBeat time.Time `json:"lastBeat"`
+	Healthy  bool      `json:"healthy"`
+}
+
+// Monitor tracks heartbeats from critical loops. Each interval it pings the
+// external monitor for every loop that beat within the deadline and sends a
+// single /fail ping when one falls silent, so a hung loop is caught even
+// though the process is still up; if the whole process hangs, the pings
+// stop and the monitor's own grace period catches it.
+type Monitor struct {
+	// OnFail is called once when a check misses its deadline
+	OnFail func(name string, silent time.Duration)
+
+	mu       sync.Mutex
+	checks   map[string]*Check
+	url      string
+	deadline time.Duration
+	client   *http.Client
+	age      *metrics.GaugeVec
+	now      func() time.Time
+}
+
+// New creates a monitor pinging url (empty tracks checks without pinging);
+// reg may be nil
+func New(url string, deadline time.Duration, reg *metrics.Registry) *Monitor {
+	if deadline <= 0 {
+		deadline = DefaultDeadline
+	}
+	m := &Monitor{
+		checks:   make(map[string]*Check),
+		url:      strings.TrimSuffix(url, "/"),
+		deadline: deadline,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		now:      time.Now,
+	}
+	if reg != nil {
+		m.age = reg.Gauge("titan_heartbeat_age_seconds", "Seconds since each critical loop last beat", "check")
+	}
+	return m
+}
+
+// FromConfig creates a monitor from heartbeat configuration
+func FromConfig(cfg *config.HeartbeatConfig, reg *metrics.Registry) *Monitor {
+	if cfg == nil {
+		return New("", 0, reg)
+	}
+	return New(cfg.URL, time.Duration(cfg.DeadlineSecs)*time.Second, reg)
+}
+
+// Register starts a check's deadline clock, so a loop that never beats is
+// caught too
+func (m *Monitor) Register(name string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.checks[name]; !ok {
+		m.checks[name] = &Check{Name: name, LastBeat: m.now(), Healthy: true}
+	}
+}
+
+// Beat records that a loop made progress; a nil monitor ignores it
+func (m *Monitor) Beat(name string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.checks[name]
+	if !ok {
+		c = &Check{Name: name, Healthy: true}
+		m.checks[name] = c
+	}
+	c.LastBeat = m.now()
+}
+
+// Checks returns every check, by name
+func (m *Monitor) Checks() []Check {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Check, 0, len(m.checks))
+	for _, c := range m.checks {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Run evaluates and pings every interval until ctx is cancelled
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		m.Evaluate(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evaluate marks checks past their deadline failed and pings the monitor:
+// a success ping for each healthy check and one fail ping per failure
+func (m *Monitor) Evaluate(ctx context.Context) {
+	type ping struct {
+		name   string
+		fail   bool
+		silent time.Duration
+	}
+	var pings []ping
+	m.mu.Lock()
+	now := m.now()
+	for _, c := range m.checks {
+		silent := now.Sub(c.LastBeat)
+		if m.age != nil {
+			m.age.Set(silent.Seconds(), c.Name)
+		}
+		healthy := silent <= m.deadline
+		if healthy {
+			if !c.Healthy {
+				log.Printf("💓 %s beating again", c.Name)
+			}
+			pings = append(pings, ping{name: c.Name})
+		} else if c.Healthy {
+			pings = append(pings, ping{name: c.Name, fail: true, silent: silent})
+		}
+		c.Healthy = healthy
+	}
+	m.mu.Unlock()
+
+	sort.Slice(pings, func(i, j int) bool { return pings[i].name < pings[j].name })
+	for _, p := range pings {
+		if p.fail {
+			log.Printf("💔 %s silent for %s (deadline %s)", p.name, p.silent.Round(time.Second), m.deadline)
+			if m.OnFail != nil {
+				m.OnFail(p.name, p.silent)
+			}
+		}
+		if m.url == "" {
+			continue
+		}
+		if err := m.ping(ctx, p.name, p.fail); err != nil {
+			log.Printf("⚠️ Heartbeat %s: %v", p.name, err)
+		}
+	}
+}
+
+// pingURL returns the check's ping URL, healthchecks.io style: the check name
+// replaces {check} in the URL or is appended to its path, and failures add
+// /fail to the path
+func (m *Monitor) pingURL(name string, fail bool) string {
+	raw := m.url + "/" + name
+	if strings.Contains(m.url, "{check}") {
+		raw = strings.ReplaceAll(m.url, "{check}", url.PathEscape(name))
+	}
+	if !fail {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw + "/fail"
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/fail"
+	return u.String()
+}
+
+func (m *Monitor) ping(ctx context.Context, name string, fail bool) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.pingURL(name, fail), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("ping returned %s", resp.Status)
+	}
+	return nil
+}
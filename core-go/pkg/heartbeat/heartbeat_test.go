@@ -0,0 +1,101 @@
+package heartbeat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+type pingLog struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (p *pingLog) take() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := p.paths
+	p.paths = nil
+	return out
+}
+
+func pingServer(t *testing.T) (*httptest.Server, *pingLog) {
+	t.Helper()
+	log := &pingLog{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.mu.Lock()
+		log.paths = append(log.paths, r.URL.Path)
+		log.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, log
+}
+
+func TestMonitorPingsAndFailsOnce(t *testing.T) {
+	srv, pings := pingServer(t)
+	reg := metrics.NewRegistry()
+	m := New(srv.URL+"/ping/", time.Minute, reg)
+	now := time.Unix(1_700_000_000, 0)
+	m.now = func() time.Time { return now }
+	var failed []string
+	m.OnFail = func(name string, silent time.Duration) { failed = append(failed, name) }
+
+	m.Register("prices-polygon")
+	m.Register("reconciler")
+	ctx := context.Background()
+	m.Evaluate(ctx)
+	if got := pings.take(); len(got) != 2 || got[0] != "/ping/prices-polygon" || got[1] != "/ping/reconciler" {
+		t.Errorf("Expected a success ping per check, got %v", got)
+	}
+
+	// prices keeps beating, the reconciler hangs
+	now = now.Add(2 * time.Minute)
+	m.Beat("prices-polygon")
+	m.Evaluate(ctx)
+	m.Evaluate(ctx)
+	if got := pings.take(); len(got) != 3 || got[1] != "/ping/reconciler/fail" || got[2] != "/ping/prices-polygon" {
+		t.Errorf("Expected one fail ping for the silent check, got %v", got)
+	}
+	if len(failed) != 1 || failed[0] != "reconciler" {
+		t.Errorf("Expected OnFail once for reconciler, got %v", failed)
+	}
+	if got := reg.Value("titan_heartbeat_age_seconds", "reconciler"); got != 120 {
+		t.Errorf("Expected reconciler age 120, got %v", got)
+	}
+
+	m.Beat("reconciler")
+	m.Evaluate(ctx)
+	if got := pings.take(); len(got) != 2 {
+		t.Errorf("Expected both checks pinging again after recovery, got %v", got)
+	}
+	for _, c := range m.Checks() {
+		if !c.Healthy {
+			t.Errorf("Expected %s healthy after recovery", c.Name)
+		}
+	}
+}
+
+func TestPingURLTemplate(t *testing.T) {
+	m := New("https://monitor.example/hb/{check}?token=x", 0, nil)
+	if got := m.pingURL("mevshare", false); got != "https://monitor.example/hb/mevshare?token=x" {
+		t.Errorf("Expected the check substituted, got %s", got)
+	}
+	if got := m.pingURL("mevshare", true); got != "https://monitor.example/hb/mevshare/fail?token=x" {
+		t.Errorf("Expected /fail added to the path, got %s", got)
+	}
+	m = New("https://hc-ping.com/uuid-key", 0, nil)
+	if got := m.pingURL("mevshare", true); got != "https://hc-ping.com/uuid-key/mevshare/fail" {
+		t.Errorf("Expected a slug fail URL, got %s", got)
+	}
+}
+
+func TestNilMonitorIgnoresBeats(t *testing.T) {
+	var m *Monitor
+	m.Register("executor")
+	m.Beat("executor")
+}
@@ -0,0 +1,207 @@
+package hedge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/bridge"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Order sides
+const (
+	Sell = "SELL"
+	Buy  = "BUY"
+)
+
+// Order is a market order on a perp venue
+type Order struct {
+	Market     string
+	Side       string
+	Quantity   float64 // base units, e.g. ETH
+	ReduceOnly bool
+	ClientID   string
+}
+
+// Fill is a venue's acknowledgement of an order
+type Fill struct {
+	OrderID  string
+	Quantity float64
+	Price    float64
+}
+
+// Venue places perp orders
+type Venue interface {
+	Name() string
+	Place(ctx context.Context, o Order) (*Fill, error)
+}
+
+// Position is an open hedge against one plan's in-flight transfer
+type Position struct {
+	PlanID      string    `json:"planId"`
+	Market      string    `json:"market"`
+	Quantity    float64   `json:"quantity"`
+	NotionalUSD units.USD `json:"notionalUsd"`
+	OrderID     string    `json:"orderId"`
+	Opened      time.Time `json:"opened"`
+}
+
+// Pricer returns a token's USD price with 8 decimals
+type Pricer func(chainID uint64, token common.Address) (uint64, error)
+
+// Hedger shorts the perp of each in-flight bridge transfer so price moves
+// while funds are in the bridge are offset, and closes the short when the
+// transfer lands
+type Hedger struct {
+	venue   Venue
+	price   Pricer
+	markets map[string]string
+
+	// MinNotional leaves smaller transfers unhedged, since each hedge pays
+	// taker fees twice
+	MinNotional units.USD
+
+	mu        sync.Mutex
+	positions map[string]*Position
+	now       func() time.Time
+}
+
+// NewHedger creates a hedger trading markets (token symbol to perp market)
+// on venue; transfers of tokens without a market, such as stablecoins, are
+// left unhedged
+func NewHedger(venue Venue, price Pricer, markets map[string]string) *Hedger {
+	return &Hedger{
+		venue:     venue,
+		price:     price,
+		markets:   markets,
+		positions: make(map[string]*Position),
+		now:       time.Now,
+	}
+}
+
+// FromConfig creates a hedger on the configured venue, or nil when hedging is disabled
+func FromConfig(cfg *config.HedgeConfig, price Pricer) *Hedger {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	h := NewHedger(NewBinanceFutures(cfg.BaseURL, cfg.APIKey, cfg.APISecret), price, cfg.Markets)
+	h.MinNotional = units.DollarsToUSD(cfg.MinNotionalUSD)
+	return h
+}
+
+// Sync opens hedges for plans whose transfer is in flight and closes hedges
+// whose transfer landed or whose plan is no longer tracked. It is meant to be
+// called after every tracker update or on a timer.
+func (h *Hedger) Sync(ctx context.Context, plans []bridge.Plan) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var errs []error
+	inFlight := make(map[string]bool)
+	for _, p := range plans {
+		if !p.InFlight() {
+			continue
+		}
+		inFlight[p.ID] = true
+		if _, ok := h.positions[p.ID]; ok {
+			continue
+		}
+		if err := h.open(ctx, p); err != nil {
+			errs = append(errs, fmt.Errorf("hedge %s: %w", p.ID, err))
+		}
+	}
+	for id, pos := range h.positions {
+		if inFlight[id] {
+			continue
+		}
+		if err := h.close(ctx, pos); err != nil {
+			errs = append(errs, fmt.Errorf("unhedge %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// open shorts the transfer's notional
+func (h *Hedger) open(ctx context.Context, p bridge.Plan) error {
+	token := p.Transfer.FromToken
+	market, ok := h.markets[strings.ToUpper(token.Symbol)]
+	if !ok {
+		return nil
+	}
+	priceE8, err := h.price(p.Transfer.FromChain, token.Address)
+	if err != nil {
+		return err
+	}
+	notional, err := p.Transfer.Amount.ToUSD(priceE8)
+	if err != nil {
+		return err
+	}
+	if notional < h.MinNotional {
+		return nil
+	}
+	fill, err := h.venue.Place(ctx, Order{
+		Market:   market,
+		Side:     Sell,
+		Quantity: p.Transfer.Amount.Float(),
+		ClientID: clientID(p.ID, "open"),
+	})
+	if err != nil {
+		return err
+	}
+	h.positions[p.ID] = &Position{
+		PlanID:      p.ID,
+		Market:      market,
+		Quantity:    fill.Quantity,
+		NotionalUSD: notional,
+		OrderID:     fill.OrderID,
+		Opened:      h.now(),
+	}
+	log.Printf("🛡️ Hedged %s in flight on %s: short %g %s (%s)", p.ID, h.venue.Name(), fill.Quantity, market, notional)
+	return nil
+}
+
+// close buys back the short; a failed close keeps the position for the next Sync
+func (h *Hedger) close(ctx context.Context, pos *Position) error {
+	if _, err := h.venue.Place(ctx, Order{
+		Market:     pos.Market,
+		Side:       Buy,
+		Quantity:   pos.Quantity,
+		ReduceOnly: true,
+		ClientID:   clientID(pos.PlanID, "close"),
+	}); err != nil {
+		return err
+	}
+	delete(h.positions, pos.PlanID)
+	log.Printf("🛡️ Closed hedge for %s after %s", pos.PlanID, h.now().Sub(pos.Opened).Round(time.Second))
+	return nil
+}
+
+// Positions returns the open hedges, oldest first
+func (h *Hedger) Positions() []Position {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]Position, 0, len(h.positions))
+	for _, p := range h.positions {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Opened.Before(out[j].Opened) })
+	return out
+}
+
+// clientID makes an idempotency key per plan and action, so a retried order
+// is rejected by the venue instead of doubling the hedge
+func clientID(planID, action string) string {
+	id := "titan-" + action + "-" + planID
+	if len(id) > 36 {
+		id = id[:36]
+	}
+	return id
+}
@@ -0,0 +1,117 @@
+package hedge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/bridge"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+type recordingVenue struct {
+	orders []Order
+}
+
+func (v *recordingVenue) Name() string { return "test" }
+
+func (v *recordingVenue) Place(ctx context.Context, o Order) (*Fill, error) {
+	v.orders = append(v.orders, o)
+	return &Fill{OrderID: "1", Quantity: o.Quantity}, nil
+}
+
+func transferPlan(t *testing.T, id, symbol string, whole uint64) bridge.Plan {
+	token, err := tokens.Default().Lookup(137, symbol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount, err := units.FromWhole(token.Address, whole, token.Decimals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bridge.Plan{
+		ID:       id,
+		Transfer: bridge.Request{FromChain: 137, ToChain: 56, FromToken: token, Amount: amount},
+		Source:   bridge.Leg{State: bridge.LegFilled},
+	}
+}
+
+func TestHedgerFollowsInFlightTransfers(t *testing.T) {
+	venue := &recordingVenue{}
+	price := func(chainID uint64, token common.Address) (uint64, error) { return 3000_00000000, nil }
+	h := NewHedger(venue, price, map[string]string{"WETH": "ETHUSDT"})
+	h.MinNotional = units.DollarsToUSD(1000)
+
+	weth := transferPlan(t, "p1", "WETH", 2)
+	small := transferPlan(t, "p2", "WETH", 0)
+	stable := transferPlan(t, "p3", "USDC", 50_000)
+	if err := h.Sync(context.Background(), []bridge.Plan{weth, small, stable}); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(venue.orders) != 1 || venue.orders[0].Side != Sell || venue.orders[0].Quantity != 2 {
+		t.Fatalf("Expected one 2 ETH short, got %+v", venue.orders)
+	}
+	if pos := h.Positions(); len(pos) != 1 || pos[0].NotionalUSD != units.DollarsToUSD(6000) {
+		t.Errorf("Expected a $6000 position, got %+v", pos)
+	}
+
+	// Still in flight: no new orders
+	if err := h.Sync(context.Background(), []bridge.Plan{weth}); err != nil || len(venue.orders) != 1 {
+		t.Errorf("Expected the open hedge to be kept, got %d orders, %v", len(venue.orders), err)
+	}
+
+	weth.Arrived = true
+	if err := h.Sync(context.Background(), []bridge.Plan{weth}); err != nil {
+		t.Fatal(err)
+	}
+	if len(venue.orders) != 2 || venue.orders[1].Side != Buy || !venue.orders[1].ReduceOnly {
+		t.Errorf("Expected a reduce-only buy to close, got %+v", venue.orders)
+	}
+	if len(h.Positions()) != 0 {
+		t.Errorf("Expected no open hedges, got %+v", h.Positions())
+	}
+}
+
+func TestBinanceSignsOrders(t *testing.T) {
+	var form url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		form, _ = url.ParseQuery(string(body))
+		query, sig, _ := strings.Cut(string(body), "&signature=")
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(query))
+		if r.URL.Path != "/fapi/v1/order" || r.Header.Get("X-MBX-APIKEY") != "key" || sig != hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":-1022,"msg":"Signature for this request is not valid."}`))
+			return
+		}
+		w.Write([]byte(`{"orderId":42,"executedQty":"1.234","avgPrice":"3001.5"}`))
+	}))
+	defer srv.Close()
+
+	venue := NewBinanceFutures(srv.URL, "key", "secret")
+	fill, err := venue.Place(context.Background(), Order{Market: "ETHUSDT", Side: Sell, Quantity: 1.23456, ClientID: "titan-open-p1"})
+	if err != nil {
+		t.Fatalf("Place failed: %v", err)
+	}
+	if fill.OrderID != "42" || fill.Quantity != 1.234 || fill.Price != 3001.5 {
+		t.Errorf("Expected order 42 for 1.234 at 3001.5, got %+v", fill)
+	}
+	if form.Get("quantity") != "1.234" || form.Get("type") != "MARKET" || form.Get("reduceOnly") != "" {
+		t.Errorf("Expected a truncated market order, got %v", form)
+	}
+
+	venue = NewBinanceFutures(srv.URL, "key", "wrong")
+	if _, err := venue.Place(context.Background(), Order{Market: "ETHUSDT", Side: Buy, Quantity: 1}); err == nil || !strings.Contains(err.Error(), "-1022") {
+		t.Errorf("Expected signature rejection, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package hf
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+)
+
+// Reading is a token's news sentiment over the board's window
+type Reading struct {
+	Token      string    `json:"token"`
+	Score      float64   `json:"score"`      // mean polarity of confident headlines
+	Confidence float64   `json:"confidence"` // mean confidence of those headlines
+	Headlines  int       `json:"headlines"`
+	Updated    time.Time `json:"updated"`
+
+	at time.Time // Updated with its monotonic reading, for the window
+}
+
+// Board keeps each token's sentiment from the headlines ingested about it,
+// so scoring reads cached features instead of calling the endpoint on the
+// hot path. Readings older than the window are dropped, and headlines the
+// model isn't confident about are ignored. A nil board has no readings.
+type Board struct {
+	client   *Client
+	registry *tokens.Registry
+	window   time.Duration
+
+	mu       sync.Mutex
+	readings map[string]*Reading // by upper-case symbol
+
+	now func() time.Time
+}
+
+// NewBoard creates a board scoring headlines with client and resolving
+// opportunity tokens through registry
+func NewBoard(client *Client, registry *tokens.Registry, window time.Duration) *Board {
+	return &Board{client: client, registry: registry, window: window, readings: make(map[string]*Reading), now: time.Now}
+}
+
+// Ingest scores a headline and folds it into the readings of the tokens it
+// mentions, by symbol
+func (b *Board) Ingest(ctx context.Context, symbols []string, text string) (Sentiment, error) {
+	if b == nil {
+		return Sentiment{}, ErrUnavailable
+	}
+	s, err := b.client.Sentiment(ctx, text)
+	if err != nil || !s.Confident {
+		return s, err
+	}
+	now := b.now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		r := b.readings[symbol]
+		if r == nil || now.Sub(r.at) > b.window {
+			r = &Reading{Token: symbol}
+			b.readings[symbol] = r
+		}
+		n := float64(r.Headlines)
+		r.Score = (r.Score*n + s.Score) / (n + 1)
+		r.Confidence = (r.Confidence*n + s.Confidence) / (n + 1)
+		r.Headlines++
+		r.Updated, r.at = now.UTC(), now
+	}
+	return s, nil
+}
+
+// Features returns the news features of the tokens o trades: the mean
+// sentiment and confidence of those with fresh readings, or nil when none
+// have; it has the signature of experiment.Experiment's Signals
+func (b *Board) Features(o *opportunity.Opportunity) map[string]float64 {
+	if b == nil || o.Explanation == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var score, confidence float64
+	var n int
+	now := b.now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, leg := range o.Explanation.Legs {
+		token, ok := b.registry.ByAddress(o.ChainID, leg.TokenIn)
+		if !ok {
+			continue
+		}
+		symbol := strings.ToUpper(token.Symbol)
+		r := b.readings[symbol]
+		if seen[symbol] || r == nil || now.Sub(r.at) > b.window {
+			continue
+		}
+		seen[symbol] = true
+		score += r.Score
+		confidence += r.Confidence
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	return map[string]float64{
+		"news_sentiment":  score / float64(n),
+		"news_confidence": confidence / float64(n),
+	}
+}
+
+// Readings returns the fresh readings, most recently updated first
+func (b *Board) Readings() []Reading {
+	if b == nil {
+		return nil
+	}
+	now := b.now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]Reading, 0, len(b.readings))
+	for _, r := range b.readings {
+		if now.Sub(r.at) <= b.window {
+			out = append(out, *r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Updated.After(out[j].Updated) })
+	return out
+}
@@ -0,0 +1,179 @@
+package hf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// cooldown is how long the client stops calling an endpoint that failed
+const cooldown = 30 * time.Second
+
+// ErrUnavailable is returned while the endpoint is failing or cooling down
+var ErrUnavailable = errors.New("hf: endpoint unavailable")
+
+// Label is one class a text-classification model assigned
+type Label struct {
+	Label string  `json:"label"`
+	Score float64 `json:"score"`
+}
+
+// Sentiment is a text's polarity as read from a sentiment classifier
+type Sentiment struct {
+	Score      float64 `json:"score"`      // P(positive) - P(negative), in [-1, 1]
+	Label      string  `json:"label"`      // most likely class
+	Confidence float64 `json:"confidence"` // its probability
+	Confident  bool    `json:"confident"`  // confidence cleared HF_CONFIDENCE_THRESHOLD
+}
+
+// Client calls a HuggingFace inference endpoint hosting a text-classification
+// model, such as a financial news sentiment model. Scoring is best effort: a
+// failing or slow endpoint puts the client in a short cooldown during which
+// calls return ErrUnavailable at once, so callers carry on without the
+// features rather than wait on it. A nil client is always unavailable.
+type Client struct {
+	url       string
+	token     string
+	threshold float64
+	client    *http.Client
+
+	mu        sync.Mutex
+	downUntil time.Time
+
+	now      func() time.Time
+	requests *metrics.CounterVec
+}
+
+// New creates a client for the endpoint at url; reg may be nil
+func New(url, token string, timeout time.Duration, threshold float64, reg *metrics.Registry) *Client {
+	c := &Client{
+		url:       url,
+		token:     token,
+		threshold: threshold,
+		client:    &http.Client{Timeout: timeout},
+		now:       time.Now,
+	}
+	if reg != nil {
+		c.requests = reg.Counter("titan_hf_requests_total", "HuggingFace inference calls, by result", "result")
+	}
+	return c
+}
+
+// FromConfig creates the configured client, or nil without an endpoint
+func FromConfig(cfg *config.AIConfig, reg *metrics.Registry) *Client {
+	if cfg == nil || cfg.HFEndpointURL == "" {
+		return nil
+	}
+	return New(cfg.HFEndpointURL, cfg.HFToken, time.Duration(cfg.HFTimeoutMs)*time.Millisecond, cfg.HFConfidenceThreshold, reg)
+}
+
+// Available reports whether the client will call the endpoint
+func (c *Client) Available() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.now().Before(c.downUntil)
+}
+
+// Classify returns the labels the model assigns text
+func (c *Client) Classify(ctx context.Context, text string) ([]Label, error) {
+	if !c.Available() {
+		c.count("skipped")
+		return nil, ErrUnavailable
+	}
+	labels, err := c.classify(ctx, text)
+	if err != nil {
+		c.mu.Lock()
+		first := !c.now().Before(c.downUntil)
+		c.downUntil = c.now().Add(cooldown)
+		c.mu.Unlock()
+		if first {
+			log.Printf("⚠️ HuggingFace endpoint unavailable, retrying in %s: %v", cooldown, err)
+		}
+		c.count("error")
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	c.count("ok")
+	return labels, nil
+}
+
+func (c *Client) classify(ctx context.Context, text string) ([]Label, error) {
+	body, err := json.Marshal(map[string]string{"inputs": text})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// a scaled-to-zero endpoint answers 503 while the model loads
+		return nil, fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	// Endpoints answer [[labels]] for a single input, older ones [labels]
+	var nested [][]Label
+	if err := json.Unmarshal(raw, &nested); err == nil && len(nested) == 1 {
+		return nested[0], nil
+	}
+	var labels []Label
+	if err := json.Unmarshal(raw, &labels); err != nil {
+		return nil, fmt.Errorf("unexpected response: %w", err)
+	}
+	return labels, nil
+}
+
+// Sentiment classifies text and reduces the labels to a polarity; labels
+// are matched by name, so positive/negative/neutral and bullish/bearish
+// models both work
+func (c *Client) Sentiment(ctx context.Context, text string) (Sentiment, error) {
+	labels, err := c.Classify(ctx, text)
+	if err != nil {
+		return Sentiment{}, err
+	}
+	var s Sentiment
+	for _, l := range labels {
+		switch name := strings.ToLower(l.Label); {
+		case strings.HasPrefix(name, "pos"), name == "bullish":
+			s.Score += l.Score
+		case strings.HasPrefix(name, "neg"), name == "bearish":
+			s.Score -= l.Score
+		}
+		if l.Score > s.Confidence {
+			s.Label, s.Confidence = l.Label, l.Score
+		}
+	}
+	s.Confident = s.Confidence >= c.threshold
+	return s, nil
+}
+
+func (c *Client) count(result string) {
+	if c != nil && c.requests != nil {
+		c.requests.Inc(result)
+	}
+}
@@ -0,0 +1,105 @@
+package hf
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+)
+
+func endpoint(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSentiment(t *testing.T) {
+	srv := endpoint(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer hf_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[[{"label": "negative", "score": 0.9}, {"label": "neutral", "score": 0.07}, {"label": "positive", "score": 0.03}]]`))
+	})
+	c := New(srv.URL, "hf_test", time.Second, 0.8, nil)
+	s, err := c.Sentiment(context.Background(), "Bridge exploit drains pool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(s.Score+0.87) > 1e-9 || s.Label != "negative" || !s.Confident {
+		t.Errorf("Expected a confident -0.87, got %+v", s)
+	}
+	c.threshold = 0.95
+	if s, _ := c.Sentiment(context.Background(), "Bridge exploit drains pool"); s.Confident {
+		t.Errorf("Expected 0.9 below a 0.95 threshold, got %+v", s)
+	}
+}
+
+func TestDegradesWhenUnavailable(t *testing.T) {
+	reg := metrics.NewRegistry()
+	var calls int32
+	srv := endpoint(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.Write([]byte(`[{"label": "bullish", "score": 0.99}]`))
+	})
+	c := New(srv.URL, "", 20*time.Millisecond, 0.8, reg)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	if _, err := c.Sentiment(context.Background(), "ETF approved"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Expected a slow endpoint to time out, got %v", err)
+	}
+	if _, err := c.Sentiment(context.Background(), "ETF approved"); !errors.Is(err, ErrUnavailable) || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected the endpoint skipped while cooling down, got %v after %d calls", err, atomic.LoadInt32(&calls))
+	}
+	if reg.Value("titan_hf_requests_total", "skipped") != 1 {
+		t.Errorf("Expected the skipped call counted")
+	}
+
+	now = now.Add(cooldown)
+	if s, err := c.Sentiment(context.Background(), "ETF approved"); err != nil || s.Score != 0.99 {
+		t.Errorf("Expected the endpoint retried after the cooldown, got %+v, %v", s, err)
+	}
+	if _, err := (*Client)(nil).Sentiment(context.Background(), "x"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Expected a nil client unavailable, got %v", err)
+	}
+}
+
+func TestBoardFeatures(t *testing.T) {
+	srv := endpoint(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[[{"label": "positive", "score": 0.85}, {"label": "negative", "score": 0.15}]]`))
+	})
+	weth, pepe := titantest.Address(1), titantest.Address(2)
+	registry := tokens.NewRegistry()
+	registry.Add(tokens.Token{ChainID: 1, Symbol: "WETH", Address: weth, Decimals: 18})
+	registry.Add(tokens.Token{ChainID: 1, Symbol: "PEPE", Address: pepe, Decimals: 18})
+	b := NewBoard(New(srv.URL, "", time.Second, 0.8, nil), registry, time.Hour)
+
+	if _, err := b.Ingest(context.Background(), []string{"pepe"}, "PEPE listed on a major exchange"); err != nil {
+		t.Fatal(err)
+	}
+	o := &opportunity.Opportunity{ChainID: 1, Explanation: &opportunity.Explanation{Legs: []opportunity.Leg{
+		{TokenIn: weth, TokenOut: pepe}, {TokenIn: pepe, TokenOut: weth},
+	}}}
+	f := b.Features(o)
+	if math.Abs(f["news_sentiment"]-0.7) > 1e-9 || f["news_confidence"] != 0.85 {
+		t.Errorf("Expected PEPE's 0.7 sentiment, got %v", f)
+	}
+
+	b.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if f := b.Features(o); f != nil || len(b.Readings()) != 0 {
+		t.Errorf("Expected stale readings dropped, got %v", f)
+	}
+}
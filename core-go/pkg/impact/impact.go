@@ -0,0 +1,147 @@
+package impact
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/pathfind"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// MaxPoints bounds the sizes sampled for one curve
+const MaxPoints = 200
+
+// ErrBrokenPath is returned when a pool does not trade the previous hop's
+// output token
+var ErrBrokenPath = errors.New("impact: pools do not form a path")
+
+// Point is the route's output at one size
+type Point struct {
+	AmountIn  units.Amount `json:"amountIn"`
+	AmountOut units.Amount `json:"amountOut"`
+	// Price is the average tokenOut received per tokenIn at this size
+	Price float64 `json:"price"`
+	// MarginalPrice is the tokenOut received per tokenIn for the next
+	// sliver of size, the slope of the output curve
+	MarginalPrice float64 `json:"marginalPrice"`
+	// ImpactBps is how far Price falls below the spot price, fees included
+	ImpactBps float64 `json:"impactBps"`
+}
+
+// Curve is a route's output across evenly spaced sizes, for sizing charts
+type Curve struct {
+	ChainID   uint64           `json:"chainId"`
+	TokenIn   tokens.Token     `json:"tokenIn"`
+	TokenOut  tokens.Token     `json:"tokenOut"`
+	Pools     []common.Address `json:"pools"`
+	SpotPrice float64          `json:"spotPrice"` // tokenOut per tokenIn before fees and slippage
+	Points    []Point          `json:"points"`
+}
+
+// Path chains snapshots into a route starting from tokenIn, each pool
+// swapping the previous pool's output, and returns the route's output token
+func Path(snapshots []*reserves.Snapshot, tokenIn common.Address) (pathfind.Route, common.Address, error) {
+	if len(snapshots) == 0 {
+		return nil, common.Address{}, fmt.Errorf("%w: no pools", ErrBrokenPath)
+	}
+	route := make(pathfind.Route, len(snapshots))
+	token := tokenIn
+	for i, s := range snapshots {
+		var out common.Address
+		switch token {
+		case s.Token0:
+			out = s.Token1
+		case s.Token1:
+			out = s.Token0
+		default:
+			return nil, common.Address{}, fmt.Errorf("%w: pool %s does not trade %s", ErrBrokenPath, s.Pool.Hex(), token.Hex())
+		}
+		route[i] = pathfind.Hop{Pool: s.Pool, TokenIn: token, TokenOut: out, ZeroForOne: s.ZeroForOne(token), State: s.AMM()}
+		token = out
+	}
+	return route, token, nil
+}
+
+// Build samples r at points evenly spaced sizes up to maxIn, using local AMM
+// math. The marginal price at each size is the output of a further 0.1% of
+// the step between sizes, so it tracks the slope without a closed form per
+// pool kind.
+func Build(chainID uint64, r pathfind.Route, tokenIn, tokenOut tokens.Token, maxIn units.Amount, points int) (*Curve, error) {
+	if points < 1 || points > MaxPoints {
+		return nil, fmt.Errorf("impact: %d points, need 1 to %d", points, MaxPoints)
+	}
+	maxRaw := maxIn.Big()
+	step := new(big.Int).Quo(maxRaw, big.NewInt(int64(points)))
+	if step.Sign() == 0 {
+		return nil, fmt.Errorf("impact: %s %s is too small to split into %d sizes", maxIn, tokenIn.Symbol, points)
+	}
+	delta := new(big.Int).Quo(step, big.NewInt(1000))
+	if delta.Sign() == 0 {
+		delta.SetInt64(1)
+	}
+	scale := math.Pow10(int(tokenIn.Decimals) - int(tokenOut.Decimals))
+	curve := &Curve{ChainID: chainID, TokenIn: tokenIn, TokenOut: tokenOut, SpotPrice: spotPrice(r) * scale}
+	for _, hop := range r {
+		curve.Pools = append(curve.Pools, hop.Pool)
+	}
+	for i := 1; i <= points; i++ {
+		in := new(big.Int).Mul(step, big.NewInt(int64(i)))
+		if i == points {
+			in.Set(maxRaw)
+		}
+		out, err := r.Quote(in)
+		if err != nil {
+			return nil, fmt.Errorf("impact: quoting %s: %w", in, err)
+		}
+		next, err := r.Quote(new(big.Int).Add(in, delta))
+		if err != nil {
+			return nil, fmt.Errorf("impact: quoting %s: %w", in, err)
+		}
+		amountIn, err := units.FromBig(tokenIn.Address, in, tokenIn.Decimals)
+		if err != nil {
+			return nil, err
+		}
+		amountOut, err := units.FromBig(tokenOut.Address, out, tokenOut.Decimals)
+		if err != nil {
+			return nil, err
+		}
+		p := Point{
+			AmountIn:      amountIn,
+			AmountOut:     amountOut,
+			Price:         ratio(out, in) * scale,
+			MarginalPrice: ratio(new(big.Int).Sub(next, out), delta) * scale,
+		}
+		if curve.SpotPrice > 0 {
+			p.ImpactBps = math.Max(0, (1-p.Price/curve.SpotPrice)*10000)
+		}
+		curve.Points = append(curve.Points, p)
+	}
+	return curve, nil
+}
+
+// spotPrice multiplies each hop's reserve ratio, in raw units
+func spotPrice(r pathfind.Route) float64 {
+	price := 1.0
+	for _, hop := range r {
+		in, out := hop.State.Reserve0, hop.State.Reserve1
+		if !hop.ZeroForOne {
+			in, out = out, in
+		}
+		if in == nil || out == nil || in.Sign() == 0 {
+			return 0
+		}
+		price *= ratio(out, in)
+	}
+	return price
+}
+
+// ratio returns num/den as a float
+func ratio(num, den *big.Int) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(num), new(big.Float).SetInt(den)).Float64()
+	return f
+}
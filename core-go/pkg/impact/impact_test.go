@@ -0,0 +1,69 @@
+package impact
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+var (
+	usdc = tokens.Token{Symbol: "USDC", Address: titantest.Address(1), Decimals: 6}
+	weth = tokens.Token{Symbol: "WETH", Address: titantest.Address(2), Decimals: 18}
+	dai  = tokens.Token{Symbol: "DAI", Address: titantest.Address(3), Decimals: 18}
+)
+
+func TestBuildTracesOutputCurve(t *testing.T) {
+	// $2M against 1000 WETH, then WETH back into a deep DAI pool
+	pools := []*reserves.Snapshot{
+		{Pool: titantest.Address(0xa1), Token0: usdc.Address, Token1: weth.Address, Reserve0: big.NewInt(2_000_000_000_000), Reserve1: titantest.Units(1000, 18), FeeBps: 30},
+		{Pool: titantest.Address(0xa2), Token0: dai.Address, Token1: weth.Address, Reserve0: titantest.Units(200_000_000, 18), Reserve1: titantest.Units(100_000, 18), FeeBps: 5},
+	}
+	r, out, err := Path(pools, usdc.Address)
+	if err != nil || out != dai.Address {
+		t.Fatalf("Expected a USDC>WETH>DAI path, got %s (%v)", out.Hex(), err)
+	}
+	maxIn, _ := units.FromWhole(usdc.Address, 200_000, 6)
+	curve, err := Build(137, r, usdc, dai, maxIn, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(curve.Points) != 4 || curve.Points[3].AmountIn.String() != "200000" {
+		t.Fatalf("Expected 4 sizes up to 200000, got %+v", curve.Points)
+	}
+	if math.Abs(curve.SpotPrice-1) > 1e-9 {
+		t.Errorf("Expected a spot price of 1 DAI per USDC, got %f", curve.SpotPrice)
+	}
+	prev := curve.Points[0]
+	if prev.ImpactBps < 35 || prev.ImpactBps > 300 {
+		t.Errorf("Expected the smallest size to pay about the fees plus slippage, got %.1f bps", prev.ImpactBps)
+	}
+	for _, p := range curve.Points[1:] {
+		if p.Price >= prev.Price || p.MarginalPrice >= p.Price || p.ImpactBps <= prev.ImpactBps {
+			t.Errorf("Expected price to fall and impact to grow with size, got %+v after %+v", p, prev)
+		}
+		prev = p
+	}
+	// $200k out of $2M costs roughly 9% on the first pool
+	if last := curve.Points[3]; last.ImpactBps < 900 || last.ImpactBps > 1000 {
+		t.Errorf("Expected about 9%% impact at the largest size, got %.1f bps", last.ImpactBps)
+	}
+}
+
+func TestPathRejectsDisconnectedPools(t *testing.T) {
+	pools := []*reserves.Snapshot{
+		{Pool: titantest.Address(0xa1), Token0: usdc.Address, Token1: weth.Address},
+		{Pool: titantest.Address(0xa2), Token0: usdc.Address, Token1: dai.Address},
+	}
+	if _, _, err := Path(pools, usdc.Address); !errors.Is(err, ErrBrokenPath) {
+		t.Errorf("Expected ErrBrokenPath, got %v", err)
+	}
+	if _, _, err := Path(nil, usdc.Address); !errors.Is(err, ErrBrokenPath) {
+		t.Errorf("Expected ErrBrokenPath for no pools, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/seal"
+)
+
+// Entry kinds written by the core
+const (
+	KindOpportunity = "opportunity"
+	KindExecution   = "execution"
+	KindReversal    = "reversal"
+	KindAudit       = "audit"
+	KindChange      = "change"
+	KindCost        = "cost"
+	KindRollup      = "rollup"
+	KindProvider    = "provider"
+	KindBridge      = "bridge"
+	KindAdmin       = "admin"
+)
+
+// Entry is a single journal record
+type Entry struct {
+	Time time.Time       `json:"ts"`
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Journal is an append-only JSON-lines log of decisions and outcomes
+type Journal struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	keys *seal.Keyring
+}
+
+// Open opens (or creates) a journal file for appending
+func Open(path string) (*Journal, error) {
+	return OpenSealed(path, nil)
+}
+
+// OpenSealed opens a journal whose new lines are sealed with keys; a nil
+// keyring writes plaintext
+func OpenSealed(path string, keys *seal.Keyring) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal %s: %w", path, err)
+	}
+	return &Journal{path: path, file: f, keys: keys}, nil
+}
+
+// Append writes one record
+func (j *Journal) Append(kind string, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(Entry{Time: time.Now().UTC(), Kind: kind, Data: raw})
+	if err != nil {
+		return err
+	}
+	if line, err = j.keys.Seal(line); err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// Path returns the journal file path
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// ReadAll reads every entry from a plaintext journal file, optionally
+// filtered by kind
+func ReadAll(path string, kind string) ([]Entry, error) {
+	return ReadSealed(path, kind, nil)
+}
+
+// ReadSealed reads every entry from a journal file, opening sealed lines with
+// keys. Plaintext lines from before encryption was enabled are read as-is.
+func ReadSealed(path string, kind string, keys *seal.Keyring) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line, err := keys.Open(scanner.Bytes())
+		if errors.Is(err, seal.ErrNoKey) {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		} else if err != nil {
+			continue // tolerate a torn final line after a crash
+		}
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+		if kind == "" || e.Kind == kind {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// Reseal rewrites a journal file with every line sealed under keys' primary
+// key, or in plaintext when keys is nil. It rotates keys and encrypts
+// existing journals; run it while nothing is appending to the file.
+func Reseal(path string, keys *seal.Keyring) (int, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+	tmp := path + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp)
+	defer out.Close()
+
+	n, lineNo, torn := 0, 0, 0
+	w := bufio.NewWriter(out)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lineNo++
+		if torn > 0 {
+			return 0, fmt.Errorf("%s line %d: %w", path, torn, seal.ErrCorrupt)
+		}
+		line, err := keys.Open(scanner.Bytes())
+		if errors.Is(err, seal.ErrCorrupt) {
+			torn = lineNo // only the final line may be torn by a crash
+			continue
+		} else if err != nil {
+			return 0, fmt.Errorf("%s line %d: %w", path, lineNo, err)
+		}
+		if line, err = keys.Seal(line); err != nil {
+			return 0, err
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if err := w.Flush(); err != nil {
+		return 0, err
+	}
+	if err := out.Sync(); err != nil {
+		return 0, err
+	}
+	if err := out.Close(); err != nil {
+		return 0, err
+	}
+	return n, os.Rename(tmp, path)
+}
@@ -0,0 +1,70 @@
+package journal
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/seal"
+)
+
+func keyring(t *testing.T, spec string) *seal.Keyring {
+	t.Helper()
+	k, err := seal.ParseKeyring(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestSealedJournalAndReseal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	plain, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plain.Append(KindExecution, map[string]string{"tx": "0xplain"}); err != nil {
+		t.Fatal(err)
+	}
+	plain.Close()
+
+	oldKey, _ := seal.GenerateKey()
+	newKey, _ := seal.GenerateKey()
+	keys := keyring(t, "a:"+oldKey)
+	j, err := OpenSealed(path, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Append(KindExecution, map[string]string{"tx": "0xsealed"}); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+
+	raw, _ := os.ReadFile(path)
+	if bytes.Contains(raw, []byte("0xsealed")) {
+		t.Errorf("Expected the new entry encrypted on disk")
+	}
+	entries, err := ReadSealed(path, KindExecution, keys)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Expected plaintext and sealed entries read, got %d (%v)", len(entries), err)
+	}
+	if _, err := ReadAll(path, ""); !errors.Is(err, seal.ErrNoKey) {
+		t.Errorf("Expected ErrNoKey reading a sealed journal without keys, got %v", err)
+	}
+
+	// Rotate: b seals, a still opens; after resealing a is no longer needed
+	rotated := keyring(t, "b:"+newKey+",a:"+oldKey)
+	if n, err := Reseal(path, rotated); err != nil || n != 2 {
+		t.Fatalf("Expected 2 lines resealed, got %d (%v)", n, err)
+	}
+	raw, _ = os.ReadFile(path)
+	if bytes.Contains(raw, []byte("0xplain")) {
+		t.Errorf("Expected old plaintext entries encrypted by reseal")
+	}
+	entries, err = ReadSealed(path, "", keyring(t, "b:"+newKey))
+	if err != nil || len(entries) != 2 {
+		t.Errorf("Expected entries readable with only the new key, got %d (%v)", len(entries), err)
+	}
+}
@@ -0,0 +1,155 @@
+package leader
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EtcdLock is a Lock on an etcd key bound to a lease, spoken over the v3
+// JSON gateway so no gRPC client is needed. The key is written only if it
+// doesn't exist and disappears with the lease.
+type EtcdLock struct {
+	endpoint string
+	key      string
+	client   *http.Client
+
+	mu    sync.Mutex
+	lease string // lease ID held by this instance, empty when not leader
+}
+
+// NewEtcdLock creates a lock on key behind the gateway at endpoint, e.g.
+// http://etcd:2379
+func NewEtcdLock(endpoint, key string) *EtcdLock {
+	return &EtcdLock{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		key:      key,
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Acquire grants a lease and creates the key on it in one transaction
+func (l *EtcdLock) Acquire(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	var grant struct {
+		ID string `json:"ID"`
+	}
+	if err := l.call(ctx, "/v3/lease/grant", map[string]interface{}{"TTL": seconds(ttl)}, &grant); err != nil {
+		return false, err
+	}
+	var txn struct {
+		Succeeded bool `json:"succeeded"`
+	}
+	err := l.call(ctx, "/v3/kv/txn", map[string]interface{}{
+		"compare": []map[string]string{{"target": "CREATE", "key": b64(l.key), "create_revision": "0"}},
+		"success": []map[string]interface{}{{"request_put": map[string]string{"key": b64(l.key), "value": b64(id), "lease": grant.ID}}},
+	}, &txn)
+	if err != nil || !txn.Succeeded {
+		l.call(ctx, "/v3/lease/revoke", map[string]string{"ID": grant.ID}, nil)
+		return false, err
+	}
+	l.mu.Lock()
+	l.lease = grant.ID
+	l.mu.Unlock()
+	return true, nil
+}
+
+// Renew keeps the lease alive; an expired lease has taken the key with it
+func (l *EtcdLock) Renew(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	lease := l.lease
+	l.mu.Unlock()
+	if lease == "" {
+		return false, nil
+	}
+	var resp struct {
+		Result struct {
+			TTL string `json:"TTL"`
+		} `json:"result"`
+	}
+	if err := l.call(ctx, "/v3/lease/keepalive", map[string]string{"ID": lease}, &resp); err != nil {
+		return false, err
+	}
+	if left, _ := strconv.ParseInt(resp.Result.TTL, 10, 64); left <= 0 {
+		l.mu.Lock()
+		l.lease = ""
+		l.mu.Unlock()
+		return false, nil
+	}
+	return true, nil
+}
+
+// Release revokes the lease, deleting the key
+func (l *EtcdLock) Release(ctx context.Context, id string) error {
+	l.mu.Lock()
+	lease := l.lease
+	l.lease = ""
+	l.mu.Unlock()
+	if lease == "" {
+		return nil
+	}
+	return l.call(ctx, "/v3/lease/revoke", map[string]string{"ID": lease}, nil)
+}
+
+// Holder reads the key
+func (l *EtcdLock) Holder(ctx context.Context) (string, error) {
+	var resp struct {
+		KVs []struct {
+			Value string `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := l.call(ctx, "/v3/kv/range", map[string]string{"key": b64(l.key)}, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.KVs) == 0 {
+		return "", nil
+	}
+	id, err := base64.StdEncoding.DecodeString(resp.KVs[0].Value)
+	return string(id), err
+}
+
+// call POSTs req as JSON to a gateway path and decodes the reply into out
+func (l *EtcdLock) call(ctx context.Context, path string, req, out interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, l.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := l.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return fmt.Errorf("etcd %s: HTTP %d %s", path, resp.StatusCode, e.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func seconds(d time.Duration) int64 {
+	if s := int64(d / time.Second); s > 0 {
+		return s
+	}
+	return 1
+}
@@ -0,0 +1,184 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// Lock is a lease held by at most one instance at a time, expiring after
+// its TTL unless renewed
+type Lock interface {
+	// Acquire takes the lock for id if nobody holds it
+	Acquire(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	// Renew extends id's hold, returning false if the lock was lost
+	Renew(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	// Release gives the lock up if id holds it
+	Release(ctx context.Context, id string) error
+	// Holder returns the current holder's ID, empty when free
+	Holder(ctx context.Context) (string, error)
+}
+
+// Elector campaigns for a Lock and keeps it renewed. Standbys poll at the
+// renewal interval, so one takes over within about TTL of the leader dying.
+type Elector struct {
+	lock Lock
+	id   string
+	ttl  time.Duration
+
+	// RenewEvery is how often the leader renews and standbys campaign
+	RenewEvery time.Duration
+
+	leader    atomic.Bool
+	lastRenew time.Time
+	lastErr   string
+
+	mu        sync.Mutex
+	listeners []func(leader bool)
+
+	gauge *metrics.GaugeVec
+	now   func() time.Time
+}
+
+// NewElector creates an elector for instance id; reg may be nil to skip metrics
+func NewElector(lock Lock, id string, ttl time.Duration, reg *metrics.Registry) *Elector {
+	e := &Elector{lock: lock, id: id, ttl: ttl, RenewEvery: ttl / 3, now: time.Now}
+	if reg != nil {
+		e.gauge = reg.Gauge("titan_leader", "1 while this instance holds the leader lock", "instance")
+		e.gauge.Set(0, id)
+	}
+	return e
+}
+
+// FromConfig creates the configured elector, or nil when election is disabled
+func FromConfig(cfg *config.LeaderConfig, reg *metrics.Registry) (*Elector, error) {
+	if cfg == nil || cfg.Backend == "" {
+		return nil, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	id := cfg.InstanceID
+	if id == "" {
+		host, _ := os.Hostname()
+		id = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	var lock Lock
+	switch cfg.Backend {
+	case config.LeaderRedis:
+		l, err := NewRedisLock(cfg.URL, cfg.Key)
+		if err != nil {
+			return nil, err
+		}
+		lock = l
+	case config.LeaderEtcd:
+		lock = NewEtcdLock(cfg.URL, cfg.Key)
+	}
+	return NewElector(lock, id, time.Duration(cfg.TTLSecs)*time.Second, reg), nil
+}
+
+// ID returns this instance's ID
+func (e *Elector) ID() string { return e.id }
+
+// IsLeader reports whether this instance currently holds the lock
+func (e *Elector) IsLeader() bool { return e.leader.Load() }
+
+// Holder returns the current leader's ID as seen by the lock backend
+func (e *Elector) Holder(ctx context.Context) (string, error) {
+	return e.lock.Holder(ctx)
+}
+
+// OnChange registers fn to run whenever leadership is gained or lost
+func (e *Elector) OnChange(fn func(leader bool)) {
+	e.mu.Lock()
+	e.listeners = append(e.listeners, fn)
+	e.mu.Unlock()
+}
+
+// Run campaigns and renews until ctx is cancelled, then releases the lock
+// so a standby can take over at once
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.RenewEvery)
+	defer ticker.Stop()
+	for {
+		e.step(ctx)
+		select {
+		case <-ctx.Done():
+			if e.IsLeader() {
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+				if err := e.lock.Release(releaseCtx, e.id); err != nil {
+					log.Printf("⚠️ Leader release: %v", err)
+				}
+				cancel()
+				e.set(false)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// step runs one renewal as leader or one campaign as standby
+func (e *Elector) step(ctx context.Context) {
+	now := e.now()
+	if !e.IsLeader() {
+		ok, err := e.lock.Acquire(ctx, e.id, e.ttl)
+		if e.report(err); ok {
+			e.lastRenew = now
+			e.set(true)
+		}
+		return
+	}
+	ok, err := e.lock.Renew(ctx, e.id, e.ttl)
+	e.report(err)
+	switch {
+	case err == nil && ok:
+		e.lastRenew = now
+	case err == nil:
+		log.Printf("⚠️ Leader lock lost by %s", e.id)
+		e.set(false)
+	case now.Sub(e.lastRenew) >= e.ttl-e.RenewEvery:
+		// The lease may expire before the backend is reachable again; stop
+		// executing before a standby can take over rather than after
+		log.Printf("⚠️ Leader %s stepping down: lock unrenewed for %s", e.id, now.Sub(e.lastRenew))
+		e.set(false)
+	}
+}
+
+// report logs backend errors once each rather than on every tick
+func (e *Elector) report(err error) {
+	msg := ""
+	if err != nil {
+		msg = err.Error()
+	}
+	if msg != "" && msg != e.lastErr {
+		log.Printf("❌ Leader election: %v", err)
+	}
+	e.lastErr = msg
+}
+
+func (e *Elector) set(leader bool) {
+	if e.leader.Swap(leader) == leader {
+		return
+	}
+	if e.gauge != nil {
+		v := 0.0
+		if leader {
+			v = 1
+		}
+		e.gauge.Set(v, e.id)
+	}
+	e.mu.Lock()
+	listeners := append([]func(bool){}, e.listeners...)
+	e.mu.Unlock()
+	for _, fn := range listeners {
+		fn(leader)
+	}
+}
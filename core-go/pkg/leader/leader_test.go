@@ -0,0 +1,206 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+)
+
+// newRedis starts a fake Redis that runs the lock's two scripts
+func newRedis(t *testing.T) *titantest.Redis {
+	srv, err := titantest.NewRedis()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Handle("EVAL", func(args []string) interface{} {
+		script, key, id := args[1], args[3], args[4]
+		if held, ok := srv.Get(key); !ok || held != id {
+			return int64(0)
+		}
+		switch script {
+		case renewScript:
+			ms, _ := strconv.ParseInt(args[5], 10, 64)
+			srv.Set(key, id, time.Duration(ms)*time.Millisecond)
+		case releaseScript:
+			srv.Del(key)
+		}
+		return int64(1)
+	})
+	return srv
+}
+
+func TestElectorFailover(t *testing.T) {
+	srv := newRedis(t)
+	defer srv.Close()
+	ctx := context.Background()
+	reg := metrics.NewRegistry()
+
+	lockA, _ := NewRedisLock(srv.URL(), "titan:leader")
+	lockB, _ := NewRedisLock(srv.URL(), "titan:leader")
+	a := NewElector(lockA, "a", 100*time.Millisecond, reg)
+	b := NewElector(lockB, "b", 100*time.Millisecond, reg)
+	var changes []bool
+	a.OnChange(func(leader bool) { changes = append(changes, leader) })
+
+	a.step(ctx)
+	b.step(ctx)
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("Expected a to lead, got a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+	a.step(ctx) // renew
+	if holder, _ := b.Holder(ctx); holder != "a" || reg.Value("titan_leader", "a") != 1 {
+		t.Errorf("Expected a as holder and gauge 1, got %q", holder)
+	}
+
+	// a stops renewing, as if it died; b takes over once the TTL passes
+	time.Sleep(150 * time.Millisecond)
+	b.step(ctx)
+	if !b.IsLeader() {
+		t.Fatal("Expected b to take over")
+	}
+	a.step(ctx)
+	if a.IsLeader() || reg.Value("titan_leader", "a") != 0 {
+		t.Error("Expected a to notice it lost the lock")
+	}
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("Expected gained then lost, got %v", changes)
+	}
+
+	// A release by the old leader must not delete b's lock
+	lockA.Release(ctx, "a")
+	if holder, _ := srv.Get("titan:leader"); holder != "b" {
+		t.Errorf("Expected b to keep the lock, got %q", holder)
+	}
+}
+
+type flakyLock struct{ err error }
+
+func (l *flakyLock) Acquire(context.Context, string, time.Duration) (bool, error) { return true, nil }
+func (l *flakyLock) Renew(context.Context, string, time.Duration) (bool, error)   { return false, l.err }
+func (l *flakyLock) Release(context.Context, string) error                        { return nil }
+func (l *flakyLock) Holder(context.Context) (string, error)                       { return "", l.err }
+
+func TestElectorStepsDownBeforeLeaseExpires(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	lock := &flakyLock{}
+	e := NewElector(lock, "a", 9*time.Second, nil)
+	e.now = func() time.Time { return now }
+	e.step(context.Background())
+
+	lock.err = errors.New("connection refused")
+	now = now.Add(3 * time.Second)
+	e.step(context.Background())
+	if !e.IsLeader() {
+		t.Error("Expected one failed renewal to be tolerated")
+	}
+	now = now.Add(3 * time.Second)
+	e.step(context.Background())
+	if e.IsLeader() {
+		t.Error("Expected the leader to step down a renewal before its lease runs out")
+	}
+}
+
+// fakeEtcd implements the gateway endpoints the lock uses
+type fakeEtcd struct {
+	mu     sync.Mutex
+	next   int
+	leases map[string]bool
+	key    string // base64 value, empty when absent
+	lease  string
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&req)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.URL.Path {
+	case "/v3/lease/grant":
+		f.next++
+		id := strconv.Itoa(f.next)
+		f.leases[id] = true
+		json.NewEncoder(w).Encode(map[string]string{"ID": id, "TTL": "10"})
+	case "/v3/kv/txn":
+		if f.key != "" {
+			json.NewEncoder(w).Encode(map[string]interface{}{})
+			return
+		}
+		put := req["success"].([]interface{})[0].(map[string]interface{})["request_put"].(map[string]interface{})
+		f.key, f.lease = put["value"].(string), put["lease"].(string)
+		json.NewEncoder(w).Encode(map[string]bool{"succeeded": true})
+	case "/v3/lease/keepalive":
+		ttl := "0"
+		if f.leases[req["ID"].(string)] {
+			ttl = "10"
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]string{"TTL": ttl}})
+	case "/v3/lease/revoke":
+		id := req["ID"].(string)
+		delete(f.leases, id)
+		if id == f.lease {
+			f.key, f.lease = "", ""
+		}
+		w.Write([]byte(`{}`))
+	case "/v3/kv/range":
+		if f.key == "" {
+			w.Write([]byte(`{}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"kvs": []map[string]string{{"value": f.key}}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestEtcdLock(t *testing.T) {
+	fake := &fakeEtcd{leases: make(map[string]bool)}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	ctx := context.Background()
+
+	a, b := NewEtcdLock(srv.URL+"/", "titan/leader"), NewEtcdLock(srv.URL, "titan/leader")
+	if ok, err := a.Acquire(ctx, "a", 10*time.Second); !ok || err != nil {
+		t.Fatalf("Expected a to acquire, got %v, %v", ok, err)
+	}
+	if ok, err := b.Acquire(ctx, "b", 10*time.Second); ok || err != nil {
+		t.Fatalf("Expected b to be refused, got %v, %v", ok, err)
+	}
+	if len(fake.leases) != 1 {
+		t.Errorf("Expected b's unused lease revoked, got %d leases", len(fake.leases))
+	}
+	if holder, _ := b.Holder(ctx); holder != "a" {
+		t.Errorf("Expected holder a, got %q", holder)
+	}
+	if ok, _ := a.Renew(ctx, "a", 10*time.Second); !ok {
+		t.Error("Expected a to renew")
+	}
+
+	// The lease expires server-side
+	fake.mu.Lock()
+	delete(fake.leases, fake.lease)
+	fake.key = ""
+	fake.mu.Unlock()
+	if ok, _ := a.Renew(ctx, "a", 10*time.Second); ok {
+		t.Error("Expected renewal of an expired lease to fail")
+	}
+	if ok, _ := b.Acquire(ctx, "b", 10*time.Second); !ok {
+		t.Error("Expected b to acquire after expiry")
+	}
+	b.Release(ctx, "b")
+	if holder, _ := a.Holder(ctx); holder != "" {
+		t.Errorf("Expected no holder after release, got %q", holder)
+	}
+	if _, err := NewEtcdLock(srv.URL+"/nope", "k").Holder(ctx); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected HTTP error, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/redis"
+)
+
+// Scripts that only touch the key while it still holds the caller's ID, so
+// a slow instance can't extend or delete a lock someone else has taken since
+const (
+	renewScript   = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`
+	releaseScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+)
+
+// RedisLock is a Lock on a single Redis key set with NX and a millisecond TTL
+type RedisLock struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisLock creates a lock on key at a redis:// URL
+func NewRedisLock(url, key string) (*RedisLock, error) {
+	client, err := redis.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisLock{client: client, key: key}, nil
+}
+
+// Acquire sets the key to id if it doesn't exist
+func (l *RedisLock) Acquire(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	_, err := l.client.String(ctx, "SET", l.key, id, "NX", "PX", millis(ttl))
+	if errors.Is(err, redis.ErrNil) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Renew extends the key's TTL if it still holds id
+func (l *RedisLock) Renew(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	n, err := l.client.Int(ctx, "EVAL", renewScript, "1", l.key, id, millis(ttl))
+	return n == 1, err
+}
+
+// Release deletes the key if it still holds id
+func (l *RedisLock) Release(ctx context.Context, id string) error {
+	_, err := l.client.Int(ctx, "EVAL", releaseScript, "1", l.key, id)
+	return err
+}
+
+// Holder returns the key's value
+func (l *RedisLock) Holder(ctx context.Context) (string, error) {
+	id, err := l.client.String(ctx, "GET", l.key)
+	if errors.Is(err, redis.ErrNil) {
+		return "", nil
+	}
+	return id, err
+}
+
+func millis(d time.Duration) string {
+	return strconv.FormatInt(d.Milliseconds(), 10)
+}
@@ -0,0 +1,498 @@
+package liquidity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/commander"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/dex"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/exposure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Trend classifies how a pool's liquidity moved over the window
+type Trend string
+
+// Trends
+const (
+	TrendDraining Trend = "draining" // TVL fell well below the window's peak
+	TrendSteady   Trend = "steady"
+	TrendDeep     Trend = "deep" // TVL stayed above the deep threshold all window
+)
+
+// Level returns the trend as a number for metrics: -1 draining, 0 steady, 1 deep
+func (t Trend) Level() float64 {
+	switch t {
+	case TrendDraining:
+		return -1
+	case TrendDeep:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// minSamples is the fewest TVL readings a trend is judged from; until then a
+// pool is treated as steady
+const minSamples = 3
+
+// maxBlocks bounds the range of one swap log query
+const maxBlocks = 2000
+
+// Swap event signatures of constant-product and concentrated-liquidity pools
+var (
+	v2SwapTopic = crypto.Keccak256Hash([]byte("Swap(address,uint256,uint256,uint256,uint256,address)"))
+	v3SwapTopic = crypto.Keccak256Hash([]byte("Swap(address,address,int256,int256,uint160,uint128,int24)"))
+)
+
+// Backend reads a chain's pools and swap logs
+type Backend interface {
+	dex.Backend
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// Dialer returns a backend for a chain
+type Dialer func(chainID uint64) (Backend, error)
+
+// Settings bounds the trends and how far each scales the max TVL share
+type Settings struct {
+	Window       time.Duration // lookback trends and volume are measured over
+	DrainBps     uint64        // TVL this far below the window's peak marks a pool draining
+	DeepUSD      units.USD     // TVL never below this over the window marks a pool deep
+	DeepScaleBps uint64        // max TVL share multiplier for deep pools, in bps of 1x
+	MaxShareBps  uint64        // ceiling on a deep pool's share
+}
+
+// State is a pool's measured liquidity and the trend it puts the pool in
+type State struct {
+	ChainID   uint64         `json:"chainId"`
+	Pool      common.Address `json:"pool"`
+	Dex       string         `json:"dex"`
+	TVLUSD    units.USD      `json:"tvlUsd"`
+	PeakUSD   units.USD      `json:"peakUsd"`
+	LowUSD    units.USD      `json:"lowUsd"`
+	ChangeBps int64          `json:"changeBps"` // TVL change since the window's first reading
+	VolumeUSD units.USD      `json:"volumeUsd"` // swapped into the pool over the window
+	Samples   int            `json:"samples"`
+	Trend     Trend          `json:"trend"`
+	ScaleBps  uint64         `json:"scaleBps"` // max TVL share multiplier, in bps of 1x
+}
+
+type poolKey struct {
+	chainID uint64
+	pool    common.Address
+}
+
+type sample struct {
+	at  time.Time
+	usd units.USD
+}
+
+// Tracker follows the TVL and swap volume of the cached pools and scales the
+// max TVL share guardrail per pool: down in proportion to the drop when a
+// pool is draining, up to a ceiling when it has stayed deep all window. A
+// nil tracker keeps every pool steady. It is safe for concurrent use.
+type Tracker struct {
+	settings Settings
+	pools    *reserves.Cache
+	dial     Dialer
+	value    exposure.Valuer
+
+	mu     sync.Mutex
+	tvl    map[poolKey][]sample
+	volume map[poolKey][]sample
+	states map[poolKey]State
+	next   map[uint64]uint64 // first block not yet read for swaps, by chain
+	now    func() time.Time
+
+	tvlGauge   *metrics.GaugeVec
+	trendGauge *metrics.GaugeVec
+}
+
+// New creates a tracker of pools' snapshots in cache, refreshed through dial
+// and valued by value; reg may be nil
+func New(s Settings, cache *reserves.Cache, dial Dialer, value exposure.Valuer, reg *metrics.Registry) *Tracker {
+	t := &Tracker{
+		settings: s,
+		pools:    cache,
+		dial:     dial,
+		value:    value,
+		tvl:      make(map[poolKey][]sample),
+		volume:   make(map[poolKey][]sample),
+		states:   make(map[poolKey]State),
+		next:     make(map[uint64]uint64),
+		now:      time.Now,
+	}
+	if reg != nil {
+		t.tvlGauge = reg.Gauge("titan_pool_tvl_usd", "Pool TVL in USD, from its latest reserves", "chain", "pool")
+		t.trendGauge = reg.Gauge("titan_pool_liquidity_trend", "Pool liquidity trend: -1 draining, 0 steady, 1 deep", "chain", "pool")
+	}
+	return t
+}
+
+// FromConfig creates the configured tracker, nil when disabled
+func FromConfig(cfg *config.LiquidityConfig, cache *reserves.Cache, dial Dialer, value exposure.Valuer, reg *metrics.Registry) *Tracker {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	return New(Settings{
+		Window:       time.Duration(cfg.WindowHours) * time.Hour,
+		DrainBps:     cfg.DrainBps,
+		DeepUSD:      units.DollarsToUSD(cfg.DeepUSD),
+		DeepScaleBps: cfg.DeepScaleBps,
+		MaxShareBps:  cfg.MaxShareBps,
+	}, cache, dial, value, reg)
+}
+
+// Run polls chains every interval until ctx is done
+func (t *Tracker) Run(ctx context.Context, interval time.Duration, chains ...uint64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, chainID := range chains {
+			if err := t.Poll(ctx, chainID); err != nil && ctx.Err() == nil {
+				log.Printf("⚠️ Pool liquidity on chain %d: %v", chainID, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll refreshes chainID's cached pools, records their TVL and indexes their
+// swaps since the last poll. Swaps are read forward from the head seen on the
+// first poll.
+func (t *Tracker) Poll(ctx context.Context, chainID uint64) error {
+	client, err := t.dial(chainID)
+	if err != nil {
+		return err
+	}
+	discovery := dex.NewDiscovery(chainID, client)
+	pools := make(map[common.Address]*reserves.Snapshot)
+	var errs []error
+	for _, s := range t.pools.All(chainID) {
+		var fresh *reserves.Snapshot
+		if s.Kind == reserves.KindV3 {
+			fresh, err = discovery.FetchV3(ctx, s.Dex, s.Pool, s.FeeBps*100)
+		} else {
+			fresh, err = discovery.FetchV2(ctx, s.Dex, s.Pool, s.FeeBps)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pool %s: %w", s.Pool.Hex(), err))
+			continue
+		}
+		t.pools.Put(fresh)
+		pools[fresh.Pool] = fresh
+		if usd, err := t.tvlOf(fresh); err == nil {
+			t.Observe(fresh, usd)
+		}
+	}
+	if len(pools) == 0 {
+		return errors.Join(errs...)
+	}
+
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	from, seen := t.next[chainID]
+	t.mu.Unlock()
+	if !seen {
+		from = head
+	}
+	if from > head {
+		return errors.Join(errs...)
+	}
+	to := head
+	if to-from >= maxBlocks {
+		to = from + maxBlocks - 1
+	}
+	addresses := make([]common.Address, 0, len(pools))
+	for a := range pools {
+		addresses = append(addresses, a)
+	}
+	logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: addresses,
+		Topics:    [][]common.Hash{{v2SwapTopic, v3SwapTopic}},
+	})
+	if err != nil {
+		return fmt.Errorf("blocks %d-%d: %w", from, to, err)
+	}
+	for _, l := range logs {
+		if s, ok := pools[l.Address]; ok && !l.Removed {
+			t.AddVolume(chainID, l.Address, t.swapped(s, l))
+		}
+	}
+	t.mu.Lock()
+	t.next[chainID] = to + 1
+	t.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// tvlOf values both of s's reserves
+func (t *Tracker) tvlOf(s *reserves.Snapshot) (units.USD, error) {
+	usd0, err := t.value.USD(s.ChainID, s.Token0, s.Reserve0)
+	if err != nil {
+		return 0, err
+	}
+	usd1, err := t.value.USD(s.ChainID, s.Token1, s.Reserve1)
+	if err != nil {
+		return 0, err
+	}
+	return usd0 + usd1, nil
+}
+
+// swapped values the input side of a swap log of s; unpriced or malformed
+// swaps count as nothing
+func (t *Tracker) swapped(s *reserves.Snapshot, l types.Log) units.USD {
+	words := make([]*big.Int, len(l.Data)/32)
+	for i := range words {
+		words[i] = new(big.Int).SetBytes(l.Data[i*32 : (i+1)*32])
+	}
+	in0, in1 := new(big.Int), new(big.Int)
+	switch {
+	case len(l.Topics) > 0 && l.Topics[0] == v2SwapTopic && len(words) == 4:
+		in0, in1 = words[0], words[1]
+	case len(l.Topics) > 0 && l.Topics[0] == v3SwapTopic && len(words) >= 2:
+		// the pool's side of the swap: positive amounts were paid in
+		if words[0].Bit(255) == 0 {
+			in0 = words[0]
+		}
+		if words[1].Bit(255) == 0 {
+			in1 = words[1]
+		}
+	default:
+		return 0
+	}
+	var usd units.USD
+	for _, leg := range []struct {
+		token common.Address
+		raw   *big.Int
+	}{{s.Token0, in0}, {s.Token1, in1}} {
+		if leg.raw.Sign() == 0 {
+			continue
+		}
+		if v, err := t.value.USD(s.ChainID, leg.token, leg.raw); err == nil {
+			usd += v
+		}
+	}
+	return usd
+}
+
+// Observe records a TVL reading for s's pool and re-evaluates its trend
+func (t *Tracker) Observe(s *reserves.Snapshot, tvl units.USD) {
+	if t == nil {
+		return
+	}
+	key := poolKey{s.ChainID, s.Pool}
+	now := t.now()
+	t.mu.Lock()
+	samples := prune(append(t.tvl[key], sample{at: now, usd: tvl}), now.Add(-t.settings.Window))
+	t.tvl[key] = samples
+	prev := t.states[key].Trend
+	st := t.measure(key, s.Dex, samples, now)
+	t.states[key] = st
+	t.mu.Unlock()
+
+	if t.tvlGauge != nil {
+		chain := strconv.FormatUint(s.ChainID, 10)
+		t.tvlGauge.Set(tvl.Float(), chain, s.Pool.Hex())
+		t.trendGauge.Set(st.Trend.Level(), chain, s.Pool.Hex())
+	}
+	if prev != "" && prev != st.Trend {
+		log.Printf("💧 Pool %s on chain %d %s → %s (TVL %s, peak %s)", s.Pool.Hex(), s.ChainID, prev, st.Trend, st.TVLUSD, st.PeakUSD)
+	}
+}
+
+// AddVolume records usd swapped into pool
+func (t *Tracker) AddVolume(chainID uint64, pool common.Address, usd units.USD) {
+	if t == nil || usd <= 0 {
+		return
+	}
+	key := poolKey{chainID, pool}
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.volume[key] = prune(append(t.volume[key], sample{at: now, usd: usd}), now.Add(-t.settings.Window))
+	if st, ok := t.states[key]; ok {
+		st.VolumeUSD = sum(t.volume[key])
+		t.states[key] = st
+	}
+}
+
+// measure judges the trend from the window's TVL readings; caller holds t.mu
+func (t *Tracker) measure(key poolKey, dexName string, samples []sample, now time.Time) State {
+	st := State{ChainID: key.chainID, Pool: key.pool, Dex: dexName, Samples: len(samples), Trend: TrendSteady, ScaleBps: units.BpsDenominator}
+	st.VolumeUSD = sum(prune(t.volume[key], now.Add(-t.settings.Window)))
+	if len(samples) == 0 {
+		return st
+	}
+	st.TVLUSD, st.PeakUSD, st.LowUSD = samples[len(samples)-1].usd, samples[0].usd, samples[0].usd
+	for _, s := range samples {
+		if s.usd > st.PeakUSD {
+			st.PeakUSD = s.usd
+		}
+		if s.usd < st.LowUSD {
+			st.LowUSD = s.usd
+		}
+	}
+	if first := samples[0].usd; first > 0 {
+		st.ChangeBps = ratioBps(st.TVLUSD-first, first)
+	}
+	if len(samples) < minSamples || st.PeakUSD <= 0 {
+		return st
+	}
+	switch {
+	case ratioBps(st.PeakUSD-st.TVLUSD, st.PeakUSD) >= int64(t.settings.DrainBps):
+		st.Trend, st.ScaleBps = TrendDraining, uint64(ratioBps(st.TVLUSD, st.PeakUSD))
+	// deep only once readings span most of the window, so a pool isn't
+	// trusted on an hour of history
+	case st.LowUSD >= t.settings.DeepUSD && now.Sub(samples[0].at) >= t.settings.Window*3/4:
+		st.Trend, st.ScaleBps = TrendDeep, t.settings.DeepScaleBps
+	}
+	return st
+}
+
+// ratioBps returns part/whole in basis points, rounding toward zero
+func ratioBps(part, whole units.USD) int64 {
+	r := new(big.Int).Mul(big.NewInt(int64(part)), big.NewInt(units.BpsDenominator))
+	return r.Quo(r, big.NewInt(int64(whole))).Int64()
+}
+
+// State returns pool's latest measurement
+func (t *Tracker) State(chainID uint64, pool common.Address) State {
+	steady := State{ChainID: chainID, Pool: pool, Trend: TrendSteady, ScaleBps: units.BpsDenominator}
+	if t == nil {
+		return steady
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if st, ok := t.states[poolKey{chainID, pool}]; ok {
+		return st
+	}
+	return steady
+}
+
+// States returns every observed pool's latest measurement, by chain and pool
+func (t *Tracker) States() []State {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]State, 0, len(t.states))
+	for _, st := range t.states {
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ChainID != out[j].ChainID {
+			return out[i].ChainID < out[j].ChainID
+		}
+		return out[i].Pool.Hex() < out[j].Pool.Hex()
+	})
+	return out
+}
+
+// Adjust scales g's max TVL share by pool's trend. A deep pool's share grows
+// to at most the ceiling, and never shrinks when g is already above it.
+func (t *Tracker) Adjust(chainID uint64, pool common.Address, g config.Guardrails) config.Guardrails {
+	st := t.State(chainID, pool)
+	if st.ScaleBps == units.BpsDenominator {
+		return g
+	}
+	share := units.MulBpsUint(g.MaxTVLShareBps, st.ScaleBps)
+	if st.Trend == TrendDeep {
+		share = max(min(share, t.settings.MaxShareBps), g.MaxTVLShareBps)
+	}
+	g.MaxTVLShareBps = max(share, 1)
+	return g
+}
+
+// Gate rejects o when any leg takes more of its pool's input reserve than the
+// pool's adjusted max TVL share, recording the tightest leg as a guardrail.
+// Legs through uncached pools aren't checked; a nil tracker passes everything.
+func (t *Tracker) Gate(o *opportunity.Opportunity, g config.Guardrails) bool {
+	if t == nil || o.Explanation == nil {
+		return true
+	}
+	var worst *opportunity.Guardrail
+	var worstIn, worstLimit *big.Int
+	for _, leg := range o.Explanation.Legs {
+		s, ok := t.pools.Get(o.ChainID, leg.Pool, 0)
+		if !ok || leg.AmountIn == nil {
+			continue
+		}
+		reserveIn := s.Reserve1
+		if s.ZeroForOne(leg.TokenIn) {
+			reserveIn = s.Reserve0
+		}
+		share := t.Adjust(o.ChainID, leg.Pool, g).MaxTVLShareBps
+		limit := units.MulBps(reserveIn, share)
+		if worst == nil || heavier(leg.AmountIn, limit, worstIn, worstLimit) {
+			worst = &opportunity.Guardrail{
+				Name:   commander.GuardrailMaxTVLShare,
+				Limit:  limit.String(),
+				Actual: leg.AmountIn.String(),
+				Bound:  limit.Sign() == 0 || leg.AmountIn.Cmp(limit) > 0,
+			}
+			worstIn, worstLimit = leg.AmountIn, limit
+		}
+	}
+	if worst == nil {
+		return true
+	}
+	o.Explanation.Guardrails = append(o.Explanation.Guardrails, *worst)
+	if worst.Bound {
+		o.Explanation.Reject(failure.InsufficientLiquidity, "leg exceeds the pool's max TVL share")
+		return false
+	}
+	return true
+}
+
+// prune drops samples before cutoff
+func prune(samples []sample, cutoff time.Time) []sample {
+	i := sort.Search(len(samples), func(i int) bool { return !samples[i].at.Before(cutoff) })
+	return samples[i:]
+}
+
+func sum(samples []sample) units.USD {
+	var total units.USD
+	for _, s := range samples {
+		total += s.usd
+	}
+	return total
+}
+
+// heavier reports whether amount takes a larger fraction of limit than
+// other does of otherLimit; a zero limit is exceeded by anything
+func heavier(amount, limit, other, otherLimit *big.Int) bool {
+	if otherLimit.Sign() == 0 {
+		return false
+	}
+	if limit.Sign() == 0 {
+		return true
+	}
+	return new(big.Int).Mul(amount, otherLimit).Cmp(new(big.Int).Mul(other, limit)) > 0
+}
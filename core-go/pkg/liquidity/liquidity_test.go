@@ -0,0 +1,156 @@
+package liquidity
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// chain is a titantest backend that also serves swap logs
+type chain struct {
+	*titantest.Backend
+	logs []types.Log
+}
+
+func (c *chain) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var out []types.Log
+	for _, l := range c.logs {
+		if l.BlockNumber >= q.FromBlock.Uint64() && l.BlockNumber <= q.ToBlock.Uint64() {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+// dollars values every whole token at a dollar
+type dollars struct{}
+
+func (dollars) USD(chainID uint64, token common.Address, raw *big.Int) (units.USD, error) {
+	return units.DollarsToUSD(new(big.Int).Div(raw, big.NewInt(1e18)).Uint64()), nil
+}
+
+func newTracker(c *chain, cache *reserves.Cache, reg *metrics.Registry) (*Tracker, *time.Time) {
+	cfg := &config.LiquidityConfig{Enabled: true, WindowHours: 24, PollSecs: 60, DrainBps: 2000, DeepUSD: 5000000, DeepScaleBps: 15000, MaxShareBps: 2500}
+	tr := FromConfig(cfg, cache, func(uint64) (Backend, error) { return c, nil }, dollars{}, reg)
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+	return tr, &now
+}
+
+func v2Swap(pool common.Address, block uint64, in0, in1 int64) types.Log {
+	data := titantest.EncodeUint(titantest.Units(in0, 18))
+	data = append(data, titantest.EncodeUint(titantest.Units(in1, 18))...)
+	data = append(data, make([]byte, 64)...)
+	return types.Log{Address: pool, Topics: []common.Hash{v2SwapTopic}, Data: data, BlockNumber: block}
+}
+
+func TestPollTracksDrainingPool(t *testing.T) {
+	c := &chain{Backend: titantest.NewBackend(1)}
+	token0, token1 := titantest.Address(1), titantest.Address(2)
+	pool := c.AddPool(titantest.NewPool(titantest.Address(0xb1), token0, token1).WithReserves(titantest.Units(3_000_000, 18), titantest.Units(3_000_000, 18)))
+	cache := reserves.NewCache()
+	cache.Put(&reserves.Snapshot{ChainID: 1, Dex: "UNISWAP", Kind: reserves.KindV2, Pool: pool.Address, Token0: token0, Token1: token1, Reserve0: new(big.Int), Reserve1: new(big.Int), FeeBps: 30})
+	reg := metrics.NewRegistry()
+	tr, now := newTracker(c, cache, reg)
+
+	if err := tr.Poll(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := cache.Get(1, pool.Address, 0); s.Reserve0.Cmp(pool.Reserve0) != 0 {
+		t.Fatalf("Expected the cached snapshot refreshed, got %s", s.Reserve0)
+	}
+	c.Mine(3, time.Second)
+	c.logs = []types.Log{v2Swap(pool.Address, 0, 10, 0), v2Swap(pool.Address, 2, 0, 250_000), v2Swap(pool.Address, 3, 50_000, 0)}
+	for _, reserve := range []int64{2_800_000, 2_000_000} {
+		*now = now.Add(time.Hour)
+		pool.WithReserves(titantest.Units(reserve, 18), titantest.Units(reserve, 18))
+		if err := tr.Poll(context.Background(), 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	st := tr.State(1, pool.Address)
+	if st.Trend != TrendDraining || st.TVLUSD != units.DollarsToUSD(4_000_000) || st.PeakUSD != units.DollarsToUSD(6_000_000) {
+		t.Fatalf("Expected a draining pool at $4M off a $6M peak, got %+v", st)
+	}
+	if st.ChangeBps != -3333 || st.ScaleBps != 6666 {
+		t.Errorf("Expected a third lost since the first reading, got %d bps scaled to %d bps", st.ChangeBps, st.ScaleBps)
+	}
+	if st.VolumeUSD != units.DollarsToUSD(300_000) {
+		t.Errorf("Expected only swaps after the first head counted, got %s", st.VolumeUSD)
+	}
+	if reg.Value("titan_pool_liquidity_trend", "1", pool.Address.Hex()) != -1 {
+		t.Error("Expected the trend gauge to show draining")
+	}
+
+	g := tr.Adjust(1, pool.Address, config.Guardrails{MaxTVLShareBps: 2000})
+	if g.MaxTVLShareBps != 1333 {
+		t.Errorf("Expected the share cut by the drop from peak, got %d", g.MaxTVLShareBps)
+	}
+}
+
+func TestDeepPoolRaisesShareToCeiling(t *testing.T) {
+	tr, now := newTracker(&chain{}, reserves.NewCache(), nil)
+	s := &reserves.Snapshot{ChainID: 1, Pool: titantest.Address(0xb2)}
+	for i := 0; i < 4; i++ {
+		tr.Observe(s, units.DollarsToUSD(8_000_000))
+		*now = now.Add(6 * time.Hour)
+	}
+	if st := tr.State(1, s.Pool); st.Trend != TrendDeep {
+		t.Fatalf("Expected a pool deep all window, got %+v", st)
+	}
+	if g := tr.Adjust(1, s.Pool, config.Guardrails{MaxTVLShareBps: 2000}); g.MaxTVLShareBps != 2500 {
+		t.Errorf("Expected the share raised to the ceiling, got %d", g.MaxTVLShareBps)
+	}
+	if g := tr.Adjust(1, s.Pool, config.Guardrails{MaxTVLShareBps: 4000}); g.MaxTVLShareBps != 4000 {
+		t.Errorf("Expected a share above the ceiling left alone, got %d", g.MaxTVLShareBps)
+	}
+
+	young, _ := newTracker(&chain{}, reserves.NewCache(), nil)
+	for i := 0; i < 4; i++ {
+		young.Observe(s, units.DollarsToUSD(8_000_000))
+	}
+	if st := young.State(1, s.Pool); st.Trend != TrendSteady {
+		t.Errorf("Expected an hour of history not trusted as deep, got %s", st.Trend)
+	}
+}
+
+func TestGateRejectsLegOverAdjustedShare(t *testing.T) {
+	cache := reserves.NewCache()
+	token0, token1 := titantest.Address(1), titantest.Address(2)
+	s := &reserves.Snapshot{ChainID: 1, Pool: titantest.Address(0xb3), Token0: token0, Token1: token1, Reserve0: titantest.Units(1000, 18), Reserve1: titantest.Units(500, 18)}
+	cache.Put(s)
+	tr, now := newTracker(&chain{}, cache, nil)
+	for _, tvl := range []uint64{3_000_000, 3_000_000, 1_500_000} {
+		tr.Observe(s, units.DollarsToUSD(tvl))
+		*now = now.Add(time.Hour)
+	}
+
+	// a 20% share halved by the drain leaves 50 of the 500 token1 reserve
+	o := &opportunity.Opportunity{ChainID: 1, Explanation: &opportunity.Explanation{Legs: []opportunity.Leg{
+		{Pool: s.Pool, TokenIn: token0, AmountIn: titantest.Units(60, 18)},
+		{Pool: s.Pool, TokenIn: token1, AmountIn: titantest.Units(60, 18)},
+		{Pool: titantest.Address(0xff), TokenIn: token0, AmountIn: titantest.Units(1e6, 18)},
+	}}}
+	if tr.Gate(o, config.Guardrails{MaxTVLShareBps: 2000}) {
+		t.Fatal("Expected the token1 leg rejected")
+	}
+	if o.Explanation.Category != failure.InsufficientLiquidity || len(o.Explanation.Guardrails) != 1 || !o.Explanation.Guardrails[0].Bound {
+		t.Errorf("Expected a bound max_tvl_share guardrail, got %+v", o.Explanation)
+	}
+	if (*Tracker)(nil).Gate(o, config.Guardrails{MaxTVLShareBps: 2000}) != true {
+		t.Error("Expected a nil tracker to pass everything")
+	}
+}
@@ -0,0 +1,254 @@
+package mevshare
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/amortize"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/canary"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/pathfind"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/route"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+var (
+	// syncTopic is UniswapV2 Sync(uint112,uint112): the pair's reserves after the swap
+	syncTopic = crypto.Keccak256Hash([]byte("Sync(uint112,uint112)"))
+	// swapV3Topic is UniswapV3 Swap, carrying the price and liquidity after the swap
+	swapV3Topic = crypto.Keccak256Hash([]byte("Swap(address,address,int256,int256,uint160,uint128,int24)"))
+)
+
+var q96 = new(big.Int).Lsh(big.NewInt(1), 96)
+
+// Candidate is a backrun found for a hint: a cycle through a pool the pending
+// transaction moves, quoted against the pool's state after that transaction
+type Candidate struct {
+	Hint           common.Hash
+	Route          pathfind.Route
+	AmountIn       *big.Int        // quoted size, below the backrunner's for canary routes
+	Profit         *big.Int        // in the start token, before the builder payment
+	BuilderPayment *big.Int        // owed to the builder under the payment policy, in the start token
+	Adapters       []route.Adapter // the executor adapter swapping each hop
+	Opportunity    *opportunity.Opportunity
+}
+
+// Backrunner finds cycles that close the price gap a pending swap opens
+type Backrunner struct {
+	chainID  uint64
+	cache    *reserves.Cache
+	start    common.Address
+	amountIn *big.Int
+
+	// MaxHops bounds cycle length
+	MaxHops int
+	// MaxAge skips cached pools older than this; 0 disables the check
+	MaxAge time.Duration
+	// ToUSD values profit in the start token; nil leaves USD fields zero
+	ToUSD func(amount *big.Int) (units.USD, error)
+	// Payment shares profit with the block builder; nil pays nothing
+	Payment *PaymentPolicy
+	// Gas predicts the backrun's gas from past executions of the same route
+	// shape; nil leaves gas units zero until the transaction is built
+	Gas *gas.History
+	// Costs charges each backrun its route's share of one-off costs; nil
+	// charges nothing
+	Costs *amortize.Ledger
+	// Canary requotes routes that haven't proven themselves at a canary
+	// size; nil quotes every route at full size
+	Canary *canary.Tracker
+	// FlashPremiumBps is the lender's fee on the borrowed size, charged
+	// alongside the builder payment
+	FlashPremiumBps uint64
+	// GasCostUSD prices the backrun's gas at the chain's suggested fees,
+	// leaving out the priority fee the builder payment replaces; nil charges
+	// no gas
+	GasCostUSD func(gasUnits uint64) (units.USD, error)
+}
+
+// NewBackrunner creates a backrunner quoting amountIn of start through cached pools
+func NewBackrunner(chainID uint64, cache *reserves.Cache, start common.Address, amountIn *big.Int) *Backrunner {
+	return &Backrunner{chainID: chainID, cache: cache, start: start, amountIn: amountIn, MaxHops: 3, MaxAge: time.Minute}
+}
+
+// Candidates returns profitable backruns for h, most profitable first. Only
+// pools whose post-swap state the hint reveals (a V2 Sync or V3 Swap log with
+// data) can be priced; hints sharing just addresses or topics yield nothing.
+func (b *Backrunner) Candidates(h *Hint) []*Candidate {
+	touched := b.postStates(h)
+	if len(touched) == 0 {
+		return nil
+	}
+
+	graph := pathfind.NewGraph()
+	snapshots := make(map[common.Address]*reserves.Snapshot)
+	for _, s := range b.cache.All(b.chainID) {
+		if post, ok := touched[s.Pool]; ok {
+			s = post
+		} else if b.MaxAge > 0 && time.Since(s.FetchedAt) > b.MaxAge {
+			continue
+		}
+		snapshots[s.Pool] = s
+		graph.AddPool(s.Pool, s.Token0, s.Token1, s.AMM())
+	}
+
+	var out []*Candidate
+	for _, route := range graph.FindCycles(b.start, b.MaxHops) {
+		if !crosses(route, touched) {
+			continue
+		}
+		c := b.quote(h, route, b.amountIn, snapshots)
+		if c == nil {
+			continue
+		}
+		// The route's label is only known once its legs are, so canaries
+		// are requoted at their smaller size
+		if size, bound := b.Canary.Size(b.chainID, c.Opportunity.Explanation.Route(), b.amountIn); bound != nil {
+			if c = b.quote(h, route, size, snapshots); c == nil {
+				continue
+			}
+			c.Opportunity.Explanation.Guardrails = append(c.Opportunity.Explanation.Guardrails, *bound)
+		}
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Profit.Cmp(out[j].Profit) > 0 })
+	return out
+}
+
+// quote prices route at amountIn, returning nil when it isn't profitable
+func (b *Backrunner) quote(h *Hint, route pathfind.Route, amountIn *big.Int, snapshots map[common.Address]*reserves.Snapshot) *Candidate {
+	amountOut, err := route.Quote(amountIn)
+	if err != nil || amountOut.Cmp(amountIn) <= 0 {
+		return nil
+	}
+	c := &Candidate{Hint: h.Hash, Route: route, AmountIn: amountIn, Profit: new(big.Int).Sub(amountOut, amountIn)}
+	if c.Opportunity, err = b.opportunity(c, snapshots); err != nil {
+		return nil
+	}
+	return c
+}
+
+// postStates applies the hint's logs to copies of the cached pools they touch
+func (b *Backrunner) postStates(h *Hint) map[common.Address]*reserves.Snapshot {
+	touched := make(map[common.Address]*reserves.Snapshot)
+	for _, l := range h.Logs {
+		if len(l.Topics) == 0 {
+			continue
+		}
+		cached, ok := touched[l.Address]
+		if !ok {
+			if cached, ok = b.cache.Get(b.chainID, l.Address, 0); !ok {
+				continue
+			}
+		}
+		post := *cached
+		switch {
+		case l.Topics[0] == syncTopic && cached.Kind == reserves.KindV2 && len(l.Data) >= 64:
+			post.Reserve0 = new(big.Int).SetBytes(l.Data[:32])
+			post.Reserve1 = new(big.Int).SetBytes(l.Data[32:64])
+		case l.Topics[0] == swapV3Topic && cached.Kind == reserves.KindV3 && len(l.Data) >= 160:
+			sqrtPrice := new(big.Int).SetBytes(l.Data[64:96])
+			liquidity := new(big.Int).SetBytes(l.Data[96:128])
+			if sqrtPrice.Sign() == 0 {
+				continue
+			}
+			post.Reserve0 = new(big.Int).Mul(liquidity, q96)
+			post.Reserve0.Quo(post.Reserve0, sqrtPrice)
+			post.Reserve1 = new(big.Int).Mul(liquidity, sqrtPrice)
+			post.Reserve1.Quo(post.Reserve1, q96)
+			post.Liquidity = liquidity
+		default:
+			continue
+		}
+		touched[l.Address] = &post
+	}
+	return touched
+}
+
+// opportunity records the candidate in the shape the scoring and journal paths expect
+func (b *Backrunner) opportunity(c *Candidate, snapshots map[common.Address]*reserves.Snapshot) (*opportunity.Opportunity, error) {
+	legs, err := opportunity.LegsFromRoute(c.Route, c.AmountIn)
+	if err != nil {
+		return nil, err
+	}
+	var block uint64
+	shape := gas.Shape{ChainID: b.chainID, Adapters: make([]string, len(legs))}
+	c.Adapters = make([]route.Adapter, len(legs))
+	for i := range legs {
+		if s := snapshots[legs[i].Pool]; s != nil {
+			legs[i].Dex = s.Dex
+			if s.Block > block {
+				block = s.Block
+			}
+			c.Adapters[i] = route.AdapterUniV2
+			if s.Kind == reserves.KindV3 {
+				c.Adapters[i] = route.AdapterUniV3
+			}
+			shape.Adapters[i] = c.Adapters[i].Name()
+		}
+	}
+	c.BuilderPayment = new(big.Int)
+	if b.Payment != nil {
+		if c.BuilderPayment, err = b.Payment.Payment(c.Profit, b.ToUSD); err != nil {
+			return nil, err
+		}
+	}
+
+	o := opportunity.New(b.chainID, block, b.start, c.AmountIn)
+	o.Explanation.Legs = legs
+	if b.Gas != nil {
+		est := b.Gas.Estimate(shape)
+		o.Explanation.GasEstimate = &est
+		o.Explanation.GasUnits = est.Gas
+	}
+	if b.ToUSD != nil {
+		gross, err := b.ToUSD(c.Profit)
+		if err == nil {
+			paid, _ := b.ToUSD(c.BuilderPayment)
+			notional, _ := b.ToUSD(c.AmountIn)
+			costs := opportunity.NewCostBreakdown(legs, notional)
+			costs.RelayTipUSD = paid
+			costs.FlashLoanUSD = units.USD(units.MulBpsUint(uint64(max(notional, 0)), b.FlashPremiumBps))
+			// Gas is charged before scoring, so the profit floor sees it
+			if b.GasCostUSD != nil {
+				gasUnits := o.Explanation.GasUnits
+				if gasUnits == 0 {
+					gasUnits = DefaultBackrunGas
+				}
+				if costs.SourceGasUSD, err = b.GasCostUSD(gasUnits); err != nil {
+					return nil, fmt.Errorf("gas: %w", err)
+				}
+			}
+			o.Explanation.GrossProfitUSD = gross
+			o.Explanation.Attribute(costs)
+			if b.Costs != nil {
+				o.Explanation.Amortize(b.Costs.Charge(b.chainID, o.Explanation.Route()))
+			}
+		}
+	}
+
+	// Score by return on the quoted size after paying the builder, so
+	// backruns rank alongside other cycles
+	kept := new(big.Int).Sub(c.Profit, c.BuilderPayment)
+	ret, _ := new(big.Float).Quo(new(big.Float).SetInt(kept), new(big.Float).SetInt(c.AmountIn)).Float64()
+	o.Explanation.Components = []opportunity.ScoreComponent{{Name: "backrun_return", Value: ret, Weight: 1}}
+	o.Explanation.Score = ret
+	o.Explanation.Reason = "mev-share backrun of " + c.Hint.Hex()
+	return o, nil
+}
+
+// crosses reports whether route swaps through any touched pool
+func crosses(route pathfind.Route, touched map[common.Address]*reserves.Snapshot) bool {
+	for _, hop := range route {
+		if _, ok := touched[hop.Pool]; ok {
+			return true
+		}
+	}
+	return false
+}
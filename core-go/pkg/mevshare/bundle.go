@@ -0,0 +1,131 @@
+package mevshare
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DefaultRelayURL is the Flashbots relay accepting mev_sendBundle
+const DefaultRelayURL = "https://relay.flashbots.net"
+
+// ErrRelay is returned when the relay rejects a bundle
+var ErrRelay = errors.New("mevshare: relay rejected bundle")
+
+// Bundle is an mev_sendBundle (v0.1) request
+type Bundle struct {
+	Version   string       `json:"version"`
+	Inclusion Inclusion    `json:"inclusion"`
+	Body      []BundleItem `json:"body"`
+}
+
+// Inclusion is the block range a bundle is valid for
+type Inclusion struct {
+	Block    hexutil.Uint64 `json:"block"`
+	MaxBlock hexutil.Uint64 `json:"maxBlock,omitempty"`
+}
+
+// BundleItem is either a matched pending transaction (Hash) or a signed one (Tx)
+type BundleItem struct {
+	Hash      *common.Hash  `json:"hash,omitempty"`
+	Tx        hexutil.Bytes `json:"tx,omitempty"`
+	CanRevert bool          `json:"canRevert,omitempty"`
+}
+
+// NewBackrunBundle places the signed backrun raw directly after the hinted
+// transaction, valid from block for the given number of blocks
+func NewBackrunBundle(hint common.Hash, raw []byte, block, blocks uint64) *Bundle {
+	if blocks == 0 {
+		blocks = 1
+	}
+	return &Bundle{
+		Version:   "v0.1",
+		Inclusion: Inclusion{Block: hexutil.Uint64(block), MaxBlock: hexutil.Uint64(block + blocks - 1)},
+		Body:      []BundleItem{{Hash: &hint}, {Tx: raw}},
+	}
+}
+
+// Client submits bundles to the relay, signing each request with the
+// searcher's reputation key as X-Flashbots-Signature
+type Client struct {
+	url    string
+	key    *ecdsa.PrivateKey
+	client *http.Client
+}
+
+// NewClient creates a relay client; an empty url uses DefaultRelayURL. The
+// key only identifies the searcher and should not hold funds.
+func NewClient(url string, key *ecdsa.PrivateKey) *Client {
+	if url == "" {
+		url = DefaultRelayURL
+	}
+	return &Client{url: url, key: key, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// SendBundle submits b and returns the relay's bundle hash
+func (c *Client) SendBundle(ctx context.Context, b *Bundle) (common.Hash, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "mev_sendBundle",
+		"params":  []interface{}{b},
+	})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	signature, err := c.sign(body)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Flashbots-Signature", signature)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer resp.Body.Close()
+	var out struct {
+		Result *struct {
+			BundleHash common.Hash `json:"bundleHash"`
+		} `json:"result"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return common.Hash{}, fmt.Errorf("relay returned %s: %w", resp.Status, err)
+	}
+	if out.Error != nil {
+		return common.Hash{}, fmt.Errorf("%w: %s (%d)", ErrRelay, out.Error.Message, out.Error.Code)
+	}
+	if out.Result == nil {
+		return common.Hash{}, fmt.Errorf("%w: empty result (%s)", ErrRelay, resp.Status)
+	}
+	return out.Result.BundleHash, nil
+}
+
+// sign returns "address:signature" over the hex keccak of body, as Flashbots expects
+func (c *Client) sign(body []byte) (string, error) {
+	digest := accounts.TextHash([]byte(crypto.Keccak256Hash(body).Hex()))
+	sig, err := crypto.Sign(digest, c.key)
+	if err != nil {
+		return "", err
+	}
+	return crypto.PubkeyToAddress(c.key.PublicKey).Hex() + ":" + hexutil.Encode(sig), nil
+}
@@ -0,0 +1,129 @@
+package mevshare
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// DefaultStreamURL is the public MEV-Share SSE endpoint
+const DefaultStreamURL = "https://mev-share.flashbots.net"
+
+// Hint is one pending transaction or bundle as shared by MEV-Share. Users
+// choose what to reveal, so any field other than Hash may be empty.
+type Hint struct {
+	Hash        common.Hash  `json:"hash"`
+	Logs        []HintLog    `json:"logs"`
+	Txs         []HintTx     `json:"txs"`
+	MevGasPrice *hexutil.Big `json:"mevGasPrice,omitempty"`
+	GasUsed     *hexutil.Big `json:"gasUsed,omitempty"`
+}
+
+// HintLog is a log the pending transaction will emit
+type HintLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+// HintTx is the revealed part of a pending transaction
+type HintTx struct {
+	To               *common.Address `json:"to,omitempty"`
+	FunctionSelector hexutil.Bytes   `json:"functionSelector,omitempty"`
+	CallData         hexutil.Bytes   `json:"callData,omitempty"`
+}
+
+// Stream reads hints from the MEV-Share event stream, reconnecting when it drops
+type Stream struct {
+	url    string
+	client *http.Client
+
+	// Backoff is the delay before reconnecting
+	Backoff time.Duration
+}
+
+// NewStream creates a stream consumer; an empty url uses DefaultStreamURL
+func NewStream(url string) *Stream {
+	if url == "" {
+		url = DefaultStreamURL
+	}
+	return &Stream{url: url, client: &http.Client{}, Backoff: 2 * time.Second}
+}
+
+// Run delivers hints to handle until ctx is cancelled
+func (s *Stream) Run(ctx context.Context, handle func(*Hint)) error {
+	for {
+		err := s.connect(ctx, handle)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("⚠️ MEV-Share stream: %v; reconnecting in %s", err, s.Backoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.Backoff):
+		}
+	}
+}
+
+func (s *Stream) connect(ctx context.Context, handle func(*Hint)) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("stream returned %s", resp.Status)
+	}
+	if err := ReadEvents(resp.Body, handle); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
+
+// ReadEvents parses server-sent events from r and delivers each hint.
+// Comments (keep-alive pings) and events that are not hints are skipped.
+func ReadEvents(r io.Reader, handle func(*Hint)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	var data strings.Builder
+	dispatch := func() {
+		if data.Len() == 0 {
+			return
+		}
+		var h Hint
+		if err := json.Unmarshal([]byte(data.String()), &h); err == nil && h.Hash != (common.Hash{}) {
+			handle(&h)
+		}
+		data.Reset()
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			dispatch()
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	dispatch()
+	return scanner.Err()
+}
@@ -0,0 +1,371 @@
+package mevshare
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/canary"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/commander"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/route"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/simulation"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+func TestReadEvents(t *testing.T) {
+	stream := ":ping\n\n" +
+		"data: {\"hash\":\"0x0000000000000000000000000000000000000000000000000000000000000001\",\"logs\":[{\"address\":\"0x00000000000000000000000000000000000000aa\",\"topics\":[],\"data\":\"0x\"}],\"txs\":null}\n\n" +
+		"data: {\"unrelated\":true}\n\n" +
+		"data: {\"hash\":\"0x0000000000000000000000000000000000000000000000000000000000000002\",\"txs\":[{\"to\":\"0x00000000000000000000000000000000000000bb\",\"functionSelector\":\"0x38ed1739\"}]}\n"
+
+	var hints []*Hint
+	if err := ReadEvents(strings.NewReader(stream), func(h *Hint) { hints = append(hints, h) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(hints) != 2 {
+		t.Fatalf("Expected 2 hints, got %d", len(hints))
+	}
+	if hints[0].Logs[0].Address != common.HexToAddress("0xaa") {
+		t.Errorf("Expected log address 0xaa, got %s", hints[0].Logs[0].Address.Hex())
+	}
+	if got := hexutil.Encode(hints[1].Txs[0].FunctionSelector); got != "0x38ed1739" {
+		t.Errorf("Expected selector 0x38ed1739, got %s", got)
+	}
+}
+
+func TestBackrunFromSyncHint(t *testing.T) {
+	usdc, weth := titantest.Address(0x01), titantest.Address(0x02)
+	poolA, poolB := titantest.Address(0xa0), titantest.Address(0xb0)
+	cache := reserves.NewCache()
+	for _, pool := range []common.Address{poolA, poolB} {
+		cache.Put(&reserves.Snapshot{
+			ChainID: 1, Dex: "UNISWAP_V2", Kind: reserves.KindV2, Pool: pool,
+			Token0: usdc, Token1: weth,
+			Reserve0: titantest.Units(2_000_000, 6), Reserve1: titantest.Units(1000, 18),
+			FeeBps: 30, FetchedAt: time.Now(),
+		})
+	}
+
+	// Without revealed reserves the pending swap cannot be priced
+	b := NewBackrunner(1, cache, weth, titantest.Units(1, 18))
+	if got := b.Candidates(&Hint{Logs: []HintLog{{Address: poolA, Topics: []common.Hash{syncTopic}}}}); len(got) != 0 {
+		t.Errorf("Expected no candidates from a data-less hint, got %d", len(got))
+	}
+
+	// A pending USDC→WETH buy on A leaves WETH dearer there than on B
+	data := append(common.LeftPadBytes(titantest.Units(2_200_000, 6).Bytes(), 32), common.LeftPadBytes(titantest.Units(910, 18).Bytes(), 32)...)
+	hint := &Hint{Hash: common.HexToHash("0xfeed"), Logs: []HintLog{{Address: poolA, Topics: []common.Hash{syncTopic}, Data: data}}}
+	b.ToUSD = func(wei *big.Int) (units.USD, error) { return units.DollarsToUSD(200), nil }
+
+	candidates := b.Candidates(hint)
+	if len(candidates) == 0 {
+		t.Fatal("Expected a backrun candidate")
+	}
+	best := candidates[0]
+	if best.Route[0].Pool != poolA || best.Route[1].Pool != poolB {
+		t.Errorf("Expected to sell WETH into A and buy back on B, got %s then %s", best.Route[0].Pool.Hex(), best.Route[1].Pool.Hex())
+	}
+	if best.Profit.Sign() <= 0 || best.Opportunity.Explanation.Score <= 0 {
+		t.Errorf("Expected positive profit and score, got %s and %f", best.Profit, best.Opportunity.Explanation.Score)
+	}
+	if best.Opportunity.Explanation.GrossProfitUSD != units.DollarsToUSD(200) {
+		t.Errorf("Expected $200 gross profit, got %s", best.Opportunity.Explanation.GrossProfitUSD)
+	}
+	if c := best.Opportunity.Explanation.Costs; c == nil || len(c.DEX) != 2 || c.DEX[0].USD != 600_000 {
+		t.Errorf("Expected each 30 bps pool fee charged on the $200 size, got %+v", c)
+	}
+
+	// Gas is charged before scoring, so a backrun that gas makes
+	// unprofitable fails the profit floor
+	var priced uint64
+	b.GasCostUSD = func(gasUnits uint64) (units.USD, error) {
+		priced = gasUnits
+		return units.DollarsToUSD(250), nil
+	}
+	costly := b.Candidates(hint)
+	if len(costly) == 0 {
+		t.Fatal("Expected the candidate quoted before gas is gated")
+	}
+	e := costly[0].Opportunity.Explanation
+	if priced != DefaultBackrunGas || e.GasUSD != units.DollarsToUSD(250) || e.Costs.SourceGasUSD != e.GasUSD {
+		t.Errorf("Expected $250 of gas on the default backrun gas, got %s over %d units (%+v)", e.GasUSD, priced, e.Costs)
+	}
+	if e.NetProfitUSD >= 0 || e.GateProfit(units.DollarsToUSD(10)) {
+		t.Errorf("Expected the gas-negative backrun rejected, got net %s", e.NetProfitUSD)
+	}
+	b.GasCostUSD = nil
+
+	// The cached state itself is untouched
+	if s, _ := cache.Get(1, poolA, 0); s.Reserve1.Cmp(titantest.Units(1000, 18)) != 0 {
+		t.Errorf("Expected cached reserves unchanged, got %s", s.Reserve1)
+	}
+
+	// An unproven route is requoted at the canary size
+	b.Canary = canary.New(1, 1000, nil)
+	canaries := b.Candidates(hint)
+	if len(canaries) == 0 || canaries[0].AmountIn.Cmp(titantest.Units(1, 17)) != 0 {
+		t.Fatalf("Expected a 0.1 WETH canary, got %+v", canaries)
+	}
+	if got := canaries[0].Opportunity.Explanation.BindingGuardrail(); got != canary.GuardrailCanary {
+		t.Errorf("Expected the canary guardrail bound, got %q", got)
+	}
+	if canaries[0].Profit.Cmp(best.Profit) >= 0 {
+		t.Errorf("Expected less profit at the canary size, got %s", canaries[0].Profit)
+	}
+}
+
+func TestSendBundleSignsRequest(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	hint := common.HexToHash("0xfeed")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		parts := strings.SplitN(r.Header.Get("X-Flashbots-Signature"), ":", 2)
+		sig, _ := hexutil.Decode(parts[1])
+		pub, err := crypto.SigToPub(accounts.TextHash([]byte(crypto.Keccak256Hash(body).Hex())), sig)
+		if err != nil || crypto.PubkeyToAddress(*pub).Hex() != parts[0] {
+			w.Write([]byte(`{"error":{"code":-32600,"message":"bad signature"}}`))
+			return
+		}
+		var req struct {
+			Method string    `json:"method"`
+			Params []*Bundle `json:"params"`
+		}
+		json.Unmarshal(body, &req)
+		if req.Method != "mev_sendBundle" || *req.Params[0].Body[0].Hash != hint || req.Params[0].Inclusion.MaxBlock != 102 {
+			w.Write([]byte(`{"error":{"code":-32602,"message":"bad bundle"}}`))
+			return
+		}
+		w.Write([]byte(`{"result":{"bundleHash":"0x00000000000000000000000000000000000000000000000000000000000000bb"}}`))
+	}))
+	defer server.Close()
+
+	got, err := NewClient(server.URL, key).SendBundle(context.Background(), NewBackrunBundle(hint, []byte{0x02}, 100, 3))
+	if err != nil {
+		t.Fatalf("SendBundle failed: %v", err)
+	}
+	if got != common.HexToHash("0xbb") {
+		t.Errorf("Expected bundle hash 0xbb, got %s", got.Hex())
+	}
+}
+
+// submitNode answers the RPC a submitter makes, keeping the overrides its
+// simulation was sent
+type submitNode struct {
+	slot8     common.Hash
+	revert    bool
+	overrides simulation.Overrides
+}
+
+// revertError mimics the JSON-RPC error a node returns for a reverted call
+type revertError struct{}
+
+func (revertError) Error() string  { return "execution reverted: ROUTE_MIN_OUT" }
+func (revertError) ErrorCode() int { return 3 }
+
+func (n *submitNode) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	switch method {
+	case "eth_getStorageAt":
+		*result.(*hexutil.Bytes) = n.slot8.Bytes()
+	case "eth_call":
+		n.overrides = args[2].(simulation.Overrides)
+		if n.revert {
+			return revertError{}
+		}
+		*result.(*hexutil.Bytes) = nil
+	case "eth_blockNumber":
+		*result.(*hexutil.Uint64) = 100
+	case "eth_getTransactionCount":
+		*result.(*hexutil.Uint64) = 7
+	default:
+		return errors.New("unexpected " + method)
+	}
+	return nil
+}
+
+// capSizer caps every loan at max, as the lender's liquidity would
+type capSizer struct{ max *big.Int }
+
+func (s capSizer) SizeLoan(token common.Address, amount *big.Int, decimals uint8) (*commander.SizingDecision, error) {
+	d := &commander.SizingDecision{Token: token, Decimals: decimals, Requested: amount, Amount: amount}
+	if amount.Cmp(s.max) > 0 {
+		d.Amount, d.BoundBy = s.max, commander.GuardrailMaxTVLShare
+	}
+	return d, nil
+}
+
+func TestSubmitterSendsBackrunBundle(t *testing.T) {
+	usdc, weth := titantest.Address(0x01), titantest.Address(0x02)
+	poolA, poolB, contract := titantest.Address(0xa0), titantest.Address(0xb0), titantest.Address(0xe0)
+	cache := reserves.NewCache()
+	for _, pool := range []common.Address{poolA, poolB} {
+		cache.Put(&reserves.Snapshot{
+			ChainID: 1, Dex: "UNISWAP_V2", Kind: reserves.KindV2, Pool: pool,
+			Token0: usdc, Token1: weth,
+			Reserve0: titantest.Units(2_000_000, 6), Reserve1: titantest.Units(1000, 18),
+			FeeBps: 30, FetchedAt: time.Now(),
+		})
+	}
+	reserve0, reserve1 := titantest.Units(2_200_000, 6), titantest.Units(910, 18)
+	data := append(common.LeftPadBytes(reserve0.Bytes(), 32), common.LeftPadBytes(reserve1.Bytes(), 32)...)
+	hint := &Hint{Hash: common.HexToHash("0xfeed"), Logs: []HintLog{{Address: poolA, Topics: []common.Hash{syncTopic}, Data: data}}}
+	b := NewBackrunner(1, cache, weth, titantest.Units(1, 18))
+	b.Payment = &PaymentPolicy{ShareBps: 5000}
+	candidates := b.Candidates(hint)
+	if len(candidates) == 0 {
+		t.Fatal("Expected a backrun candidate")
+	}
+
+	var sent *Bundle
+	relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string    `json:"method"`
+			Params []*Bundle `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method == "mev_sendBundle" && len(req.Params) == 1 {
+			sent = req.Params[0]
+		}
+		w.Write([]byte(`{"result":{"bundleHash":"0x00000000000000000000000000000000000000000000000000000000000000bb"}}`))
+	}))
+	defer relay.Close()
+
+	// The pair's last update time sits above its reserves and survives the override
+	node := &submitNode{slot8: common.BigToHash(new(big.Int).Lsh(big.NewInt(1_700_000_000), 224))}
+	sim := simulation.NewCallSimulator(titantest.NewBackend(1))
+	sim.RPC = node
+	half := titantest.Units(1, 17)
+	s := NewSubmitter(b, contract, route.FlashBalancer, node, capSizer{max: half}, simulation.NewRunner(simulation.Policy{Default: simulation.DepthCall}, sim))
+	layout := simulation.TokenLayout{BalanceSlot: 3}
+	s.Layout = func(context.Context, common.Address) (simulation.TokenLayout, error) { return layout, nil }
+
+	// The executor swaps through the DEX's router, never the pair
+	if _, err := s.Size(hint, candidates[0]); !errors.Is(err, ErrNoRouter) {
+		t.Errorf("Expected ErrNoRouter without the DEX's router, got %v", err)
+	}
+	router := titantest.Address(0xd0)
+	s.Routers = map[string]common.Address{"UNISWAP_V2": router}
+	backrun, err := s.Size(hint, candidates[0])
+	if err != nil {
+		t.Fatalf("Size failed: %v", err)
+	}
+	for i, step := range backrun.Route.Steps {
+		if step.Router != router || step.Target() != router {
+			t.Errorf("Expected hop %d sent through the V2 router, got %+v", i, step)
+		}
+	}
+	if backrun.Route.AmountIn.Cmp(half) != 0 || backrun.Sizing.BoundBy != commander.GuardrailMaxTVLShare {
+		t.Errorf("Expected the loan capped at 0.1 WETH, got %s bound by %q", backrun.Route.AmountIn, backrun.Sizing.BoundBy)
+	}
+	if err := s.Simulate(context.Background(), backrun); err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	packed := new(big.Int).Lsh(big.NewInt(1_700_000_000), 112)
+	packed.Or(packed, reserve1).Lsh(packed, 112).Or(packed, reserve0)
+	if got := node.overrides[poolA].StateDiff[v2ReservesSlot]; got != common.BigToHash(packed) {
+		t.Errorf("Expected the hinted reserves under the kept timestamp, got %s", got.Hex())
+	}
+	if got := node.overrides[weth].StateDiff[layout.BalanceKey(poolA)]; got.Big().Cmp(reserve1) != 0 {
+		t.Errorf("Expected the pair's WETH balance at its new reserve, got %s", got.Big())
+	}
+
+	// Watch-only submitters hold no key
+	if _, err := s.Sign(context.Background(), backrun); !errors.Is(err, ErrNoSigner) {
+		t.Errorf("Expected ErrNoSigner without a key, got %v", err)
+	}
+
+	key, _ := crypto.GenerateKey()
+	s.Key, s.Sender, s.Relay = key, crypto.PubkeyToAddress(key.PublicKey), NewClient(relay.URL, key)
+	s.Fees = func(context.Context) (gas.Fees, error) {
+		return gas.Fees{TipCap: big.NewInt(1e9), FeeCap: big.NewInt(30e9)}, nil
+	}
+	sub, err := s.Sign(context.Background(), backrun)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if sent != nil {
+		t.Fatalf("Expected nothing sent before Send, got %+v", sent)
+	}
+	if err := s.Send(context.Background(), sub); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if sent == nil || len(sent.Body) != 2 || *sent.Body[0].Hash != hint.Hash || sent.Inclusion.Block != 101 {
+		t.Fatalf("Expected a bundle behind the hinted transaction for block 101, got %+v", sent)
+	}
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(sent.Body[1].Tx); err != nil {
+		t.Fatal(err)
+	}
+	from, _ := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), &tx)
+	call, err := route.UnpackExecute(tx.Data())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Hash() != sub.Tx || *tx.To() != contract || tx.Nonce() != 7 || from != s.Sender || call.Amount.Cmp(half) != 0 {
+		t.Errorf("Expected nonce 7 from the signer borrowing 0.1 WETH through the executor, got %+v from %s", call, from.Hex())
+	}
+	// The builder is paid through the tip, over the quoted headroom
+	tip := PriorityFee(backrun.Payment, backrun.GasUsed)
+	if backrun.Payment.Sign() == 0 || tx.GasTipCap().Cmp(tip) != 0 || tx.GasFeeCap().Cmp(new(big.Int).Add(big.NewInt(29e9), tip)) != 0 {
+		t.Errorf("Expected a %s wei tip paying %s to the builder, got tip %s cap %s", tip, backrun.Payment, tx.GasTipCap(), tx.GasFeeCap())
+	}
+
+	if sub.Bundle != common.HexToHash("0xbb") {
+		t.Errorf("Expected bundle hash 0xbb, got %s", sub.Bundle.Hex())
+	}
+
+	// A backrun reverting against the post-swap state fails simulation
+	node.revert = true
+	if err := s.Simulate(context.Background(), backrun); !errors.Is(err, ErrSimulation) {
+		t.Errorf("Expected ErrSimulation, got %v", err)
+	}
+}
+
+func TestPaymentPolicyCapsShare(t *testing.T) {
+	profit := titantest.Units(1, 18)
+	usdPerEth := func(wei *big.Int) (units.USD, error) {
+		// $2000 per ETH
+		usd := new(big.Int).Mul(wei, big.NewInt(int64(units.DollarsToUSD(2000))))
+		return units.USD(usd.Quo(usd, titantest.Units(1, 18)).Int64()), nil
+	}
+
+	p := PaymentPolicy{ShareBps: 5000}
+	got, err := p.Payment(profit, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(titantest.Units(1, 18).Div(titantest.Units(1, 18), big.NewInt(2))) != 0 {
+		t.Errorf("Expected half the profit, got %s", got)
+	}
+
+	// $1000 share capped at $250 pays a quarter of the share
+	p.MaxUSD = units.DollarsToUSD(250)
+	got, err = p.Payment(profit, usdPerEth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usd, _ := usdPerEth(got); usd != units.DollarsToUSD(250) {
+		t.Errorf("Expected payment capped at $250, got %s", usd)
+	}
+	if _, err := p.Payment(profit, nil); !errors.Is(err, ErrUnpricedCap) {
+		t.Errorf("Expected ErrUnpricedCap, got %v", err)
+	}
+
+	if tip := PriorityFee(big.NewInt(300_000), 150_000); tip.Int64() != 2 {
+		t.Errorf("Expected 2 wei per gas, got %s", tip)
+	}
+}
@@ -0,0 +1,60 @@
+package mevshare
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// ErrUnpricedCap is returned when a USD payment cap cannot be checked for lack of a price
+var ErrUnpricedCap = errors.New("mevshare: builder payment cap needs a price")
+
+// PaymentPolicy decides what a bundle pays the block builder, as the
+// backrun's priority fee. A larger share wins inclusion more often at the
+// cost of margin.
+type PaymentPolicy struct {
+	ShareBps uint64    // share of expected profit paid
+	MaxUSD   units.USD // cap per bundle, 0 uncapped
+}
+
+// PaymentPolicyFromConfig builds the configured policy
+func PaymentPolicyFromConfig(cfg *config.BuilderPaymentConfig) (PaymentPolicy, error) {
+	if err := cfg.Validate(); err != nil {
+		return PaymentPolicy{}, err
+	}
+	return PaymentPolicy{ShareBps: cfg.ShareBps, MaxUSD: units.DollarsToUSD(cfg.MaxUSD)}, nil
+}
+
+// Payment returns the builder's cut of profit, in the same token, scaled down
+// to MaxUSD when it exceeds the cap. toUSD values amounts of that token.
+func (p PaymentPolicy) Payment(profit *big.Int, toUSD func(*big.Int) (units.USD, error)) (*big.Int, error) {
+	if profit.Sign() <= 0 || p.ShareBps == 0 {
+		return new(big.Int), nil
+	}
+	payment := units.MulBps(profit, p.ShareBps)
+	if p.MaxUSD <= 0 {
+		return payment, nil
+	}
+	if toUSD == nil {
+		return nil, ErrUnpricedCap
+	}
+	usd, err := toUSD(payment)
+	if err != nil {
+		return nil, err
+	}
+	if usd > p.MaxUSD {
+		payment.Mul(payment, big.NewInt(int64(p.MaxUSD)))
+		payment.Quo(payment, big.NewInt(int64(usd)))
+	}
+	return payment, nil
+}
+
+// PriorityFee spreads payment over gasUsed as a per-gas tip
+func PriorityFee(payment *big.Int, gasUsed uint64) *big.Int {
+	if gasUsed == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Quo(payment, new(big.Int).SetUint64(gasUsed))
+}
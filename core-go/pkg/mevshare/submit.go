@@ -0,0 +1,379 @@
+package mevshare
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/commander"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/execution"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/route"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/simulation"
+)
+
+// DefaultBackrunGas is the gas limit of backruns the gas history can't predict
+const DefaultBackrunGas = 400_000
+
+// gasBufferBps pads predicted backrun gas, as node estimates are padded
+const gasBufferBps = 12000
+
+var (
+	// ErrNoSigner is returned when submitting without a key or relay, as in
+	// watch-only mode
+	ErrNoSigner = errors.New("mevshare: no signer to submit backruns")
+	// ErrSizing is returned when the sizing guardrails leave no profitable backrun
+	ErrSizing = errors.New("mevshare: sizing rejected backrun")
+	// ErrSimulation is returned when the backrun fails simulation
+	ErrSimulation = errors.New("mevshare: backrun failed simulation")
+	// ErrNoRouter is returned for a hop through a DEX with no router
+	// configured, as the executor swaps through routers, not pairs
+	ErrNoRouter = errors.New("mevshare: no router for hop")
+)
+
+var (
+	// v2ReservesSlot holds a UniswapV2 pair's reserve0, reserve1 and last
+	// update time, packed low to high
+	v2ReservesSlot = common.BigToHash(big.NewInt(8))
+	// v3Slot0 packs a UniswapV3 pool's price and tick under its oracle
+	// indexes and reentrancy lock; v3LiquiditySlot holds its active liquidity
+	v3Slot0         = common.Hash{}
+	v3LiquiditySlot = common.BigToHash(big.NewInt(4))
+)
+
+// Sizer bounds a flash loan by the lender's liquidity and the sizing
+// guardrails; *commander.TitanCommander satisfies it
+type Sizer interface {
+	SizeLoan(token common.Address, amount *big.Int, decimals uint8) (*commander.SizingDecision, error)
+}
+
+// Backrun is a candidate on its way to the relay
+type Backrun struct {
+	Hint      *Hint
+	Candidate *Candidate
+	Sizing    *commander.SizingDecision
+	Route     *route.Route
+	Data      []byte // the executor's execute calldata
+	GasUsed   uint64 // predicted; the builder payment is spread over it
+	GasLimit  uint64
+	Payment   *big.Int           // owed to the builder, in wei
+	Result    *simulation.Result // nil until simulated
+}
+
+// Submission is a signed backrun on its way to the relay
+type Submission struct {
+	Tx     common.Hash
+	From   common.Address
+	Nonce  uint64
+	Block  uint64      // chain head when signed; the bundle targets the next one
+	Bundle common.Hash // set once sent
+
+	raw  []byte
+	hint common.Hash
+}
+
+// Submitter carries backruns through sizing, simulation and submission. The
+// executor flash-borrows the sized amount, the transaction is simulated
+// against the state the hinted swap leaves its pools in, and the signed
+// backrun goes to the relay bundled right behind the hinted transaction.
+type Submitter struct {
+	chainID    uint64
+	contract   common.Address
+	source     route.FlashSource
+	node       simulation.RPC
+	sizer      Sizer
+	runner     *simulation.Runner
+	backrunner *Backrunner
+
+	// Sender calls the executor in simulation; set it to the executor's
+	// owner, which is Key's address when a key is loaded
+	Sender common.Address
+	// Key signs backruns and Relay takes the bundles; Submit refuses
+	// without them
+	Key   *ecdsa.PrivateKey
+	Relay *Client
+	// Adapter signs the backrun in the chain's format
+	Adapter execution.Adapter
+	// Fees quotes the backrun's fees; Submit refuses without it
+	Fees func(ctx context.Context) (gas.Fees, error)
+	// Routers are the DEX routers the executor swaps through, by the DEX
+	// names pool snapshots carry; Size refuses hops through any other DEX
+	Routers map[string]common.Address
+	// Blocks is how many blocks a bundle stays valid for
+	Blocks uint64
+	// Layout finds where a token keeps balances, so pool balances can be
+	// overridden; results are cached per token
+	Layout func(ctx context.Context, token common.Address) (simulation.TokenLayout, error)
+
+	mu      sync.Mutex
+	layouts map[common.Address]simulation.TokenLayout
+}
+
+// NewSubmitter creates a submitter for backrunner's candidates through the
+// executor contract, borrowing from source. node reads pool storage, nonces
+// and heads; the runner must simulate at call depth or deeper, since
+// backruns are simulated against overridden pool state.
+func NewSubmitter(backrunner *Backrunner, contract common.Address, source route.FlashSource, node simulation.RPC, sizer Sizer, runner *simulation.Runner) *Submitter {
+	s := &Submitter{
+		chainID:    backrunner.chainID,
+		contract:   contract,
+		source:     source,
+		node:       node,
+		sizer:      sizer,
+		runner:     runner,
+		backrunner: backrunner,
+		Adapter:    execution.For(backrunner.chainID),
+		Blocks:     1,
+		layouts:    make(map[common.Address]simulation.TokenLayout),
+	}
+	s.Layout = func(ctx context.Context, token common.Address) (simulation.TokenLayout, error) {
+		return simulation.FindLayout(ctx, node, token)
+	}
+	return s
+}
+
+// Size bounds c by the sizing guardrails, requoting it when they shrink it,
+// and encodes the executor call through each hop's DEX router. The route's minOut covers the loan and the
+// builder payment, so the executor reverts rather than pay out of principal.
+func (s *Submitter) Size(h *Hint, c *Candidate) (*Backrun, error) {
+	decision, err := s.sizer.SizeLoan(s.backrunner.start, c.AmountIn, 18)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSizing, err)
+	}
+	if decision.Amount == nil || decision.Amount.Sign() == 0 {
+		return nil, fmt.Errorf("%w: bound by %s", ErrSizing, decision.BoundBy)
+	}
+	amountIn, payment := decision.Amount, c.BuilderPayment
+	if amountIn.Cmp(c.AmountIn) != 0 {
+		out, err := c.Route.Quote(amountIn)
+		if err != nil || out.Cmp(amountIn) <= 0 {
+			return nil, fmt.Errorf("%w: unprofitable at %s (bound by %s)", ErrSizing, amountIn, decision.BoundBy)
+		}
+		payment = scale(payment, amountIn, c.AmountIn)
+	}
+
+	r := &route.Route{TokenIn: s.backrunner.start, AmountIn: amountIn, Steps: make([]route.Step, len(c.Route))}
+	r.MinOut = new(big.Int).Add(amountIn, payment)
+	for i, hop := range c.Route {
+		var dex string
+		if legs := c.Opportunity.Explanation.Legs; i < len(legs) {
+			dex = legs[i].Dex
+		}
+		router, ok := s.Routers[dex]
+		if !ok || router == (common.Address{}) {
+			return nil, fmt.Errorf("%w %d through %s on %q", ErrNoRouter, i, hop.Pool.Hex(), dex)
+		}
+		step := route.Step{Adapter: route.AdapterUniV2, Router: router, Pool: hop.Pool, TokenOut: hop.TokenOut}
+		if i < len(c.Adapters) && c.Adapters[i] != 0 {
+			step.Adapter = c.Adapters[i]
+		}
+		if i == 0 {
+			step.AmountIn = amountIn
+		}
+		// V3 adapters take the fee tier, in hundredths of a basis point
+		if step.Adapter == route.AdapterUniV3 && hop.State != nil {
+			step.Extra = route.V3Extra(uint32(hop.State.FeeBps * 100))
+		}
+		r.Steps[i] = step
+	}
+	if err := r.CheckRepayable(s.source); err != nil {
+		return nil, err
+	}
+	data, err := route.EncodeExecute(s.source, r)
+	if err != nil {
+		return nil, err
+	}
+	gasUsed := uint64(DefaultBackrunGas)
+	if units := c.Opportunity.Explanation.GasUnits; units > 0 {
+		gasUsed = units
+	}
+	return &Backrun{
+		Hint:      h,
+		Candidate: c,
+		Sizing:    decision,
+		Route:     r,
+		Data:      data,
+		GasUsed:   gasUsed,
+		GasLimit:  gasUsed * gasBufferBps / 10000,
+		Payment:   payment,
+	}, nil
+}
+
+// Simulate calls the executor as Sender against the hinted transaction's
+// post-swap state, recording the result on b
+func (s *Submitter) Simulate(ctx context.Context, b *Backrun) error {
+	overrides, err := s.overrides(ctx, b.Hint)
+	if err != nil {
+		return err
+	}
+	res, err := s.runner.Simulate(ctx, simulation.Candidate{
+		ChainID:           s.chainID,
+		Bundle:            []simulation.Call{{From: s.Sender, To: s.contract, Data: b.Data, Gas: b.GasLimit}},
+		ExpectedProfitUSD: b.Candidate.Opportunity.Explanation.NetProfitUSD,
+		Overrides:         overrides,
+	})
+	if err != nil {
+		return err
+	}
+	b.Result = res
+	if !res.OK {
+		return fmt.Errorf("%w at %s depth: %s", ErrSimulation, res.Depth, res.Reason)
+	}
+	return nil
+}
+
+// Sign signs b to follow the hinted transaction from the next block. The
+// builder payment is the transaction's priority fee, spread over its
+// predicted gas. Nothing is sent: record the submission, then Send it.
+func (s *Submitter) Sign(ctx context.Context, b *Backrun) (*Submission, error) {
+	if s.Key == nil || s.Relay == nil || s.Fees == nil {
+		return nil, ErrNoSigner
+	}
+	from := execution.Sender(s.Key)
+	var head, nonce hexutil.Uint64
+	if err := s.node.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+		return nil, fmt.Errorf("head: %w", err)
+	}
+	if err := s.node.CallContext(ctx, &nonce, "eth_getTransactionCount", from, "pending"); err != nil {
+		return nil, fmt.Errorf("nonce: %w", err)
+	}
+	fees, err := s.Fees(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fees: %w", err)
+	}
+	if fees, err = payBuilder(fees, b.Payment, b.GasUsed); err != nil {
+		return nil, err
+	}
+	call := execution.Call{
+		ChainID:  s.chainID,
+		From:     from,
+		To:       s.contract,
+		Data:     b.Data,
+		Nonce:    uint64(nonce),
+		GasLimit: b.GasLimit,
+		Fees:     fees,
+	}
+	raw, hash, err := s.Adapter.Sign(call, s.Key)
+	if err != nil {
+		return nil, err
+	}
+	return &Submission{Tx: hash, From: from, Nonce: uint64(nonce), Block: uint64(head), raw: raw, hint: b.Hint.Hash}, nil
+}
+
+// Send bundles a signed backrun behind its hinted transaction, valid from
+// the block after it was signed for Blocks blocks
+func (s *Submitter) Send(ctx context.Context, sub *Submission) error {
+	if s.Relay == nil {
+		return ErrNoSigner
+	}
+	bundle, err := s.Relay.SendBundle(ctx, NewBackrunBundle(sub.hint, sub.raw, sub.Block+1, s.Blocks))
+	if err != nil {
+		return err
+	}
+	sub.Bundle = bundle
+	return nil
+}
+
+// payBuilder replaces the quoted tip with payment spread over gasUsed,
+// raising the fee cap to keep the quoted headroom over the base fee. Without
+// a payment the quoted fees stand.
+func payBuilder(fees gas.Fees, payment *big.Int, gasUsed uint64) (gas.Fees, error) {
+	if payment == nil || payment.Sign() == 0 {
+		return fees, nil
+	}
+	if fees.TipCap == nil || fees.FeeCap == nil {
+		return gas.Fees{}, errors.New("fees: no fee cap to add the builder payment to")
+	}
+	tip := PriorityFee(payment, gasUsed)
+	feeCap := new(big.Int).Sub(fees.FeeCap, fees.TipCap)
+	fees.TipCap, fees.FeeCap = tip, feeCap.Add(feeCap, tip)
+	return fees, nil
+}
+
+// overrides returns the state the hinted transaction leaves its pools in,
+// as eth_call overrides: V2 pairs get the reserves of their Sync log and
+// token balances to match, V3 pools the price, tick and liquidity of their
+// Swap log
+func (s *Submitter) overrides(ctx context.Context, h *Hint) (simulation.Overrides, error) {
+	over := make(simulation.Overrides)
+	for _, l := range h.Logs {
+		if len(l.Topics) == 0 {
+			continue
+		}
+		pool, ok := s.backrunner.cache.Get(s.chainID, l.Address, 0)
+		if !ok {
+			continue
+		}
+		switch {
+		case l.Topics[0] == syncTopic && pool.Kind == reserves.KindV2 && len(l.Data) >= 64:
+			current, err := s.storage(ctx, l.Address, v2ReservesSlot)
+			if err != nil {
+				return nil, err
+			}
+			reserve0, reserve1 := new(big.Int).SetBytes(l.Data[:32]), new(big.Int).SetBytes(l.Data[32:64])
+			packed := new(big.Int).Rsh(current.Big(), 224)
+			packed.Lsh(packed, 112).Or(packed, reserve1)
+			packed.Lsh(packed, 112).Or(packed, reserve0)
+			over.SetStorage(l.Address, v2ReservesSlot, common.BigToHash(packed))
+			if err := s.fund(ctx, over, pool.Token0, l.Address, reserve0); err != nil {
+				return nil, err
+			}
+			if err := s.fund(ctx, over, pool.Token1, l.Address, reserve1); err != nil {
+				return nil, err
+			}
+		case l.Topics[0] == swapV3Topic && pool.Kind == reserves.KindV3 && len(l.Data) >= 160:
+			current, err := s.storage(ctx, l.Address, v3Slot0)
+			if err != nil {
+				return nil, err
+			}
+			packed := new(big.Int).Rsh(current.Big(), 184)
+			packed.Lsh(packed, 24).Or(packed, new(big.Int).SetBytes(l.Data[157:160]))
+			packed.Lsh(packed, 160).Or(packed, new(big.Int).SetBytes(l.Data[64:96]))
+			over.SetStorage(l.Address, v3Slot0, common.BigToHash(packed))
+			over.SetStorage(l.Address, v3LiquiditySlot, common.BytesToHash(l.Data[96:128]))
+		}
+	}
+	return over, nil
+}
+
+// fund overrides holder's balance of token
+func (s *Submitter) fund(ctx context.Context, over simulation.Overrides, token, holder common.Address, amount *big.Int) error {
+	s.mu.Lock()
+	layout, ok := s.layouts[token]
+	s.mu.Unlock()
+	if !ok {
+		var err error
+		if layout, err = s.Layout(ctx, token); err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.layouts[token] = layout
+		s.mu.Unlock()
+	}
+	over.Fund(token, layout, holder, amount)
+	return nil
+}
+
+// storage reads one slot of addr at the latest block
+func (s *Submitter) storage(ctx context.Context, addr common.Address, slot common.Hash) (common.Hash, error) {
+	var out hexutil.Bytes
+	if err := s.node.CallContext(ctx, &out, "eth_getStorageAt", addr, slot, "latest"); err != nil {
+		return common.Hash{}, fmt.Errorf("storage of %s: %w", addr.Hex(), err)
+	}
+	return common.BytesToHash(out), nil
+}
+
+// scale returns v×num/den; nil stays nil
+func scale(v, num, den *big.Int) *big.Int {
+	if v == nil || den.Sign() == 0 {
+		return v
+	}
+	out := new(big.Int).Mul(v, num)
+	return out.Quo(out, den)
+}
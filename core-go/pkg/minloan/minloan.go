@@ -0,0 +1,149 @@
+package minloan
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Settings shapes the floor
+type Settings struct {
+	EdgeBps    uint64 // spread a trade earns over its size after fees
+	FloorUSD   units.USD
+	CeilingUSD units.USD
+}
+
+// Floor returns the smallest trade whose edge pays gasUSD, within bounds
+func (s Settings) Floor(gasUSD units.USD) units.USD {
+	floor := units.USD(0)
+	if s.EdgeBps > 0 {
+		floor = gasUSD * 10000 / units.USD(s.EdgeBps)
+	}
+	if floor < s.FloorUSD {
+		floor = s.FloorUSD
+	}
+	if floor > s.CeilingUSD {
+		floor = s.CeilingUSD
+	}
+	return floor
+}
+
+// GasCost prices one typical trade's gas on a chain
+type GasCost func(ctx context.Context, chainID uint64) (units.USD, error)
+
+// Floor is a chain's minimum trade size and the gas cost it was derived from
+type Floor struct {
+	ChainID   uint64    `json:"chainId"`
+	GasUSD    units.USD `json:"gasUsd"`
+	FloorUSD  units.USD `json:"floorUsd"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Floors keeps each chain's minimum trade size in line with its gas cost, so
+// a chain where gas costs cents trades far smaller sizes than Ethereum. A
+// chain is held to the static MinLoanUSD guardrail until its gas cost is
+// known, and a nil Floors holds every chain to it. It is safe for concurrent
+// use.
+type Floors struct {
+	settings Settings
+	cost     GasCost
+
+	mu     sync.Mutex
+	floors map[uint64]Floor
+	now    func() time.Time
+
+	gauge *metrics.GaugeVec
+}
+
+// New creates floors priced by cost; reg may be nil
+func New(s Settings, cost GasCost, reg *metrics.Registry) *Floors {
+	f := &Floors{settings: s, cost: cost, floors: make(map[uint64]Floor), now: time.Now}
+	if reg != nil {
+		f.gauge = reg.Gauge("titan_min_loan_usd", "Minimum trade size derived from gas cost", "chain")
+	}
+	return f
+}
+
+// FromConfig creates the configured floors, nil when not dynamic
+func FromConfig(cfg *config.MinLoanConfig, cost GasCost, reg *metrics.Registry) *Floors {
+	if cfg == nil || !cfg.Dynamic {
+		return nil
+	}
+	return New(Settings{
+		EdgeBps:    cfg.EdgeBps,
+		FloorUSD:   units.DollarsToUSD(cfg.FloorUSD),
+		CeilingUSD: units.DollarsToUSD(cfg.CeilingUSD),
+	}, cost, reg)
+}
+
+// Run refreshes chains every interval until ctx is done
+func (f *Floors) Run(ctx context.Context, interval time.Duration, chains ...uint64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, chainID := range chains {
+			if err := f.Refresh(ctx, chainID); err != nil && ctx.Err() == nil {
+				log.Printf("⚠️ Min loan on chain %d: %v", chainID, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh re-derives chainID's floor from its current gas cost; on error the
+// previous floor stands
+func (f *Floors) Refresh(ctx context.Context, chainID uint64) error {
+	gasUSD, err := f.cost(ctx, chainID)
+	if err != nil {
+		return err
+	}
+	floor := Floor{ChainID: chainID, GasUSD: gasUSD, FloorUSD: f.settings.Floor(gasUSD), UpdatedAt: f.now().UTC()}
+	f.mu.Lock()
+	f.floors[chainID] = floor
+	f.mu.Unlock()
+	if f.gauge != nil {
+		f.gauge.Set(floor.FloorUSD.Float(), strconv.FormatUint(chainID, 10))
+	}
+	return nil
+}
+
+// Floors returns every priced chain's floor, by chain
+func (f *Floors) Floors() []Floor {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]Floor, 0, len(f.floors))
+	for _, floor := range f.floors {
+		out = append(out, floor)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ChainID < out[j].ChainID })
+	return out
+}
+
+// Adjust replaces g's min loan with chainID's floor, rounded up to whole
+// dollars, once the chain's gas cost is known
+func (f *Floors) Adjust(chainID uint64, g config.Guardrails) config.Guardrails {
+	if f == nil {
+		return g
+	}
+	f.mu.Lock()
+	floor, ok := f.floors[chainID]
+	f.mu.Unlock()
+	if ok {
+		g.MinLoanUSD = uint64((floor.FloorUSD + units.DollarsToUSD(1) - 1) / units.DollarsToUSD(1))
+	}
+	return g
+}
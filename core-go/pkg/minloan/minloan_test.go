@@ -0,0 +1,65 @@
+package minloan
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+func TestFloorFollowsGasCost(t *testing.T) {
+	s := Settings{EdgeBps: 30, FloorUSD: units.DollarsToUSD(100), CeilingUSD: units.DollarsToUSD(10000)}
+	for _, tc := range []struct {
+		gas, want units.USD
+	}{
+		{units.DollarsToUSD(15), units.DollarsToUSD(5000)},  // $15 of gas needs $5k at 30 bps
+		{units.DollarsToUSD(90), units.DollarsToUSD(10000)}, // capped at the ceiling
+		{units.USD(20_000), units.DollarsToUSD(100)},        // 2 cents: raised to the floor
+	} {
+		if got := s.Floor(tc.gas); got != tc.want {
+			t.Errorf("Floor(%s) = %s, want %s", tc.gas, got, tc.want)
+		}
+	}
+}
+
+func TestAdjustOnlyPricedChains(t *testing.T) {
+	gas := map[uint64]units.USD{1: units.DollarsToUSD(12), 42161: units.USD(1_500_000)}
+	cost := func(ctx context.Context, chainID uint64) (units.USD, error) {
+		usd, ok := gas[chainID]
+		if !ok {
+			return 0, errors.New("no gas price")
+		}
+		return usd, nil
+	}
+	cfg := &config.MinLoanConfig{Dynamic: true, EdgeBps: 30, FloorUSD: 100, CeilingUSD: 10000, RefreshSecs: 60}
+	reg := metrics.NewRegistry()
+	f := FromConfig(cfg, cost, reg)
+	for _, chainID := range []uint64{1, 42161} {
+		if err := f.Refresh(context.Background(), chainID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Refresh(context.Background(), 137); err == nil {
+		t.Error("Expected an unpriced chain to fail")
+	}
+
+	static := config.Guardrails{MinLoanUSD: 10000}
+	if g := f.Adjust(1, static); g.MinLoanUSD != 4000 {
+		t.Errorf("Expected Ethereum's floor at $4k, got %d", g.MinLoanUSD)
+	}
+	if g := f.Adjust(42161, static); g.MinLoanUSD != 500 {
+		t.Errorf("Expected Arbitrum's floor at $500, got %d", g.MinLoanUSD)
+	}
+	if g := f.Adjust(137, static); g.MinLoanUSD != 10000 {
+		t.Errorf("Expected an unpriced chain held to the static floor, got %d", g.MinLoanUSD)
+	}
+	if reg.Value("titan_min_loan_usd", "42161") != 500 {
+		t.Error("Expected the floor gauge set")
+	}
+	if g := (*Floors)(nil).Adjust(1, static); g.MinLoanUSD != 10000 {
+		t.Error("Expected nil floors to keep the static guardrail")
+	}
+}
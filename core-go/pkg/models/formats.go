@@ -0,0 +1,131 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// linear is a weighted sum of features plus a bias
+type linear struct {
+	Bias    float64            `json:"bias"`
+	Weights map[string]float64 `json:"weights"`
+}
+
+func decodeLinear(artifact []byte) (Scorer, error) {
+	var l linear
+	if err := json.Unmarshal(artifact, &l); err != nil {
+		return nil, fmt.Errorf("linear artifact: %w", err)
+	}
+	if len(l.Weights) == 0 {
+		return nil, fmt.Errorf("linear artifact has no weights")
+	}
+	return &l, nil
+}
+
+// Score implements Scorer
+func (l *linear) Score(features map[string]float64) float64 {
+	score := l.Bias
+	for name, w := range l.Weights {
+		score += w * features[name]
+	}
+	return score
+}
+
+// catBoost is an oblivious-tree ensemble over float features, as CatBoost
+// exports it to JSON
+type catBoost struct {
+	features []string // name of each float feature index
+	trees    []obliviousTree
+	scale    float64
+	bias     float64
+}
+
+type obliviousTree struct {
+	LeafValues []float64 `json:"leaf_values"`
+	Splits     []struct {
+		Feature   int     `json:"float_feature_index"`
+		Border    float64 `json:"border"`
+		SplitType string  `json:"split_type"`
+	} `json:"splits"`
+}
+
+func decodeCatBoost(artifact []byte, names []string) (Scorer, error) {
+	var raw struct {
+		FeaturesInfo struct {
+			FloatFeatures []struct {
+				Index int    `json:"feature_index"`
+				Flat  int    `json:"flat_feature_index"`
+				Name  string `json:"feature_name"`
+			} `json:"float_features"`
+			CategoricalFeatures []json.RawMessage `json:"categorical_features"`
+		} `json:"features_info"`
+		Trees        []obliviousTree   `json:"oblivious_trees"`
+		ScaleAndBias []json.RawMessage `json:"scale_and_bias"`
+	}
+	if err := json.Unmarshal(artifact, &raw); err != nil {
+		return nil, fmt.Errorf("catboost artifact: %w", err)
+	}
+	if len(raw.FeaturesInfo.CategoricalFeatures) > 0 {
+		return nil, fmt.Errorf("%w: catboost categorical features", ErrUnsupported)
+	}
+	if len(raw.Trees) == 0 {
+		return nil, fmt.Errorf("catboost artifact has no oblivious trees")
+	}
+	c := &catBoost{trees: raw.Trees, scale: 1, features: make([]string, len(raw.FeaturesInfo.FloatFeatures))}
+	for _, f := range raw.FeaturesInfo.FloatFeatures {
+		if f.Index < 0 || f.Index >= len(c.features) {
+			return nil, fmt.Errorf("catboost feature index %d out of range", f.Index)
+		}
+		name := f.Name
+		if name == "" && f.Flat < len(names) {
+			name = names[f.Flat]
+		}
+		if name == "" {
+			return nil, fmt.Errorf("catboost feature %d is unnamed; list it in the manifest's features", f.Index)
+		}
+		c.features[f.Index] = name
+	}
+	for i, tree := range c.trees {
+		if len(tree.LeafValues) != 1<<len(tree.Splits) {
+			return nil, fmt.Errorf("catboost tree %d: %d leaves for depth %d (multi-dimensional models are unsupported)", i, len(tree.LeafValues), len(tree.Splits))
+		}
+		for _, s := range tree.Splits {
+			if s.SplitType != "" && s.SplitType != "FloatFeature" {
+				return nil, fmt.Errorf("%w: catboost %s split", ErrUnsupported, s.SplitType)
+			}
+			if s.Feature < 0 || s.Feature >= len(c.features) {
+				return nil, fmt.Errorf("catboost tree %d splits on unknown feature %d", i, s.Feature)
+			}
+		}
+	}
+	// scale_and_bias is [scale, bias] or, in newer exports, [scale, [bias]]
+	if len(raw.ScaleAndBias) == 2 {
+		if err := json.Unmarshal(raw.ScaleAndBias[0], &c.scale); err != nil {
+			return nil, fmt.Errorf("catboost scale: %w", err)
+		}
+		if err := json.Unmarshal(raw.ScaleAndBias[1], &c.bias); err != nil {
+			var biases []float64
+			if err := json.Unmarshal(raw.ScaleAndBias[1], &biases); err != nil || len(biases) != 1 {
+				return nil, fmt.Errorf("catboost bias must be a single value")
+			}
+			c.bias = biases[0]
+		}
+	}
+	return c, nil
+}
+
+// Score implements Scorer: each tree's leaf is chosen by one bit per level,
+// set when the feature is above the split's border
+func (c *catBoost) Score(features map[string]float64) float64 {
+	var sum float64
+	for _, tree := range c.trees {
+		leaf := 0
+		for depth, s := range tree.Splits {
+			if features[c.features[s.Feature]] > s.Border {
+				leaf |= 1 << depth
+			}
+		}
+		sum += tree.LeafValues[leaf]
+	}
+	return c.scale*sum + c.bias
+}
@@ -0,0 +1,238 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// Artifact formats
+const (
+	FormatCatBoostJSON = "catboost-json" // CatBoost save_model(format="json")
+	FormatLinear       = "linear"        // {"bias": b, "weights": {"feature": w}}
+	FormatONNX         = "onnx"          // needs an ONNX runtime this build doesn't link
+)
+
+// currentFile names the file holding a model's live version
+const currentFile = "CURRENT"
+
+// ErrUnsupported is returned loading an artifact this build can't evaluate
+var ErrUnsupported = errors.New("models: unsupported artifact")
+
+// Manifest describes one version of a model. Artifacts live under the
+// source as <name>/<version>/manifest.json beside the artifact file, and
+// <name>/CURRENT names the version to serve, so a version is promoted by
+// rewriting CURRENT and signalling a reload.
+type Manifest struct {
+	Name     string   `json:"name"`
+	Version  string   `json:"version"`
+	Format   string   `json:"format"`
+	Artifact string   `json:"artifact"`           // file name beside the manifest
+	Features []string `json:"features,omitempty"` // input order, for artifacts that don't name their inputs
+	SHA256   string   `json:"sha256,omitempty"`   // artifact digest, checked when set
+}
+
+// Scorer evaluates a model on named features; missing features are 0
+type Scorer interface {
+	Score(features map[string]float64) float64
+}
+
+// Loaded is a model version ready to score
+type Loaded struct {
+	Manifest
+	Scorer   `json:"-"`
+	LoadedAt time.Time `json:"loadedAt"`
+}
+
+// Registry serves the current version of each named model from a directory
+// or an http(s) base URL, such as an object store bucket's endpoint. Reloads
+// swap each model atomically; a model whose new version fails to load keeps
+// serving its previous one. It is safe for concurrent use.
+type Registry struct {
+	source string
+	names  []string
+	client *http.Client
+
+	mu     sync.RWMutex
+	models map[string]*Loaded
+
+	version *metrics.GaugeVec
+}
+
+// New creates a registry of names under source; reg may be nil
+func New(source string, names []string, reg *metrics.Registry) *Registry {
+	r := &Registry{
+		source: strings.TrimSuffix(source, "/"),
+		names:  names,
+		client: &http.Client{Timeout: 30 * time.Second},
+		models: make(map[string]*Loaded),
+	}
+	if reg != nil {
+		r.version = reg.Gauge("titan_model_version_info", "1 for the model version being served", "model", "version")
+	}
+	return r
+}
+
+// FromConfig creates the configured registry, or nil without a source
+func FromConfig(cfg *config.ModelsConfig, reg *metrics.Registry) *Registry {
+	if cfg == nil || cfg.Source == "" {
+		return nil
+	}
+	return New(cfg.Source, cfg.Names, reg)
+}
+
+// Load (re)loads every model's current version
+func (r *Registry) Load(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	var errs []error
+	for _, name := range r.names {
+		m, err := r.load(ctx, name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("model %s: %w", name, err))
+			continue
+		}
+		r.mu.Lock()
+		old := r.models[name]
+		r.models[name] = m
+		r.mu.Unlock()
+		if old != nil && old.Version == m.Version {
+			continue
+		}
+		if r.version != nil {
+			if old != nil {
+				r.version.Set(0, name, old.Version)
+			}
+			r.version.Set(1, name, m.Version)
+		}
+		log.Printf("🧠 Model %s: serving version %s (%s)", name, m.Version, m.Format)
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Registry) load(ctx context.Context, name string) (*Loaded, error) {
+	current, err := r.read(ctx, name+"/"+currentFile)
+	if err != nil {
+		return nil, err
+	}
+	version := strings.TrimSpace(string(current))
+	if version == "" || strings.ContainsAny(version, `/\`) || version == ".." {
+		return nil, fmt.Errorf("bad version %q in %s", version, currentFile)
+	}
+	raw, err := r.read(ctx, name+"/"+version+"/manifest.json")
+	if err != nil {
+		return nil, err
+	}
+	m := &Loaded{LoadedAt: time.Now().UTC()}
+	if err := json.Unmarshal(raw, &m.Manifest); err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	if m.Name != name || m.Version != version {
+		return nil, fmt.Errorf("manifest is %s@%s, expected %s@%s", m.Name, m.Version, name, version)
+	}
+	if m.Artifact == "" || strings.ContainsAny(m.Artifact, `/\`) {
+		return nil, fmt.Errorf("bad artifact %q", m.Artifact)
+	}
+	artifact, err := r.read(ctx, name+"/"+version+"/"+m.Artifact)
+	if err != nil {
+		return nil, err
+	}
+	if m.SHA256 != "" {
+		sum := sha256.Sum256(artifact)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), m.SHA256) {
+			return nil, fmt.Errorf("artifact %s digest mismatch", m.Artifact)
+		}
+	}
+	if m.Scorer, err = decode(m.Manifest, artifact); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// read fetches path under the source
+func (r *Registry) read(ctx context.Context, path string) ([]byte, error) {
+	if !strings.HasPrefix(r.source, "http://") && !strings.HasPrefix(r.source, "https://") {
+		return os.ReadFile(filepath.Join(r.source, filepath.FromSlash(path)))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.source+"/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", path, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 256<<20))
+}
+
+// decode builds the scorer for an artifact's format
+func decode(m Manifest, artifact []byte) (Scorer, error) {
+	switch m.Format {
+	case FormatCatBoostJSON:
+		return decodeCatBoost(artifact, m.Features)
+	case FormatLinear:
+		return decodeLinear(artifact)
+	case FormatONNX:
+		return nil, fmt.Errorf("%w: onnx needs an ONNX runtime; export CatBoost models as JSON", ErrUnsupported)
+	}
+	return nil, fmt.Errorf("%w: format %q", ErrUnsupported, m.Format)
+}
+
+// Serves reports whether name is one of the registry's models, loaded or not
+func (r *Registry) Serves(name string) bool {
+	if r == nil {
+		return false
+	}
+	for _, n := range r.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the version of name being served
+func (r *Registry) Get(name string) (*Loaded, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.models[name]
+	return m, ok
+}
+
+// Models returns every model being served, by name
+func (r *Registry) Models() []*Loaded {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]*Loaded, 0, len(r.models))
+	for _, m := range r.models {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// catBoostArtifact is a two-tree model in CatBoost's JSON export layout
+const catBoostArtifact = `{
+  "features_info": {"float_features": [
+    {"feature_index": 0, "flat_feature_index": 0, "feature_name": "net_profit_usd"},
+    {"feature_index": 1, "flat_feature_index": 1, "feature_name": ""}
+  ]},
+  "oblivious_trees": [
+    {"leaf_values": [-1, 1], "splits": [{"float_feature_index": 0, "border": 10, "split_type": "FloatFeature"}]},
+    {"leaf_values": [0, 0.5, -0.5, 2], "splits": [
+      {"float_feature_index": 0, "border": 50, "split_type": "FloatFeature"},
+      {"float_feature_index": 1, "border": 5, "split_type": "FloatFeature"}
+    ]}
+  ],
+  "scale_and_bias": [2, [0.1]]
+}`
+
+// publish writes a version of name under dir and makes it current
+func publish(t *testing.T, dir, name, version, format, artifact string) {
+	t.Helper()
+	sum := sha256.Sum256([]byte(artifact))
+	manifest := `{"name": "` + name + `", "version": "` + version + `", "format": "` + format +
+		`", "artifact": "model.json", "features": ["net_profit_usd", "gas_usd"], "sha256": "` + hex.EncodeToString(sum[:]) + `"}`
+	files := map[string]string{
+		filepath.Join(name, version, "manifest.json"): manifest,
+		filepath.Join(name, version, "model.json"):    artifact,
+		filepath.Join(name, currentFile):              version + "\n",
+	}
+	for path, body := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCatBoostScore(t *testing.T) {
+	dir := t.TempDir()
+	publish(t, dir, "fill", "v1", FormatCatBoostJSON, catBoostArtifact)
+	r := New(dir, []string{"fill"}, nil)
+	if err := r.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	m, ok := r.Get("fill")
+	if !ok || m.Version != "v1" {
+		t.Fatalf("Expected fill@v1 served, got %+v", m)
+	}
+	if got := m.Score(map[string]float64{"net_profit_usd": 60, "gas_usd": 1}); math.Abs(got-3.1) > 1e-9 {
+		t.Errorf("Expected 2*(1+0.5)+0.1, got %v", got)
+	}
+	// gas_usd is unnamed in the artifact, so it comes from the manifest
+	if got := m.Score(map[string]float64{"net_profit_usd": 5, "gas_usd": 8}); math.Abs(got+2.9) > 1e-9 {
+		t.Errorf("Expected 2*(-1-0.5)+0.1, got %v", got)
+	}
+}
+
+func TestHotSwapKeepsLastGoodVersion(t *testing.T) {
+	reg := metrics.NewRegistry()
+	dir := t.TempDir()
+	publish(t, dir, "fill", "v1", FormatLinear, `{"bias": 1, "weights": {"net_profit_usd": 0.5}}`)
+	r := New(dir, []string{"fill"}, reg)
+	if err := r.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, dir, "fill", "v2", FormatLinear, `{"bias": 0, "weights": {"net_profit_usd": 2}}`)
+	if err := r.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	m, _ := r.Get("fill")
+	if m.Version != "v2" || m.Score(map[string]float64{"net_profit_usd": 3}) != 6 {
+		t.Errorf("Expected v2 swapped in, got %s", m.Version)
+	}
+	if reg.Value("titan_model_version_info", "fill", "v1") != 0 || reg.Value("titan_model_version_info", "fill", "v2") != 1 {
+		t.Errorf("Expected the version gauge moved to v2")
+	}
+
+	publish(t, dir, "fill", "v3", FormatONNX, `not a runtime we link`)
+	if err := r.Load(context.Background()); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected an onnx artifact unsupported, got %v", err)
+	}
+	if m, _ := r.Get("fill"); m.Version != "v2" {
+		t.Errorf("Expected v2 kept after a failed swap, got %s", m.Version)
+	}
+}
+
+func TestHTTPSource(t *testing.T) {
+	dir := t.TempDir()
+	publish(t, dir, "fill", "v1", FormatLinear, `{"weights": {"gas_usd": -1}}`)
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer srv.Close()
+
+	r := New(srv.URL+"/", []string{"fill"}, nil)
+	if err := r.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := r.Get("fill"); !ok || m.Score(map[string]float64{"gas_usd": 4}) != -4 {
+		t.Errorf("Expected fill loaded over http, got %+v", m)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "fill", "v1", "model.json"), []byte(`{"weights": {"gas_usd": 1}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Load(context.Background()); err == nil {
+		t.Errorf("Expected a tampered artifact rejected by its digest")
+	}
+	if len(r.Models()) != 1 {
+		t.Errorf("Expected fill still served, got %+v", r.Models())
+	}
+}
@@ -0,0 +1,128 @@
+package multicall
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Multicall3Address is the deterministic Multicall3 deployment shared by all supported chains
+const Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+const multicall3ABI = `[{"inputs":[{"components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}],"name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}],"name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`
+
+var parsedABI = mustParse(multicall3ABI)
+
+// Call is a single call inside an aggregate3 batch
+type Call struct {
+	Target       common.Address `abi:"target"`
+	AllowFailure bool           `abi:"allowFailure"`
+	CallData     []byte         `abi:"callData"`
+}
+
+// Result is the outcome of a single call inside a batch
+type Result struct {
+	Success    bool   `abi:"success"`
+	ReturnData []byte `abi:"returnData"`
+}
+
+// Client batches calls through Multicall3
+type Client struct {
+	caller    ethereum.ContractCaller
+	address   common.Address
+	batchSize int
+}
+
+// New creates a multicall client; batchSize bounds calls per eth_call
+func New(caller ethereum.ContractCaller, batchSize int) *Client {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return &Client{
+		caller:    caller,
+		address:   common.HexToAddress(Multicall3Address),
+		batchSize: batchSize,
+	}
+}
+
+// Aggregate executes calls in batches and returns results in input order
+func (c *Client) Aggregate(ctx context.Context, calls []Call, blockNumber *big.Int) ([]Result, error) {
+	results := make([]Result, 0, len(calls))
+	for start := 0; start < len(calls); start += c.batchSize {
+		end := start + c.batchSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+
+		data, err := EncodeAggregate3(calls[start:end])
+		if err != nil {
+			return nil, err
+		}
+		out, err := c.caller.CallContract(ctx, ethereum.CallMsg{To: &c.address, Data: data}, blockNumber)
+		if err != nil {
+			return nil, fmt.Errorf("multicall batch %d-%d failed: %w", start, end, err)
+		}
+		batch, err := DecodeAggregate3Result(out)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, batch...)
+	}
+	return results, nil
+}
+
+// EncodeAggregate3 packs calls into aggregate3 calldata
+func EncodeAggregate3(calls []Call) ([]byte, error) {
+	data, err := parsedABI.Pack("aggregate3", calls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack aggregate3: %w", err)
+	}
+	return data, nil
+}
+
+// DecodeAggregate3Calls unpacks aggregate3 calldata (including selector) into calls
+func DecodeAggregate3Calls(data []byte) ([]Call, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("aggregate3 calldata too short")
+	}
+	values, err := parsedABI.Methods["aggregate3"].Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack aggregate3 calls: %w", err)
+	}
+	var calls []Call
+	if err := parsedABI.Methods["aggregate3"].Inputs.Copy(&calls, values); err != nil {
+		return nil, err
+	}
+	return calls, nil
+}
+
+// EncodeAggregate3Result packs results as aggregate3 return data
+func EncodeAggregate3Result(results []Result) ([]byte, error) {
+	return parsedABI.Methods["aggregate3"].Outputs.Pack(results)
+}
+
+// DecodeAggregate3Result unpacks aggregate3 return data
+func DecodeAggregate3Result(data []byte) ([]Result, error) {
+	values, err := parsedABI.Unpack("aggregate3", data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack aggregate3 result: %w", err)
+	}
+	var results []Result
+	if err := parsedABI.Methods["aggregate3"].Outputs.Copy(&results, values); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func mustParse(def string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(def))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
@@ -0,0 +1,43 @@
+package multicall_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/multicall"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+)
+
+func TestAggregateBatches(t *testing.T) {
+	chain := titantest.NewChain(137)
+	chain.Backend.EnableMulticall()
+	token := chain.Token(6)
+
+	var calls []multicall.Call
+	for i := 1; i <= 5; i++ {
+		holder := titantest.Address(uint64(i))
+		chain.Fund(token, holder, big.NewInt(int64(i*100)))
+		data := append(titantest.SelectorBalanceOf[:], titantest.EncodeAddress(holder)...)
+		calls = append(calls, multicall.Call{Target: token, AllowFailure: true, CallData: data})
+	}
+	// A call with no fixture must fail softly
+	calls = append(calls, multicall.Call{Target: titantest.Address(0xdead), AllowFailure: true, CallData: titantest.SelectorDecimals[:]})
+
+	results, err := multicall.New(chain.Backend, 2).Aggregate(context.Background(), calls, nil)
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if len(results) != len(calls) {
+		t.Fatalf("Expected %d results, got %d", len(calls), len(results))
+	}
+	for i := 0; i < 5; i++ {
+		got := new(big.Int).SetBytes(results[i].ReturnData)
+		if !results[i].Success || got.Int64() != int64((i+1)*100) {
+			t.Errorf("Result %d: expected %d, got %s (success=%v)", i, (i+1)*100, got, results[i].Success)
+		}
+	}
+	if results[5].Success {
+		t.Error("Expected call without fixture to fail")
+	}
+}
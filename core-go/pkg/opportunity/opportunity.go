@@ -0,0 +1,579 @@
+package opportunity
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/pathfind"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/slippage"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Decisions recorded on an explanation
+const (
+	DecisionExecute = "execute"
+	DecisionReject  = "reject"
+	DecisionPending = "pending"
+)
+
+// Leg is a single quoted swap in an opportunity's route
+type Leg struct {
+	Dex       string         `json:"dex,omitempty"`
+	Pool      common.Address `json:"pool"`
+	TokenIn   common.Address `json:"tokenIn"`
+	TokenOut  common.Address `json:"tokenOut"`
+	AmountIn  *big.Int       `json:"amountIn"`
+	AmountOut *big.Int       `json:"amountOut"`
+	FeeBps    uint32         `json:"feeBps"`
+}
+
+// ScoreComponent is one stage's contribution to the final score
+type ScoreComponent struct {
+	Name   string  `json:"name"`
+	Value  float64 `json:"value"`
+	Weight float64 `json:"weight"`
+}
+
+// Guardrail records a limit evaluated while sizing or gating an opportunity
+type Guardrail struct {
+	Name   string `json:"name"`
+	Limit  string `json:"limit"`
+	Actual string `json:"actual"`
+	Bound  bool   `json:"bound"` // true when this guardrail determined or blocked the size
+}
+
+// CostBreakdown attributes an opportunity's costs to the legs that incur
+// them, so margin erosion can be traced to a swap, the lender, a bridge or
+// gas on either chain. DEX fees come out of each swap's quoted output and so
+// are already netted in gross profit; the rest are charged against it.
+//
+// Only MEV-Share backruns record a breakdown. They trade on one chain, so
+// BridgeUSD and DestinationGasUSD stay zero until cross-chain plans are
+// costed here.
+type CostBreakdown struct {
+	DEX               []LegFee  `json:"dex"`
+	FlashLoanUSD      units.USD `json:"flashLoanUsd"`
+	BridgeUSD         units.USD `json:"bridgeUsd"`         // zero on single-chain plans
+	SourceGasUSD      units.USD `json:"sourceGasUsd"`      // at suggested fees
+	DestinationGasUSD units.USD `json:"destinationGasUsd"` // zero on single-chain plans
+	RelayTipUSD       units.USD `json:"relayTipUsd"`       // builder or relayer payment
+}
+
+// LegFee is the pool fee one swap paid
+type LegFee struct {
+	Leg    int       `json:"leg"` // index into the explanation's legs
+	Dex    string    `json:"dex,omitempty"`
+	FeeBps uint32    `json:"feeBps"`
+	USD    units.USD `json:"usd"`
+}
+
+// NewCostBreakdown charges each leg its pool fee on notional, the value
+// entering a cycle, which every hop carries at roughly the same worth
+func NewCostBreakdown(legs []Leg, notional units.USD) CostBreakdown {
+	c := CostBreakdown{DEX: make([]LegFee, len(legs))}
+	for i, leg := range legs {
+		c.DEX[i] = LegFee{Leg: i, Dex: leg.Dex, FeeBps: leg.FeeBps}
+		if notional > 0 {
+			c.DEX[i].USD = units.USD(units.MulBpsUint(uint64(notional), uint64(leg.FeeBps)))
+		}
+	}
+	return c
+}
+
+// DEXUSD totals the pool fees
+func (c *CostBreakdown) DEXUSD() units.USD {
+	var total units.USD
+	for _, f := range c.DEX {
+		total += f.USD
+	}
+	return total
+}
+
+// FeesUSD totals the fees charged against gross profit
+func (c *CostBreakdown) FeesUSD() units.USD {
+	return c.FlashLoanUSD + c.BridgeUSD + c.RelayTipUSD
+}
+
+// GasUSD totals gas on both chains
+func (c *CostBreakdown) GasUSD() units.USD {
+	return c.SourceGasUSD + c.DestinationGasUSD
+}
+
+// TotalUSD totals every cost, pool fees included
+func (c *CostBreakdown) TotalUSD() units.USD {
+	return c.DEXUSD() + c.FeesUSD() + c.GasUSD()
+}
+
+// Explanation is the auditable breakdown of how an opportunity was evaluated
+type Explanation struct {
+	Legs           []Leg            `json:"legs"`
+	GasUnits       uint64           `json:"gasUnits"`
+	GasEstimate    *gas.Estimate    `json:"gasEstimate,omitempty"` // how GasUnits was predicted, when not simulated
+	GasUSD         units.USD        `json:"gasUsd"`
+	FeesUSD        units.USD        `json:"feesUsd"`
+	AmortizedUSD   units.USD        `json:"amortizedUsd,omitempty"` // share of one-off costs such as approvals and bridging
+	GrossProfitUSD units.USD        `json:"grossProfitUsd"`
+	NetProfitUSD   units.USD        `json:"netProfitUsd"`
+	Score          float64          `json:"score"`
+	Components     []ScoreComponent `json:"components,omitempty"`
+	Guardrails     []Guardrail      `json:"guardrails,omitempty"`
+	Decision       string           `json:"decision"`
+	Reason         string           `json:"reason,omitempty"`
+	Category       failure.Reason   `json:"category,omitempty"`  // canonical reason of a rejection
+	Trial          *Trial           `json:"trial,omitempty"`     // scoring experiment the opportunity took part in
+	Breakdown      *ScoreBreakdown  `json:"breakdown,omitempty"` // how the scoring pipeline reached its verdict
+	Costs          *CostBreakdown   `json:"costs,omitempty"`     // FeesUSD and GasUSD by leg
+}
+
+// ScoreBreakdown records what the scoring pipeline saw and how each stage
+// contributed, so a pass or failure can be explained after the fact and
+// replayed as training data
+type ScoreBreakdown struct {
+	Features   map[string]float64 `json:"features,omitempty"` // inputs the models scored, as of the verdict
+	Stages     []StageScore       `json:"stages"`
+	Score      float64            `json:"score"` // weighted mean of the stage scores
+	MinScore   float64            `json:"minScore"`
+	Passed     bool               `json:"passed"`
+	RejectedBy string             `json:"rejectedBy,omitempty"` // stage whose rejection was reported
+}
+
+// StageScore is one stage's verdict and its share of the final score
+type StageScore struct {
+	Stage        string  `json:"stage"`
+	Score        float64 `json:"score"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"` // weighted score over the total weight
+	Accept       bool    `json:"accept"`
+	Reason       string  `json:"reason,omitempty"`
+}
+
+// Trial records how each arm of a scoring experiment judged an opportunity
+type Trial struct {
+	Decider string     `json:"decider"` // arm whose decision was used
+	Arms    []TrialArm `json:"arms"`
+}
+
+// TrialArm is one arm's score and decision
+type TrialArm struct {
+	Arm     string  `json:"arm"` // control or candidate
+	Model   string  `json:"model"`
+	Version string  `json:"version,omitempty"` // registry model version that scored it
+	Score   float64 `json:"score"`
+	Accept  bool    `json:"accept"` // the score cleared the arm's threshold
+}
+
+// SetCosts records gas and fees and recomputes net profit from gross profit
+func (e *Explanation) SetCosts(gasUnits uint64, gasUSD, feesUSD units.USD) {
+	e.GasUnits = gasUnits
+	e.GasUSD = gasUSD
+	e.FeesUSD = feesUSD
+	e.NetProfitUSD = e.GrossProfitUSD - feesUSD - gasUSD - e.AmortizedUSD
+}
+
+// Amortize charges the opportunity its share of one-off costs and recomputes
+// net profit
+func (e *Explanation) Amortize(usd units.USD) {
+	e.AmortizedUSD = usd
+	e.NetProfitUSD = e.GrossProfitUSD - e.FeesUSD - e.GasUSD - usd
+}
+
+// Attribute records c as the opportunity's costs, setting fees and gas from
+// it and recomputing net profit
+func (e *Explanation) Attribute(c CostBreakdown) {
+	e.Costs = &c
+	e.SetCosts(e.GasUnits, c.GasUSD(), c.FeesUSD())
+}
+
+// GateProfit rejects the opportunity when net profit is below minProfit
+func (e *Explanation) GateProfit(minProfit units.USD) bool {
+	e.Guardrails = append(e.Guardrails, Guardrail{
+		Name:   "min_profit",
+		Limit:  minProfit.String(),
+		Actual: e.NetProfitUSD.String(),
+		Bound:  e.NetProfitUSD < minProfit,
+	})
+	if e.NetProfitUSD < minProfit {
+		e.Reject(failure.GuardrailFloor, "net profit below minimum")
+		return false
+	}
+	return true
+}
+
+// GateLoan rejects the opportunity when its notional is below minLoan
+func (e *Explanation) GateLoan(notional, minLoan units.USD) bool {
+	e.Guardrails = append(e.Guardrails, Guardrail{
+		Name:   "min_loan",
+		Limit:  minLoan.String(),
+		Actual: notional.String(),
+		Bound:  notional < minLoan,
+	})
+	if notional < minLoan {
+		e.Reject(failure.GuardrailFloor, "trade below the chain's minimum size")
+		return false
+	}
+	return true
+}
+
+// Reject records a rejection with its category and operator-facing reason
+func (e *Explanation) Reject(category failure.Reason, reason string) {
+	e.Decision = DecisionReject
+	e.Reason = reason
+	e.Category = category
+}
+
+// BindingGuardrail returns the name of the guardrail that bound the size, if any
+func (e *Explanation) BindingGuardrail() string {
+	for _, g := range e.Guardrails {
+		if g.Bound {
+			return g.Name
+		}
+	}
+	return ""
+}
+
+// Text renders the explanation for operators
+func (e *Explanation) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Decision: %s", strings.ToUpper(e.Decision))
+	if e.Reason != "" {
+		fmt.Fprintf(&b, " (%s)", e.Reason)
+	}
+	b.WriteString("\n")
+
+	for i, leg := range e.Legs {
+		fmt.Fprintf(&b, "  Leg %d: %s %s → %s  in=%s out=%s fee=%dbps\n",
+			i+1, leg.Dex, short(leg.TokenIn), short(leg.TokenOut), leg.AmountIn, leg.AmountOut, leg.FeeBps)
+	}
+	fmt.Fprintf(&b, "  Gross: %s  Fees: %s  Gas: %s (%d units)", e.GrossProfitUSD, e.FeesUSD, e.GasUSD, e.GasUnits)
+	if e.AmortizedUSD != 0 {
+		fmt.Fprintf(&b, "  Amortized: %s", e.AmortizedUSD)
+	}
+	fmt.Fprintf(&b, "  Net: %s\n", e.NetProfitUSD)
+	if c := e.Costs; c != nil {
+		fmt.Fprintf(&b, "  Costs: dex %s", c.DEXUSD())
+		for _, f := range c.DEX {
+			fmt.Fprintf(&b, " [%d %s %s]", f.Leg+1, f.Dex, f.USD)
+		}
+		fmt.Fprintf(&b, "  flash %s  bridge %s  gas %s+%s  tip %s\n",
+			c.FlashLoanUSD, c.BridgeUSD, c.SourceGasUSD, c.DestinationGasUSD, c.RelayTipUSD)
+	}
+	if len(e.Components) > 0 {
+		fmt.Fprintf(&b, "  Score: %.4f =", e.Score)
+		for i, c := range e.Components {
+			if i > 0 {
+				b.WriteString(" +")
+			}
+			fmt.Fprintf(&b, " %s(%.3f×%.2f)", c.Name, c.Value, c.Weight)
+		}
+		b.WriteString("\n")
+	}
+	for _, g := range e.Guardrails {
+		marker := " "
+		if g.Bound {
+			marker = "*"
+		}
+		fmt.Fprintf(&b, "  %s %s: limit=%s actual=%s\n", marker, g.Name, g.Limit, g.Actual)
+	}
+	if bd := e.Breakdown; bd != nil {
+		fmt.Fprintf(&b, "  Pipeline: %.3f (min %.2f)\n", bd.Score, bd.MinScore)
+		for _, st := range bd.Stages {
+			marker := " "
+			if !st.Accept {
+				marker = "✗"
+			}
+			fmt.Fprintf(&b, "  %s %s: %.3f×%.2f → %.3f", marker, st.Stage, st.Score, st.Weight, st.Contribution)
+			if st.Reason != "" {
+				fmt.Fprintf(&b, " (%s)", st.Reason)
+			}
+			b.WriteString("\n")
+		}
+		names := make([]string, 0, len(bd.Features))
+		for name := range bd.Features {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&b, "    %s=%g\n", name, bd.Features[name])
+		}
+	}
+	return b.String()
+}
+
+// Opportunity is a candidate arbitrage and its evaluation
+type Opportunity struct {
+	ID          string         `json:"id"`
+	ChainID     uint64         `json:"chainId"`
+	Block       uint64         `json:"block"`
+	DetectedAt  time.Time      `json:"detectedAt"`
+	TokenIn     common.Address `json:"tokenIn"`
+	AmountIn    *big.Int       `json:"amountIn"`
+	Explanation *Explanation   `json:"explanation"`
+	Strategy    string         `json:"strategy,omitempty"` // instance that evaluated it, e.g. main
+}
+
+// Route returns a compact route label built from the legs' DEX names
+func (e *Explanation) Route() string {
+	names := make([]string, len(e.Legs))
+	for i, leg := range e.Legs {
+		names[i] = leg.Dex
+		if names[i] == "" {
+			names[i] = short(leg.Pool)
+		}
+	}
+	return strings.Join(names, ">")
+}
+
+// Fingerprint identifies the plan independent of when it was detected: the
+// chain, input token and amount, and each leg's pool and direction. Two
+// opportunities with the same fingerprint would submit equivalent transactions.
+func (o *Opportunity) Fingerprint() common.Hash {
+	var buf []byte
+	buf = binary.BigEndian.AppendUint64(buf, o.ChainID)
+	buf = append(buf, o.TokenIn.Bytes()...)
+	if o.AmountIn != nil {
+		buf = append(buf, common.BigToHash(o.AmountIn).Bytes()...)
+	}
+	if o.Explanation != nil {
+		for _, leg := range o.Explanation.Legs {
+			buf = append(buf, leg.Pool.Bytes()...)
+			buf = append(buf, leg.TokenIn.Bytes()...)
+			buf = append(buf, leg.TokenOut.Bytes()...)
+		}
+	}
+	return crypto.Keccak256Hash(buf)
+}
+
+// Execution is the on-chain outcome of an executed opportunity
+type Execution struct {
+	OpportunityID      string         `json:"opportunityId"`
+	ChainID            uint64         `json:"chainId"`
+	Route              string         `json:"route"`
+	TxHash             common.Hash    `json:"txHash"`
+	Block              uint64         `json:"block,omitempty"`
+	BlockHash          common.Hash    `json:"blockHash,omitempty"`
+	Success            bool           `json:"success"`
+	Reason             string         `json:"reason,omitempty"`   // failure cause when Success is false
+	Category           failure.Reason `json:"category,omitempty"` // canonical failure cause
+	PredictedProfitUSD units.USD      `json:"predictedProfitUsd"`
+	RealizedProfitUSD  units.USD      `json:"realizedProfitUsd"`
+	GasUSD             units.USD      `json:"gasUsd"`
+	GasUsed            uint64         `json:"gasUsed,omitempty"`  // from the receipt
+	Adapters           []string       `json:"adapters,omitempty"` // route adapter per hop, e.g. univ2
+	Time               time.Time      `json:"time"`
+
+	// Fills compares each leg's predicted output with the realized one
+	Fills []slippage.Observation `json:"fills,omitempty"`
+}
+
+// GasShape returns the execution's route shape for gas modelling
+func (e *Execution) GasShape() gas.Shape {
+	return gas.Shape{ChainID: e.ChainID, Adapters: e.Adapters}
+}
+
+// One-off cost kinds
+const (
+	CostApproval = "approval" // token approval gas
+	CostBridge   = "bridge"   // moving inventory between chains
+	CostDeploy   = "deploy"   // deploying or upgrading an executor
+)
+
+// Cost is a one-off expense incurred to keep routes tradable. It is spread
+// over the executions of its route, or of every route on the chain when
+// Route is empty, so each trade carries its share.
+type Cost struct {
+	Kind    string      `json:"kind"`
+	ChainID uint64      `json:"chainId"`
+	Route   string      `json:"route,omitempty"`
+	USD     units.USD   `json:"usd"`
+	TxHash  common.Hash `json:"txHash,omitempty"`
+	Note    string      `json:"note,omitempty"`
+	Time    time.Time   `json:"time"`
+}
+
+// Reversal retracts an execution whose block was reorged out of the chain.
+// Accounting drops the execution journaled with the same opportunity ID and
+// block hash; a later re-inclusion is journaled as a new execution.
+type Reversal struct {
+	OpportunityID string      `json:"opportunityId"`
+	ChainID       uint64      `json:"chainId"`
+	TxHash        common.Hash `json:"txHash"`
+	BlockHash     common.Hash `json:"blockHash"`
+	Reason        string      `json:"reason,omitempty"`
+	Time          time.Time   `json:"time"`
+}
+
+// New creates an opportunity with a fresh ID and a pending explanation
+func New(chainID uint64, block uint64, tokenIn common.Address, amountIn *big.Int) *Opportunity {
+	return &Opportunity{
+		ID:          NewID(),
+		ChainID:     chainID,
+		Block:       block,
+		DetectedAt:  time.Now().UTC(),
+		TokenIn:     tokenIn,
+		AmountIn:    amountIn,
+		Explanation: &Explanation{Decision: DecisionPending},
+	}
+}
+
+// NewID returns a random 16-hex-character opportunity ID
+func NewID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// LegsFromRoute quotes amountIn through route and returns per-leg amounts
+func LegsFromRoute(route pathfind.Route, amountIn *big.Int) ([]Leg, error) {
+	legs := make([]Leg, 0, len(route))
+	amount := amountIn
+	for _, hop := range route {
+		out, err := hop.State.Quote(amount, hop.ZeroForOne)
+		if err != nil {
+			return nil, fmt.Errorf("leg %s: %w", hop.Pool.Hex(), err)
+		}
+		legs = append(legs, Leg{
+			Pool:      hop.Pool,
+			TokenIn:   hop.TokenIn,
+			TokenOut:  hop.TokenOut,
+			AmountIn:  amount,
+			AmountOut: out,
+			FeeBps:    hop.State.FeeBps,
+		})
+		amount = out
+	}
+	return legs, nil
+}
+
+// Store keeps the most recent opportunities in memory for the API
+type Store struct {
+	mu    sync.RWMutex
+	ring  []*Opportunity
+	next  int
+	full  bool
+	index map[string]*Opportunity
+}
+
+// NewStore creates a store retaining the last capacity opportunities
+func NewStore(capacity int) *Store {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &Store{ring: make([]*Opportunity, capacity), index: make(map[string]*Opportunity)}
+}
+
+// Add records an opportunity, evicting the oldest when full
+func (s *Store) Add(o *Opportunity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old := s.ring[s.next]; old != nil {
+		delete(s.index, old.ID)
+	}
+	s.ring[s.next] = o
+	s.index[o.ID] = o
+	s.next = (s.next + 1) % len(s.ring)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+// Get returns an opportunity by ID
+func (s *Store) Get(id string) (*Opportunity, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	o, ok := s.index[id]
+	return o, ok
+}
+
+// Recent returns up to limit opportunities, newest first
+func (s *Store) Recent(limit int) []*Opportunity {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	size := s.next
+	if s.full {
+		size = len(s.ring)
+	}
+	if limit <= 0 || limit > size {
+		limit = size
+	}
+	out := make([]*Opportunity, 0, limit)
+	for i := 1; i <= limit; i++ {
+		out = append(out, s.ring[(s.next-i+len(s.ring))%len(s.ring)])
+	}
+	return out
+}
+
+func short(a common.Address) string {
+	h := a.Hex()
+	return h[:6] + "…" + h[len(h)-4:]
+}
+
+// Recorder publishes evaluated opportunities to the API store and the journal
+type Recorder struct {
+	store     *Store
+	journal   *journal.Journal
+	listeners []func(kind string, record interface{})
+
+	// Failures counts rejections; execution failures are counted by the
+	// pipeline, which sees every lifecycle end. Nil counts nothing.
+	Failures *failure.Counter
+}
+
+// NewRecorder creates a recorder; journal may be nil to skip persistence
+func NewRecorder(store *Store, j *journal.Journal) *Recorder {
+	return &Recorder{store: store, journal: j}
+}
+
+// Listen registers fn to receive every record, keyed by its journal kind,
+// once it is stored. Listeners are registered before recording starts.
+func (r *Recorder) Listen(fn func(kind string, record interface{})) {
+	r.listeners = append(r.listeners, fn)
+}
+
+// RecordExecution journals an execution outcome
+func (r *Recorder) RecordExecution(e *Execution) error {
+	return r.append(journal.KindExecution, e)
+}
+
+// RecordCost journals a one-off cost
+func (r *Recorder) RecordCost(c *Cost) error {
+	return r.append(journal.KindCost, c)
+}
+
+// RecordReversal journals the retraction of a reorged execution
+func (r *Recorder) RecordReversal(v *Reversal) error {
+	return r.append(journal.KindReversal, v)
+}
+
+// Record stores the opportunity and journals it with its explanation
+func (r *Recorder) Record(o *Opportunity) error {
+	r.store.Add(o)
+	if e := o.Explanation; e != nil && e.Decision == DecisionReject {
+		r.Failures.Inc("scored", failure.Of(e.Category, e.Reason))
+	}
+	return r.append(journal.KindOpportunity, o)
+}
+
+// append journals a record and notifies listeners
+func (r *Recorder) append(kind string, record interface{}) error {
+	if r.journal != nil {
+		if err := r.journal.Append(kind, record); err != nil {
+			return err
+		}
+	}
+	for _, fn := range r.listeners {
+		fn(kind, record)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package opportunity
+
+import (
+	"encoding/json"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+func TestStoreEvictsOldestAndReturnsNewestFirst(t *testing.T) {
+	store := NewStore(2)
+	a := New(1, 1, common.Address{}, big.NewInt(1))
+	b := New(1, 2, common.Address{}, big.NewInt(1))
+	c := New(1, 3, common.Address{}, big.NewInt(1))
+	store.Add(a)
+	store.Add(b)
+	store.Add(c)
+
+	if _, ok := store.Get(a.ID); ok {
+		t.Error("Expected oldest opportunity to be evicted")
+	}
+	recent := store.Recent(10)
+	if len(recent) != 2 {
+		t.Fatalf("Expected 2 opportunities, got %d", len(recent))
+	}
+	if recent[0].ID != c.ID || recent[1].ID != b.ID {
+		t.Errorf("Expected newest first, got blocks %d, %d", recent[0].Block, recent[1].Block)
+	}
+}
+
+func TestExplanationText(t *testing.T) {
+	e := &Explanation{
+		Decision: DecisionReject,
+		Reason:   "below floor",
+		Guardrails: []Guardrail{
+			{Name: "max_tvl_share", Limit: "100", Actual: "50"},
+			{Name: "min_floor", Limit: "500", Actual: "0", Bound: true},
+		},
+	}
+	if got := e.BindingGuardrail(); got != "min_floor" {
+		t.Errorf("Expected binding guardrail min_floor, got %q", got)
+	}
+	text := e.Text()
+	if !strings.Contains(text, "Decision: REJECT (below floor)") {
+		t.Errorf("Expected decision header, got %q", text)
+	}
+	if !strings.Contains(text, "* min_floor: limit=500 actual=0") {
+		t.Errorf("Expected binding guardrail to be marked, got %q", text)
+	}
+}
+
+func TestRecorderJournalsExplanation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j, err := journal.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewStore(10)
+	o := New(137, 42, common.Address{}, big.NewInt(1000))
+	o.Explanation.Decision = DecisionExecute
+
+	if err := NewRecorder(store, j).Record(o); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+
+	if _, ok := store.Get(o.ID); !ok {
+		t.Error("Expected recorded opportunity in store")
+	}
+	entries, err := journal.ReadAll(path, journal.KindOpportunity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 journal entry, got %d", len(entries))
+	}
+	var got Opportunity
+	if err := json.Unmarshal(entries[0].Data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != o.ID || got.Explanation.Decision != DecisionExecute {
+		t.Errorf("Expected journaled %s/%s, got %s/%s", o.ID, DecisionExecute, got.ID, got.Explanation.Decision)
+	}
+}
+
+func TestGateProfitUsesNetOfGas(t *testing.T) {
+	e := &Explanation{Decision: DecisionPending, GrossProfitUSD: units.DollarsToUSD(20)}
+	e.SetCosts(300_000, units.DollarsToUSD(12), units.DollarsToUSD(3))
+	if e.NetProfitUSD != units.DollarsToUSD(5) {
+		t.Errorf("Expected $5 net, got %s", e.NetProfitUSD)
+	}
+	if e.GateProfit(units.DollarsToUSD(10)) {
+		t.Error("Expected opportunity below minimum profit to be rejected")
+	}
+	if e.Decision != DecisionReject || e.BindingGuardrail() != "min_profit" {
+		t.Errorf("Expected min_profit rejection, got %s/%s", e.Decision, e.BindingGuardrail())
+	}
+}
+
+func TestGateLoanRejectsSmallTrades(t *testing.T) {
+	e := &Explanation{Decision: DecisionPending}
+	if !e.GateLoan(units.DollarsToUSD(800), units.DollarsToUSD(500)) {
+		t.Fatal("Expected a trade above the floor to pass")
+	}
+	if e.GateLoan(units.DollarsToUSD(800), units.DollarsToUSD(5000)) {
+		t.Error("Expected a trade below the floor to be rejected")
+	}
+	if e.Decision != DecisionReject || e.Category != failure.GuardrailFloor || e.BindingGuardrail() != "min_loan" {
+		t.Errorf("Expected a min_loan rejection, got %s/%s/%s", e.Decision, e.Category, e.BindingGuardrail())
+	}
+}
+
+func TestAmortizeReducesNet(t *testing.T) {
+	e := &Explanation{GrossProfitUSD: units.DollarsToUSD(20)}
+	e.SetCosts(300_000, units.DollarsToUSD(4), units.DollarsToUSD(1))
+	e.Amortize(units.DollarsToUSD(6))
+	if e.NetProfitUSD != units.DollarsToUSD(9) {
+		t.Errorf("Expected $9 net, got %s", e.NetProfitUSD)
+	}
+	if !strings.Contains(e.Text(), "Amortized: ") {
+		t.Errorf("Expected the amortized charge shown, got %q", e.Text())
+	}
+}
+
+func TestAttributeChargesCostsByLeg(t *testing.T) {
+	legs := []Leg{{Dex: "UNISWAP_V3", FeeBps: 30}, {Dex: "CURVE", FeeBps: 5}}
+	c := NewCostBreakdown(legs, units.DollarsToUSD(10_000))
+	if c.DEX[0].USD != units.DollarsToUSD(30) || c.DEX[1].USD != units.DollarsToUSD(5) || c.DEXUSD() != units.DollarsToUSD(35) {
+		t.Errorf("Expected $30 and $5 of pool fees on $10k, got %+v", c.DEX)
+	}
+	c.FlashLoanUSD, c.RelayTipUSD = units.DollarsToUSD(5), units.DollarsToUSD(20)
+	c.SourceGasUSD, c.DestinationGasUSD = units.DollarsToUSD(3), units.DollarsToUSD(1)
+
+	// Pool fees are already netted in gross and are not charged again
+	e := &Explanation{GrossProfitUSD: units.DollarsToUSD(100), GasUnits: 300_000}
+	e.Attribute(c)
+	if e.FeesUSD != units.DollarsToUSD(25) || e.GasUSD != units.DollarsToUSD(4) || e.NetProfitUSD != units.DollarsToUSD(71) {
+		t.Errorf("Expected $25 fees, $4 gas and $71 net, got %s, %s and %s", e.FeesUSD, e.GasUSD, e.NetProfitUSD)
+	}
+	if c.TotalUSD() != units.DollarsToUSD(64) {
+		t.Errorf("Expected $64 of costs in total, got %s", c.TotalUSD())
+	}
+	if text := e.Text(); !strings.Contains(text, "Costs: dex $35.00 [1 UNISWAP_V3 $30.00] [2 CURVE $5.00]") {
+		t.Errorf("Expected the per-leg costs shown, got %q", text)
+	}
+}
+
+func TestTextShowsBreakdown(t *testing.T) {
+	e := &Explanation{Breakdown: &ScoreBreakdown{
+		Features: map[string]float64{"pump_probability": 0.7},
+		Stages:   []StageScore{{Stage: "tar", Score: 0.3, Weight: 1, Contribution: 0.3, Reason: "token trending"}},
+		Score:    0.3, MinScore: 0.5,
+	}}
+	text := e.Text()
+	for _, want := range []string{"Pipeline: 0.300 (min 0.50)", "✗ tar: 0.300×1.00 → 0.300 (token trending)", "pump_probability=0.7"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in the explanation, got %q", want, text)
+		}
+	}
+}
+
+func TestFingerprintIgnoresDetectionDetails(t *testing.T) {
+	legs := []Leg{{Pool: common.HexToAddress("0x1"), TokenIn: common.HexToAddress("0xa"), TokenOut: common.HexToAddress("0xb")}}
+	a := New(137, 100, common.HexToAddress("0xa"), big.NewInt(1000))
+	b := New(137, 101, common.HexToAddress("0xa"), big.NewInt(1000))
+	a.Explanation.Legs, b.Explanation.Legs = legs, legs
+
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Error("Expected equivalent plans to share a fingerprint")
+	}
+	b.AmountIn = big.NewInt(2000)
+	if a.Fingerprint() == b.Fingerprint() {
+		t.Error("Expected different amounts to change the fingerprint")
+	}
+}
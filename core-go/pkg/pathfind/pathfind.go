@@ -0,0 +1,128 @@
+package pathfind
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/amm"
+)
+
+// Hop is a single directed swap through a pool
+type Hop struct {
+	Pool       common.Address
+	TokenIn    common.Address
+	TokenOut   common.Address
+	ZeroForOne bool
+	State      *amm.Pool
+}
+
+// Route is an ordered list of hops
+type Route []Hop
+
+// Quote simulates amountIn through every hop using local AMM math
+func (r Route) Quote(amountIn *big.Int) (*big.Int, error) {
+	amount := amountIn
+	for _, hop := range r {
+		out, err := hop.State.Quote(amount, hop.ZeroForOne)
+		if err != nil {
+			return nil, err
+		}
+		amount = out
+	}
+	return amount, nil
+}
+
+// QuoteIn works back from the last hop, returning the input that delivers at
+// least amountOut at the end of the route
+func (r Route) QuoteIn(amountOut *big.Int) (*big.Int, error) {
+	amount := amountOut
+	for i := len(r) - 1; i >= 0; i-- {
+		in, err := r[i].State.QuoteIn(amount, r[i].ZeroForOne)
+		if err != nil {
+			return nil, err
+		}
+		amount = in
+	}
+	return amount, nil
+}
+
+// Graph is a token graph where every pool contributes two directed edges
+type Graph struct {
+	edges map[common.Address][]Hop
+	pools int
+}
+
+// NewGraph creates an empty token graph
+func NewGraph() *Graph {
+	return &Graph{edges: make(map[common.Address][]Hop)}
+}
+
+// AddPool adds both swap directions of a pool
+func (g *Graph) AddPool(address, token0, token1 common.Address, state *amm.Pool) {
+	g.edges[token0] = append(g.edges[token0], Hop{Pool: address, TokenIn: token0, TokenOut: token1, ZeroForOne: true, State: state})
+	g.edges[token1] = append(g.edges[token1], Hop{Pool: address, TokenIn: token1, TokenOut: token0, ZeroForOne: false, State: state})
+	g.pools++
+}
+
+// PoolCount returns the number of pools in the graph
+func (g *Graph) PoolCount() int {
+	return g.pools
+}
+
+// Edges returns the outgoing hops from token
+func (g *Graph) Edges(token common.Address) []Hop {
+	return g.edges[token]
+}
+
+// FindCycles returns every route that starts and ends at start using at most
+// maxHops hops and never reuses a pool
+func (g *Graph) FindCycles(start common.Address, maxHops int) []Route {
+	var routes []Route
+	used := make(map[common.Address]bool)
+	path := make(Route, 0, maxHops)
+
+	var walk func(token common.Address)
+	walk = func(token common.Address) {
+		for _, hop := range g.edges[token] {
+			if used[hop.Pool] {
+				continue
+			}
+			if hop.TokenOut == start {
+				if len(path) >= 1 {
+					route := make(Route, len(path)+1)
+					copy(route, path)
+					route[len(path)] = hop
+					routes = append(routes, route)
+				}
+				continue
+			}
+			if len(path)+1 >= maxHops {
+				continue
+			}
+			used[hop.Pool] = true
+			path = append(path, hop)
+			walk(hop.TokenOut)
+			path = path[:len(path)-1]
+			used[hop.Pool] = false
+		}
+	}
+	walk(start)
+	return routes
+}
+
+// BestCycle quotes every cycle from start and returns the most profitable one.
+// ok is false when no cycle returns more than amountIn.
+func (g *Graph) BestCycle(start common.Address, amountIn *big.Int, maxHops int) (best Route, profit *big.Int, ok bool) {
+	profit = new(big.Int)
+	for _, route := range g.FindCycles(start, maxHops) {
+		out, err := route.Quote(amountIn)
+		if err != nil {
+			continue
+		}
+		gain := new(big.Int).Sub(out, amountIn)
+		if gain.Cmp(profit) > 0 {
+			best, profit, ok = route, gain, true
+		}
+	}
+	return best, profit, ok
+}
@@ -0,0 +1,498 @@
+package pipeline
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/freshness"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/seal"
+)
+
+// Stage is a step in an opportunity's execution lifecycle
+type Stage string
+
+// Lifecycle stages, in order
+const (
+	StageDetected  Stage = "detected"
+	StageScored    Stage = "scored"
+	StageSized     Stage = "sized"
+	StageSimulated Stage = "simulated"
+	StageSubmitted Stage = "submitted"
+	StageConfirmed Stage = "confirmed"
+	StageFailed    Stage = "failed"
+	StageAbandoned Stage = "abandoned" // outcome could not be tied to chain state; nothing left in flight
+	StageObserved  Stage = "observed"  // evaluated in watch-only mode; never submitted
+)
+
+// Stages lists every stage in lifecycle order
+var Stages = []Stage{StageDetected, StageScored, StageSized, StageSimulated, StageSubmitted, StageConfirmed, StageObserved, StageFailed, StageAbandoned}
+
+// next maps each stage to its successor; any non-terminal stage may also fail or be abandoned
+var next = map[Stage]Stage{
+	StageDetected:  StageScored,
+	StageScored:    StageSized,
+	StageSized:     StageSimulated,
+	StageSimulated: StageSubmitted,
+	StageSubmitted: StageConfirmed,
+}
+
+// DefaultStuckLimits are how long an opportunity may sit in each stage before
+// it is reported as stuck
+var DefaultStuckLimits = map[Stage]time.Duration{
+	StageDetected:  30 * time.Second,
+	StageScored:    30 * time.Second,
+	StageSized:     30 * time.Second,
+	StageSimulated: time.Minute,
+	StageSubmitted: 5 * time.Minute,
+}
+
+// Terminal reports whether no further transitions are possible
+func (s Stage) Terminal() bool {
+	return s == StageConfirmed || s == StageObserved || s == StageFailed || s == StageAbandoned
+}
+
+var (
+	// ErrInvalidTransition is returned for a transition the lifecycle does not allow
+	ErrInvalidTransition = errors.New("pipeline: invalid stage transition")
+	// ErrUnknown is returned for an ID the machine has not seen
+	ErrUnknown = errors.New("pipeline: unknown opportunity")
+	// ErrExists is returned when starting an ID that is already tracked
+	ErrExists = errors.New("pipeline: opportunity already tracked")
+	// ErrDuplicate is returned when an equivalent plan or the same nonce was already submitted
+	ErrDuplicate = errors.New("pipeline: duplicate submission")
+)
+
+// TxRef identifies a submitted transaction and the plan it executes
+type TxRef struct {
+	Hash        common.Hash    `json:"hash"`
+	From        common.Address `json:"from"`
+	Nonce       uint64         `json:"nonce"`
+	Fingerprint common.Hash    `json:"fingerprint,omitempty"` // route fingerprint of the plan
+	Block       uint64         `json:"block,omitempty"`       // chain head when submitted
+}
+
+// DefaultReplayWindow is how many blocks an equivalent plan is refused for after submission
+const DefaultReplayWindow = 20
+
+// Transition is one persisted stage change
+type Transition struct {
+	ID      string    `json:"id"`
+	ChainID uint64    `json:"chainId,omitempty"`
+	Block   uint64    `json:"block,omitempty"` // block the opportunity was quoted at, on Detected
+	Stage   Stage     `json:"stage"`
+	At      time.Time `json:"at"`
+	Note    string    `json:"note,omitempty"`
+	Tx      *TxRef    `json:"tx,omitempty"`
+	// Reason categorizes a failed or abandoned transition
+	Reason failure.Reason `json:"reason,omitempty"`
+}
+
+// Record is the current state of one opportunity and its stage history
+type Record struct {
+	ID      string       `json:"id"`
+	ChainID uint64       `json:"chainId"`
+	Block   uint64       `json:"block,omitempty"` // block the opportunity was quoted at
+	Stage   Stage        `json:"stage"`
+	Since   time.Time    `json:"since"` // when the current stage was entered
+	Tx      *TxRef       `json:"tx,omitempty"`
+	History []Transition `json:"history"`
+}
+
+// Machine tracks lifecycles and persists every transition to an append-only
+// file, so in-flight work can be recovered after a crash. Safe for concurrent use.
+type Machine struct {
+	mu      sync.Mutex
+	file    *os.File
+	path    string
+	records map[string]*Record
+	now     func() time.Time
+	keys    *seal.Keyring
+
+	// ReplayWindow is the block window for duplicate-plan detection
+	ReplayWindow uint64
+	// Freshness fails submissions of opportunities quoted too long ago; nil
+	// submits regardless of age
+	Freshness *freshness.Policy
+
+	transitions *metrics.CounterVec
+	inStage     *metrics.GaugeVec
+	stageTime   *metrics.HistogramVec
+	failures    *failure.Counter
+}
+
+// stageBuckets are histogram bounds for time spent in a stage, in seconds
+var stageBuckets = []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 15, 60, 300}
+
+// Open replays the transition log at path (creating it if needed) and returns a machine
+func Open(path string, reg *metrics.Registry) (*Machine, error) {
+	return OpenSealed(path, reg, nil)
+}
+
+// OpenSealed is Open for a log whose lines are sealed with keys; compaction
+// reseals every kept line under the primary key
+func OpenSealed(path string, reg *metrics.Registry, keys *seal.Keyring) (*Machine, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	m := &Machine{path: path, records: make(map[string]*Record), now: time.Now, keys: keys, ReplayWindow: DefaultReplayWindow}
+	if reg != nil {
+		m.transitions = reg.Counter("titan_pipeline_transitions_total", "Opportunity stage transitions", "stage")
+		m.inStage = reg.Gauge("titan_pipeline_in_stage", "Opportunities currently in each stage", "stage")
+		m.stageTime = reg.Histogram("titan_pipeline_stage_seconds", "Time spent in a stage before leaving it", stageBuckets, "stage")
+		m.failures = failure.NewCounter(reg)
+	}
+
+	if err := m.replay(); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pipeline log %s: %w", path, err)
+	}
+	m.file = f
+	m.updateGauges()
+	return m, nil
+}
+
+func (m *Machine) replay() error {
+	f, err := os.Open(m.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, err := m.keys.Open(scanner.Bytes())
+		if errors.Is(err, seal.ErrNoKey) {
+			return fmt.Errorf("%s: %w", m.path, err)
+		}
+		var t Transition
+		if err != nil || json.Unmarshal(line, &t) != nil {
+			continue // tolerate a torn final line after a crash
+		}
+		m.apply(t)
+	}
+	return scanner.Err()
+}
+
+// apply updates in-memory state; caller holds m.mu (or is replaying)
+func (m *Machine) apply(t Transition) *Record {
+	r, ok := m.records[t.ID]
+	if !ok {
+		r = &Record{ID: t.ID, ChainID: t.ChainID, Block: t.Block}
+		m.records[t.ID] = r
+	}
+	r.Stage = t.Stage
+	r.Since = t.At
+	if t.Tx != nil {
+		r.Tx = t.Tx
+	}
+	r.History = append(r.History, t)
+	return r
+}
+
+// Start begins tracking an opportunity in the Detected stage
+func (m *Machine) Start(id string, chainID uint64) error {
+	return m.StartAt(id, chainID, 0)
+}
+
+// StartAt is Start for an opportunity quoted at block, so submission can
+// enforce the chain's freshness window in blocks as well as time
+func (m *Machine) StartAt(id string, chainID, block uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.records[id]; ok {
+		return fmt.Errorf("%w: %s", ErrExists, id)
+	}
+	return m.persist(Transition{ID: id, ChainID: chainID, Block: block, Stage: StageDetected, At: m.now()})
+}
+
+// Advance moves an opportunity to stage, recording note. Failing or
+// abandoning through Advance categorizes the failure from the note.
+func (m *Machine) Advance(id string, stage Stage, note string) error {
+	if stage == StageFailed || stage == StageAbandoned {
+		return m.interrupt(id, stage, failure.Classify(note), note)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.records[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknown, id)
+	}
+	return m.advanceLocked(r, Transition{ID: id, Stage: stage, Note: note})
+}
+
+// Submit records the signed transaction and moves Simulated → Submitted. Call
+// it before broadcasting so a crash can never leave an untracked transaction.
+// It refuses (ErrDuplicate) a plan whose fingerprint was submitted within
+// ReplayWindow blocks, or a nonce still in flight from the same sender. An
+// opportunity outside its chain's freshness window is failed as a stale
+// quote and freshness.ErrStale returned.
+func (m *Machine) Submit(id string, tx TxRef) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.records[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknown, id)
+	}
+	if err := m.checkReplayLocked(r, tx); err != nil {
+		return err
+	}
+	if err := m.Freshness.Check("submit", r.ChainID, r.History[0].At, r.Block, tx.Block); err != nil {
+		from := r.Stage
+		if ferr := m.advanceLocked(r, Transition{ID: id, Stage: StageFailed, Note: err.Error(), Reason: failure.StaleQuote}); ferr != nil {
+			return ferr
+		}
+		m.failures.Inc(string(from), failure.StaleQuote)
+		return err
+	}
+	return m.advanceLocked(r, Transition{ID: id, Stage: StageSubmitted, Tx: &tx})
+}
+
+// checkReplayLocked looks for an earlier equivalent submission; caller holds m.mu
+func (m *Machine) checkReplayLocked(r *Record, tx TxRef) error {
+	for _, other := range m.records {
+		if other.ID == r.ID || other.Tx == nil || other.ChainID != r.ChainID {
+			continue
+		}
+		if tx.Fingerprint != (common.Hash{}) && other.Tx.Fingerprint == tx.Fingerprint &&
+			tx.Block < other.Tx.Block+m.ReplayWindow {
+			return fmt.Errorf("%w: plan %s already submitted by %s at block %d",
+				ErrDuplicate, tx.Fingerprint.Hex()[:10], other.ID, other.Tx.Block)
+		}
+		if other.Stage == StageSubmitted && other.Tx.From == tx.From && other.Tx.Nonce == tx.Nonce {
+			return fmt.Errorf("%w: nonce %d of %s already in flight for %s",
+				ErrDuplicate, tx.Nonce, tx.From.Hex(), other.ID)
+		}
+	}
+	return nil
+}
+
+// Abandon moves an opportunity to Abandoned from any non-terminal stage,
+// categorizing it from the note
+func (m *Machine) Abandon(id string, reason string) error {
+	return m.Advance(id, StageAbandoned, reason)
+}
+
+// AbandonAs moves an opportunity to Abandoned with a failure category
+func (m *Machine) AbandonAs(id string, reason failure.Reason, note string) error {
+	return m.interrupt(id, StageAbandoned, reason, note)
+}
+
+// Observe ends an opportunity's lifecycle before submission, for watch-only
+// runs that evaluate everything but hold no signer
+func (m *Machine) Observe(id string, note string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.records[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknown, id)
+	}
+	return m.advanceLocked(r, Transition{ID: id, Stage: StageObserved, Note: note})
+}
+
+// advanceLocked validates and persists a transition; caller holds m.mu
+func (m *Machine) advanceLocked(r *Record, t Transition) error {
+	interrupt := t.Stage == StageFailed || t.Stage == StageAbandoned
+	observe := t.Stage == StageObserved && r.Stage != StageSubmitted
+	if r.Stage.Terminal() || (!interrupt && !observe && next[r.Stage] != t.Stage) {
+		return fmt.Errorf("%w: %s %s → %s", ErrInvalidTransition, r.ID, r.Stage, t.Stage)
+	}
+
+	t.At = m.now()
+	if m.stageTime != nil {
+		m.stageTime.Observe(t.At.Sub(r.Since).Seconds(), string(r.Stage))
+	}
+	return m.persist(t)
+}
+
+// Fail moves an opportunity to Failed from any non-terminal stage,
+// categorizing it from the note
+func (m *Machine) Fail(id string, reason string) error {
+	return m.Advance(id, StageFailed, reason)
+}
+
+// FailAs moves an opportunity to Failed with a failure category
+func (m *Machine) FailAs(id string, reason failure.Reason, note string) error {
+	return m.interrupt(id, StageFailed, reason, note)
+}
+
+// interrupt ends a lifecycle early and counts the failure against the stage
+// it was in
+func (m *Machine) interrupt(id string, stage Stage, reason failure.Reason, note string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.records[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknown, id)
+	}
+	from := r.Stage
+	if err := m.advanceLocked(r, Transition{ID: id, Stage: stage, Note: note, Reason: reason}); err != nil {
+		return err
+	}
+	m.failures.Inc(string(from), reason)
+	return nil
+}
+
+// persist writes then applies a transition; caller holds m.mu
+func (m *Machine) persist(t Transition) error {
+	line, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if line, err = m.keys.Seal(line); err != nil {
+		return err
+	}
+	if _, err := m.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to persist transition: %w", err)
+	}
+	if err := m.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync pipeline log: %w", err)
+	}
+	m.apply(t)
+	if m.transitions != nil {
+		m.transitions.Inc(string(t.Stage))
+	}
+	m.updateGaugesLocked()
+	return nil
+}
+
+// Get returns a copy of an opportunity's record
+func (m *Machine) Get(id string) (Record, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.records[id]
+	if !ok {
+		return Record{}, false
+	}
+	return copyRecord(r), true
+}
+
+// InFlight returns every record in a non-terminal stage, oldest first
+func (m *Machine) InFlight() []Record {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Record
+	for _, r := range m.records {
+		if !r.Stage.Terminal() {
+			out = append(out, copyRecord(r))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Since.Before(out[j].Since) })
+	return out
+}
+
+// Stuck returns in-flight records that have been in their stage longer than
+// the stage's limit; stages without a limit are never stuck
+func (m *Machine) Stuck(limits map[Stage]time.Duration) []Record {
+	now := m.now()
+	var out []Record
+	for _, r := range m.InFlight() {
+		if limit, ok := limits[r.Stage]; ok && now.Sub(r.Since) > limit {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Compact rewrites the log keeping in-flight records and terminal records
+// still inside the replay window
+func (m *Machine) Compact() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	head := make(map[uint64]uint64) // chain -> latest submission block
+	for _, r := range m.records {
+		if r.Tx != nil && r.Tx.Block > head[r.ChainID] {
+			head[r.ChainID] = r.Tx.Block
+		}
+	}
+
+	tmp := m.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for id, r := range m.records {
+		replayable := r.Tx != nil && r.Tx.Block+m.ReplayWindow > head[r.ChainID]
+		if r.Stage.Terminal() && !replayable {
+			delete(m.records, id)
+			continue
+		}
+		for _, t := range r.History {
+			line, err := json.Marshal(t)
+			if err == nil {
+				line, err = m.keys.Seal(line)
+			}
+			if err != nil {
+				f.Close()
+				return err
+			}
+			w.Write(append(line, '\n'))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := m.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		return err
+	}
+	m.file, err = os.OpenFile(m.path, os.O_APPEND|os.O_WRONLY, 0o644)
+	return err
+}
+
+// Close closes the transition log
+func (m *Machine) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.file.Close()
+}
+
+func (m *Machine) updateGauges() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.updateGaugesLocked()
+}
+
+func (m *Machine) updateGaugesLocked() {
+	if m.inStage == nil {
+		return
+	}
+	counts := make(map[Stage]int)
+	for _, r := range m.records {
+		counts[r.Stage]++
+	}
+	for _, s := range Stages {
+		if !s.Terminal() {
+			m.inStage.Set(float64(counts[s]), string(s))
+		}
+	}
+}
+
+func copyRecord(r *Record) Record {
+	c := *r
+	c.History = append([]Transition(nil), r.History...)
+	return c
+}
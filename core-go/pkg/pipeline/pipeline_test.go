@@ -0,0 +1,234 @@
+package pipeline
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/freshness"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+func TestLifecycleTransitions(t *testing.T) {
+	reg := metrics.NewRegistry()
+	m, err := Open(filepath.Join(t.TempDir(), "pipeline.jsonl"), reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if err := m.Start("a", 137); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Start("a", 137); !errors.Is(err, ErrExists) {
+		t.Errorf("Expected ErrExists, got %v", err)
+	}
+	if err := m.Advance("a", StageSized, ""); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected skipping a stage to fail, got %v", err)
+	}
+	for _, s := range []Stage{StageScored, StageSized, StageSimulated, StageSubmitted, StageConfirmed} {
+		if err := m.Advance("a", s, ""); err != nil {
+			t.Fatalf("Advance to %s: %v", s, err)
+		}
+	}
+	if err := m.Fail("a", "late"); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected terminal record to reject transitions, got %v", err)
+	}
+
+	r, _ := m.Get("a")
+	if len(r.History) != 6 || r.ChainID != 137 {
+		t.Errorf("Expected 6 transitions on chain 137, got %d on %d", len(r.History), r.ChainID)
+	}
+	if got := reg.Value("titan_pipeline_transitions_total", string(StageConfirmed)); got != 1 {
+		t.Errorf("Expected 1 confirmed transition, got %v", got)
+	}
+}
+
+func TestFailuresAreCategorized(t *testing.T) {
+	reg := metrics.NewRegistry()
+	m, err := Open(filepath.Join(t.TempDir(), "pipeline.jsonl"), reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	m.Start("a", 1)
+	m.Advance("a", StageScored, "")
+	if err := m.FailAs("a", failure.SimulationRevert, "simulated revert: INSUFFICIENT_PROFIT"); err != nil {
+		t.Fatal(err)
+	}
+	m.Start("b", 1)
+	m.Abandon("b", "dropped from mempool with nonce 3 unused")
+
+	r, _ := m.Get("a")
+	if last := r.History[len(r.History)-1]; last.Reason != failure.SimulationRevert {
+		t.Errorf("Expected the failure reason on the transition, got %+v", last)
+	}
+	if got := reg.Value("titan_failures_total", string(StageScored), string(failure.SimulationRevert)); got != 1 {
+		t.Errorf("Expected 1 simulation failure from scored, got %v", got)
+	}
+	if got := reg.Value("titan_failures_total", string(StageDetected), string(failure.Dropped)); got != 1 {
+		t.Errorf("Expected a classified abandonment from detected, got %v", got)
+	}
+}
+
+func TestRecoveryAndStuckDetection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pipeline.jsonl")
+	m, err := Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Start("done", 1)
+	m.Fail("done", "reverted")
+	m.Start("inflight", 1)
+	m.Advance("inflight", StageScored, "")
+	m.Close()
+
+	// Reopen as after a crash
+	m, err = Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if err := m.Compact(); err != nil {
+		t.Fatal(err)
+	}
+
+	inFlight := m.InFlight()
+	if len(inFlight) != 1 || inFlight[0].ID != "inflight" || inFlight[0].Stage != StageScored {
+		t.Fatalf("Expected inflight in scored stage, got %+v", inFlight)
+	}
+	if _, ok := m.Get("done"); ok {
+		t.Error("Expected compaction to drop terminal records")
+	}
+
+	m.now = func() time.Time { return time.Now().Add(time.Hour) }
+	stuck := m.Stuck(map[Stage]time.Duration{StageScored: time.Minute})
+	if len(stuck) != 1 {
+		t.Errorf("Expected 1 stuck record, got %d", len(stuck))
+	}
+	if err := m.Advance("inflight", StageSized, ""); err != nil {
+		t.Errorf("Expected recovered record to keep advancing, got %v", err)
+	}
+}
+
+func TestSubmitRefusesReplays(t *testing.T) {
+	m, err := Open(filepath.Join(t.TempDir(), "pipeline.jsonl"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	m.ReplayWindow = 10
+
+	ready := func(id string) {
+		m.Start(id, 137)
+		for _, s := range []Stage{StageScored, StageSized, StageSimulated} {
+			if err := m.Advance(id, s, ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	sender := common.HexToAddress("0xabc")
+	plan := common.HexToHash("0x01")
+
+	ready("first")
+	if err := m.Submit("first", TxRef{Hash: common.Hash{1}, From: sender, Nonce: 7, Fingerprint: plan, Block: 100}); err != nil {
+		t.Fatal(err)
+	}
+	m.Fail("first", "reverted")
+
+	ready("double-fire")
+	err = m.Submit("double-fire", TxRef{Hash: common.Hash{2}, From: sender, Nonce: 8, Fingerprint: plan, Block: 105})
+	if !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected same plan within window to be refused, got %v", err)
+	}
+	if err := m.Submit("double-fire", TxRef{Hash: common.Hash{2}, From: sender, Nonce: 8, Fingerprint: plan, Block: 110}); err != nil {
+		t.Errorf("Expected same plan after window to be accepted, got %v", err)
+	}
+
+	ready("same-nonce")
+	err = m.Submit("same-nonce", TxRef{Hash: common.Hash{3}, From: sender, Nonce: 8, Fingerprint: common.HexToHash("0x02"), Block: 111})
+	if !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected in-flight nonce reuse to be refused, got %v", err)
+	}
+	if r, _ := m.Get("same-nonce"); r.Stage != StageSimulated {
+		t.Errorf("Expected refused submission to stay simulated, got %s", r.Stage)
+	}
+}
+
+func TestSubmitRefusesStaleQuotes(t *testing.T) {
+	reg := metrics.NewRegistry()
+	m, err := Open(filepath.Join(t.TempDir(), "pipeline.jsonl"), reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	m.Freshness = freshness.New(map[uint64]freshness.Window{137: {MaxBlocks: 2}}, nil)
+
+	ready := func(id string, block uint64) {
+		m.StartAt(id, 137, block)
+		for _, s := range []Stage{StageScored, StageSized, StageSimulated} {
+			if err := m.Advance(id, s, ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	ready("fresh", 100)
+	if err := m.Submit("fresh", TxRef{Hash: common.Hash{1}, Nonce: 1, Block: 102}); err != nil {
+		t.Errorf("Expected a quote 2 blocks old accepted, got %v", err)
+	}
+
+	ready("stale", 100)
+	if err := m.Submit("stale", TxRef{Hash: common.Hash{2}, Nonce: 2, Block: 110}); !errors.Is(err, freshness.ErrStale) {
+		t.Errorf("Expected ErrStale, got %v", err)
+	}
+	r, _ := m.Get("stale")
+	if last := r.History[len(r.History)-1]; r.Stage != StageFailed || last.Reason != failure.StaleQuote {
+		t.Errorf("Expected failed as %s, got %s (%s)", failure.StaleQuote, r.Stage, last.Reason)
+	}
+	if got := reg.Value("titan_failures_total", string(StageSimulated), string(failure.StaleQuote)); got != 1 {
+		t.Errorf("Expected 1 stale failure counted, got %v", got)
+	}
+}
+
+func TestObserveEndsWatchOnlyLifecycles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pipeline.jsonl")
+	m, err := Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Start("watched", 1)
+	m.Advance("watched", StageScored, "")
+	if err := m.Observe("watched", "watch-only"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Advance("watched", StageSized, ""); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected an observed record to be terminal, got %v", err)
+	}
+
+	m.Start("sent", 1)
+	for _, s := range []Stage{StageScored, StageSized, StageSimulated} {
+		m.Advance("sent", s, "")
+	}
+	m.Submit("sent", TxRef{Hash: common.Hash{1}})
+	if err := m.Observe("sent", "watch-only"); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected a submitted record not to be observed, got %v", err)
+	}
+	m.Close()
+
+	m, err = Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if r, _ := m.Get("watched"); r.Stage != StageObserved {
+		t.Errorf("Expected observed after replay, got %s", r.Stage)
+	}
+	if inFlight := m.InFlight(); len(inFlight) != 1 || inFlight[0].ID != "sent" {
+		t.Errorf("Expected only the submitted record in flight, got %+v", inFlight)
+	}
+}
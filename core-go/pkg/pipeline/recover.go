@@ -0,0 +1,227 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+)
+
+// Reconciliation actions
+const (
+	ActionConfirmed  = "confirmed"
+	ActionFailed     = "failed"
+	ActionAbandoned  = "abandoned"
+	ActionMonitoring = "monitoring"
+	ActionReorged    = "reorged"
+)
+
+// Outcome is what reconciliation decided for one in-flight record
+type Outcome struct {
+	ID     string
+	Action string
+	Detail string
+	Reason failure.Reason // for failed and abandoned outcomes
+
+	// Block and BlockHash locate the receipt the outcome was decided from;
+	// for ActionReorged they name the block that is no longer canonical
+	Block     uint64
+	BlockHash common.Hash
+}
+
+// inclusion is where a submitted transaction was last seen mined
+type inclusion struct {
+	block uint64
+	hash  common.Hash
+}
+
+// Reconciler ties persisted in-flight records to chain state after a restart
+// and keeps monitoring submitted transactions. Running it repeatedly is safe:
+// terminal records are never revisited.
+type Reconciler struct {
+	// Confirmations is the block depth a receipt needs before its record is
+	// settled, per chain; chains without an entry settle at depth 1
+	Confirmations map[uint64]uint64
+
+	// OnPass is called after each Watch pass, whether or not it settled anything
+	OnPass func()
+
+	machine *Machine
+	chains  func(chainID uint64) (chain.Client, error)
+
+	mu   sync.Mutex
+	seen map[string]inclusion
+}
+
+// NewReconciler creates a reconciler; chains resolves a chain's state reader
+func NewReconciler(machine *Machine, chains func(chainID uint64) (chain.Client, error)) *Reconciler {
+	return &Reconciler{
+		Confirmations: make(map[uint64]uint64),
+		machine:       machine,
+		chains:        chains,
+		seen:          make(map[string]inclusion),
+	}
+}
+
+// confirmations returns the settlement depth for a chain
+func (rc *Reconciler) confirmations(chainID uint64) uint64 {
+	if n := rc.Confirmations[chainID]; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// Reconcile resolves every in-flight record. Records that never reached
+// Submitted had nothing broadcast and are abandoned; submitted ones are settled
+// from their receipt or nonce, or left in monitoring while still pending or
+// short of the chain's confirmation depth.
+// Call it once at startup, before new work enters the pipeline.
+func (rc *Reconciler) Reconcile(ctx context.Context) ([]Outcome, error) {
+	return rc.run(ctx, false)
+}
+
+func (rc *Reconciler) run(ctx context.Context, submittedOnly bool) ([]Outcome, error) {
+	var outcomes []Outcome
+	var errs []error
+	for _, r := range rc.machine.InFlight() {
+		if submittedOnly && r.Stage != StageSubmitted {
+			continue
+		}
+		o, err := rc.reconcile(ctx, r)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.ID, err))
+			continue
+		}
+		outcomes = append(outcomes, o)
+	}
+	return outcomes, errors.Join(errs...)
+}
+
+func (rc *Reconciler) reconcile(ctx context.Context, r Record) (Outcome, error) {
+	if r.Stage != StageSubmitted || r.Tx == nil {
+		detail := fmt.Sprintf("interrupted in %s before submission", r.Stage)
+		return Outcome{ID: r.ID, Action: ActionAbandoned, Detail: detail, Reason: failure.Interrupted}, rc.machine.AbandonAs(r.ID, failure.Interrupted, detail)
+	}
+
+	node, err := rc.chains(r.ChainID)
+	if err != nil {
+		return Outcome{}, err
+	}
+
+	receipt, err := node.TransactionReceipt(ctx, r.Tx.Hash)
+	if err == nil {
+		return rc.settle(ctx, node, r, receipt)
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return Outcome{}, err
+	}
+	if o, reorged := rc.forget(r.ID); reorged {
+		return o, nil
+	}
+
+	// No receipt: either still pending, replaced, or dropped
+	nonce, err := node.NonceAt(ctx, r.Tx.From, nil)
+	if err != nil {
+		return Outcome{}, err
+	}
+	if nonce > r.Tx.Nonce {
+		detail := fmt.Sprintf("nonce %d consumed by a different transaction", r.Tx.Nonce)
+		return Outcome{ID: r.ID, Action: ActionAbandoned, Detail: detail, Reason: failure.Dropped}, rc.machine.AbandonAs(r.ID, failure.Dropped, detail)
+	}
+	if _, _, err := node.TransactionByHash(ctx, r.Tx.Hash); errors.Is(err, ethereum.NotFound) {
+		detail := fmt.Sprintf("dropped from mempool with nonce %d unused", r.Tx.Nonce)
+		return Outcome{ID: r.ID, Action: ActionAbandoned, Detail: detail, Reason: failure.Dropped}, rc.machine.AbandonAs(r.ID, failure.Dropped, detail)
+	} else if err != nil {
+		return Outcome{}, err
+	}
+	return Outcome{ID: r.ID, Action: ActionMonitoring, Detail: "pending in mempool"}, nil
+}
+
+// settle finalizes a mined record once its receipt is deep enough. A receipt
+// that moved to a different block since the last pass is reported as a reorg
+// before the new inclusion is tracked.
+func (rc *Reconciler) settle(ctx context.Context, node chain.Client, r Record, receipt *types.Receipt) (Outcome, error) {
+	mined := inclusion{block: receipt.BlockNumber.Uint64(), hash: receipt.BlockHash}
+	rc.mu.Lock()
+	prev, ok := rc.seen[r.ID]
+	rc.seen[r.ID] = mined
+	rc.mu.Unlock()
+	if ok && prev != mined {
+		detail := fmt.Sprintf("moved from block %d to %d", prev.block, mined.block)
+		return Outcome{ID: r.ID, Action: ActionReorged, Detail: detail, Block: prev.block, BlockHash: prev.hash}, nil
+	}
+
+	head, err := node.BlockNumber(ctx)
+	if err != nil {
+		return Outcome{}, err
+	}
+	var depth uint64
+	if head >= mined.block {
+		depth = head - mined.block + 1
+	}
+	if need := rc.confirmations(r.ChainID); depth < need {
+		detail := fmt.Sprintf("mined in block %d, %d/%d confirmations", mined.block, depth, need)
+		return Outcome{ID: r.ID, Action: ActionMonitoring, Detail: detail, Block: mined.block, BlockHash: mined.hash}, nil
+	}
+
+	rc.mu.Lock()
+	delete(rc.seen, r.ID)
+	rc.mu.Unlock()
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		detail := fmt.Sprintf("final in block %d after %d confirmations", mined.block, depth)
+		o := Outcome{ID: r.ID, Action: ActionConfirmed, Detail: detail, Block: mined.block, BlockHash: mined.hash}
+		return o, rc.machine.Advance(r.ID, StageConfirmed, detail)
+	}
+	detail := fmt.Sprintf("reverted in block %d", mined.block)
+	o := Outcome{ID: r.ID, Action: ActionFailed, Detail: detail, Reason: failure.Reverted, Block: mined.block, BlockHash: mined.hash}
+	return o, rc.machine.FailAs(r.ID, failure.Reverted, detail)
+}
+
+// forget drops the tracked inclusion of a record whose receipt is gone,
+// reporting a reorg if it had been seen mined
+func (rc *Reconciler) forget(id string) (Outcome, bool) {
+	rc.mu.Lock()
+	prev, ok := rc.seen[id]
+	delete(rc.seen, id)
+	rc.mu.Unlock()
+	if !ok {
+		return Outcome{}, false
+	}
+	detail := fmt.Sprintf("receipt disappeared after inclusion in block %d", prev.block)
+	return Outcome{ID: id, Action: ActionReorged, Detail: detail, Block: prev.block, BlockHash: prev.hash}, true
+}
+
+// Watch settles submitted transactions every interval until ctx is cancelled,
+// reporting each settled outcome to notify. Earlier stages are left alone
+// because they belong to live work.
+func (rc *Reconciler) Watch(ctx context.Context, interval time.Duration, notify func(Outcome)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		outcomes, err := rc.run(ctx, true)
+		if err != nil {
+			log.Printf("⚠️ Pipeline reconciliation: %v", err)
+		}
+		for _, o := range outcomes {
+			if o.Action != ActionMonitoring && notify != nil {
+				notify(o)
+			}
+		}
+		if rc.OnPass != nil {
+			rc.OnPass()
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package pipeline
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+)
+
+// fakeChain answers receipts, pending transactions and nonces from maps
+type fakeChain struct {
+	chain.Client
+	head     uint64
+	receipts map[common.Hash]uint64 // hash -> receipt status
+	blocks   map[common.Hash]uint64 // hash -> inclusion block, 100 if unset
+	pending  map[common.Hash]bool
+	nonces   map[common.Address]uint64
+}
+
+func (f *fakeChain) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	status, ok := f.receipts[hash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	block := f.blocks[hash]
+	if block == 0 {
+		block = 100
+	}
+	return &types.Receipt{Status: status, BlockNumber: new(big.Int).SetUint64(block), BlockHash: common.BigToHash(new(big.Int).SetUint64(block))}, nil
+}
+
+func (f *fakeChain) BlockNumber(ctx context.Context) (uint64, error) {
+	return f.head, nil
+}
+
+func (f *fakeChain) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if !f.pending[hash] {
+		return nil, false, ethereum.NotFound
+	}
+	return types.NewTx(&types.LegacyTx{}), true, nil
+}
+
+func (f *fakeChain) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return f.nonces[account], nil
+}
+
+func submitted(t *testing.T, m *Machine, id string, tx TxRef) {
+	t.Helper()
+	m.Start(id, 1)
+	for _, s := range []Stage{StageScored, StageSized, StageSimulated} {
+		if err := m.Advance(id, s, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Submit(id, tx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReconcileInFlight(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pipeline.jsonl")
+	m, err := Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := common.HexToAddress("0x1")
+	hash := func(n byte) common.Hash { return common.Hash{n} }
+
+	submitted(t, m, "mined", TxRef{Hash: hash(1), From: sender, Nonce: 1})
+	submitted(t, m, "reverted", TxRef{Hash: hash(2), From: sender, Nonce: 2})
+	submitted(t, m, "replaced", TxRef{Hash: hash(3), From: sender, Nonce: 3})
+	submitted(t, m, "pending", TxRef{Hash: hash(4), From: sender, Nonce: 5})
+	submitted(t, m, "dropped", TxRef{Hash: hash(5), From: sender, Nonce: 6})
+	m.Start("early", 1)
+	m.Close()
+
+	node := &fakeChain{
+		head:     100,
+		receipts: map[common.Hash]uint64{hash(1): types.ReceiptStatusSuccessful, hash(2): types.ReceiptStatusFailed},
+		pending:  map[common.Hash]bool{hash(4): true},
+		nonces:   map[common.Address]uint64{sender: 5},
+	}
+
+	m, err = Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	rc := NewReconciler(m, func(uint64) (chain.Client, error) { return node, nil })
+	outcomes, err := rc.Reconcile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"mined":    ActionConfirmed,
+		"reverted": ActionFailed,
+		"replaced": ActionAbandoned,
+		"pending":  ActionMonitoring,
+		"dropped":  ActionAbandoned,
+		"early":    ActionAbandoned,
+	}
+	if len(outcomes) != len(want) {
+		t.Fatalf("Expected %d outcomes, got %+v", len(want), outcomes)
+	}
+	for _, o := range outcomes {
+		if want[o.ID] != o.Action {
+			t.Errorf("Expected %s to be %s, got %s (%s)", o.ID, want[o.ID], o.Action, o.Detail)
+		}
+	}
+
+	// A second pass only revisits the still-pending transaction
+	outcomes, err = rc.Reconcile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outcomes) != 1 || outcomes[0].ID != "pending" {
+		t.Errorf("Expected only pending to remain in flight, got %+v", outcomes)
+	}
+}
+
+func TestReconcileWaitsForConfirmations(t *testing.T) {
+	m, err := Open(filepath.Join(t.TempDir(), "pipeline.jsonl"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	tx := TxRef{Hash: common.Hash{1}, From: common.HexToAddress("0x1"), Nonce: 1}
+	submitted(t, m, "trade", tx)
+
+	node := &fakeChain{
+		head:     101,
+		receipts: map[common.Hash]uint64{tx.Hash: types.ReceiptStatusSuccessful},
+		blocks:   map[common.Hash]uint64{tx.Hash: 100},
+	}
+	rc := NewReconciler(m, func(uint64) (chain.Client, error) { return node, nil })
+	rc.Confirmations[1] = 3
+	step := func() Outcome {
+		t.Helper()
+		outcomes, err := rc.Reconcile(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(outcomes) != 1 {
+			t.Fatalf("Expected 1 outcome, got %+v", outcomes)
+		}
+		return outcomes[0]
+	}
+
+	if o := step(); o.Action != ActionMonitoring {
+		t.Errorf("Expected monitoring at 2/3 confirmations, got %s (%s)", o.Action, o.Detail)
+	}
+
+	// The block is reorged out and the transaction re-mined later
+	node.blocks[tx.Hash] = 102
+	if o := step(); o.Action != ActionReorged || o.Block != 100 {
+		t.Errorf("Expected reorg out of block 100, got %s at %d", o.Action, o.Block)
+	}
+	if r, _ := m.Get("trade"); r.Stage != StageSubmitted {
+		t.Errorf("Expected reorged trade to stay submitted, got %s", r.Stage)
+	}
+
+	node.head = 104
+	if o := step(); o.Action != ActionConfirmed || o.Block != 102 {
+		t.Errorf("Expected confirmation in block 102, got %s at %d", o.Action, o.Block)
+	}
+}
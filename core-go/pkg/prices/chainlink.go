@@ -0,0 +1,138 @@
+package prices
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+const aggregatorABI = `[
+{"inputs":[],"name":"decimals","outputs":[{"type":"uint8"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"latestRoundData","outputs":[{"name":"roundId","type":"uint80"},{"name":"answer","type":"int256"},{"name":"startedAt","type":"uint256"},{"name":"updatedAt","type":"uint256"},{"name":"answeredInRound","type":"uint80"}],"stateMutability":"view","type":"function"}
+]`
+
+var parsedAggregatorABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(aggregatorABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// DefaultFeedMaxAge is how old a Chainlink round may be before it is rejected
+const DefaultFeedMaxAge = 2 * time.Hour
+
+// Dialer returns a contract caller for a chain
+type Dialer func(chainID uint64) (ethereum.ContractCaller, error)
+
+// ChainlinkSource reads native/USD prices from Chainlink aggregators
+type ChainlinkSource struct {
+	feeds  map[uint64]common.Address
+	dial   Dialer
+	maxAge time.Duration
+
+	mu       sync.Mutex
+	decimals map[common.Address]uint8
+}
+
+// NewChainlinkSource creates a source over the given feeds
+func NewChainlinkSource(feeds map[uint64]common.Address, dial Dialer) *ChainlinkSource {
+	return &ChainlinkSource{feeds: feeds, dial: dial, maxAge: DefaultFeedMaxAge, decimals: make(map[common.Address]uint8)}
+}
+
+// ChainlinkFeedsFromConfig collects each chain's configured native/USD feed
+func ChainlinkFeedsFromConfig(cfg *config.Config) map[uint64]common.Address {
+	feeds := make(map[uint64]common.Address)
+	for chainID, chain := range cfg.Chains {
+		if chain.NativeUSDFeed != "" {
+			feeds[chainID] = common.HexToAddress(chain.NativeUSDFeed)
+		}
+	}
+	return feeds
+}
+
+// Name returns the source name
+func (c *ChainlinkSource) Name() string { return "chainlink" }
+
+// NativePriceE8 returns the latest answer normalized to 8 decimals
+func (c *ChainlinkSource) NativePriceE8(ctx context.Context, chainID uint64) (uint64, error) {
+	feed, ok := c.feeds[chainID]
+	if !ok {
+		return 0, fmt.Errorf("no feed for chain %d", chainID)
+	}
+	caller, err := c.dial(chainID)
+	if err != nil {
+		return 0, err
+	}
+	decimals, err := c.feedDecimals(ctx, caller, feed)
+	if err != nil {
+		return 0, err
+	}
+
+	out, err := call(ctx, caller, feed, "latestRoundData")
+	if err != nil {
+		return 0, err
+	}
+	answer, updatedAt := out[1].(*big.Int), out[3].(*big.Int)
+	if answer.Sign() <= 0 {
+		return 0, fmt.Errorf("feed %s returned non-positive answer %s", feed.Hex(), answer)
+	}
+	if age := time.Since(time.Unix(updatedAt.Int64(), 0)); c.maxAge > 0 && age > c.maxAge {
+		return 0, fmt.Errorf("feed %s last updated %s ago", feed.Hex(), age.Round(time.Second))
+	}
+
+	amount, err := units.FromBig(feed, answer, decimals)
+	if err != nil {
+		return 0, err
+	}
+	scaled, err := amount.Rescale(feed, units.PriceDecimals)
+	if err != nil {
+		return 0, err
+	}
+	if !scaled.Value.IsUint64() {
+		return 0, units.ErrOverflow
+	}
+	return scaled.Value.Uint64(), nil
+}
+
+func (c *ChainlinkSource) feedDecimals(ctx context.Context, caller ethereum.ContractCaller, feed common.Address) (uint8, error) {
+	c.mu.Lock()
+	d, ok := c.decimals[feed]
+	c.mu.Unlock()
+	if ok {
+		return d, nil
+	}
+	out, err := call(ctx, caller, feed, "decimals")
+	if err != nil {
+		return 0, err
+	}
+	d = out[0].(uint8)
+	if err := units.ValidateDecimals(d); err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	c.decimals[feed] = d
+	c.mu.Unlock()
+	return d, nil
+}
+
+func call(ctx context.Context, caller ethereum.ContractCaller, to common.Address, method string) ([]interface{}, error) {
+	data, err := parsedAggregatorABI.Pack(method)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := caller.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return parsedAggregatorABI.Unpack(method, ret)
+}
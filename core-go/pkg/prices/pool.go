@@ -0,0 +1,75 @@
+package prices
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// stableSymbols are the USD tokens a pool price is derived against, in preference order
+var stableSymbols = []string{"USDC", "USDT", "DAI"}
+
+// PoolSource derives native/USD prices from the deepest cached wrapped-native/stablecoin pool
+type PoolSource struct {
+	cache    *reserves.Cache
+	registry *tokens.Registry
+	maxAge   time.Duration
+}
+
+// NewPoolSource creates a source over a reserve cache; snapshots older than maxAge are ignored
+func NewPoolSource(cache *reserves.Cache, registry *tokens.Registry, maxAge time.Duration) *PoolSource {
+	return &PoolSource{cache: cache, registry: registry, maxAge: maxAge}
+}
+
+// Name returns the source name
+func (p *PoolSource) Name() string { return "pool" }
+
+// NativePriceE8 prices the wrapped native token at the spot ratio of its deepest stable pool
+func (p *PoolSource) NativePriceE8(ctx context.Context, chainID uint64) (uint64, error) {
+	wrapped, ok := p.registry.WrappedNative(chainID)
+	if !ok {
+		return 0, fmt.Errorf("no wrapped native token for chain %d", chainID)
+	}
+	for _, symbol := range stableSymbols {
+		stable, err := p.registry.Lookup(chainID, symbol)
+		if err != nil {
+			continue
+		}
+		pools := p.cache.Pair(chainID, wrapped.Address, stable.Address, p.maxAge)
+		if len(pools) == 0 {
+			continue
+		}
+		s := pools[0]
+		nativeReserve, stableReserve := s.Reserve0, s.Reserve1
+		if s.Token0 != wrapped.Address {
+			nativeReserve, stableReserve = s.Reserve1, s.Reserve0
+		}
+		if nativeReserve.Sign() == 0 {
+			continue
+		}
+
+		// price = stableReserve/10^sd / (nativeReserve/10^nd), scaled to 8 decimals
+		nativeScale, err := units.Pow10(wrapped.Decimals)
+		if err != nil {
+			return 0, err
+		}
+		stableScale, err := units.Pow10(stable.Decimals)
+		if err != nil {
+			return 0, err
+		}
+		num := new(big.Int).Mul(stableReserve, nativeScale.ToBig())
+		num.Mul(num, big.NewInt(1e8))
+		den := new(big.Int).Mul(nativeReserve, stableScale.ToBig())
+		price := num.Quo(num, den)
+		if !price.IsUint64() || price.Sign() == 0 {
+			continue
+		}
+		return price.Uint64(), nil
+	}
+	return 0, fmt.Errorf("no cached %s/stable pool on chain %d", wrapped.Symbol, chainID)
+}
@@ -0,0 +1,173 @@
+package prices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+var (
+	// ErrNoPrice is returned when no source has priced a chain's native token
+	ErrNoPrice = errors.New("prices: no native price")
+	// ErrStale is returned when the last known price is older than the tracker's max age
+	ErrStale = errors.New("prices: native price is stale")
+)
+
+// nativeDecimals is the decimals of every supported chain's native gas token
+const nativeDecimals = 18
+
+// Source prices a chain's native gas token in USD with 8 decimals
+type Source interface {
+	Name() string
+	NativePriceE8(ctx context.Context, chainID uint64) (uint64, error)
+}
+
+// Quote is a native-token price observation
+type Quote struct {
+	ChainID   uint64    `json:"chainId"`
+	PriceE8   uint64    `json:"priceE8"`
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Tracker keeps the latest native/USD price per chain, trying sources in order
+type Tracker struct {
+	// OnRefresh is called after each chain's price is refreshed
+	OnRefresh func(chainID uint64)
+
+	sources []Source
+	maxAge  time.Duration
+
+	mu     sync.RWMutex
+	quotes map[uint64]Quote
+
+	price *metrics.GaugeVec
+}
+
+// NewTracker creates a tracker; prices older than maxAge are refused
+func NewTracker(maxAge time.Duration, reg *metrics.Registry, sources ...Source) *Tracker {
+	t := &Tracker{sources: sources, maxAge: maxAge, quotes: make(map[uint64]Quote)}
+	if reg != nil {
+		t.price = reg.Gauge("titan_native_price_usd", "Native gas token USD price", "chain")
+	}
+	return t
+}
+
+// Refresh fetches a fresh price for each chain from the first source that answers
+func (t *Tracker) Refresh(ctx context.Context, chainIDs ...uint64) error {
+	var errs []error
+	for _, chainID := range chainIDs {
+		if err := t.refresh(ctx, chainID); err != nil {
+			errs = append(errs, fmt.Errorf("chain %d: %w", chainID, err))
+		} else if t.OnRefresh != nil {
+			t.OnRefresh(chainID)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *Tracker) refresh(ctx context.Context, chainID uint64) error {
+	var errs []error
+	for _, src := range t.sources {
+		price, err := src.NativePriceE8(ctx, chainID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", src.Name(), err))
+			continue
+		}
+		t.Set(Quote{ChainID: chainID, PriceE8: price, Source: src.Name(), UpdatedAt: time.Now()})
+		return nil
+	}
+	if len(errs) == 0 {
+		return ErrNoPrice
+	}
+	return errors.Join(errs...)
+}
+
+// Set records a price observation
+func (t *Tracker) Set(q Quote) {
+	t.mu.Lock()
+	t.quotes[q.ChainID] = q
+	t.mu.Unlock()
+	if t.price != nil {
+		t.price.Set(float64(q.PriceE8)/1e8, strconv.FormatUint(q.ChainID, 10))
+	}
+}
+
+// Run refreshes chainIDs every interval until ctx is cancelled
+func (t *Tracker) Run(ctx context.Context, interval time.Duration, chainIDs ...uint64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := t.Refresh(ctx, chainIDs...); err != nil {
+			log.Printf("⚠️ Native price refresh: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Quote returns the latest observation for a chain, refusing stale prices
+func (t *Tracker) Quote(chainID uint64) (Quote, error) {
+	t.mu.RLock()
+	q, ok := t.quotes[chainID]
+	t.mu.RUnlock()
+	if !ok {
+		return Quote{}, fmt.Errorf("%w for chain %d", ErrNoPrice, chainID)
+	}
+	if t.maxAge > 0 && time.Since(q.UpdatedAt) > t.maxAge {
+		return q, fmt.Errorf("%w for chain %d (%s old)", ErrStale, chainID, time.Since(q.UpdatedAt).Round(time.Second))
+	}
+	return q, nil
+}
+
+// NativeToUSD values an amount of native token (in wei) at the current price
+func (t *Tracker) NativeToUSD(chainID uint64, wei *big.Int) (units.USD, error) {
+	q, err := t.Quote(chainID)
+	if err != nil {
+		return 0, err
+	}
+	amount, err := units.FromBig(common.Address{}, wei, nativeDecimals)
+	if err != nil {
+		return 0, err
+	}
+	return amount.ToUSD(q.PriceE8)
+}
+
+// GasCostUSD converts gasUnits at gasPriceWei into USD on a chain
+func (t *Tracker) GasCostUSD(chainID uint64, gasUnits uint64, gasPriceWei *big.Int) (units.USD, error) {
+	price, overflow := uint256.FromBig(gasPriceWei)
+	if overflow {
+		return 0, units.ErrOverflow
+	}
+	wei, overflow := new(uint256.Int).MulOverflow(price, uint256.NewInt(gasUnits))
+	if overflow {
+		return 0, units.ErrOverflow
+	}
+	return t.NativeToUSD(chainID, wei.ToBig())
+}
+
+// Quotes returns the latest observation for every tracked chain
+func (t *Tracker) Quotes() []Quote {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	out := make([]Quote, 0, len(t.quotes))
+	for _, q := range t.quotes {
+		out = append(out, q)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ChainID < out[j].ChainID })
+	return out
+}
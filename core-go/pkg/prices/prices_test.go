@@ -0,0 +1,117 @@
+package prices
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+func TestChainlinkSourceNormalizesDecimals(t *testing.T) {
+	backend := titantest.NewBackend(137)
+	feed := titantest.Address(0xfeed)
+	backend.Handle(feed, "decimals()", func(ethereum.CallMsg) ([]byte, error) {
+		return titantest.EncodeUint(big.NewInt(18)), nil
+	})
+	backend.Handle(feed, "latestRoundData()", func(ethereum.CallMsg) ([]byte, error) {
+		var out []byte
+		out = append(out, titantest.EncodeUint(big.NewInt(1))...)
+		out = append(out, titantest.EncodeUint(titantest.Units(55, 16))...) // $0.55 at 18 decimals
+		out = append(out, titantest.EncodeUint(big.NewInt(time.Now().Unix()))...)
+		out = append(out, titantest.EncodeUint(big.NewInt(time.Now().Unix()))...)
+		out = append(out, titantest.EncodeUint(big.NewInt(1))...)
+		return out, nil
+	})
+
+	src := NewChainlinkSource(map[uint64]common.Address{137: feed}, func(uint64) (ethereum.ContractCaller, error) {
+		return backend, nil
+	})
+	price, err := src.NativePriceE8(context.Background(), 137)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price != 55_000_000 {
+		t.Errorf("Expected 55000000, got %d", price)
+	}
+	if _, err := src.NativePriceE8(context.Background(), 1); err == nil {
+		t.Error("Expected error for chain without feed")
+	}
+}
+
+func TestPoolSourceUsesWrappedNative(t *testing.T) {
+	registry := tokens.Default()
+	wmatic, _ := registry.Lookup(137, "WMATIC")
+	usdc, _ := registry.Lookup(137, "USDC")
+	if err := registry.SetWrappedNative(137, "MATIC", wmatic.Address); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := reserves.NewCache()
+	cache.Put(&reserves.Snapshot{
+		ChainID:   137,
+		Pool:      titantest.Address(1),
+		Token0:    wmatic.Address,
+		Token1:    usdc.Address,
+		Reserve0:  titantest.Units(1_000_000, 18),
+		Reserve1:  titantest.Units(500_000, 6),
+		FetchedAt: time.Now(),
+	})
+
+	price, err := NewPoolSource(cache, registry, time.Hour).NativePriceE8(context.Background(), 137)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price != 50_000_000 {
+		t.Errorf("Expected $0.50 (50000000), got %d", price)
+	}
+}
+
+type failingSource struct{}
+
+func (failingSource) Name() string { return "failing" }
+func (failingSource) NativePriceE8(context.Context, uint64) (uint64, error) {
+	return 0, errors.New("down")
+}
+
+type fixedSource uint64
+
+func (fixedSource) Name() string { return "fixed" }
+func (f fixedSource) NativePriceE8(context.Context, uint64) (uint64, error) {
+	return uint64(f), nil
+}
+
+func TestTrackerGasCostAndFallback(t *testing.T) {
+	tracker := NewTracker(time.Minute, nil, failingSource{}, fixedSource(3000_00000000))
+	if err := tracker.Refresh(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	q, err := tracker.Quote(1)
+	if err != nil || q.Source != "fixed" {
+		t.Fatalf("Expected fallback to fixed source, got %+v (%v)", q, err)
+	}
+
+	// 200k gas at 50 gwei = 0.01 ETH = $30
+	cost, err := tracker.GasCostUSD(1, 200_000, big.NewInt(50_000_000_000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != units.DollarsToUSD(30) {
+		t.Errorf("Expected $30 gas, got %s", cost)
+	}
+
+	tracker.Set(Quote{ChainID: 1, PriceE8: 1, UpdatedAt: time.Now().Add(-time.Hour)})
+	if _, err := tracker.Quote(1); !errors.Is(err, ErrStale) {
+		t.Errorf("Expected ErrStale, got %v", err)
+	}
+	if _, err := tracker.Quote(56); !errors.Is(err, ErrNoPrice) {
+		t.Errorf("Expected ErrNoPrice, got %v", err)
+	}
+}
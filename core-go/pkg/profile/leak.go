@@ -0,0 +1,135 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// Leak is a module whose goroutine count looks unbounded
+type Leak struct {
+	Module  string
+	Count   int
+	Reason  string
+	History []int // counts over the detection window, oldest first
+}
+
+// String describes the leak for alerts
+func (l Leak) String() string {
+	return fmt.Sprintf("module %q has %d goroutines: %s (recent %v)", l.Module, l.Count, l.Reason, l.History)
+}
+
+// LeakDetector samples goroutine counts per module and flags modules that
+// grow on every sample across a window, or pass a hard limit. Loops that
+// resubscribe without cancelling the old subscription show up as steady
+// growth long before they exhaust memory.
+type LeakDetector struct {
+	// Window is how many consecutive increases count as a leak
+	Window int
+	// Limit flags any module with more goroutines than this; 0 disables it
+	Limit int
+
+	history map[string][]int
+	flagged map[string]bool
+	gauge   *metrics.GaugeVec
+	sample  func() (map[string]int, error)
+}
+
+// NewLeakDetector creates a detector; reg may be nil to skip metrics
+func NewLeakDetector(window, limit int, reg *metrics.Registry) *LeakDetector {
+	d := &LeakDetector{
+		Window:  window,
+		Limit:   limit,
+		history: make(map[string][]int),
+		flagged: make(map[string]bool),
+		sample:  Goroutines,
+	}
+	if reg != nil {
+		d.gauge = reg.Gauge("titan_goroutines", "Live goroutines by module label", "module")
+	}
+	return d
+}
+
+// Observe records one sample and returns modules newly flagged as leaking.
+// A flagged module is reported again only after it stops growing.
+func (d *LeakDetector) Observe(counts map[string]int) []Leak {
+	modules := make([]string, 0, len(counts))
+	for module := range counts {
+		modules = append(modules, module)
+	}
+	for module := range d.history {
+		if _, ok := counts[module]; !ok {
+			modules = append(modules, module)
+		}
+	}
+	sort.Strings(modules)
+
+	var leaks []Leak
+	for _, module := range modules {
+		n := counts[module]
+		if d.gauge != nil {
+			d.gauge.Set(float64(n), module)
+		}
+		h := append(d.history[module], n)
+		if len(h) > d.Window+1 {
+			h = h[len(h)-d.Window-1:]
+		}
+		d.history[module] = h
+
+		reason := ""
+		switch {
+		case d.Limit > 0 && n > d.Limit:
+			reason = fmt.Sprintf("over the limit of %d", d.Limit)
+		case module != "" && growing(h, d.Window):
+			// Unlabelled goroutines include net/http's per-connection ones,
+			// which legitimately grow with load
+			reason = fmt.Sprintf("grew on each of the last %d samples", d.Window)
+		}
+		if reason == "" {
+			d.flagged[module] = false
+			continue
+		}
+		if !d.flagged[module] {
+			d.flagged[module] = true
+			leaks = append(leaks, Leak{Module: module, Count: n, Reason: reason, History: append([]int(nil), h...)})
+		}
+	}
+	return leaks
+}
+
+// Run samples every interval until ctx is cancelled, calling notify for
+// each new leak
+func (d *LeakDetector) Run(ctx context.Context, every time.Duration, notify func(Leak)) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		counts, err := d.sample()
+		if err != nil {
+			continue
+		}
+		for _, leak := range d.Observe(counts) {
+			notify(leak)
+		}
+	}
+}
+
+// growing reports whether h holds window strict increases in a row
+func growing(h []int, window int) bool {
+	if window <= 0 || len(h) < window+1 {
+		return false
+	}
+	for i := 1; i < len(h); i++ {
+		if h[i] <= h[i-1] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package profile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	rpprof "runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LabelModule is the pprof label naming the subsystem a goroutine belongs to
+const LabelModule = "module"
+
+// NewRunID returns an ID for this process run, sortable by start time
+func NewRunID() string {
+	b := make([]byte, 3)
+	rand.Read(b)
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
+
+// Go runs fn in a new goroutine labelled with module. Labels are inherited
+// by every goroutine fn starts, so CPU profiles and goroutine counts can be
+// broken down by module.
+func Go(ctx context.Context, module string, fn func(ctx context.Context)) {
+	go rpprof.Do(ctx, rpprof.Labels(LabelModule, module), fn)
+}
+
+// Register mounts the net/http/pprof handlers under /debug/pprof/
+func Register(handle func(pattern string, handler http.HandlerFunc)) {
+	handle("/debug/pprof/", pprof.Index)
+	handle("/debug/pprof/cmdline", pprof.Cmdline)
+	handle("/debug/pprof/profile", pprof.Profile)
+	handle("/debug/pprof/symbol", pprof.Symbol)
+	handle("/debug/pprof/trace", pprof.Trace)
+}
+
+// Goroutines counts live goroutines by module label; unlabelled ones are
+// counted under ""
+func Goroutines() (map[string]int, error) {
+	var buf bytes.Buffer
+	if err := rpprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return nil, err
+	}
+	return parseGoroutines(&buf)
+}
+
+// parseGoroutines reads the debug=1 goroutine profile, where each stack is a
+// "<count> @ <pcs>" line optionally followed by "# labels: {...}"
+func parseGoroutines(r *bytes.Buffer) (map[string]int, error) {
+	counts := make(map[string]int)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	pending, module := 0, ""
+	flush := func() {
+		if pending > 0 {
+			counts[module] += pending
+		}
+		pending, module = 0, ""
+	}
+	for sc.Scan() {
+		line := sc.Text()
+		if n, _, ok := strings.Cut(line, " @ "); ok && !strings.HasPrefix(line, "#") {
+			flush()
+			count, err := strconv.Atoi(n)
+			if err != nil {
+				return nil, fmt.Errorf("profile: bad stack count %q", n)
+			}
+			pending = count
+			continue
+		}
+		if labels, ok := strings.CutPrefix(line, "# labels: "); ok && pending > 0 {
+			module = parseLabels(labels)[LabelModule]
+		}
+	}
+	flush()
+	return counts, sc.Err()
+}
+
+// parseLabels reads {"key":"value", ...}
+func parseLabels(s string) map[string]string {
+	out := make(map[string]string)
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	for _, pair := range strings.Split(s, ", ") {
+		k, v, ok := strings.Cut(pair, ":")
+		if !ok {
+			continue
+		}
+		key, err1 := strconv.Unquote(k)
+		value, err2 := strconv.Unquote(v)
+		if err1 == nil && err2 == nil {
+			out[key] = value
+		}
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package profile
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+func TestGoroutinesByModule(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{}, 3)
+	for i := 0; i < 3; i++ {
+		Go(ctx, "scanner", func(ctx context.Context) {
+			// A child goroutine inherits the label
+			go func() {
+				started <- struct{}{}
+				<-ctx.Done()
+			}()
+			<-ctx.Done()
+		})
+	}
+	for i := 0; i < 3; i++ {
+		<-started
+	}
+	counts, err := Goroutines()
+	cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["scanner"] != 6 {
+		t.Errorf("Expected 6 scanner goroutines, got %v", counts)
+	}
+	if counts[""] == 0 {
+		t.Error("Expected unlabelled goroutines counted under \"\"")
+	}
+}
+
+func TestParseGoroutines(t *testing.T) {
+	profile := `goroutine profile: total 7
+4 @ 0x43e 0x40a
+# labels: {"module":"mevshare", "stream":"hints"}
+#	0x43e	runtime.gopark+0x0
+
+2 @ 0x43e 0x40b
+#	0x43e	runtime.gopark+0x0
+
+1 @ 0x43e 0x40c
+# labels: {"stream":"x"}
+`
+	counts, err := parseGoroutines(bytes.NewBufferString(profile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["mevshare"] != 4 || counts[""] != 3 {
+		t.Errorf("Expected 4 mevshare and 3 unlabelled, got %v", counts)
+	}
+}
+
+func TestLeakDetector(t *testing.T) {
+	reg := metrics.NewRegistry()
+	d := NewLeakDetector(3, 100, reg)
+	samples := []map[string]int{
+		{"cex": 4, "mevshare": 2, "": 10},
+		{"cex": 5, "mevshare": 2, "": 11},
+		{"cex": 6, "mevshare": 2, "": 12},
+		{"cex": 7, "mevshare": 2, "": 13},
+		{"cex": 8, "mevshare": 101, "": 14},
+	}
+	var leaks []Leak
+	for _, s := range samples {
+		leaks = append(leaks, d.Observe(s)...)
+	}
+	if len(leaks) != 2 || leaks[0].Module != "cex" || leaks[1].Module != "mevshare" {
+		t.Fatalf("Expected cex growth then the mevshare limit, got %+v", leaks)
+	}
+	if !strings.Contains(leaks[0].String(), "grew on each of the last 3 samples") {
+		t.Errorf("Unexpected description %q", leaks[0])
+	}
+	if reg.Value("titan_goroutines", "cex") != 8 {
+		t.Errorf("Expected gauge 8, got %v", reg.Value("titan_goroutines", "cex"))
+	}
+
+	// Once the module settles it can be flagged again
+	d.Observe(map[string]int{"cex": 8})
+	if leaks := d.Observe(map[string]int{"cex": 200}); len(leaks) != 1 {
+		t.Errorf("Expected cex flagged again, got %+v", leaks)
+	}
+}
+
+func TestSnapshotterPrunes(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Unix(1_700_000_000, 0)
+	s := NewSnapshotter(dir, "run-1", 2)
+	s.now = func() time.Time { now = now.Add(time.Second); return now }
+	for i := 0; i < 3; i++ {
+		if _, err := s.Snapshot(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "run-1"))
+	if len(entries) != 4 {
+		t.Fatalf("Expected two heap and two goroutine snapshots, got %d", len(entries))
+	}
+	if entries[0].Name() != "goroutine-20231114T221322.000.pb.gz" {
+		t.Errorf("Expected the oldest snapshot pruned, got %s", entries[0].Name())
+	}
+}
+
+func TestRegister(t *testing.T) {
+	mux := http.NewServeMux()
+	Register(func(pattern string, handler http.HandlerFunc) { mux.HandleFunc(pattern, handler) })
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/goroutine?debug=1", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "goroutine profile") {
+		t.Errorf("Expected a goroutine profile, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,107 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	rpprof "runtime/pprof"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshotter periodically writes heap and goroutine profiles to
+// Dir/<run ID>/, so a slow leak can be diffed across hours of one run
+type Snapshotter struct {
+	Dir   string
+	RunID string
+	// Keep is how many snapshots of each kind are retained per run
+	Keep int
+
+	now func() time.Time
+}
+
+// snapshotKinds are the profiles written on every snapshot
+var snapshotKinds = []string{"heap", "goroutine"}
+
+// NewSnapshotter creates a snapshotter writing under dir
+func NewSnapshotter(dir, runID string, keep int) *Snapshotter {
+	return &Snapshotter{Dir: dir, RunID: runID, Keep: keep, now: time.Now}
+}
+
+// Run snapshots every interval until ctx is cancelled
+func (s *Snapshotter) Run(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Snapshot(); err != nil {
+				log.Printf("⚠️ Profile snapshot: %v", err)
+			}
+		}
+	}
+}
+
+// Snapshot writes one profile of each kind and prunes old ones, returning
+// the files written
+func (s *Snapshotter) Snapshot() ([]string, error) {
+	dir := filepath.Join(s.Dir, s.RunID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	stamp := s.now().UTC().Format("20060102T150405.000")
+	var written []string
+	for _, kind := range snapshotKinds {
+		path := filepath.Join(dir, fmt.Sprintf("%s-%s.pb.gz", kind, stamp))
+		if err := writeProfile(kind, path); err != nil {
+			return written, fmt.Errorf("%s: %w", kind, err)
+		}
+		written = append(written, path)
+		if err := s.prune(dir, kind); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func writeProfile(kind, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := rpprof.Lookup(kind).WriteTo(f, 0); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// prune removes all but the newest Keep snapshots of kind; names sort by time
+func (s *Snapshotter) prune(dir, kind string) error {
+	if s.Keep <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), kind+"-") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for len(names) > s.Keep {
+		if err := os.Remove(filepath.Join(dir, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package quotes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/bridge"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// LifiAdapter quotes same-chain swaps through the LiFi aggregator, which
+// returns ready-to-send calldata for the best DEX aggregator it knows
+type LifiAdapter struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewLifiAdapter creates a LiFi swap adapter; apiKey may be empty for public rate limits
+func NewLifiAdapter(baseURL, apiKey string) *LifiAdapter {
+	if baseURL == "" {
+		baseURL = bridge.DefaultLifiURL
+	}
+	return &LifiAdapter{
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name returns "lifi"
+func (a *LifiAdapter) Name() string {
+	return "lifi"
+}
+
+type lifiSwap struct {
+	Tool     string `json:"tool"`
+	Estimate struct {
+		FromAmount string `json:"fromAmount"`
+		ToAmount   string `json:"toAmount"`
+		GasCosts   []struct {
+			Estimate string `json:"estimate"`
+		} `json:"gasCosts"`
+	} `json:"estimate"`
+	TransactionRequest *struct {
+		To    string `json:"to"`
+		Data  string `json:"data"`
+		Value string `json:"value"`
+	} `json:"transactionRequest"`
+}
+
+// Quote requests GET /quote with the same source and destination chain, or
+// GET /quote/toAmount for exact-output requests
+func (a *LifiAdapter) Quote(ctx context.Context, req Request) (*Quote, error) {
+	chain := strconv.FormatUint(req.ChainID, 10)
+	q := url.Values{}
+	q.Set("fromChain", chain)
+	q.Set("toChain", chain)
+	q.Set("fromToken", req.TokenIn.Address.Hex())
+	q.Set("toToken", req.TokenOut.Address.Hex())
+	q.Set("fromAddress", req.Sender.Hex())
+	path := "/quote?"
+	if req.ExactOutput() {
+		path = "/quote/toAmount?"
+		q.Set("toAmount", req.AmountOut.Value.Dec())
+	} else {
+		q.Set("fromAmount", req.AmountIn.Value.Dec())
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseURL+path+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if a.apiKey != "" {
+		httpReq.Header.Set("x-lifi-api-key", a.apiKey)
+	}
+
+	resp, err := a.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("lifi quote failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(body) > 200 {
+			body = append(body[:200:200], "..."...)
+		}
+		return nil, fmt.Errorf("lifi quote failed: HTTP %d: %s", resp.StatusCode, body)
+	}
+
+	var ls lifiSwap
+	if err := json.Unmarshal(body, &ls); err != nil {
+		return nil, fmt.Errorf("lifi quote: invalid response: %w", err)
+	}
+	toAmount, ok := new(big.Int).SetString(ls.Estimate.ToAmount, 10)
+	if !ok {
+		return nil, fmt.Errorf("lifi quote: invalid toAmount %q", ls.Estimate.ToAmount)
+	}
+	out, err := units.FromBig(req.TokenOut.Address, toAmount, req.TokenOut.Decimals)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &Quote{
+		Kind:      KindAggregator,
+		Venue:     ls.Tool,
+		AmountOut: out,
+		QuotedAt:  time.Now(),
+	}
+	if req.ExactOutput() {
+		fromAmount, ok := new(big.Int).SetString(ls.Estimate.FromAmount, 10)
+		if !ok {
+			return nil, fmt.Errorf("lifi quote: invalid fromAmount %q", ls.Estimate.FromAmount)
+		}
+		in, err := units.FromBig(req.TokenIn.Address, fromAmount, req.TokenIn.Decimals)
+		if err != nil {
+			return nil, err
+		}
+		quote.AmountIn = &in
+	}
+	for _, g := range ls.Estimate.GasCosts {
+		if n, err := strconv.ParseUint(g.Estimate, 10, 64); err == nil {
+			quote.GasUnits += n
+		}
+	}
+	if tr := ls.TransactionRequest; tr != nil && common.IsHexAddress(tr.To) {
+		data, err := hexutil.Decode(tr.Data)
+		if err != nil {
+			return nil, fmt.Errorf("lifi quote: invalid calldata: %w", err)
+		}
+		value := "0"
+		if tr.Value != "" {
+			v, ok := new(big.Int).SetString(strings.TrimPrefix(tr.Value, "0x"), 16)
+			if !ok {
+				return nil, fmt.Errorf("lifi quote: invalid value %q", tr.Value)
+			}
+			value = v.String()
+		}
+		quote.Tx = &Tx{To: common.HexToAddress(tr.To), Data: data, Value: value}
+	}
+	return quote, nil
+}
@@ -0,0 +1,109 @@
+package quotes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/dex"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/route"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// PoolAdapter quotes a swap against one router's pools from live reserves
+type PoolAdapter struct {
+	discovery *dex.Discovery
+	router    dex.Router
+
+	// Gas prices the swap, used to break ties between equal outputs; nil
+	// uses typical per-adapter constants
+	Gas *gas.Model
+}
+
+// NewPoolAdapter creates an adapter for router using discovery's chain
+func NewPoolAdapter(discovery *dex.Discovery, router dex.Router) *PoolAdapter {
+	return &PoolAdapter{discovery: discovery, router: router}
+}
+
+// PoolAdapters creates one adapter per router
+func PoolAdapters(discovery *dex.Discovery, routers []dex.Router) []Adapter {
+	out := make([]Adapter, len(routers))
+	for i, r := range routers {
+		out[i] = NewPoolAdapter(discovery, r)
+	}
+	return out
+}
+
+// Name returns the router name
+func (a *PoolAdapter) Name() string {
+	return a.router.Name
+}
+
+// Quote discovers the router's pools for the pair and returns the one paying
+// the most, or for exact-output requests the one charging the least; for V3
+// routers every fee tier competes
+func (a *PoolAdapter) Quote(ctx context.Context, req Request) (*Quote, error) {
+	snapshots, errs := a.discovery.DiscoverPair(ctx, []dex.Router{a.router}, req.TokenIn.Address, req.TokenOut.Address)
+	if len(snapshots) == 0 {
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+		return nil, fmt.Errorf("no %s pool for %s/%s", a.router.Name, req.TokenIn.Symbol, req.TokenOut.Symbol)
+	}
+
+	var best *reserves.Snapshot
+	var bestIn *units.Amount
+	bestOut := req.AmountOut
+	for _, s := range snapshots {
+		zeroForOne := s.ZeroForOne(req.TokenIn.Address)
+		if req.ExactOutput() {
+			raw, err := s.AMM().QuoteIn(req.AmountOut.Big(), zeroForOne)
+			if err != nil {
+				continue
+			}
+			in, err := units.FromBig(req.TokenIn.Address, raw, req.TokenIn.Decimals)
+			if err != nil {
+				continue
+			}
+			if best == nil || in.Cmp(*bestIn) < 0 {
+				best, bestIn = s, &in
+			}
+			continue
+		}
+		raw, err := s.AMM().Quote(req.AmountIn.Big(), zeroForOne)
+		if err != nil {
+			continue
+		}
+		out, err := units.FromBig(req.TokenOut.Address, raw, req.TokenOut.Decimals)
+		if err != nil {
+			continue
+		}
+		if best == nil || out.Cmp(bestOut) > 0 {
+			best, bestOut = s, out
+		}
+	}
+	if best == nil && req.ExactOutput() {
+		return nil, fmt.Errorf("no %s pool can deliver %s %s", a.router.Name, req.AmountOut, req.TokenOut.Symbol)
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no %s pool can fill %s %s", a.router.Name, req.AmountIn, req.TokenIn.Symbol)
+	}
+
+	adapter := route.AdapterUniV2
+	if best.Kind == reserves.KindV3 {
+		adapter = route.AdapterUniV3
+	}
+	return &Quote{
+		Kind:      KindPool,
+		Venue:     best.Dex,
+		Pool:      best.Pool,
+		AmountIn:  bestIn,
+		AmountOut: bestOut,
+		FeeBps:    best.FeeBps,
+		GasUnits:  a.Gas.Estimate(req.ChainID, adapter.Name()),
+		Block:     best.Block,
+		QuotedAt:  time.Now(),
+	}, nil
+}
@@ -0,0 +1,216 @@
+package quotes
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/freshness"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// DefaultTimeout bounds each adapter's quote
+const DefaultTimeout = 2 * time.Second
+
+var (
+	// ErrNoQuote is returned when no adapter produced an executable quote
+	ErrNoQuote = errors.New("quotes: no executable quote")
+	// ErrNoInput fails an exact-output quote that doesn't say what it costs
+	ErrNoInput = errors.New("quotes: no input amount for an exact-output swap")
+)
+
+// Quote kinds
+const (
+	KindPool       = "pool"       // a single pool swap quoted from on-chain reserves
+	KindAggregator = "aggregator" // an off-chain aggregator route with calldata
+)
+
+// Request describes a same-chain swap to quote. Setting AmountOut asks for
+// an exact-output swap instead: what it costs to end with exactly AmountOut,
+// as when a flash loan must be repaid to the unit or inventory topped up to a
+// target; AmountIn is then ignored.
+type Request struct {
+	ChainID   uint64
+	TokenIn   tokens.Token
+	TokenOut  tokens.Token
+	AmountIn  units.Amount
+	AmountOut units.Amount
+	Sender    common.Address // aggregators build calldata for this address
+}
+
+// ExactOutput reports whether the request fixes the output rather than the input
+func (r Request) ExactOutput() bool {
+	return !r.AmountOut.IsZero()
+}
+
+// Tx is the transaction an aggregator quote executes through
+type Tx struct {
+	To    common.Address `json:"to"`
+	Data  []byte         `json:"data"`
+	Value string         `json:"value,omitempty"` // wei, decimal
+}
+
+// Quote is one adapter's offer for a Request, with where it came from
+type Quote struct {
+	Adapter   string         `json:"adapter"`
+	Kind      string         `json:"kind"`
+	Venue     string         `json:"venue"`              // DEX or aggregator tool that fills the swap
+	Pool      common.Address `json:"pool,omitempty"`     // set for pool quotes
+	AmountIn  *units.Amount  `json:"amountIn,omitempty"` // input needed, set for exact-output quotes
+	AmountOut units.Amount   `json:"amountOut"`
+	FeeBps    uint32         `json:"feeBps"`
+	GasUnits  uint64         `json:"gasUnits,omitempty"`
+	Block     uint64         `json:"block,omitempty"` // state the quote was computed against, 0 if unknown
+	QuotedAt  time.Time      `json:"quotedAt"`
+	Tx        *Tx            `json:"tx,omitempty"` // set for aggregator quotes
+}
+
+// Executable reports whether the quote can be turned into a transaction: it
+// has output and either names a pool or carries aggregator calldata
+func (q *Quote) Executable() bool {
+	if q == nil || q.AmountOut.IsZero() {
+		return false
+	}
+	switch q.Kind {
+	case KindPool:
+		return q.Pool != (common.Address{})
+	case KindAggregator:
+		return q.Tx != nil && q.Tx.To != (common.Address{}) && len(q.Tx.Data) > 0
+	}
+	return false
+}
+
+// Adapter quotes swaps on one DEX or aggregator
+type Adapter interface {
+	Name() string
+	Quote(ctx context.Context, req Request) (*Quote, error)
+}
+
+// Result pairs an adapter with its quote or error and how long it took
+type Result struct {
+	Adapter string
+	Quote   *Quote
+	Err     error
+	Latency time.Duration
+}
+
+// Service fans quote requests out to every registered adapter
+type Service struct {
+	mu       sync.RWMutex
+	adapters []Adapter
+	timeout  time.Duration
+
+	// Freshness fails quotes outside the chain's window, counting blocks
+	// behind the freshest quote; nil accepts every quote
+	Freshness *freshness.Policy
+}
+
+// NewService creates a service bounding each adapter by timeout (DefaultTimeout if zero)
+func NewService(timeout time.Duration, adapters ...Adapter) *Service {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Service{adapters: adapters, timeout: timeout}
+}
+
+// Register adds an adapter
+func (s *Service) Register(a Adapter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.adapters = append(s.adapters, a)
+}
+
+// Adapters returns the registered adapters
+func (s *Service) Adapters() []Adapter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Adapter(nil), s.adapters...)
+}
+
+// QuoteAll queries every adapter concurrently. Executable quotes come first,
+// best output first, or cheapest input first for exact-output requests, with
+// fewer gas units breaking ties; failures come last.
+func (s *Service) QuoteAll(ctx context.Context, req Request) []Result {
+	adapters := s.Adapters()
+	results := make([]Result, len(adapters))
+	var wg sync.WaitGroup
+	for i, a := range adapters {
+		wg.Add(1)
+		go func(i int, a Adapter) {
+			defer wg.Done()
+			qctx, cancel := context.WithTimeout(ctx, s.timeout)
+			defer cancel()
+			start := time.Now()
+			q, err := a.Quote(qctx, req)
+			if err == nil && q != nil {
+				q.Adapter = a.Name()
+				if q.QuotedAt.IsZero() {
+					q.QuotedAt = time.Now()
+				}
+				if req.ExactOutput() && (q.AmountIn == nil || q.AmountIn.IsZero()) {
+					err = ErrNoInput
+				}
+			}
+			results[i] = Result{Adapter: a.Name(), Quote: q, Err: err, Latency: time.Since(start)}
+		}(i, a)
+	}
+	wg.Wait()
+	s.checkFreshness(req.ChainID, results)
+
+	sort.SliceStable(results, func(i, j int) bool {
+		qi, qj := results[i].usable(), results[j].usable()
+		if qi == nil || qj == nil {
+			return qj == nil && qi != nil
+		}
+		if req.ExactOutput() {
+			if c := qi.AmountIn.Cmp(*qj.AmountIn); c != 0 {
+				return c < 0
+			}
+		} else if c := qi.AmountOut.Cmp(qj.AmountOut); c != 0 {
+			return c > 0
+		}
+		return qi.GasUnits < qj.GasUnits
+	})
+	return results
+}
+
+// Best returns the best executable quote alongside every adapter's result
+func (s *Service) Best(ctx context.Context, req Request) (*Quote, []Result, error) {
+	results := s.QuoteAll(ctx, req)
+	if len(results) == 0 || results[0].usable() == nil {
+		return nil, results, ErrNoQuote
+	}
+	return results[0].Quote, results, nil
+}
+
+// checkFreshness fails every result whose quote is outside the chain's
+// window, measuring block lag against the freshest quote's block
+func (s *Service) checkFreshness(chainID uint64, results []Result) {
+	if s.Freshness == nil {
+		return
+	}
+	var head uint64
+	for _, r := range results {
+		if r.Err == nil && r.Quote != nil && r.Quote.Block > head {
+			head = r.Quote.Block
+		}
+	}
+	for i, r := range results {
+		if r.Err != nil || r.Quote == nil {
+			continue
+		}
+		results[i].Err = s.Freshness.Check("quote", chainID, r.Quote.QuotedAt, r.Quote.Block, head)
+	}
+}
+
+// usable returns the quote when it succeeded and is executable
+func (r Result) usable() *Quote {
+	if r.Err != nil || !r.Quote.Executable() {
+		return nil
+	}
+	return r.Quote
+}
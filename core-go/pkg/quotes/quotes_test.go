@@ -0,0 +1,262 @@
+package quotes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/dex"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/freshness"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// fixedAdapter returns a canned quote or error, optionally after a delay
+type fixedAdapter struct {
+	name  string
+	quote *Quote
+	err   error
+	delay time.Duration
+}
+
+func (a *fixedAdapter) Name() string { return a.name }
+
+func (a *fixedAdapter) Quote(ctx context.Context, req Request) (*Quote, error) {
+	select {
+	case <-time.After(a.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if a.err != nil {
+		return nil, a.err
+	}
+	q := *a.quote
+	return &q, nil
+}
+
+func poolQuote(t *testing.T, out uint64, gas uint64) *Quote {
+	amount, err := units.FromWhole(common.Address{}, out, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Quote{Kind: KindPool, Pool: titantest.Address(out), AmountOut: amount, GasUnits: gas}
+}
+
+func TestBestSelectsHighestExecutableQuote(t *testing.T) {
+	svc := NewService(50*time.Millisecond,
+		&fixedAdapter{name: "slow", quote: poolQuote(t, 200, 100_000), delay: time.Second},
+		&fixedAdapter{name: "broken", err: errors.New("boom")},
+		&fixedAdapter{name: "good", quote: poolQuote(t, 100, 150_000)},
+		&fixedAdapter{name: "cheaper", quote: poolQuote(t, 100, 110_000)},
+		&fixedAdapter{name: "no-calldata", quote: &Quote{Kind: KindAggregator, AmountOut: poolQuote(t, 150, 0).AmountOut}},
+	)
+
+	best, results, err := svc.Best(context.Background(), Request{ChainID: 137})
+	if err != nil {
+		t.Fatalf("Best failed: %v", err)
+	}
+	if best.Adapter != "cheaper" {
+		t.Errorf("Expected cheaper to win the tie on gas, got %s", best.Adapter)
+	}
+	if best.QuotedAt.IsZero() {
+		t.Error("Expected quote time to be recorded")
+	}
+	if len(results) != 5 || results[1].Adapter != "good" {
+		t.Fatalf("Expected good second of 5 results, got %+v", results)
+	}
+	for _, r := range results[2:] {
+		if r.usable() != nil {
+			t.Errorf("Expected %s to rank after executable quotes", r.Adapter)
+		}
+		if r.Adapter == "slow" && !errors.Is(r.Err, context.DeadlineExceeded) {
+			t.Errorf("Expected slow adapter to time out, got %v", r.Err)
+		}
+	}
+}
+
+func TestBestWithoutExecutableQuote(t *testing.T) {
+	svc := NewService(0, &fixedAdapter{name: "broken", err: errors.New("boom")})
+	if _, _, err := svc.Best(context.Background(), Request{}); !errors.Is(err, ErrNoQuote) {
+		t.Errorf("Expected ErrNoQuote, got %v", err)
+	}
+}
+
+func TestPoolAdapterQuotesFromReserves(t *testing.T) {
+	chain := titantest.NewChain(137)
+	weth := chain.Token(18)
+	usdc := chain.Token(6)
+	pool := chain.Pool(weth, usdc, titantest.Units(1_000, 18), titantest.Units(3_000_000, 6))
+
+	router, factory := titantest.Address(0xa1), titantest.Address(0xf1)
+	chain.Backend.Handle(router, "factory()", func(ethereum.CallMsg) ([]byte, error) {
+		return titantest.EncodeAddress(factory), nil
+	})
+	chain.Backend.Handle(factory, "getPair(address,address)", func(ethereum.CallMsg) ([]byte, error) {
+		return titantest.EncodeAddress(pool.Address), nil
+	})
+
+	adapter := NewPoolAdapter(dex.NewDiscovery(137, chain.Backend),
+		dex.Router{Name: "QUICKSWAP", Address: router, Kind: reserves.KindV2})
+	amountIn, _ := units.FromBig(weth, titantest.Units(1, 18), 18)
+	q, err := adapter.Quote(context.Background(), Request{
+		ChainID:  137,
+		TokenIn:  tokens.Token{ChainID: 137, Symbol: "WETH", Address: weth, Decimals: 18},
+		TokenOut: tokens.Token{ChainID: 137, Symbol: "USDC", Address: usdc, Decimals: 6},
+		AmountIn: amountIn,
+	})
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	if !q.Executable() || q.Pool != pool.Address || q.Venue != "QUICKSWAP" {
+		t.Errorf("Unexpected provenance %+v", q)
+	}
+	// 1 WETH into 1000 WETH / 3M USDC: ~2988 USDC after fee and slippage
+	if f := q.AmountOut.Float(); f < 2980 || f > 2995 {
+		t.Errorf("Expected ~2988 USDC, got %s", q.AmountOut)
+	}
+}
+
+func TestPoolAdapterQuotesExactOutput(t *testing.T) {
+	chain := titantest.NewChain(137)
+	weth := chain.Token(18)
+	usdc := chain.Token(6)
+	pool := chain.Pool(weth, usdc, titantest.Units(1_000, 18), titantest.Units(3_000_000, 6))
+
+	router, factory := titantest.Address(0xa1), titantest.Address(0xf1)
+	chain.Backend.Handle(router, "factory()", func(ethereum.CallMsg) ([]byte, error) {
+		return titantest.EncodeAddress(factory), nil
+	})
+	chain.Backend.Handle(factory, "getPair(address,address)", func(ethereum.CallMsg) ([]byte, error) {
+		return titantest.EncodeAddress(pool.Address), nil
+	})
+
+	adapter := NewPoolAdapter(dex.NewDiscovery(137, chain.Backend),
+		dex.Router{Name: "QUICKSWAP", Address: router, Kind: reserves.KindV2})
+	amountOut, _ := units.FromWhole(usdc, 3_000, 6)
+	q, err := adapter.Quote(context.Background(), Request{
+		ChainID:   137,
+		TokenIn:   tokens.Token{ChainID: 137, Symbol: "WETH", Address: weth, Decimals: 18},
+		TokenOut:  tokens.Token{ChainID: 137, Symbol: "USDC", Address: usdc, Decimals: 6},
+		AmountOut: amountOut,
+	})
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	if q.AmountOut.Cmp(amountOut) != 0 || q.AmountIn == nil {
+		t.Fatalf("Expected the exact output and the input it costs, got %+v", q)
+	}
+	// exactly 3000 USDC out of 1000 WETH / 3M USDC: ~1.0040 WETH after fee and slippage
+	if f := q.AmountIn.Float(); f < 1.003 || f > 1.005 {
+		t.Errorf("Expected ~1.004 WETH, got %s", q.AmountIn)
+	}
+}
+
+func TestBestExactOutputPrefersCheapestInput(t *testing.T) {
+	withInput := func(out, in uint64) *Quote {
+		q := poolQuote(t, out, 100_000)
+		amount, _ := units.FromWhole(common.Address{}, in, 18)
+		q.AmountIn = &amount
+		return q
+	}
+	svc := NewService(0,
+		&fixedAdapter{name: "dear", quote: withInput(100, 3)},
+		&fixedAdapter{name: "cheap", quote: withInput(100, 2)},
+		&fixedAdapter{name: "forward-only", quote: poolQuote(t, 500, 100_000)},
+	)
+	amountOut, _ := units.FromWhole(common.Address{}, 100, 6)
+	best, results, err := svc.Best(context.Background(), Request{ChainID: 137, AmountOut: amountOut})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if best.Adapter != "cheap" || results[1].Adapter != "dear" {
+		t.Errorf("Expected the cheapest input first, got %+v", results)
+	}
+	if !errors.Is(results[2].Err, ErrNoInput) {
+		t.Errorf("Expected a quote without an input to fail, got %v", results[2].Err)
+	}
+}
+
+func TestLifiAdapterSwapQuote(t *testing.T) {
+	router := "0x1231DEB6f5749EF6cE6943a275A1D3E7486F4EaE"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fromChain") != "137" || r.URL.Query().Get("toChain") != "137" {
+			t.Errorf("Expected same-chain request, got %s", r.URL.RawQuery)
+		}
+		fmt.Fprintf(w, `{"tool":"1inch","estimate":{"toAmount":"2990000000","gasCosts":[{"estimate":"180000"}]},
+			"transactionRequest":{"to":%q,"data":"0xdeadbeef","value":"0x00"}}`, router)
+	}))
+	defer srv.Close()
+
+	amountIn, _ := units.FromWhole(common.Address{}, 1, 18)
+	q, err := NewLifiAdapter(srv.URL, "").Quote(context.Background(), Request{
+		ChainID:  137,
+		TokenOut: tokens.Token{Decimals: 6},
+		AmountIn: amountIn,
+	})
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	if !q.Executable() || q.Venue != "1inch" || q.GasUnits != 180000 {
+		t.Errorf("Unexpected quote %+v", q)
+	}
+	if q.Tx.To != common.HexToAddress(router) || q.Tx.Value != "0" || len(q.Tx.Data) != 4 {
+		t.Errorf("Unexpected transaction %+v", q.Tx)
+	}
+	if q.AmountOut.String() != "2990" {
+		t.Errorf("Expected 2990, got %s", q.AmountOut)
+	}
+}
+
+func TestLifiAdapterExactOutputQuote(t *testing.T) {
+	router := "0x1231DEB6f5749EF6cE6943a275A1D3E7486F4EaE"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/quote/toAmount" || r.URL.Query().Get("toAmount") != "3000000000" || r.URL.Query().Has("fromAmount") {
+			t.Errorf("Expected a toAmount request, got %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		fmt.Fprintf(w, `{"tool":"1inch","estimate":{"fromAmount":"1004000000000000000","toAmount":"3000000000"},
+			"transactionRequest":{"to":%q,"data":"0xdeadbeef"}}`, router)
+	}))
+	defer srv.Close()
+
+	amountOut, _ := units.FromWhole(common.Address{}, 3000, 6)
+	q, err := NewLifiAdapter(srv.URL, "").Quote(context.Background(), Request{
+		ChainID:   137,
+		TokenIn:   tokens.Token{Decimals: 18},
+		TokenOut:  tokens.Token{Decimals: 6},
+		AmountOut: amountOut,
+	})
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	if q.AmountIn == nil || q.AmountIn.String() != "1.004" || q.AmountOut.String() != "3000" {
+		t.Errorf("Expected 1.004 in for 3000 out, got %+v", q)
+	}
+}
+
+func TestQuoteAllRejectsStaleQuotes(t *testing.T) {
+	lagging := poolQuote(t, 200, 100_000)
+	lagging.Block = 90
+	current := poolQuote(t, 100, 100_000)
+	current.Block = 100
+	svc := NewService(0, &fixedAdapter{name: "lagging", quote: lagging}, &fixedAdapter{name: "current", quote: current})
+	svc.Freshness = freshness.New(map[uint64]freshness.Window{137: {MaxBlocks: 2}}, nil)
+
+	best, results, err := svc.Best(context.Background(), Request{ChainID: 137})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if best.Adapter != "current" {
+		t.Errorf("Expected the current quote to win over a better stale one, got %s", best.Adapter)
+	}
+	if !errors.Is(results[1].Err, freshness.ErrStale) {
+		t.Errorf("Expected the lagging quote marked stale, got %v", results[1].Err)
+	}
+}
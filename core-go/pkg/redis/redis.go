@@ -0,0 +1,224 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNil is returned for a nil reply, such as GET of a missing key
+var ErrNil = errors.New("redis: nil reply")
+
+// Error is an error reply from the server
+type Error string
+
+func (e Error) Error() string { return "redis: " + string(e) }
+
+// Client is a minimal RESP client over one connection, enough for streams,
+// locks and counters. Commands are serialised; it reconnects after a
+// network error on the next command.
+type Client struct {
+	addr     string
+	password string
+	db       int
+
+	// Timeout bounds dialing and each command
+	Timeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// Dial parses a redis://[:password@]host[:port][/db] URL; the connection is
+// opened on first use
+func Dial(rawURL string) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "redis" {
+		return nil, fmt.Errorf("redis: unsupported scheme %q", u.Scheme)
+	}
+	c := &Client{addr: u.Host, Timeout: 5 * time.Second}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.password, _ = u.User.Password()
+		if c.password == "" {
+			c.password = u.User.Username()
+		}
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("redis: bad database %q", db)
+		}
+	}
+	return c, nil
+}
+
+// Do sends one command and returns its reply: string, int64, []interface{}
+// or nil for a nil bulk string or array
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		if err := c.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+	reply, err := c.roundTrip(ctx, args)
+	if err != nil {
+		var replyErr Error
+		if !errors.As(err, &replyErr) {
+			c.closeLocked()
+		}
+	}
+	return reply, err
+}
+
+// String runs a command whose reply is a string, returning ErrNil for nil
+func (c *Client) String(ctx context.Context, args ...string) (string, error) {
+	reply, err := c.Do(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+	switch v := reply.(type) {
+	case nil:
+		return "", ErrNil
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	}
+	return "", fmt.Errorf("redis: unexpected %T reply", reply)
+}
+
+// Int runs a command whose reply is an integer
+func (c *Client) Int(ctx context.Context, args ...string) (int64, error) {
+	reply, err := c.Do(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("redis: unexpected %T reply", reply)
+	}
+	return n, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closeLocked()
+}
+
+func (c *Client) closeLocked() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.r = nil, nil
+	return err
+}
+
+func (c *Client) connect(ctx context.Context) error {
+	d := net.Dialer{Timeout: c.Timeout}
+	conn, err := d.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	c.conn, c.r = conn, bufio.NewReader(conn)
+	if c.password != "" {
+		if _, err := c.roundTrip(ctx, []string{"AUTH", c.password}); err != nil {
+			c.closeLocked()
+			return err
+		}
+	}
+	if c.db != 0 {
+		if _, err := c.roundTrip(ctx, []string{"SELECT", strconv.Itoa(c.db)}); err != nil {
+			c.closeLocked()
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) roundTrip(ctx context.Context, args []string) (interface{}, error) {
+	deadline := time.Now().Add(c.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.conn.SetDeadline(deadline)
+	if _, err := c.conn.Write(encode(args)); err != nil {
+		return nil, err
+	}
+	return readReply(c.r)
+}
+
+// encode writes args as a RESP array of bulk strings
+func encode(args []string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	return []byte(b.String())
+}
+
+// readReply parses one RESP2 reply
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("redis: empty reply line")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		out := make([]interface{}, n)
+		for i := range out {
+			if out[i], err = readReply(r); err != nil {
+				var replyErr Error
+				if !errors.As(err, &replyErr) {
+					return nil, err
+				}
+				out[i] = err
+			}
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("redis: unknown reply type %q", line[0])
+}
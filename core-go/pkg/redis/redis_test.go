@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+)
+
+func TestClientCommands(t *testing.T) {
+	srv, err := titantest.NewRedis()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	srv.Password = "hunter2"
+
+	ctx := context.Background()
+	bad, _ := Dial(srv.URL())
+	if _, err := bad.Do(ctx, "PING"); err == nil {
+		t.Error("Expected NOAUTH without a password")
+	}
+
+	c, err := Dial("redis://:hunter2@" + srv.URL()[len("redis://"):] + "/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.String(ctx, "GET", "missing"); !errors.Is(err, ErrNil) {
+		t.Errorf("Expected ErrNil, got %v", err)
+	}
+	if ok, err := c.String(ctx, "SET", "k", "v", "NX", "PX", "1000"); err != nil || ok != "OK" {
+		t.Errorf("Expected OK, got %q, %v", ok, err)
+	}
+	if _, err := c.String(ctx, "SET", "k", "w", "NX"); !errors.Is(err, ErrNil) {
+		t.Errorf("Expected NX to refuse an existing key, got %v", err)
+	}
+	if v, _ := c.String(ctx, "GET", "k"); v != "v" {
+		t.Errorf("Expected v, got %q", v)
+	}
+	var replyErr Error
+	if _, err := c.Do(ctx, "NOPE"); !errors.As(err, &replyErr) {
+		t.Errorf("Expected an error reply, got %v", err)
+	}
+	if n, err := c.Int(ctx, "DEL", "k"); err != nil || n != 1 {
+		t.Errorf("Expected one key deleted, got %d, %v", n, err)
+	}
+	if cmds := srv.Commands(); cmds[0] != "SELECT" {
+		t.Errorf("Expected SELECT after AUTH, got %v", cmds)
+	}
+}
+
+func TestDialRejectsBadURLs(t *testing.T) {
+	for _, u := range []string{"http://localhost", "redis://localhost/x"} {
+		if _, err := Dial(u); err == nil {
+			t.Errorf("Expected %s to fail", u)
+		}
+	}
+	c, err := Dial("redis://cache")
+	if err != nil || c.addr != "cache:6379" {
+		t.Errorf("Expected default port, got %+v, %v", c, err)
+	}
+}
@@ -0,0 +1,51 @@
+package report
+
+import (
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/experiment"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/models"
+)
+
+// Backfilled is a journaled opportunity with its features recomputed under
+// the current schema and the score each model would have given it
+type Backfilled struct {
+	Labeled
+	Features map[string]float64 `json:"features"`
+	Scores   map[string]float64 `json:"scores"` // by model, registry ones as name@version
+}
+
+// Backfill recomputes the features of every opportunity journaled at or after
+// since, scores them with the built-in models and each one registry serves,
+// which may be nil, and labels them with their executions, so models trained
+// after a feature-schema change see the journal's full depth. Signals such as
+// news can't be recomputed after the fact: those stored on a breakdown are
+// kept unless the opportunity itself now yields a feature of the same name.
+func Backfill(entries []journal.Entry, since time.Time, registry *models.Registry) ([]Backfilled, error) {
+	labels, opportunities, err := label(entries, since, false)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Backfilled, len(labels))
+	for i, o := range opportunities {
+		features := make(map[string]float64)
+		if bd := o.Explanation.Breakdown; bd != nil {
+			for name, v := range bd.Features {
+				features[name] = v
+			}
+		}
+		for name, v := range experiment.Features(o) {
+			features[name] = v
+		}
+		scores := make(map[string]float64)
+		for name, model := range experiment.Models {
+			scores[name] = model.Score(o)
+		}
+		for _, m := range registry.Models() {
+			scores[m.Name+"@"+m.Version] = m.Score(features)
+		}
+		out[i] = Backfilled{Labeled: labels[i], Features: features, Scores: scores}
+	}
+	return out, nil
+}
@@ -0,0 +1,109 @@
+package report
+
+import (
+	"math"
+	"sort"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/scoring"
+)
+
+// Confidence is bucketed in tenths; a bucket whose realized success rate is
+// off its mean confidence by more than calibrationGap over at least
+// calibrationSamples executions is flagged in reports
+const (
+	calibrationBuckets = 10
+	calibrationGap     = 0.15
+	calibrationSamples = 10
+)
+
+// CalibrationBucket compares the confidence a model gave executed
+// opportunities in one range with how often they succeeded
+type CalibrationBucket struct {
+	Model          string  `json:"model"` // model@version, or the pipeline score
+	Low            float64 `json:"low"`
+	High           float64 `json:"high"`
+	Executions     int     `json:"executions"`
+	Successes      int     `json:"successes"`
+	MeanConfidence float64 `json:"meanConfidence"`
+	SuccessRate    float64 `json:"successRate"`
+	Miscalibrated  bool    `json:"miscalibrated"`
+}
+
+// prediction is one confidence given to an opportunity
+type prediction struct {
+	model      string
+	confidence float64
+}
+
+// predictions returns the confidences recorded on o's explanation: each
+// experiment arm's score and the composite of the scoring pipeline. Scores
+// outside [0, 1], such as raw returns, are not confidences and are skipped.
+func predictions(x *opportunity.Explanation) []prediction {
+	var out []prediction
+	add := func(model string, score float64) {
+		if score >= 0 && score <= 1 {
+			out = append(out, prediction{model, score})
+		}
+	}
+	if x.Trial != nil {
+		for _, ta := range x.Trial.Arms {
+			model := ta.Model
+			if ta.Version != "" {
+				model += "@" + ta.Version
+			}
+			add(model, ta.Score)
+		}
+	}
+	if bd := x.Breakdown; bd != nil && len(bd.Stages) > 0 {
+		add(scoring.ComponentComposite, bd.Score)
+	}
+	return out
+}
+
+// calibration accumulates executions by model and confidence bucket
+type calibration map[string]*[calibrationBuckets]CalibrationBucket
+
+// observe records an execution the model gave confidence to
+func (c calibration) observe(p prediction, success bool) {
+	curve := c[p.model]
+	if curve == nil {
+		curve = new([calibrationBuckets]CalibrationBucket)
+		c[p.model] = curve
+	}
+	i := int(p.confidence * calibrationBuckets)
+	if i == calibrationBuckets {
+		i-- // a confidence of 1 belongs to the top bucket
+	}
+	b := &curve[i]
+	b.Executions++
+	b.MeanConfidence += p.confidence // summed until curves
+	if success {
+		b.Successes++
+	}
+}
+
+// curves returns the non-empty buckets by model then confidence
+func (c calibration) curves() []CalibrationBucket {
+	var out []CalibrationBucket
+	for model, curve := range c {
+		for i, b := range curve {
+			if b.Executions == 0 {
+				continue
+			}
+			b.Model = model
+			b.Low, b.High = float64(i)/calibrationBuckets, float64(i+1)/calibrationBuckets
+			b.MeanConfidence /= float64(b.Executions)
+			b.SuccessRate = float64(b.Successes) / float64(b.Executions)
+			b.Miscalibrated = b.Executions >= calibrationSamples && math.Abs(b.SuccessRate-b.MeanConfidence) > calibrationGap
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Model != out[j].Model {
+			return out[i].Model < out[j].Model
+		}
+		return out[i].Low < out[j].Low
+	})
+	return out
+}
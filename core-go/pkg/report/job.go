@@ -0,0 +1,58 @@
+package report
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/alert"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/seal"
+)
+
+// Job produces daily reports at UTC midnight and weekly reports on Mondays
+type Job struct {
+	JournalPath string
+	Keys        *seal.Keyring // opens a sealed journal
+	OutDir      string
+	Alerts      *alert.Dispatcher
+}
+
+// Run blocks until ctx is cancelled
+func (j *Job) Run(ctx context.Context) {
+	for {
+		now := time.Now().UTC()
+		next := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		j.publish(ctx, PeriodDaily, next)
+		if next.Weekday() == time.Monday {
+			j.publish(ctx, PeriodWeekly, next)
+		}
+	}
+}
+
+// publish generates, stores and delivers one report
+func (j *Job) publish(ctx context.Context, period string, end time.Time) {
+	summary, err := Generate(j.JournalPath, j.Keys, period, end)
+	if err != nil {
+		log.Printf("❌ %s report failed: %v", period, err)
+		return
+	}
+	jsonPath, _, err := summary.Save(j.OutDir)
+	if err != nil {
+		log.Printf("❌ Failed to save %s report: %v", period, err)
+		return
+	}
+	log.Printf("✅ %s report written to %s", period, jsonPath)
+	if j.Alerts != nil {
+		if err := j.Alerts.Notify(ctx, summary.Message()); err != nil {
+			log.Printf("⚠️ %s report delivery: %v", period, err)
+		}
+	}
+}
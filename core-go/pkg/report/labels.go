@@ -0,0 +1,83 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Labeled is an opportunity's score breakdown joined with how its execution
+// went, one example for the self-learning loop to retrain on
+type Labeled struct {
+	ID        string                      `json:"id"`
+	Time      time.Time                   `json:"time"`
+	ChainID   uint64                      `json:"chainId"`
+	Strategy  string                      `json:"strategy,omitempty"`
+	Route     string                      `json:"route"`
+	Decision  string                      `json:"decision"`
+	Category  failure.Reason              `json:"category,omitempty"`
+	Breakdown *opportunity.ScoreBreakdown `json:"breakdown"`
+	Executed  bool                        `json:"executed"`
+	Success   bool                        `json:"success"`
+	PnLUSD    units.USD                   `json:"pnlUsd"` // realized, net of gas
+}
+
+// Labels returns every scored opportunity journaled at or after since, oldest
+// first, labeled with its execution; reorged executions don't count
+func Labels(entries []journal.Entry, since time.Time) ([]Labeled, error) {
+	labels, _, err := label(entries, since, true)
+	return labels, err
+}
+
+// label returns the opportunities journaled at or after since, oldest first,
+// each labeled with its execution, along with the opportunities themselves;
+// scored keeps only those with a breakdown
+func label(entries []journal.Entry, since time.Time, scored bool) ([]Labeled, []*opportunity.Opportunity, error) {
+	reversed, err := reversals(entries)
+	if err != nil {
+		return nil, nil, err
+	}
+	var out []Labeled
+	var opportunities []*opportunity.Opportunity
+	index := make(map[string]int)
+	for _, e := range entries {
+		switch e.Kind {
+		case journal.KindOpportunity:
+			if e.Time.Before(since) {
+				continue
+			}
+			var o opportunity.Opportunity
+			if err := json.Unmarshal(e.Data, &o); err != nil {
+				return nil, nil, fmt.Errorf("bad opportunity entry at %s: %w", e.Time, err)
+			}
+			x := o.Explanation
+			if x == nil || (scored && x.Breakdown == nil) {
+				continue
+			}
+			index[o.ID] = len(out)
+			out = append(out, Labeled{
+				ID: o.ID, Time: e.Time, ChainID: o.ChainID, Strategy: o.Strategy, Route: x.Route(),
+				Decision: x.Decision, Category: x.Category, Breakdown: x.Breakdown,
+			})
+			opportunities = append(opportunities, &o)
+
+		case journal.KindExecution:
+			var x opportunity.Execution
+			if err := json.Unmarshal(e.Data, &x); err != nil {
+				return nil, nil, fmt.Errorf("bad execution entry at %s: %w", e.Time, err)
+			}
+			i, ok := index[x.OpportunityID]
+			if !ok || reversed[reversalKey{x.OpportunityID, x.BlockHash}] {
+				continue
+			}
+			out[i].Executed, out[i].Success = true, x.Success
+			out[i].PnLUSD = x.RealizedProfitUSD - x.GasUSD
+		}
+	}
+	return out, opportunities, nil
+}
@@ -0,0 +1,498 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/alert"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/seal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/slippage"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Report periods
+const (
+	PeriodDaily  = "daily"
+	PeriodWeekly = "weekly"
+)
+
+// topN is the number of routes and failure causes listed in a summary
+const topN = 5
+
+// Adapters whose mean slippage error exceeds optimisticBps over at least
+// optimisticSamples fills are flagged in reports
+const (
+	optimisticBps     = 10
+	optimisticSamples = 5
+)
+
+// Duration returns the window length for a period
+func Duration(period string) (time.Duration, error) {
+	switch period {
+	case PeriodDaily:
+		return 24 * time.Hour, nil
+	case PeriodWeekly:
+		return 7 * 24 * time.Hour, nil
+	}
+	return 0, fmt.Errorf("unknown report period %q", period)
+}
+
+// Count is a named tally
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// RouteStat aggregates executions over one route
+type RouteStat struct {
+	Route      string    `json:"route"`
+	Executions int       `json:"executions"`
+	Successes  int       `json:"successes"`
+	PnLUSD     units.USD `json:"pnlUsd"`  // net of gas and the route's one-off costs
+	CostUSD    units.USD `json:"costUsd"` // one-off costs attributed to the route
+}
+
+// ArmStat compares one arm of a scoring experiment: how often it would have
+// accepted, and how the executions of opportunities it accepted went. Each
+// version of a registry model is compared separately.
+type ArmStat struct {
+	Arm        string    `json:"arm"`
+	Model      string    `json:"model"`
+	Version    string    `json:"version,omitempty"`
+	Scored     int       `json:"scored"`
+	Accepted   int       `json:"accepted"`
+	Decided    int       `json:"decided"` // opportunities this arm's decision was used for
+	Executions int       `json:"executions"`
+	Successes  int       `json:"successes"`
+	HitRate    float64   `json:"hitRate"` // successes / executions
+	PnLUSD     units.USD `json:"pnlUsd"`  // net of gas
+}
+
+// Summary is a performance report over one window
+type Summary struct {
+	Period            string      `json:"period"`
+	From              time.Time   `json:"from"`
+	To                time.Time   `json:"to"`
+	GeneratedAt       time.Time   `json:"generatedAt"`
+	OpportunitiesSeen int         `json:"opportunitiesSeen"`
+	Approved          int         `json:"approved"`
+	Executions        int         `json:"executions"`
+	Successes         int         `json:"successes"`
+	HitRate           float64     `json:"hitRate"`  // successes / executions
+	PnLUSD            units.USD   `json:"pnlUsd"`   // realized, net of gas and one-off costs
+	Reversed          int         `json:"reversed"` // executions dropped after a reorg
+	GasUSD            units.USD   `json:"gasUsd"`
+	CostsUSD          units.USD   `json:"costsUsd"` // approvals, bridging and deployments
+	TopRoutes         []RouteStat `json:"topRoutes"`
+	TopFailures       []Count     `json:"topFailures"`
+	TopRejections     []Count     `json:"topRejections"`
+
+	// Every failure and rejection by canonical reason, for comparison across periods
+	FailureCategories   []Count `json:"failureCategories"`
+	RejectionCategories []Count `json:"rejectionCategories"`
+
+	// StageRejections counts rejections by the scoring stage that made them
+	StageRejections []Count `json:"stageRejections,omitempty"`
+
+	Slippage []slippage.Stat `json:"slippage"` // realized vs predicted, per DEX and pool kind
+
+	// Experiment compares scoring models on the same traffic; in shadow mode
+	// the candidate's executions are those the control also accepted
+	Experiment []ArmStat `json:"experiment,omitempty"`
+
+	// Calibration compares each model's confidence with the realized success
+	// rate of the executions it scored
+	Calibration []CalibrationBucket `json:"calibration,omitempty"`
+}
+
+// Build summarizes journal entries whose timestamp falls in [from, to)
+func Build(period string, from, to time.Time, entries []journal.Entry) (*Summary, error) {
+	s := &Summary{Period: period, From: from, To: to, GeneratedAt: time.Now().UTC()}
+	routes := make(map[string]*RouteStat)
+	failures := make(map[string]int)
+	rejections := make(map[string]int)
+	failureCategories := make(map[string]int)
+	rejectionCategories := make(map[string]int)
+	stageRejections := make(map[string]int)
+	fills := slippage.NewTracker(nil)
+	reversed, err := reversals(entries)
+	if err != nil {
+		return nil, err
+	}
+	trials := make(map[string]*opportunity.Trial)
+	predicted := make(map[string][]prediction)
+	calibrated := make(calibration)
+	arms := make(map[string]*ArmStat)
+	arm := func(ta opportunity.TrialArm) *ArmStat {
+		k := ta.Arm + "/" + ta.Model + "@" + ta.Version
+		as := arms[k]
+		if as == nil {
+			as = &ArmStat{Arm: ta.Arm, Model: ta.Model, Version: ta.Version}
+			arms[k] = as
+		}
+		return as
+	}
+	route := func(name string) *RouteStat {
+		rs := routes[name]
+		if rs == nil {
+			rs = &RouteStat{Route: name}
+			routes[name] = rs
+		}
+		return rs
+	}
+
+	for _, e := range entries {
+		if e.Time.Before(from) || !e.Time.Before(to) {
+			continue
+		}
+		switch e.Kind {
+		case journal.KindOpportunity:
+			var o opportunity.Opportunity
+			if err := json.Unmarshal(e.Data, &o); err != nil {
+				return nil, fmt.Errorf("bad opportunity entry at %s: %w", e.Time, err)
+			}
+			s.OpportunitiesSeen++
+			if o.Explanation == nil {
+				continue
+			}
+			predicted[o.ID] = predictions(o.Explanation)
+			if t := o.Explanation.Trial; t != nil {
+				trials[o.ID] = t
+				for _, ta := range t.Arms {
+					as := arm(ta)
+					as.Scored++
+					if ta.Accept {
+						as.Accepted++
+					}
+					if ta.Arm == t.Decider {
+						as.Decided++
+					}
+				}
+			}
+			switch o.Explanation.Decision {
+			case opportunity.DecisionExecute:
+				s.Approved++
+			case opportunity.DecisionReject:
+				rejections[reasonOrUnknown(o.Explanation.Reason)]++
+				rejectionCategories[string(failure.Of(o.Explanation.Category, o.Explanation.Reason))]++
+				if bd := o.Explanation.Breakdown; bd != nil && bd.RejectedBy != "" {
+					stageRejections[bd.RejectedBy]++
+				}
+			}
+
+		case journal.KindExecution:
+			var x opportunity.Execution
+			if err := json.Unmarshal(e.Data, &x); err != nil {
+				return nil, fmt.Errorf("bad execution entry at %s: %w", e.Time, err)
+			}
+			if reversed[reversalKey{x.OpportunityID, x.BlockHash}] {
+				s.Reversed++
+				continue
+			}
+			s.Executions++
+			s.GasUSD += x.GasUSD
+			for _, fill := range x.Fills {
+				fills.Observe(fill)
+			}
+			s.PnLUSD += x.RealizedProfitUSD - x.GasUSD
+			if t := trials[x.OpportunityID]; t != nil {
+				for _, ta := range t.Arms {
+					if !ta.Accept {
+						continue
+					}
+					as := arm(ta)
+					as.Executions++
+					as.PnLUSD += x.RealizedProfitUSD - x.GasUSD
+					if x.Success {
+						as.Successes++
+					}
+				}
+			}
+
+			for _, p := range predicted[x.OpportunityID] {
+				calibrated.observe(p, x.Success)
+			}
+
+			rs := route(x.Route)
+			rs.Executions++
+			rs.PnLUSD += x.RealizedProfitUSD - x.GasUSD
+			if x.Success {
+				s.Successes++
+				rs.Successes++
+			} else {
+				failures[reasonOrUnknown(x.Reason)]++
+				failureCategories[string(failure.Of(x.Category, x.Reason))]++
+			}
+
+		case journal.KindCost:
+			var c opportunity.Cost
+			if err := json.Unmarshal(e.Data, &c); err != nil {
+				return nil, fmt.Errorf("bad cost entry at %s: %w", e.Time, err)
+			}
+			s.CostsUSD += c.USD
+			s.PnLUSD -= c.USD
+			if c.Route != "" {
+				rs := route(c.Route)
+				rs.CostUSD += c.USD
+				rs.PnLUSD -= c.USD
+			}
+		}
+	}
+
+	if s.Executions > 0 {
+		s.HitRate = float64(s.Successes) / float64(s.Executions)
+	}
+	for _, rs := range routes {
+		s.TopRoutes = append(s.TopRoutes, *rs)
+	}
+	sort.Slice(s.TopRoutes, func(i, j int) bool {
+		if s.TopRoutes[i].PnLUSD != s.TopRoutes[j].PnLUSD {
+			return s.TopRoutes[i].PnLUSD > s.TopRoutes[j].PnLUSD
+		}
+		return s.TopRoutes[i].Route < s.TopRoutes[j].Route
+	})
+	if len(s.TopRoutes) > topN {
+		s.TopRoutes = s.TopRoutes[:topN]
+	}
+	s.TopFailures = topCounts(failures)
+	s.TopRejections = topCounts(rejections)
+	s.FailureCategories = sortedCounts(failureCategories)
+	s.RejectionCategories = sortedCounts(rejectionCategories)
+	s.StageRejections = sortedCounts(stageRejections)
+	s.Slippage = fills.Stats()
+	for _, as := range arms {
+		if as.Executions > 0 {
+			as.HitRate = float64(as.Successes) / float64(as.Executions)
+		}
+		s.Experiment = append(s.Experiment, *as)
+	}
+	sort.Slice(s.Experiment, func(i, j int) bool {
+		if s.Experiment[i].Arm != s.Experiment[j].Arm {
+			return s.Experiment[i].Arm > s.Experiment[j].Arm // control first
+		}
+		if s.Experiment[i].Model != s.Experiment[j].Model {
+			return s.Experiment[i].Model < s.Experiment[j].Model
+		}
+		return s.Experiment[i].Version < s.Experiment[j].Version
+	})
+	s.Calibration = calibrated.curves()
+	return s, nil
+}
+
+// reversalKey identifies one inclusion of an execution
+type reversalKey struct {
+	id    string
+	block common.Hash
+}
+
+// reversals indexes every journaled reversal, regardless of the report
+// window, since a reorg may be detected after the period closed
+func reversals(entries []journal.Entry) (map[reversalKey]bool, error) {
+	out := make(map[reversalKey]bool)
+	for _, e := range entries {
+		if e.Kind != journal.KindReversal {
+			continue
+		}
+		var v opportunity.Reversal
+		if err := json.Unmarshal(e.Data, &v); err != nil {
+			return nil, fmt.Errorf("bad reversal entry at %s: %w", e.Time, err)
+		}
+		out[reversalKey{v.OpportunityID, v.BlockHash}] = true
+	}
+	return out, nil
+}
+
+// Generate reads the journal, opening sealed entries with keys, and
+// summarizes the period ending at end
+func Generate(journalPath string, keys *seal.Keyring, period string, end time.Time) (*Summary, error) {
+	length, err := Duration(period)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := journal.ReadSealed(journalPath, "", keys)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return Build(period, end.Add(-length), end, entries)
+}
+
+// ObserveFills feeds every journaled execution fill into tracker, restoring
+// slippage history after a restart
+func ObserveFills(entries []journal.Entry, tracker *slippage.Tracker) error {
+	for _, e := range entries {
+		if e.Kind != journal.KindExecution {
+			continue
+		}
+		var x opportunity.Execution
+		if err := json.Unmarshal(e.Data, &x); err != nil {
+			return fmt.Errorf("bad execution entry at %s: %w", e.Time, err)
+		}
+		for _, fill := range x.Fills {
+			tracker.Observe(fill)
+		}
+	}
+	return nil
+}
+
+// ObserveGas feeds every journaled execution's receipt gas into history,
+// restoring the gas models after a restart
+func ObserveGas(entries []journal.Entry, history *gas.History) error {
+	for _, e := range entries {
+		if e.Kind != journal.KindExecution {
+			continue
+		}
+		var x opportunity.Execution
+		if err := json.Unmarshal(e.Data, &x); err != nil {
+			return fmt.Errorf("bad execution entry at %s: %w", e.Time, err)
+		}
+		history.Observe(x.GasShape(), x.GasUsed)
+	}
+	return nil
+}
+
+// Markdown renders the summary for humans
+func (s *Summary) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Titan %s report\n\n", s.Period)
+	fmt.Fprintf(&b, "%s → %s (UTC)\n\n", s.From.UTC().Format("2006-01-02 15:04"), s.To.UTC().Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "| Metric | Value |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Opportunities seen | %d |\n", s.OpportunitiesSeen)
+	fmt.Fprintf(&b, "| Approved | %d |\n", s.Approved)
+	fmt.Fprintf(&b, "| Executions | %d |\n", s.Executions)
+	fmt.Fprintf(&b, "| Hit rate | %.1f%% |\n", s.HitRate*100)
+	fmt.Fprintf(&b, "| Net PnL | %s |\n", s.PnLUSD)
+	fmt.Fprintf(&b, "| Gas spend | %s |\n", s.GasUSD)
+	if s.CostsUSD > 0 {
+		fmt.Fprintf(&b, "| One-off costs | %s |\n", s.CostsUSD)
+	}
+	if s.Reversed > 0 {
+		fmt.Fprintf(&b, "| Reorged out | %d |\n", s.Reversed)
+	}
+
+	if len(s.TopRoutes) > 0 {
+		b.WriteString("\n## Top routes\n\n| Route | Executions | Successes | PnL |\n|---|---|---|---|\n")
+		for _, r := range s.TopRoutes {
+			fmt.Fprintf(&b, "| %s | %d | %d | %s |\n", r.Route, r.Executions, r.Successes, r.PnLUSD)
+		}
+	}
+	writeCounts(&b, "Top failure causes", s.TopFailures)
+	writeCounts(&b, "Top rejection reasons", s.TopRejections)
+	writeCounts(&b, "Failures by category", s.FailureCategories)
+	writeCounts(&b, "Rejections by category", s.RejectionCategories)
+	writeCounts(&b, "Rejections by scoring stage", s.StageRejections)
+
+	if len(s.Experiment) > 0 {
+		b.WriteString("\n## Scoring experiment\n\n| Arm | Model | Scored | Accepted | Decided | Executions | Hit rate | PnL |\n|---|---|---|---|---|---|---|---|\n")
+		for _, a := range s.Experiment {
+			model := a.Model
+			if a.Version != "" {
+				model += "@" + a.Version
+			}
+			fmt.Fprintf(&b, "| %s | %s | %d | %d | %d | %d | %.1f%% | %s |\n",
+				a.Arm, model, a.Scored, a.Accepted, a.Decided, a.Executions, a.HitRate*100, a.PnLUSD)
+		}
+	}
+
+	if len(s.Calibration) > 0 {
+		b.WriteString("\n## Confidence calibration\n\n| Model | Confidence | Executions | Mean confidence | Success rate |\n|---|---|---|---|---|\n")
+		for _, c := range s.Calibration {
+			flag := ""
+			if c.Miscalibrated {
+				flag = " ⚠️"
+			}
+			fmt.Fprintf(&b, "| %s%s | %.1f–%.1f | %d | %.0f%% | %.0f%% |\n",
+				c.Model, flag, c.Low, c.High, c.Executions, c.MeanConfidence*100, c.SuccessRate*100)
+		}
+	}
+
+	if len(s.Slippage) > 0 {
+		b.WriteString("\n## Slippage prediction error\n\n| DEX | Pool kind | Fills | Mean error | Max error | Optimistic |\n|---|---|---|---|---|---|\n")
+		for _, st := range s.Slippage {
+			flag := ""
+			if st.Samples >= optimisticSamples && st.MeanErrorBps > optimisticBps {
+				flag = " ⚠️"
+			}
+			fmt.Fprintf(&b, "| %s%s | %s | %d | %.1f bps | %d bps | %.0f%% |\n",
+				st.Dex, flag, st.PoolKind, st.Samples, st.MeanErrorBps, st.MaxErrorBps, st.OptimisticPct)
+		}
+	}
+	return b.String()
+}
+
+// Save writes the summary as JSON and Markdown artifacts into dir
+func (s *Summary) Save(dir string) (jsonPath, mdPath string, err error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", err
+	}
+	base := filepath.Join(dir, fmt.Sprintf("%s-%s", s.Period, s.To.UTC().Format("2006-01-02T1504Z")))
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", "", err
+	}
+	jsonPath, mdPath = base+".json", base+".md"
+	if err := os.WriteFile(jsonPath, data, 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(mdPath, []byte(s.Markdown()), 0o644); err != nil {
+		return "", "", err
+	}
+	return jsonPath, mdPath, nil
+}
+
+// Message converts the summary into an alert for delivery
+func (s *Summary) Message() alert.Message {
+	title := fmt.Sprintf("Titan %s report: %d executions, %.1f%% hit rate, PnL %s",
+		s.Period, s.Executions, s.HitRate*100, s.PnLUSD)
+	return alert.Message{Level: alert.LevelInfo, Title: title, Body: s.Markdown()}
+}
+
+func writeCounts(b *strings.Builder, heading string, counts []Count) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n## %s\n\n", heading)
+	for _, c := range counts {
+		fmt.Fprintf(b, "- %s: %d\n", c.Name, c.Count)
+	}
+}
+
+func topCounts(m map[string]int) []Count {
+	out := sortedCounts(m)
+	if len(out) > topN {
+		out = out[:topN]
+	}
+	return out
+}
+
+// sortedCounts returns every count, largest first
+func sortedCounts(m map[string]int) []Count {
+	out := make([]Count, 0, len(m))
+	for name, n := range m {
+		out = append(out, Count{Name: name, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func reasonOrUnknown(reason string) string {
+	if reason == "" {
+		return "unknown"
+	}
+	return reason
+}
@@ -0,0 +1,356 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/models"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+func entry(t *testing.T, at time.Time, kind string, data interface{}) journal.Entry {
+	t.Helper()
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return journal.Entry{Time: at, Kind: kind, Data: raw}
+}
+
+func TestBuildSummarizesWindow(t *testing.T) {
+	to := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	from := to.Add(-24 * time.Hour)
+	in := from.Add(time.Hour)
+
+	entries := []journal.Entry{
+		entry(t, in, journal.KindOpportunity, opportunity.Opportunity{ID: "a", Explanation: &opportunity.Explanation{Decision: opportunity.DecisionExecute}}),
+		entry(t, in, journal.KindOpportunity, opportunity.Opportunity{ID: "b", Explanation: &opportunity.Explanation{Decision: opportunity.DecisionReject, Reason: "below floor"}}),
+		entry(t, in, journal.KindExecution, opportunity.Execution{Route: "uniswap>sushi", Success: true, RealizedProfitUSD: units.DollarsToUSD(50), GasUSD: units.DollarsToUSD(5)}),
+		entry(t, in, journal.KindExecution, opportunity.Execution{Route: "uniswap>sushi", Success: false, Reason: "reverted", GasUSD: units.DollarsToUSD(3)}),
+		// Outside the window
+		entry(t, to, journal.KindExecution, opportunity.Execution{Route: "late", Success: true}),
+	}
+
+	s, err := Build(PeriodDaily, from, to, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.OpportunitiesSeen != 2 || s.Approved != 1 {
+		t.Errorf("Expected 2 seen/1 approved, got %d/%d", s.OpportunitiesSeen, s.Approved)
+	}
+	if s.Executions != 2 || s.HitRate != 0.5 {
+		t.Errorf("Expected 2 executions at 50%% hit rate, got %d at %v", s.Executions, s.HitRate)
+	}
+	if s.PnLUSD != units.DollarsToUSD(42) {
+		t.Errorf("Expected PnL $42, got %s", s.PnLUSD)
+	}
+	if s.GasUSD != units.DollarsToUSD(8) {
+		t.Errorf("Expected gas $8, got %s", s.GasUSD)
+	}
+	if len(s.TopRoutes) != 1 || s.TopRoutes[0].Route != "uniswap>sushi" {
+		t.Errorf("Expected single top route, got %+v", s.TopRoutes)
+	}
+	if len(s.TopFailures) != 1 || s.TopFailures[0].Name != "reverted" {
+		t.Errorf("Expected reverted failure, got %+v", s.TopFailures)
+	}
+	if len(s.TopRejections) != 1 || s.TopRejections[0].Name != "below floor" {
+		t.Errorf("Expected below floor rejection, got %+v", s.TopRejections)
+	}
+	if len(s.FailureCategories) != 1 || s.FailureCategories[0].Name != string(failure.Reverted) {
+		t.Errorf("Expected one REVERTED failure, got %+v", s.FailureCategories)
+	}
+	if len(s.RejectionCategories) != 1 || s.RejectionCategories[0].Name != string(failure.GuardrailFloor) {
+		t.Errorf("Expected one GUARDRAIL_FLOOR rejection, got %+v", s.RejectionCategories)
+	}
+}
+
+func TestSaveWritesArtifacts(t *testing.T) {
+	to := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	s, err := Build(PeriodWeekly, to.Add(-7*24*time.Hour), to, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonPath, mdPath, err := s.Save(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(jsonPath, "weekly-2026-01-05T0000Z.json") {
+		t.Errorf("Unexpected artifact name %s", jsonPath)
+	}
+	md, err := os.ReadFile(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(md), "# Titan weekly report") {
+		t.Errorf("Expected markdown heading, got %q", md)
+	}
+}
+
+func TestBuildDropsReorgedExecutions(t *testing.T) {
+	to := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	from := to.Add(-24 * time.Hour)
+	in := from.Add(time.Hour)
+	orphan, canonical := common.HexToHash("0xaa"), common.HexToHash("0xbb")
+
+	entries := []journal.Entry{
+		entry(t, in, journal.KindExecution, opportunity.Execution{OpportunityID: "x", BlockHash: orphan, Success: true, RealizedProfitUSD: units.DollarsToUSD(50)}),
+		entry(t, in, journal.KindExecution, opportunity.Execution{OpportunityID: "x", BlockHash: canonical, Success: true, RealizedProfitUSD: units.DollarsToUSD(45)}),
+		// Detected after the window closed, still retracts the orphaned entry
+		entry(t, to.Add(time.Hour), journal.KindReversal, opportunity.Reversal{OpportunityID: "x", BlockHash: orphan}),
+	}
+
+	s, err := Build(PeriodDaily, from, to, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Executions != 1 || s.Reversed != 1 {
+		t.Errorf("Expected 1 execution and 1 reversal, got %d/%d", s.Executions, s.Reversed)
+	}
+	if s.PnLUSD != units.DollarsToUSD(45) {
+		t.Errorf("Expected PnL $45 from the canonical inclusion, got %s", s.PnLUSD)
+	}
+}
+
+func TestBuildChargesOneOffCosts(t *testing.T) {
+	to := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	from := to.Add(-24 * time.Hour)
+	in := from.Add(time.Hour)
+
+	entries := []journal.Entry{
+		entry(t, in, journal.KindExecution, opportunity.Execution{Route: "uniswap>sushi", Success: true, RealizedProfitUSD: units.DollarsToUSD(50), GasUSD: units.DollarsToUSD(5)}),
+		entry(t, in, journal.KindCost, opportunity.Cost{Kind: opportunity.CostApproval, ChainID: 137, Route: "uniswap>sushi", USD: units.DollarsToUSD(3)}),
+		entry(t, in, journal.KindCost, opportunity.Cost{Kind: opportunity.CostBridge, ChainID: 137, USD: units.DollarsToUSD(10)}),
+	}
+
+	s, err := Build(PeriodDaily, from, to, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.CostsUSD != units.DollarsToUSD(13) || s.PnLUSD != units.DollarsToUSD(32) {
+		t.Errorf("Expected $13 of costs and PnL $32, got %s and %s", s.CostsUSD, s.PnLUSD)
+	}
+	if len(s.TopRoutes) != 1 || s.TopRoutes[0].CostUSD != units.DollarsToUSD(3) || s.TopRoutes[0].PnLUSD != units.DollarsToUSD(42) {
+		t.Errorf("Expected the approval charged to its route, got %+v", s.TopRoutes)
+	}
+	if !strings.Contains(s.Markdown(), "| One-off costs | ") {
+		t.Errorf("Expected a one-off costs row, got %q", s.Markdown())
+	}
+}
+
+func TestBuildComparesExperimentArms(t *testing.T) {
+	to := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	from := to.Add(-24 * time.Hour)
+	in := from.Add(time.Hour)
+	trial := func(controlAccepts, candidateAccepts bool) *opportunity.Explanation {
+		return &opportunity.Explanation{Trial: &opportunity.Trial{Decider: "control", Arms: []opportunity.TrialArm{
+			{Arm: "control", Model: "return", Accept: controlAccepts},
+			{Arm: "candidate", Model: "net-profit", Accept: candidateAccepts},
+		}}}
+	}
+
+	s, err := Build(PeriodDaily, from, to, []journal.Entry{
+		entry(t, in, journal.KindOpportunity, opportunity.Opportunity{ID: "a", Explanation: trial(true, true)}),
+		entry(t, in, journal.KindOpportunity, opportunity.Opportunity{ID: "b", Explanation: trial(true, false)}),
+		entry(t, in, journal.KindOpportunity, opportunity.Opportunity{ID: "c", Explanation: trial(false, true)}),
+		entry(t, in, journal.KindExecution, opportunity.Execution{OpportunityID: "a", Success: true, RealizedProfitUSD: units.DollarsToUSD(40), GasUSD: units.DollarsToUSD(4)}),
+		entry(t, in, journal.KindExecution, opportunity.Execution{OpportunityID: "b", Success: false, GasUSD: units.DollarsToUSD(6)}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Experiment) != 2 {
+		t.Fatalf("Expected both arms compared, got %+v", s.Experiment)
+	}
+	control, candidate := s.Experiment[0], s.Experiment[1]
+	if control.Arm != "control" || control.Accepted != 2 || control.Decided != 3 || control.Executions != 2 || control.HitRate != 0.5 || control.PnLUSD != units.DollarsToUSD(30) {
+		t.Errorf("Expected the control's 2 executions at 50%% for $30, got %+v", control)
+	}
+	if candidate.Model != "net-profit" || candidate.Accepted != 2 || candidate.Decided != 0 || candidate.Executions != 1 || candidate.HitRate != 1 || candidate.PnLUSD != units.DollarsToUSD(36) {
+		t.Errorf("Expected the candidate to have avoided the failed execution, got %+v", candidate)
+	}
+	if !strings.Contains(s.Markdown(), "## Scoring experiment") {
+		t.Errorf("Expected the comparison in the markdown report")
+	}
+}
+
+func TestBuildFlagsMiscalibratedConfidence(t *testing.T) {
+	to := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	from := to.Add(-24 * time.Hour)
+	in := from.Add(time.Hour)
+	var entries []journal.Entry
+	for i := 0; i < 12; i++ {
+		id := string(rune('a' + i))
+		x := &opportunity.Explanation{Trial: &opportunity.Trial{Decider: "control", Arms: []opportunity.TrialArm{
+			{Arm: "control", Model: "gbm", Version: "3", Score: 0.85, Accept: true},
+			{Arm: "candidate", Model: "return", Score: 12.5},
+		}}}
+		entries = append(entries,
+			entry(t, in, journal.KindOpportunity, opportunity.Opportunity{ID: id, Explanation: x}),
+			entry(t, in, journal.KindExecution, opportunity.Execution{OpportunityID: id, Success: i%2 == 0}))
+	}
+
+	s, err := Build(PeriodDaily, from, to, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Calibration) != 1 {
+		t.Fatalf("Expected one bucket for the model with confidences, got %+v", s.Calibration)
+	}
+	c := s.Calibration[0]
+	if c.Model != "gbm@3" || c.Low != 0.8 || c.Executions != 12 || c.SuccessRate != 0.5 || math.Abs(c.MeanConfidence-0.85) > 1e-9 || !c.Miscalibrated {
+		t.Errorf("Expected 0.85 confidence winning half the time flagged, got %+v", c)
+	}
+	if !strings.Contains(s.Markdown(), "| gbm@3 ⚠️ | 0.8–0.9 | 12 | 85% | 50% |") {
+		t.Errorf("Expected the flagged bucket in the markdown report, got %s", s.Markdown())
+	}
+}
+
+func TestLabelsJoinBreakdownsWithOutcomes(t *testing.T) {
+	at := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	scored := func(passed bool, rejectedBy string) *opportunity.Explanation {
+		x := &opportunity.Explanation{Decision: opportunity.DecisionExecute, Breakdown: &opportunity.ScoreBreakdown{
+			Features: map[string]float64{"net_profit_usd": 12},
+			Stages:   []opportunity.StageScore{{Stage: "filters", Score: 1, Weight: 1, Contribution: 1, Accept: passed}},
+			Score:    1, Passed: passed, RejectedBy: rejectedBy,
+		}}
+		if !passed {
+			x.Reject(failure.GuardrailFloor, "net profit below minimum")
+		}
+		return x
+	}
+	entries := []journal.Entry{
+		entry(t, at.Add(-time.Hour), journal.KindOpportunity, opportunity.Opportunity{ID: "old", Explanation: scored(true, "")}),
+		entry(t, at, journal.KindOpportunity, opportunity.Opportunity{ID: "a", Explanation: scored(true, "")}),
+		entry(t, at, journal.KindOpportunity, opportunity.Opportunity{ID: "b", Explanation: scored(false, "filters")}),
+		entry(t, at, journal.KindOpportunity, opportunity.Opportunity{ID: "unscored", Explanation: &opportunity.Explanation{}}),
+		entry(t, at, journal.KindExecution, opportunity.Execution{OpportunityID: "a", Success: true, RealizedProfitUSD: units.DollarsToUSD(20), GasUSD: units.DollarsToUSD(5)}),
+	}
+	labels, err := Labels(entries, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 2 {
+		t.Fatalf("Expected the two scored opportunities since the cutoff, got %+v", labels)
+	}
+	if a := labels[0]; !a.Executed || !a.Success || a.PnLUSD != units.DollarsToUSD(15) || a.Breakdown.Features["net_profit_usd"] != 12 {
+		t.Errorf("Expected a's features labeled with its $15 fill, got %+v", a)
+	}
+	if b := labels[1]; b.Executed || b.Breakdown.RejectedBy != "filters" || b.Category != failure.GuardrailFloor {
+		t.Errorf("Expected b's rejection kept, got %+v", b)
+	}
+
+	s, err := Build(PeriodDaily, at.Add(-time.Hour), at.Add(time.Hour), entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.StageRejections) != 1 || s.StageRejections[0] != (Count{Name: "filters", Count: 1}) {
+		t.Errorf("Expected the rejection attributed to filters, got %+v", s.StageRejections)
+	}
+}
+
+func TestBackfillRecomputesFeaturesAndScores(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"edge/CURRENT":          "v2\n",
+		"edge/v2/manifest.json": `{"name": "edge", "version": "v2", "format": "linear", "artifact": "model.json"}`,
+		"edge/v2/model.json":    `{"bias": 1, "weights": {"hops": 2, "news_sentiment": 10}}`,
+	}
+	for path, body := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	registry := models.New(dir, []string{"edge"}, nil)
+	if err := registry.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	legs := []opportunity.Leg{{}, {}}
+	entries := []journal.Entry{
+		// journaled before breakdowns were stored
+		entry(t, at, journal.KindOpportunity, opportunity.Opportunity{ID: "a", Explanation: &opportunity.Explanation{Score: 0.4, Legs: legs}}),
+		entry(t, at, journal.KindOpportunity, opportunity.Opportunity{ID: "b", Explanation: &opportunity.Explanation{Score: 0.1, Legs: legs, Breakdown: &opportunity.ScoreBreakdown{
+			Features: map[string]float64{"news_sentiment": 0.5, "hops": 9},
+		}}}),
+		entry(t, at, journal.KindExecution, opportunity.Execution{OpportunityID: "a", Success: true}),
+	}
+	rows, err := Backfill(entries, at, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || !rows[0].Executed || !rows[0].Success || rows[1].Executed {
+		t.Fatalf("Expected both opportunities labeled with their outcomes, got %+v", rows)
+	}
+	if a := rows[0]; a.Features["hops"] != 2 || a.Scores["edge@v2"] != 5 || a.Scores["return"] != 0.4 {
+		t.Errorf("Expected a's features and scores recomputed, got %+v", a)
+	}
+	if b := rows[1]; b.Features["hops"] != 2 || b.Features["news_sentiment"] != 0.5 || b.Scores["edge@v2"] != 10 {
+		t.Errorf("Expected b's stored signals kept under the recomputed features, got %+v", b)
+	}
+}
+
+func TestRollupJobStoresClosedHours(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	var lines []byte
+	for _, e := range []journal.Entry{
+		entry(t, base.Add(5*time.Minute), journal.KindOpportunity, opportunity.Opportunity{ID: "a", Explanation: &opportunity.Explanation{Decision: opportunity.DecisionExecute}}),
+		entry(t, base.Add(10*time.Minute), journal.KindExecution, opportunity.Execution{OpportunityID: "a", Success: true, RealizedProfitUSD: units.DollarsToUSD(20), GasUSD: units.DollarsToUSD(2)}),
+		entry(t, base.Add(2*time.Hour+time.Minute), journal.KindExecution, opportunity.Execution{OpportunityID: "b", GasUSD: units.DollarsToUSD(3)}),
+		entry(t, base.Add(2*time.Hour+2*time.Minute), journal.KindCost, opportunity.Cost{Kind: opportunity.CostApproval, USD: units.DollarsToUSD(1)}),
+	} {
+		raw, _ := json.Marshal(e)
+		lines = append(append(lines, raw...), '\n')
+	}
+	journalPath := filepath.Join(dir, "journal.jsonl")
+	if err := os.WriteFile(journalPath, lines, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	store, err := journal.Open(filepath.Join(dir, "rollups.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	job := &RollupJob{JournalPath: journalPath, Store: store}
+
+	if err := job.CatchUp(base.Add(2*time.Hour + 30*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := job.CatchUp(base.Add(3 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	rollups, err := ReadRollups(store.Path(), nil, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rollups) != 3 || !rollups[0].Hour.Equal(base) || !rollups[2].Hour.Equal(base.Add(2*time.Hour)) {
+		t.Fatalf("Expected each closed hour stored once, got %+v", rollups)
+	}
+	if r := rollups[0]; r.Opportunities != 1 || r.Approved != 1 || r.Executions != 1 || r.HitRate != 1 || r.PnLUSD != units.DollarsToUSD(18) {
+		t.Errorf("Expected the first hour's trade, got %+v", r)
+	}
+	if r := rollups[1]; r.Executions != 0 || r.PnLUSD != 0 {
+		t.Errorf("Expected a quiet hour kept in the series, got %+v", r)
+	}
+	if r := rollups[2]; r.Executions != 1 || r.HitRate != 0 || r.GasUSD != units.DollarsToUSD(3) || r.PnLUSD != -units.DollarsToUSD(4) {
+		t.Errorf("Expected the failed trade and the approval cost, got %+v", r)
+	}
+	if recent, _ := ReadRollups(store.Path(), nil, base.Add(time.Hour)); len(recent) != 2 {
+		t.Errorf("Expected rollups since the cutoff, got %+v", recent)
+	}
+}
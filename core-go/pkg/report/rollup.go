@@ -0,0 +1,169 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/seal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Rollup is one hour of activity, kept locally so the history outlives
+// Prometheus retention
+type Rollup struct {
+	Hour          time.Time `json:"hour"` // start of the hour, UTC
+	Opportunities int       `json:"opportunities"`
+	Approved      int       `json:"approved"`
+	Executions    int       `json:"executions"`
+	Successes     int       `json:"successes"`
+	HitRate       float64   `json:"hitRate"`  // successes / executions
+	PnLUSD        units.USD `json:"pnlUsd"`   // realized, net of gas and one-off costs
+	Reversed      int       `json:"reversed"` // executions dropped after a reorg
+	GasUSD        units.USD `json:"gasUsd"`
+	CostsUSD      units.USD `json:"costsUsd"`
+}
+
+// Rollups summarizes every hour from the one holding from up to the one
+// holding to, exclusive, in one pass over the entries; like Build, it drops
+// executions reorged before the call
+func Rollups(entries []journal.Entry, from, to time.Time) ([]Rollup, error) {
+	from, to = from.UTC().Truncate(time.Hour), to.UTC().Truncate(time.Hour)
+	reversed, err := reversals(entries)
+	if err != nil {
+		return nil, err
+	}
+	var out []Rollup
+	for h := from; h.Before(to); h = h.Add(time.Hour) {
+		out = append(out, Rollup{Hour: h})
+	}
+	for _, e := range entries {
+		if e.Time.Before(from) || !e.Time.Before(to) {
+			continue
+		}
+		r := &out[int(e.Time.Sub(from)/time.Hour)]
+		switch e.Kind {
+		case journal.KindOpportunity:
+			var o opportunity.Opportunity
+			if err := json.Unmarshal(e.Data, &o); err != nil {
+				return nil, fmt.Errorf("bad opportunity entry at %s: %w", e.Time, err)
+			}
+			r.Opportunities++
+			if o.Explanation != nil && o.Explanation.Decision == opportunity.DecisionExecute {
+				r.Approved++
+			}
+
+		case journal.KindExecution:
+			var x opportunity.Execution
+			if err := json.Unmarshal(e.Data, &x); err != nil {
+				return nil, fmt.Errorf("bad execution entry at %s: %w", e.Time, err)
+			}
+			if reversed[reversalKey{x.OpportunityID, x.BlockHash}] {
+				r.Reversed++
+				continue
+			}
+			r.Executions++
+			r.GasUSD += x.GasUSD
+			r.PnLUSD += x.RealizedProfitUSD - x.GasUSD
+			if x.Success {
+				r.Successes++
+			}
+
+		case journal.KindCost:
+			var c opportunity.Cost
+			if err := json.Unmarshal(e.Data, &c); err != nil {
+				return nil, fmt.Errorf("bad cost entry at %s: %w", e.Time, err)
+			}
+			r.CostsUSD += c.USD
+			r.PnLUSD -= c.USD
+		}
+	}
+	for i := range out {
+		if out[i].Executions > 0 {
+			out[i].HitRate = float64(out[i].Successes) / float64(out[i].Executions)
+		}
+	}
+	return out, nil
+}
+
+// ReadRollups returns the stored rollups of hours starting at or after
+// since, oldest first; a missing store has none
+func ReadRollups(path string, keys *seal.Keyring, since time.Time) ([]Rollup, error) {
+	entries, err := journal.ReadSealed(path, journal.KindRollup, keys)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var out []Rollup
+	for _, e := range entries {
+		var r Rollup
+		if err := json.Unmarshal(e.Data, &r); err != nil {
+			return nil, fmt.Errorf("bad rollup entry at %s: %w", e.Time, err)
+		}
+		if !r.Hour.Before(since) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// RollupJob appends each hour's rollup of the journal to a store once the
+// hour closes, catching up on hours missed while the daemon was down. A
+// stored hour isn't revisited, so a reorg detected after its rollup only
+// shows in reports built from the journal.
+type RollupJob struct {
+	JournalPath string
+	Keys        *seal.Keyring // opens a sealed journal and store
+	Store       *journal.Journal
+}
+
+// Run blocks until ctx is cancelled
+func (j *RollupJob) Run(ctx context.Context) {
+	for {
+		if err := j.CatchUp(time.Now()); err != nil {
+			log.Printf("❌ Hourly rollup failed: %v", err)
+		}
+		now := time.Now().UTC()
+		timer := time.NewTimer(now.Truncate(time.Hour).Add(time.Hour).Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// CatchUp stores the rollup of every closed hour before now that follows the
+// last one stored, starting from the journal's first hour
+func (j *RollupJob) CatchUp(now time.Time) error {
+	stored, err := ReadRollups(j.Store.Path(), j.Keys, time.Time{})
+	if err != nil {
+		return err
+	}
+	entries, err := journal.ReadSealed(j.JournalPath, "", j.Keys)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	from := entries[0].Time
+	if len(stored) > 0 {
+		from = stored[len(stored)-1].Hour.Add(time.Hour)
+	}
+	rollups, err := Rollups(entries, from, now)
+	if err != nil {
+		return err
+	}
+	for _, r := range rollups {
+		if err := j.Store.Append(journal.KindRollup, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
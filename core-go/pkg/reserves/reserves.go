@@ -0,0 +1,183 @@
+package reserves
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/amm"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Pool kinds
+const (
+	KindV2 = "v2" // constant product (UniswapV2 and forks)
+	KindV3 = "v3" // concentrated liquidity (UniswapV3 and forks)
+)
+
+// Snapshot is the cached state of a single pool. For V3 pools Reserve0/1 are
+// the virtual reserves of the active tick range, L/sqrtP and L*sqrtP.
+type Snapshot struct {
+	ChainID   uint64         `json:"chainId"`
+	Dex       string         `json:"dex"`
+	Kind      string         `json:"kind"`
+	Pool      common.Address `json:"pool"`
+	Token0    common.Address `json:"token0"`
+	Token1    common.Address `json:"token1"`
+	Reserve0  *big.Int       `json:"reserve0"`
+	Reserve1  *big.Int       `json:"reserve1"`
+	FeeBps    uint32         `json:"feeBps"`
+	Liquidity *big.Int       `json:"liquidity,omitempty"`
+	Block     uint64         `json:"block"`
+	FetchedAt time.Time      `json:"fetchedAt"`
+}
+
+// AMM returns the constant-product view of the snapshot for local quoting
+func (s *Snapshot) AMM() *amm.Pool {
+	return &amm.Pool{Reserve0: s.Reserve0, Reserve1: s.Reserve1, FeeBps: s.FeeBps}
+}
+
+// ZeroForOne reports whether tokenIn is the pool's token0
+func (s *Snapshot) ZeroForOne(tokenIn common.Address) bool {
+	return tokenIn == s.Token0
+}
+
+// PriceImpactBps estimates the total cost (fee + slippage) of swapping amountIn
+// of tokenIn relative to the spot price, in basis points
+func (s *Snapshot) PriceImpactBps(tokenIn common.Address, amountIn *big.Int) (uint64, error) {
+	zeroForOne := s.ZeroForOne(tokenIn)
+	out, err := s.AMM().Quote(amountIn, zeroForOne)
+	if err != nil {
+		return 0, err
+	}
+	reserveIn, reserveOut := s.Reserve0, s.Reserve1
+	if !zeroForOne {
+		reserveIn, reserveOut = s.Reserve1, s.Reserve0
+	}
+
+	// impact = 1 - (out/in) / (reserveOut/reserveIn)
+	realized := new(big.Int).Mul(out, reserveIn)
+	realized.Mul(realized, big.NewInt(units.BpsDenominator))
+	spot := new(big.Int).Mul(amountIn, reserveOut)
+	ratio := realized.Quo(realized, spot).Uint64()
+	if ratio >= units.BpsDenominator {
+		return 0, nil
+	}
+	return units.BpsDenominator - ratio, nil
+}
+
+// Cache holds pool snapshots keyed by chain and pool address. It is safe for
+// concurrent use.
+type Cache struct {
+	mu    sync.RWMutex
+	pools map[uint64]map[common.Address]*Snapshot
+}
+
+// NewCache creates an empty reserve cache
+func NewCache() *Cache {
+	return &Cache{pools: make(map[uint64]map[common.Address]*Snapshot)}
+}
+
+// Put stores or replaces a snapshot
+func (c *Cache) Put(s *Snapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pools[s.ChainID] == nil {
+		c.pools[s.ChainID] = make(map[common.Address]*Snapshot)
+	}
+	c.pools[s.ChainID][s.Pool] = s
+}
+
+// Get returns a snapshot no older than maxAge (0 disables the age check)
+func (c *Cache) Get(chainID uint64, pool common.Address, maxAge time.Duration) (*Snapshot, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	s, ok := c.pools[chainID][pool]
+	if !ok || (maxAge > 0 && time.Since(s.FetchedAt) > maxAge) {
+		return nil, false
+	}
+	return s, true
+}
+
+// Pair returns every fresh snapshot trading tokenA against tokenB, deepest first
+func (c *Cache) Pair(chainID uint64, tokenA, tokenB common.Address, maxAge time.Duration) []*Snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var out []*Snapshot
+	for _, s := range c.pools[chainID] {
+		if maxAge > 0 && time.Since(s.FetchedAt) > maxAge {
+			continue
+		}
+		if (s.Token0 == tokenA && s.Token1 == tokenB) || (s.Token0 == tokenB && s.Token1 == tokenA) {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return depth(out[i], tokenA).Cmp(depth(out[j], tokenA)) > 0
+	})
+	return out
+}
+
+// All returns every snapshot on a chain
+func (c *Cache) All(chainID uint64) []*Snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]*Snapshot, 0, len(c.pools[chainID]))
+	for _, s := range c.pools[chainID] {
+		out = append(out, s)
+	}
+	return out
+}
+
+// Save writes the cache to a JSON file
+func (c *Cache) Save(path string) error {
+	c.mu.RLock()
+	var all []*Snapshot
+	for _, pools := range c.pools {
+		for _, s := range pools {
+			all = append(all, s)
+		}
+	}
+	c.mu.RUnlock()
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Load merges snapshots from a JSON file written by Save. A missing file is not an error.
+func (c *Cache) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var all []*Snapshot
+	if err := json.Unmarshal(data, &all); err != nil {
+		return fmt.Errorf("invalid reserve cache %s: %w", path, err)
+	}
+	for _, s := range all {
+		c.Put(s)
+	}
+	return nil
+}
+
+func depth(s *Snapshot, token common.Address) *big.Int {
+	if s.Token0 == token {
+		return s.Reserve0
+	}
+	return s.Reserve1
+}
@@ -0,0 +1,135 @@
+package route
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Encoding, version 1. All integers are big-endian, and testdata/vectors.json
+// pins the layout byte for byte. Nothing outside this package's tests calls
+// Encode or Decode: the executor never reads the compact encoding, its
+// calldata carries RAW_ADDRESSES routeData (EncodeRaw), and no route is
+// stored in it. It keeps a step's Pool but not its Router, so a route that
+// round-trips through it can no longer be sent.
+//
+//	route:  version u8 | steps u8 | tokenIn [20] | amountIn amt | minOut amt | step*steps
+//	step:   adapter u8 | pool [20] | tokenOut [20] | amountIn amt | minOut amt | extraLen u16 | extra
+//	amt:    len u8 (0-32) | value [len], no leading zero bytes; zero is len 0
+
+// Encode returns the compact encoding of r
+func Encode(r *Route) ([]byte, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, 2+common.AddressLength+66+len(r.Steps)*(1+2*common.AddressLength+68))
+	out = append(out, Version, uint8(len(r.Steps)))
+	out = append(out, r.TokenIn.Bytes()...)
+	out = appendAmount(out, r.AmountIn)
+	out = appendAmount(out, r.MinOut)
+	for _, s := range r.Steps {
+		out = append(out, uint8(s.Adapter))
+		out = append(out, s.Pool.Bytes()...)
+		out = append(out, s.TokenOut.Bytes()...)
+		out = appendAmount(out, s.AmountIn)
+		out = appendAmount(out, s.MinOut)
+		out = binary.BigEndian.AppendUint16(out, uint16(len(s.Extra)))
+		out = append(out, s.Extra...)
+	}
+	return out, nil
+}
+
+// Decode parses an encoded route, rejecting unknown versions, truncated or
+// trailing data and non-canonical amounts
+func Decode(data []byte) (*Route, error) {
+	d := &decoder{data: data}
+	version := d.byte()
+	if d.err == nil && version != Version {
+		return nil, fmt.Errorf("%w %d", ErrVersion, version)
+	}
+	n := int(d.byte())
+	r := &Route{TokenIn: d.address(), AmountIn: d.amount(), MinOut: d.amount()}
+	if d.err == nil && (n == 0 || n > MaxSteps) {
+		return nil, fmt.Errorf("%w: %d steps, need 1 to %d", ErrInvalid, n, MaxSteps)
+	}
+	for i := 0; i < n && d.err == nil; i++ {
+		s := Step{Adapter: Adapter(d.byte()), Pool: d.address(), TokenOut: d.address(), AmountIn: d.amount(), MinOut: d.amount()}
+		if size := int(d.uint16()); size > 0 {
+			s.Extra = d.bytes(size)
+		}
+		r.Steps = append(r.Steps, s)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if len(d.data) > d.pos {
+		return nil, fmt.Errorf("%w: %d after route", ErrTrailingBytes, len(d.data)-d.pos)
+	}
+	return r, nil
+}
+
+// appendAmount writes v as a length-prefixed minimal big-endian integer
+func appendAmount(out []byte, v *big.Int) []byte {
+	if v == nil {
+		return append(out, 0)
+	}
+	b := v.Bytes()
+	return append(append(out, uint8(len(b))), b...)
+}
+
+// decoder reads fields in order, keeping the first error
+type decoder struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.data)-d.pos < n {
+		d.err = fmt.Errorf("%w at byte %d", ErrTruncated, d.pos)
+		return nil
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+func (d *decoder) byte() uint8 {
+	if b := d.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *decoder) uint16() uint16 {
+	if b := d.bytes(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *decoder) address() common.Address {
+	return common.BytesToAddress(d.bytes(common.AddressLength))
+}
+
+func (d *decoder) amount() *big.Int {
+	start := d.pos
+	n := int(d.byte())
+	if d.err == nil && n > 32 {
+		d.err = fmt.Errorf("%w: %d-byte amount at byte %d", ErrNonCanonical, n, start)
+	}
+	b := d.bytes(n)
+	if d.err != nil {
+		return nil
+	}
+	if n > 0 && b[0] == 0 {
+		d.err = fmt.Errorf("%w: leading zero at byte %d", ErrNonCanonical, start)
+		return nil
+	}
+	return new(big.Int).SetBytes(b)
+}
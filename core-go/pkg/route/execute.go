@@ -0,0 +1,128 @@
+package route
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// FlashSource selects the executor's flash-loan lender
+type FlashSource uint8
+
+// Flash-loan lenders understood by the executor, in the order of its
+// FlashSource enum (docs/CANONICAL_SPECIFICATION.md)
+const (
+	FlashAave     FlashSource = 0
+	FlashBalancer FlashSource = 1
+)
+
+// Name returns the lender's display name
+func (s FlashSource) Name() string {
+	switch s {
+	case FlashBalancer:
+		return "balancer"
+	case FlashAave:
+		return "aave"
+	}
+	return fmt.Sprintf("lender(%d)", uint8(s))
+}
+
+// PremiumBps is the lender's fee on the borrowed amount
+func (s FlashSource) PremiumBps() uint64 {
+	if s == FlashAave {
+		return 5
+	}
+	return 0
+}
+
+// ParseFlashSource parses a lender name, balancer or aave
+func ParseFlashSource(name string) (FlashSource, error) {
+	switch strings.ToLower(name) {
+	case "balancer":
+		return FlashBalancer, nil
+	case "aave":
+		return FlashAave, nil
+	}
+	return 0, fmt.Errorf("unknown flash-loan lender %q: want balancer or aave", name)
+}
+
+// ExecuteSelector is keccak256("execute(uint8,address,uint256,bytes)")[:4]
+var ExecuteSelector = crypto.Keccak256([]byte("execute(uint8,address,uint256,bytes)"))[:4]
+
+// ErrNotExecute is returned when unpacking calldata for another function
+var ErrNotExecute = errors.New("route: not an execute call")
+
+// ErrNoExecuteMulti is returned for multi-token plans: the deployed executor
+// and docs/CANONICAL_SPECIFICATION.md have no executeMulti, so a plan
+// borrowing several tokens in one loan cannot be sent
+var ErrNoExecuteMulti = errors.New("route: the executor has no executeMulti entry point")
+
+var executeArgs = abi.Arguments{
+	{Type: mustType("uint8")},
+	{Type: mustType("address")},
+	{Type: mustType("uint256")},
+	{Type: mustType("bytes")},
+}
+
+// ExecuteCall is the executor's execute(flashSource, token, amount, routeData):
+// borrow Amount of Token from Source and swap it along RouteData
+type ExecuteCall struct {
+	Source    FlashSource
+	Token     common.Address
+	Amount    *big.Int
+	RouteData []byte
+}
+
+// Pack returns the call's calldata
+func (c *ExecuteCall) Pack() ([]byte, error) {
+	packed, err := executeArgs.Pack(uint8(c.Source), c.Token, c.Amount, c.RouteData)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, ExecuteSelector...), packed...), nil
+}
+
+// UnpackExecute decodes execute calldata
+func UnpackExecute(data []byte) (*ExecuteCall, error) {
+	if len(data) < 4 || !bytes.Equal(data[:4], ExecuteSelector) {
+		return nil, ErrNotExecute
+	}
+	args, err := executeArgs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotExecute, err)
+	}
+	return &ExecuteCall{
+		Source:    FlashSource(args[0].(uint8)),
+		Token:     args[1].(common.Address),
+		Amount:    args[2].(*big.Int),
+		RouteData: args[3].([]byte),
+	}, nil
+}
+
+// EncodeExecute returns calldata borrowing r's AmountIn of TokenIn from
+// source and swapping it along r, encoded as RAW_ADDRESSES routeData
+func EncodeExecute(source FlashSource, r *Route) ([]byte, error) {
+	data, err := EncodeRaw(r)
+	if err != nil {
+		return nil, err
+	}
+	amount := r.AmountIn
+	if amount == nil {
+		amount = new(big.Int)
+	}
+	return (&ExecuteCall{Source: source, Token: r.TokenIn, Amount: amount, RouteData: data}).Pack()
+}
+
+func mustType(name string) abi.Type {
+	t, err := abi.NewType(name, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
@@ -0,0 +1,151 @@
+package route
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Calldata gas per byte, as charged for L1 data on OP-stack chains
+const (
+	zeroByteGas    = 4
+	nonZeroByteGas = 16
+)
+
+// Compaction is dead code. Compact, Optimize and the Savings report work on
+// the compact encoding, which the executor never reads, and RAW_ADDRESSES
+// routeData carries no amounts to shrink, so titan execute only reports the
+// plain calldata's L1 data fee (L1Fee.Cost) and nothing here runs outside
+// tests.
+
+// Compact returns a copy of r shrunk for calldata-priced chains:
+//   - hops after the first spend the previous output, so their amountIn is
+//     dropped
+//   - step minOuts are dropped; the route's minOut, checked after the last
+//     swap, protects the whole route (the last step's minOut is promoted when
+//     the route has none)
+//   - minOut is rounded down to clear as many low bytes as toleranceBps of
+//     slack allows, since zero bytes are 4x cheaper than non-zero ones
+//
+// Compact assumes every hop spends its whole input, which is how routes from
+// the pathfinder are built.
+func Compact(r *Route, toleranceBps uint64) (*Route, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	if toleranceBps >= 10_000 {
+		return nil, fmt.Errorf("%w: minOut tolerance %d bps", ErrInvalid, toleranceBps)
+	}
+	out := &Route{TokenIn: r.TokenIn, AmountIn: r.AmountIn, MinOut: r.MinOut, Steps: make([]Step, len(r.Steps))}
+	if out.MinOut == nil || out.MinOut.Sign() == 0 {
+		out.MinOut = r.Steps[len(r.Steps)-1].MinOut
+	}
+	for i, s := range r.Steps {
+		s.MinOut = nil
+		if i > 0 {
+			s.AmountIn = nil
+		}
+		out.Steps[i] = s
+	}
+	out.MinOut = RoundMinOut(out.MinOut, toleranceBps)
+	return out, nil
+}
+
+// RoundMinOut rounds minOut down to the value with the most trailing zero
+// bytes that loosens it by at most toleranceBps
+func RoundMinOut(minOut *big.Int, toleranceBps uint64) *big.Int {
+	if minOut == nil || minOut.Sign() == 0 {
+		return minOut
+	}
+	slack := new(big.Int).Mul(minOut, new(big.Int).SetUint64(toleranceBps))
+	floor := new(big.Int).Sub(minOut, slack.Quo(slack, big.NewInt(10_000)))
+	best := minOut
+	for bits := uint(8); bits < uint(minOut.BitLen()); bits += 8 {
+		rounded := new(big.Int).Rsh(minOut, bits)
+		rounded.Lsh(rounded, bits)
+		if rounded.Cmp(floor) < 0 {
+			break
+		}
+		best = rounded
+	}
+	return best
+}
+
+// CalldataGas returns the L1 gas of data: 4 per zero byte, 16 per other
+func CalldataGas(data []byte) uint64 {
+	var gas uint64
+	for _, b := range data {
+		if b == 0 {
+			gas += zeroByteGas
+		} else {
+			gas += nonZeroByteGas
+		}
+	}
+	return gas
+}
+
+// L1Fee prices calldata with the OP-stack Ecotone formula, using the values
+// the chain's GasPriceOracle reports
+type L1Fee struct {
+	BaseFee           *big.Int // L1 base fee, wei
+	BlobBaseFee       *big.Int // L1 blob base fee, wei
+	BaseFeeScalar     uint32
+	BlobBaseFeeScalar uint32
+}
+
+// Cost returns the L1 data fee in wei for posting data
+func (f L1Fee) Cost(data []byte) *big.Int {
+	price := new(big.Int)
+	if f.BaseFee != nil {
+		price.Mul(f.BaseFee, big.NewInt(16*int64(f.BaseFeeScalar)))
+	}
+	if f.BlobBaseFee != nil {
+		price.Add(price, new(big.Int).Mul(f.BlobBaseFee, big.NewInt(int64(f.BlobBaseFeeScalar))))
+	}
+	fee := new(big.Int).Mul(price, new(big.Int).SetUint64(CalldataGas(data)))
+	return fee.Quo(fee, big.NewInt(16_000_000))
+}
+
+// Savings compares the calldata cost of two encodings of one plan
+type Savings struct {
+	BytesBefore int      `json:"bytesBefore"`
+	BytesAfter  int      `json:"bytesAfter"`
+	GasBefore   uint64   `json:"gasBefore"`
+	GasAfter    uint64   `json:"gasAfter"`
+	FeeBefore   *big.Int `json:"feeBefore"`
+	FeeAfter    *big.Int `json:"feeAfter"`
+	// Saved is FeeBefore - FeeAfter in wei; negative when after costs more
+	Saved *big.Int `json:"saved"`
+}
+
+// Compare reports the L1 data fee of after against before
+func (f L1Fee) Compare(before, after []byte) *Savings {
+	s := &Savings{
+		BytesBefore: len(before),
+		BytesAfter:  len(after),
+		GasBefore:   CalldataGas(before),
+		GasAfter:    CalldataGas(after),
+		FeeBefore:   f.Cost(before),
+		FeeAfter:    f.Cost(after),
+	}
+	s.Saved = new(big.Int).Sub(s.FeeBefore, s.FeeAfter)
+	return s
+}
+
+// Optimize compacts r and reports the L1 data fee saved over its plain
+// compact encoding. It has no caller: the saving is on an encoding no
+// transaction carries.
+func Optimize(r *Route, toleranceBps uint64, fee L1Fee) ([]byte, *Savings, error) {
+	before, err := Encode(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	compact, err := Compact(r, toleranceBps)
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err := Encode(compact)
+	if err != nil {
+		return nil, nil, err
+	}
+	return after, fee.Compare(before, after), nil
+}
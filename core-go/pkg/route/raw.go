@@ -0,0 +1,124 @@
+package route
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// RawAddresses is the executor's RouteEncoding for explicit router and
+// token addresses (docs/CANONICAL_SPECIFICATION.md)
+const RawAddresses uint8 = 0
+
+var (
+	// (uint8 enc, uint8[] protocols, address[] routersOrPools, address[] tokenOutPath, bytes[] extra)
+	rawArgs = abi.Arguments{
+		{Type: mustType("uint8")},
+		{Type: mustType("uint8[]")},
+		{Type: mustType("address[]")},
+		{Type: mustType("address[]")},
+		{Type: mustType("bytes[]")},
+	}
+	v3ExtraArgs    = abi.Arguments{{Type: mustType("uint24")}}
+	curveExtraArgs = abi.Arguments{{Type: mustType("int128")}, {Type: mustType("int128")}}
+)
+
+// V3Extra returns a UniV3 step's extra data, abi.encode(uint24 fee), for a
+// fee in hundredths of a basis point (500, 3000, 10000)
+func V3Extra(fee uint32) []byte {
+	out, _ := v3ExtraArgs.Pack(new(big.Int).SetUint64(uint64(fee & 0xffffff)))
+	return out
+}
+
+// CurveExtra returns a Curve step's extra data, abi.encode(int128 i, int128 j)
+func CurveExtra(i, j int64) []byte {
+	out, _ := curveExtraArgs.Pack(big.NewInt(i), big.NewInt(j))
+	return out
+}
+
+// EncodeRaw returns r as the executor's RAW_ADDRESSES routeData. Each step's
+// routersOrPools entry is what the executor calls: the UniV2 router, the UniV3
+// SwapRouter, or for Curve the pool itself, so V2 and V3 steps must name their
+// Router and Curve steps must not. The encoding carries no amounts: the
+// loan amount is execute's argument, later hops spend the previous hop's
+// output, and the executor only requires the loan to be repaid, so r.MinOut
+// is enforced off-chain by simulation.
+func EncodeRaw(r *Route) ([]byte, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	n := len(r.Steps)
+	protocols := make([]uint8, n)
+	targets := make([]common.Address, n)
+	tokensOut := make([]common.Address, n)
+	extra := make([][]byte, n)
+	for i, s := range r.Steps {
+		if s.AmountIn != nil && s.AmountIn.Sign() != 0 && (i > 0 || r.AmountIn == nil || s.AmountIn.Cmp(r.AmountIn) != 0) {
+			return nil, fmt.Errorf("%w: step %d amountIn %s cannot be encoded as raw addresses", ErrInvalid, i, s.AmountIn)
+		}
+		switch s.Adapter {
+		case AdapterUniV2:
+			if len(s.Extra) != 0 {
+				return nil, fmt.Errorf("%w: univ2 step %d takes no extra data", ErrInvalid, i)
+			}
+		case AdapterUniV3:
+			if _, err := v3ExtraArgs.Unpack(s.Extra); err != nil || len(s.Extra) != 32 {
+				return nil, fmt.Errorf("%w: univ3 step %d needs abi.encode(uint24 fee)", ErrInvalid, i)
+			}
+		case AdapterCurve:
+			if _, err := curveExtraArgs.Unpack(s.Extra); err != nil || len(s.Extra) != 64 {
+				return nil, fmt.Errorf("%w: curve step %d needs abi.encode(int128 i, int128 j)", ErrInvalid, i)
+			}
+			if s.Router != (common.Address{}) {
+				return nil, fmt.Errorf("%w: curve step %d is called on its pool and takes no router", ErrInvalid, i)
+			}
+		default:
+			return nil, fmt.Errorf("%w: step %d uses %s", ErrInvalid, i, s.Adapter.Name())
+		}
+		if s.Target() == (common.Address{}) {
+			if s.Adapter == AdapterCurve {
+				return nil, fmt.Errorf("%w: curve step %d needs its pool", ErrInvalid, i)
+			}
+			return nil, fmt.Errorf("%w: %s step %d needs its router; the pool is not called", ErrInvalid, s.Adapter.Name(), i)
+		}
+		protocols[i] = uint8(s.Adapter)
+		targets[i] = s.Target()
+		tokensOut[i] = s.TokenOut
+		extra[i] = append([]byte{}, s.Extra...)
+	}
+	return rawArgs.Pack(RawAddresses, protocols, targets, tokensOut, extra)
+}
+
+// DecodeRaw parses RAW_ADDRESSES routeData borrowing tokenIn; the returned
+// route's amounts are unset
+func DecodeRaw(tokenIn common.Address, data []byte) (*Route, error) {
+	args, err := rawArgs.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if enc := args[0].(uint8); enc != RawAddresses {
+		return nil, fmt.Errorf("%w: route encoding %d", ErrVersion, enc)
+	}
+	protocols := args[1].([]uint8)
+	pools := args[2].([]common.Address)
+	tokensOut := args[3].([]common.Address)
+	extra := args[4].([][]byte)
+	if len(pools) != len(protocols) || len(tokensOut) != len(protocols) || len(extra) != len(protocols) {
+		return nil, fmt.Errorf("%w: misaligned route arrays", ErrInvalid)
+	}
+	r := &Route{TokenIn: tokenIn, Steps: make([]Step, len(protocols))}
+	for i := range protocols {
+		r.Steps[i] = Step{Adapter: Adapter(protocols[i]), TokenOut: tokensOut[i], Extra: extra[i]}
+		if r.Steps[i].Adapter == AdapterCurve {
+			r.Steps[i].Pool = pools[i]
+		} else {
+			r.Steps[i].Router = pools[i]
+		}
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
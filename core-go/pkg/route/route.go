@@ -0,0 +1,177 @@
+package route
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Version is the encoding version written by Encode
+const Version uint8 = 1
+
+// MaxSteps bounds the hops in one route; the executor rejects longer routes
+const MaxSteps = 8
+
+// MaxExtra bounds one step's adapter data
+const MaxExtra = 0xffff
+
+// Adapter selects the executor's swap adapter for a step. IDs match the
+// protocol IDs of the ABI route encodings.
+type Adapter uint8
+
+// Adapters understood by the executor
+const (
+	AdapterUniV2 Adapter = 1
+	AdapterUniV3 Adapter = 2
+	AdapterCurve Adapter = 3
+)
+
+// Name returns the adapter's display name
+func (a Adapter) Name() string {
+	switch a {
+	case AdapterUniV2:
+		return "univ2"
+	case AdapterUniV3:
+		return "univ3"
+	case AdapterCurve:
+		return "curve"
+	}
+	return fmt.Sprintf("adapter(%d)", uint8(a))
+}
+
+var (
+	// ErrVersion is returned when decoding a route of an unknown version
+	ErrVersion = errors.New("route: unsupported encoding version")
+	// ErrTruncated is returned when the encoding ends mid-field
+	ErrTruncated = errors.New("route: truncated encoding")
+	// ErrTrailingBytes is returned when bytes follow a complete route
+	ErrTrailingBytes = errors.New("route: trailing bytes")
+	// ErrNonCanonical is returned for amounts with leading zero bytes, so
+	// every route has exactly one encoding
+	ErrNonCanonical = errors.New("route: non-canonical amount")
+	// ErrInvalid is returned for a route that cannot be encoded
+	ErrInvalid = errors.New("route: invalid route")
+)
+
+// Step is one swap of the route. The executor calls a UniV2 or UniV3 step's
+// Router (the V2 router or the V3 SwapRouter) and a Curve step's Pool; a V2
+// or V3 step's Pool only records the pair or pool it was priced against.
+type Step struct {
+	Adapter  Adapter        `json:"adapter"`
+	Router   common.Address `json:"router,omitempty"`
+	Pool     common.Address `json:"pool"`
+	TokenOut common.Address `json:"tokenOut"`
+	// AmountIn is the step's input; zero spends the previous step's whole
+	// output, which is how hops after the first are normally encoded
+	AmountIn *big.Int      `json:"amountIn"`
+	MinOut   *big.Int      `json:"minOut"`
+	Extra    hexutil.Bytes `json:"extra,omitempty"` // adapter data, e.g. a V3 fee tier
+}
+
+// Target returns the contract the executor calls for the step: its routersOrPools entry
+func (s Step) Target() common.Address {
+	if s.Adapter == AdapterCurve {
+		return s.Pool
+	}
+	return s.Router
+}
+
+// Route is a sequence of swaps starting from AmountIn of TokenIn
+type Route struct {
+	TokenIn  common.Address `json:"tokenIn"`
+	AmountIn *big.Int       `json:"amountIn"`
+	Steps    []Step         `json:"steps"`
+	// MinOut is the least the final step may return, checked after the last swap
+	MinOut *big.Int `json:"minOut"`
+}
+
+// Validate reports whether the route can be encoded
+func (r *Route) Validate() error {
+	if len(r.Steps) == 0 || len(r.Steps) > MaxSteps {
+		return fmt.Errorf("%w: %d steps, need 1 to %d", ErrInvalid, len(r.Steps), MaxSteps)
+	}
+	if err := checkAmount("amountIn", r.AmountIn); err != nil {
+		return err
+	}
+	if err := checkAmount("minOut", r.MinOut); err != nil {
+		return err
+	}
+	for i, s := range r.Steps {
+		if s.Adapter == 0 {
+			return fmt.Errorf("%w: step %d has no adapter", ErrInvalid, i)
+		}
+		if err := checkAmount(fmt.Sprintf("step %d amountIn", i), s.AmountIn); err != nil {
+			return err
+		}
+		if err := checkAmount(fmt.Sprintf("step %d minOut", i), s.MinOut); err != nil {
+			return err
+		}
+		if len(s.Extra) > MaxExtra {
+			return fmt.Errorf("%w: step %d extra is %d bytes", ErrInvalid, i, len(s.Extra))
+		}
+	}
+	return nil
+}
+
+// checkAmount accepts nil (encoded as zero) and values that fit in a uint256
+func checkAmount(name string, v *big.Int) error {
+	if v != nil && (v.Sign() < 0 || v.BitLen() > 256) {
+		return fmt.Errorf("%w: %s %s is not a uint256", ErrInvalid, name, v)
+	}
+	return nil
+}
+
+// ErrUnrepayable is returned for a route that cannot repay its flash loan
+var ErrUnrepayable = errors.New("route: cannot repay flash loan")
+
+// Scale returns a copy of r trading amountIn instead of r.AmountIn, with
+// step amounts scaled down in proportion and minimums scaled up, so a
+// resized route never accepts proportionally less
+func (r *Route) Scale(amountIn *big.Int) *Route {
+	out := *r
+	out.AmountIn = new(big.Int).Set(amountIn)
+	out.MinOut = scaleAmount(r.MinOut, amountIn, r.AmountIn, true)
+	out.Steps = make([]Step, len(r.Steps))
+	for i, s := range r.Steps {
+		s.AmountIn = scaleAmount(s.AmountIn, amountIn, r.AmountIn, false)
+		s.MinOut = scaleAmount(s.MinOut, amountIn, r.AmountIn, true)
+		out.Steps[i] = s
+	}
+	return &out
+}
+
+// scaleAmount returns v×num/den, rounded up when up is set; nil and zero
+// amounts stay as they are
+func scaleAmount(v, num, den *big.Int, up bool) *big.Int {
+	if v == nil || v.Sign() == 0 || den == nil || den.Sign() == 0 {
+		return v
+	}
+	scaled := new(big.Int).Mul(v, num)
+	q, m := scaled.QuoRem(scaled, den, new(big.Int))
+	if up && m.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
+
+// CheckRepayable reports whether r can repay a flash loan of its AmountIn
+// from source: it must end in TokenIn, and its MinOut must cover the
+// principal plus the lender's premium, so a route simulated to return MinOut
+// cannot lose principal
+func (r *Route) CheckRepayable(source FlashSource) error {
+	if len(r.Steps) == 0 || r.AmountIn == nil || r.AmountIn.Sign() == 0 {
+		return fmt.Errorf("%w: no amount to borrow", ErrUnrepayable)
+	}
+	if last := r.Steps[len(r.Steps)-1].TokenOut; last != r.TokenIn {
+		return fmt.Errorf("%w: route ends in %s, borrowed %s", ErrUnrepayable, last.Hex(), r.TokenIn.Hex())
+	}
+	owed := new(big.Int).Mul(r.AmountIn, new(big.Int).SetUint64(10000+source.PremiumBps()))
+	owed.Add(owed, big.NewInt(9999)).Quo(owed, big.NewInt(10000))
+	if r.MinOut == nil || r.MinOut.Cmp(owed) < 0 {
+		return fmt.Errorf("%w: minOut %s below the %s owed to %s", ErrUnrepayable, r.MinOut, owed, source.Name())
+	}
+	return nil
+}
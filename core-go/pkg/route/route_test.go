@@ -0,0 +1,287 @@
+package route
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// vectors pin the compact encoding; a change here is a change to the stored
+// format and needs a new Version
+type vectors struct {
+	Valid []struct {
+		Name  string        `json:"name"`
+		Hex   hexutil.Bytes `json:"hex"`
+		Route Route         `json:"route"`
+	} `json:"valid"`
+	Invalid []struct {
+		Name  string        `json:"name"`
+		Hex   hexutil.Bytes `json:"hex"`
+		Error string        `json:"error"`
+	} `json:"invalid"`
+}
+
+var vectorErrors = map[string]error{
+	"version":      ErrVersion,
+	"truncated":    ErrTruncated,
+	"trailing":     ErrTrailingBytes,
+	"noncanonical": ErrNonCanonical,
+	"invalid":      ErrInvalid,
+}
+
+func loadVectors(t *testing.T) vectors {
+	data, err := os.ReadFile("testdata/vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v vectors
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestVectorsEncode(t *testing.T) {
+	for _, v := range loadVectors(t).Valid {
+		got, err := Encode(&v.Route)
+		if err != nil {
+			t.Errorf("%s: Encode failed: %v", v.Name, err)
+			continue
+		}
+		if !bytes.Equal(got, v.Hex) {
+			t.Errorf("%s: Expected %x, got %x", v.Name, []byte(v.Hex), got)
+		}
+	}
+}
+
+func TestVectorsDecode(t *testing.T) {
+	v := loadVectors(t)
+	for _, c := range v.Valid {
+		got, err := Decode(c.Hex)
+		if err != nil {
+			t.Errorf("%s: Decode failed: %v", c.Name, err)
+			continue
+		}
+		want, _ := json.Marshal(c.Route)
+		have, _ := json.Marshal(got)
+		if !bytes.Equal(want, have) {
+			t.Errorf("%s: Expected %s, got %s", c.Name, want, have)
+		}
+	}
+	for _, c := range v.Invalid {
+		if _, err := Decode(c.Hex); !errors.Is(err, vectorErrors[c.Error]) {
+			t.Errorf("%s: Expected %s error, got %v", c.Name, c.Error, err)
+		}
+	}
+}
+
+func TestEncodeRejectsInvalidRoutes(t *testing.T) {
+	tooBig := new(big.Int).Lsh(big.NewInt(1), 256)
+	cases := map[string]*Route{
+		"no steps":       {AmountIn: big.NewInt(1)},
+		"no adapter":     {AmountIn: big.NewInt(1), Steps: []Step{{}}},
+		"negative":       {AmountIn: big.NewInt(-1), Steps: []Step{{Adapter: AdapterUniV2}}},
+		"over uint256":   {AmountIn: big.NewInt(1), Steps: []Step{{Adapter: AdapterUniV2, MinOut: tooBig}}},
+		"too many steps": {AmountIn: big.NewInt(1), Steps: make([]Step, MaxSteps+1)},
+	}
+	for name, r := range cases {
+		if _, err := Encode(r); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: Expected ErrInvalid, got %v", name, err)
+		}
+	}
+}
+
+func TestCompactShrinksCalldata(t *testing.T) {
+	minOut, _ := new(big.Int).SetString("1234567890123456789", 10)
+	r := &Route{
+		TokenIn:  common.HexToAddress("0x01"),
+		AmountIn: big.NewInt(1e18),
+		Steps: []Step{
+			{Adapter: AdapterUniV2, Pool: common.HexToAddress("0x02"), TokenOut: common.HexToAddress("0x03"), AmountIn: big.NewInt(1e18), MinOut: big.NewInt(5e17)},
+			{Adapter: AdapterUniV3, Pool: common.HexToAddress("0x04"), TokenOut: common.HexToAddress("0x01"), AmountIn: big.NewInt(5e17), MinOut: minOut, Extra: hexutil.Bytes{0x0b, 0xb8}},
+		},
+	}
+	fee := L1Fee{BaseFee: big.NewInt(20e9), BlobBaseFee: big.NewInt(1), BaseFeeScalar: 1368, BlobBaseFeeScalar: 810949}
+	data, savings, err := Optimize(r, 10, fee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Steps[1].AmountIn.Sign() != 0 || got.Steps[0].MinOut.Sign() != 0 || got.Steps[1].MinOut.Sign() != 0 {
+		t.Errorf("Expected later amountIn and step minOuts dropped, got %+v", got.Steps)
+	}
+	floor := new(big.Int).Sub(minOut, new(big.Int).Div(new(big.Int).Mul(minOut, big.NewInt(10)), big.NewInt(10_000)))
+	if got.MinOut.Cmp(minOut) > 0 || got.MinOut.Cmp(floor) < 0 {
+		t.Errorf("Expected minOut within 10 bps below %s, got %s", minOut, got.MinOut)
+	}
+	if savings.BytesAfter >= savings.BytesBefore || savings.GasAfter >= savings.GasBefore || savings.Saved.Sign() <= 0 {
+		t.Errorf("Expected smaller, cheaper calldata, got %+v", savings)
+	}
+	if r.Steps[1].AmountIn.Sign() == 0 {
+		t.Error("Expected the input route to be left untouched")
+	}
+}
+
+func TestRoundMinOut(t *testing.T) {
+	cases := []struct {
+		minOut, bps, want int64
+	}{
+		{0x123456, 0, 0x123456},
+		{0x123456, 100, 0x123400},
+		{0x123456, 5000, 0x120000},
+		{0xff, 5000, 0xff},
+	}
+	for _, c := range cases {
+		if got := RoundMinOut(big.NewInt(c.minOut), uint64(c.bps)); got.Int64() != c.want {
+			t.Errorf("RoundMinOut(%#x, %d): Expected %#x, got %#x", c.minOut, c.bps, c.want, got.Int64())
+		}
+	}
+	if got := CalldataGas([]byte{0, 1, 0, 2}); got != 40 {
+		t.Errorf("Expected 40 calldata gas, got %d", got)
+	}
+}
+
+func TestScaleKeepsProtection(t *testing.T) {
+	r := &Route{
+		TokenIn:  common.HexToAddress("0x01"),
+		AmountIn: big.NewInt(1000),
+		MinOut:   big.NewInt(1003),
+		Steps: []Step{
+			{Adapter: AdapterUniV2, TokenOut: common.HexToAddress("0x02"), AmountIn: big.NewInt(1000), MinOut: big.NewInt(501)},
+			{Adapter: AdapterUniV2, TokenOut: common.HexToAddress("0x01")},
+		},
+	}
+	got := r.Scale(big.NewInt(300))
+	if got.AmountIn.Int64() != 300 || got.Steps[0].AmountIn.Int64() != 300 {
+		t.Errorf("Expected amounts scaled to 300, got %s/%s", got.AmountIn, got.Steps[0].AmountIn)
+	}
+	if got.MinOut.Int64() != 301 || got.Steps[0].MinOut.Int64() != 151 {
+		t.Errorf("Expected minimums rounded up to 301/151, got %s/%s", got.MinOut, got.Steps[0].MinOut)
+	}
+	if got.Steps[1].AmountIn != nil || r.AmountIn.Int64() != 1000 {
+		t.Error("Expected unset amounts kept and the input route untouched")
+	}
+}
+
+func TestCheckRepayable(t *testing.T) {
+	r := &Route{
+		TokenIn:  common.HexToAddress("0x01"),
+		AmountIn: big.NewInt(1_000_000),
+		MinOut:   big.NewInt(1_000_100),
+		Steps:    []Step{{Adapter: AdapterUniV2, TokenOut: common.HexToAddress("0x01")}},
+	}
+	if err := r.CheckRepayable(FlashBalancer); err != nil {
+		t.Errorf("Expected a Balancer loan to be repayable, got %v", err)
+	}
+	if err := r.CheckRepayable(FlashAave); !errors.Is(err, ErrUnrepayable) {
+		t.Errorf("Expected minOut below Aave's 5 bps premium to fail, got %v", err)
+	}
+	r.Steps[0].TokenOut = common.HexToAddress("0x02")
+	if err := r.CheckRepayable(FlashBalancer); !errors.Is(err, ErrUnrepayable) {
+		t.Errorf("Expected a route ending in another token to fail, got %v", err)
+	}
+}
+
+func TestFlashSourceMatchesSpec(t *testing.T) {
+	// enum FlashSource { AaveV3, BalancerV3 }
+	if FlashAave != 0 || FlashBalancer != 1 {
+		t.Fatalf("Expected AaveV3=0 and BalancerV3=1, got aave=%d balancer=%d", FlashAave, FlashBalancer)
+	}
+	for source, want := range map[FlashSource]byte{FlashAave: 0, FlashBalancer: 1} {
+		data, err := (&ExecuteCall{Source: source, Amount: big.NewInt(1)}).Pack()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The first argument word ends in the source byte
+		if word := data[4:36]; word[31] != want || !bytes.Equal(word[:31], make([]byte, 31)) {
+			t.Errorf("Expected %s to encode as %d, got %x", source.Name(), want, word)
+		}
+	}
+}
+
+func TestExecuteCallRoundTrip(t *testing.T) {
+	r := &Route{
+		TokenIn:  common.HexToAddress("0x01"),
+		AmountIn: big.NewInt(1e18),
+		MinOut:   big.NewInt(1e18),
+		Steps:    []Step{{Adapter: AdapterUniV2, Router: common.HexToAddress("0x02"), TokenOut: common.HexToAddress("0x01")}},
+	}
+	data, err := EncodeExecute(FlashAave, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call, err := UnpackExecute(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if call.Source != FlashAave || call.Token != r.TokenIn || call.Amount.Cmp(r.AmountIn) != 0 {
+		t.Errorf("Expected an Aave loan of the route's input, got %+v", call)
+	}
+	if decoded, err := DecodeRaw(call.Token, call.RouteData); err != nil || decoded.Steps[0].Router != r.Steps[0].Router {
+		t.Errorf("Expected the route as RAW_ADDRESSES route data, got %v", err)
+	}
+	if _, err := UnpackExecute(data[1:]); !errors.Is(err, ErrNotExecute) {
+		t.Errorf("Expected ErrNotExecute, got %v", err)
+	}
+}
+
+func TestEncodeRawMatchesSpec(t *testing.T) {
+	weth, usdc := common.HexToAddress("0x01"), common.HexToAddress("0x0a")
+	r := &Route{
+		TokenIn:  weth,
+		AmountIn: big.NewInt(1e18),
+		Steps: []Step{
+			{Adapter: AdapterUniV2, Router: common.HexToAddress("0xa1"), Pool: common.HexToAddress("0xb1"), TokenOut: usdc, AmountIn: big.NewInt(1e18)},
+			{Adapter: AdapterUniV3, Router: common.HexToAddress("0xa2"), Pool: common.HexToAddress("0xb2"), TokenOut: usdc, Extra: V3Extra(3000)},
+			{Adapter: AdapterCurve, Pool: common.HexToAddress("0xc3"), TokenOut: weth, Extra: CurveExtra(1, 0)},
+		},
+	}
+	data, err := EncodeRaw(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// abi.encode(uint8(0), [1,2,3], [v2Router,v3Router,curvePool], [usdc,usdc,weth],
+	// [0x, abi.encode(uint24(3000)), abi.encode(int128(1), int128(0))])
+	want, err := rawArgs.Pack(uint8(0), []uint8{1, 2, 3},
+		[]common.Address{common.HexToAddress("0xa1"), common.HexToAddress("0xa2"), common.HexToAddress("0xc3")},
+		[]common.Address{usdc, usdc, weth},
+		[][]byte{{}, common.LeftPadBytes([]byte{0x0b, 0xb8}, 32), append(common.LeftPadBytes([]byte{1}, 32), make([]byte, 32)...)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("Expected the spec's RAW_ADDRESSES tuple, got %x", data)
+	}
+	decoded, err := DecodeRaw(weth, data)
+	if err != nil || len(decoded.Steps) != 3 || decoded.Steps[1].Adapter != AdapterUniV3 || decoded.Steps[0].TokenOut != usdc {
+		t.Errorf("Expected the route back, got %+v (%v)", decoded, err)
+	} else if decoded.Steps[0].Router != r.Steps[0].Router || decoded.Steps[2].Pool != r.Steps[2].Pool || decoded.Steps[2].Router != (common.Address{}) {
+		t.Errorf("Expected routers back for V2 and V3 and the pool for Curve, got %+v", decoded.Steps)
+	}
+
+	bad := map[string]Step{
+		"v3 without fee":     {Adapter: AdapterUniV3, Router: common.HexToAddress("0xa2"), TokenOut: weth},
+		"v2 with extra":      {Adapter: AdapterUniV2, Router: common.HexToAddress("0xa1"), TokenOut: weth, Extra: []byte{1}},
+		"later hop amount":   {Adapter: AdapterUniV2, Router: common.HexToAddress("0xa1"), TokenOut: weth, AmountIn: big.NewInt(1)},
+		"v2 pair only":       {Adapter: AdapterUniV2, Pool: common.HexToAddress("0xb1"), TokenOut: weth},
+		"v3 pool only":       {Adapter: AdapterUniV3, Pool: common.HexToAddress("0xb2"), TokenOut: weth, Extra: V3Extra(500)},
+		"curve with router":  {Adapter: AdapterCurve, Router: common.HexToAddress("0xa1"), Pool: common.HexToAddress("0xc3"), TokenOut: weth, Extra: CurveExtra(0, 1)},
+		"curve without pool": {Adapter: AdapterCurve, TokenOut: weth, Extra: CurveExtra(0, 1)},
+	}
+	for name, step := range bad {
+		broken := *r
+		broken.Steps = []Step{r.Steps[0], step}
+		if _, err := EncodeRaw(&broken); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected ErrInvalid, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+)
+
+// Capability is an optional RPC feature providers differ on
+type Capability string
+
+// Probed capabilities
+const (
+	CapFeeHistory    Capability = "eth_feeHistory"
+	CapTraceCall     Capability = "debug_traceCall"
+	CapCallBundle    Capability = "eth_callBundle"
+	CapBatch         Capability = "batch"
+	CapSubscriptions Capability = "subscriptions"
+)
+
+// Capabilities is the set of capabilities an endpoint was found to support.
+// A nil set was never probed and supports everything, leaving callers to
+// fail at runtime as they did before probing.
+type Capabilities map[Capability]bool
+
+// Supports reports whether c is in the set
+func (cs Capabilities) Supports(c Capability) bool {
+	return cs == nil || cs[c]
+}
+
+// String lists the supported capabilities, e.g. "batch, eth_feeHistory"
+func (cs Capabilities) String() string {
+	if cs == nil {
+		return "unprobed"
+	}
+	var names []string
+	for c, ok := range cs {
+		if ok {
+			names = append(names, string(c))
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Caller is the raw JSON-RPC access probing needs; *gethrpc.Client satisfies it
+type Caller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+	BatchCallContext(ctx context.Context, b []gethrpc.BatchElem) error
+	SupportsSubscriptions() bool
+	EthSubscribe(ctx context.Context, channel interface{}, args ...interface{}) (*gethrpc.ClientSubscription, error)
+}
+
+// Prober finds the capabilities of the endpoint at url
+type Prober func(ctx context.Context, url string) (Capabilities, error)
+
+// ProberFor returns a Prober dialing each endpoint over the transport cfg
+// sets for its provider
+func ProberFor(cfg *config.Config) Prober {
+	return func(ctx context.Context, url string) (Capabilities, error) {
+		client, err := Dial(ctx, cfg, url)
+		if err != nil {
+			return nil, err
+		}
+		defer client.Close()
+		return Probe(ctx, client), nil
+	}
+}
+
+// Probe asks c for each capability with a harmless request. debug_traceCall
+// and eth_callBundle count as supported when they exist, even if they reject
+// the probe's placeholder arguments.
+func Probe(ctx context.Context, c Caller) Capabilities {
+	cs := make(Capabilities)
+	var history map[string]interface{}
+	cs[CapFeeHistory] = c.CallContext(ctx, &history, "eth_feeHistory", "0x1", "latest", []float64{50}) == nil
+
+	call := map[string]interface{}{"to": "0x0000000000000000000000000000000000000000", "data": "0x"}
+	var frame map[string]interface{}
+	cs[CapTraceCall] = answered(c.CallContext(ctx, &frame, "debug_traceCall", call, "latest", map[string]string{"tracer": "callTracer"}))
+
+	var bundle map[string]interface{}
+	cs[CapCallBundle] = answered(c.CallContext(ctx, &bundle, "eth_callBundle", map[string]interface{}{"txs": []string{}, "blockNumber": "latest"}))
+
+	batch := []gethrpc.BatchElem{
+		{Method: "eth_chainId", Result: new(string)},
+		{Method: "eth_blockNumber", Result: new(string)},
+	}
+	cs[CapBatch] = c.BatchCallContext(ctx, batch) == nil && batch[0].Error == nil && batch[1].Error == nil
+
+	if c.SupportsSubscriptions() {
+		heads := make(chan map[string]interface{})
+		if sub, err := c.EthSubscribe(ctx, heads, "newHeads"); err == nil {
+			sub.Unsubscribe()
+			cs[CapSubscriptions] = true
+		}
+	}
+	return cs
+}
+
+// answered reports whether the endpoint has the method: it answered, or
+// refused the probe's arguments with a JSON-RPC error other than "method not
+// found" and its provider-specific variants. Transport failures count
+// against it, as the method can't be relied on either way.
+func answered(err error) bool {
+	if err == nil {
+		return true
+	}
+	var rpcErr gethrpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() == -32601 {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"method not found", "not supported", "unsupported method", "does not exist", "not available", "not whitelisted"} {
+		if strings.Contains(msg, s) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+)
+
+// meteredClient records every call against its provider tier, and its
+// latency and outcome in the provider's history
+type meteredClient struct {
+	chain.Client
+	meter    *Meter   // nil skips cost tracking
+	history  *History // nil skips performance history
+	chainID  uint64
+	provider string
+	tier     string
+}
+
+var (
+	_ chain.Client           = (*meteredClient)(nil)
+	_ chain.FeeHistoryReader = (*meteredClient)(nil)
+)
+
+// errNoFeeHistory is returned by FeeHistory when the wrapped client can't read it
+var errNoFeeHistory = errors.New("rpc: client does not read fee history")
+
+// observe records a call to method that started at start and failed with
+// *err, if it did
+func (c *meteredClient) observe(method string, start time.Time, err *error) {
+	if c.meter != nil {
+		c.meter.Record(c.provider, c.tier, method)
+	}
+	c.history.Observe(c.chainID, c.provider, c.tier, time.Since(start), *err)
+}
+
+func (c *meteredClient) ChainID(ctx context.Context) (v *big.Int, err error) {
+	defer c.observe("eth_chainId", time.Now(), &err)
+	return c.Client.ChainID(ctx)
+}
+
+func (c *meteredClient) BlockNumber(ctx context.Context) (v uint64, err error) {
+	defer c.observe("eth_blockNumber", time.Now(), &err)
+	return c.Client.BlockNumber(ctx)
+}
+
+func (c *meteredClient) HeaderByNumber(ctx context.Context, number *big.Int) (v *types.Header, err error) {
+	defer c.observe("eth_getBlockByNumber", time.Now(), &err)
+	return c.Client.HeaderByNumber(ctx, number)
+}
+
+func (c *meteredClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) (v []byte, err error) {
+	defer c.observe("eth_call", time.Now(), &err)
+	return c.Client.CallContract(ctx, msg, blockNumber)
+}
+
+func (c *meteredClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) (v []byte, err error) {
+	defer c.observe("eth_getCode", time.Now(), &err)
+	return c.Client.CodeAt(ctx, contract, blockNumber)
+}
+
+func (c *meteredClient) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) (v []byte, err error) {
+	defer c.observe("eth_getStorageAt", time.Now(), &err)
+	return c.Client.StorageAt(ctx, account, key, blockNumber)
+}
+
+func (c *meteredClient) TransactionReceipt(ctx context.Context, hash common.Hash) (v *types.Receipt, err error) {
+	defer c.observe("eth_getTransactionReceipt", time.Now(), &err)
+	return c.Client.TransactionReceipt(ctx, hash)
+}
+
+func (c *meteredClient) TransactionByHash(ctx context.Context, hash common.Hash) (v *types.Transaction, w bool, err error) {
+	defer c.observe("eth_getTransactionByHash", time.Now(), &err)
+	return c.Client.TransactionByHash(ctx, hash)
+}
+
+func (c *meteredClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (v uint64, err error) {
+	defer c.observe("eth_getTransactionCount", time.Now(), &err)
+	return c.Client.NonceAt(ctx, account, blockNumber)
+}
+
+func (c *meteredClient) PendingNonceAt(ctx context.Context, account common.Address) (v uint64, err error) {
+	defer c.observe("eth_getTransactionCount", time.Now(), &err)
+	return c.Client.PendingNonceAt(ctx, account)
+}
+
+func (c *meteredClient) SuggestGasTipCap(ctx context.Context) (v *big.Int, err error) {
+	defer c.observe("eth_maxPriorityFeePerGas", time.Now(), &err)
+	return c.Client.SuggestGasTipCap(ctx)
+}
+
+func (c *meteredClient) EstimateGas(ctx context.Context, call ethereum.CallMsg) (v uint64, err error) {
+	defer c.observe("eth_estimateGas", time.Now(), &err)
+	return c.Client.EstimateGas(ctx, call)
+}
+
+func (c *meteredClient) SendTransaction(ctx context.Context, tx *types.Transaction) (err error) {
+	defer c.observe("eth_sendRawTransaction", time.Now(), &err)
+	return c.Client.SendTransaction(ctx, tx)
+}
+
+// FeeHistory passes through to a wrapped client that reads fee history
+func (c *meteredClient) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (v *ethereum.FeeHistory, err error) {
+	reader, ok := c.Client.(chain.FeeHistoryReader)
+	if !ok {
+		return nil, errNoFeeHistory
+	}
+	defer c.observe("eth_feeHistory", time.Now(), &err)
+	return reader.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+}
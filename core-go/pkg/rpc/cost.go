@@ -0,0 +1,159 @@
+package rpc
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// computeUnits estimates each method's cost in provider compute units,
+// following the weights paid plans commonly bill by; unknown methods
+// count as defaultComputeUnits
+var computeUnits = map[string]uint64{
+	"eth_chainId":               0,
+	"eth_blockNumber":           10,
+	"eth_getBlockByNumber":      16,
+	"eth_call":                  26,
+	"eth_getCode":               26,
+	"eth_getStorageAt":          17,
+	"eth_getTransactionReceipt": 15,
+	"eth_getTransactionByHash":  17,
+	"eth_getTransactionCount":   26,
+	"eth_maxPriorityFeePerGas":  10,
+	"eth_feeHistory":            10,
+	"eth_estimateGas":           87,
+	"eth_sendRawTransaction":    250,
+}
+
+const defaultComputeUnits = 20
+
+// month is the billing period monthly estimates are projected over
+const month = 30 * 24 * time.Hour
+
+// minProjectionWindow keeps the first minutes' bursts, such as startup
+// backfills, from projecting absurd monthly bills
+const minProjectionWindow = 10 * time.Minute
+
+// ComputeUnits returns the estimated compute units of one call to method
+func ComputeUnits(method string) uint64 {
+	if cu, ok := computeUnits[method]; ok {
+		return cu
+	}
+	return defaultComputeUnits
+}
+
+// ProviderName returns the host an RPC URL bills under, e.g.
+// polygon-mainnet.g.alchemy.com
+func ProviderName(rpcURL string) string {
+	u, err := url.Parse(rpcURL)
+	if err != nil || u.Hostname() == "" {
+		return "unknown"
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// Usage is one provider tier's traffic since the meter started
+type Usage struct {
+	Provider     string  `json:"provider"`
+	Tier         string  `json:"tier"`
+	Requests     uint64  `json:"requests"`
+	ComputeUnits uint64  `json:"computeUnits"`
+	MonthlyUSD   float64 `json:"monthlyUsd"` // projected from the rate so far
+}
+
+// Meter counts requests and compute units per provider and projects a
+// monthly bill from each provider's price per million compute units
+type Meter struct {
+	pricing map[string]float64 // host suffix to USD per million CU
+	started time.Time
+	now     func() time.Time
+
+	mu    sync.Mutex
+	usage map[[2]string]*Usage
+
+	requests *metrics.CounterVec
+	units    *metrics.CounterVec
+	monthly  *metrics.GaugeVec
+}
+
+// NewMeter creates a meter; pricing maps a provider host or host suffix
+// (alchemy.com) to USD per million compute units, and reg may be nil
+func NewMeter(pricing map[string]float64, reg *metrics.Registry) *Meter {
+	m := &Meter{pricing: pricing, started: time.Now(), now: time.Now, usage: make(map[[2]string]*Usage)}
+	if reg != nil {
+		m.requests = reg.Counter("titan_rpc_requests_total", "JSON-RPC requests by provider, tier and method", "provider", "tier", "method")
+		m.units = reg.Counter("titan_rpc_compute_units_total", "Estimated compute units by provider and tier", "provider", "tier")
+		m.monthly = reg.Gauge("titan_rpc_monthly_cost_usd", "Projected monthly cost at the current request rate", "provider", "tier")
+	}
+	return m
+}
+
+// Record counts one call to method on provider's tier
+func (m *Meter) Record(provider, tier, method string) {
+	cu := ComputeUnits(method)
+	m.mu.Lock()
+	key := [2]string{provider, tier}
+	u, ok := m.usage[key]
+	if !ok {
+		u = &Usage{Provider: provider, Tier: tier}
+		m.usage[key] = u
+	}
+	u.Requests++
+	u.ComputeUnits += cu
+	monthly := m.project(u)
+	m.mu.Unlock()
+
+	if m.requests != nil {
+		m.requests.Inc(provider, tier, method)
+		m.units.Add(float64(cu), provider, tier)
+		m.monthly.Set(monthly, provider, tier)
+	}
+}
+
+// Usage returns every provider tier's usage with monthly projections,
+// most expensive first
+func (m *Meter) Usage() []Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Usage, 0, len(m.usage))
+	for _, u := range m.usage {
+		c := *u
+		c.MonthlyUSD = m.project(u)
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].MonthlyUSD != out[j].MonthlyUSD {
+			return out[i].MonthlyUSD > out[j].MonthlyUSD
+		}
+		return out[i].Provider+out[i].Tier < out[j].Provider+out[j].Tier
+	})
+	return out
+}
+
+// project scales usage so far to a month at the same rate; the caller holds mu
+func (m *Meter) project(u *Usage) float64 {
+	price := m.price(u.Provider)
+	if price == 0 {
+		return 0
+	}
+	elapsed := m.now().Sub(m.started)
+	if elapsed < minProjectionWindow {
+		elapsed = minProjectionWindow
+	}
+	return float64(u.ComputeUnits) / 1e6 * price * float64(month) / float64(elapsed)
+}
+
+// price finds the longest pricing key the provider host ends with
+func (m *Meter) price(provider string) float64 {
+	best, price := -1, 0.0
+	for suffix, p := range m.pricing {
+		if (provider == suffix || strings.HasSuffix(provider, "."+suffix)) && len(suffix) > best {
+			best, price = len(suffix), p
+		}
+	}
+	return price
+}
@@ -0,0 +1,355 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/seal"
+)
+
+// Error types a provider call fails with
+const (
+	ErrorTimeout   = "timeout"
+	ErrorRateLimit = "rate_limit"
+	ErrorServer    = "server" // connection failures and HTTP errors
+	ErrorRPC       = "rpc"    // a JSON-RPC error answer, such as a missing header
+)
+
+// latencyBounds are the upper bounds of the latency buckets, in
+// milliseconds; calls slower than the last fall in a final overflow bucket
+var latencyBounds = []uint64{25, 50, 100, 250, 500, 1000, 2500, 5000}
+
+// ClassifyError names the type of a failed call, or "" for calls the
+// provider served: successes, and answers that only report an absent
+// receipt or a reverted call
+func ClassifyError(err error) string {
+	if err == nil || errors.Is(err, ethereum.NotFound) {
+		return ""
+	}
+	msg := strings.ToLower(err.Error())
+	var httpErr gethrpc.HTTPError
+	if errors.As(err, &httpErr) {
+		if httpErr.StatusCode == 429 {
+			return ErrorRateLimit
+		}
+		return ErrorServer
+	}
+	for _, s := range []string{"rate limit", "too many requests", "limit exceeded", "capacity"} {
+		if strings.Contains(msg, s) {
+			return ErrorRateLimit
+		}
+	}
+	var rpcErr gethrpc.Error
+	if errors.As(err, &rpcErr) {
+		if rpcErr.ErrorCode() == 3 || strings.Contains(msg, "revert") {
+			return ""
+		}
+		return ErrorRPC
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorTimeout
+	}
+	return ErrorServer
+}
+
+// Performance is one provider tier's record for a chain over a window
+type Performance struct {
+	ChainID     uint64            `json:"chainId"`
+	Provider    string            `json:"provider"`
+	Tier        string            `json:"tier"`
+	Start       time.Time         `json:"start"`
+	End         time.Time         `json:"end"`
+	Calls       uint64            `json:"calls"`
+	Errors      map[string]uint64 `json:"errors,omitempty"` // by error type
+	LatencyMs   uint64            `json:"latencyMs"`        // summed over every call
+	Buckets     []uint64          `json:"buckets"`          // calls by latencyBounds
+	UpMinutes   uint64            `json:"upMinutes"`        // minutes with a served call
+	DownMinutes uint64            `json:"downMinutes"`      // minutes whose every call failed
+}
+
+// Failures counts calls that failed with any error type
+func (p *Performance) Failures() uint64 {
+	var n uint64
+	for _, count := range p.Errors {
+		n += count
+	}
+	return n
+}
+
+// Uptime is the share of active minutes the provider served calls in; a
+// provider never called has no downtime
+func (p *Performance) Uptime() float64 {
+	if p.UpMinutes+p.DownMinutes == 0 {
+		return 1
+	}
+	return float64(p.UpMinutes) / float64(p.UpMinutes+p.DownMinutes)
+}
+
+// ErrorRate is the share of calls that failed
+func (p *Performance) ErrorRate() float64 {
+	if p.Calls == 0 {
+		return 0
+	}
+	return float64(p.Failures()) / float64(p.Calls)
+}
+
+// MeanLatency is the average call latency
+func (p *Performance) MeanLatency() time.Duration {
+	if p.Calls == 0 {
+		return 0
+	}
+	return time.Duration(p.LatencyMs/p.Calls) * time.Millisecond
+}
+
+// Quantile is the upper bound of the latency bucket holding the q-th
+// call, the last bound doubled for the overflow bucket
+func (p *Performance) Quantile(q float64) time.Duration {
+	if p.Calls == 0 {
+		return 0
+	}
+	rank := uint64(q*float64(p.Calls-1)) + 1
+	var seen uint64
+	for i, n := range p.Buckets {
+		if seen += n; seen >= rank {
+			if i < len(latencyBounds) {
+				return time.Duration(latencyBounds[i]) * time.Millisecond
+			}
+			break
+		}
+	}
+	return time.Duration(2*latencyBounds[len(latencyBounds)-1]) * time.Millisecond
+}
+
+// merge adds o's window into p
+func (p *Performance) merge(o *Performance) {
+	if p.Start.IsZero() || o.Start.Before(p.Start) {
+		p.Start = o.Start
+	}
+	if o.End.After(p.End) {
+		p.End = o.End
+	}
+	p.Calls += o.Calls
+	p.LatencyMs += o.LatencyMs
+	p.UpMinutes += o.UpMinutes
+	p.DownMinutes += o.DownMinutes
+	for t, n := range o.Errors {
+		if p.Errors == nil {
+			p.Errors = make(map[string]uint64)
+		}
+		p.Errors[t] += n
+	}
+	for len(p.Buckets) < len(o.Buckets) {
+		p.Buckets = append(p.Buckets, 0)
+	}
+	for i, n := range o.Buckets {
+		p.Buckets[i] += n
+	}
+}
+
+// window is a provider tier's open Performance and its current minute
+type window struct {
+	perf   Performance
+	minute int64 // unix minute of the calls below
+	served bool
+	failed bool
+}
+
+// closeMinute counts the current minute as up or down
+func (w *window) closeMinute() {
+	switch {
+	case w.served:
+		w.perf.UpMinutes++
+	case w.failed:
+		w.perf.DownMinutes++
+	}
+	w.served, w.failed = false, false
+}
+
+// History records every metered call's latency and outcome by chain and
+// provider tier, appending each hour's Performance to a store so provider
+// choices can rest on months of data rather than one process's uptime. A
+// nil history records nothing. It is safe for concurrent use.
+type History struct {
+	store *journal.Journal
+	now   func() time.Time
+
+	mu   sync.Mutex
+	open map[string]*window // by chain, provider and tier
+
+	errors *metrics.CounterVec
+}
+
+// NewHistory creates a history appending to store; reg may be nil
+func NewHistory(store *journal.Journal, reg *metrics.Registry) *History {
+	h := &History{store: store, now: time.Now, open: make(map[string]*window)}
+	if reg != nil {
+		h.errors = reg.Counter("titan_rpc_errors_total", "Failed JSON-RPC calls by provider, tier and error type", "provider", "tier", "type")
+	}
+	return h
+}
+
+// Observe records one call that took latency and returned err
+func (h *History) Observe(chainID uint64, provider, tier string, latency time.Duration, err error) {
+	if h == nil {
+		return
+	}
+	kind := ClassifyError(err)
+	now := h.now()
+	minute := now.Unix() / 60
+	ms := uint64(latency / time.Millisecond)
+	bucket := sort.Search(len(latencyBounds), func(i int) bool { return ms <= latencyBounds[i] })
+
+	h.mu.Lock()
+	key := fmt.Sprintf("%d %s %s", chainID, provider, tier)
+	w, ok := h.open[key]
+	if !ok {
+		w = &window{perf: Performance{ChainID: chainID, Provider: provider, Tier: tier, Start: now, Buckets: make([]uint64, len(latencyBounds)+1)}, minute: minute}
+		h.open[key] = w
+	}
+	if minute != w.minute {
+		w.closeMinute()
+		w.minute = minute
+	}
+	w.perf.Calls++
+	w.perf.LatencyMs += ms
+	w.perf.Buckets[bucket]++
+	w.perf.End = now
+	// JSON-RPC error answers still show the provider up
+	if kind == "" || kind == ErrorRPC {
+		w.served = true
+	} else {
+		w.failed = true
+	}
+	if kind != "" {
+		if w.perf.Errors == nil {
+			w.perf.Errors = make(map[string]uint64)
+		}
+		w.perf.Errors[kind]++
+	}
+	h.mu.Unlock()
+
+	if kind != "" && h.errors != nil {
+		h.errors.Inc(provider, tier, kind)
+	}
+}
+
+// Flush appends every open window to the store and starts new ones
+func (h *History) Flush() error {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	open := h.open
+	h.open = make(map[string]*window)
+	h.mu.Unlock()
+
+	keys := make([]string, 0, len(open))
+	for key := range open {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		w := open[key]
+		w.closeMinute()
+		if err := h.store.Append(journal.KindProvider, w.perf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run flushes at the top of every hour until ctx is done; flush once more
+// on shutdown to keep the last partial hour
+func (h *History) Run(ctx context.Context) {
+	if h == nil {
+		return
+	}
+	for {
+		now := time.Now().UTC()
+		timer := time.NewTimer(now.Truncate(time.Hour).Add(time.Hour).Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := h.Flush(); err != nil {
+			log.Printf("⚠️ Provider history: %v", err)
+		}
+	}
+}
+
+// ReadHistory returns the stored windows ending at or after since, oldest
+// first; a missing store has none
+func ReadHistory(path string, keys *seal.Keyring, since time.Time) ([]Performance, error) {
+	entries, err := journal.ReadSealed(path, journal.KindProvider, keys)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var out []Performance
+	for _, e := range entries {
+		var p Performance
+		if err := json.Unmarshal(e.Data, &p); err != nil {
+			return nil, fmt.Errorf("bad provider entry at %s: %w", e.Time, err)
+		}
+		if !p.End.Before(since) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// Rank merges windows by chain and provider tier and orders each chain's
+// providers best first: highest uptime, then lowest error rate, then lowest
+// 95th percentile latency
+func Rank(windows []Performance) []Performance {
+	merged := make(map[string]*Performance)
+	var keys []string
+	for i := range windows {
+		w := &windows[i]
+		key := strconv.FormatUint(w.ChainID, 10) + " " + w.Provider + " " + w.Tier
+		p, ok := merged[key]
+		if !ok {
+			p = &Performance{ChainID: w.ChainID, Provider: w.Provider, Tier: w.Tier}
+			merged[key] = p
+			keys = append(keys, key)
+		}
+		p.merge(w)
+	}
+	out := make([]Performance, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, *merged[key])
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := &out[i], &out[j]
+		if a.ChainID != b.ChainID {
+			return a.ChainID < b.ChainID
+		}
+		if a.Uptime() != b.Uptime() {
+			return a.Uptime() > b.Uptime()
+		}
+		if a.ErrorRate() != b.ErrorRate() {
+			return a.ErrorRate() < b.ErrorRate()
+		}
+		if a.Quantile(0.95) != b.Quantile(0.95) {
+			return a.Quantile(0.95) < b.Quantile(0.95)
+		}
+		return a.Provider+a.Tier < b.Provider+b.Tier
+	})
+	return out
+}
@@ -0,0 +1,208 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+)
+
+// latencyWeight is the share of each new measurement in an endpoint's
+// moving average round trip
+const latencyWeight = 0.3
+
+// Latency is a regional endpoint's measured round trip from this host
+type Latency struct {
+	ChainID  uint64    `json:"chainId"`
+	Region   string    `json:"region"` // empty for the chain's untagged RPC
+	Provider string    `json:"provider"`
+	RTTMs    float64   `json:"rttMs"`   // moving average of successful measurements
+	Healthy  bool      `json:"healthy"` // the last measurement succeeded
+	Selected bool      `json:"selected"`
+	Checked  time.Time `json:"checked"`
+
+	url string
+}
+
+// regional is one of a chain's endpoints and the region it is tagged with
+type regional struct {
+	region string
+	url    string
+}
+
+// regionals lists a chain's untagged RPC, then its regional endpoints by
+// region
+func regionals(c *config.ChainConfig) []regional {
+	var out []regional
+	if c.RPC != "" {
+		out = append(out, regional{url: c.RPC})
+	}
+	regions := make([]string, 0, len(c.RegionRPCs))
+	for region := range c.RegionRPCs {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	for _, region := range regions {
+		out = append(out, regional{region: region, url: c.RegionRPCs[region]})
+	}
+	return out
+}
+
+// local reports whether e serves this host's region; untagged endpoints
+// serve every region, and a host with no region is local to all of them
+func (r *Router) local(e regional) bool {
+	return r.cfg.Region == nil || r.cfg.Region.Region == "" || e.region == "" || e.region == r.cfg.Region.Region
+}
+
+// route picks the endpoint for a chain with regional endpoints: the fastest
+// healthy local one, else with failover the fastest healthy one anywhere.
+// Until one is measured healthy it is the first local endpoint.
+func (r *Router) route(chainID uint64, c *config.ChainConfig) string {
+	endpoints := regionals(c)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	url := r.fastest(chainID, endpoints, r.local)
+	if url == "" && r.cfg.Region != nil && r.cfg.Region.Failover {
+		url = r.fastest(chainID, endpoints, func(regional) bool { return true })
+	}
+	if url == "" {
+		url = endpoints[0].url
+		for _, e := range endpoints {
+			if r.local(e) {
+				url = e.url
+				break
+			}
+		}
+	}
+	if prev, ok := r.routed[chainID]; ok && prev != url {
+		log.Printf("🌍 Chain %d moved from %s to %s", chainID, ProviderName(prev), ProviderName(url))
+	}
+	r.routed[chainID] = url
+	return url
+}
+
+// fastest returns the eligible endpoint with the lowest healthy round trip,
+// "" when none is measured healthy
+func (r *Router) fastest(chainID uint64, endpoints []regional, eligible func(regional) bool) string {
+	best, rtt := "", math.Inf(1)
+	for _, e := range endpoints {
+		l := r.latency[fmt.Sprintf("%d %s", chainID, e.url)]
+		if l != nil && l.Healthy && eligible(e) && l.RTTMs < rtt {
+			best, rtt = e.url, l.RTTMs
+		}
+	}
+	return best
+}
+
+// Chains lists the chains among ids with regional endpoints, ascending
+func (r *Router) Chains(ids ...uint64) []uint64 {
+	var out []uint64
+	for _, id := range ids {
+		if c, ok := r.cfg.GetChain(id); ok && len(c.RegionRPCs) > 0 {
+			out = append(out, id)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// Run measures chains' endpoints every interval until ctx is done
+func (r *Router) Run(ctx context.Context, interval time.Duration, chains ...uint64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, chainID := range chains {
+			if err := r.Poll(ctx, chainID); err != nil && ctx.Err() == nil {
+				log.Printf("⚠️ Latency on chain %d: %v", chainID, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll measures the round trip to each of chainID's endpoints with
+// eth_blockNumber. A failed measurement marks the endpoint unhealthy until
+// one succeeds.
+func (r *Router) Poll(ctx context.Context, chainID uint64) error {
+	c, ok := r.cfg.GetChain(chainID)
+	if !ok || len(c.RegionRPCs) == 0 {
+		return fmt.Errorf("no regional endpoints for chain %d", chainID)
+	}
+	var failed []string
+	for _, e := range regionals(c) {
+		rtt, err := r.measure(ctx, chainID, e.url)
+		r.record(chainID, e, rtt, err)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%s): %v", ProviderName(e.url), e.region, err))
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// measure times one eth_blockNumber against url
+func (r *Router) measure(ctx context.Context, chainID uint64, url string) (time.Duration, error) {
+	client, err := r.connect(chainID, TierPrimary, url)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	start := time.Now()
+	_, err = client.BlockNumber(ctx)
+	return time.Since(start), err
+}
+
+// record folds a measurement into e's moving average
+func (r *Router) record(chainID uint64, e regional, rtt time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := fmt.Sprintf("%d %s", chainID, e.url)
+	l, ok := r.latency[key]
+	if !ok {
+		l = &Latency{ChainID: chainID, Region: e.region, Provider: ProviderName(e.url), url: e.url}
+		r.latency[key] = l
+	}
+	l.Healthy, l.Checked = err == nil, time.Now()
+	if err != nil {
+		return
+	}
+	ms := float64(rtt) / float64(time.Millisecond)
+	if l.RTTMs == 0 {
+		l.RTTMs = ms
+	} else {
+		l.RTTMs = latencyWeight*ms + (1-latencyWeight)*l.RTTMs
+	}
+}
+
+// Latencies lists every measured endpoint by chain and region, marking the
+// ones traffic is routed to
+func (r *Router) Latencies() []Latency {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Latency, 0, len(r.latency))
+	for _, l := range r.latency {
+		entry := *l
+		entry.Selected = r.routed[l.ChainID] == l.url
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ChainID != out[j].ChainID {
+			return out[i].ChainID < out[j].ChainID
+		}
+		return out[i].Region < out[j].Region
+	})
+	return out
+}
@@ -0,0 +1,191 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+)
+
+// Priority says how latency-sensitive a caller's traffic is
+type Priority int
+
+const (
+	// PriorityLow is discovery, backfills and housekeeping, sent to a
+	// chain's backfill endpoint when one is configured
+	PriorityLow Priority = iota
+	// PriorityHigh is quoting, simulation and submission, always sent to
+	// the chain's primary endpoint or, when it has regional ones, the
+	// fastest of those
+	PriorityHigh
+)
+
+// Tier labels
+const (
+	TierPrimary  = "primary"
+	TierBackfill = "backfill"
+)
+
+// probeTimeout bounds probing a new endpoint's capabilities
+const probeTimeout = 10 * time.Second
+
+// Router hands out metered node connections by chain and priority,
+// sharing one connection per endpoint. Chains with regional endpoints send
+// latency-sensitive traffic to the fastest one Run has measured near this
+// host.
+type Router struct {
+	cfg   *config.Config
+	dial  chain.Dialer
+	meter *Meter
+
+	// Probe, when set, finds each endpoint's capabilities as it is first
+	// connected; without it every endpoint is taken to support everything
+	Probe Prober
+	// History, when set, records every call's latency and outcome; set it
+	// before the first connection
+	History *History
+
+	mu      sync.Mutex
+	clients map[string]chain.Client // by tier and URL
+	caps    map[string]Endpoint     // by tier and URL
+	latency map[string]*Latency     // regional endpoints by chain and URL
+	routed  map[uint64]string       // each chain's current regional endpoint
+}
+
+// Endpoint is one connected endpoint's probed capabilities
+type Endpoint struct {
+	ChainID      uint64       `json:"chainId"`
+	Tier         string       `json:"tier"`
+	Provider     string       `json:"provider"`
+	Capabilities Capabilities `json:"capabilities"`
+}
+
+// NewRouter creates a router over cfg's chain endpoints; meter may be nil
+// to skip cost tracking
+func NewRouter(cfg *config.Config, dial chain.Dialer, meter *Meter) *Router {
+	return &Router{
+		cfg:     cfg,
+		dial:    dial,
+		meter:   meter,
+		clients: make(map[string]chain.Client),
+		caps:    make(map[string]Endpoint),
+		latency: make(map[string]*Latency),
+		routed:  make(map[uint64]string),
+	}
+}
+
+// endpoint returns the URL and tier serving chainID's traffic at priority
+func (r *Router) endpoint(chainID uint64, p Priority) (url, tier string, err error) {
+	c, ok := r.cfg.GetChain(chainID)
+	if !ok || (c.RPC == "" && len(c.RegionRPCs) == 0) {
+		return "", "", fmt.Errorf("no RPC configured for chain %d", chainID)
+	}
+	if p == PriorityLow && c.BackfillRPC != "" {
+		return c.BackfillRPC, TierBackfill, nil
+	}
+	if len(c.RegionRPCs) > 0 {
+		return r.route(chainID, c), TierPrimary, nil
+	}
+	return c.RPC, TierPrimary, nil
+}
+
+// Client returns the connection for chainID's traffic at priority
+func (r *Router) Client(chainID uint64, p Priority) (chain.Client, error) {
+	url, tier, err := r.endpoint(chainID, p)
+	if err != nil {
+		return nil, err
+	}
+	return r.connect(chainID, tier, url)
+}
+
+// connect returns the shared connection to url, dialing it on first use
+func (r *Router) connect(chainID uint64, tier, url string) (chain.Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := tier + " " + url
+	if client, ok := r.clients[key]; ok {
+		return client, nil
+	}
+	client, err := r.dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to chain %d (%s): %w", chainID, tier, err)
+	}
+	if r.meter != nil || r.History != nil {
+		client = &meteredClient{Client: client, meter: r.meter, history: r.History, chainID: chainID, provider: ProviderName(url), tier: tier}
+	}
+	r.clients[key] = client
+	if r.Probe != nil {
+		r.caps[key] = r.probe(chainID, tier, url)
+	}
+	return client, nil
+}
+
+// probe finds a new endpoint's capabilities; an endpoint that can't be
+// probed stays unprobed rather than being taken to support nothing
+func (r *Router) probe(chainID uint64, tier, url string) Endpoint {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	e := Endpoint{ChainID: chainID, Tier: tier, Provider: ProviderName(url)}
+	caps, err := r.Probe(ctx, url)
+	if err != nil {
+		log.Printf("⚠️ Probing %s (chain %d, %s): %v", e.Provider, chainID, tier, err)
+		return e
+	}
+	e.Capabilities = caps
+	log.Printf("🔌 %s (chain %d, %s) supports %s", e.Provider, chainID, tier, caps)
+	return e
+}
+
+// Capabilities returns the probed capabilities of chainID's endpoint at
+// priority, nil when it is unprobed or not yet connected
+func (r *Router) Capabilities(chainID uint64, p Priority) Capabilities {
+	url, tier, err := r.endpoint(chainID, p)
+	if err != nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.caps[tier+" "+url].Capabilities
+}
+
+// Supports reports whether chainID's endpoint at priority supports c; see
+// Capabilities.Supports
+func (r *Router) Supports(chainID uint64, p Priority, c Capability) bool {
+	return r.Capabilities(chainID, p).Supports(c)
+}
+
+// Endpoints lists every probed endpoint by chain and tier
+func (r *Router) Endpoints() []Endpoint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Endpoint, 0, len(r.caps))
+	for _, e := range r.caps {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ChainID != out[j].ChainID {
+			return out[i].ChainID < out[j].ChainID
+		}
+		return out[i].Tier < out[j].Tier
+	})
+	return out
+}
+
+// Meter returns the router's cost meter, nil when untracked
+func (r *Router) Meter() *Meter { return r.meter }
+
+// Close closes every connection
+func (r *Router) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, client := range r.clients {
+		client.Close()
+	}
+	r.clients = make(map[string]chain.Client)
+	r.caps = make(map[string]Endpoint)
+}
@@ -0,0 +1,391 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// fakeClient answers the calls the tests make, or fails them with err, and
+// remembers its URL
+type fakeClient struct {
+	chain.Client
+	url    string
+	err    error
+	closed bool
+}
+
+func (c *fakeClient) BlockNumber(context.Context) (uint64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	return 1, nil
+}
+func (c *fakeClient) CallContract(context.Context, ethereum.CallMsg, *big.Int) ([]byte, error) {
+	return nil, nil
+}
+func (c *fakeClient) Close() { c.closed = true }
+
+func TestRouterTiers(t *testing.T) {
+	cfg, err := config.NewBuilder().
+		AddChain(137, config.ChainConfig{Name: "polygon", RPC: "https://polygon-mainnet.g.alchemy.com/v2/key", BackfillRPC: "https://polygon-rpc.com"}).
+		AddChain(1, config.ChainConfig{Name: "ethereum", RPC: "https://eth.llamarpc.com"}).
+		AddChain(10, config.ChainConfig{Name: "optimism"}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dialed []*fakeClient
+	dial := func(url string) (chain.Client, error) {
+		c := &fakeClient{url: url}
+		dialed = append(dialed, c)
+		return c, nil
+	}
+	reg := metrics.NewRegistry()
+	router := NewRouter(cfg, dial, NewMeter(map[string]float64{"alchemy.com": 0.45}, reg))
+
+	high, _ := router.Client(137, PriorityHigh)
+	low, _ := router.Client(137, PriorityLow)
+	again, _ := router.Client(137, PriorityHigh)
+	eth, _ := router.Client(1, PriorityLow)
+	if high != again || len(dialed) != 3 {
+		t.Fatalf("Expected one connection per endpoint, dialed %d", len(dialed))
+	}
+	if dialed[0].url != cfg.Chains[137].RPC || dialed[1].url != "https://polygon-rpc.com" || dialed[2].url != "https://eth.llamarpc.com" {
+		t.Errorf("Expected premium, backfill, then primary fallback, got %s %s %s", dialed[0].url, dialed[1].url, dialed[2].url)
+	}
+	if _, err := router.Client(10, PriorityHigh); err == nil {
+		t.Error("Expected error for a chain without RPC")
+	}
+
+	high.BlockNumber(context.Background())
+	high.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	low.BlockNumber(context.Background())
+	eth.BlockNumber(context.Background())
+	if v := reg.Value("titan_rpc_compute_units_total", "polygon-mainnet.g.alchemy.com", TierPrimary); v != 36 {
+		t.Errorf("Expected 36 CU on the premium tier, got %v", v)
+	}
+	if v := reg.Value("titan_rpc_requests_total", "polygon-rpc.com", TierBackfill, "eth_blockNumber"); v != 1 {
+		t.Errorf("Expected one backfill request, got %v", v)
+	}
+
+	router.Close()
+	for _, c := range dialed {
+		if !c.closed {
+			t.Errorf("Expected %s closed", c.url)
+		}
+	}
+}
+
+func TestMeterProjectsMonthlyCost(t *testing.T) {
+	m := NewMeter(map[string]float64{"alchemy.com": 0.40, "g.alchemy.com": 0.50}, nil)
+	start := m.started
+	m.now = func() time.Time { return start.Add(time.Hour) }
+	for i := 0; i < 1000; i++ {
+		m.Record("polygon-mainnet.g.alchemy.com", TierPrimary, "eth_call")
+	}
+	m.Record("polygon-rpc.com", TierBackfill, "eth_getLogs")
+
+	usage := m.Usage()
+	if len(usage) != 2 || usage[0].Tier != TierPrimary {
+		t.Fatalf("Expected the priced tier first, got %+v", usage)
+	}
+	// 26,000 CU an hour is 18.72M CU over 30 days at the longer suffix's $0.50
+	if want := 18.72 * 0.50; math.Abs(usage[0].MonthlyUSD-want) > 1e-9 {
+		t.Errorf("Expected $%.2f a month, got $%.4f", want, usage[0].MonthlyUSD)
+	}
+	if usage[1].MonthlyUSD != 0 || usage[1].ComputeUnits != defaultComputeUnits {
+		t.Errorf("Expected an unpriced provider at the default CU, got %+v", usage[1])
+	}
+}
+
+// probeService is an eth namespace serving fee history, batches and
+// eth_callBundle, but no debug namespace or subscriptions
+type probeService struct{}
+
+func (probeService) ChainId() hexutil.Uint64     { return 1 }
+func (probeService) BlockNumber() hexutil.Uint64 { return 100 }
+func (probeService) FeeHistory(count hexutil.Uint64, last string, percentiles []float64) map[string]interface{} {
+	return map[string]interface{}{"oldestBlock": "0x64"}
+}
+func (probeService) CallBundle(args map[string]interface{}) (map[string]interface{}, error) {
+	return nil, errors.New("invalid bundle: no transactions")
+}
+
+func TestProbe(t *testing.T) {
+	server := gethrpc.NewServer()
+	if err := server.RegisterName("eth", probeService{}); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client := gethrpc.DialInProc(server)
+	defer client.Close()
+
+	caps := Probe(context.Background(), client)
+	for c, want := range map[Capability]bool{CapFeeHistory: true, CapCallBundle: true, CapBatch: true, CapTraceCall: false, CapSubscriptions: false} {
+		if caps.Supports(c) != want {
+			t.Errorf("Expected %s supported %v, got %v", c, want, !want)
+		}
+	}
+	if got := caps.String(); got != "batch, eth_callBundle, eth_feeHistory" {
+		t.Errorf("Expected the supported capabilities listed, got %q", got)
+	}
+	var unprobed Capabilities
+	if !unprobed.Supports(CapTraceCall) {
+		t.Errorf("Expected an unprobed endpoint to support everything")
+	}
+}
+
+func TestRouterProbesNewEndpoints(t *testing.T) {
+	cfg, err := config.NewBuilder().
+		AddChain(137, config.ChainConfig{Name: "polygon", RPC: "https://polygon-rpc.com", BackfillRPC: "https://polygon.llamarpc.com"}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewRouter(cfg, func(url string) (chain.Client, error) { return &fakeClient{url: url}, nil }, nil)
+	probes := 0
+	router.Probe = func(ctx context.Context, url string) (Capabilities, error) {
+		probes++
+		if url == "https://polygon.llamarpc.com" {
+			return nil, errors.New("timeout")
+		}
+		return Capabilities{CapFeeHistory: true}, nil
+	}
+
+	if !router.Supports(137, PriorityHigh, CapTraceCall) {
+		t.Errorf("Expected an unconnected endpoint treated as unprobed")
+	}
+	router.Client(137, PriorityHigh)
+	router.Client(137, PriorityHigh)
+	router.Client(137, PriorityLow)
+	if probes != 2 {
+		t.Errorf("Expected each endpoint probed once, got %d probes", probes)
+	}
+	if router.Supports(137, PriorityHigh, CapTraceCall) || !router.Supports(137, PriorityHigh, CapFeeHistory) {
+		t.Errorf("Expected the primary's probed capabilities, got %s", router.Capabilities(137, PriorityHigh))
+	}
+	if !router.Supports(137, PriorityLow, CapTraceCall) {
+		t.Errorf("Expected a failed probe to leave the backfill unprobed")
+	}
+	if e := router.Endpoints(); len(e) != 2 || e[0].Tier != TierBackfill || e[1].Provider != "polygon-rpc.com" {
+		t.Errorf("Expected both endpoints listed by tier, got %+v", e)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	for _, c := range []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{ethereum.NotFound, ""},
+		{gethrpc.HTTPError{StatusCode: 429}, ErrorRateLimit},
+		{gethrpc.HTTPError{StatusCode: 502}, ErrorServer},
+		{context.DeadlineExceeded, ErrorTimeout},
+		{errors.New("connection refused"), ErrorServer},
+		{errors.New("daily request limit exceeded"), ErrorRateLimit},
+	} {
+		if got := ClassifyError(c.err); got != c.want {
+			t.Errorf("Expected %v classified %q, got %q", c.err, c.want, got)
+		}
+	}
+}
+
+func TestHistoryRanksProviders(t *testing.T) {
+	dir := t.TempDir()
+	store, err := journal.Open(filepath.Join(dir, "providers.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	reg := metrics.NewRegistry()
+	h := NewHistory(store, reg)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+
+	// The fast provider serves every call; the flaky one times out for a whole minute
+	for i := 0; i < 3; i++ {
+		h.Observe(137, "fast.io", TierPrimary, 40*time.Millisecond, nil)
+		h.Observe(137, "flaky.io", TierPrimary, 20*time.Millisecond, nil)
+		now = now.Add(time.Minute)
+	}
+	h.Observe(137, "flaky.io", TierPrimary, 5*time.Second, context.DeadlineExceeded)
+	h.Observe(137, "flaky.io", TierPrimary, 5*time.Second, context.DeadlineExceeded)
+	if err := h.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Hour)
+	h.Observe(137, "fast.io", TierPrimary, 30*time.Millisecond, nil)
+	if err := h.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	windows, err := ReadHistory(store.Path(), nil, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 3 {
+		t.Fatalf("Expected 3 stored windows, got %d", len(windows))
+	}
+	ranked := Rank(windows)
+	if len(ranked) != 2 || ranked[0].Provider != "fast.io" {
+		t.Fatalf("Expected the fast provider first, got %+v", ranked)
+	}
+	fast, flaky := ranked[0], ranked[1]
+	if fast.Calls != 4 || fast.Uptime() != 1 || fast.Quantile(0.95) != 50*time.Millisecond {
+		t.Errorf("Expected 4 calls at full uptime under 50ms, got %+v", fast)
+	}
+	if flaky.UpMinutes != 3 || flaky.DownMinutes != 1 || flaky.Errors[ErrorTimeout] != 2 {
+		t.Errorf("Expected 3 minutes up, 1 down and 2 timeouts, got %+v", flaky)
+	}
+	if got := reg.Value("titan_rpc_errors_total", "flaky.io", TierPrimary, ErrorTimeout); got != 2 {
+		t.Errorf("Expected 2 timeouts counted, got %v", got)
+	}
+	if recent, _ := ReadHistory(store.Path(), nil, now.Add(-time.Minute)); len(recent) != 1 {
+		t.Errorf("Expected only the last window since a minute ago, got %d", len(recent))
+	}
+}
+
+func TestRouterRecordsHistory(t *testing.T) {
+	cfg, err := config.NewBuilder().AddChain(1, config.ChainConfig{Name: "ethereum", RPC: "https://eth.llamarpc.com"}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewRouter(cfg, func(url string) (chain.Client, error) { return &fakeClient{url: url}, nil }, nil)
+	router.History = NewHistory(nil, nil)
+	client, _ := router.Client(1, PriorityHigh)
+	client.BlockNumber(context.Background())
+	if w := router.History.open["1 eth.llamarpc.com primary"]; w == nil || w.perf.Calls != 1 {
+		t.Errorf("Expected the call recorded without a meter, got %+v", w)
+	}
+}
+
+func TestDialerSendsThroughProviderProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// An HTTP proxy is sent the absolute URL of the endpoint
+		proxied = append(proxied, r.URL.String())
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":"0x89"}`)
+	}))
+	defer proxy.Close()
+
+	cfg := &config.Config{ProviderTransport: map[string]*config.TransportConfig{
+		"provider.test": {DialTimeoutMs: 1000, ReadTimeoutMs: 1000, MaxIdleConns: 4, Proxy: proxy.URL},
+	}}
+	client, err := Dialer(cfg)("http://polygon.provider.test/v1/key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	id, err := client.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Uint64() != 137 || len(proxied) != 1 || proxied[0] != "http://polygon.provider.test/v1/key" {
+		t.Errorf("Expected chain 137 answered through the proxy, got %v via %v", id, proxied)
+	}
+}
+
+func TestHTTPClientSettings(t *testing.T) {
+	c, err := HTTPClient(config.TransportConfig{DialTimeoutMs: 500, ReadTimeoutMs: 2000, MaxIdleConns: 16, IdleTimeoutSecs: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := c.Transport.(*http.Transport)
+	if tr.MaxIdleConnsPerHost != 16 || tr.ResponseHeaderTimeout != 2*time.Second || tr.IdleConnTimeout != 30*time.Second {
+		t.Errorf("Expected the tuned pool and timeouts, got %+v", tr)
+	}
+	if tr.ForceAttemptHTTP2 || tr.TLSNextProto == nil {
+		t.Errorf("Expected HTTP/2 off")
+	}
+	if _, err := HTTPClient(config.TransportConfig{DialTimeoutMs: 500, Proxy: "://bad"}); err == nil {
+		t.Errorf("Expected a bad proxy URL rejected")
+	}
+}
+
+func TestRouterPrefersFastestLocalRegion(t *testing.T) {
+	cfg, err := config.NewBuilder().
+		AddChain(42161, config.ChainConfig{Name: "arbitrum", RPC: "https://arb1.arbitrum.io/rpc", RegionRPCs: map[string]string{
+			"us-east": "https://us-east.arb.example",
+			"eu-west": "https://eu-west.arb.example",
+		}}).
+		AddChain(1, config.ChainConfig{Name: "ethereum", RPC: "https://eth.llamarpc.com"}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Region = &config.RegionConfig{Region: "eu-west", ProbeSecs: 30}
+	down := map[string]bool{"https://eu-west.arb.example": true}
+	router := NewRouter(cfg, func(url string) (chain.Client, error) {
+		if down[url] {
+			return &fakeClient{url: url, err: errors.New("connection refused")}, nil
+		}
+		return &fakeClient{url: url}, nil
+	}, nil)
+	selected := func() string {
+		client, err := router.Client(42161, PriorityHigh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client.(*fakeClient).url
+	}
+
+	if got := router.Chains(1, 42161); len(got) != 1 || got[0] != 42161 {
+		t.Errorf("Expected only arbitrum to have regional endpoints, got %v", got)
+	}
+	if got := selected(); got != "https://arb1.arbitrum.io/rpc" {
+		t.Errorf("Expected the untagged RPC before any measurement, got %s", got)
+	}
+
+	untagged := regional{url: "https://arb1.arbitrum.io/rpc"}
+	euWest := regional{region: "eu-west", url: "https://eu-west.arb.example"}
+	usEast := regional{region: "us-east", url: "https://us-east.arb.example"}
+	router.record(42161, untagged, 80*time.Millisecond, nil)
+	router.record(42161, euWest, 20*time.Millisecond, nil)
+	router.record(42161, usEast, 5*time.Millisecond, nil)
+	if got := selected(); got != euWest.url {
+		t.Errorf("Expected the fastest local endpoint, got %s", got)
+	}
+
+	if err := router.Poll(context.Background(), 42161); err == nil || !strings.Contains(err.Error(), "eu-west.arb.example") {
+		t.Errorf("Expected the down endpoint reported, got %v", err)
+	}
+	if got := selected(); got != untagged.url {
+		t.Errorf("Expected the untagged RPC once eu-west is down, got %s", got)
+	}
+	router.record(42161, untagged, 0, errors.New("timeout"))
+	if got := selected(); got != untagged.url {
+		t.Errorf("Expected to stay local without failover, got %s", got)
+	}
+	cfg.Region.Failover = true
+	if got := selected(); got != usEast.url {
+		t.Errorf("Expected failover to us-east, got %s", got)
+	}
+
+	latencies := router.Latencies()
+	if len(latencies) != 3 || latencies[0].Region != "" || !latencies[2].Selected || latencies[1].Healthy {
+		t.Errorf("Expected every endpoint listed with us-east selected, got %+v", latencies)
+	}
+	if _, err := router.Client(1, PriorityHigh); err != nil {
+		t.Errorf("Expected chains without regions unaffected, got %v", err)
+	}
+}
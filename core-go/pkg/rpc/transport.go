@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+)
+
+// proxyFunc picks the proxy a transport sends through: its own, else the
+// standard proxy environment variables
+func proxyFunc(t config.TransportConfig) (func(*http.Request) (*url.URL, error), error) {
+	if t.Proxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	u, err := url.Parse(t.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	return http.ProxyURL(u), nil
+}
+
+// HTTPClient builds the HTTP client for a provider's transport settings
+func HTTPClient(t config.TransportConfig) (*http.Client, error) {
+	proxy, err := proxyFunc(t)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{Timeout: time.Duration(t.DialTimeoutMs) * time.Millisecond, KeepAlive: 30 * time.Second}
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   time.Duration(t.DialTimeoutMs) * time.Millisecond,
+		ResponseHeaderTimeout: time.Duration(t.ReadTimeoutMs) * time.Millisecond,
+		MaxIdleConns:          int(t.MaxIdleConns),
+		MaxIdleConnsPerHost:   int(t.MaxIdleConns),
+		IdleConnTimeout:       time.Duration(t.IdleTimeoutSecs) * time.Second,
+		ForceAttemptHTTP2:     t.HTTP2,
+	}
+	if !t.HTTP2 {
+		// A non-nil empty map keeps the transport from upgrading to HTTP/2
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// websocketDialer builds the websocket dialer for a provider's transport
+// settings
+func websocketDialer(t config.TransportConfig) (websocket.Dialer, error) {
+	proxy, err := proxyFunc(t)
+	if err != nil {
+		return websocket.Dialer{}, err
+	}
+	dialer := &net.Dialer{Timeout: time.Duration(t.DialTimeoutMs) * time.Millisecond, KeepAlive: 30 * time.Second}
+	return websocket.Dialer{
+		Proxy:            proxy,
+		NetDialContext:   dialer.DialContext,
+		HandshakeTimeout: time.Duration(t.DialTimeoutMs) * time.Millisecond,
+		ReadBufferSize:   1024,
+		WriteBufferSize:  1024,
+	}, nil
+}
+
+// Dial opens a JSON-RPC connection to rpcURL over the transport cfg sets
+// for its provider
+func Dial(ctx context.Context, cfg *config.Config, rpcURL string) (*gethrpc.Client, error) {
+	t := cfg.TransportFor(ProviderName(rpcURL))
+	httpClient, err := HTTPClient(t)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := websocketDialer(t)
+	if err != nil {
+		return nil, err
+	}
+	return gethrpc.DialOptions(ctx, rpcURL, gethrpc.WithHTTPClient(httpClient), gethrpc.WithWebsocketDialer(ws))
+}
+
+// Dialer returns a chain.Dialer connecting over cfg's transports, the
+// tuned replacement for chain.Dial
+func Dialer(cfg *config.Config) chain.Dialer {
+	return func(rpcURL string) (chain.Client, error) {
+		client, err := Dial(context.Background(), cfg, rpcURL)
+		if err != nil {
+			return nil, err
+		}
+		return ethclient.NewClient(client), nil
+	}
+}
@@ -0,0 +1,189 @@
+package scoring
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+)
+
+// ComponentComposite names the weighted mean of the stage scores
+const ComponentComposite = "pipeline_score"
+
+// Verdict is a stage's judgement of an opportunity
+type Verdict struct {
+	Score    float64        // from 0 to 1, higher is better
+	Category failure.Reason // set when the opportunity must not execute
+	Reason   string
+}
+
+// Pass is the verdict of a stage with nothing against an opportunity
+func Pass(score float64) Verdict { return Verdict{Score: score} }
+
+// Reject is the verdict of a stage refusing an opportunity
+func Reject(category failure.Reason, reason string) Verdict {
+	return Verdict{Category: category, Reason: reason}
+}
+
+// Stage judges an opportunity
+type Stage func(o *opportunity.Opportunity) Verdict
+
+// Pipeline scores opportunities through the enabled stages in their
+// configured order. Every stage runs, so experiments compare models on the
+// same traffic whatever the gates before them decided; the order sets which
+// stage's rejection is reported and which stages' scores later ones see, as
+// each is recorded on the explanation as it runs. A nil pipeline accepts
+// everything.
+type Pipeline struct {
+	stages   []config.ScoringStage
+	impl     []Stage
+	minScore float64
+
+	verdicts *metrics.CounterVec
+}
+
+// New creates a pipeline of the enabled stages, each implemented by the
+// stage of its name in impl; reg may be nil
+func New(stages []config.ScoringStage, minScore float64, impl map[string]Stage, reg *metrics.Registry) (*Pipeline, error) {
+	p := &Pipeline{minScore: minScore}
+	for _, stage := range stages {
+		if !stage.Enabled {
+			continue
+		}
+		fn, ok := impl[stage.Name]
+		if !ok {
+			return nil, fmt.Errorf("scoring stage %q is not available", stage.Name)
+		}
+		p.stages, p.impl = append(p.stages, stage), append(p.impl, fn)
+	}
+	if reg != nil {
+		p.verdicts = reg.Counter("titan_scoring_stage_total", "Opportunities judged by each scoring stage, by verdict", "stage", "verdict")
+	}
+	return p, nil
+}
+
+// Run scores o through every stage and reports whether it may execute: no
+// stage rejected it and the weighted mean of their scores reached the
+// minimum. The stages' verdicts and contributions are kept on the
+// explanation's breakdown.
+func (p *Pipeline) Run(o *opportunity.Opportunity) bool {
+	if p == nil || o.Explanation == nil {
+		return true
+	}
+	bd := &opportunity.ScoreBreakdown{MinScore: p.minScore}
+	var rejected *Verdict
+	var weights float64
+	for i, stage := range p.stages {
+		v := p.impl[i](o)
+		o.Explanation.Components = append(o.Explanation.Components, opportunity.ScoreComponent{Name: stage.Name, Value: v.Score, Weight: stage.Weight})
+		bd.Stages = append(bd.Stages, opportunity.StageScore{Stage: stage.Name, Score: v.Score, Weight: stage.Weight, Accept: v.Category == "", Reason: v.Reason})
+		weights += stage.Weight
+		verdict := opportunity.DecisionExecute
+		if v.Category != "" {
+			verdict = opportunity.DecisionReject
+			if rejected == nil {
+				rejected, bd.RejectedBy = &v, stage.Name
+			}
+		}
+		if p.verdicts != nil {
+			p.verdicts.Inc(stage.Name, verdict)
+		}
+	}
+	if weights > 0 {
+		for i := range bd.Stages {
+			st := &bd.Stages[i]
+			st.Contribution = st.Score * st.Weight / weights
+			bd.Score += st.Contribution
+		}
+		o.Explanation.Components = append(o.Explanation.Components, opportunity.ScoreComponent{Name: ComponentComposite, Value: bd.Score})
+		if rejected == nil && bd.Score < p.minScore {
+			rejected, bd.RejectedBy = &Verdict{Category: failure.ScoringReject, Reason: fmt.Sprintf("pipeline score %.2f below %.2f", bd.Score, p.minScore)}, ComponentComposite
+		}
+	}
+	o.Explanation.Breakdown = bd
+	if rejected != nil {
+		o.Explanation.Reject(rejected.Category, rejected.Reason)
+		return false
+	}
+	bd.Passed = true
+	return true
+}
+
+// Stages returns the enabled stages in order
+func (p *Pipeline) Stages() []config.ScoringStage {
+	if p == nil {
+		return nil
+	}
+	return append([]config.ScoringStage(nil), p.stages...)
+}
+
+// Set holds the process pipeline and those of strategy instances that
+// configure their own. A nil set accepts everything.
+type Set struct {
+	main       *Pipeline
+	strategies map[string]*Pipeline // by lower-case name
+
+	// Features, when set, supplies the model inputs recorded on each
+	// breakdown once the pipeline has run
+	Features func(o *opportunity.Opportunity) map[string]float64
+}
+
+// FromConfig creates the process pipeline and each strategy's own; reg may
+// be nil
+func FromConfig(cfg *config.Config, impl map[string]Stage, reg *metrics.Registry) (*Set, error) {
+	if cfg.Scoring == nil {
+		return nil, nil
+	}
+	main, err := New(cfg.Scoring.Stages, cfg.Scoring.MinScore, impl, reg)
+	if err != nil {
+		return nil, err
+	}
+	s := &Set{main: main, strategies: make(map[string]*Pipeline)}
+	for _, strategy := range cfg.Strategies {
+		if len(strategy.Scoring) == 0 {
+			continue
+		}
+		p, err := New(strategy.Scoring, cfg.Scoring.MinScore, impl, reg)
+		if err != nil {
+			return nil, fmt.Errorf("strategy %q: %w", strategy.Name, err)
+		}
+		s.strategies[strings.ToLower(strategy.Name)] = p
+	}
+	return s, nil
+}
+
+// For returns the pipeline that scores strategy's opportunities
+func (s *Set) For(strategy string) *Pipeline {
+	if s == nil {
+		return nil
+	}
+	if p, ok := s.strategies[strings.ToLower(strategy)]; ok {
+		return p
+	}
+	return s.main
+}
+
+// Run scores o through the pipeline of the strategy evaluating it
+func (s *Set) Run(o *opportunity.Opportunity) bool {
+	ok := s.For(o.Strategy).Run(o)
+	if s != nil && s.Features != nil && o.Explanation != nil && o.Explanation.Breakdown != nil {
+		o.Explanation.Breakdown.Features = s.Features(o)
+	}
+	return ok
+}
+
+// Pipelines returns each pipeline's stages by strategy, the process one
+// under config.StrategyMain
+func (s *Set) Pipelines() map[string][]config.ScoringStage {
+	if s == nil {
+		return nil
+	}
+	out := map[string][]config.ScoringStage{config.StrategyMain: s.main.Stages()}
+	for name, p := range s.strategies {
+		out[name] = p.Stages()
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/failure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+)
+
+// stages implements every stage, recording the order they run in
+func stages(ran *[]string, verdicts map[string]Verdict) map[string]Stage {
+	impl := make(map[string]Stage)
+	for _, name := range []string{config.ScoringFilters, config.ScoringTAR, config.ScoringML, config.ScoringRoutes, config.ScoringRisk} {
+		name := name
+		impl[name] = func(o *opportunity.Opportunity) Verdict {
+			*ran = append(*ran, name)
+			if v, ok := verdicts[name]; ok {
+				return v
+			}
+			return Pass(1)
+		}
+	}
+	return impl
+}
+
+func newOpportunity() *opportunity.Opportunity {
+	return &opportunity.Opportunity{Strategy: config.StrategyMain, Explanation: &opportunity.Explanation{Decision: opportunity.DecisionExecute}}
+}
+
+func component(o *opportunity.Opportunity, name string) (opportunity.ScoreComponent, bool) {
+	for _, c := range o.Explanation.Components {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return opportunity.ScoreComponent{}, false
+}
+
+func TestRunsStagesInOrder(t *testing.T) {
+	var ran []string
+	reg := metrics.NewRegistry()
+	p, err := New([]config.ScoringStage{
+		{Name: config.ScoringRisk, Enabled: true, Weight: 1},
+		{Name: config.ScoringML, Enabled: false, Weight: 1},
+		{Name: config.ScoringFilters, Enabled: true, Weight: 1},
+	}, 0, stages(&ran, map[string]Verdict{
+		config.ScoringRisk:    Reject(failure.GuardrailFloor, "turbulent"),
+		config.ScoringFilters: Reject(failure.GuardrailFloor, "net profit below minimum"),
+	}), reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := newOpportunity()
+	if p.Run(o) {
+		t.Fatalf("Expected a rejected opportunity")
+	}
+	if len(ran) != 2 || ran[0] != config.ScoringRisk || ran[1] != config.ScoringFilters {
+		t.Errorf("Expected risk then filters with ml skipped, got %v", ran)
+	}
+	if o.Explanation.Reason != "turbulent" || o.Explanation.Decision != opportunity.DecisionReject {
+		t.Errorf("Expected the first stage's rejection reported, got %q", o.Explanation.Reason)
+	}
+	if got := reg.Value("titan_scoring_stage_total", config.ScoringFilters, opportunity.DecisionReject); got != 1 {
+		t.Errorf("Expected the later rejection still counted, got %v", got)
+	}
+}
+
+func TestWeightedMinimum(t *testing.T) {
+	var ran []string
+	impl := stages(&ran, map[string]Verdict{config.ScoringTAR: Pass(0.2), config.ScoringRoutes: Pass(0.5)})
+	p, err := New([]config.ScoringStage{
+		{Name: config.ScoringFilters, Enabled: true, Weight: 0},
+		{Name: config.ScoringTAR, Enabled: true, Weight: 3},
+		{Name: config.ScoringRoutes, Enabled: true, Weight: 1},
+	}, 0.5, impl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := newOpportunity()
+	if p.Run(o) {
+		t.Errorf("Expected a weighted score of 0.275 rejected below 0.5")
+	}
+	if c, ok := component(o, ComponentComposite); !ok || c.Value != 0.275 {
+		t.Errorf("Expected the composite recorded, got %+v", c)
+	}
+	if c, ok := component(o, config.ScoringTAR); !ok || c.Weight != 3 || c.Value != 0.2 {
+		t.Errorf("Expected tar's score and weight recorded, got %+v", c)
+	}
+	if o.Explanation.Category != failure.ScoringReject {
+		t.Errorf("Expected a scoring rejection, got %s", o.Explanation.Category)
+	}
+	bd := o.Explanation.Breakdown
+	if bd == nil || bd.Passed || bd.RejectedBy != ComponentComposite || len(bd.Stages) != 3 {
+		t.Fatalf("Expected the breakdown to blame the composite, got %+v", bd)
+	}
+	if st := bd.Stages[1]; st.Stage != config.ScoringTAR || math.Abs(st.Contribution-0.15) > 1e-9 || !st.Accept {
+		t.Errorf("Expected tar to contribute 0.15, got %+v", st)
+	}
+
+	if _, err := New([]config.ScoringStage{{Name: "oracle", Enabled: true}}, 0, impl, nil); err == nil {
+		t.Errorf("Expected a stage without an implementation refused")
+	}
+}
+
+func TestStrategiesSkipStages(t *testing.T) {
+	var ran []string
+	cfg := &config.Config{
+		Scoring: &config.ScoringConfig{Stages: []config.ScoringStage{
+			{Name: config.ScoringFilters, Enabled: true, Weight: 1},
+			{Name: config.ScoringML, Enabled: true, Weight: 1},
+		}},
+		Strategies: []config.StrategyConfig{{Name: "Fast", Kind: config.StrategyKindBackrun, Scoring: []config.ScoringStage{
+			{Name: config.ScoringFilters, Enabled: true, Weight: 1},
+		}}},
+	}
+	s, err := FromConfig(cfg, stages(&ran, map[string]Verdict{config.ScoringML: Reject(failure.ScoringReject, "low")}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Run(newOpportunity()) {
+		t.Errorf("Expected the main pipeline's model to reject")
+	}
+	ran = nil
+	s.Features = func(o *opportunity.Opportunity) map[string]float64 { return map[string]float64{"gas_units": 210000} }
+	o := newOpportunity()
+	o.Strategy = "fast"
+	if !s.Run(o) || len(ran) != 1 {
+		t.Errorf("Expected fast to skip ml, ran %v", ran)
+	}
+	if bd := o.Explanation.Breakdown; !bd.Passed || bd.Features["gas_units"] != 210000 {
+		t.Errorf("Expected a passing breakdown with its features, got %+v", bd)
+	}
+	if got := s.Pipelines(); len(got) != 2 || len(got["fast"]) != 1 {
+		t.Errorf("Expected both pipelines described, got %+v", got)
+	}
+
+	var none *Set
+	if !none.Run(newOpportunity()) {
+		t.Errorf("Expected a nil set to accept")
+	}
+}
@@ -0,0 +1,169 @@
+package seal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix marks a sealed value
+const prefix = "sealed:v1:"
+
+// KeySize is the AES-256 key length in bytes
+const KeySize = 32
+
+var (
+	// ErrNoKey is returned when opening a value sealed under a key not in the keyring
+	ErrNoKey = errors.New("seal: no key to open this value")
+	// ErrCorrupt is returned when a sealed value is malformed or fails authentication
+	ErrCorrupt = errors.New("seal: value corrupt or tampered with")
+)
+
+// Keyring encrypts state at rest with operator-provided AES-256-GCM keys.
+// Sealed values are single-line text, so they fit in JSON-lines files and
+// environment variables alike:
+//
+//	sealed:v1:<key id>:<base64(nonce || ciphertext)>
+//
+// One primary key seals; older keys still open values sealed before a
+// rotation.
+type Keyring struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewKeyring creates a keyring sealing with primaryID; keys maps key IDs to
+// raw 32-byte keys
+func NewKeyring(primaryID string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[primaryID]; !ok {
+		return nil, fmt.Errorf("seal: primary key %q missing", primaryID)
+	}
+	k := &Keyring{primary: primaryID, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("seal: invalid key id %q", id)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("seal: key %s is %d bytes, want %d", id, len(key), KeySize)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.aeads[id] = aead
+	}
+	return k, nil
+}
+
+// ParseKeyring parses "id:base64key,..." as set in TITAN_STATE_KEYS. The
+// first key seals; the rest only open, for values sealed before a rotation.
+// An empty spec returns nil, leaving state in plaintext.
+func ParseKeyring(spec string) (*Keyring, error) {
+	var primary string
+	keys := make(map[string][]byte)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("seal: key entry %q: want id:base64key", item)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("seal: key %s: %w", id, err)
+		}
+		if _, dup := keys[id]; dup {
+			return nil, fmt.Errorf("seal: key %s listed twice", id)
+		}
+		if primary == "" {
+			primary = id
+		}
+		keys[id] = key
+	}
+	if primary == "" {
+		return nil, nil
+	}
+	return NewKeyring(primary, keys)
+}
+
+// GenerateKey returns a new random key, base64-encoded for TITAN_STATE_KEYS
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// Primary returns the ID of the key new values are sealed with
+func (k *Keyring) Primary() string {
+	if k == nil {
+		return ""
+	}
+	return k.primary
+}
+
+// Seal encrypts plaintext under the primary key; a nil keyring returns it
+// unchanged
+func (k *Keyring) Seal(plaintext []byte) ([]byte, error) {
+	if k == nil {
+		return plaintext, nil
+	}
+	aead := k.aeads[k.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	// The key ID is authenticated so a value can't be relabelled
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(k.primary))
+	return []byte(prefix + k.primary + ":" + base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// Open decrypts a sealed value; anything not sealed is returned unchanged,
+// so files written before encryption was enabled stay readable
+func (k *Keyring) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	id, encoded, ok := strings.Cut(string(data[len(prefix):]), ":")
+	if !ok {
+		return nil, ErrCorrupt
+	}
+	if k == nil {
+		return nil, fmt.Errorf("%w (sealed under %s; set TITAN_STATE_KEYS)", ErrNoKey, id)
+	}
+	aead, ok := k.aeads[id]
+	if !ok {
+		return nil, fmt.Errorf("%w (sealed under %s)", ErrNoKey, id)
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) < aead.NonceSize() {
+		return nil, ErrCorrupt
+	}
+	plaintext, err := aead.Open(nil, raw[:aead.NonceSize()], raw[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	return plaintext, nil
+}
+
+// OpenString is Open for text such as environment variables
+func (k *Keyring) OpenString(s string) (string, error) {
+	plaintext, err := k.Open([]byte(s))
+	return string(plaintext), err
+}
+
+// IsSealed reports whether data is a sealed value
+func IsSealed(data []byte) bool {
+	return len(data) > len(prefix) && string(data[:len(prefix)]) == prefix
+}
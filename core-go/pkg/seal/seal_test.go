@@ -0,0 +1,87 @@
+package seal
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func testKeyring(t *testing.T, spec string) *Keyring {
+	t.Helper()
+	k, err := ParseKeyring(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func newKey(t *testing.T) string {
+	t.Helper()
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestSealRoundTripAndRotation(t *testing.T) {
+	oldKey, newKeyB64 := newKey(t), newKey(t)
+	before := testKeyring(t, "a:"+oldKey)
+	sealed, err := before.Seal([]byte(`{"kind":"execution"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealed(sealed) || bytes.Contains(sealed, []byte("execution")) {
+		t.Fatalf("Expected an opaque sealed value, got %s", sealed)
+	}
+
+	// After rotation b seals and a still opens old values
+	after := testKeyring(t, "b:"+newKeyB64+", a:"+oldKey)
+	if after.Primary() != "b" {
+		t.Errorf("Expected b primary, got %s", after.Primary())
+	}
+	plain, err := after.Open(sealed)
+	if err != nil || string(plain) != `{"kind":"execution"}` {
+		t.Errorf("Expected the old value opened after rotation, got %s (%v)", plain, err)
+	}
+	resealed, _ := after.Seal(plain)
+	if _, err := before.Open(resealed); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Expected ErrNoKey opening with a keyring lacking b, got %v", err)
+	}
+	if _, err := (*Keyring)(nil).Open(resealed); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Expected ErrNoKey without keys, got %v", err)
+	}
+
+	// Plaintext passes through so pre-encryption files stay readable
+	if out, err := after.Open([]byte(`{"a":1}`)); err != nil || string(out) != `{"a":1}` {
+		t.Errorf("Expected plaintext unchanged, got %s (%v)", out, err)
+	}
+}
+
+func TestOpenRejectsTampering(t *testing.T) {
+	k := testKeyring(t, "a:"+newKey(t))
+	sealed, err := k.Seal([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	flipped := append([]byte{}, sealed...)
+	flipped[len(flipped)-3] ^= 1
+	if _, err := k.Open(flipped); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Expected ErrCorrupt for a flipped byte, got %v", err)
+	}
+	if _, err := k.Open(sealed[:len(sealed)-10]); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Expected ErrCorrupt for a torn value, got %v", err)
+	}
+}
+
+func TestParseKeyring(t *testing.T) {
+	if k, err := ParseKeyring(""); k != nil || err != nil {
+		t.Errorf("Expected no keyring for an empty spec, got %v, %v", k, err)
+	}
+	key := newKey(t)
+	for _, spec := range []string{"nokey", "a:not-base64!", "a:c2hvcnQ=", "a:" + key + ",a:" + key} {
+		if _, err := ParseKeyring(spec); err == nil {
+			t.Errorf("Expected %q rejected", spec)
+		}
+	}
+}
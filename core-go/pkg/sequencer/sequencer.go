@@ -0,0 +1,231 @@
+package sequencer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// uptimeABI is the slice of Chainlink's sequencer uptime feed read here
+const uptimeABI = `[
+{"inputs":[],"name":"latestRoundData","outputs":[{"name":"roundId","type":"uint80"},{"name":"answer","type":"int256"},{"name":"startedAt","type":"uint256"},{"name":"updatedAt","type":"uint256"},{"name":"answeredInRound","type":"uint80"}],"stateMutability":"view","type":"function"}
+]`
+
+var parsedUptimeABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(uptimeABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// Client reads a chain's uptime feed and head
+type Client interface {
+	ethereum.ContractCaller
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Dialer returns a client for a chain
+type Dialer func(chainID uint64) (Client, error)
+
+// Status is what a chain's sequencer health means for execution
+type Status struct {
+	ChainID uint64    `json:"chainId"`
+	Paused  bool      `json:"paused"`
+	Reason  string    `json:"reason,omitempty"` // why execution is paused
+	Since   time.Time `json:"since"`            // when the feed last changed state
+	Head    time.Time `json:"head"`             // the head block's timestamp
+	Checked time.Time `json:"checked"`
+}
+
+// Monitor polls each L2's Chainlink sequencer uptime feed and head block,
+// pausing execution while the sequencer is down, within the grace period
+// after it comes back, or not producing blocks. Chains never polled
+// successfully are not paused. A nil monitor pauses nothing. It is safe for
+// concurrent use.
+type Monitor struct {
+	feeds  map[uint64]common.Address
+	grace  time.Duration
+	maxLag time.Duration
+	dial   Dialer
+
+	// OnChange, when set, is called when a chain pauses or resumes
+	OnChange func(s Status)
+
+	mu     sync.Mutex
+	status map[uint64]Status
+
+	now func() time.Time
+	up  *metrics.GaugeVec
+}
+
+// New creates a monitor of the given feeds; reg may be nil
+func New(feeds map[uint64]common.Address, grace, maxLag time.Duration, dial Dialer, reg *metrics.Registry) *Monitor {
+	m := &Monitor{
+		feeds:  feeds,
+		grace:  grace,
+		maxLag: maxLag,
+		dial:   dial,
+		status: make(map[uint64]Status),
+		now:    time.Now,
+	}
+	if reg != nil {
+		m.up = reg.Gauge("titan_sequencer_up", "1 while a chain's sequencer is fit to execute against", "chain")
+	}
+	return m
+}
+
+// FromConfig creates a monitor of every chain with an uptime feed, or nil
+// when the monitor is off or no chain has one
+func FromConfig(cfg *config.Config, dial Dialer, reg *metrics.Registry) *Monitor {
+	if cfg.Sequencer == nil || !cfg.Sequencer.Enabled {
+		return nil
+	}
+	feeds := make(map[uint64]common.Address)
+	for chainID, chain := range cfg.Chains {
+		if chain.SequencerFeed != "" {
+			feeds[chainID] = common.HexToAddress(chain.SequencerFeed)
+		}
+	}
+	if len(feeds) == 0 {
+		return nil
+	}
+	s := cfg.Sequencer
+	return New(feeds, time.Duration(s.GraceSecs)*time.Second, time.Duration(s.MaxLagSecs)*time.Second, dial, reg)
+}
+
+// Chains lists the monitored chains among ids, ascending
+func (m *Monitor) Chains(ids ...uint64) []uint64 {
+	if m == nil {
+		return nil
+	}
+	var out []uint64
+	for _, id := range ids {
+		if _, ok := m.feeds[id]; ok {
+			out = append(out, id)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// Run polls chains every interval until ctx is done
+func (m *Monitor) Run(ctx context.Context, interval time.Duration, chains ...uint64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, chainID := range chains {
+			if err := m.Poll(ctx, chainID); err != nil && ctx.Err() == nil {
+				log.Printf("⚠️ Sequencer on chain %d: %v", chainID, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll reads chainID's uptime feed and head. A failed read keeps the last
+// status, so a flaky RPC neither pauses nor resumes the chain.
+func (m *Monitor) Poll(ctx context.Context, chainID uint64) error {
+	feed, ok := m.feeds[chainID]
+	if !ok {
+		return fmt.Errorf("no uptime feed for chain %d", chainID)
+	}
+	client, err := m.dial(chainID)
+	if err != nil {
+		return err
+	}
+	data, err := parsedUptimeABI.Pack("latestRoundData")
+	if err != nil {
+		return err
+	}
+	ret, err := client.CallContract(ctx, ethereum.CallMsg{To: &feed, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("uptime feed %s: %w", feed.Hex(), err)
+	}
+	out, err := parsedUptimeABI.Unpack("latestRoundData", ret)
+	if err != nil {
+		return fmt.Errorf("uptime feed %s: %w", feed.Hex(), err)
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	now := m.now()
+	answer, startedAt := out[1].(*big.Int), out[2].(*big.Int)
+	s := Status{
+		ChainID: chainID,
+		Since:   time.Unix(startedAt.Int64(), 0),
+		Head:    time.Unix(int64(head.Time), 0),
+		Checked: now,
+	}
+	// The feed answers 0 while the sequencer is up and 1 while it is down
+	switch up := now.Sub(s.Since); {
+	case answer.Sign() != 0:
+		s.Paused, s.Reason = true, fmt.Sprintf("sequencer down since %s", s.Since.UTC().Format(time.RFC3339))
+	case up < m.grace:
+		s.Paused, s.Reason = true, fmt.Sprintf("sequencer back %s ago, within the %s grace period", up.Round(time.Second), m.grace)
+	}
+	if lag := now.Sub(s.Head); !s.Paused && m.maxLag > 0 && lag > m.maxLag {
+		s.Paused, s.Reason = true, fmt.Sprintf("sequencer lagging: head block %d is %s old", head.Number, lag.Round(time.Second))
+	}
+
+	m.mu.Lock()
+	prev, seen := m.status[chainID]
+	m.status[chainID] = s
+	m.mu.Unlock()
+	if m.up != nil {
+		up := 1.0
+		if s.Paused {
+			up = 0
+		}
+		m.up.Set(up, strconv.FormatUint(chainID, 10))
+	}
+	if m.OnChange != nil && prev.Paused != s.Paused && (seen || s.Paused) {
+		m.OnChange(s)
+	}
+	return nil
+}
+
+// Halted returns why execution on chainID is paused, if it is
+func (m *Monitor) Halted(chainID uint64) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.status[chainID]
+	return s.Reason, s.Paused
+}
+
+// Statuses returns every polled chain's status, by chain
+func (m *Monitor) Statuses() []Status {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Status, 0, len(m.status))
+	for _, s := range m.status {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ChainID < out[j].ChainID })
+	return out
+}
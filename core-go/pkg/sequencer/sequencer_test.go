@@ -0,0 +1,158 @@
+package sequencer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+)
+
+// genesis is the titantest backend's genesis timestamp
+var genesis = time.Unix(1700000000, 0)
+
+// uptimeFeed serves a feed answering answer since startedAt
+func uptimeFeed(backend *titantest.Backend, feed common.Address, answer *int64, startedAt *time.Time) {
+	backend.Handle(feed, "latestRoundData()", func(ethereum.CallMsg) ([]byte, error) {
+		var out []byte
+		out = append(out, titantest.EncodeUint(big.NewInt(1))...)
+		out = append(out, titantest.EncodeUint(big.NewInt(*answer))...)
+		out = append(out, titantest.EncodeUint(big.NewInt(startedAt.Unix()))...)
+		out = append(out, titantest.EncodeUint(big.NewInt(startedAt.Unix()))...)
+		out = append(out, titantest.EncodeUint(big.NewInt(1))...)
+		return out, nil
+	})
+}
+
+func newMonitor(backend *titantest.Backend, feed common.Address, reg *metrics.Registry) *Monitor {
+	m := New(map[uint64]common.Address{42161: feed}, time.Hour, time.Minute, func(uint64) (Client, error) {
+		return backend, nil
+	}, reg)
+	m.now = func() time.Time { return genesis }
+	return m
+}
+
+func TestPausesWhileDownAndThroughGrace(t *testing.T) {
+	backend := titantest.NewBackend(42161)
+	feed := titantest.Address(0xfeed)
+	answer, startedAt := int64(1), genesis.Add(-10*time.Minute)
+	uptimeFeed(backend, feed, &answer, &startedAt)
+	reg := metrics.NewRegistry()
+	m := newMonitor(backend, feed, reg)
+	var changes []Status
+	m.OnChange = func(s Status) { changes = append(changes, s) }
+
+	if _, halted := m.Halted(42161); halted {
+		t.Errorf("Expected a chain never polled not paused")
+	}
+	if err := m.Poll(context.Background(), 42161); err != nil {
+		t.Fatal(err)
+	}
+	if reason, halted := m.Halted(42161); !halted || !strings.Contains(reason, "down") {
+		t.Errorf("Expected a down sequencer to pause, got %q", reason)
+	}
+	if got := reg.Value("titan_sequencer_up", "42161"); got != 0 {
+		t.Errorf("Expected the up gauge at 0, got %v", got)
+	}
+
+	answer, startedAt = 0, genesis.Add(-10*time.Minute)
+	if err := m.Poll(context.Background(), 42161); err != nil {
+		t.Fatal(err)
+	}
+	if reason, halted := m.Halted(42161); !halted || !strings.Contains(reason, "grace") {
+		t.Errorf("Expected a restarted sequencer paused through the grace period, got %q", reason)
+	}
+
+	startedAt = genesis.Add(-2 * time.Hour)
+	if err := m.Poll(context.Background(), 42161); err != nil {
+		t.Fatal(err)
+	}
+	if reason, halted := m.Halted(42161); halted {
+		t.Errorf("Expected a healthy sequencer to resume, got %q", reason)
+	}
+	if got := reg.Value("titan_sequencer_up", "42161"); got != 1 {
+		t.Errorf("Expected the up gauge at 1, got %v", got)
+	}
+	if len(changes) != 2 || !changes[0].Paused || changes[1].Paused {
+		t.Errorf("Expected a pause then a resume, got %+v", changes)
+	}
+}
+
+func TestPausesWhileHeadLags(t *testing.T) {
+	backend := titantest.NewBackend(42161)
+	feed := titantest.Address(0xfeed)
+	answer, startedAt := int64(0), genesis.Add(-24*time.Hour)
+	uptimeFeed(backend, feed, &answer, &startedAt)
+	m := newMonitor(backend, feed, nil)
+	m.now = func() time.Time { return genesis.Add(5 * time.Minute) }
+
+	if err := m.Poll(context.Background(), 42161); err != nil {
+		t.Fatal(err)
+	}
+	if reason, halted := m.Halted(42161); !halted || !strings.Contains(reason, "lagging") {
+		t.Errorf("Expected a stalled head to pause, got %q", reason)
+	}
+	backend.Mine(1, 5*time.Minute)
+	if err := m.Poll(context.Background(), 42161); err != nil {
+		t.Fatal(err)
+	}
+	if reason, halted := m.Halted(42161); halted {
+		t.Errorf("Expected a fresh head to resume, got %q", reason)
+	}
+}
+
+func TestFailedReadKeepsStatus(t *testing.T) {
+	backend := titantest.NewBackend(42161)
+	feed := titantest.Address(0xfeed)
+	answer, startedAt := int64(1), genesis
+	uptimeFeed(backend, feed, &answer, &startedAt)
+	m := newMonitor(backend, feed, nil)
+	if err := m.Poll(context.Background(), 42161); err != nil {
+		t.Fatal(err)
+	}
+	backend.CallErr = errors.New("rpc down")
+	if err := m.Poll(context.Background(), 42161); err == nil {
+		t.Errorf("Expected the read error returned")
+	}
+	if _, halted := m.Halted(42161); !halted {
+		t.Errorf("Expected the chain to stay paused")
+	}
+	if err := m.Poll(context.Background(), 10); err == nil {
+		t.Errorf("Expected an unmonitored chain refused")
+	}
+}
+
+func TestNilMonitor(t *testing.T) {
+	var m *Monitor
+	if _, halted := m.Halted(42161); halted {
+		t.Errorf("Expected a nil monitor to pause nothing")
+	}
+	if len(m.Statuses()) != 0 || len(m.Chains(42161)) != 0 {
+		t.Errorf("Expected a nil monitor to watch nothing")
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	cfg := &config.Config{
+		Chains: map[uint64]*config.ChainConfig{
+			1:     {Name: "ethereum"},
+			42161: {Name: "arbitrum", SequencerFeed: "0xFdB631F5EE196F0ed6FAa767959853A9F217697D"},
+		},
+		Sequencer: &config.SequencerConfig{Enabled: true, GraceSecs: 3600, PollSecs: 15},
+	}
+	m := FromConfig(cfg, nil, nil)
+	if got := m.Chains(1, 42161); len(got) != 1 || got[0] != 42161 {
+		t.Errorf("Expected only arbitrum monitored, got %v", got)
+	}
+	cfg.Sequencer.Enabled = false
+	if FromConfig(cfg, nil, nil) != nil {
+		t.Errorf("Expected no monitor when disabled")
+	}
+}
@@ -0,0 +1,119 @@
+package signals
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/holiman/uint256"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/bridge"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// GuardrailPump names the guardrail trending tokens are bound by
+const GuardrailPump = "pump_probability"
+
+// ErrTrending is returned for routes holding a token flagged as a pump
+var ErrTrending = errors.New("signals: token trending above the pump threshold")
+
+// Guard blocks or downsizes routes whose profit depends on holding a token
+// across blocks while the hub flags it as a pump, since a mid-pump price is
+// gone by the time the position is unwound. Atomic routes, such as backruns
+// that buy and sell in one transaction, don't hold anything and aren't
+// screened. A nil guard allows everything.
+type Guard struct {
+	hub     *Hub
+	action  string
+	sizeBps uint64
+
+	actions *metrics.CounterVec
+}
+
+// NewGuard creates a guard taking action on tokens hub gates; reg may be nil
+func NewGuard(hub *Hub, action string, sizeBps uint64, reg *metrics.Registry) *Guard {
+	g := &Guard{hub: hub, action: action, sizeBps: sizeBps}
+	if reg != nil {
+		g.actions = reg.Counter("titan_pump_guard_total", "Routes the pump guard blocked or downsized", "token", "action")
+	}
+	return g
+}
+
+// GuardFromConfig creates the configured guard, or nil when it is off or
+// there are no signals to act on
+func GuardFromConfig(cfg *config.PumpGuardConfig, hub *Hub, reg *metrics.Registry) *Guard {
+	if cfg == nil || cfg.Action == config.PumpGuardOff || hub == nil {
+		return nil
+	}
+	return NewGuard(hub, cfg.Action, cfg.SizeBps, reg)
+}
+
+// Check returns the share of its size a route holding token may trade at,
+// with the guardrail that bound it, or ErrTrending when the route is blocked
+func (g *Guard) Check(token tokens.Token) (uint64, *opportunity.Guardrail, error) {
+	if g == nil {
+		return 10000, nil, nil
+	}
+	s := g.hub.Token(token.Symbol)
+	if !s.Gated {
+		return 10000, nil, nil
+	}
+	guardrail := &opportunity.Guardrail{
+		Name:   GuardrailPump,
+		Limit:  fmt.Sprintf("< %.2f", g.hub.threshold),
+		Actual: fmt.Sprintf("%.2f (%d mentions, %.1fx)", s.PumpProbability, s.Mentions, s.Velocity),
+		Bound:  true,
+	}
+	if g.actions != nil {
+		g.actions.Inc(s.Token, g.action)
+	}
+	if g.action == config.PumpGuardDownsize {
+		return g.sizeBps, guardrail, nil
+	}
+	return 0, guardrail, fmt.Errorf("%w: %s at %.2f", ErrTrending, s.Token, s.PumpProbability)
+}
+
+// Screen vets a cross-chain plan; it has the signature of bridge.Tracker's
+// Screen. Only confirm-first plans hold the bridged token until it lands;
+// prepositioned ones fill both legs at once and keep their edge.
+func (g *Guard) Screen(p *bridge.Plan) error {
+	if g == nil || p.Policy != bridge.PolicyConfirmFirst {
+		return nil
+	}
+	bps := uint64(10000)
+	for i, token := range []tokens.Token{p.Transfer.FromToken, p.Transfer.ToToken} {
+		if i == 1 && strings.EqualFold(token.Symbol, p.Transfer.FromToken.Symbol) {
+			break
+		}
+		share, guardrail, err := g.Check(token)
+		if err != nil {
+			log.Printf("🚫 Plan %s blocked: %v", p.ID, err)
+			return err
+		}
+		if share < bps {
+			bps = share
+			log.Printf("📉 Plan %s downsized to %d bps: %s is %s", p.ID, bps, token.Symbol, guardrail.Actual)
+		}
+	}
+	if bps < 10000 {
+		p.Transfer.Amount = scale(p.Transfer.Amount, bps)
+		p.Source.Spend = scale(p.Source.Spend, bps)
+		p.Destination.Spend = scale(p.Destination.Spend, bps)
+		p.NotionalUSD = p.NotionalUSD * units.USD(bps) / 10000
+		p.ExpectedProfitUSD = p.ExpectedProfitUSD * units.USD(bps) / 10000
+	}
+	return nil
+}
+
+// scale returns bps of a
+func scale(a units.Amount, bps uint64) units.Amount {
+	if a.Value == nil {
+		return a
+	}
+	v := new(uint256.Int).Mul(a.Value, uint256.NewInt(bps))
+	return units.New(a.Token, v.Div(v, uint256.NewInt(10000)), a.Decimals)
+}
@@ -0,0 +1,87 @@
+package signals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/bridge"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// pumpedHub is a hub on which PEPE is trending
+func pumpedHub() *Hub {
+	h := New(nil, newRegistry(), []uint64{1}, time.Hour, 0.2, nil)
+	for i := 0; i < 20; i++ {
+		h.Ingest(context.Background(), Event{ID: fmt.Sprint(i), Source: SourceTwitter, Text: "$PEPE 100x gem, don't miss"})
+	}
+	return h
+}
+
+func pepes(t *testing.T, whole uint64) units.Amount {
+	a, err := units.FromWhole(pepe, whole, 18)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func pepePlan(t *testing.T, policy bridge.Policy) bridge.Plan {
+	pepeToken := tokens.Token{ChainID: 1, Symbol: "PEPE", Address: pepe, Decimals: 18}
+	amount := pepes(t, 1000)
+	return bridge.Plan{
+		ID: "p1", Policy: policy,
+		Transfer:    bridge.Request{FromChain: 1, ToChain: 42161, FromToken: pepeToken, ToToken: pepeToken, Amount: amount},
+		Source:      bridge.Leg{ChainID: 1, Spend: amount},
+		Destination: bridge.Leg{ChainID: 42161, Spend: amount},
+	}
+}
+
+func TestGuardBlocksTrendingTokens(t *testing.T) {
+	reg := metrics.NewRegistry()
+	g := GuardFromConfig(&config.PumpGuardConfig{Action: config.PumpGuardBlock}, pumpedHub(), reg)
+	tracker := bridge.NewTracker(bridge.PolicyConfirmFirst, nil)
+	tracker.Screen = g.Screen
+
+	if err := tracker.Open(pepePlan(t, "")); !errors.Is(err, ErrTrending) {
+		t.Errorf("Expected a confirm-first plan holding PEPE blocked, got %v", err)
+	}
+	if got := reg.Value("titan_pump_guard_total", "PEPE", config.PumpGuardBlock); got != 1 {
+		t.Errorf("Expected the block counted, got %v", got)
+	}
+	if bps, _, err := g.Check(tokens.Token{Symbol: "WETH"}); err != nil || bps != 10000 {
+		t.Errorf("Expected a quiet token left alone, got %d, %v", bps, err)
+	}
+	if err := g.Screen(&bridge.Plan{ID: "p2", Policy: bridge.PolicyPrepositioned, Transfer: pepePlan(t, "").Transfer}); err != nil {
+		t.Errorf("Expected a prepositioned plan, which holds nothing, allowed, got %v", err)
+	}
+}
+
+func TestGuardDownsizes(t *testing.T) {
+	g := GuardFromConfig(&config.PumpGuardConfig{Action: config.PumpGuardDownsize, SizeBps: 2500}, pumpedHub(), nil)
+	bps, guardrail, err := g.Check(tokens.Token{Symbol: "pepe"})
+	if err != nil || bps != 2500 || guardrail == nil || guardrail.Name != GuardrailPump || !guardrail.Bound {
+		t.Fatalf("Expected PEPE cut to 2500 bps, got %d, %+v, %v", bps, guardrail, err)
+	}
+
+	p := pepePlan(t, bridge.PolicyConfirmFirst)
+	p.NotionalUSD, p.ExpectedProfitUSD = units.DollarsToUSD(1000), units.DollarsToUSD(40)
+	if err := g.Screen(&p); err != nil {
+		t.Fatal(err)
+	}
+	quarter := pepes(t, 250)
+	if p.Transfer.Amount.Cmp(quarter) != 0 || p.Source.Spend.Cmp(quarter) != 0 || p.Destination.Spend.Cmp(quarter) != 0 {
+		t.Errorf("Expected every amount cut to 250 PEPE, got %s", p.Transfer.Amount)
+	}
+	if p.NotionalUSD != units.DollarsToUSD(250) || p.ExpectedProfitUSD != units.DollarsToUSD(10) {
+		t.Errorf("Expected the notional and profit cut with the size, got %s and %s", p.NotionalUSD, p.ExpectedProfitUSD)
+	}
+	if GuardFromConfig(&config.PumpGuardConfig{Action: config.PumpGuardOff}, pumpedHub(), nil) != nil {
+		t.Errorf("Expected no guard when off")
+	}
+}
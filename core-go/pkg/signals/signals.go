@@ -0,0 +1,350 @@
+package signals
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/hf"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+)
+
+// Signal sources
+const (
+	SourceRSS     = "rss"
+	SourceTwitter = "twitter"
+	SourceWebhook = "webhook"
+)
+
+// baseline is the lookback a token's usual mention rate is measured over
+const baseline = 24 * time.Hour
+
+// seenLimit bounds the event IDs remembered for deduplication
+const seenLimit = 10000
+
+var (
+	cashtag = regexp.MustCompile(`\$([A-Za-z][A-Za-z0-9]{1,9})\b`)
+	word    = regexp.MustCompile(`\b[A-Za-z][A-Za-z0-9]{2,9}\b`)
+	// hype are terms typical of coordinated pump calls
+	hype = regexp.MustCompile(`(?i)\b(100x|1000x|moon(ing)?|pump(ing)?|presale|gem|ape in|next \d+x|lambo|don'?t miss)\b`)
+)
+
+// Event is one normalized social or news item
+type Event struct {
+	ID        string        `json:"id"` // unique within the source
+	Source    string        `json:"source"`
+	Time      time.Time     `json:"time"`
+	Text      string        `json:"text"`
+	URL       string        `json:"url,omitempty"`
+	Tokens    []string      `json:"tokens"`              // upper-case symbols it mentions
+	Sentiment *hf.Sentiment `json:"sentiment,omitempty"` // when the HF endpoint scored it
+}
+
+// TokenSignal is a token's social activity over the window
+type TokenSignal struct {
+	Token           string    `json:"token"`
+	Mentions        int       `json:"mentions"`  // within the window
+	Velocity        float64   `json:"velocity"`  // mentions against the baseline rate
+	HypeShare       float64   `json:"hypeShare"` // share of mentions using pump language
+	PumpProbability float64   `json:"pumpProbability"`
+	Gated           bool      `json:"gated"` // at or above PUMP_PROBABILITY_THRESHOLD
+	LastSeen        time.Time `json:"lastSeen"`
+}
+
+type mention struct {
+	time time.Time
+	hype bool
+}
+
+// Hub normalizes social and news events, tags them with the tokens they
+// mention and keeps per-token activity, which scoring reads as features.
+// Tokens whose chatter looks like a pump are gated: their sentiment is
+// withheld from the features, since coordinated hype carries no information
+// about price, and only the pump probability is exposed. A nil hub has no
+// signals. It is safe for concurrent use.
+type Hub struct {
+	news      *hf.Board
+	registry  *tokens.Registry
+	chains    []uint64
+	window    time.Duration
+	threshold float64
+
+	mu       sync.Mutex
+	mentions map[string][]mention // by symbol, oldest first
+	seen     map[string]bool
+	order    []string // seen IDs, oldest first
+	recent   []Event
+
+	now    func() time.Time
+	events *metrics.CounterVec
+}
+
+// New creates a hub tagging symbols of tokens on chains and scoring
+// sentiment on news, which may be nil; reg may be nil
+func New(news *hf.Board, registry *tokens.Registry, chains []uint64, window time.Duration, pumpThreshold float64, reg *metrics.Registry) *Hub {
+	h := &Hub{
+		news:      news,
+		registry:  registry,
+		chains:    chains,
+		window:    window,
+		threshold: pumpThreshold,
+		mentions:  make(map[string][]mention),
+		seen:      make(map[string]bool),
+		now:       time.Now,
+	}
+	if reg != nil {
+		h.events = reg.Counter("titan_signal_events_total", "Social and news events ingested, by source and whether they mentioned a known token", "source", "tagged")
+	}
+	return h
+}
+
+// FromConfig creates the hub for the configured chains, or nil when
+// REAL_TIME_DATA_ENABLED is off
+func FromConfig(cfg *config.Config, news *hf.Board, registry *tokens.Registry, reg *metrics.Registry) *Hub {
+	if cfg.AI == nil || !cfg.AI.RealTimeDataEnabled || cfg.Signals == nil {
+		return nil
+	}
+	chains := make([]uint64, 0, len(cfg.Chains))
+	for id := range cfg.Chains {
+		chains = append(chains, id)
+	}
+	sort.Slice(chains, func(i, j int) bool { return chains[i] < chains[j] })
+	return New(news, registry, chains, time.Duration(cfg.Signals.WindowMins)*time.Minute, cfg.AI.PumpProbabilityThreshold, reg)
+}
+
+// Ingest tags e, counts its mentions and scores its sentiment; an event seen
+// before is ignored. It reports whether the event was new.
+func (h *Hub) Ingest(ctx context.Context, e Event) (Event, bool) {
+	if h == nil {
+		return e, false
+	}
+	if e.Time.IsZero() {
+		e.Time = h.now().UTC()
+	}
+	e.Tokens = h.tag(e.Text, e.Tokens)
+	key := e.Source + "/" + e.ID
+	h.mu.Lock()
+	if h.seen[key] {
+		h.mu.Unlock()
+		return e, false
+	}
+	h.remember(key)
+	h.mu.Unlock()
+
+	if h.events != nil {
+		h.events.Inc(e.Source, strconv.FormatBool(len(e.Tokens) > 0))
+	}
+	if len(e.Tokens) == 0 {
+		return e, true
+	}
+	// sentiment is best effort; mentions count without it
+	if s, err := h.news.Ingest(ctx, e.Tokens, e.Text); err == nil {
+		e.Sentiment = &s
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	m := mention{time: e.Time, hype: hype.MatchString(e.Text)}
+	for _, symbol := range e.Tokens {
+		// feeds deliver late items, so keep mentions in time order
+		ms := h.prune(symbol)
+		i := sort.Search(len(ms), func(i int) bool { return ms[i].time.After(m.time) })
+		ms = append(ms, mention{})
+		copy(ms[i+1:], ms[i:])
+		ms[i] = m
+		h.mentions[symbol] = h.pruned(ms)
+	}
+	h.recent = append(h.recent, e)
+	if len(h.recent) > 100 {
+		h.recent = h.recent[len(h.recent)-100:]
+	}
+	return e, true
+}
+
+// remember marks key seen, forgetting the oldest past seenLimit
+func (h *Hub) remember(key string) {
+	h.seen[key] = true
+	h.order = append(h.order, key)
+	if len(h.order) > seenLimit {
+		delete(h.seen, h.order[0])
+		h.order = h.order[1:]
+	}
+}
+
+// prune drops symbol's mentions older than the baseline
+func (h *Hub) prune(symbol string) []mention {
+	return h.pruned(h.mentions[symbol])
+}
+
+func (h *Hub) pruned(ms []mention) []mention {
+	cutoff := h.now().Add(-baseline)
+	i := sort.Search(len(ms), func(i int) bool { return ms[i].time.After(cutoff) })
+	return ms[i:]
+}
+
+// tag returns the known symbols text mentions as cashtags or words, with the
+// explicit ones, upper-cased and deduplicated
+func (h *Hub) tag(text string, explicit []string) []string {
+	known := make(map[string]bool)
+	for _, chainID := range h.chains {
+		for _, t := range h.registry.Chain(chainID) {
+			known[strings.ToUpper(t.Symbol)] = true
+		}
+	}
+	seen := make(map[string]bool)
+	var out []string
+	add := func(symbol string) {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol != "" && !seen[symbol] {
+			seen[symbol] = true
+			out = append(out, symbol)
+		}
+	}
+	for _, symbol := range explicit {
+		add(symbol)
+	}
+	for _, m := range cashtag.FindAllStringSubmatch(text, -1) {
+		if known[strings.ToUpper(m[1])] {
+			add(m[1])
+		}
+	}
+	// bare words only count in upper case, so "eth" in prose isn't a mention
+	for _, w := range word.FindAllString(text, -1) {
+		if w == strings.ToUpper(w) && known[w] {
+			add(w)
+		}
+	}
+	return out
+}
+
+// Token returns symbol's activity over the window
+func (h *Hub) Token(symbol string) TokenSignal {
+	if h == nil {
+		return TokenSignal{Token: symbol}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.token(strings.ToUpper(symbol))
+}
+
+// token scores symbol's activity. Pump probability is a logistic blend of
+// how far mentions run above their baseline rate and how much of the
+// chatter uses pump language; it is a screen, not a calibrated model.
+func (h *Hub) token(symbol string) TokenSignal {
+	ms := h.prune(symbol)
+	s := TokenSignal{Token: symbol}
+	if len(ms) == 0 {
+		delete(h.mentions, symbol)
+		return s
+	}
+	h.mentions[symbol] = ms
+	cutoff := h.now().Add(-h.window)
+	var hyped int
+	for _, m := range ms {
+		if m.time.After(cutoff) {
+			s.Mentions++
+			if m.hype {
+				hyped++
+			}
+		}
+	}
+	s.LastSeen = ms[len(ms)-1].time
+	if s.Mentions == 0 {
+		return s
+	}
+	// the rate before the window, in mentions per window, floored at one so a
+	// token's first mentions aren't an infinite spike
+	before := float64(len(ms)-s.Mentions) * float64(h.window) / float64(baseline-h.window)
+	s.Velocity = float64(s.Mentions) / math.Max(before, 1)
+	s.HypeShare = float64(hyped) / float64(s.Mentions)
+	s.PumpProbability = 1 / (1 + math.Exp(-(1.5*math.Log(s.Velocity) + 3*s.HypeShare - 4)))
+	s.Gated = s.PumpProbability >= h.threshold
+	return s
+}
+
+// Tokens returns the activity of every token mentioned within the window,
+// most mentioned first
+func (h *Hub) Tokens() []TokenSignal {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var out []TokenSignal
+	for symbol := range h.mentions {
+		if s := h.token(symbol); s.Mentions > 0 {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Mentions != out[j].Mentions {
+			return out[i].Mentions > out[j].Mentions
+		}
+		return out[i].Token < out[j].Token
+	})
+	return out
+}
+
+// Recent returns the latest tagged events, newest first
+func (h *Hub) Recent() []Event {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]Event, len(h.recent))
+	for i, e := range h.recent {
+		out[len(out)-1-i] = e
+	}
+	return out
+}
+
+// Features returns the social features of the tokens o trades: mentions,
+// velocity and pump probability of the most pumped token, and the news
+// sentiment unless that token is gated; it has the signature of
+// experiment.Experiment's Signals
+func (h *Hub) Features(o *opportunity.Opportunity) map[string]float64 {
+	if h == nil || o.Explanation == nil {
+		return nil
+	}
+	var top TokenSignal
+	var mentions int
+	seen := make(map[string]bool)
+	h.mu.Lock()
+	for _, leg := range o.Explanation.Legs {
+		t, ok := h.registry.ByAddress(o.ChainID, leg.TokenIn)
+		symbol := strings.ToUpper(t.Symbol)
+		if !ok || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		s := h.token(symbol)
+		mentions += s.Mentions
+		if s.PumpProbability > top.PumpProbability {
+			top = s
+		}
+	}
+	h.mu.Unlock()
+	f := map[string]float64{
+		"social_mentions":  float64(mentions),
+		"social_velocity":  top.Velocity,
+		"pump_probability": top.PumpProbability,
+		"pump_gated":       0,
+	}
+	if top.Gated {
+		f["pump_gated"] = 1
+		return f
+	}
+	for name, v := range h.news.Features(o) {
+		f[name] = v
+	}
+	return f
+}
@@ -0,0 +1,126 @@
+package signals
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/hf"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+)
+
+var (
+	weth = titantest.Address(1)
+	pepe = titantest.Address(2)
+)
+
+func newRegistry() *tokens.Registry {
+	registry := tokens.NewRegistry()
+	registry.Add(tokens.Token{ChainID: 1, Symbol: "WETH", Address: weth, Decimals: 18})
+	registry.Add(tokens.Token{ChainID: 1, Symbol: "PEPE", Address: pepe, Decimals: 18})
+	return registry
+}
+
+func pepeBackrun() *opportunity.Opportunity {
+	return &opportunity.Opportunity{ChainID: 1, Explanation: &opportunity.Explanation{Legs: []opportunity.Leg{
+		{TokenIn: weth, TokenOut: pepe}, {TokenIn: pepe, TokenOut: weth},
+	}}}
+}
+
+func TestIngestTagsAndDeduplicates(t *testing.T) {
+	reg := metrics.NewRegistry()
+	h := New(nil, newRegistry(), []uint64{1}, time.Hour, 0.2, reg)
+	e, fresh := h.Ingest(context.Background(), Event{ID: "1", Source: SourceRSS, Text: "$pepe volume doubles as WETH pairs deepen; eth fees fall"})
+	if !fresh || len(e.Tokens) != 2 || e.Tokens[0] != "PEPE" || e.Tokens[1] != "WETH" {
+		t.Errorf("Expected PEPE and WETH tagged, got %v", e.Tokens)
+	}
+	if _, fresh := h.Ingest(context.Background(), Event{ID: "1", Source: SourceRSS, Text: "$PEPE"}); fresh {
+		t.Errorf("Expected a repeated event ignored")
+	}
+	if e, _ := h.Ingest(context.Background(), Event{ID: "2", Source: SourceRSS, Text: "Markets are quiet"}); len(e.Tokens) != 0 {
+		t.Errorf("Expected no tokens tagged, got %v", e.Tokens)
+	}
+	if got := reg.Value("titan_signal_events_total", SourceRSS, "true"); got != 1 {
+		t.Errorf("Expected one tagged event counted, got %v", got)
+	}
+	if s := h.Token("pepe"); s.Mentions != 1 || s.Gated {
+		t.Errorf("Expected one ungated PEPE mention, got %+v", s)
+	}
+}
+
+func TestPumpGatesSentiment(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[[{"label": "positive", "score": 0.95}]]`))
+	}))
+	defer srv.Close()
+	registry := newRegistry()
+	news := hf.NewBoard(hf.New(srv.URL, "", time.Second, 0.8, nil), registry, time.Hour)
+	h := New(news, registry, []uint64{1}, time.Hour, 0.2, nil)
+
+	h.Ingest(context.Background(), Event{ID: "a", Source: SourceRSS, Text: "PEPE listed on a major exchange"})
+	f := h.Features(pepeBackrun())
+	if f["pump_gated"] != 0 || f["news_sentiment"] != 0.95 || f["social_mentions"] != 1 {
+		t.Errorf("Expected an ordinary headline's sentiment exposed, got %v", f)
+	}
+
+	for i := 0; i < 20; i++ {
+		h.Ingest(context.Background(), Event{ID: fmt.Sprint(i), Source: SourceTwitter, Text: "$PEPE is going to the moon, 100x gem"})
+	}
+	s := h.Token("PEPE")
+	if !s.Gated || s.HypeShare < 0.9 {
+		t.Fatalf("Expected a burst of pump calls gated, got %+v", s)
+	}
+	f = h.Features(pepeBackrun())
+	if f["pump_gated"] != 1 || f["pump_probability"] != s.PumpProbability {
+		t.Errorf("Expected the pump flagged, got %v", f)
+	}
+	if _, ok := f["news_sentiment"]; ok {
+		t.Errorf("Expected sentiment withheld for a gated token, got %v", f)
+	}
+}
+
+func TestPollerSources(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel>
+<item><guid>n1</guid><title>PEPE rallies</title><link>https://news.example.com/n1</link><pubDate>Mon, 02 Jan 2006 15:04:05 +0000</pubDate></item>
+</channel></rss>`))
+	})
+	var sinceIDs []string
+	mux.HandleFunc("/2/tweets/search/recent", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		sinceIDs = append(sinceIDs, r.URL.Query().Get("since_id"))
+		w.Write([]byte(`{"data": [{"id": "99", "text": "$WETH flows rising", "created_at": "2006-01-02T15:30:00Z"}], "meta": {"newest_id": "99"}}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	h := New(nil, newRegistry(), []uint64{1}, time.Hour, 0.2, nil)
+	now := time.Date(2006, 1, 2, 16, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+	p := NewPoller(h, &config.SignalsConfig{RSSFeeds: []string{srv.URL + "/feed"}, TwitterToken: "token", TwitterQuery: "$WETH", PollSecs: 60})
+	p.TwitterAPI = srv.URL + "/2"
+	p.Poll(context.Background())
+	p.Poll(context.Background())
+
+	recent := h.Recent()
+	if len(recent) != 2 {
+		t.Fatalf("Expected one item from each source, got %+v", recent)
+	}
+	if recent[1].Source != SourceRSS || recent[1].Tokens[0] != "PEPE" || !recent[1].Time.Equal(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Errorf("Expected the feed item normalized, got %+v", recent[1])
+	}
+	if len(sinceIDs) != 2 || sinceIDs[0] != "" || sinceIDs[1] != "99" {
+		t.Errorf("Expected the search resumed after the newest tweet, got %v", sinceIDs)
+	}
+}
@@ -0,0 +1,187 @@
+package signals
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+)
+
+// twitterAPI is the Twitter API v2 base URL
+const twitterAPI = "https://api.twitter.com/2"
+
+// Poller feeds the hub from RSS/Atom feeds and a Twitter recent search;
+// webhook sources push to Hub.Ingest directly
+type Poller struct {
+	hub      *Hub
+	feeds    []string
+	token    string
+	query    string
+	interval time.Duration
+	client   *http.Client
+
+	// TwitterAPI is the API base URL, replaced in tests
+	TwitterAPI string
+
+	sinceID string // newest tweet already ingested
+}
+
+// NewPoller creates a poller for the configured sources
+func NewPoller(hub *Hub, cfg *config.SignalsConfig) *Poller {
+	return &Poller{
+		hub:        hub,
+		feeds:      cfg.RSSFeeds,
+		token:      cfg.TwitterToken,
+		query:      cfg.TwitterQuery,
+		interval:   time.Duration(cfg.PollSecs) * time.Second,
+		client:     &http.Client{Timeout: 15 * time.Second},
+		TwitterAPI: twitterAPI,
+	}
+}
+
+// Run polls every source until ctx is cancelled; a failing source is logged
+// and retried on the next poll without holding up the others
+func (p *Poller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches each source once and ingests new items
+func (p *Poller) Poll(ctx context.Context) {
+	for _, feed := range p.feeds {
+		events, err := p.rss(ctx, feed)
+		if err != nil {
+			log.Printf("⚠️ Signal feed %s: %v", feed, err)
+			continue
+		}
+		for _, e := range events {
+			p.hub.Ingest(ctx, e)
+		}
+	}
+	if p.token == "" {
+		return
+	}
+	events, err := p.twitter(ctx)
+	if err != nil {
+		log.Printf("⚠️ Twitter search: %v", err)
+		return
+	}
+	for _, e := range events {
+		p.hub.Ingest(ctx, e)
+	}
+}
+
+// rssDocument covers RSS 2.0 channels and Atom feeds
+type rssDocument struct {
+	Items []struct {
+		GUID        string `xml:"guid"`
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		PubDate     string `xml:"pubDate"`
+	} `xml:"channel>item"`
+	Entries []struct {
+		ID      string `xml:"id"`
+		Title   string `xml:"title"`
+		Summary string `xml:"summary"`
+		Updated string `xml:"updated"`
+		Link    struct {
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+	} `xml:"entry"`
+}
+
+func (p *Poller) rss(ctx context.Context, feed string) ([]Event, error) {
+	raw, err := p.get(ctx, feed, nil)
+	if err != nil {
+		return nil, err
+	}
+	var doc rssDocument
+	if err := xml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("bad feed: %w", err)
+	}
+	var events []Event
+	for _, item := range doc.Items {
+		id := item.GUID
+		if id == "" {
+			id = item.Link
+		}
+		t, _ := time.Parse(time.RFC1123Z, item.PubDate)
+		if t.IsZero() {
+			t, _ = time.Parse(time.RFC1123, item.PubDate)
+		}
+		events = append(events, Event{ID: id, Source: SourceRSS, Time: t.UTC(), Text: strings.TrimSpace(item.Title + "\n" + item.Description), URL: item.Link})
+	}
+	for _, entry := range doc.Entries {
+		t, _ := time.Parse(time.RFC3339, entry.Updated)
+		events = append(events, Event{ID: entry.ID, Source: SourceRSS, Time: t.UTC(), Text: strings.TrimSpace(entry.Title + "\n" + entry.Summary), URL: entry.Link.Href})
+	}
+	return events, nil
+}
+
+func (p *Poller) twitter(ctx context.Context) ([]Event, error) {
+	q := url.Values{"query": {p.query}, "max_results": {"100"}, "tweet.fields": {"created_at"}}
+	if p.sinceID != "" {
+		q.Set("since_id", p.sinceID)
+	}
+	raw, err := p.get(ctx, p.TwitterAPI+"/tweets/search/recent?"+q.Encode(), map[string]string{"Authorization": "Bearer " + p.token})
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Data []struct {
+			ID        string    `json:"id"`
+			Text      string    `json:"text"`
+			CreatedAt time.Time `json:"created_at"`
+		} `json:"data"`
+		Meta struct {
+			NewestID string `json:"newest_id"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("unexpected response: %w", err)
+	}
+	if resp.Meta.NewestID != "" {
+		p.sinceID = resp.Meta.NewestID
+	}
+	events := make([]Event, 0, len(resp.Data))
+	for _, tweet := range resp.Data {
+		events = append(events, Event{ID: tweet.ID, Source: SourceTwitter, Time: tweet.CreatedAt.UTC(), Text: tweet.Text, URL: "https://twitter.com/i/web/status/" + tweet.ID})
+	}
+	return events, nil
+}
+
+func (p *Poller) get(ctx context.Context, u string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("returned %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+}
@@ -0,0 +1,137 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// NativeToken stands for the chain's native asset in balance checks
+var NativeToken = common.Address{}
+
+// balanceOfSelector is keccak256("balanceOf(address)")[:4]
+var balanceOfSelector = []byte{0x70, 0xa0, 0x82, 0x31}
+
+// BalanceCheck asserts how much a holder's balance of a token must change
+// across the whole bundle. MinDelta may be negative to bound a loss.
+type BalanceCheck struct {
+	Name     string
+	Token    common.Address // NativeToken for the native asset
+	Holder   common.Address
+	MinDelta *big.Int
+}
+
+// BalanceChange is a checked balance before and after the bundle
+type BalanceChange struct {
+	Name   string         `json:"name"`
+	Token  common.Address `json:"token"`
+	Holder common.Address `json:"holder"`
+	Before *big.Int       `json:"before"`
+	After  *big.Int       `json:"after"`
+	Delta  *big.Int       `json:"delta"`
+	OK     bool           `json:"ok"`
+}
+
+// FlashLoan describes a flash-loan trade simulated end to end: the executor
+// borrows Amount of Token from Lender, swaps, repays with the premium and
+// sends the profit on
+type FlashLoan struct {
+	Lender      common.Address // Aave pool or Balancer vault holding the liquidity
+	Token       common.Address
+	Amount      *big.Int
+	PremiumBps  uint64 // lender fee on Amount (Aave 5, Balancer 0)
+	Executor    common.Address
+	ProfitTo    common.Address
+	ProfitToken common.Address
+	MinProfit   *big.Int
+}
+
+// Premium returns the fee the lender must receive on top of the principal
+func (f FlashLoan) Premium() *big.Int {
+	return units.MulBps(f.Amount, f.PremiumBps)
+}
+
+// Checks returns the balance assertions for a successful flash-loan trade:
+// the lender is repaid with its premium, the executor keeps no debt in the
+// borrowed token and the profit recipient gains at least MinProfit
+func (f FlashLoan) Checks() []BalanceCheck {
+	minProfit := f.MinProfit
+	if minProfit == nil {
+		minProfit = big.NewInt(1)
+	}
+	checks := []BalanceCheck{
+		{Name: "repayment", Token: f.Token, Holder: f.Lender, MinDelta: f.Premium()},
+		{Name: "profit", Token: f.ProfitToken, Holder: f.ProfitTo, MinDelta: minProfit},
+	}
+	if f.Executor != f.ProfitTo {
+		checks = append(checks, BalanceCheck{Name: "executor", Token: f.Token, Holder: f.Executor, MinDelta: new(big.Int)})
+	}
+	return checks
+}
+
+// readBalances reads each check's balance through node
+func readBalances(ctx context.Context, node RPC, checks []BalanceCheck) ([]*big.Int, error) {
+	out := make([]*big.Int, len(checks))
+	for i, c := range checks {
+		var raw hexutil.Big
+		if c.Token == NativeToken {
+			if err := node.CallContext(ctx, &raw, "eth_getBalance", c.Holder, "latest"); err != nil {
+				return nil, fmt.Errorf("%s balance: %w", c.Name, err)
+			}
+		} else {
+			var ret hexutil.Bytes
+			args := map[string]interface{}{
+				"to":   c.Token,
+				"data": hexutil.Bytes(append(append([]byte{}, balanceOfSelector...), common.LeftPadBytes(c.Holder.Bytes(), 32)...)),
+			}
+			if err := node.CallContext(ctx, &ret, "eth_call", args, "latest"); err != nil {
+				return nil, fmt.Errorf("%s balance: %w", c.Name, err)
+			}
+			if len(ret) < 32 {
+				return nil, fmt.Errorf("%s balance: short balanceOf return from %s", c.Name, c.Token.Hex())
+			}
+			raw = hexutil.Big(*new(big.Int).SetBytes(ret[:32]))
+		}
+		out[i] = raw.ToInt()
+	}
+	return out, nil
+}
+
+// compareBalances records every change and returns the first failed check's reason
+func compareBalances(checks []BalanceCheck, before, after []*big.Int) ([]BalanceChange, string) {
+	changes := make([]BalanceChange, len(checks))
+	reason := ""
+	for i, c := range checks {
+		delta := new(big.Int).Sub(after[i], before[i])
+		ok := c.MinDelta == nil || delta.Cmp(c.MinDelta) >= 0
+		changes[i] = BalanceChange{Name: c.Name, Token: c.Token, Holder: c.Holder, Before: before[i], After: after[i], Delta: delta, OK: ok}
+		if !ok && reason == "" {
+			reason = fmt.Sprintf("%s check failed: balance changed by %s, need at least %s", c.Name, delta, c.MinDelta)
+		}
+	}
+	return changes, reason
+}
+
+// revertReason decodes an Error(string) or Panic(uint256) revert carried by a
+// JSON-RPC error, falling back to the error text
+func revertReason(err error) string {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			if raw, decodeErr := hexutil.Decode(data); decodeErr == nil {
+				if reason, unpackErr := abi.UnpackRevert(raw); unpackErr == nil {
+					return "reverted: " + reason
+				}
+			}
+		}
+	}
+	return err.Error()
+}
@@ -0,0 +1,136 @@
+package simulation
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+)
+
+// ledgerFork is a fork node whose token balances move by a fixed set of
+// transfers when the bundle's transaction is sent
+type ledgerFork struct {
+	balances  map[common.Address]map[common.Address]*big.Int // token -> holder -> balance
+	transfers []transfer
+	revert    error
+}
+
+type transfer struct {
+	token    common.Address
+	from, to common.Address
+	amount   *big.Int
+}
+
+func (f *ledgerFork) balance(token, holder common.Address) *big.Int {
+	if f.balances[token] == nil {
+		f.balances[token] = make(map[common.Address]*big.Int)
+	}
+	if f.balances[token][holder] == nil {
+		f.balances[token][holder] = new(big.Int)
+	}
+	return f.balances[token][holder]
+}
+
+func (f *ledgerFork) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	switch method {
+	case "evm_snapshot":
+		*result.(*string) = "0x1"
+	case "evm_revert":
+		*result.(*bool) = true
+	case "eth_call":
+		call := args[0].(map[string]interface{})
+		data := call["data"].(hexutil.Bytes)
+		holder := common.BytesToAddress(data[4:])
+		*result.(*hexutil.Bytes) = common.LeftPadBytes(f.balance(call["to"].(common.Address), holder).Bytes(), 32)
+	case "eth_sendTransaction":
+		if f.revert != nil {
+			return f.revert
+		}
+		for _, t := range f.transfers {
+			f.balance(t.token, t.from).Sub(f.balance(t.token, t.from), t.amount)
+			f.balance(t.token, t.to).Add(f.balance(t.token, t.to), t.amount)
+		}
+		*result.(*common.Hash) = common.BigToHash(common.Big1)
+	case "eth_getTransactionReceipt":
+		*result.(**types.Receipt) = &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 400_000}
+	}
+	return nil
+}
+
+// revertDataError carries ABI-encoded revert data like a node's eth_sendTransaction error
+type revertDataError struct{ data string }
+
+func (e revertDataError) Error() string          { return "execution reverted" }
+func (e revertDataError) ErrorCode() int         { return 3 }
+func (e revertDataError) ErrorData() interface{} { return e.data }
+
+func TestForkSimulatorChecksFlashLoanRepayment(t *testing.T) {
+	usdc := titantest.Address(0x0c)
+	loan := FlashLoan{
+		Lender:      titantest.Address(0xaa),
+		Token:       usdc,
+		Amount:      titantest.Units(100_000, 6),
+		PremiumBps:  5,
+		Executor:    titantest.Address(0xe0),
+		ProfitTo:    titantest.Address(0x01),
+		ProfitToken: usdc,
+		MinProfit:   titantest.Units(10, 6),
+	}
+	trade := Call{From: titantest.Address(0x01), To: loan.Executor}
+	cand := Candidate{Bundle: []Call{trade}, Checks: loan.Checks()}
+
+	// Borrow, trade for a $60 gain, repay principal plus the $50 premium, keep $10
+	fork := &ledgerFork{balances: map[common.Address]map[common.Address]*big.Int{}}
+	fork.balance(usdc, loan.Lender).Set(titantest.Units(1_000_000, 6))
+	fork.transfers = []transfer{
+		{usdc, loan.Lender, loan.Executor, loan.Amount},
+		{usdc, titantest.Address(0x99), loan.Executor, titantest.Units(60, 6)},
+		{usdc, loan.Executor, loan.Lender, new(big.Int).Add(loan.Amount, loan.Premium())},
+		{usdc, loan.Executor, loan.ProfitTo, titantest.Units(10, 6)},
+	}
+	res, err := NewForkSimulator(fork).Simulate(context.Background(), cand)
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if !res.OK || len(res.Balances) != 3 {
+		t.Fatalf("Expected all 3 checks to pass, got %+v", res)
+	}
+	if res.Balances[0].Delta.Cmp(titantest.Units(50, 6)) != 0 {
+		t.Errorf("Expected lender to gain the $50 premium, got %s", res.Balances[0].Delta)
+	}
+
+	// Short repayment leaves the lender below its premium
+	fork = &ledgerFork{balances: map[common.Address]map[common.Address]*big.Int{}}
+	fork.transfers = []transfer{
+		{usdc, loan.Lender, loan.Executor, loan.Amount},
+		{usdc, loan.Executor, loan.Lender, loan.Amount},
+		{usdc, titantest.Address(0x99), loan.ProfitTo, titantest.Units(10, 6)},
+	}
+	res, err = NewForkSimulator(fork).Simulate(context.Background(), cand)
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if res.OK || !strings.HasPrefix(res.Reason, "repayment check failed") {
+		t.Errorf("Expected repayment check to fail, got %+v", res)
+	}
+
+	// A revert surfaces the contract's reason
+	fork = &ledgerFork{balances: map[common.Address]map[common.Address]*big.Int{}}
+	// Error("NO_PROFIT")
+	fork.revert = revertDataError{data: "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000009" +
+		"4e4f5f50524f4649540000000000000000000000000000000000000000000000"}
+	res, err = NewForkSimulator(fork).Simulate(context.Background(), cand)
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if res.OK || res.Reason != "tx 0: reverted: NO_PROFIT" {
+		t.Errorf("Expected decoded revert reason, got %q", res.Reason)
+	}
+}
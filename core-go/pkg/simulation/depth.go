@@ -0,0 +1,412 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Depth is how thoroughly a candidate is checked before submission
+type Depth string
+
+// Simulation depths, cheapest first
+const (
+	DepthQuote Depth = config.SimulationDepthQuote
+	DepthCall  Depth = config.SimulationDepthCall
+	DepthFork  Depth = config.SimulationDepthFork
+)
+
+// depthOrder ranks depths for escalation and fallback
+var depthOrder = []Depth{DepthQuote, DepthCall, DepthFork}
+
+func (d Depth) rank() int {
+	for i, have := range depthOrder {
+		if have == d {
+			return i
+		}
+	}
+	return -1
+}
+
+// ErrUnknownDepth is returned for a depth name that is not quote, call or fork
+var ErrUnknownDepth = errors.New("simulation: unknown depth")
+
+// ParseDepth parses a configured depth name
+func ParseDepth(s string) (Depth, error) {
+	if d := Depth(s); d.rank() >= 0 {
+		return d, nil
+	}
+	return "", fmt.Errorf("%w %q", ErrUnknownDepth, s)
+}
+
+// Call is one transaction of a candidate's bundle
+type Call struct {
+	From  common.Address
+	To    common.Address
+	Data  []byte
+	Value *big.Int
+	Gas   uint64 // 0 lets the node pick
+}
+
+// Candidate is an evaluated opportunity awaiting simulation. Bundle holds the
+// transactions in submission order; the last one is the trade itself.
+type Candidate struct {
+	ChainID           uint64
+	Bundle            []Call
+	ExpectedProfitUSD units.USD      // from quote math; drives escalation
+	Checks            []BalanceCheck // ending-balance assertions, enforced at fork depth
+	// Overrides is assumed state, e.g. the executor already holding tokens or
+	// approvals; such what-if candidates run at call depth or deeper
+	Overrides Overrides
+}
+
+// Result is the outcome of simulating a candidate
+type Result struct {
+	Requested Depth           `json:"requested"` // depth the policy asked for
+	Depth     Depth           `json:"depth"`     // depth actually run
+	OK        bool            `json:"ok"`
+	GasUsed   uint64          `json:"gasUsed,omitempty"`
+	Output    []byte          `json:"output,omitempty"`
+	Reason    string          `json:"reason,omitempty"`
+	Balances  []BalanceChange `json:"balances,omitempty"`
+	Elapsed   time.Duration   `json:"elapsed"`
+	// WhatIf marks a result that relied on state overrides, so it says
+	// nothing about submitting against today's chain
+	WhatIf bool `json:"whatIf,omitempty"`
+}
+
+// Simulator checks candidates at one depth
+type Simulator interface {
+	Depth() Depth
+	Simulate(ctx context.Context, c Candidate) (*Result, error)
+}
+
+// Policy picks a depth per candidate: the chain's configured depth, raised
+// when expected profit crosses an escalation threshold
+type Policy struct {
+	Default         Depth
+	EscalateCallUSD units.USD // 0 never escalates to call
+	EscalateForkUSD units.USD // 0 never escalates to fork
+}
+
+// PolicyFromConfig builds the policy for a configured chain
+func PolicyFromConfig(chain *config.ChainConfig, sim *config.SimulationConfig) (Policy, error) {
+	depth := DepthCall
+	if chain.SimulationDepth != "" {
+		var err error
+		if depth, err = ParseDepth(chain.SimulationDepth); err != nil {
+			return Policy{}, fmt.Errorf("%s: %w", chain.Name, err)
+		}
+	}
+	p := Policy{Default: depth}
+	if sim != nil {
+		p.EscalateCallUSD = units.DollarsToUSD(sim.EscalateCallUSD)
+		p.EscalateForkUSD = units.DollarsToUSD(sim.EscalateForkUSD)
+	}
+	return p, nil
+}
+
+// For returns the depth a candidate with the given expected profit needs
+func (p Policy) For(profit units.USD) Depth {
+	depth := p.Default
+	if depth.rank() < 0 {
+		depth = DepthCall
+	}
+	if p.EscalateForkUSD > 0 && profit >= p.EscalateForkUSD {
+		return DepthFork
+	}
+	if p.EscalateCallUSD > 0 && profit >= p.EscalateCallUSD && depth.rank() < DepthCall.rank() {
+		return DepthCall
+	}
+	return depth
+}
+
+// Runner routes candidates to the simulator their policy asks for. When that
+// depth has no simulator (no fork node configured, say) it falls back to the
+// deepest available one below it and records both on the result.
+type Runner struct {
+	policy     Policy
+	simulators map[Depth]Simulator
+}
+
+// NewRunner creates a runner; quote-depth checking is always available
+func NewRunner(policy Policy, simulators ...Simulator) *Runner {
+	r := &Runner{policy: policy, simulators: map[Depth]Simulator{DepthQuote: QuoteSimulator{}}}
+	for _, s := range simulators {
+		if s != nil {
+			r.simulators[s.Depth()] = s
+		}
+	}
+	return r
+}
+
+// RunnerFromConfig builds a chain's runner: quote checks, eth_call through
+// reader when one is given, and fork simulation when the chain has a ForkRPC
+func RunnerFromConfig(ctx context.Context, cfg *config.Config, chainID uint64, reader chain.ChainReader) (*Runner, error) {
+	chainCfg, ok := cfg.GetChain(chainID)
+	if !ok {
+		return nil, fmt.Errorf("chain %d not configured", chainID)
+	}
+	policy, err := PolicyFromConfig(chainCfg, cfg.Simulation)
+	if err != nil {
+		return nil, err
+	}
+	var sims []Simulator
+	if reader != nil {
+		sims = append(sims, NewCallSimulator(reader))
+	}
+	if chainCfg.ForkRPC != "" {
+		client, err := rpc.DialContext(ctx, chainCfg.ForkRPC)
+		if err != nil {
+			return nil, fmt.Errorf("%s fork node: %w", chainCfg.Name, err)
+		}
+		sims = append(sims, NewForkSimulator(client))
+	}
+	return NewRunner(policy, sims...), nil
+}
+
+// Policy returns the runner's depth policy
+func (r *Runner) Policy() Policy {
+	return r.policy
+}
+
+// Simulate runs the candidate at its policy depth. Candidates carrying
+// balance checks ask for fork depth, the only one that can enforce them;
+// what-if candidates never stop at quote depth, which ignores chain state.
+func (r *Runner) Simulate(ctx context.Context, c Candidate) (*Result, error) {
+	requested := r.policy.For(c.ExpectedProfitUSD)
+	if len(c.Checks) > 0 {
+		requested = DepthFork
+	}
+	if len(c.Overrides) > 0 && requested.rank() < DepthCall.rank() {
+		requested = DepthCall
+	}
+	lowest := 0
+	if len(c.Overrides) > 0 {
+		lowest = DepthCall.rank()
+	}
+	for i := requested.rank(); i >= lowest; i-- {
+		sim, ok := r.simulators[depthOrder[i]]
+		if !ok {
+			continue
+		}
+		start := time.Now()
+		res, err := sim.Simulate(ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("%s simulation: %w", sim.Depth(), err)
+		}
+		res.Requested = requested
+		res.Depth = sim.Depth()
+		res.Elapsed = time.Since(start)
+		res.WhatIf = len(c.Overrides) > 0
+		return res, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownDepth, requested)
+}
+
+// QuoteSimulator trusts the quote math: it passes any candidate expected to profit
+type QuoteSimulator struct{}
+
+// Depth returns DepthQuote
+func (QuoteSimulator) Depth() Depth { return DepthQuote }
+
+// Simulate checks the expected profit only
+func (QuoteSimulator) Simulate(ctx context.Context, c Candidate) (*Result, error) {
+	if c.ExpectedProfitUSD <= 0 {
+		return &Result{Reason: "no expected profit"}, nil
+	}
+	return &Result{OK: true}, nil
+}
+
+// CallSimulator eth_calls the trade against the latest state. Earlier bundle
+// transactions are not applied, so the trade must not depend on them.
+type CallSimulator struct {
+	reader chain.ChainReader
+
+	// RPC sends eth_call with state overrides for what-if candidates; it is
+	// taken from reader when that is an *ethclient.Client
+	RPC RPC
+}
+
+// NewCallSimulator creates an eth_call simulator
+func NewCallSimulator(reader chain.ChainReader) *CallSimulator {
+	s := &CallSimulator{reader: reader}
+	if raw, ok := reader.(interface{ Client() *rpc.Client }); ok {
+		s.RPC = raw.Client()
+	}
+	return s
+}
+
+// Depth returns DepthCall
+func (s *CallSimulator) Depth() Depth { return DepthCall }
+
+// Simulate calls the last transaction of the bundle; a revert fails the candidate
+func (s *CallSimulator) Simulate(ctx context.Context, c Candidate) (*Result, error) {
+	if len(c.Bundle) == 0 {
+		return nil, errors.New("empty bundle")
+	}
+	trade := c.Bundle[len(c.Bundle)-1]
+	if len(c.Overrides) > 0 {
+		return s.simulateWhatIf(ctx, trade, c.Overrides)
+	}
+	out, err := s.reader.CallContract(ctx, ethereum.CallMsg{
+		From:  trade.From,
+		To:    &trade.To,
+		Data:  trade.Data,
+		Value: trade.Value,
+		Gas:   trade.Gas,
+	}, nil)
+	if err != nil {
+		if rejected(err) {
+			return &Result{Reason: revertReason(err)}, nil
+		}
+		return nil, err
+	}
+	return &Result{OK: true, Output: out}, nil
+}
+
+// simulateWhatIf calls trade with overrides applied on top of the latest state
+func (s *CallSimulator) simulateWhatIf(ctx context.Context, trade Call, overrides Overrides) (*Result, error) {
+	if s.RPC == nil {
+		return nil, ErrOverridesUnsupported
+	}
+	args := map[string]interface{}{
+		"from": trade.From,
+		"to":   trade.To,
+		"data": hexutil.Bytes(trade.Data),
+	}
+	if trade.Value != nil {
+		args["value"] = (*hexutil.Big)(trade.Value)
+	}
+	if trade.Gas > 0 {
+		args["gas"] = hexutil.Uint64(trade.Gas)
+	}
+	var out hexutil.Bytes
+	if err := s.RPC.CallContext(ctx, &out, "eth_call", args, "latest", overrides); err != nil {
+		if rejected(err) {
+			return &Result{Reason: revertReason(err)}, nil
+		}
+		return nil, err
+	}
+	return &Result{OK: true, Output: out}, nil
+}
+
+// rejected reports whether err is the node refusing the transaction (a revert
+// or invalid call) rather than a transport failure
+func rejected(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr)
+}
+
+// RPC is the raw JSON-RPC access fork simulation needs; *rpc.Client satisfies it
+type RPC interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// ForkSimulator runs the whole bundle on a forked development node (anvil
+// or hardhat) and reverts the fork afterwards. The sender is impersonated,
+// so no keys are involved.
+type ForkSimulator struct {
+	rpc RPC
+}
+
+// NewForkSimulator creates a simulator against a forked node
+func NewForkSimulator(node RPC) *ForkSimulator {
+	return &ForkSimulator{rpc: node}
+}
+
+// Depth returns DepthFork
+func (s *ForkSimulator) Depth() Depth { return DepthFork }
+
+// Simulate sends every bundle transaction in order inside a snapshot; the
+// candidate passes when all of them succeed and every balance check holds on
+// the ending state. Overrides are written into the fork before the bundle runs.
+func (s *ForkSimulator) Simulate(ctx context.Context, c Candidate) (res *Result, err error) {
+	if len(c.Bundle) == 0 {
+		return nil, errors.New("empty bundle")
+	}
+	var snapshot string
+	if err := s.rpc.CallContext(ctx, &snapshot, "evm_snapshot"); err != nil {
+		return nil, fmt.Errorf("evm_snapshot: %w", err)
+	}
+	defer func() {
+		var reverted bool
+		if rerr := s.rpc.CallContext(context.Background(), &reverted, "evm_revert", snapshot); rerr != nil && err == nil {
+			res, err = nil, fmt.Errorf("evm_revert: %w", rerr)
+		}
+	}()
+
+	impersonated := make(map[common.Address]bool)
+	defer func() {
+		for addr := range impersonated {
+			s.rpc.CallContext(context.Background(), nil, "hardhat_stopImpersonatingAccount", addr)
+		}
+	}()
+
+	if err := applyOverrides(ctx, s.rpc, c.Overrides); err != nil {
+		return nil, err
+	}
+
+	before, err := readBalances(ctx, s.rpc, c.Checks)
+	if err != nil {
+		return nil, err
+	}
+
+	res = &Result{OK: true}
+	for i, call := range c.Bundle {
+		if !impersonated[call.From] {
+			if err := s.rpc.CallContext(ctx, nil, "hardhat_impersonateAccount", call.From); err != nil {
+				return nil, fmt.Errorf("impersonate %s: %w", call.From.Hex(), err)
+			}
+			impersonated[call.From] = true
+		}
+		tx := map[string]interface{}{
+			"from": call.From,
+			"to":   call.To,
+			"data": hexutil.Bytes(call.Data),
+		}
+		if call.Value != nil {
+			tx["value"] = (*hexutil.Big)(call.Value)
+		}
+		if call.Gas > 0 {
+			tx["gas"] = hexutil.Uint64(call.Gas)
+		}
+		var hash common.Hash
+		if err := s.rpc.CallContext(ctx, &hash, "eth_sendTransaction", tx); err != nil {
+			if rejected(err) {
+				return &Result{GasUsed: res.GasUsed, Reason: fmt.Sprintf("tx %d: %s", i, revertReason(err))}, nil
+			}
+			return nil, fmt.Errorf("send tx %d: %w", i, err)
+		}
+		var receipt *types.Receipt
+		if err := s.rpc.CallContext(ctx, &receipt, "eth_getTransactionReceipt", hash); err != nil {
+			return nil, fmt.Errorf("receipt for tx %d: %w", i, err)
+		}
+		if receipt == nil {
+			return nil, fmt.Errorf("tx %d not mined; is automine enabled on the fork?", i)
+		}
+		res.GasUsed += receipt.GasUsed
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return &Result{GasUsed: res.GasUsed, Reason: fmt.Sprintf("tx %d reverted", i)}, nil
+		}
+	}
+
+	after, err := readBalances(ctx, s.rpc, c.Checks)
+	if err != nil {
+		return nil, err
+	}
+	res.Balances, res.Reason = compareBalances(c.Checks, before, after)
+	res.OK = res.Reason == ""
+	return res, nil
+}
@@ -0,0 +1,146 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// revertError mimics the JSON-RPC error a node returns for a reverted call
+type revertError struct{}
+
+func (revertError) Error() string  { return "execution reverted: INSUFFICIENT_PROFIT" }
+func (revertError) ErrorCode() int { return 3 }
+
+func TestPolicyEscalation(t *testing.T) {
+	p, err := PolicyFromConfig(
+		&config.ChainConfig{Name: "arbitrum", SimulationDepth: "quote"},
+		&config.SimulationConfig{EscalateCallUSD: 100, EscalateForkUSD: 5000})
+	if err != nil {
+		t.Fatalf("PolicyFromConfig failed: %v", err)
+	}
+	cases := []struct {
+		profit units.USD
+		want   Depth
+	}{
+		{units.DollarsToUSD(5), DepthQuote},
+		{units.DollarsToUSD(100), DepthCall},
+		{units.DollarsToUSD(5000), DepthFork},
+	}
+	for _, tc := range cases {
+		if got := p.For(tc.profit); got != tc.want {
+			t.Errorf("Expected %s at %s profit, got %s", tc.want, tc.profit, got)
+		}
+	}
+
+	// A chain already at fork depth is never lowered
+	p.Default = DepthFork
+	if got := p.For(0); got != DepthFork {
+		t.Errorf("Expected fork, got %s", got)
+	}
+
+	if _, err := PolicyFromConfig(&config.ChainConfig{SimulationDepth: "deep"}, nil); !errors.Is(err, ErrUnknownDepth) {
+		t.Errorf("Expected ErrUnknownDepth, got %v", err)
+	}
+}
+
+func TestRunnerFallsBackWithoutForkNode(t *testing.T) {
+	backend := titantest.NewBackend(137)
+	executor := titantest.Address(0xe0)
+	backend.Handle(executor, "execute(bytes)", func(msg ethereum.CallMsg) ([]byte, error) {
+		if msg.From != titantest.Address(0x01) {
+			return nil, revertError{}
+		}
+		return []byte{0x01}, nil
+	})
+
+	runner := NewRunner(Policy{Default: DepthQuote, EscalateForkUSD: units.DollarsToUSD(1000)}, NewCallSimulator(backend))
+	trade := Call{From: titantest.Address(0x01), To: executor, Data: selector("execute(bytes)")}
+
+	res, err := runner.Simulate(context.Background(), Candidate{Bundle: []Call{trade}, ExpectedProfitUSD: units.DollarsToUSD(2000)})
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if res.Requested != DepthFork || res.Depth != DepthCall || !res.OK {
+		t.Errorf("Expected fork request served by call, got %+v", res)
+	}
+
+	trade.From = titantest.Address(0x02)
+	res, err = runner.Simulate(context.Background(), Candidate{Bundle: []Call{trade}, ExpectedProfitUSD: units.DollarsToUSD(2000)})
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if res.OK || res.Reason == "" {
+		t.Errorf("Expected revert to fail the candidate, got %+v", res)
+	}
+
+	// Cheap candidates stay on quote math and never touch the chain
+	calls := backend.Calls
+	res, _ = runner.Simulate(context.Background(), Candidate{Bundle: []Call{trade}, ExpectedProfitUSD: units.DollarsToUSD(5)})
+	if res.Depth != DepthQuote || !res.OK || backend.Calls != calls {
+		t.Errorf("Expected quote-only pass without calls, got %+v", res)
+	}
+}
+
+// fakeFork answers the anvil/hardhat methods fork simulation uses
+type fakeFork struct {
+	methods []string
+	status  []uint64 // receipt status per sent transaction
+	sent    int
+}
+
+func (f *fakeFork) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	f.methods = append(f.methods, method)
+	switch method {
+	case "evm_snapshot":
+		*result.(*string) = "0x1"
+	case "evm_revert":
+		*result.(*bool) = true
+	case "eth_sendTransaction":
+		*result.(*common.Hash) = common.BigToHash(common.Big1)
+	case "eth_getTransactionReceipt":
+		*result.(**types.Receipt) = &types.Receipt{Status: f.status[f.sent], GasUsed: 50_000}
+		f.sent++
+	}
+	return nil
+}
+
+func TestForkSimulatorRunsBundleAndReverts(t *testing.T) {
+	bundle := []Call{
+		{From: titantest.Address(0x01), To: titantest.Address(0xaa)},
+		{From: titantest.Address(0x01), To: titantest.Address(0xe0)},
+	}
+
+	fork := &fakeFork{status: []uint64{1, 1}}
+	res, err := NewForkSimulator(fork).Simulate(context.Background(), Candidate{Bundle: bundle})
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if !res.OK || res.GasUsed != 100_000 {
+		t.Errorf("Expected success using 100000 gas, got %+v", res)
+	}
+	if last := fork.methods[len(fork.methods)-1]; last != "evm_revert" {
+		t.Errorf("Expected fork to be reverted last, got %s", last)
+	}
+
+	fork = &fakeFork{status: []uint64{1, 0}}
+	res, err = NewForkSimulator(fork).Simulate(context.Background(), Candidate{Bundle: bundle})
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if res.OK || res.Reason != "tx 1 reverted" {
+		t.Errorf("Expected second transaction to fail the bundle, got %+v", res)
+	}
+}
+
+func selector(signature string) []byte {
+	return crypto.Keccak256([]byte(signature))[:4]
+}
@@ -0,0 +1,221 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// ErrOverridesUnsupported is returned when a candidate carries state
+	// overrides but the simulator has no raw RPC access to send them
+	ErrOverridesUnsupported = errors.New("simulation: state overrides need raw RPC access")
+	// ErrLayoutNotFound is returned when no probed slot holds a token's balances
+	ErrLayoutNotFound = errors.New("simulation: token storage layout not found")
+)
+
+// allowanceSelector is keccak256("allowance(address,address)")[:4]
+var allowanceSelector = []byte{0xdd, 0x62, 0xed, 0x3e}
+
+// MaxProbeSlot bounds the storage slots FindLayout tries
+const MaxProbeSlot = 20
+
+// Override is the state assumed for one account during a what-if simulation.
+// Nil fields keep the chain's value.
+type Override struct {
+	Balance   *big.Int
+	Nonce     *uint64
+	Code      []byte
+	StateDiff map[common.Hash]common.Hash
+}
+
+// MarshalJSON encodes the override in eth_call's state-override format
+func (o *Override) MarshalJSON() ([]byte, error) {
+	out := make(map[string]interface{})
+	if o.Balance != nil {
+		out["balance"] = (*hexutil.Big)(o.Balance)
+	}
+	if o.Nonce != nil {
+		out["nonce"] = hexutil.Uint64(*o.Nonce)
+	}
+	if o.Code != nil {
+		out["code"] = hexutil.Bytes(o.Code)
+	}
+	if len(o.StateDiff) > 0 {
+		out["stateDiff"] = o.StateDiff
+	}
+	return json.Marshal(out)
+}
+
+// Overrides is the state assumed for a what-if simulation, such as the
+// executor already holding tokens or approvals it has not been given yet
+type Overrides map[common.Address]*Override
+
+func (o Overrides) account(addr common.Address) *Override {
+	acc, ok := o[addr]
+	if !ok {
+		acc = &Override{}
+		o[addr] = acc
+	}
+	return acc
+}
+
+// SetBalance assumes addr holds wei of the native asset
+func (o Overrides) SetBalance(addr common.Address, wei *big.Int) Overrides {
+	o.account(addr).Balance = wei
+	return o
+}
+
+// SetNonce assumes addr's nonce
+func (o Overrides) SetNonce(addr common.Address, nonce uint64) Overrides {
+	o.account(addr).Nonce = &nonce
+	return o
+}
+
+// SetCode assumes code is deployed at addr, e.g. an executor not deployed yet
+func (o Overrides) SetCode(addr common.Address, code []byte) Overrides {
+	o.account(addr).Code = code
+	return o
+}
+
+// SetStorage assumes one storage slot of addr
+func (o Overrides) SetStorage(addr common.Address, slot, value common.Hash) Overrides {
+	acc := o.account(addr)
+	if acc.StateDiff == nil {
+		acc.StateDiff = make(map[common.Hash]common.Hash)
+	}
+	acc.StateDiff[slot] = value
+	return o
+}
+
+// Fund assumes holder owns amount of token
+func (o Overrides) Fund(token common.Address, layout TokenLayout, holder common.Address, amount *big.Int) Overrides {
+	return o.SetStorage(token, layout.BalanceKey(holder), common.BigToHash(amount))
+}
+
+// Approve assumes owner has approved spender for amount of token
+func (o Overrides) Approve(token common.Address, layout TokenLayout, owner, spender common.Address, amount *big.Int) Overrides {
+	return o.SetStorage(token, layout.AllowanceKey(owner, spender), common.BigToHash(amount))
+}
+
+// TokenLayout locates an ERC20's balance and allowance mappings in storage
+type TokenLayout struct {
+	BalanceSlot   uint64 `json:"balanceSlot"`
+	AllowanceSlot uint64 `json:"allowanceSlot"`
+	// Vyper hashes slot before key; Solidity hashes key before slot
+	Vyper bool `json:"vyper,omitempty"`
+}
+
+// BalanceKey returns the storage key of holder's balance
+func (l TokenLayout) BalanceKey(holder common.Address) common.Hash {
+	return mappingKey(common.BytesToHash(holder.Bytes()), l.BalanceSlot, l.Vyper)
+}
+
+// AllowanceKey returns the storage key of owner's allowance for spender
+func (l TokenLayout) AllowanceKey(owner, spender common.Address) common.Hash {
+	inner := mappingKey(common.BytesToHash(owner.Bytes()), l.AllowanceSlot, l.Vyper)
+	return nestedKey(common.BytesToHash(spender.Bytes()), inner, l.Vyper)
+}
+
+func mappingKey(key common.Hash, slot uint64, vyper bool) common.Hash {
+	return nestedKey(key, common.BigToHash(new(big.Int).SetUint64(slot)), vyper)
+}
+
+func nestedKey(key, slot common.Hash, vyper bool) common.Hash {
+	if vyper {
+		return crypto.Keccak256Hash(slot.Bytes(), key.Bytes())
+	}
+	return crypto.Keccak256Hash(key.Bytes(), slot.Bytes())
+}
+
+// probeValue is written by FindLayout; no real balance is this value
+var probeValue = common.HexToHash("0x7469746e7469746e7469746e")
+
+// probeAccount stands in for an owner and spender while probing
+var probeAccount = common.HexToAddress("0x000000000000000000000000000000007469746e")
+
+// FindLayout finds token's balance and allowance slots by overriding each
+// candidate slot in turn and reading the value back through balanceOf and
+// allowance. Proxied tokens work, since the override targets the proxy's storage.
+func FindLayout(ctx context.Context, node RPC, token common.Address) (TokenLayout, error) {
+	balanceCall := append(append([]byte{}, balanceOfSelector...), common.LeftPadBytes(probeAccount.Bytes(), 32)...)
+	allowanceCall := append(append([]byte{}, allowanceSelector...), common.LeftPadBytes(probeAccount.Bytes(), 32)...)
+	allowanceCall = append(allowanceCall, common.LeftPadBytes(probeAccount.Bytes(), 32)...)
+
+	for _, vyper := range []bool{false, true} {
+		layout := TokenLayout{Vyper: vyper}
+		found := false
+		for slot := uint64(0); slot < MaxProbeSlot && !found; slot++ {
+			layout.BalanceSlot = slot
+			over := make(Overrides).SetStorage(token, layout.BalanceKey(probeAccount), probeValue)
+			hit, err := probe(ctx, node, token, balanceCall, over)
+			if err != nil {
+				return TokenLayout{}, err
+			}
+			found = hit
+		}
+		if !found {
+			continue
+		}
+		for slot := uint64(0); slot < MaxProbeSlot; slot++ {
+			layout.AllowanceSlot = slot
+			over := make(Overrides).SetStorage(token, layout.AllowanceKey(probeAccount, probeAccount), probeValue)
+			hit, err := probe(ctx, node, token, allowanceCall, over)
+			if err != nil {
+				return TokenLayout{}, err
+			}
+			if hit {
+				return layout, nil
+			}
+		}
+	}
+	return TokenLayout{}, fmt.Errorf("%w for %s in slots 0-%d", ErrLayoutNotFound, token.Hex(), MaxProbeSlot-1)
+}
+
+// probe reports whether the call returns probeValue under over; reverts
+// count as a miss
+func probe(ctx context.Context, node RPC, token common.Address, data []byte, over Overrides) (bool, error) {
+	var ret hexutil.Bytes
+	args := map[string]interface{}{"to": token, "data": hexutil.Bytes(data)}
+	if err := node.CallContext(ctx, &ret, "eth_call", args, "latest", over); err != nil {
+		if rejected(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("probe %s: %w", token.Hex(), err)
+	}
+	return len(ret) >= 32 && common.BytesToHash(ret[:32]) == probeValue, nil
+}
+
+// applyOverrides writes overrides into a fork node's state, for fork-depth
+// what-if simulation inside a snapshot
+func applyOverrides(ctx context.Context, node RPC, overrides Overrides) error {
+	for addr, o := range overrides {
+		if o.Balance != nil {
+			if err := node.CallContext(ctx, nil, "hardhat_setBalance", addr, (*hexutil.Big)(o.Balance)); err != nil {
+				return fmt.Errorf("set balance of %s: %w", addr.Hex(), err)
+			}
+		}
+		if o.Nonce != nil {
+			if err := node.CallContext(ctx, nil, "hardhat_setNonce", addr, hexutil.Uint64(*o.Nonce)); err != nil {
+				return fmt.Errorf("set nonce of %s: %w", addr.Hex(), err)
+			}
+		}
+		if o.Code != nil {
+			if err := node.CallContext(ctx, nil, "hardhat_setCode", addr, hexutil.Bytes(o.Code)); err != nil {
+				return fmt.Errorf("set code of %s: %w", addr.Hex(), err)
+			}
+		}
+		for slot, value := range o.StateDiff {
+			if err := node.CallContext(ctx, nil, "hardhat_setStorageAt", addr, (*hexutil.Big)(slot.Big()), value); err != nil {
+				return fmt.Errorf("set storage of %s: %w", addr.Hex(), err)
+			}
+		}
+	}
+	return nil
+}
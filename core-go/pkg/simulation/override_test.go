@@ -0,0 +1,112 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// fakeToken answers eth_call for a token storing balances at slot 3 and
+// allowances at slot 4, reading only the state overrides it is sent
+type fakeToken struct {
+	token    common.Address
+	executor common.Address
+	calls    int
+}
+
+func (f *fakeToken) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	f.calls++
+	var overrides Overrides
+	if len(args) > 2 {
+		overrides = args[2].(Overrides)
+	}
+	storage := func(key common.Hash) common.Hash {
+		if o, ok := overrides[f.token]; ok {
+			return o.StateDiff[key]
+		}
+		return common.Hash{}
+	}
+	layout := TokenLayout{BalanceSlot: 3, AllowanceSlot: 4}
+	call := args[0].(map[string]interface{})
+	data := call["data"].(hexutil.Bytes)
+	var ret common.Hash
+	switch {
+	case call["to"] == f.token && string(data[:4]) == string(balanceOfSelector):
+		ret = storage(layout.BalanceKey(common.BytesToAddress(data[4:36])))
+	case call["to"] == f.token && string(data[:4]) == string(allowanceSelector):
+		ret = storage(layout.AllowanceKey(common.BytesToAddress(data[4:36]), common.BytesToAddress(data[36:68])))
+	case call["to"] == f.executor:
+		// The trade needs the executor funded with 1000 of the token
+		if storage(layout.BalanceKey(f.executor)).Big().Cmp(big.NewInt(1000)) < 0 {
+			return revertError{}
+		}
+		ret = common.BigToHash(common.Big1)
+	}
+	*result.(*hexutil.Bytes) = ret.Bytes()
+	return nil
+}
+
+func TestFindLayoutProbesSlots(t *testing.T) {
+	node := &fakeToken{token: titantest.Address(0x70)}
+	layout, err := FindLayout(context.Background(), node, node.token)
+	if err != nil {
+		t.Fatalf("FindLayout failed: %v", err)
+	}
+	if layout != (TokenLayout{BalanceSlot: 3, AllowanceSlot: 4}) {
+		t.Errorf("Expected balance slot 3 and allowance slot 4, got %+v", layout)
+	}
+}
+
+func TestWhatIfSimulation(t *testing.T) {
+	node := &fakeToken{token: titantest.Address(0x70), executor: titantest.Address(0xe0)}
+	sim := NewCallSimulator(titantest.NewBackend(137))
+	sim.RPC = node
+	runner := NewRunner(Policy{Default: DepthQuote}, sim)
+
+	layout := TokenLayout{BalanceSlot: 3, AllowanceSlot: 4}
+	trade := Call{From: titantest.Address(0x01), To: node.executor, Data: selector("execute(bytes)")}
+	c := Candidate{
+		Bundle:            []Call{trade},
+		ExpectedProfitUSD: units.DollarsToUSD(5),
+		Overrides:         make(Overrides).Fund(node.token, layout, node.executor, big.NewInt(1000)),
+	}
+	res, err := runner.Simulate(context.Background(), c)
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if res.Depth != DepthCall || !res.OK || !res.WhatIf {
+		t.Errorf("Expected what-if pass at call depth, got %+v", res)
+	}
+
+	c.Overrides = make(Overrides).Fund(node.token, layout, node.executor, big.NewInt(999))
+	if res, _ = runner.Simulate(context.Background(), c); res.OK {
+		t.Errorf("Expected underfunded executor to revert, got %+v", res)
+	}
+
+	// Without raw RPC access the overrides cannot be sent
+	runner = NewRunner(Policy{Default: DepthCall}, NewCallSimulator(titantest.NewBackend(137)))
+	if _, err := runner.Simulate(context.Background(), c); err == nil {
+		t.Error("Expected an error without RPC access")
+	}
+}
+
+func TestOverrideJSON(t *testing.T) {
+	over := make(Overrides).
+		SetBalance(titantest.Address(0x01), big.NewInt(255)).
+		SetNonce(titantest.Address(0x01), 7).
+		SetCode(titantest.Address(0x02), []byte{0x60, 0x00})
+	data, err := json.Marshal(over)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"0x0000000000000000000000000000000000000001":{"balance":"0xff","nonce":"0x7"},"0x0000000000000000000000000000000000000002":{"code":"0x6000"}}`
+	if string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+}
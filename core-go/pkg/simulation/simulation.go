@@ -0,0 +1,170 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// ERC20 ABI for balanceOf
+const erc20ABI = `[{"constant":true,"inputs":[{"name":"_owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"balance","type":"uint256"}],"type":"function"}]`
+
+// decimalsSelector is keccak256("decimals()")[:4]
+var decimalsSelector = []byte{0x31, 0x3c, 0xe5, 0x67}
+
+// TitanSimulationEngine validates liquidity and simulates trades
+type TitanSimulationEngine struct {
+	chainID  uint64
+	provider chain.ChainReader
+}
+
+// New creates a new simulation engine
+func New(chainID uint64, provider chain.ChainReader) *TitanSimulationEngine {
+	return &TitanSimulationEngine{
+		chainID:  chainID,
+		provider: provider,
+	}
+}
+
+// GetLenderTVL checks how deep the lender's pockets are
+// Returns: Total Available Liquidity (raw units)
+func (tse *TitanSimulationEngine) GetLenderTVL(
+	ctx context.Context,
+	tokenAddress common.Address,
+	lenderAddress common.Address,
+) (*big.Int, error) {
+	return GetProviderTVL(tse.provider, tokenAddress, lenderAddress)
+}
+
+// GetLenderLiquidity returns the lender's balance of token as a unit-tagged Amount
+func (tse *TitanSimulationEngine) GetLenderLiquidity(
+	ctx context.Context,
+	tokenAddress common.Address,
+	lenderAddress common.Address,
+	decimals uint8,
+) (units.Amount, error) {
+	balance, err := GetProviderTVL(tse.provider, tokenAddress, lenderAddress)
+	if err != nil {
+		return units.Amount{}, err
+	}
+	return units.FromBig(tokenAddress, balance, decimals)
+}
+
+// GetTokenDecimals reads and validates an ERC20 token's decimals()
+func (tse *TitanSimulationEngine) GetTokenDecimals(ctx context.Context, tokenAddress common.Address) (uint8, error) {
+	msg := ethereum.CallMsg{
+		To:   &tokenAddress,
+		Data: decimalsSelector,
+	}
+	
+	result, err := tse.provider.CallContract(ctx, msg, nil)
+	if err != nil {
+		return 0, fmt.Errorf("decimals() call failed for %s: %w", tokenAddress.Hex(), err)
+	}
+	
+	decimals, err := units.DecodeDecimals(result)
+	if err != nil {
+		return 0, fmt.Errorf("token %s: %w", tokenAddress.Hex(), err)
+	}
+	return decimals, nil
+}
+
+// IsConnected checks if provider is connected
+func (tse *TitanSimulationEngine) IsConnected(ctx context.Context) bool {
+	_, err := tse.provider.BlockNumber(ctx)
+	return err == nil
+}
+
+// GetBlockNumber returns the current block number
+func (tse *TitanSimulationEngine) GetBlockNumber(ctx context.Context) (uint64, error) {
+	return tse.provider.BlockNumber(ctx)
+}
+
+// GetProviderTVL is a standalone function for checking provider liquidity
+func GetProviderTVL(
+	provider chain.ChainReader,
+	tokenAddress common.Address,
+	lenderAddress common.Address,
+) (*big.Int, error) {
+	// Parse the ABI
+	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		log.Printf("Failed to parse ABI: %v", err)
+		return big.NewInt(0), nil
+	}
+
+	// Pack the balanceOf call
+	data, err := parsedABI.Pack("balanceOf", lenderAddress)
+	if err != nil {
+		log.Printf("Failed to pack balanceOf: %v", err)
+		return big.NewInt(0), nil
+	}
+
+	// Make the call
+	msg := ethereum.CallMsg{
+		To:   &tokenAddress,
+		Data: data,
+	}
+
+	result, err := provider.CallContract(context.Background(), msg, nil)
+	if err != nil {
+		log.Printf("Failed to call balanceOf: %v", err)
+		return big.NewInt(0), nil
+	}
+
+	// Unpack the result
+	var balance *big.Int
+	err = parsedABI.UnpackIntoInterface(&balance, "balanceOf", result)
+	if err != nil {
+		log.Printf("Failed to unpack result: %v", err)
+		return big.NewInt(0), nil
+	}
+
+	if balance != nil {
+		log.Printf("TVL for token %s at lender %s: %s", tokenAddress.Hex(), lenderAddress.Hex(), balance.String())
+		return balance, nil
+	}
+
+	return big.NewInt(0), nil
+}
+
+// getReserveDataSelector is keccak256("getReserveData(address)")[:4]
+var getReserveDataSelector = crypto.Keccak256([]byte("getReserveData(address)"))[:4]
+
+// aTokenWord is the index of aTokenAddress in Aave V3's ReserveData
+const aTokenWord = 8
+
+// GetAaveTVL returns the token an Aave V3 pool can lend: the underlying
+// balance held by the reserve's aToken. A token the pool does not list has
+// no liquidity.
+func GetAaveTVL(
+	provider chain.ChainReader,
+	tokenAddress common.Address,
+	poolAddress common.Address,
+) (*big.Int, error) {
+	msg := ethereum.CallMsg{
+		To:   &poolAddress,
+		Data: append(append([]byte{}, getReserveDataSelector...), common.LeftPadBytes(tokenAddress.Bytes(), 32)...),
+	}
+	result, err := provider.CallContract(context.Background(), msg, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getReserveData(%s) on %s: %w", tokenAddress.Hex(), poolAddress.Hex(), err)
+	}
+	if len(result) < (aTokenWord+1)*32 {
+		return big.NewInt(0), nil
+	}
+	aToken := common.BytesToAddress(result[aTokenWord*32 : (aTokenWord+1)*32])
+	if aToken == (common.Address{}) {
+		return big.NewInt(0), nil
+	}
+	return GetProviderTVL(provider, tokenAddress, aToken)
+}
@@ -0,0 +1,43 @@
+package simulation
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/chain"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+)
+
+var _ chain.ChainReader = (*titantest.Backend)(nil)
+
+func TestEngineReadsThroughChainReader(t *testing.T) {
+	backend := titantest.NewBackend(137)
+	token := common.HexToAddress("0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359")
+	lender := common.HexToAddress("0xbA1333333333a1BA1108E8412f11850A5C319bA9")
+	backend.SetDecimals(token, 6)
+	backend.SetBalance(token, lender, big.NewInt(2_500_000))
+	backend.Mine(3, 0)
+
+	engine := New(137, backend)
+	ctx := context.Background()
+
+	if !engine.IsConnected(ctx) {
+		t.Error("Expected engine to report connected")
+	}
+	if block, err := engine.GetBlockNumber(ctx); err != nil || block != 3 {
+		t.Errorf("Expected block 3, got %d (%v)", block, err)
+	}
+	decimals, err := engine.GetTokenDecimals(ctx, token)
+	if err != nil || decimals != 6 {
+		t.Errorf("Expected 6 decimals, got %d (%v)", decimals, err)
+	}
+	liquidity, err := engine.GetLenderLiquidity(ctx, token, lender, decimals)
+	if err != nil {
+		t.Fatalf("GetLenderLiquidity failed: %v", err)
+	}
+	if liquidity.String() != "2.5" {
+		t.Errorf("Expected 2.5, got %s", liquidity)
+	}
+}
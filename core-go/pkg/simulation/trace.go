@@ -0,0 +1,190 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/route"
+)
+
+// ErrTraceUnsupported is returned for chains whose node lacks debug_traceCall
+var ErrTraceUnsupported = errors.New("simulation: debug_traceCall unsupported")
+
+// CallFrame is one call of a callTracer trace
+type CallFrame struct {
+	Type    string         `json:"type"`
+	From    common.Address `json:"from"`
+	To      common.Address `json:"to"`
+	Gas     hexutil.Uint64 `json:"gas"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+	Input   hexutil.Bytes  `json:"input"`
+	Output  hexutil.Bytes  `json:"output,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Calls   []CallFrame    `json:"calls,omitempty"`
+}
+
+// StepGas is the gas one route step used inside a traced transaction
+type StepGas struct {
+	Adapter string         `json:"adapter"`
+	Pool    common.Address `json:"pool"`
+	GasUsed uint64         `json:"gasUsed"`
+	Found   bool           `json:"found"` // false when no call reached the pool
+}
+
+// Attribution splits a traced transaction's gas across its route steps;
+// Overhead is everything else: intrinsic gas, flash loan and token transfers
+type Attribution struct {
+	GasUsed  uint64    `json:"gasUsed"`
+	Steps    []StepGas `json:"steps"`
+	Overhead uint64    `json:"overhead"`
+	Reverted string    `json:"reverted,omitempty"`
+}
+
+// TraceCall runs call through debug_traceCall with the call tracer, applying
+// overrides when there are any
+func TraceCall(ctx context.Context, node RPC, call Call, overrides Overrides) (*CallFrame, error) {
+	args := map[string]interface{}{
+		"from": call.From,
+		"to":   call.To,
+		"data": hexutil.Bytes(call.Data),
+	}
+	if call.Value != nil {
+		args["value"] = (*hexutil.Big)(call.Value)
+	}
+	if call.Gas > 0 {
+		args["gas"] = hexutil.Uint64(call.Gas)
+	}
+	config := map[string]interface{}{"tracer": "callTracer"}
+	if len(overrides) > 0 {
+		config["stateOverrides"] = overrides
+	}
+	var frame CallFrame
+	if err := node.CallContext(ctx, &frame, "debug_traceCall", args, "latest", config); err != nil {
+		if traceUnsupported(err) {
+			return nil, fmt.Errorf("%w: %v", ErrTraceUnsupported, err)
+		}
+		return nil, err
+	}
+	return &frame, nil
+}
+
+// Attribute charges each step the gas of the outermost calls into its pool,
+// claiming calls in order so a pool visited twice is split between its steps
+func Attribute(frame *CallFrame, steps []route.Step) *Attribution {
+	a := &Attribution{GasUsed: uint64(frame.GasUsed), Steps: make([]StepGas, len(steps)), Reverted: frame.Error}
+	var calls []*CallFrame
+	var walk func(f *CallFrame)
+	walk = func(f *CallFrame) {
+		for i := range f.Calls {
+			c := &f.Calls[i]
+			calls = append(calls, c)
+			walk(c)
+		}
+	}
+	walk(frame)
+
+	claimed := make(map[*CallFrame]bool)
+	next := 0
+	var attributed uint64
+	for i, s := range steps {
+		a.Steps[i] = StepGas{Adapter: s.Adapter.Name(), Pool: s.Pool}
+		for j := next; j < len(calls); j++ {
+			c := calls[j]
+			if c.To != s.Pool || claimed[c] {
+				continue
+			}
+			a.Steps[i].GasUsed, a.Steps[i].Found = uint64(c.GasUsed), true
+			attributed += uint64(c.GasUsed)
+			claimInner(c, claimed)
+			next = j + 1
+			break
+		}
+	}
+	if attributed < a.GasUsed {
+		a.Overhead = a.GasUsed - attributed
+	}
+	return a
+}
+
+// claimInner marks a call and everything under it as attributed
+func claimInner(f *CallFrame, claimed map[*CallFrame]bool) {
+	claimed[f] = true
+	for i := range f.Calls {
+		claimInner(&f.Calls[i], claimed)
+	}
+}
+
+// Tracer traces candidates on chains that support debug_traceCall and feeds
+// per-step gas into a model. Chains answering "method not found" are
+// remembered and skipped.
+type Tracer struct {
+	model *gas.Model
+
+	// Supports, when set, reports whether a chain's node serves
+	// debug_traceCall, so chains known to lack it are skipped unasked
+	Supports func(chainID uint64) bool
+
+	mu          sync.Mutex
+	unsupported map[uint64]bool
+}
+
+// NewTracer creates a tracer feeding model
+func NewTracer(model *gas.Model) *Tracer {
+	return &Tracer{model: model, unsupported: make(map[uint64]bool)}
+}
+
+// Trace traces the candidate's trade and records the gas of every step found
+// in a successful trace. It returns ErrTraceUnsupported for chains without
+// debug_traceCall.
+func (t *Tracer) Trace(ctx context.Context, node RPC, c Candidate, steps []route.Step) (*Attribution, error) {
+	if len(c.Bundle) == 0 {
+		return nil, errors.New("empty bundle")
+	}
+	t.mu.Lock()
+	skip := t.unsupported[c.ChainID]
+	t.mu.Unlock()
+	if skip || (t.Supports != nil && !t.Supports(c.ChainID)) {
+		return nil, ErrTraceUnsupported
+	}
+	frame, err := TraceCall(ctx, node, c.Bundle[len(c.Bundle)-1], c.Overrides)
+	if err != nil {
+		if errors.Is(err, ErrTraceUnsupported) {
+			t.mu.Lock()
+			t.unsupported[c.ChainID] = true
+			t.mu.Unlock()
+		}
+		return nil, err
+	}
+	a := Attribute(frame, steps)
+	if a.Reverted == "" && t.model != nil {
+		for _, s := range a.Steps {
+			if s.Found {
+				t.model.Observe(c.ChainID, s.Adapter, s.GasUsed)
+			}
+		}
+	}
+	return a, nil
+}
+
+// traceUnsupported reports whether err is the node refusing the debug
+// namespace rather than the trace failing
+func traceUnsupported(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601 {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"method not found", "not supported", "unsupported method", "does not exist", "not available"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/gas"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/route"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+)
+
+// fakeTracer answers debug_traceCall with a fixed frame, or errs
+type fakeTracer struct {
+	frame CallFrame
+	err   error
+	calls int
+}
+
+func (f *fakeTracer) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	f.calls++
+	if f.err != nil {
+		return f.err
+	}
+	*result.(*CallFrame) = f.frame
+	return nil
+}
+
+func TestTracerAttributesStepGas(t *testing.T) {
+	v2, v3, token := titantest.Address(0xa2), titantest.Address(0xa3), titantest.Address(0x70)
+	node := &fakeTracer{frame: CallFrame{To: titantest.Address(0xe0), GasUsed: 300_000, Calls: []CallFrame{
+		{To: token, GasUsed: 30_000}, // transfer into the V2 pair
+		{To: v2, GasUsed: 90_000, Calls: []CallFrame{{To: token, GasUsed: 25_000}}},
+		{To: v3, GasUsed: 120_000, Calls: []CallFrame{{To: titantest.Address(0xe0), GasUsed: 40_000}}},
+	}}}
+	steps := []route.Step{{Adapter: route.AdapterUniV2, Pool: v2}, {Adapter: route.AdapterUniV3, Pool: v3}}
+
+	model := gas.NewModel(nil)
+	tracer := NewTracer(model)
+	trade := Call{From: titantest.Address(0x01), To: titantest.Address(0xe0)}
+	a, err := tracer.Trace(context.Background(), node, Candidate{ChainID: 137, Bundle: []Call{trade}}, steps)
+	if err != nil {
+		t.Fatalf("Trace failed: %v", err)
+	}
+	if a.Steps[0].GasUsed != 90_000 || a.Steps[1].GasUsed != 120_000 || a.Overhead != 90_000 {
+		t.Errorf("Expected 90000 and 120000 per step with 90000 overhead, got %+v", a)
+	}
+	if got := model.Estimate(137, "univ3"); got != 120_000 {
+		t.Errorf("Expected modelled univ3 gas 120000, got %d", got)
+	}
+	if got := model.Estimate(1, "univ3"); got != gas.DefaultSwapGas["univ3"] {
+		t.Errorf("Expected default gas on an untraced chain, got %d", got)
+	}
+
+	// A chain without the debug namespace is remembered and not asked again
+	node.err = errors.New("the method debug_traceCall does not exist/is not available")
+	if _, err := tracer.Trace(context.Background(), node, Candidate{ChainID: 10, Bundle: []Call{trade}}, steps); !errors.Is(err, ErrTraceUnsupported) {
+		t.Fatalf("Expected ErrTraceUnsupported, got %v", err)
+	}
+	calls := node.calls
+	if _, err := tracer.Trace(context.Background(), node, Candidate{ChainID: 10, Bundle: []Call{trade}}, steps); !errors.Is(err, ErrTraceUnsupported) || node.calls != calls {
+		t.Errorf("Expected unsupported chain to be skipped, got %v after %d calls", err, node.calls-calls)
+	}
+
+	// Nor is a chain whose provider was probed without it
+	tracer.Supports = func(chainID uint64) bool { return chainID != 137 }
+	if _, err := tracer.Trace(context.Background(), node, Candidate{ChainID: 137, Bundle: []Call{trade}}, steps); !errors.Is(err, ErrTraceUnsupported) || node.calls != calls {
+		t.Errorf("Expected a chain probed without debug_traceCall to be skipped, got %v", err)
+	}
+}
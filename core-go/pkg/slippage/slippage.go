@@ -0,0 +1,156 @@
+package slippage
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+// Observation compares a leg's predicted output with what the chain delivered
+type Observation struct {
+	Dex       string         `json:"dex"`
+	PoolKind  string         `json:"poolKind"`
+	Pool      common.Address `json:"pool"`
+	Token     common.Address `json:"token"`
+	Predicted *big.Int       `json:"predicted"`
+	Realized  *big.Int       `json:"realized"`
+}
+
+// ErrorBps returns (predicted - realized) / predicted in basis points; positive
+// values mean the prediction was optimistic
+func (o Observation) ErrorBps() int64 {
+	if o.Predicted == nil || o.Realized == nil || o.Predicted.Sign() <= 0 {
+		return 0
+	}
+	diff := new(big.Int).Sub(o.Predicted, o.Realized)
+	diff.Mul(diff, big.NewInt(10000))
+	diff.Quo(diff, o.Predicted)
+	if !diff.IsInt64() {
+		if diff.Sign() > 0 {
+			return 10000
+		}
+		return -10000
+	}
+	return diff.Int64()
+}
+
+// Stat is the aggregated prediction error for one DEX and pool kind
+type Stat struct {
+	Dex           string  `json:"dex"`
+	PoolKind      string  `json:"poolKind"`
+	Samples       int     `json:"samples"`
+	MeanErrorBps  float64 `json:"meanErrorBps"`
+	MaxErrorBps   int64   `json:"maxErrorBps"`
+	OptimisticPct float64 `json:"optimisticPct"` // share of samples that under-delivered
+}
+
+// Tracker aggregates observations per DEX and pool kind and mirrors them into metrics
+type Tracker struct {
+	mu    sync.Mutex
+	stats map[[2]string]*accumulator
+
+	observations *metrics.CounterVec
+	errorBps     *metrics.HistogramVec
+	meanErrorBps *metrics.GaugeVec
+}
+
+type accumulator struct {
+	samples    int
+	sum        int64
+	max        int64
+	optimistic int
+}
+
+// errorBuckets are histogram bounds for prediction error in bps
+var errorBuckets = []float64{-100, -25, -5, 0, 5, 10, 25, 50, 100, 250, 1000}
+
+// NewTracker creates a tracker; reg may be nil to skip metrics
+func NewTracker(reg *metrics.Registry) *Tracker {
+	t := &Tracker{stats: make(map[[2]string]*accumulator)}
+	if reg != nil {
+		t.observations = reg.Counter("titan_slippage_observations_total", "Fills compared against predicted output", "dex", "pool_kind")
+		t.errorBps = reg.Histogram("titan_slippage_error_bps", "Predicted minus realized output, in bps of predicted", errorBuckets, "dex", "pool_kind")
+		t.meanErrorBps = reg.Gauge("titan_slippage_mean_error_bps", "Mean prediction error in bps; positive is optimistic", "dex", "pool_kind")
+	}
+	return t
+}
+
+// Observe records one fill
+func (t *Tracker) Observe(o Observation) {
+	errBps := o.ErrorBps()
+
+	t.mu.Lock()
+	key := [2]string{o.Dex, o.PoolKind}
+	acc := t.stats[key]
+	if acc == nil {
+		acc = &accumulator{max: errBps}
+		t.stats[key] = acc
+	}
+	acc.samples++
+	acc.sum += errBps
+	if errBps > acc.max {
+		acc.max = errBps
+	}
+	if errBps > 0 {
+		acc.optimistic++
+	}
+	mean := float64(acc.sum) / float64(acc.samples)
+	t.mu.Unlock()
+
+	if t.observations != nil {
+		t.observations.Inc(o.Dex, o.PoolKind)
+		t.errorBps.Observe(float64(errBps), o.Dex, o.PoolKind)
+		t.meanErrorBps.Set(mean, o.Dex, o.PoolKind)
+	}
+}
+
+// Stats returns per-DEX statistics, worst mean error first
+func (t *Tracker) Stats() []Stat {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]Stat, 0, len(t.stats))
+	for key, acc := range t.stats {
+		out = append(out, Stat{
+			Dex:           key[0],
+			PoolKind:      key[1],
+			Samples:       acc.samples,
+			MeanErrorBps:  float64(acc.sum) / float64(acc.samples),
+			MaxErrorBps:   acc.max,
+			OptimisticPct: 100 * float64(acc.optimistic) / float64(acc.samples),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].MeanErrorBps != out[j].MeanErrorBps {
+			return out[i].MeanErrorBps > out[j].MeanErrorBps
+		}
+		return out[i].Dex+out[i].PoolKind < out[j].Dex+out[j].PoolKind
+	})
+	return out
+}
+
+// Optimistic returns adapters whose mean error exceeds thresholdBps over at least minSamples fills
+func (t *Tracker) Optimistic(thresholdBps float64, minSamples int) []Stat {
+	var out []Stat
+	for _, s := range t.Stats() {
+		if s.Samples >= minSamples && s.MeanErrorBps > thresholdBps {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Correction returns the haircut in bps to apply to quotes from an adapter, or 0
+// when there is not enough evidence of optimism
+func (t *Tracker) Correction(dex, poolKind string, minSamples int) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	acc := t.stats[[2]string{dex, poolKind}]
+	if acc == nil || acc.samples < minSamples || acc.sum <= 0 {
+		return 0
+	}
+	return acc.sum / int64(acc.samples)
+}
@@ -0,0 +1,62 @@
+package slippage
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+func fill(dex string, predicted, realized int64) Observation {
+	return Observation{Dex: dex, PoolKind: "v2", Predicted: big.NewInt(predicted), Realized: big.NewInt(realized)}
+}
+
+func TestErrorBps(t *testing.T) {
+	if got := fill("x", 10000, 9900).ErrorBps(); got != 100 {
+		t.Errorf("Expected 100 bps optimistic error, got %d", got)
+	}
+	if got := fill("x", 10000, 10050).ErrorBps(); got != -50 {
+		t.Errorf("Expected -50 bps pessimistic error, got %d", got)
+	}
+	if got := fill("x", 0, 10).ErrorBps(); got != 0 {
+		t.Errorf("Expected 0 for empty prediction, got %d", got)
+	}
+}
+
+func TestTrackerFlagsOptimisticAdapters(t *testing.T) {
+	reg := metrics.NewRegistry()
+	tracker := NewTracker(reg)
+	for i := 0; i < 5; i++ {
+		tracker.Observe(fill("fot_fork", 10000, 9800)) // fee-on-transfer under-delivery
+		tracker.Observe(fill("uniswap_v2", 10000, 10000))
+	}
+
+	stats := tracker.Stats()
+	if len(stats) != 2 || stats[0].Dex != "fot_fork" {
+		t.Fatalf("Expected fot_fork first, got %+v", stats)
+	}
+	if stats[0].MeanErrorBps != 200 || stats[0].OptimisticPct != 100 {
+		t.Errorf("Expected 200 bps mean at 100%% optimistic, got %+v", stats[0])
+	}
+
+	flagged := tracker.Optimistic(10, 5)
+	if len(flagged) != 1 || flagged[0].Dex != "fot_fork" {
+		t.Errorf("Expected only fot_fork flagged, got %+v", flagged)
+	}
+	if got := tracker.Correction("fot_fork", "v2", 5); got != 200 {
+		t.Errorf("Expected 200 bps correction, got %d", got)
+	}
+	if got := tracker.Correction("uniswap_v2", "v2", 5); got != 0 {
+		t.Errorf("Expected no correction for accurate adapter, got %d", got)
+	}
+
+	if got := reg.Value("titan_slippage_observations_total", "fot_fork", "v2"); got != 5 {
+		t.Errorf("Expected 5 observations, got %v", got)
+	}
+	var b strings.Builder
+	reg.WriteText(&b)
+	if !strings.Contains(b.String(), `titan_slippage_mean_error_bps{dex="fot_fork",pool_kind="v2"} 200`) {
+		t.Errorf("Expected mean error gauge in exposition, got:\n%s", b.String())
+	}
+}
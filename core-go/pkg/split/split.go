@@ -0,0 +1,284 @@
+package split
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/amm"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// ErrNoPools is returned when no pool trades the requested pair
+var ErrNoPools = errors.New("split: no pools for pair")
+
+// ErrNoSplitRoute is returned for plans using more than one pool: the
+// executor's RouteEncoding (docs/CANONICAL_SPECIFICATION.md) only has
+// sequential RAW_ADDRESSES and REGISTRY_ENUMS hops, so slices swapped in
+// parallel cannot be sent as one execute call
+var ErrNoSplitRoute = errors.New("split: the executor has no split route encoding")
+
+// DefaultMinSliceBps drops slices smaller than this share of the order
+const DefaultMinSliceBps = 500
+
+// floatPrec is the big.Float precision used while solving the allocation
+const floatPrec = 256
+
+// Slice is the part of an order routed through one pool
+type Slice struct {
+	Pool      common.Address `json:"pool"`
+	Dex       string         `json:"dex"`
+	Kind      string         `json:"kind"`
+	FeeBps    uint32         `json:"feeBps"`
+	AmountIn  *big.Int       `json:"amountIn"`
+	AmountOut *big.Int       `json:"amountOut"`
+}
+
+// Plan is an order split across pools of one pair
+type Plan struct {
+	TokenIn   common.Address `json:"tokenIn"`
+	TokenOut  common.Address `json:"tokenOut"`
+	AmountIn  *big.Int       `json:"amountIn"`
+	AmountOut *big.Int       `json:"amountOut"`
+	Slices    []Slice        `json:"slices"`
+
+	// SingleOut is the best output from routing the whole order through one pool
+	SingleOut *big.Int `json:"singleOut"`
+}
+
+// Split reports whether the plan uses more than one pool
+func (p *Plan) Split() bool {
+	return len(p.Slices) > 1
+}
+
+// Executable reports whether the plan can be sent as one execute call; only
+// a plan routing the whole order through one pool can (ErrNoSplitRoute)
+func (p *Plan) Executable() error {
+	if p.Split() {
+		return fmt.Errorf("%w: %d slices", ErrNoSplitRoute, len(p.Slices))
+	}
+	return nil
+}
+
+// GainBps is the plan's extra output over the best single pool, in basis points
+func (p *Plan) GainBps() int64 {
+	if p.SingleOut == nil || p.SingleOut.Sign() == 0 {
+		return 0
+	}
+	gain := new(big.Int).Sub(p.AmountOut, p.SingleOut)
+	gain.Mul(gain, big.NewInt(units.BpsDenominator))
+	return gain.Quo(gain, p.SingleOut).Int64()
+}
+
+// Optimizer sizes orders across the pools of a pair so every used pool ends
+// at the same marginal price
+type Optimizer struct {
+	// MaxSlices bounds how many pools one order is split across
+	MaxSlices int
+	// MinSliceBps drops slices below this share of the order, since each
+	// extra pool costs gas
+	MinSliceBps uint64
+}
+
+// NewOptimizer creates an optimizer splitting across at most maxSlices pools
+func NewOptimizer(maxSlices int) *Optimizer {
+	return &Optimizer{MaxSlices: maxSlices, MinSliceBps: DefaultMinSliceBps}
+}
+
+// candidate is a pool oriented in the order's direction
+type candidate struct {
+	snap       *reserves.Snapshot
+	zeroForOne bool
+	reserveIn  *big.Float
+	reserveOut *big.Float
+	gamma      *big.Float // 1 - fee
+}
+
+// Optimize splits amountIn of tokenIn across pools. For constant-product
+// pools the marginal output of x in is g*Rin*Rout/(Rin+g*x)^2, so equal
+// marginal prices give x_i = (sqrt(Rin_i*Rout_i/(g_i*l)) - Rin_i/g_i) for a
+// common l; l is solved from the order size, and pools whose share would be
+// negative or below MinSliceBps are dropped and the rest re-solved.
+func (o *Optimizer) Optimize(pools []*reserves.Snapshot, tokenIn common.Address, amountIn *big.Int) (*Plan, error) {
+	if amountIn.Sign() <= 0 {
+		return nil, amm.ErrInvalidAmount
+	}
+	var cands []*candidate
+	var tokenOut common.Address
+	for _, s := range pools {
+		if s.Token0 != tokenIn && s.Token1 != tokenIn {
+			continue
+		}
+		c := orient(s, tokenIn)
+		if c == nil {
+			continue
+		}
+		out := s.Token1
+		if !c.zeroForOne {
+			out = s.Token0
+		}
+		if tokenOut == (common.Address{}) {
+			tokenOut = out
+		} else if out != tokenOut {
+			continue
+		}
+		cands = append(cands, c)
+	}
+	if len(cands) == 0 {
+		return nil, ErrNoPools
+	}
+
+	plan := &Plan{TokenIn: tokenIn, TokenOut: tokenOut, AmountIn: amountIn}
+	plan.SingleOut = bestSingle(cands, amountIn)
+	all := cands
+
+	// Deepest pools first, so MaxSlices keeps the ones that matter
+	sort.Slice(cands, func(i, j int) bool {
+		return new(big.Float).Mul(cands[i].reserveIn, cands[i].reserveOut).Cmp(new(big.Float).Mul(cands[j].reserveIn, cands[j].reserveOut)) > 0
+	})
+	if o.MaxSlices > 0 && len(cands) > o.MaxSlices {
+		cands = cands[:o.MaxSlices]
+	}
+
+	total := new(big.Float).SetPrec(floatPrec).SetInt(amountIn)
+	active := cands
+	var sizes []*big.Float
+	for {
+		sizes = allocate(active, total)
+		var keep []*candidate
+		minSize := new(big.Float).Mul(total, big.NewFloat(float64(o.MinSliceBps)/units.BpsDenominator))
+		for i, c := range active {
+			if sizes[i].Sign() > 0 && (len(active) == 1 || sizes[i].Cmp(minSize) >= 0) {
+				keep = append(keep, c)
+			}
+		}
+		if len(keep) == len(active) {
+			break
+		}
+		if len(keep) == 0 {
+			// Every pool fell under the minimum slice; use the deepest alone
+			keep = active[:1]
+		}
+		active = keep
+	}
+
+	amounts := toAmounts(sizes, amountIn)
+	plan.AmountOut = new(big.Int)
+	for i, c := range active {
+		if amounts[i].Sign() == 0 {
+			continue
+		}
+		out, err := c.snap.AMM().Quote(amounts[i], c.zeroForOne)
+		if err != nil {
+			return nil, err
+		}
+		plan.Slices = append(plan.Slices, Slice{
+			Pool:      c.snap.Pool,
+			Dex:       c.snap.Dex,
+			Kind:      c.snap.Kind,
+			FeeBps:    c.snap.FeeBps,
+			AmountIn:  amounts[i],
+			AmountOut: out,
+		})
+		plan.AmountOut.Add(plan.AmountOut, out)
+	}
+
+	// Rounding can leave a split marginally worse than the best single pool
+	if plan.SingleOut != nil && plan.AmountOut.Cmp(plan.SingleOut) < 0 {
+		return single(plan, all, amountIn)
+	}
+	return plan, nil
+}
+
+// orient views a pool in the order's direction; nil when it is empty
+func orient(s *reserves.Snapshot, tokenIn common.Address) *candidate {
+	if s.Reserve0 == nil || s.Reserve1 == nil || s.Reserve0.Sign() <= 0 || s.Reserve1.Sign() <= 0 {
+		return nil
+	}
+	c := &candidate{snap: s, zeroForOne: s.ZeroForOne(tokenIn)}
+	rin, rout := s.Reserve0, s.Reserve1
+	if !c.zeroForOne {
+		rin, rout = s.Reserve1, s.Reserve0
+	}
+	c.reserveIn = new(big.Float).SetPrec(floatPrec).SetInt(rin)
+	c.reserveOut = new(big.Float).SetPrec(floatPrec).SetInt(rout)
+	c.gamma = new(big.Float).SetPrec(floatPrec).Quo(
+		big.NewFloat(float64(units.BpsDenominator-s.FeeBps)),
+		big.NewFloat(units.BpsDenominator))
+	return c
+}
+
+// allocate solves the equal-marginal-price sizes for pools; negative sizes
+// mean the pool is too shallow to take any of the order at that price
+func allocate(pools []*candidate, total *big.Float) []*big.Float {
+	// 1/sqrt(l) = (total + sum Rin/g) / sum sqrt(Rin*Rout/g)
+	offset := new(big.Float).SetPrec(floatPrec)
+	depth := new(big.Float).SetPrec(floatPrec)
+	roots := make([]*big.Float, len(pools))
+	for i, c := range pools {
+		offset.Add(offset, new(big.Float).SetPrec(floatPrec).Quo(c.reserveIn, c.gamma))
+		k := new(big.Float).SetPrec(floatPrec).Mul(c.reserveIn, c.reserveOut)
+		k.Quo(k, c.gamma)
+		roots[i] = k.Sqrt(k)
+		depth.Add(depth, roots[i])
+	}
+	scale := new(big.Float).SetPrec(floatPrec).Add(total, offset)
+	scale.Quo(scale, depth)
+
+	sizes := make([]*big.Float, len(pools))
+	for i, c := range pools {
+		size := new(big.Float).SetPrec(floatPrec).Mul(roots[i], scale)
+		sizes[i] = size.Sub(size, new(big.Float).SetPrec(floatPrec).Quo(c.reserveIn, c.gamma))
+	}
+	return sizes
+}
+
+// toAmounts rounds sizes down to integers and gives the remainder to the
+// largest slice, so the slices sum exactly to amountIn
+func toAmounts(sizes []*big.Float, amountIn *big.Int) []*big.Int {
+	amounts := make([]*big.Int, len(sizes))
+	sum := new(big.Int)
+	largest := 0
+	for i, size := range sizes {
+		amounts[i], _ = size.Int(nil)
+		if amounts[i].Sign() < 0 {
+			amounts[i].SetInt64(0)
+		}
+		sum.Add(sum, amounts[i])
+		if amounts[i].Cmp(amounts[largest]) > 0 {
+			largest = i
+		}
+	}
+	amounts[largest].Add(amounts[largest], sum.Sub(amountIn, sum))
+	return amounts
+}
+
+// bestSingle returns the best output from one pool taking the whole order
+func bestSingle(cands []*candidate, amountIn *big.Int) *big.Int {
+	var best *big.Int
+	for _, c := range cands {
+		out, err := c.snap.AMM().Quote(amountIn, c.zeroForOne)
+		if err == nil && (best == nil || out.Cmp(best) > 0) {
+			best = out
+		}
+	}
+	return best
+}
+
+// single routes the whole order through the best pool
+func single(plan *Plan, cands []*candidate, amountIn *big.Int) (*Plan, error) {
+	plan.Slices = nil
+	for _, c := range cands {
+		out, err := c.snap.AMM().Quote(amountIn, c.zeroForOne)
+		if err != nil || out.Cmp(plan.SingleOut) != 0 {
+			continue
+		}
+		plan.Slices = []Slice{{Pool: c.snap.Pool, Dex: c.snap.Dex, Kind: c.snap.Kind, FeeBps: c.snap.FeeBps, AmountIn: amountIn, AmountOut: out}}
+		plan.AmountOut = out
+		return plan, nil
+	}
+	return nil, amm.ErrInsufficientLiquidity
+}
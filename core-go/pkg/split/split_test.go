@@ -0,0 +1,116 @@
+package split
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/reserves"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+)
+
+var (
+	usdc = titantest.Address(0x01)
+	weth = titantest.Address(0x02)
+)
+
+func pool(n uint64, kind string, usdcReserve, wethReserve int64) *reserves.Snapshot {
+	return &reserves.Snapshot{
+		ChainID: 1, Dex: "DEX", Kind: kind, Pool: titantest.Address(n),
+		Token0: usdc, Token1: weth,
+		Reserve0: titantest.Units(usdcReserve, 6), Reserve1: titantest.Units(wethReserve, 18),
+		FeeBps: 30,
+	}
+}
+
+// marginal returns the pool's output for one more unit after amountIn, a proxy for marginal price
+func marginal(t *testing.T, s *reserves.Snapshot, amountIn *big.Int) *big.Int {
+	unit := titantest.Units(100, 6)
+	a, err := s.AMM().Quote(amountIn, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.AMM().Quote(new(big.Int).Add(amountIn, unit), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b.Sub(b, a)
+}
+
+func TestOptimizeEqualizesMarginalPrice(t *testing.T) {
+	deep := pool(0xa0, reserves.KindV2, 20_000_000, 10_000)
+	shallow := pool(0xb0, reserves.KindV3, 5_000_000, 2_500)
+	amountIn := titantest.Units(1_000_000, 6)
+
+	plan, err := NewOptimizer(4).Optimize([]*reserves.Snapshot{shallow, deep}, usdc, amountIn)
+	if err != nil {
+		t.Fatalf("Optimize failed: %v", err)
+	}
+	if !plan.Split() || plan.TokenOut != weth {
+		t.Fatalf("Expected a WETH split across both pools, got %+v", plan)
+	}
+	sum := new(big.Int)
+	for _, s := range plan.Slices {
+		sum.Add(sum, s.AmountIn)
+	}
+	if sum.Cmp(amountIn) != 0 {
+		t.Errorf("Expected slices to total %s, got %s", amountIn, sum)
+	}
+	if plan.AmountOut.Cmp(plan.SingleOut) <= 0 || plan.GainBps() <= 0 {
+		t.Errorf("Expected split to beat the single pool, got %s vs %s", plan.AmountOut, plan.SingleOut)
+	}
+
+	// Same price and fee, so the deep pool takes four fifths of the order (to rounding)
+	share := new(big.Int).Sub(plan.Slices[0].AmountIn, titantest.Units(800_000, 6))
+	if plan.Slices[0].Pool != deep.Pool || share.Abs(share).Cmp(big.NewInt(1)) > 0 {
+		t.Errorf("Expected 800000 USDC through the deep pool, got %s", plan.Slices[0].AmountIn)
+	}
+	m0 := marginal(t, deep, plan.Slices[0].AmountIn)
+	m1 := marginal(t, shallow, plan.Slices[1].AmountIn)
+	diff := new(big.Int).Sub(m0, m1)
+	if diff.Abs(diff).Cmp(new(big.Int).Quo(m0, big.NewInt(10_000))) > 0 {
+		t.Errorf("Expected equal marginal output, got %s and %s", m0, m1)
+	}
+}
+
+func TestOptimizeDropsDustSlices(t *testing.T) {
+	deep := pool(0xa0, reserves.KindV2, 20_000_000, 10_000)
+	tiny := pool(0xb0, reserves.KindV2, 20_000, 10)
+
+	plan, err := NewOptimizer(4).Optimize([]*reserves.Snapshot{deep, tiny}, usdc, titantest.Units(10_000, 6))
+	if err != nil {
+		t.Fatalf("Optimize failed: %v", err)
+	}
+	if plan.Split() || plan.Slices[0].Pool != deep.Pool {
+		t.Errorf("Expected the whole order through the deep pool, got %+v", plan.Slices)
+	}
+
+	if _, err := NewOptimizer(4).Optimize([]*reserves.Snapshot{deep}, titantest.Address(0x03), titantest.Units(1, 6)); !errors.Is(err, ErrNoPools) {
+		t.Errorf("Expected ErrNoPools, got %v", err)
+	}
+}
+
+func TestSplitPlanIsNotExecutable(t *testing.T) {
+	pools := []*reserves.Snapshot{
+		pool(0xa0, reserves.KindV2, 20_000_000, 10_000),
+		pool(0xb0, reserves.KindV3, 5_000_000, 2_500),
+	}
+	plan, err := NewOptimizer(4).Optimize(pools, usdc, titantest.Units(1_000_000, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Split() {
+		t.Fatalf("Expected a split plan, got %+v", plan.Slices)
+	}
+	if err := plan.Executable(); !errors.Is(err, ErrNoSplitRoute) {
+		t.Errorf("Expected ErrNoSplitRoute, got %v", err)
+	}
+
+	single, err := NewOptimizer(1).Optimize(pools, usdc, titantest.Units(1_000_000, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := single.Executable(); err != nil {
+		t.Errorf("Expected a single-pool plan to be executable, got %v", err)
+	}
+}
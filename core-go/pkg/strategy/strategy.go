@@ -0,0 +1,306 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Decisions counted per instance
+const (
+	DecisionAccept     = "accept"
+	DecisionReject     = "reject"
+	DecisionOverBudget = "over_budget"
+)
+
+// ErrOverBudget is returned reserving beyond an instance's risk budget
+var ErrOverBudget = errors.New("strategy: over budget")
+
+// Budget is an instance's risk budget and what it has used
+type Budget struct {
+	DailyLossUSD   units.USD `json:"dailyLossUsd,omitempty"` // 0 is unbounded
+	MaxInFlightUSD units.USD `json:"maxInFlightUsd,omitempty"`
+	PnLTodayUSD    units.USD `json:"pnlTodayUsd"` // realized since UTC midnight
+	InFlightUSD    units.USD `json:"inFlightUsd"`
+	InFlight       int       `json:"inFlight"`
+	Paused         bool      `json:"paused"` // the day's loss reached the budget
+}
+
+// Status describes an instance for operators
+type Status struct {
+	Name       string             `json:"name"`
+	Kind       string             `json:"kind"`
+	Tokens     []string           `json:"tokens,omitempty"`
+	Guardrails *config.Guardrails `json:"guardrails,omitempty"` // nil follows the live guardrails
+	Wallets    []common.Address   `json:"wallets,omitempty"`
+	Budget     Budget             `json:"budget"`
+}
+
+// Instance is one named strategy: the opportunities it covers, the limits it
+// takes them at and the risk it may carry. Instances never share budgets.
+type Instance struct {
+	Name    string
+	Kind    string
+	Tokens  []string
+	Wallets []common.Address
+
+	guardrails *config.Guardrails // nil follows the live process guardrails
+	universe   map[string]bool    // upper-cased symbols and addresses; empty covers everything
+	registry   *tokens.Registry
+
+	mu          sync.Mutex
+	dailyLoss   units.USD
+	maxInFlight units.USD
+	day         string // UTC date pnl is counted for
+	pnl         units.USD
+	inFlight    map[string]units.USD // reserved notional by opportunity ID
+	now         func() time.Time
+
+	decisions *metrics.CounterVec
+	exposure  *metrics.GaugeVec
+	realized  *metrics.GaugeVec
+}
+
+// Covers reports whether every token o touches is in the instance's universe
+func (in *Instance) Covers(o *opportunity.Opportunity) bool {
+	if len(in.universe) == 0 {
+		return true
+	}
+	touched := []common.Address{o.TokenIn}
+	if o.Explanation != nil {
+		for _, leg := range o.Explanation.Legs {
+			touched = append(touched, leg.TokenIn, leg.TokenOut)
+		}
+	}
+	for _, token := range touched {
+		if in.universe[strings.ToUpper(token.Hex())] {
+			continue
+		}
+		t, ok := in.registry.ByAddress(o.ChainID, token)
+		if !ok || !in.universe[strings.ToUpper(t.Symbol)] {
+			return false
+		}
+	}
+	return true
+}
+
+// Limits returns the guardrails the instance trades at, given the live
+// process guardrails
+func (in *Instance) Limits(live config.Guardrails) config.Guardrails {
+	if in.guardrails != nil {
+		return *in.guardrails
+	}
+	return live
+}
+
+// Reserve holds notional against the in-flight budget for opportunity id,
+// refusing while the day's loss budget is spent
+func (in *Instance) Reserve(id string, notional units.USD) error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.rollLocked()
+	if in.dailyLoss > 0 && -in.pnl >= in.dailyLoss {
+		return fmt.Errorf("%w: %s lost %s today (budget %s)", ErrOverBudget, in.Name, -in.pnl, in.dailyLoss)
+	}
+	if in.maxInFlight > 0 {
+		total := notional
+		for _, n := range in.inFlight {
+			total += n
+		}
+		if total > in.maxInFlight {
+			return fmt.Errorf("%w: %s would have %s in flight (budget %s)", ErrOverBudget, in.Name, total, in.maxInFlight)
+		}
+	}
+	in.inFlight[id] = notional
+	in.publishLocked()
+	return nil
+}
+
+// release frees id's reservation and books pnl against the day's budget
+func (in *Instance) release(id string, pnl units.USD) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.rollLocked()
+	delete(in.inFlight, id)
+	in.pnl += pnl
+	in.publishLocked()
+}
+
+// rollLocked starts a new day's pnl at UTC midnight; caller holds in.mu
+func (in *Instance) rollLocked() {
+	if day := in.now().UTC().Format("2006-01-02"); day != in.day {
+		in.day, in.pnl = day, 0
+	}
+}
+
+// publishLocked updates the budget gauges; caller holds in.mu
+func (in *Instance) publishLocked() {
+	if in.exposure == nil {
+		return
+	}
+	var total units.USD
+	for _, n := range in.inFlight {
+		total += n
+	}
+	in.exposure.Set(total.Float(), in.Name)
+	in.realized.Set(in.pnl.Float(), in.Name)
+}
+
+// Count records an evaluation decision under the instance's label
+func (in *Instance) Count(decision string) {
+	if in.decisions != nil {
+		in.decisions.Inc(in.Name, decision)
+	}
+}
+
+// Budget returns the instance's budget and its use
+func (in *Instance) Budget() Budget {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.rollLocked()
+	b := Budget{DailyLossUSD: in.dailyLoss, MaxInFlightUSD: in.maxInFlight, PnLTodayUSD: in.pnl, InFlight: len(in.inFlight)}
+	for _, n := range in.inFlight {
+		b.InFlightUSD += n
+	}
+	b.Paused = in.dailyLoss > 0 && -in.pnl >= in.dailyLoss
+	return b
+}
+
+// Set is the process's strategy instances: the configured ones in config
+// order, then main. Each opportunity is evaluated by the first instance
+// whose universe covers it, so an experiment's trades are gated and budgeted
+// by its own settings and never by main's. It is safe for concurrent use.
+type Set struct {
+	instances []*Instance
+
+	mu     sync.Mutex
+	owners map[string]*Instance // reserving instance by opportunity ID
+}
+
+// New builds the configured instances and main, which covers every token at
+// the live guardrails without a budget; reg may be nil
+func New(strategies []config.StrategyConfig, registry *tokens.Registry, reg *metrics.Registry) (*Set, error) {
+	if err := config.ValidateStrategies(strategies); err != nil {
+		return nil, err
+	}
+	var decisions *metrics.CounterVec
+	var exposure, realized *metrics.GaugeVec
+	if reg != nil {
+		decisions = reg.Counter("titan_strategy_opportunities_total", "Opportunities evaluated by each strategy instance, by decision", "strategy", "decision")
+		exposure = reg.Gauge("titan_strategy_in_flight_usd", "Notional each strategy instance has in flight, in USD", "strategy")
+		realized = reg.Gauge("titan_strategy_pnl_today_usd", "PnL each strategy instance realized since UTC midnight, in USD", "strategy")
+	}
+	s := &Set{owners: make(map[string]*Instance)}
+	for _, sc := range append(append([]config.StrategyConfig(nil), strategies...), config.StrategyConfig{Name: config.StrategyMain, Kind: config.StrategyKindBackrun}) {
+		in := &Instance{
+			Name:        sc.Name,
+			Kind:        sc.Kind,
+			Tokens:      sc.Tokens,
+			guardrails:  sc.Guardrails,
+			universe:    make(map[string]bool),
+			registry:    registry,
+			dailyLoss:   units.DollarsToUSD(sc.DailyLossUSD),
+			maxInFlight: units.DollarsToUSD(sc.MaxInFlightUSD),
+			inFlight:    make(map[string]units.USD),
+			now:         time.Now,
+			decisions:   decisions,
+			exposure:    exposure,
+			realized:    realized,
+		}
+		for _, token := range sc.Tokens {
+			if common.IsHexAddress(token) {
+				token = common.HexToAddress(token).Hex()
+			}
+			in.universe[strings.ToUpper(token)] = true
+		}
+		for _, w := range sc.Wallets {
+			in.Wallets = append(in.Wallets, common.HexToAddress(w))
+		}
+		s.instances = append(s.instances, in)
+	}
+	return s, nil
+}
+
+// Instances returns the instances in evaluation order
+func (s *Set) Instances() []*Instance {
+	return s.instances
+}
+
+// Get returns the named instance
+func (s *Set) Get(name string) (*Instance, bool) {
+	for _, in := range s.instances {
+		if strings.EqualFold(in.Name, name) {
+			return in, true
+		}
+	}
+	return nil, false
+}
+
+// Route returns the instance that evaluates o, or nil when none of the kind
+// covers it
+func (s *Set) Route(kind string, o *opportunity.Opportunity) *Instance {
+	for _, in := range s.instances {
+		if in.Kind == kind && in.Covers(o) {
+			return in
+		}
+	}
+	return nil
+}
+
+// Claim reserves o's notional against in's budget and remembers in as the
+// instance the opportunity's PnL is booked to
+func (s *Set) Claim(in *Instance, id string, notional units.USD) error {
+	if err := in.Reserve(id, notional); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.owners[id] = in
+	s.mu.Unlock()
+	return nil
+}
+
+// Release frees the reservation of an opportunity that ended without
+// executing
+func (s *Set) Release(id string) {
+	if in := s.take(id); in != nil {
+		in.release(id, 0)
+	}
+}
+
+// Observe settles executions against their instance's budget; it has the
+// signature of opportunity.Recorder listeners
+func (s *Set) Observe(kind string, record interface{}) {
+	x, ok := record.(*opportunity.Execution)
+	if !ok {
+		return
+	}
+	if in := s.take(x.OpportunityID); in != nil {
+		in.release(x.OpportunityID, x.RealizedProfitUSD-x.GasUSD)
+	}
+}
+
+func (s *Set) take(id string) *Instance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	in := s.owners[id]
+	delete(s.owners, id)
+	return in
+}
+
+// Statuses describes every instance in evaluation order
+func (s *Set) Statuses() []Status {
+	out := make([]Status, len(s.instances))
+	for i, in := range s.instances {
+		out[i] = Status{Name: in.Name, Kind: in.Kind, Tokens: in.Tokens, Guardrails: in.guardrails, Wallets: in.Wallets, Budget: in.Budget()}
+	}
+	return out
+}
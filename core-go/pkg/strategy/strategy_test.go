@@ -0,0 +1,119 @@
+package strategy
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+var (
+	weth   = titantest.Address(1)
+	wsteth = titantest.Address(2)
+	pepe   = titantest.Address(3)
+)
+
+func newSet(t *testing.T, strategies []config.StrategyConfig, reg *metrics.Registry) *Set {
+	t.Helper()
+	registry := tokens.NewRegistry()
+	registry.Add(tokens.Token{ChainID: 1, Symbol: "WETH", Address: weth, Decimals: 18})
+	registry.Add(tokens.Token{ChainID: 1, Symbol: "wstETH", Address: wsteth, Decimals: 18})
+	registry.Add(tokens.Token{ChainID: 1, Symbol: "PEPE", Address: pepe, Decimals: 18})
+	s, err := New(strategies, registry, reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func backrun(legs ...[2]uint64) *opportunity.Opportunity {
+	o := &opportunity.Opportunity{ChainID: 1, TokenIn: weth, Explanation: &opportunity.Explanation{}}
+	for _, leg := range legs {
+		o.Explanation.Legs = append(o.Explanation.Legs, opportunity.Leg{TokenIn: titantest.Address(leg[0]), TokenOut: titantest.Address(leg[1])})
+	}
+	return o
+}
+
+func TestRouteByUniverse(t *testing.T) {
+	floor := config.Guardrails{MinProfitUSD: 25, MaxTVLShareBps: 2000}
+	s := newSet(t, []config.StrategyConfig{
+		{Name: "lst", Kind: config.StrategyKindBackrun, Tokens: []string{"WETH", wsteth.Hex()}, Guardrails: &floor},
+	}, nil)
+
+	lst := s.Route(config.StrategyKindBackrun, backrun([2]uint64{1, 2}, [2]uint64{2, 1}))
+	if lst == nil || lst.Name != "lst" {
+		t.Fatalf("Expected the WETH/wstETH backrun routed to lst, got %+v", lst)
+	}
+	if got := lst.Limits(config.Guardrails{MinProfitUSD: 10}); got.MinProfitUSD != 25 {
+		t.Errorf("Expected lst's own profit floor, got %+v", got)
+	}
+	main := s.Route(config.StrategyKindBackrun, backrun([2]uint64{1, 3}, [2]uint64{3, 1}))
+	if main == nil || main.Name != config.StrategyMain {
+		t.Fatalf("Expected the PEPE backrun left to main, got %+v", main)
+	}
+	if got := main.Limits(config.Guardrails{MinProfitUSD: 10}); got.MinProfitUSD != 10 {
+		t.Errorf("Expected main to follow the live guardrails, got %+v", got)
+	}
+	if s.Route("sandwich", backrun()) != nil {
+		t.Errorf("Expected no instance for an unknown kind")
+	}
+}
+
+func TestBudgetsAreIsolated(t *testing.T) {
+	reg := metrics.NewRegistry()
+	s := newSet(t, []config.StrategyConfig{
+		{Name: "lst", Kind: config.StrategyKindBackrun, Tokens: []string{"WETH"}, DailyLossUSD: 50, MaxInFlightUSD: 5000},
+	}, reg)
+	lst, _ := s.Get("lst")
+	main, _ := s.Get(config.StrategyMain)
+
+	if err := s.Claim(lst, "a", units.DollarsToUSD(3000)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Claim(lst, "b", units.DollarsToUSD(3000)); !errors.Is(err, ErrOverBudget) {
+		t.Errorf("Expected lst's in-flight budget enforced, got %v", err)
+	}
+	if err := s.Claim(main, "b", units.DollarsToUSD(3000)); err != nil {
+		t.Errorf("Expected main unaffected by lst's budget, got %v", err)
+	}
+	if got := reg.Value("titan_strategy_in_flight_usd", "lst"); got != 3000 {
+		t.Errorf("Expected $3000 in flight for lst, got %v", got)
+	}
+
+	s.Observe("execution", &opportunity.Execution{OpportunityID: "a", RealizedProfitUSD: 0, GasUSD: units.DollarsToUSD(60)})
+	b := lst.Budget()
+	if !b.Paused || b.PnLTodayUSD != -units.DollarsToUSD(60) || b.InFlight != 0 {
+		t.Errorf("Expected lst paused after a $60 loss, got %+v", b)
+	}
+	if err := s.Claim(lst, "c", units.DollarsToUSD(1)); !errors.Is(err, ErrOverBudget) {
+		t.Errorf("Expected lst's loss budget enforced, got %v", err)
+	}
+	if main.Budget().PnLTodayUSD != 0 {
+		t.Errorf("Expected lst's loss kept off main, got %+v", main.Budget())
+	}
+
+	lst.now = func() time.Time { return time.Now().Add(24 * time.Hour) }
+	if err := s.Claim(lst, "c", units.DollarsToUSD(1)); err != nil {
+		t.Errorf("Expected the loss budget reset the next day, got %v", err)
+	}
+	s.Release("c")
+	if b := lst.Budget(); b.InFlight != 0 {
+		t.Errorf("Expected the released reservation freed, got %+v", b)
+	}
+}
+
+func TestNewRejectsInvalid(t *testing.T) {
+	if _, err := New([]config.StrategyConfig{{Name: "x", Kind: config.StrategyKindBackrun, Wallets: []string{"nope"}}}, tokens.NewRegistry(), nil); err == nil {
+		t.Errorf("Expected an invalid wallet rejected")
+	}
+	s := newSet(t, nil, nil)
+	if len(s.Statuses()) != 1 || s.Statuses()[0].Name != config.StrategyMain {
+		t.Errorf("Expected only main without configured instances, got %+v", s.Statuses())
+	}
+}
@@ -0,0 +1,101 @@
+package titantest
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/multicall"
+)
+
+// Pool is a constant-product (UniswapV2-style) pool fixture
+type Pool struct {
+	Address  common.Address
+	Token0   common.Address
+	Token1   common.Address
+	Reserve0 *big.Int
+	Reserve1 *big.Int
+	FeeBps   uint32
+}
+
+// NewPool creates a pool fixture with empty reserves and the standard 30 bps fee
+func NewPool(address, token0, token1 common.Address) *Pool {
+	return &Pool{
+		Address:  address,
+		Token0:   token0,
+		Token1:   token1,
+		Reserve0: new(big.Int),
+		Reserve1: new(big.Int),
+		FeeBps:   30,
+	}
+}
+
+// WithReserves sets both reserves in raw units
+func (p *Pool) WithReserves(reserve0, reserve1 *big.Int) *Pool {
+	p.Reserve0 = new(big.Int).Set(reserve0)
+	p.Reserve1 = new(big.Int).Set(reserve1)
+	return p
+}
+
+// WithFee sets the swap fee in basis points
+func (p *Pool) WithFee(feeBps uint32) *Pool {
+	p.FeeBps = feeBps
+	return p
+}
+
+// Chain is a convenience builder that seeds a Backend with tokens, balances and pools
+type Chain struct {
+	Backend *Backend
+	next    uint64
+}
+
+// NewChain creates a chain builder; fixture addresses are allocated from 0x1000
+func NewChain(chainID uint64) *Chain {
+	return &Chain{Backend: NewBackend(chainID), next: 0x1000}
+}
+
+// Token allocates a token address with the given decimals
+func (c *Chain) Token(decimals uint8) common.Address {
+	token := c.alloc()
+	c.Backend.SetDecimals(token, decimals)
+	return token
+}
+
+// Pool allocates and registers a pool between two tokens with the given reserves
+func (c *Chain) Pool(token0, token1 common.Address, reserve0, reserve1 *big.Int) *Pool {
+	return c.Backend.AddPool(NewPool(c.alloc(), token0, token1).WithReserves(reserve0, reserve1))
+}
+
+// Fund sets an ERC20 balance and returns the builder for chaining
+func (c *Chain) Fund(token, holder common.Address, amount *big.Int) *Chain {
+	c.Backend.SetBalance(token, holder, amount)
+	return c
+}
+
+func (c *Chain) alloc() common.Address {
+	c.next++
+	return Address(c.next)
+}
+
+// EnableMulticall deploys a Multicall3 aggregate3 handler that dispatches each
+// inner call back into the backend
+func (b *Backend) EnableMulticall() {
+	b.Handle(common.HexToAddress(multicall.Multicall3Address), "aggregate3((address,bool,bytes)[])",
+		func(msg ethereum.CallMsg) ([]byte, error) {
+			calls, err := multicall.DecodeAggregate3Calls(msg.Data)
+			if err != nil {
+				return nil, err
+			}
+			results := make([]multicall.Result, len(calls))
+			for i, call := range calls {
+				target := call.Target
+				out, err := b.CallContract(context.Background(), ethereum.CallMsg{To: &target, Data: call.CallData}, nil)
+				if err != nil && !call.AllowFailure {
+					return nil, err
+				}
+				results[i] = multicall.Result{Success: err == nil, ReturnData: out}
+			}
+			return multicall.EncodeAggregate3Result(results)
+		})
+}
@@ -0,0 +1,316 @@
+package titantest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedisHandler answers a command the fake does not implement, or overrides
+// one it does. It returns a reply: string, int64, []interface{}, error or nil.
+type RedisHandler func(args []string) interface{}
+
+// Redis is an in-memory Redis server speaking enough RESP for strings with
+// expiry and streams
+type Redis struct {
+	ln       net.Listener
+	Password string
+
+	mu       sync.Mutex
+	strings  map[string]string
+	expiry   map[string]time.Time
+	streams  map[string][][]string // entries as id, field, value, ...
+	seq      int64
+	handlers map[string]RedisHandler
+	commands []string
+}
+
+// NewRedis starts a fake Redis on a random local port
+func NewRedis() (*Redis, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	r := &Redis{
+		ln:       ln,
+		strings:  make(map[string]string),
+		expiry:   make(map[string]time.Time),
+		streams:  make(map[string][][]string),
+		handlers: make(map[string]RedisHandler),
+	}
+	go r.serve()
+	return r, nil
+}
+
+// URL returns the redis:// URL of the server
+func (r *Redis) URL() string {
+	return "redis://" + r.ln.Addr().String()
+}
+
+// Close stops the server
+func (r *Redis) Close() error {
+	return r.ln.Close()
+}
+
+// Handle overrides command (upper case) with fn
+func (r *Redis) Handle(command string, fn RedisHandler) {
+	r.mu.Lock()
+	r.handlers[command] = fn
+	r.mu.Unlock()
+}
+
+// Commands returns the names of every command received, in order
+func (r *Redis) Commands() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.commands...)
+}
+
+// Get returns a string key's value
+func (r *Redis) Get(key string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expire(key)
+	v, ok := r.strings[key]
+	return v, ok
+}
+
+// Set stores a string key, expiring after ttl when it is positive
+func (r *Redis) Set(key, value string, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.strings[key] = value
+	delete(r.expiry, key)
+	if ttl > 0 {
+		r.expiry[key] = time.Now().Add(ttl)
+	}
+}
+
+// Del removes a string key
+func (r *Redis) Del(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.strings, key)
+	delete(r.expiry, key)
+}
+
+// Stream returns a stream's entries as field/value maps
+func (r *Redis) Stream(key string) []map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []map[string]string
+	for _, e := range r.streams[key] {
+		m := map[string]string{"id": e[0]}
+		for i := 1; i+1 < len(e); i += 2 {
+			m[e[i]] = e[i+1]
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func (r *Redis) serve() {
+	for {
+		conn, err := r.ln.Accept()
+		if err != nil {
+			return
+		}
+		go r.handle(conn)
+	}
+}
+
+func (r *Redis) handle(conn net.Conn) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	authed := r.Password == ""
+	for {
+		args, err := readCommand(rd)
+		if err != nil {
+			return
+		}
+		var reply interface{}
+		if name := strings.ToUpper(args[0]); name == "AUTH" {
+			if len(args) == 2 && args[1] == r.Password {
+				authed, reply = true, "OK"
+			} else {
+				reply = fmt.Errorf("WRONGPASS invalid password")
+			}
+		} else if !authed {
+			reply = fmt.Errorf("NOAUTH Authentication required")
+		} else {
+			reply = r.exec(name, args[1:])
+		}
+		if _, err := conn.Write(encodeReply(reply)); err != nil {
+			return
+		}
+	}
+}
+
+func (r *Redis) exec(name string, args []string) interface{} {
+	r.mu.Lock()
+	r.commands = append(r.commands, name)
+	handler := r.handlers[name]
+	r.mu.Unlock()
+	if handler != nil {
+		return handler(append([]string{name}, args...))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch name {
+	case "PING":
+		return "PONG"
+	case "SELECT":
+		return "OK"
+	case "GET":
+		r.expire(args[0])
+		if v, ok := r.strings[args[0]]; ok {
+			return v
+		}
+		return nil
+	case "SET":
+		return r.set(args)
+	case "DEL":
+		var n int64
+		for _, k := range args {
+			r.expire(k)
+			if _, ok := r.strings[k]; ok {
+				n++
+			}
+			delete(r.strings, k)
+			delete(r.expiry, k)
+		}
+		return n
+	case "PEXPIRE":
+		r.expire(args[0])
+		if _, ok := r.strings[args[0]]; !ok {
+			return int64(0)
+		}
+		ms, _ := strconv.ParseInt(args[1], 10, 64)
+		r.expiry[args[0]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		return int64(1)
+	case "XADD":
+		return r.xadd(args)
+	case "XLEN":
+		return int64(len(r.streams[args[0]]))
+	}
+	return fmt.Errorf("ERR unknown command '%s'", name)
+}
+
+func (r *Redis) set(args []string) interface{} {
+	key, value := args[0], args[1]
+	r.expire(key)
+	_, exists := r.strings[key]
+	var ttl time.Duration
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			if exists {
+				return nil
+			}
+		case "XX":
+			if !exists {
+				return nil
+			}
+		case "PX":
+			i++
+			ms, _ := strconv.ParseInt(args[i], 10, 64)
+			ttl = time.Duration(ms) * time.Millisecond
+		}
+	}
+	r.strings[key] = value
+	delete(r.expiry, key)
+	if ttl > 0 {
+		r.expiry[key] = time.Now().Add(ttl)
+	}
+	return "OK"
+}
+
+func (r *Redis) xadd(args []string) interface{} {
+	key := args[0]
+	i := 1
+	maxLen := -1
+	if strings.ToUpper(args[i]) == "MAXLEN" {
+		i++
+		if args[i] == "~" || args[i] == "=" {
+			i++
+		}
+		maxLen, _ = strconv.Atoi(args[i])
+		i++
+	}
+	id := args[i]
+	if id == "*" {
+		r.seq++
+		id = fmt.Sprintf("%d-0", r.seq)
+	}
+	entry := append([]string{id}, args[i+1:]...)
+	r.streams[key] = append(r.streams[key], entry)
+	if maxLen >= 0 && len(r.streams[key]) > maxLen {
+		r.streams[key] = r.streams[key][len(r.streams[key])-maxLen:]
+	}
+	return id
+}
+
+// expire drops key if its TTL has passed; the caller holds mu
+func (r *Redis) expire(key string) {
+	if at, ok := r.expiry[key]; ok && time.Now().After(at) {
+		delete(r.strings, key)
+		delete(r.expiry, key)
+	}
+}
+
+func readCommand(rd *bufio.Reader) ([]string, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("bad command header %q", line)
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func encodeReply(v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return []byte("$-1\r\n")
+	case error:
+		return []byte("-" + v.Error() + "\r\n")
+	case int64:
+		return []byte(":" + strconv.FormatInt(v, 10) + "\r\n")
+	case string:
+		if v == "OK" || v == "PONG" {
+			return []byte("+" + v + "\r\n")
+		}
+		return []byte("$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n")
+	case []interface{}:
+		out := []byte("*" + strconv.Itoa(len(v)) + "\r\n")
+		for _, item := range v {
+			out = append(out, encodeReply(item)...)
+		}
+		return out
+	}
+	return []byte("-ERR unsupported reply\r\n")
+}
@@ -0,0 +1,377 @@
+package titantest
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Well-known function selectors answered by the in-memory chain
+var (
+	SelectorBalanceOf   = selector("balanceOf(address)")
+	SelectorDecimals    = selector("decimals()")
+	SelectorGetReserves = selector("getReserves()")
+	SelectorToken0      = selector("token0()")
+	SelectorToken1      = selector("token1()")
+)
+
+// ErrNoHandler is returned when a call hits an address/selector with no fixture
+var ErrNoHandler = errors.New("titantest: no handler for call")
+
+// CallHandler answers an eth_call for a specific contract and selector
+type CallHandler func(msg ethereum.CallMsg) ([]byte, error)
+
+// Backend is an in-memory chain that implements the subset of *ethclient.Client
+// methods used by the Titan core. It is safe for concurrent use.
+type Backend struct {
+	mu sync.Mutex
+
+	chainID  *big.Int
+	headers  []*types.Header
+	balances map[common.Address]map[common.Address]*big.Int // token -> holder -> balance
+	decimals map[common.Address]uint8
+	pools    map[common.Address]*Pool
+	code     map[common.Address][]byte
+	storage  map[common.Address]map[common.Hash]common.Hash
+	handlers map[common.Address]map[[4]byte]CallHandler
+	subs     map[*subscription]struct{}
+
+	// Calls counts every CallContract invocation, useful for asserting cache hits
+	Calls int
+	// CallErr, when set, is returned from every CallContract invocation
+	CallErr error
+}
+
+// NewBackend creates an in-memory chain with a genesis block
+func NewBackend(chainID uint64) *Backend {
+	b := &Backend{
+		chainID:  new(big.Int).SetUint64(chainID),
+		balances: make(map[common.Address]map[common.Address]*big.Int),
+		decimals: make(map[common.Address]uint8),
+		pools:    make(map[common.Address]*Pool),
+		code:     make(map[common.Address][]byte),
+		storage:  make(map[common.Address]map[common.Hash]common.Hash),
+		handlers: make(map[common.Address]map[[4]byte]CallHandler),
+		subs:     make(map[*subscription]struct{}),
+	}
+	b.headers = append(b.headers, &types.Header{
+		Number:  big.NewInt(0),
+		Time:    1700000000,
+		BaseFee: big.NewInt(1_000_000_000),
+	})
+	return b
+}
+
+// ChainID returns the configured chain ID
+func (b *Backend) ChainID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(b.chainID), nil
+}
+
+// BlockNumber returns the current head number
+func (b *Backend) BlockNumber(ctx context.Context) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.head().Number.Uint64(), nil
+}
+
+// HeaderByNumber returns the header at number, or the head when number is nil
+func (b *Backend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if number == nil {
+		return types.CopyHeader(b.head()), nil
+	}
+	if !number.IsUint64() || number.Uint64() >= uint64(len(b.headers)) {
+		return nil, ethereum.NotFound
+	}
+	return types.CopyHeader(b.headers[number.Uint64()]), nil
+}
+
+// CallContract dispatches a call to the registered fixture handlers
+func (b *Backend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	b.Calls++
+	if b.CallErr != nil {
+		err := b.CallErr
+		b.mu.Unlock()
+		return nil, err
+	}
+	if msg.To == nil || len(msg.Data) < 4 {
+		b.mu.Unlock()
+		return nil, ErrNoHandler
+	}
+	to := *msg.To
+	var sel [4]byte
+	copy(sel[:], msg.Data[:4])
+	handler := b.handlers[to][sel]
+	b.mu.Unlock()
+
+	if handler != nil {
+		return handler(msg)
+	}
+	return b.builtinCall(to, sel, msg.Data[4:])
+}
+
+// SubscribeNewHead delivers every header produced by Mine to ch
+func (b *Backend) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	sub := &subscription{ch: ch, err: make(chan error, 1), quit: make(chan struct{}), backend: b}
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub, nil
+}
+
+// Close drops all active subscriptions
+func (b *Backend) Close() {
+	b.mu.Lock()
+	subs := b.subs
+	b.subs = make(map[*subscription]struct{})
+	b.mu.Unlock()
+	for sub := range subs {
+		sub.close(nil)
+	}
+}
+
+// Mine appends n blocks spaced blockTime apart and notifies subscribers
+func (b *Backend) Mine(n int, blockTime time.Duration) *types.Header {
+	var last *types.Header
+	for i := 0; i < n; i++ {
+		b.mu.Lock()
+		parent := b.head()
+		last = &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, big.NewInt(1)),
+			Time:       parent.Time + uint64(blockTime/time.Second),
+			BaseFee:    new(big.Int).Set(parent.BaseFee),
+		}
+		b.headers = append(b.headers, last)
+		subs := make([]*subscription, 0, len(b.subs))
+		for sub := range b.subs {
+			subs = append(subs, sub)
+		}
+		b.mu.Unlock()
+
+		for _, sub := range subs {
+			sub.deliver(types.CopyHeader(last))
+		}
+	}
+	return last
+}
+
+// DropSubscriptions terminates every active subscription with err, simulating
+// a websocket disconnect
+func (b *Backend) DropSubscriptions(err error) {
+	b.mu.Lock()
+	subs := b.subs
+	b.subs = make(map[*subscription]struct{})
+	b.mu.Unlock()
+	for sub := range subs {
+		sub.close(err)
+	}
+}
+
+// Handle registers a custom handler for calls to contract with the given signature
+func (b *Backend) Handle(contract common.Address, signature string, handler CallHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.handlers[contract] == nil {
+		b.handlers[contract] = make(map[[4]byte]CallHandler)
+	}
+	b.handlers[contract][selector(signature)] = handler
+}
+
+// SetCode sets the bytecode returned by CodeAt for an address
+func (b *Backend) SetCode(contract common.Address, code []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.code[contract] = append([]byte(nil), code...)
+}
+
+// CodeAt returns bytecode set with SetCode; addresses with handlers, pools or
+// decimals get a one-byte placeholder so they look deployed
+func (b *Backend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if code, ok := b.code[contract]; ok {
+		return code, nil
+	}
+	_, isPool := b.pools[contract]
+	_, isToken := b.decimals[contract]
+	if isPool || isToken || b.handlers[contract] != nil {
+		return []byte{0x00}, nil
+	}
+	return nil, nil
+}
+
+// SetStorage sets a storage slot returned by StorageAt
+func (b *Backend) SetStorage(contract common.Address, key, value common.Hash) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.storage[contract] == nil {
+		b.storage[contract] = make(map[common.Hash]common.Hash)
+	}
+	b.storage[contract][key] = value
+}
+
+// StorageAt returns a slot set with SetStorage, or zero
+func (b *Backend) StorageAt(ctx context.Context, contract common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	value := b.storage[contract][key]
+	return value.Bytes(), nil
+}
+
+// SetBalance sets the ERC20 balance of holder for token
+func (b *Backend) SetBalance(token, holder common.Address, amount *big.Int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.balances[token] == nil {
+		b.balances[token] = make(map[common.Address]*big.Int)
+	}
+	b.balances[token][holder] = new(big.Int).Set(amount)
+}
+
+// SetDecimals sets the value returned by decimals() on token
+func (b *Backend) SetDecimals(token common.Address, decimals uint8) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.decimals[token] = decimals
+}
+
+// AddPool registers a pool fixture and credits its reserves as token balances
+func (b *Backend) AddPool(pool *Pool) *Pool {
+	b.mu.Lock()
+	b.pools[pool.Address] = pool
+	b.mu.Unlock()
+
+	b.SetBalance(pool.Token0, pool.Address, pool.Reserve0)
+	b.SetBalance(pool.Token1, pool.Address, pool.Reserve1)
+	return pool
+}
+
+// Pool returns a registered pool fixture
+func (b *Backend) Pool(address common.Address) (*Pool, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	pool, ok := b.pools[address]
+	return pool, ok
+}
+
+func (b *Backend) head() *types.Header {
+	return b.headers[len(b.headers)-1]
+}
+
+func (b *Backend) builtinCall(to common.Address, sel [4]byte, args []byte) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch sel {
+	case SelectorBalanceOf:
+		if len(args) < 32 {
+			return nil, fmt.Errorf("titantest: short balanceOf calldata")
+		}
+		holder := common.BytesToAddress(args[12:32])
+		balance := b.balances[to][holder]
+		if balance == nil {
+			balance = new(big.Int)
+		}
+		return EncodeUint(balance), nil
+	case SelectorDecimals:
+		if d, ok := b.decimals[to]; ok {
+			return EncodeUint(big.NewInt(int64(d))), nil
+		}
+	case SelectorGetReserves:
+		if pool, ok := b.pools[to]; ok {
+			out := append(EncodeUint(pool.Reserve0), EncodeUint(pool.Reserve1)...)
+			return append(out, EncodeUint(new(big.Int).SetUint64(b.head().Time))...), nil
+		}
+	case SelectorToken0:
+		if pool, ok := b.pools[to]; ok {
+			return EncodeAddress(pool.Token0), nil
+		}
+	case SelectorToken1:
+		if pool, ok := b.pools[to]; ok {
+			return EncodeAddress(pool.Token1), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s selector %x", ErrNoHandler, to.Hex(), sel)
+}
+
+// subscription implements ethereum.Subscription for head notifications
+type subscription struct {
+	ch      chan<- *types.Header
+	err     chan error
+	quit    chan struct{}
+	once    sync.Once
+	backend *Backend
+}
+
+func (s *subscription) Unsubscribe() {
+	s.backend.mu.Lock()
+	delete(s.backend.subs, s)
+	s.backend.mu.Unlock()
+	s.close(nil)
+}
+
+func (s *subscription) Err() <-chan error {
+	return s.err
+}
+
+func (s *subscription) deliver(h *types.Header) {
+	select {
+	case s.ch <- h:
+	case <-s.quit:
+	}
+}
+
+func (s *subscription) close(err error) {
+	s.once.Do(func() {
+		if err != nil {
+			s.err <- err
+		}
+		close(s.quit)
+		close(s.err)
+	})
+}
+
+// Address returns a deterministic, human-recognisable address for fixtures
+func Address(n uint64) common.Address {
+	var a common.Address
+	binary.BigEndian.PutUint64(a[12:], n)
+	return a
+}
+
+// Units converts a whole-token amount to raw units, e.g. Units(500, 6)
+func Units(amount int64, decimals uint8) *big.Int {
+	exp := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return exp.Mul(exp, big.NewInt(amount))
+}
+
+// EncodeUint ABI-encodes an unsigned integer as a 32-byte word
+func EncodeUint(v *big.Int) []byte {
+	return common.LeftPadBytes(v.Bytes(), 32)
+}
+
+// EncodeAddress ABI-encodes an address as a 32-byte word
+func EncodeAddress(a common.Address) []byte {
+	return common.LeftPadBytes(a.Bytes(), 32)
+}
+
+func selector(signature string) [4]byte {
+	var sel [4]byte
+	copy(sel[:], crypto.Keccak256([]byte(signature))[:4])
+	return sel
+}
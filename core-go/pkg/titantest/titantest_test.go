@@ -0,0 +1,77 @@
+package titantest
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBalanceOfFixture(t *testing.T) {
+	chain := NewChain(137)
+	usdc := chain.Token(6)
+	vault := Address(0xba1)
+	chain.Fund(usdc, vault, Units(1_000_000, 6))
+
+	data := append(SelectorBalanceOf[:], EncodeAddress(vault)...)
+	out, err := chain.Backend.CallContract(context.Background(), ethereum.CallMsg{To: &usdc, Data: data}, nil)
+	if err != nil {
+		t.Fatalf("CallContract failed: %v", err)
+	}
+
+	if got := new(big.Int).SetBytes(out); got.Cmp(Units(1_000_000, 6)) != 0 {
+		t.Errorf("Expected balance %s, got %s", Units(1_000_000, 6), got)
+	}
+}
+
+func TestPoolFixture(t *testing.T) {
+	chain := NewChain(1)
+	weth := chain.Token(18)
+	usdc := chain.Token(6)
+	pool := chain.Pool(weth, usdc, Units(100, 18), Units(200_000, 6))
+
+	out, err := chain.Backend.CallContract(context.Background(),
+		ethereum.CallMsg{To: &pool.Address, Data: SelectorGetReserves[:]}, nil)
+	if err != nil {
+		t.Fatalf("getReserves failed: %v", err)
+	}
+	if len(out) != 96 {
+		t.Fatalf("Expected 96 bytes, got %d", len(out))
+	}
+	if got := new(big.Int).SetBytes(out[32:64]); got.Cmp(Units(200_000, 6)) != 0 {
+		t.Errorf("Expected reserve1 %s, got %s", Units(200_000, 6), got)
+	}
+
+	_, err = chain.Backend.CallContract(context.Background(),
+		ethereum.CallMsg{To: &weth, Data: SelectorGetReserves[:]}, nil)
+	if !errors.Is(err, ErrNoHandler) {
+		t.Errorf("Expected ErrNoHandler, got %v", err)
+	}
+}
+
+func TestSubscribeNewHead(t *testing.T) {
+	backend := NewBackend(42161)
+	heads := make(chan *types.Header, 4)
+	sub, err := backend.SubscribeNewHead(context.Background(), heads)
+	if err != nil {
+		t.Fatalf("SubscribeNewHead failed: %v", err)
+	}
+
+	backend.Mine(2, 250*time.Millisecond)
+	if h := <-heads; h.Number.Uint64() != 1 {
+		t.Errorf("Expected block 1, got %d", h.Number.Uint64())
+	}
+	if h := <-heads; h.Number.Uint64() != 2 {
+		t.Errorf("Expected block 2, got %d", h.Number.Uint64())
+	}
+
+	dropErr := errors.New("connection reset")
+	backend.DropSubscriptions(dropErr)
+	if err := <-sub.Err(); !errors.Is(err, dropErr) {
+		t.Errorf("Expected drop error, got %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package tokens
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// maxTokenListBytes bounds how much of a token list is read
+const maxTokenListBytes = 32 << 20
+
+// TokenList is a token list in the Uniswap token-lists format
+type TokenList struct {
+	Name      string               `json:"name"`
+	Timestamp string               `json:"timestamp"`
+	Version   TokenListVersion     `json:"version"`
+	Tokens    []ListedToken        `json:"tokens"`
+	Tags      map[string]TagDetail `json:"tags,omitempty"`
+}
+
+// TokenListVersion is a list's semantic version
+type TokenListVersion struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
+}
+
+// String formats the version as major.minor.patch
+func (v TokenListVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// TagDetail describes a tag referenced by listed tokens
+type TagDetail struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ListedToken is one entry of a token list
+type ListedToken struct {
+	ChainID  uint64   `json:"chainId"`
+	Address  string   `json:"address"`
+	Symbol   string   `json:"symbol"`
+	Name     string   `json:"name"`
+	Decimals int      `json:"decimals"`
+	LogoURI  string   `json:"logoURI,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// ParseTokenList decodes a token list
+func ParseTokenList(r io.Reader) (*TokenList, error) {
+	var list TokenList
+	if err := json.NewDecoder(io.LimitReader(r, maxTokenListBytes)).Decode(&list); err != nil {
+		return nil, fmt.Errorf("invalid token list: %w", err)
+	}
+	if len(list.Tokens) == 0 {
+		return nil, errors.New("invalid token list: no tokens")
+	}
+	return &list, nil
+}
+
+// LoadTokenList reads a token list from an http(s) URL or a file path
+func LoadTokenList(ctx context.Context, source string) (*TokenList, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ParseTokenList(f)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token list %s returned %s", source, resp.Status)
+	}
+	return ParseTokenList(resp.Body)
+}
+
+// token converts a listed entry, resolving tag IDs to names where the list defines them
+func (l *TokenList) token(e ListedToken) (Token, error) {
+	if !common.IsHexAddress(e.Address) {
+		return Token{}, fmt.Errorf("bad address %q", e.Address)
+	}
+	if e.Decimals < 0 || e.Decimals > int(units.MaxTokenDecimals) {
+		return Token{}, fmt.Errorf("%s: decimals %d out of range", e.Address, e.Decimals)
+	}
+	if strings.TrimSpace(e.Symbol) == "" {
+		return Token{}, fmt.Errorf("%s: empty symbol", e.Address)
+	}
+	tags := make([]string, len(e.Tags))
+	for i, id := range e.Tags {
+		tags[i] = id
+		if detail, ok := l.Tags[id]; ok && detail.Name != "" {
+			tags[i] = detail.Name
+		}
+	}
+	return Token{
+		ChainID:  e.ChainID,
+		Symbol:   e.Symbol,
+		Address:  common.HexToAddress(e.Address),
+		Decimals: uint8(e.Decimals),
+		Name:     e.Name,
+		LogoURI:  e.LogoURI,
+		Tags:     tags,
+	}, nil
+}
+
+// Ingest adds a list's tokens on the given chains (all chains when none are
+// given). Already-registered tokens keep their symbol and decimals and only
+// gain metadata; a listed token whose symbol is taken by another address is
+// reachable by address only, so lists cannot shadow canonical symbols.
+// Invalid or conflicting entries are skipped and reported.
+func (r *Registry) Ingest(list *TokenList, chains ...uint64) (int, error) {
+	wanted := make(map[uint64]bool, len(chains))
+	for _, id := range chains {
+		wanted[id] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var errs []error
+	added := 0
+	for _, e := range list.Tokens {
+		if len(wanted) > 0 && !wanted[e.ChainID] {
+			continue
+		}
+		t, err := list.token(e)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: chain %d: %w", list.Name, e.ChainID, err))
+			continue
+		}
+		if r.bySymbol[t.ChainID] == nil {
+			r.bySymbol[t.ChainID] = make(map[string]Token)
+			r.byAddress[t.ChainID] = make(map[common.Address]Token)
+		}
+
+		if existing, ok := r.byAddress[t.ChainID][t.Address]; ok {
+			if existing.Decimals != t.Decimals {
+				errs = append(errs, fmt.Errorf("%s: %s lists %d decimals, registry has %d", list.Name, t.Address.Hex(), t.Decimals, existing.Decimals))
+				continue
+			}
+			existing.Name = firstNonEmpty(existing.Name, t.Name)
+			existing.LogoURI = firstNonEmpty(existing.LogoURI, t.LogoURI)
+			existing.Tags = mergeTags(existing.Tags, t.Tags)
+			r.store(existing)
+			continue
+		}
+
+		r.byAddress[t.ChainID][t.Address] = t
+		if _, taken := r.bySymbol[t.ChainID][strings.ToUpper(t.Symbol)]; !taken {
+			r.bySymbol[t.ChainID][strings.ToUpper(t.Symbol)] = t
+		}
+		added++
+	}
+	return added, errors.Join(errs...)
+}
+
+// store rewrites t in every index that points at its address
+func (r *Registry) store(t Token) {
+	r.byAddress[t.ChainID][t.Address] = t
+	for sym, other := range r.bySymbol[t.ChainID] {
+		if other.Address == t.Address {
+			r.bySymbol[t.ChainID][sym] = t
+		}
+	}
+	if w, ok := r.wrapped[t.ChainID]; ok && w.Address == t.Address {
+		r.wrapped[t.ChainID] = t
+	}
+}
+
+func firstNonEmpty(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
+
+// mergeTags appends tags from b missing in a
+func mergeTags(a, b []string) []string {
+	out := append([]string(nil), a...)
+	for _, tag := range b {
+		if !(Token{Tags: out}).HasTag(tag) {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package tokens
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const sampleList = `{
+  "name": "Sample",
+  "timestamp": "2026-01-01T00:00:00Z",
+  "version": {"major": 1, "minor": 2, "patch": 0},
+  "tags": {"stable": {"name": "stablecoin", "description": "Pegged to USD"}},
+  "tokens": [
+    {"chainId": 137, "address": "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359", "symbol": "USDC", "name": "USD Coin", "decimals": 6, "logoURI": "ipfs://usdc", "tags": ["stable"]},
+    {"chainId": 137, "address": "0x0000000000000000000000000000000000000bad", "symbol": "USDC", "name": "Fake USDC", "decimals": 6},
+    {"chainId": 137, "address": "0x53E0bca35eC356BD5ddDFebbD1Fc0fD03FaBad39", "symbol": "LINK", "name": "ChainLink Token", "decimals": 18},
+    {"chainId": 137, "address": "0xc2132D05D31c914a87C6611C10748AEb04B58e8F", "symbol": "USDT", "name": "Tether", "decimals": 18},
+    {"chainId": 137, "address": "not-an-address", "symbol": "BAD", "decimals": 18},
+    {"chainId": 1, "address": "0x514910771AF9Ca656af840dff83E8264EcF986CA", "symbol": "LINK", "decimals": 18}
+  ]
+}`
+
+func TestIngestTokenList(t *testing.T) {
+	list, err := ParseTokenList(strings.NewReader(sampleList))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Version.String() != "1.2.0" {
+		t.Errorf("Expected version 1.2.0, got %s", list.Version)
+	}
+
+	r := Default()
+	added, err := r.Ingest(list, 137)
+	if err == nil {
+		t.Error("Expected the bad address and USDT decimals conflict to be reported")
+	}
+	if added != 2 {
+		t.Errorf("Expected 2 new tokens (fake USDC, LINK), got %d", added)
+	}
+
+	usdc, _ := r.Lookup(137, "USDC")
+	if usdc.Address != common.HexToAddress("0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359") {
+		t.Errorf("Expected canonical USDC to keep its symbol, got %s", usdc.Address.Hex())
+	}
+	if usdc.LogoURI != "ipfs://usdc" || !usdc.HasTag("stablecoin") {
+		t.Errorf("Expected USDC to gain list metadata, got %+v", usdc)
+	}
+	if _, ok := r.ByAddress(137, common.HexToAddress("0xbad")); !ok {
+		t.Error("Expected shadowing token to be reachable by address")
+	}
+	if usdt, _ := r.Lookup(137, "USDT"); usdt.Decimals != 6 {
+		t.Errorf("Expected registry USDT decimals to win, got %d", usdt.Decimals)
+	}
+	if _, err := r.Lookup(1, "LINK"); err == nil {
+		t.Error("Expected chains outside the filter to be skipped")
+	}
+	if got := r.Tagged(137, "stablecoin"); len(got) != 1 || got[0].Symbol != "USDC" {
+		t.Errorf("Expected USDC as the only stablecoin, got %+v", got)
+	}
+}
+
+func TestLoadTokenListOverHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(sampleList))
+	}))
+	defer srv.Close()
+
+	list, err := LoadTokenList(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Name != "Sample" || len(list.Tokens) != 6 {
+		t.Errorf("Unexpected list %s with %d tokens", list.Name, len(list.Tokens))
+	}
+}
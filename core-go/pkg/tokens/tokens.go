@@ -0,0 +1,231 @@
+package tokens
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+)
+
+// Token describes an ERC20 token on a specific chain
+type Token struct {
+	ChainID  uint64         `json:"chainId"`
+	Symbol   string         `json:"symbol"`
+	Address  common.Address `json:"address"`
+	Decimals uint8          `json:"decimals"`
+	Name     string         `json:"name,omitempty"`
+	LogoURI  string         `json:"logoURI,omitempty"`
+	Tags     []string       `json:"tags,omitempty"`
+}
+
+// HasTag reports whether the token carries tag (case-insensitive)
+func (t Token) HasTag(tag string) bool {
+	for _, have := range t.Tags {
+		if strings.EqualFold(have, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// stableSymbols are dollar stablecoins, whatever their token list says
+var stableSymbols = map[string]bool{"USDC": true, "USDC.E": true, "USDBC": true, "USDT": true, "DAI": true}
+
+// Stable reports whether the token is a dollar stablecoin
+func (t Token) Stable() bool {
+	return stableSymbols[strings.ToUpper(t.Symbol)] || t.HasTag("stablecoin")
+}
+
+// Registry indexes tokens by chain and symbol. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	bySymbol  map[uint64]map[string]Token
+	byAddress map[uint64]map[common.Address]Token
+	wrapped   map[uint64]Token
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		bySymbol:  make(map[uint64]map[string]Token),
+		byAddress: make(map[uint64]map[common.Address]Token),
+		wrapped:   make(map[uint64]Token),
+	}
+}
+
+// Default returns a registry seeded with the core tokens used across chains
+func Default() *Registry {
+	r := NewRegistry()
+	for _, t := range builtin {
+		r.Add(t)
+	}
+	return r
+}
+
+// FromConfig returns the default registry with each configured chain's native
+// symbol aliased to its wrapped token, so "ETH" or "MATIC" route through WETH/WMATIC
+func FromConfig(cfg *config.Config) (*Registry, error) {
+	r := Default()
+	for chainID, chain := range cfg.Chains {
+		if chain.WrappedNative == "" {
+			continue
+		}
+		if err := r.SetWrappedNative(chainID, chain.Native, common.HexToAddress(chain.WrappedNative)); err != nil {
+			return nil, fmt.Errorf("%s: %w", chain.Name, err)
+		}
+	}
+	return r, nil
+}
+
+// SetWrappedNative marks wrapped as the chain's native wrapper and makes the
+// native symbol resolve to it. The wrapped token must already be registered.
+func (r *Registry) SetWrappedNative(chainID uint64, nativeSymbol string, wrapped common.Address) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.byAddress[chainID][wrapped]
+	if !ok {
+		return fmt.Errorf("wrapped native %s not registered on chain %d", wrapped.Hex(), chainID)
+	}
+	r.wrapped[chainID] = t
+	if nativeSymbol != "" {
+		r.bySymbol[chainID][strings.ToUpper(nativeSymbol)] = t
+	}
+	return nil
+}
+
+// WrappedNative returns the chain's wrapped native token
+func (r *Registry) WrappedNative(chainID uint64) (Token, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.wrapped[chainID]
+	return t, ok
+}
+
+// Add registers or replaces a token
+func (r *Registry) Add(t Token) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.bySymbol[t.ChainID] == nil {
+		r.bySymbol[t.ChainID] = make(map[string]Token)
+		r.byAddress[t.ChainID] = make(map[common.Address]Token)
+	}
+	r.bySymbol[t.ChainID][strings.ToUpper(t.Symbol)] = t
+	r.byAddress[t.ChainID][t.Address] = t
+}
+
+// Lookup resolves a symbol (case-insensitive) or hex address on a chain
+func (r *Registry) Lookup(chainID uint64, symbolOrAddress string) (Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if common.IsHexAddress(symbolOrAddress) {
+		if t, ok := r.byAddress[chainID][common.HexToAddress(symbolOrAddress)]; ok {
+			return t, nil
+		}
+	} else if t, ok := r.bySymbol[chainID][strings.ToUpper(symbolOrAddress)]; ok {
+		return t, nil
+	}
+	return Token{}, fmt.Errorf("token %q not registered on chain %d", symbolOrAddress, chainID)
+}
+
+// ByAddress returns the token registered at address on a chain
+func (r *Registry) ByAddress(chainID uint64, address common.Address) (Token, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.byAddress[chainID][address]
+	return t, ok
+}
+
+// Chain returns all tokens on a chain sorted by symbol
+func (r *Registry) Chain(chainID uint64) []Token {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Token, 0, len(r.byAddress[chainID]))
+	for _, t := range r.byAddress[chainID] {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Symbol < out[j].Symbol })
+	return out
+}
+
+// Tagged returns the tokens on a chain carrying tag, sorted by symbol
+func (r *Registry) Tagged(chainID uint64, tag string) []Token {
+	var out []Token
+	for _, t := range r.Chain(chainID) {
+		if t.HasTag(tag) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func tok(chainID uint64, symbol, address string, decimals uint8) Token {
+	return Token{ChainID: chainID, Symbol: symbol, Address: common.HexToAddress(address), Decimals: decimals}
+}
+
+// builtin lists canonical stablecoins and majors per chain
+var builtin = []Token{
+	// Ethereum
+	tok(1, "USDC", "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", 6),
+	tok(1, "USDT", "0xdAC17F958D2ee523a2206206994597C13D831ec7", 6),
+	tok(1, "DAI", "0x6B175474E89094C44Da98b954EedeAC495271d0F", 18),
+	tok(1, "WETH", "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", 18),
+	tok(1, "WBTC", "0x2260FAC5E5542a773Aa44fBCfeDf7C193bc2C599", 8),
+
+	// Polygon
+	tok(137, "USDC", "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359", 6),
+	tok(137, "USDC.E", "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", 6),
+	tok(137, "USDT", "0xc2132D05D31c914a87C6611C10748AEb04B58e8F", 6),
+	tok(137, "DAI", "0x8f3Cf7ad23Cd3CaDbD9735AFf958023239c6A063", 18),
+	tok(137, "WETH", "0x7ceB23fD6bC0adD59E62ac25578270cFf1b9f619", 18),
+	tok(137, "WMATIC", "0x0d500B1d8E8eF31E21C99d1Db9A6444d3ADf1270", 18),
+	tok(137, "WBTC", "0x1BFD67037B42Cf73acF2047067bd4F2C47D9BfD6", 8),
+
+	// Arbitrum
+	tok(42161, "USDC", "0xaf88d065e77c8cC2239327C5EDb3A432268e5831", 6),
+	tok(42161, "USDC.E", "0xFF970A61A04b1cA14834A43f5dE4533eBDDB5CC8", 6),
+	tok(42161, "USDT", "0xFd086bC7CD5C481DCC9C85ebE478A1C0b69FCbb9", 6),
+	tok(42161, "DAI", "0xDA10009cBd5D07dd0CeCc66161FC93D7c9000da1", 18),
+	tok(42161, "WETH", "0x82aF49447D8a07e3bd95BD0d56f35241523fBab1", 18),
+	tok(42161, "WBTC", "0x2f2a2543B76A4166549F7aaB2e75Bef0aefC5B0f", 8),
+
+	// Optimism
+	tok(10, "USDC", "0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85", 6),
+	tok(10, "USDT", "0x94b008aA00579c1307B0EF2c499aD98a8ce58e58", 6),
+	tok(10, "DAI", "0xDA10009cBd5D07dd0CeCc66161FC93D7c9000da1", 18),
+	tok(10, "WETH", "0x4200000000000000000000000000000000000006", 18),
+	tok(10, "WBTC", "0x68f180fcCe6836688e9084f035309E29Bf0A2095", 8),
+
+	// Base
+	tok(8453, "USDC", "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913", 6),
+	tok(8453, "DAI", "0x50c5725949A6F0c72E6C4a641F24049A917DB0Cb", 18),
+	tok(8453, "WETH", "0x4200000000000000000000000000000000000006", 18),
+
+	// BSC (Binance-peg stablecoins use 18 decimals)
+	tok(56, "USDC", "0x8AC76a51cc950d9822D68b83fE1Ad97B32Cd580d", 18),
+	tok(56, "USDT", "0x55d398326f99059fF775485246999027B3197955", 18),
+	tok(56, "WBNB", "0xbb4CdB9CBd36B01bD1cBaEBF2De08d9173bc095c", 18),
+
+	// Avalanche
+	tok(43114, "USDC", "0xB97EF9Ef8734C71904D8002F8b6Bc66Dd9c48a6E", 6),
+	tok(43114, "USDT", "0x9702230A8Ea53601f5cD2dc00fDBc13d4dF4A8c7", 6),
+	tok(43114, "WAVAX", "0xB31f66AA3C1e785363F0875A1B74E27b85FD66c7", 18),
+
+	// zkSync Era
+	tok(324, "USDC", "0x1d17CBcF0D6D143135aE902365D2E5e2A16538D4", 6),
+	tok(324, "USDT", "0x493257fD37EDB34451f62EDf8D2a0C418852bA4C", 6),
+	tok(324, "WETH", "0x5AEa5775959fBC2557Cc8789bC1bf90A239D9a91", 18),
+
+	// Mantle
+	tok(5000, "USDC", "0x09Bc4E0D864854c6aFB6eB9A9cdF58aC190D0dF9", 6),
+	tok(5000, "USDT", "0x201EBa5CC46D216Ce6DC03F6a759e8E766e956aE", 6),
+	tok(5000, "WMNT", "0x78c1b0C915c4FAA5FffA6CAbf0219DA63d7f4cb8", 18),
+
+	// Celo
+	tok(42220, "CELO", "0x471EcE3750Da237f93B8E339c536989b8978a438", 18),
+	tok(42220, "cUSD", "0x765DE816845861e75A25fCA122bb6898B8B1282a", 18),
+	tok(42220, "USDC", "0xcebA9300f2b948710d2653dD7B07f33A8B32118C", 6),
+	tok(42220, "USDT", "0x48065fbBE25f71C9282ddf5e1cD6D6A887483D5e", 6),
+}
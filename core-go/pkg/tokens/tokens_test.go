@@ -0,0 +1,54 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+)
+
+func TestFromConfigResolvesNativeSymbols(t *testing.T) {
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := FromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for chainID, chain := range cfg.Chains {
+		wrapped, ok := r.WrappedNative(chainID)
+		if !ok {
+			t.Errorf("Expected wrapped native on %s", chain.Name)
+			continue
+		}
+		if wrapped.Address != common.HexToAddress(chain.WrappedNative) {
+			t.Errorf("Expected %s wrapped native %s, got %s", chain.Name, chain.WrappedNative, wrapped.Address.Hex())
+		}
+		native, err := r.Lookup(chainID, chain.Native)
+		if err != nil || native.Address != wrapped.Address {
+			t.Errorf("Expected %s on %s to resolve to %s, got %v (%v)", chain.Native, chain.Name, wrapped.Symbol, native, err)
+		}
+	}
+
+	matic, _ := r.Lookup(137, "matic")
+	if matic.Symbol != "WMATIC" {
+		t.Errorf("Expected MATIC to resolve to WMATIC, got %s", matic.Symbol)
+	}
+	for _, tok := range r.Chain(1) {
+		if tok.Symbol == "ETH" {
+			t.Error("Expected native alias not to be listed as a separate token")
+		}
+	}
+	if got, want := len(r.Chain(1)), len(Default().Chain(1)); got != want {
+		t.Errorf("Expected %d tokens on chain 1, got %d", want, got)
+	}
+}
+
+func TestSetWrappedNativeRequiresRegisteredToken(t *testing.T) {
+	r := Default()
+	if err := r.SetWrappedNative(1, "ETH", common.HexToAddress("0x01")); err == nil {
+		t.Error("Expected error for unregistered wrapped native")
+	}
+}
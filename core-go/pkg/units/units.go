@@ -0,0 +1,289 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+)
+
+// MaxDecimals is the largest exponent for which 10^decimals fits in 256 bits
+const MaxDecimals = 77
+
+// MaxTokenDecimals is the largest token decimals() value Titan will size or
+// trade. Anything above it is treated as misconfigured or malicious.
+const MaxTokenDecimals = 36
+
+// USDDecimals is the fixed-point precision of USD values (micro-dollars)
+const USDDecimals = 6
+
+// PriceDecimals is the fixed-point precision of USD prices (Chainlink convention)
+const PriceDecimals = 8
+
+// BpsDenominator is the basis-point scale of every fee, share and guardrail
+// percentage
+const BpsDenominator = 10000
+
+var (
+	// ErrOverflow is returned when a conversion does not fit in 256 bits
+	ErrOverflow = errors.New("units: value overflows uint256")
+	// ErrDecimals is returned for decimals outside [0, MaxDecimals]
+	ErrDecimals = errors.New("units: unsupported decimals")
+	// ErrParse is returned for malformed decimal strings
+	ErrParse = errors.New("units: invalid decimal string")
+	// ErrUnsupportedDecimals is returned for token decimals above MaxTokenDecimals
+	ErrUnsupportedDecimals = errors.New("units: token decimals out of supported range")
+)
+
+var pow10 [MaxDecimals + 1]uint256.Int
+
+func init() {
+	pow10[0].SetOne()
+	ten := uint256.NewInt(10)
+	for i := 1; i <= MaxDecimals; i++ {
+		pow10[i].Mul(&pow10[i-1], ten)
+	}
+}
+
+// Pow10 returns 10^decimals from a precomputed table. The result must not be mutated.
+func Pow10(decimals uint8) (*uint256.Int, error) {
+	if decimals > MaxDecimals {
+		return nil, fmt.Errorf("%w: %d", ErrDecimals, decimals)
+	}
+	return &pow10[decimals], nil
+}
+
+// ValidateDecimals rejects token decimals Titan refuses to trade
+func ValidateDecimals(decimals uint8) error {
+	if decimals > MaxTokenDecimals {
+		return fmt.Errorf("%w: %d > %d", ErrUnsupportedDecimals, decimals, MaxTokenDecimals)
+	}
+	return nil
+}
+
+// DecodeDecimals parses the raw return data of an ERC20 decimals() call.
+// A compliant token returns one 32-byte word holding a uint8; values that do
+// not fit, oversized words, or short data are rejected instead of truncated.
+func DecodeDecimals(ret []byte) (uint8, error) {
+	if len(ret) != 32 {
+		return 0, fmt.Errorf("%w: decimals() returned %d bytes", ErrUnsupportedDecimals, len(ret))
+	}
+	v := new(uint256.Int).SetBytes32(ret)
+	if !v.IsUint64() || v.Uint64() > MaxTokenDecimals {
+		return 0, fmt.Errorf("%w: decimals() returned %s", ErrUnsupportedDecimals, v.Dec())
+	}
+	return uint8(v.Uint64()), nil
+}
+
+// Amount is a raw token quantity tagged with its token and decimals
+type Amount struct {
+	Token    common.Address
+	Value    *uint256.Int
+	Decimals uint8
+}
+
+// New creates an Amount from raw units
+func New(token common.Address, value *uint256.Int, decimals uint8) Amount {
+	return Amount{Token: token, Value: new(uint256.Int).Set(value), Decimals: decimals}
+}
+
+// FromBig creates an Amount from a raw big.Int, rejecting negatives and overflow
+func FromBig(token common.Address, value *big.Int, decimals uint8) (Amount, error) {
+	if value.Sign() < 0 {
+		return Amount{}, fmt.Errorf("units: negative amount %s", value)
+	}
+	v, overflow := uint256.FromBig(value)
+	if overflow {
+		return Amount{}, ErrOverflow
+	}
+	return Amount{Token: token, Value: v, Decimals: decimals}, nil
+}
+
+// FromWhole creates an Amount of whole tokens, e.g. FromWhole(usdc, 500, 6)
+func FromWhole(token common.Address, whole uint64, decimals uint8) (Amount, error) {
+	scale, err := Pow10(decimals)
+	if err != nil {
+		return Amount{}, err
+	}
+	v, overflow := new(uint256.Int).MulOverflow(uint256.NewInt(whole), scale)
+	if overflow {
+		return Amount{}, ErrOverflow
+	}
+	return Amount{Token: token, Value: v, Decimals: decimals}, nil
+}
+
+// Parse creates an Amount from a human decimal string such as "1234.56".
+// Digits beyond the token's precision are rejected rather than rounded.
+func Parse(token common.Address, s string, decimals uint8) (Amount, error) {
+	s = strings.TrimSpace(s)
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" || len(frac) > int(decimals) || strings.ContainsAny(s, "+-eE") {
+		return Amount{}, fmt.Errorf("%w: %q", ErrParse, s)
+	}
+	if _, err := Pow10(decimals); err != nil {
+		return Amount{}, err
+	}
+
+	digits := whole + frac + strings.Repeat("0", int(decimals)-len(frac))
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		digits = "0"
+	}
+	v, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Amount{}, fmt.Errorf("%w: %q", ErrParse, s)
+	}
+	return FromBig(token, v, decimals)
+}
+
+// Rescale converts the amount to another token with different decimals,
+// truncating any precision the target cannot represent
+func (a Amount) Rescale(token common.Address, decimals uint8) (Amount, error) {
+	if a.Value == nil {
+		return Amount{Token: token, Value: new(uint256.Int), Decimals: decimals}, nil
+	}
+	from, err := Pow10(a.Decimals)
+	if err != nil {
+		return Amount{}, err
+	}
+	to, err := Pow10(decimals)
+	if err != nil {
+		return Amount{}, err
+	}
+	v, overflow := new(uint256.Int).MulDivOverflow(a.Value, to, from)
+	if overflow {
+		return Amount{}, ErrOverflow
+	}
+	return Amount{Token: token, Value: v, Decimals: decimals}, nil
+}
+
+// Big returns the raw value as a new big.Int
+func (a Amount) Big() *big.Int {
+	if a.Value == nil {
+		return new(big.Int)
+	}
+	return a.Value.ToBig()
+}
+
+// IsZero reports whether the amount is zero or unset
+func (a Amount) IsZero() bool {
+	return a.Value == nil || a.Value.IsZero()
+}
+
+// Cmp compares two amounts of the same token and decimals
+func (a Amount) Cmp(b Amount) int {
+	return a.Value.Cmp(b.Value)
+}
+
+// String formats the amount as a human decimal string without float rounding
+func (a Amount) String() string {
+	if a.Value == nil {
+		return "0"
+	}
+	s := a.Value.Dec()
+	if a.Decimals == 0 {
+		return s
+	}
+	d := int(a.Decimals)
+	if len(s) <= d {
+		s = strings.Repeat("0", d-len(s)+1) + s
+	}
+	whole, frac := s[:len(s)-d], strings.TrimRight(s[len(s)-d:], "0")
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
+
+// Float returns an approximate float64 for display and scoring features only
+func (a Amount) Float() float64 {
+	f, _ := new(big.Float).SetInt(a.Big()).Float64()
+	for i := uint8(0); i < a.Decimals; i++ {
+		f /= 10
+	}
+	return f
+}
+
+// USD is a fixed-point dollar value with USDDecimals precision
+type USD int64
+
+// DollarsToUSD converts whole dollars to USD
+func DollarsToUSD(dollars uint64) USD {
+	return USD(dollars * 1_000_000)
+}
+
+// Float returns the dollar value as float64 for display
+func (u USD) Float() float64 {
+	return float64(u) / 1e6
+}
+
+// String formats the value as dollars with cents
+func (u USD) String() string {
+	return fmt.Sprintf("$%.2f", u.Float())
+}
+
+// ToUSD values the amount at priceE8 (USD per whole token, PriceDecimals precision)
+func (a Amount) ToUSD(priceE8 uint64) (USD, error) {
+	if a.IsZero() || priceE8 == 0 {
+		return 0, nil
+	}
+	// value * price / 10^decimals / 10^(PriceDecimals-USDDecimals)
+	scale, err := Pow10(a.Decimals)
+	if err != nil {
+		return 0, err
+	}
+	denom, overflow := new(uint256.Int).MulOverflow(scale, &pow10[PriceDecimals-USDDecimals])
+	if overflow {
+		return 0, ErrOverflow
+	}
+	usd, overflow := new(uint256.Int).MulDivOverflow(a.Value, uint256.NewInt(priceE8), denom)
+	if overflow || !usd.IsUint64() || usd.Uint64() > 1<<63-1 {
+		return 0, ErrOverflow
+	}
+	return USD(usd.Uint64()), nil
+}
+
+// FromUSD converts a USD value into raw token units at priceE8
+func FromUSD(token common.Address, usd USD, priceE8 uint64, decimals uint8) (Amount, error) {
+	if usd < 0 {
+		return Amount{}, fmt.Errorf("units: negative USD value %s", usd)
+	}
+	if priceE8 == 0 {
+		return Amount{}, fmt.Errorf("units: zero price for %s", token.Hex())
+	}
+	scale, err := Pow10(decimals)
+	if err != nil {
+		return Amount{}, err
+	}
+	// usd * 10^(PriceDecimals-USDDecimals) * 10^decimals / price
+	num, overflow := new(uint256.Int).MulOverflow(uint256.NewInt(uint64(usd)), &pow10[PriceDecimals-USDDecimals])
+	if overflow {
+		return Amount{}, ErrOverflow
+	}
+	v, overflow := new(uint256.Int).MulDivOverflow(num, scale, uint256.NewInt(priceE8))
+	if overflow {
+		return Amount{}, ErrOverflow
+	}
+	return Amount{Token: token, Value: v, Decimals: decimals}, nil
+}
+
+// MulBps returns x * bps / BpsDenominator, rounding down
+func MulBps(x *big.Int, bps uint64) *big.Int {
+	out := new(big.Int).Mul(x, new(big.Int).SetUint64(bps))
+	return out.Quo(out, big.NewInt(BpsDenominator))
+}
+
+// MulBpsUint is MulBps for uint64 values, saturating at math.MaxUint64
+func MulBpsUint(x, bps uint64) uint64 {
+	hi, lo := bits.Mul64(x, bps)
+	if hi >= BpsDenominator {
+		return math.MaxUint64
+	}
+	q, _ := bits.Div64(hi, lo, BpsDenominator)
+	return q
+}
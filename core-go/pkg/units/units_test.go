@@ -0,0 +1,104 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var usdc = common.HexToAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174")
+
+func TestParseAndString(t *testing.T) {
+	a, err := Parse(usdc, "1234.5", 6)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if a.Value.Uint64() != 1_234_500_000 {
+		t.Errorf("Expected 1234500000 raw units, got %s", a.Value.Dec())
+	}
+	if a.String() != "1234.5" {
+		t.Errorf("Expected 1234.5, got %s", a.String())
+	}
+
+	small, _ := Parse(usdc, "0.000001", 6)
+	if small.String() != "0.000001" {
+		t.Errorf("Expected 0.000001, got %s", small.String())
+	}
+
+	for _, bad := range []string{"", "1.0000001", "-1", "1e6", "abc"} {
+		if _, err := Parse(usdc, bad, 6); !errors.Is(err, ErrParse) {
+			t.Errorf("Parse(%q): expected ErrParse, got %v", bad, err)
+		}
+	}
+}
+
+func TestUSDConversions(t *testing.T) {
+	weth := common.HexToAddress("0x7ceB23fD6bC0adD59E62ac25578270cFf1b9f619")
+	one, _ := FromWhole(weth, 1, 18)
+
+	// 1 WETH at $3,250.12345678
+	usd, err := one.ToUSD(325_012_345_678)
+	if err != nil {
+		t.Fatalf("ToUSD failed: %v", err)
+	}
+	if usd != 3_250_123_456 {
+		t.Errorf("Expected 3250123456 micro-dollars, got %d", usd)
+	}
+
+	back, err := FromUSD(weth, DollarsToUSD(10_000), 250_000_000_000, 18)
+	if err != nil {
+		t.Fatalf("FromUSD failed: %v", err)
+	}
+	if back.String() != "4" {
+		t.Errorf("Expected $10k at $2500 to be 4 WETH, got %s", back.String())
+	}
+}
+
+func TestPow10Bounds(t *testing.T) {
+	if _, err := Pow10(MaxDecimals); err != nil {
+		t.Errorf("Expected 10^%d to fit, got %v", MaxDecimals, err)
+	}
+	if _, err := Pow10(MaxDecimals + 1); !errors.Is(err, ErrDecimals) {
+		t.Errorf("Expected ErrDecimals, got %v", err)
+	}
+	if _, err := FromWhole(usdc, 1_000_000, MaxDecimals); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Expected ErrOverflow, got %v", err)
+	}
+}
+
+func TestDecodeDecimals(t *testing.T) {
+	word := make([]byte, 32)
+	word[31] = 6
+	if d, err := DecodeDecimals(word); err != nil || d != 6 {
+		t.Errorf("Expected 6, got %d (%v)", d, err)
+	}
+
+	// uint8 truncation of 0x0106 would silently read as 6
+	word[30] = 1
+	if _, err := DecodeDecimals(word); !errors.Is(err, ErrUnsupportedDecimals) {
+		t.Errorf("Expected ErrUnsupportedDecimals for 262, got %v", err)
+	}
+
+	word[30], word[31] = 0, MaxTokenDecimals+1
+	if _, err := DecodeDecimals(word); !errors.Is(err, ErrUnsupportedDecimals) {
+		t.Errorf("Expected ErrUnsupportedDecimals above MaxTokenDecimals, got %v", err)
+	}
+
+	if _, err := DecodeDecimals(word[:4]); !errors.Is(err, ErrUnsupportedDecimals) {
+		t.Errorf("Expected ErrUnsupportedDecimals for short data, got %v", err)
+	}
+}
+
+func TestMulBpsUint(t *testing.T) {
+	if got := MulBpsUint(15, 7500); got != 11 {
+		t.Errorf("Expected 0.75x of 15 to round down to 11, got %d", got)
+	}
+	if got := MulBpsUint(50, 20000); got != 100 {
+		t.Errorf("Expected 2x of 50, got %d", got)
+	}
+	if got := MulBpsUint(math.MaxUint64, 20000); got != math.MaxUint64 {
+		t.Errorf("Expected an overflowing product to saturate, got %d", got)
+	}
+}
@@ -0,0 +1,213 @@
+package volatility
+
+import (
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Regime classifies a pair's recent realized volatility
+type Regime string
+
+// Regimes, from quietest to most turbulent
+const (
+	RegimeCalm   Regime = "calm"
+	RegimeNormal Regime = "normal"
+	RegimeHigh   Regime = "high"
+)
+
+// Level returns the regime as a number for scoring: -1 calm, 0 normal, 1 high
+func (r Regime) Level() float64 {
+	switch r {
+	case RegimeCalm:
+		return -1
+	case RegimeHigh:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// minReturns is the fewest price moves a regime is judged from; until then a
+// pair is treated as normal
+const minReturns = 5
+
+// Settings bounds the regimes and how far each scales the guardrails
+type Settings struct {
+	Window       time.Duration // lookback realized volatility is measured over
+	CalmBps      uint64        // at or below this the pair is calm
+	HighBps      uint64        // at or above this volatility is high
+	CalmScaleBps uint64        // guardrail multiplier when calm, in bps of 1x
+	HighScaleBps uint64        // guardrail multiplier when volatility is high, in bps of 1x
+}
+
+// State is a pair's measured volatility and the regime it puts the pair in
+type State struct {
+	Pair        string  `json:"pair"`
+	RealizedBps float64 `json:"realizedBps"`
+	Returns     int     `json:"returns"`
+	Regime      Regime  `json:"regime"`
+	ScaleBps    uint64  `json:"scaleBps"` // guardrail multiplier, in bps of 1x
+}
+
+type sample struct {
+	at    time.Time
+	price float64
+}
+
+// Detector measures short-window realized volatility per pair from price
+// samples and scales the min-profit and slippage guardrails with it: wider
+// when prices are moving fast, tighter when they are calm. A nil detector
+// keeps every pair normal. It is safe for concurrent use.
+type Detector struct {
+	settings Settings
+
+	mu      sync.Mutex
+	samples map[string][]sample
+	states  map[string]State
+	now     func() time.Time
+
+	realized *metrics.GaugeVec
+	regime   *metrics.GaugeVec
+}
+
+// New creates a detector; reg may be nil
+func New(s Settings, reg *metrics.Registry) *Detector {
+	d := &Detector{settings: s, samples: make(map[string][]sample), states: make(map[string]State), now: time.Now}
+	if reg != nil {
+		d.realized = reg.Gauge("titan_realized_volatility_bps", "Realized volatility over the lookback window in bps", "pair")
+		d.regime = reg.Gauge("titan_volatility_regime", "Volatility regime: -1 calm, 0 normal, 1 high", "pair")
+	}
+	return d
+}
+
+// FromConfig creates a detector from configuration, nil when none is set
+func FromConfig(cfg *config.VolatilityConfig, reg *metrics.Registry) *Detector {
+	if cfg == nil {
+		return nil
+	}
+	return New(Settings{
+		Window:       time.Duration(cfg.WindowSecs) * time.Second,
+		CalmBps:      cfg.CalmBps,
+		HighBps:      cfg.HighBps,
+		CalmScaleBps: cfg.CalmScaleBps,
+		HighScaleBps: cfg.HighScaleBps,
+	}, reg)
+}
+
+// Observe records a price for pair and re-evaluates its regime
+func (d *Detector) Observe(pair string, price float64) {
+	if d == nil || price <= 0 {
+		return
+	}
+	now := d.now()
+	d.mu.Lock()
+	samples := append(d.samples[pair], sample{at: now, price: price})
+	cutoff := now.Add(-d.settings.Window)
+	for len(samples) > 0 && samples[0].at.Before(cutoff) {
+		samples = samples[1:]
+	}
+	d.samples[pair] = samples
+	prev := d.states[pair].Regime
+	s := d.measure(pair, samples)
+	d.states[pair] = s
+	d.mu.Unlock()
+
+	if d.realized != nil {
+		d.realized.Set(s.RealizedBps, pair)
+		d.regime.Set(s.Regime.Level(), pair)
+	}
+	if prev != "" && prev != s.Regime {
+		log.Printf("🌪️ %s volatility regime %s → %s (%.0f bps over %s)", pair, prev, s.Regime, s.RealizedBps, d.settings.Window)
+	}
+}
+
+// measure computes realized volatility as the root sum of squared log returns
+func (d *Detector) measure(pair string, samples []sample) State {
+	s := normal(pair)
+	var sum float64
+	for i := 1; i < len(samples); i++ {
+		r := math.Log(samples[i].price / samples[i-1].price)
+		sum += r * r
+	}
+	if len(samples) > 1 {
+		s.Returns = len(samples) - 1
+	}
+	s.RealizedBps = math.Sqrt(sum) * 10000
+	if s.Returns < minReturns {
+		return s
+	}
+	switch {
+	case s.RealizedBps >= float64(d.settings.HighBps):
+		s.Regime, s.ScaleBps = RegimeHigh, d.settings.HighScaleBps
+	case s.RealizedBps <= float64(d.settings.CalmBps):
+		s.Regime, s.ScaleBps = RegimeCalm, d.settings.CalmScaleBps
+	}
+	return s
+}
+
+// normal is the state of a pair without a measured regime
+func normal(pair string) State {
+	return State{Pair: pair, Regime: RegimeNormal, ScaleBps: units.BpsDenominator}
+}
+
+// State returns pair's latest measurement
+func (d *Detector) State(pair string) State {
+	if d == nil {
+		return normal(pair)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if s, ok := d.states[pair]; ok {
+		return s
+	}
+	return normal(pair)
+}
+
+// Hourly scales pair's realized volatility to one hour, assuming
+// independent returns; ok is false until enough moves are measured
+func (d *Detector) Hourly(pair string) (bps float64, ok bool) {
+	s := d.State(pair)
+	if s.Returns < minReturns || d.settings.Window <= 0 {
+		return 0, false
+	}
+	return s.RealizedBps * math.Sqrt(float64(time.Hour)/float64(d.settings.Window)), true
+}
+
+// States returns every observed pair's latest measurement
+func (d *Detector) States() []State {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make([]State, 0, len(d.states))
+	for _, s := range d.states {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Pair < out[j].Pair })
+	return out
+}
+
+// Adjust scales g's min-profit and slippage buffers by pair's regime
+func (d *Detector) Adjust(pair string, g config.Guardrails) config.Guardrails {
+	scale := d.State(pair).ScaleBps
+	if scale == units.BpsDenominator {
+		return g
+	}
+	g.MinProfitUSD = units.MulBpsUint(g.MinProfitUSD, scale)
+	g.MaxSlippageBps = min(units.MulBpsUint(g.MaxSlippageBps, scale), units.BpsDenominator-1)
+	return g
+}
+
+// Feature returns pair's regime as an unweighted score component
+func (d *Detector) Feature(pair string) opportunity.ScoreComponent {
+	return opportunity.ScoreComponent{Name: "volatility_regime", Value: d.State(pair).Regime.Level()}
+}
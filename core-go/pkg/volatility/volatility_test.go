@@ -0,0 +1,115 @@
+package volatility
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+)
+
+var settings = Settings{Window: 10 * time.Minute, CalmBps: 25, HighBps: 100, CalmScaleBps: 5000, HighScaleBps: 20000}
+
+// feed observes prices one minute apart
+func feed(d *Detector, start time.Time, pair string, prices ...float64) time.Time {
+	at := start
+	for _, p := range prices {
+		d.now = func() time.Time { return at }
+		d.Observe(pair, p)
+		at = at.Add(time.Minute)
+	}
+	return at
+}
+
+func TestRegimes(t *testing.T) {
+	reg := metrics.NewRegistry()
+	d := New(settings, reg)
+	start := time.Unix(1_700_000_000, 0)
+
+	feed(d, start, "ETH/USD", 2000, 2001, 2000, 2001, 2000)
+	if s := d.State("ETH/USD"); s.Regime != RegimeNormal || s.Returns != 4 {
+		t.Errorf("Expected normal until enough returns, got %s after %d", s.Regime, s.Returns)
+	}
+	feed(d, start.Add(5*time.Minute), "ETH/USD", 2001)
+	if s := d.State("ETH/USD"); s.Regime != RegimeCalm {
+		t.Errorf("Expected calm at %.1f bps, got %s", s.RealizedBps, s.Regime)
+	}
+
+	// 2% swings every minute
+	feed(d, start, "MATIC/USD", 1, 1.02, 1, 1.02, 1, 1.02)
+	s := d.State("MATIC/USD")
+	if s.Regime != RegimeHigh || s.ScaleBps != 20000 {
+		t.Errorf("Expected high volatility, got %s at %.0f bps", s.Regime, s.RealizedBps)
+	}
+	if want := math.Sqrt(5) * math.Log(1.02) * 10000; math.Abs(s.RealizedBps-want) > 0.01 {
+		t.Errorf("Expected %.2f bps realized, got %.2f", want, s.RealizedBps)
+	}
+	if got := reg.Value("titan_volatility_regime", "MATIC/USD"); got != 1 {
+		t.Errorf("Expected the regime gauge at 1, got %v", got)
+	}
+}
+
+func TestWindowForgetsOldMoves(t *testing.T) {
+	d := New(settings, nil)
+	start := time.Unix(1_700_000_000, 0)
+	next := feed(d, start, "ETH/USD", 2000, 2100, 2000, 2100, 2000, 2100)
+	if d.State("ETH/USD").Regime != RegimeHigh {
+		t.Fatal("Expected high volatility")
+	}
+	feed(d, next.Add(20*time.Minute), "ETH/USD", 2100, 2100, 2100, 2100, 2100, 2100)
+	if s := d.State("ETH/USD"); s.Regime != RegimeCalm {
+		t.Errorf("Expected calm once the swings leave the window, got %s at %.0f bps", s.Regime, s.RealizedBps)
+	}
+}
+
+func TestHourly(t *testing.T) {
+	d := New(settings, nil)
+	start := time.Unix(1_700_000_000, 0)
+	feed(d, start, "ETH/USD", 2000, 2100, 2000)
+	if _, ok := d.Hourly("ETH/USD"); ok {
+		t.Errorf("Expected no hourly volatility from two moves")
+	}
+	feed(d, start.Add(3*time.Minute), "ETH/USD", 2100, 2000, 2100)
+	bps, ok := d.Hourly("ETH/USD")
+	if want := d.State("ETH/USD").RealizedBps * math.Sqrt(6); !ok || math.Abs(bps-want) > 0.01 {
+		t.Errorf("Expected the 10-minute figure scaled to %.2f bps, got %.2f", want, bps)
+	}
+	var nilDetector *Detector
+	if _, ok := nilDetector.Hourly("ETH/USD"); ok {
+		t.Errorf("Expected a nil detector to measure nothing")
+	}
+}
+
+func TestAdjust(t *testing.T) {
+	d := New(settings, nil)
+	start := time.Unix(1_700_000_000, 0)
+	feed(d, start, "ETH/USD", 2000, 2100, 2000, 2100, 2000, 2100)
+	feed(d, start, "BNB/USD", 300, 300, 300, 300, 300, 300)
+	g := config.Guardrails{MinLoanUSD: 10000, MaxTVLShareBps: 2000, MaxSlippageBps: 50, MinProfitUSD: 10}
+
+	if got := d.Adjust("ETH/USD", g); got.MinProfitUSD != 20 || got.MaxSlippageBps != 100 || got.MinLoanUSD != 10000 {
+		t.Errorf("Expected only profit and slippage widened, got %+v", got)
+	}
+	if got := d.Adjust("BNB/USD", g); got.MinProfitUSD != 5 || got.MaxSlippageBps != 25 {
+		t.Errorf("Expected profit and slippage tightened, got %+v", got)
+	}
+	if got := d.Adjust("AVAX/USD", g); got != g {
+		t.Errorf("Expected an unobserved pair unchanged, got %+v", got)
+	}
+	if f := d.Feature("ETH/USD"); f.Name != "volatility_regime" || f.Value != 1 || f.Weight != 0 {
+		t.Errorf("Expected an unweighted high-regime feature, got %+v", f)
+	}
+}
+
+func TestNilDetector(t *testing.T) {
+	var d *Detector
+	d.Observe("ETH/USD", 2000)
+	g := config.Guardrails{MaxSlippageBps: 50, MinProfitUSD: 10}
+	if got := d.Adjust("ETH/USD", g); got != g {
+		t.Errorf("Expected a nil detector to keep guardrails, got %+v", got)
+	}
+	if FromConfig(nil, nil) != nil {
+		t.Errorf("Expected no detector without configuration")
+	}
+}
@@ -0,0 +1,213 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/alert"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+)
+
+// Event types; opportunity, execution and reversal match the journal kinds
+const (
+	EventOpportunity = "opportunity"
+	EventExecution   = "execution"
+	EventReversal    = "reversal"
+	EventAlert       = "alert"
+)
+
+// Delivery headers
+const (
+	HeaderEvent     = "X-Titan-Event"
+	HeaderDelivery  = "X-Titan-Delivery"
+	HeaderTimestamp = "X-Titan-Timestamp"
+	HeaderSignature = "X-Titan-Signature"
+)
+
+// queueSize bounds events waiting for delivery; later events are dropped
+const queueSize = 1024
+
+// Event is the JSON body POSTed to every endpoint
+type Event struct {
+	ID   string      `json:"id"`
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// Publisher POSTs signed events to the configured endpoints in the
+// background, so slow receivers never block the caller
+type Publisher struct {
+	urls   []string
+	secret []byte
+	events map[string]bool
+	client *http.Client
+	queue  chan Event
+
+	// Retries is how many times a failed delivery is retried
+	Retries int
+	// Backoff is the first retry delay, doubled on each retry
+	Backoff time.Duration
+
+	now func() time.Time
+}
+
+// NewPublisher creates a publisher sending eventTypes (all when empty) to urls
+func NewPublisher(urls []string, secret string, eventTypes []string) *Publisher {
+	p := &Publisher{
+		urls:    urls,
+		secret:  []byte(secret),
+		events:  make(map[string]bool),
+		client:  &http.Client{Timeout: 10 * time.Second},
+		queue:   make(chan Event, queueSize),
+		Retries: 3,
+		Backoff: time.Second,
+		now:     time.Now,
+	}
+	for _, t := range eventTypes {
+		p.events[strings.ToLower(t)] = true
+	}
+	return p
+}
+
+// FromConfig creates the configured publisher, or nil when no URLs are set
+func FromConfig(cfg *config.WebhookConfig) *Publisher {
+	if cfg == nil || len(cfg.URLs) == 0 {
+		return nil
+	}
+	return NewPublisher(cfg.URLs, cfg.Secret, cfg.Events)
+}
+
+// Publish queues an event of eventType; it has the signature of a
+// Recorder listener so journal kinds pass straight through
+func (p *Publisher) Publish(eventType string, data interface{}) {
+	if len(p.events) > 0 && !p.events[eventType] {
+		return
+	}
+	e := Event{ID: newID(), Type: eventType, Time: p.now().UTC(), Data: data}
+	select {
+	case p.queue <- e:
+	default:
+		log.Printf("⚠️ Webhook queue full, dropping %s event %s", e.Type, e.ID)
+	}
+}
+
+// Name returns the alert channel name
+func (p *Publisher) Name() string { return "webhook" }
+
+// Notify publishes an alert event, so the publisher can join an alert.Dispatcher
+func (p *Publisher) Notify(ctx context.Context, msg alert.Message) error {
+	p.Publish(EventAlert, map[string]string{"level": msg.Level, "title": msg.Title, "body": msg.Body})
+	return nil
+}
+
+// Run delivers queued events until ctx is cancelled
+func (p *Publisher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-p.queue:
+			body, err := json.Marshal(e)
+			if err != nil {
+				log.Printf("❌ Webhook %s event %s: %v", e.Type, e.ID, err)
+				continue
+			}
+			for _, url := range p.urls {
+				if err := p.deliver(ctx, url, e, body); err != nil && ctx.Err() == nil {
+					log.Printf("❌ Webhook %s event %s to %s: %v", e.Type, e.ID, url, err)
+				}
+			}
+		}
+	}
+}
+
+// deliver POSTs body, retrying network errors, 5xx and 429 responses
+func (p *Publisher) deliver(ctx context.Context, url string, e Event, body []byte) error {
+	backoff := p.Backoff
+	var err error
+	for attempt := 0; attempt <= p.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		var retry bool
+		if retry, err = p.post(ctx, url, e, body); err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+func (p *Publisher) post(ctx context.Context, url string, e Event, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := strconv.FormatInt(p.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, e.Type)
+	req.Header.Set(HeaderDelivery, e.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(p.secret, timestamp, body))
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("HTTP %d", resp.StatusCode)
+}
+
+// Sign returns the X-Titan-Signature value: "sha256=" and the hex
+// HMAC-SHA256 of timestamp, ".", and the body. Covering the timestamp lets
+// receivers reject replays.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a delivery's signature and that its timestamp is within
+// tolerance of now, for receivers written in Go
+func Verify(secret []byte, r *http.Request, body []byte, tolerance time.Duration) error {
+	timestamp := r.Header.Get(HeaderTimestamp)
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("webhook: bad timestamp %q", timestamp)
+	}
+	if age := time.Since(time.Unix(sent, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("webhook: timestamp %s outside tolerance", timestamp)
+	}
+	if !hmac.Equal([]byte(r.Header.Get(HeaderSignature)), []byte(Sign(secret, timestamp, body))) {
+		return fmt.Errorf("webhook: signature mismatch")
+	}
+	return nil
+}
+
+// newID returns a random delivery ID receivers can deduplicate retries on
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "evt_" + hex.EncodeToString(b)
+}
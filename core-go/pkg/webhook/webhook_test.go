@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vegas-max/Titan2.0/core-go/pkg/alert"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/journal"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+)
+
+func TestPublisherSignsAndRetries(t *testing.T) {
+	secret := []byte("s3cret")
+	var mu sync.Mutex
+	var events []Event
+	attempts := 0
+	done := make(chan struct{}, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if err := Verify(secret, r, body, time.Minute); err != nil {
+			t.Errorf("Verify failed: %v", err)
+		}
+		var e Event
+		json.Unmarshal(body, &e)
+		if r.Header.Get(HeaderEvent) != e.Type || r.Header.Get(HeaderDelivery) != e.ID {
+			t.Errorf("Expected headers to match event %+v", e)
+		}
+		events = append(events, e)
+		done <- struct{}{}
+	}))
+	defer srv.Close()
+
+	p := NewPublisher([]string{srv.URL}, string(secret), []string{EventOpportunity, EventAlert})
+	p.Backoff = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.Run(ctx)
+
+	recorder := opportunity.NewRecorder(opportunity.NewStore(10), nil)
+	recorder.Listen(p.Publish)
+	if err := recorder.Record(&opportunity.Opportunity{ID: "opp-1"}); err != nil {
+		t.Fatal(err)
+	}
+	recorder.RecordExecution(&opportunity.Execution{OpportunityID: "opp-1"}) // not subscribed
+	alert.NewDispatcher(p).Notify(ctx, alert.Message{Level: alert.LevelWarning, Title: "Gas spike"})
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for deliveries")
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 || events[0].Type != journal.KindOpportunity || events[1].Type != EventAlert {
+		t.Fatalf("Expected opportunity then alert events, got %+v", events)
+	}
+	if data := events[1].Data.(map[string]interface{}); data["title"] != "Gas spike" {
+		t.Errorf("Expected alert title in payload, got %v", data)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected one retried delivery, got %d attempts", attempts)
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	secret := []byte("s3cret")
+	body := []byte(`{"id":"evt_1"}`)
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	now := time.Now().Unix()
+	stamp := strconv.FormatInt(now, 10)
+	r.Header.Set(HeaderTimestamp, stamp)
+	r.Header.Set(HeaderSignature, Sign(secret, stamp, body))
+	if err := Verify(secret, r, body, time.Minute); err != nil {
+		t.Fatalf("Expected valid signature, got %v", err)
+	}
+	if err := Verify(secret, r, []byte(`{"id":"evt_2"}`), time.Minute); err == nil {
+		t.Error("Expected tampered body to fail")
+	}
+	old := strconv.FormatInt(now-3600, 10)
+	r.Header.Set(HeaderTimestamp, old)
+	r.Header.Set(HeaderSignature, Sign(secret, old, body))
+	if err := Verify(secret, r, body, time.Minute); err == nil {
+		t.Error("Expected replayed delivery to fail")
+	}
+}
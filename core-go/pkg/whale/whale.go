@@ -0,0 +1,295 @@
+package whale
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/exposure"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+// Flow directions
+const (
+	Deposit    = "deposit"    // into an exchange, often ahead of a sale
+	Withdrawal = "withdrawal" // out of an exchange
+)
+
+// maxBlocks bounds the range of one log query
+const maxBlocks = 2000
+
+// transferTopic is the ERC20 Transfer event signature
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// Filterer reads a chain's head and logs
+type Filterer interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// Dialer returns a log reader for a chain
+type Dialer func(chainID uint64) (Filterer, error)
+
+// Flow is one whale transfer to or from an exchange
+type Flow struct {
+	ChainID   uint64         `json:"chainId"`
+	Token     common.Address `json:"token"`
+	Symbol    string         `json:"symbol"`
+	Exchange  string         `json:"exchange"`
+	Direction string         `json:"direction"`
+	Amount    string         `json:"amount"`
+	USD       units.USD      `json:"usd"`
+	Tx        common.Hash    `json:"tx"`
+	Block     uint64         `json:"block"`
+	Time      time.Time      `json:"time"` // when it was indexed, within a poll of the block
+
+	at time.Time // Time with its monotonic reading, for the window
+}
+
+// Monitor indexes ERC20 transfers of the trading universe to and from known
+// exchange wallets and keeps those worth at least the threshold, because
+// large deposits tend to precede the price dislocations arbitrage feeds on.
+// Each chain is read forward from the head seen on the first poll. A nil
+// monitor has no flows. It is safe for concurrent use.
+type Monitor struct {
+	exchanges map[common.Address]string
+	minUSD    units.USD
+	window    time.Duration
+	dial      Dialer
+	tokens    func(chainID uint64) []tokens.Token
+	value     exposure.Valuer
+
+	// OnFlow, when set, is called for each whale flow as it is indexed
+	OnFlow func(f Flow)
+
+	mu    sync.Mutex
+	next  map[uint64]uint64 // first block not yet read, by chain
+	flows []Flow            // oldest first
+
+	now   func() time.Time
+	count *metrics.CounterVec
+}
+
+// New creates a monitor of transfers between exchanges and any address in
+// the tokens list returns, valued by value; reg may be nil
+func New(exchanges map[common.Address]string, minUSD units.USD, window time.Duration, dial Dialer, list func(chainID uint64) []tokens.Token, value exposure.Valuer, reg *metrics.Registry) *Monitor {
+	m := &Monitor{
+		exchanges: exchanges,
+		minUSD:    minUSD,
+		window:    window,
+		dial:      dial,
+		tokens:    list,
+		value:     value,
+		next:      make(map[uint64]uint64),
+		now:       time.Now,
+	}
+	if reg != nil {
+		m.count = reg.Counter("titan_whale_flows_total", "Exchange transfers above the whale threshold, by chain and direction", "chain", "direction")
+	}
+	return m
+}
+
+// FromConfig creates the configured monitor, or nil without exchanges
+func FromConfig(cfg *config.WhaleConfig, dial Dialer, list func(chainID uint64) []tokens.Token, value exposure.Valuer, reg *metrics.Registry) *Monitor {
+	if cfg == nil || len(cfg.Exchanges) == 0 {
+		return nil
+	}
+	exchanges := make(map[common.Address]string, len(cfg.Exchanges))
+	for address, name := range cfg.Exchanges {
+		exchanges[common.HexToAddress(address)] = name
+	}
+	return New(exchanges, units.DollarsToUSD(cfg.MinUSD), time.Duration(cfg.WindowMins)*time.Minute, dial, list, value, reg)
+}
+
+// Run polls chains every interval until ctx is done
+func (m *Monitor) Run(ctx context.Context, interval time.Duration, chains ...uint64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, chainID := range chains {
+			if err := m.Poll(ctx, chainID); err != nil && ctx.Err() == nil {
+				log.Printf("⚠️ Whale monitor on chain %d: %v", chainID, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll indexes chainID's transfers since the last poll
+func (m *Monitor) Poll(ctx context.Context, chainID uint64) error {
+	client, err := m.dial(chainID)
+	if err != nil {
+		return err
+	}
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	from, seen := m.next[chainID]
+	m.mu.Unlock()
+	if !seen {
+		from = head
+	}
+	if from > head {
+		return nil
+	}
+	to := head
+	if to-from >= maxBlocks {
+		to = from + maxBlocks - 1
+	}
+
+	list := m.tokens(chainID)
+	byAddress := make(map[common.Address]tokens.Token, len(list))
+	addresses := make([]common.Address, 0, len(list))
+	for _, t := range list {
+		if t.Address != (common.Address{}) {
+			byAddress[t.Address] = t
+			addresses = append(addresses, t.Address)
+		}
+	}
+	if len(addresses) > 0 {
+		exchanges := make([]common.Hash, 0, len(m.exchanges))
+		for a := range m.exchanges {
+			exchanges = append(exchanges, common.BytesToHash(a.Bytes()))
+		}
+		q := ethereum.FilterQuery{FromBlock: new(big.Int).SetUint64(from), ToBlock: new(big.Int).SetUint64(to), Addresses: addresses}
+		// a filter can't OR across topic positions, so deposits and
+		// withdrawals are separate queries
+		for _, topics := range [][][]common.Hash{{{transferTopic}, nil, exchanges}, {{transferTopic}, exchanges}} {
+			q.Topics = topics
+			logs, err := client.FilterLogs(ctx, q)
+			if err != nil {
+				return fmt.Errorf("blocks %d-%d: %w", from, to, err)
+			}
+			for _, l := range logs {
+				m.observe(chainID, byAddress[l.Address], l)
+			}
+		}
+	}
+	m.mu.Lock()
+	m.next[chainID] = to + 1
+	m.mu.Unlock()
+	return nil
+}
+
+// observe records l when it moves at least the threshold
+func (m *Monitor) observe(chainID uint64, token tokens.Token, l types.Log) {
+	if len(l.Topics) != 3 || l.Removed {
+		return
+	}
+	from, to := common.BytesToAddress(l.Topics[1].Bytes()), common.BytesToAddress(l.Topics[2].Bytes())
+	f := Flow{ChainID: chainID, Token: l.Address, Symbol: token.Symbol, Tx: l.TxHash, Block: l.BlockNumber}
+	f.at = m.now()
+	f.Time = f.at.UTC()
+	if name, ok := m.exchanges[to]; ok {
+		f.Exchange, f.Direction = name, Deposit
+	} else if name, ok := m.exchanges[from]; ok {
+		f.Exchange, f.Direction = name, Withdrawal
+	} else {
+		return
+	}
+	if m.exchanges[from] != "" && m.exchanges[to] != "" {
+		return // internal shuffling between exchange wallets
+	}
+	raw := new(big.Int).SetBytes(l.Data)
+	usd, err := m.value.USD(chainID, l.Address, raw)
+	if err != nil || usd < m.minUSD {
+		return
+	}
+	f.USD = usd
+	if a, err := units.FromBig(l.Address, raw, token.Decimals); err == nil {
+		f.Amount = a.String()
+	}
+
+	m.mu.Lock()
+	m.flows = append(m.prune(), f)
+	m.mu.Unlock()
+	if m.count != nil {
+		m.count.Inc(strconv.FormatUint(chainID, 10), f.Direction)
+	}
+	if m.OnFlow != nil {
+		m.OnFlow(f)
+	}
+}
+
+// prune drops flows older than the window
+func (m *Monitor) prune() []Flow {
+	cutoff := m.now().Add(-m.window)
+	i := sort.Search(len(m.flows), func(i int) bool { return m.flows[i].at.After(cutoff) })
+	return m.flows[i:]
+}
+
+// Flows returns the whale flows within the window, newest first
+func (m *Monitor) Flows() []Flow {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	m.flows = m.prune()
+	out := make([]Flow, len(m.flows))
+	for i, f := range m.flows {
+		out[len(out)-1-i] = f
+	}
+	m.mu.Unlock()
+	return out
+}
+
+// Features returns the recent whale flow of the tokens o trades: USD
+// deposited to exchanges net of withdrawals, and the number of whale
+// deposits; it has the signature of experiment.SignalSource
+func (m *Monitor) Features(o *opportunity.Opportunity) map[string]float64 {
+	if m == nil || o.Explanation == nil {
+		return nil
+	}
+	traded := make(map[common.Address]bool)
+	for _, leg := range o.Explanation.Legs {
+		traded[leg.TokenIn] = true
+	}
+	var net units.USD
+	var deposits int
+	m.mu.Lock()
+	m.flows = m.prune()
+	for _, f := range m.flows {
+		if f.ChainID != o.ChainID || !traded[f.Token] {
+			continue
+		}
+		if f.Direction == Deposit {
+			net += f.USD
+			deposits++
+		} else {
+			net -= f.USD
+		}
+	}
+	m.mu.Unlock()
+	return map[string]float64{
+		"whale_net_deposit_usd": net.Float(),
+		"whale_deposits":        float64(deposits),
+	}
+}
+
+// String describes the flow for alerts
+func (f Flow) String() string {
+	verb := "deposited to"
+	if f.Direction == Withdrawal {
+		verb = "withdrawn from"
+	}
+	return fmt.Sprintf("%s %s (%s) %s %s on chain %d, tx %s", f.Amount, f.Symbol, f.USD, verb, f.Exchange, f.ChainID, f.Tx.Hex())
+}
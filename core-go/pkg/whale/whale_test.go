@@ -0,0 +1,160 @@
+package whale
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/config"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/metrics"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/opportunity"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/titantest"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/tokens"
+	"github.com/vegas-max/Titan2.0/core-go/pkg/units"
+)
+
+var (
+	weth    = titantest.Address(1)
+	pepe    = titantest.Address(2)
+	binance = titantest.Address(10)
+	kraken  = titantest.Address(11)
+	trader  = titantest.Address(20)
+)
+
+// chain serves transfer logs, applying the topic filter like a node would
+type chain struct {
+	head    uint64
+	logs    []types.Log
+	queries []ethereum.FilterQuery
+}
+
+func (c *chain) BlockNumber(ctx context.Context) (uint64, error) { return c.head, nil }
+
+func (c *chain) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	c.queries = append(c.queries, q)
+	var out []types.Log
+	for _, l := range c.logs {
+		if l.BlockNumber < q.FromBlock.Uint64() || l.BlockNumber > q.ToBlock.Uint64() {
+			continue
+		}
+		match := true
+		for i, want := range q.Topics {
+			if len(want) == 0 {
+				continue
+			}
+			found := false
+			for _, h := range want {
+				found = found || l.Topics[i] == h
+			}
+			match = match && found
+		}
+		if match {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func transfer(block uint64, token, from, to common.Address, whole int64) types.Log {
+	raw := new(big.Int).Mul(big.NewInt(whole), big.NewInt(1e18))
+	return types.Log{
+		Address:     token,
+		Topics:      []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:        common.LeftPadBytes(raw.Bytes(), 32),
+		BlockNumber: block,
+		TxHash:      common.BigToHash(big.NewInt(int64(block))),
+	}
+}
+
+// dollars values every whole token at a dollar
+type dollars struct{}
+
+func (dollars) USD(chainID uint64, token common.Address, raw *big.Int) (units.USD, error) {
+	return units.DollarsToUSD(new(big.Int).Div(raw, big.NewInt(1e18)).Uint64()), nil
+}
+
+func newMonitor(c *chain, reg *metrics.Registry) *Monitor {
+	cfg := &config.WhaleConfig{
+		Exchanges:  map[string]string{binance.Hex(): "binance", kraken.Hex(): "kraken"},
+		MinUSD:     1000000,
+		WindowMins: 60,
+	}
+	list := func(chainID uint64) []tokens.Token {
+		return []tokens.Token{{ChainID: 1, Symbol: "WETH", Address: weth, Decimals: 18}, {ChainID: 1, Symbol: "PEPE", Address: pepe, Decimals: 18}}
+	}
+	return FromConfig(cfg, func(uint64) (Filterer, error) { return c, nil }, list, dollars{}, reg)
+}
+
+func TestMonitorIndexesExchangeFlows(t *testing.T) {
+	c := &chain{head: 100}
+	reg := metrics.NewRegistry()
+	m := newMonitor(c, reg)
+	var alerted []Flow
+	m.OnFlow = func(f Flow) { alerted = append(alerted, f) }
+
+	if err := m.Poll(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	c.head = 105
+	c.logs = []types.Log{
+		transfer(99, pepe, trader, binance, 5000000),  // before the first head
+		transfer(101, pepe, trader, binance, 3000000), // deposit
+		transfer(102, pepe, trader, binance, 500),     // too small
+		transfer(103, weth, kraken, trader, 2000000),  // withdrawal
+		transfer(104, pepe, binance, kraken, 9000000), // between exchanges
+	}
+	if err := m.Poll(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	flows := m.Flows()
+	if len(flows) != 2 || flows[0].Direction != Withdrawal || flows[0].Exchange != "kraken" || flows[1].Direction != Deposit || flows[1].Symbol != "PEPE" {
+		t.Fatalf("Expected a WETH withdrawal and a PEPE deposit, got %+v", flows)
+	}
+	if flows[1].Amount != "3000000" || flows[1].USD != units.DollarsToUSD(3000000) {
+		t.Errorf("Expected the deposit amount and value, got %s, %s", flows[1].Amount, flows[1].USD)
+	}
+	if len(alerted) != 2 {
+		t.Errorf("Expected each flow alerted, got %d", len(alerted))
+	}
+	if got := reg.Value("titan_whale_flows_total", "1", Deposit); got != 1 {
+		t.Errorf("Expected one deposit counted, got %v", got)
+	}
+	if q := c.queries[len(c.queries)-1]; q.FromBlock.Uint64() != 101 || q.ToBlock.Uint64() != 105 {
+		t.Errorf("Expected blocks after the last poll read, got %v-%v", q.FromBlock, q.ToBlock)
+	}
+}
+
+func TestFeaturesSumRecentFlow(t *testing.T) {
+	c := &chain{head: 1}
+	m := newMonitor(c, nil)
+	now := time.Date(2006, 1, 2, 15, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	m.Poll(context.Background(), 1)
+	c.head = 3
+	c.logs = []types.Log{
+		transfer(2, pepe, trader, binance, 4000000),
+		transfer(3, pepe, binance, trader, 1000000),
+	}
+	m.Poll(context.Background(), 1)
+
+	o := &opportunity.Opportunity{ChainID: 1, Explanation: &opportunity.Explanation{Legs: []opportunity.Leg{
+		{TokenIn: weth, TokenOut: pepe}, {TokenIn: pepe, TokenOut: weth},
+	}}}
+	f := m.Features(o)
+	if f["whale_net_deposit_usd"] != 3000000 || f["whale_deposits"] != 1 {
+		t.Errorf("Expected $3M net deposited, got %v", f)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if f := m.Features(o); f["whale_net_deposit_usd"] != 0 || len(m.Flows()) != 0 {
+		t.Errorf("Expected flows outside the window forgotten, got %v", f)
+	}
+	if FromConfig(&config.WhaleConfig{}, nil, nil, nil, nil) != nil {
+		t.Errorf("Expected no monitor without exchanges")
+	}
+}
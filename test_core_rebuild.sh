@@ -17,7 +17,7 @@ NC='\033[0m' # No Color
 # Test Go Implementation
 echo -e "${YELLOW}[1/4] Testing Go Build...${NC}"
 cd core-go
-if go build -o titan-core ./main.go; then
+if go build -o titan-core ./cmd/titan; then
     echo -e "${GREEN}✅ Go build successful${NC}"
     ls -lh titan-core
 else